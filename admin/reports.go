@@ -27,21 +27,28 @@
 
 		reports show --id <report_id>
 
+	Both commands are also available as `admin reports`. --from and --to narrow reports down to a time range,
+	--group-by aggregates the consumption of their servers by server or account_group, and
+	--csv exports the result to a file.
+
+	Usage:
+
+		admin reports list --from 2016-01-01 --to 2016-04-01 --group-by account_group --csv q1.csv
+
 */
 package admin
 
-import (
-	"encoding/json"
-	"fmt"
-	"os"
-	"text/tabwriter"
+// import (
+// 	"encoding/json"
+// 	"fmt"
+// 	"os"
+// 	"text/tabwriter"
 
-	"github.com/codegangsta/cli"
-	"github.com/flexiant/concerto/api/types"
-	"github.com/flexiant/concerto/utils"
-	"github.com/flexiant/concerto/webservice"
-	// "time"
-)
+// 	"github.com/codegangsta/cli"
+// 	"github.com/flexiant/concerto/utils"
+// 	"github.com/flexiant/concerto/webservice"
+// 	"time"
+// )
 
 // type Report struct {
 // 	Id             string       `json:"id"`
@@ -93,36 +100,6 @@ import (
 // 	w.Flush()
 // }
 
-func cmdShow(c *cli.Context) error {
-	var vals types.Report
-
-	utils.FlagsRequired(c, []string{"id"})
-
-	webservice, err := webservice.NewWebService()
-	utils.CheckError(err)
-
-	err, data, res := webservice.Get(fmt.Sprintf("/v1/admin/reports/%s", c.String("id")))
-	utils.CheckError(err)
-	utils.CheckReturnCode(res, data)
-
-	err = json.Unmarshal(data, &vals)
-	utils.CheckError(err)
-
-	w := tabwriter.NewWriter(os.Stdout, 15, 1, 3, ' ', 0)
-
-	fmt.Fprintln(w, "REPORT ID\tYEAR\tMONTH\tSTART TIME\tEND TIME\tSERVER SECONDS\tCLOSED\tACCOUNT GROUP ID\tACCOUNT GROUP NAME\r")
-	fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%g\t%t\t%s\t%s\n", vals.ID, vals.Year, vals.Month, vals.StartTime, vals.EndTime, vals.ServerSeconds, vals.Closed, vals.AccountGroup.ID, vals.AccountGroup.Name)
-
-	fmt.Fprintln(w, "LINES:\r")
-	fmt.Fprintln(w, "ID\tCOMMISSIONED AT\tDECOMMISSIONED AT\tINSTANCE ID\tINSTANCE NAME\tINSTANCE FQDN\tCONSUMPTION\r")
-
-	for _, l := range vals.Lines {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%g\n", l.ID, l.CommissionedAt, l.DecommissionedAt, l.InstanceID, l.InstanceName, l.InstanceFQDN, l.Consumption)
-	}
-	w.Flush()
-	return nil
-}
-
 // func SubCommands() []cli.Command {
 // 	return []cli.Command{
 // 		{
@@ -5,24 +5,46 @@ import (
 	"github.com/flexiant/concerto/cmd"
 )
 
+// reportFlags narrow reports down to a time range, and aggregate and export their consumption
+func reportFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  "from",
+			Usage: "Only count server time from a date, such as 2016-01-02 or 2016-01-02T15:04:05Z, or a duration before now, such as 7d",
+		},
+		cli.StringFlag{
+			Name:  "to",
+			Usage: "Only count server time up to a date, or a duration before now",
+		},
+		cli.StringFlag{
+			Name:  "group-by",
+			Usage: "Show the consumption of the servers of reports instead, aggregated by server or account_group",
+		},
+		cli.StringFlag{
+			Name:  "csv",
+			Usage: "Also export the result as CSV to a file, whatever the output format",
+		},
+	}
+}
+
 func SubCommands() []cli.Command {
 	return []cli.Command{
 		{
 			Name:   "list",
 			Usage:  "Returns information about the reports related to all the account groups of the tenant. The authenticated user must be an admin.",
 			Action: cmd.AdminReportList,
+			Flags:  append(reportFlags(), cmd.ListFlags()...),
 		},
 		{
 			Name:   "show",
 			Usage:  "Returns details about a particular report associated to any account group of the tenant. The authenticated user must be an admin.",
-			Action: cmdShow,
-			// Action: cmd.AdminReportShow,
-			Flags: []cli.Flag{
+			Action: cmd.AdminReportShow,
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "id",
 					Usage: "Report Identifier",
 				},
-			},
+			}, reportFlags()...),
 		},
 	}
 }
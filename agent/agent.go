@@ -0,0 +1,255 @@
+package agent
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/client"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/dispatcher"
+	"github.com/flexiant/concerto/firewall"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/metrics"
+)
+
+// stateFile is the file of the configuration location where the agent keeps its state
+const stateFile = "agent.json"
+
+// operational is the phase of the scripts the agent runs
+const operational = "operational"
+
+var (
+	agentPolls    = metrics.NewCounter("concerto_agent_polls_total", "Agent polls of the API, by result.", "result")
+	agentLastPoll = metrics.NewGauge("concerto_agent_last_poll_timestamp_seconds", "Time of the last agent poll.")
+)
+
+// Command returns the agent command, run in hosts to keep them in line with Concerto
+func Command() cli.Command {
+	return cli.Command{
+		Name:   "agent",
+		Usage:  "Registers the host and polls Concerto for its firewall policy and operational scripts, applying the policy when it changes and running new or changed scripts, whose results are reported",
+		Before: firewall.SelectDriver,
+		Action: cmdAgent,
+		Flags: []cli.Flag{
+			cli.DurationFlag{
+				Name:  "interval",
+				Usage: "Time between polls",
+				Value: time.Minute,
+			},
+			cli.BoolFlag{
+				Name:  "once",
+				Usage: "Poll once and exit, such as when run by cron or a systemd timer",
+			},
+			cli.BoolFlag{
+				Name:  "skip-firewall",
+				Usage: "Don't apply the firewall policy",
+			},
+			cli.BoolFlag{
+				Name:  "skip-scripts",
+				Usage: "Don't run operational scripts",
+			},
+			cli.StringFlag{
+				Name:   "driver",
+				Usage:  "Firewall driver. Linux: [ auto | iptables | nftables | firewalld ], Windows: [ auto | netsh | powershell ]",
+				EnvVar: "CONCERTO_FIREWALL_DRIVER",
+			},
+		},
+	}
+}
+
+// State is what the agent has applied and run, kept between runs so that scripts aren't run again
+// when it's restarted
+type State struct {
+	FirewallMd5 string `json:"firewall_md5,omitempty"`
+	// Scripts holds the fingerprint of the operational script characterizations run, by UUID
+	Scripts map[string]string `json:"scripts,omitempty"`
+}
+
+// LoadState reads the state of file. A missing file is an empty state
+func LoadState(file string) (*State, error) {
+	state := &State{Scripts: make(map[string]string)}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("Couldn't parse agent state %s: %s", file, err)
+	}
+	if state.Scripts == nil {
+		state.Scripts = make(map[string]string)
+	}
+	return state, nil
+}
+
+// Save writes the state to file, replacing it at once so that an interrupted agent doesn't leave it half written
+func (s *State) Save(file string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file))
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// Pending returns the script characterizations which haven't been run, or have changed since, in execution order
+func (s *State) Pending(scripts []types.ScriptCharacterization) []types.ScriptCharacterization {
+	var pending []types.ScriptCharacterization
+	for _, sc := range scripts {
+		if s.Scripts[sc.UUID] != fingerprint(sc) {
+			pending = append(pending, sc)
+		}
+	}
+	sort.Stable(dispatcher.ByOrder(pending))
+	return pending
+}
+
+// Forget removes the script characterizations which aren't in scripts any more, so that they're run again
+// if they're added back
+func (s *State) Forget(scripts []types.ScriptCharacterization) {
+	current := make(map[string]bool)
+	for _, sc := range scripts {
+		current[sc.UUID] = true
+	}
+	for uuid := range s.Scripts {
+		if !current[uuid] {
+			delete(s.Scripts, uuid)
+		}
+	}
+}
+
+// fingerprint identifies the code, attachments and parameters of a script characterization
+func fingerprint(sc types.ScriptCharacterization) string {
+	data, _ := json.Marshal(sc)
+	return fmt.Sprintf("%x", md5.Sum(data))
+}
+
+type agent struct {
+	client       *client.Client
+	state        *State
+	stateFile    string
+	skipFirewall bool
+	skipScripts  bool
+}
+
+// poll registers the host, applies its firewall policy and runs its pending scripts. Every step is
+// attempted even when a previous one fails, and errors are logged
+func (a *agent) poll() error {
+	var errs []error
+	hostname, _ := os.Hostname()
+	if err := a.client.RegisterHost(types.HostRegistration{Hostname: hostname, OS: runtime.GOOS, Arch: runtime.GOARCH, Version: utils.VERSION}); err != nil {
+		errs = append(errs, fmt.Errorf("Couldn't register host: %s", err))
+	}
+
+	if !a.skipFirewall {
+		md5, err := firewall.Sync(a.state.FirewallMd5)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Couldn't apply firewall policy: %s", err))
+		} else if md5 != a.state.FirewallMd5 {
+			log.Infof("Applied firewall policy %s", md5)
+			a.state.FirewallMd5 = md5
+		}
+	}
+
+	if !a.skipScripts {
+		if err := a.runScripts(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := a.state.Save(a.stateFile); err != nil {
+		errs = append(errs, fmt.Errorf("Couldn't save agent state: %s", err))
+	}
+	for _, err := range errs {
+		log.Error(err)
+	}
+	agentLastPoll.Set(float64(time.Now().Unix()))
+	if len(errs) > 0 {
+		agentPolls.Inc("failure")
+		return fmt.Errorf("Agent poll failed with %d errors", len(errs))
+	}
+	agentPolls.Inc("success")
+	return nil
+}
+
+// runScripts runs the pending operational scripts. Scripts which ran are not run again, even when they
+// failed, as their result has been reported. Scripts which couldn't run are retried in the next poll
+func (a *agent) runScripts() error {
+	scripts, err := a.client.GetScriptCharacterizations(operational)
+	if err != nil {
+		return fmt.Errorf("Couldn't receive scripts: %s", err)
+	}
+	a.state.Forget(scripts)
+	for _, sc := range a.state.Pending(scripts) {
+		conclusion, err := dispatcher.Run(a.client, operational, sc)
+		if err != nil {
+			return fmt.Errorf("Couldn't run script %s: %s", sc.UUID, err)
+		}
+		log.Infof("Script %s exited with %d", sc.UUID, conclusion.ExitCode)
+		a.state.Scripts[sc.UUID] = fingerprint(sc)
+	}
+	return nil
+}
+
+func cmdAgent(c *cli.Context) error {
+	interval := c.Duration("interval")
+	if interval <= 0 {
+		return exit.NewValidationError(fmt.Errorf("Invalid interval %s. Please, use a positive duration such as 30s or 5m", interval))
+	}
+	config, err := utils.GetConcertoConfig()
+	if err != nil {
+		return err
+	}
+	hc, err := client.Default()
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(config.ConfLocation, stateFile)
+	state, err := LoadState(file)
+	if err != nil {
+		return err
+	}
+
+	a := &agent{
+		client:       hc,
+		state:        state,
+		stateFile:    file,
+		skipFirewall: c.Bool("skip-firewall"),
+		skipScripts:  c.Bool("skip-scripts"),
+	}
+	for {
+		err := a.poll()
+		if c.Bool("once") {
+			return err
+		}
+		// errors have been logged, and the next poll may succeed
+		if !cancel.Sleep(interval) {
+			return nil
+		}
+	}
+}
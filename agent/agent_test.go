@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPending(t *testing.T) {
+	assert := assert.New(t)
+
+	install := types.ScriptCharacterization{Order: 2, UUID: "sc1", Script: types.HostScript{Code: "apt-get install -y nginx"}}
+	notify := types.ScriptCharacterization{Order: 1, UUID: "sc2", Script: types.HostScript{Code: "echo $NAME"}, Parameters: map[string]string{"NAME": "web"}}
+	scripts := []types.ScriptCharacterization{install, notify}
+
+	state := &State{Scripts: make(map[string]string)}
+	assert.Equal([]types.ScriptCharacterization{notify, install}, state.Pending(scripts), "New scripts should be pending, in execution order")
+
+	state.Scripts["sc1"] = fingerprint(install)
+	state.Scripts["sc2"] = fingerprint(notify)
+	assert.Empty(state.Pending(scripts), "Scripts run shouldn't be pending")
+
+	notify.Parameters = map[string]string{"NAME": "db"}
+	assert.Equal([]types.ScriptCharacterization{notify}, state.Pending([]types.ScriptCharacterization{install, notify}), "Changed scripts should be pending")
+
+	state.Forget([]types.ScriptCharacterization{notify})
+	assert.Equal(map[string]string{"sc2": fingerprint(types.ScriptCharacterization{Order: 1, UUID: "sc2", Script: types.HostScript{Code: "echo $NAME"}, Parameters: map[string]string{"NAME": "web"}})}, state.Scripts, "Removed scripts should be forgotten")
+}
+
+func TestState(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "concerto")
+	assert.Nil(err, "Temporary directory couldn't be created")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, stateFile)
+
+	state, err := LoadState(file)
+	assert.Nil(err, "Missing state shouldn't fail")
+	assert.Empty(state.Scripts, "Missing state should be empty")
+
+	state.FirewallMd5 = "d41d8cd98f00b204e9800998ecf8427e"
+	state.Scripts["sc1"] = "fingerprint"
+	assert.Nil(state.Save(file), "State should be saved")
+
+	loaded, err := LoadState(file)
+	assert.Nil(err, "Saved state should load")
+	assert.Equal(state, loaded, "Unexpected state")
+
+	ioutil.WriteFile(file, []byte("{"), 0600)
+	_, err = LoadState(file)
+	assert.NotNil(err, "Corrupted state should fail")
+}
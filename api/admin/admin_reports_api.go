@@ -1,7 +1,6 @@
 package admin
 
 import (
-	"encoding/json"
 	"fmt"
 
 	log "github.com/Sirupsen/logrus"
@@ -30,16 +29,7 @@ func NewReportService(concertoService utils.ConcertoService) (*ReportService, er
 func (rs *ReportService) GetAdminReportList() (reports []types.Report, err error) {
 	log.Debug("GetReportList")
 
-	data, status, err := rs.concertoService.Get("/v1/admin/reports")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &reports); err != nil {
+	if err = utils.GetJSON(rs.concertoService, "/v1/admin/reports", &reports); err != nil {
 		return nil, err
 	}
 
@@ -50,16 +40,7 @@ func (rs *ReportService) GetAdminReportList() (reports []types.Report, err error
 func (rs *ReportService) GetAdminReport(ID string) (report *types.Report, err error) {
 	log.Debug("GetReport")
 
-	data, status, err := rs.concertoService.Get(fmt.Sprintf("/v1/admin/reports/%s", ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &report); err != nil {
+	if err = utils.GetJSON(rs.concertoService, fmt.Sprintf("/v1/admin/reports/%s", ID), &report); err != nil {
 		return nil, err
 	}
 
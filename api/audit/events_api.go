@@ -28,36 +28,49 @@ func NewEventService(concertoService utils.ConcertoService) (*EventService, erro
 func (cl *EventService) GetEventList() (events []types.Event, err error) {
 	log.Debug("GetEventList")
 
-	data, status, err := cl.concertoService.Get("/v1/audit/events")
-	if err != nil {
+	if err = utils.GetJSON(cl.concertoService, "/v1/audit/events", &events); err != nil {
 		return nil, err
 	}
 
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
+	return events, nil
+}
 
-	if err = json.Unmarshal(data, &events); err != nil {
+// GetEvent returns an event by its ID
+func (cl *EventService) GetEvent(ID string) (event *types.Event, err error) {
+	log.Debug("GetEvent")
+
+	if err = utils.GetJSON(cl.concertoService, fmt.Sprintf("/v1/audit/events/%s", ID), &event); err != nil {
 		return nil, err
 	}
 
-	return events, nil
+	return event, nil
 }
 
-// GetSysEventList returns the list of events as an array of Event
-func (cl *EventService) GetSysEventList() (events []types.Event, err error) {
-	log.Debug("GetEventList")
+// StreamEventList calls fn for every event, decoding them one by one so that
+// big event logs don't have to be loaded in memory
+func (cl *EventService) StreamEventList(fn func(event types.Event) error) error {
+	log.Debug("StreamEventList")
 
-	data, status, err := cl.concertoService.Get("/v1/audit/system_events")
+	body, _, err := cl.concertoService.GetStream("/v1/audit/events")
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer body.Close()
 
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
+	return utils.DecodeJSONList(body, func(item json.RawMessage) error {
+		var event types.Event
+		if err := json.Unmarshal(item, &event); err != nil {
+			return err
+		}
+		return fn(event)
+	})
+}
+
+// GetSysEventList returns the list of events as an array of Event
+func (cl *EventService) GetSysEventList() (events []types.Event, err error) {
+	log.Debug("GetEventList")
 
-	if err = json.Unmarshal(data, &events); err != nil {
+	if err = utils.GetJSON(cl.concertoService, "/v1/audit/system_events", &events); err != nil {
 		return nil, err
 	}
 
@@ -1,11 +1,13 @@
 package audit
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/utils"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
 	"testing"
 )
 
@@ -112,6 +114,141 @@ func GetEventListFailJSONMocked(t *testing.T, eventsIn *[]types.Event) *[]types.
 	return &eventsOut
 }
 
+// GetEventMocked test mocked function
+func GetEventMocked(t *testing.T, eventIn *types.Event) *types.Event {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewEventService(cs)
+	assert.Nil(err, "Couldn't load event service")
+	assert.NotNil(ds, "Event service not instanced")
+
+	// to json
+	dIn, err := json.Marshal(eventIn)
+	assert.Nil(err, "Event test data corrupted")
+
+	// call service
+	cs.On("Get", fmt.Sprintf("/v1/audit/events/%s", eventIn.Id)).Return(dIn, 200, nil)
+	eventOut, err := ds.GetEvent(eventIn.Id)
+	assert.Nil(err, "Error getting event")
+	assert.Equal(*eventIn, *eventOut, "GetEvent returned different events")
+
+	return eventOut
+}
+
+// GetEventFailStatusMocked test mocked function
+func GetEventFailStatusMocked(t *testing.T, eventIn *types.Event) *types.Event {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewEventService(cs)
+	assert.Nil(err, "Couldn't load event service")
+	assert.NotNil(ds, "Event service not instanced")
+
+	// to json
+	dIn, err := json.Marshal(eventIn)
+	assert.Nil(err, "Event test data corrupted")
+
+	// call service
+	cs.On("Get", fmt.Sprintf("/v1/audit/events/%s", eventIn.Id)).Return(dIn, 404, nil)
+	eventOut, err := ds.GetEvent(eventIn.Id)
+
+	assert.NotNil(err, "We are expecting an status code error")
+	assert.Nil(eventOut, "Expecting nil output")
+	assert.Contains(err.Error(), "404", "Error should contain http code 404")
+
+	return eventOut
+}
+
+// StreamEventListMocked test mocked function
+func StreamEventListMocked(t *testing.T, eventsIn *[]types.Event) *[]types.Event {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewEventService(cs)
+	assert.Nil(err, "Couldn't load event service")
+	assert.NotNil(ds, "Event service not instanced")
+
+	// to json
+	dIn, err := json.Marshal(eventsIn)
+	assert.Nil(err, "Event test data corrupted")
+
+	// call service
+	cs.On("GetStream", "/v1/audit/events").Return(ioutil.NopCloser(bytes.NewReader(dIn)), 200, nil)
+	var eventsOut []types.Event
+	err = ds.StreamEventList(func(event types.Event) error {
+		eventsOut = append(eventsOut, event)
+		return nil
+	})
+	assert.Nil(err, "Error streaming event list")
+	assert.Equal(*eventsIn, eventsOut, "StreamEventList returned different events")
+
+	return &eventsOut
+}
+
+// StreamEventListFailStatusMocked test mocked function
+func StreamEventListFailStatusMocked(t *testing.T, eventsIn *[]types.Event) *[]types.Event {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewEventService(cs)
+	assert.Nil(err, "Couldn't load event service")
+	assert.NotNil(ds, "Event service not instanced")
+
+	// to json
+	dIn, err := json.Marshal(eventsIn)
+	assert.Nil(err, "Event test data corrupted")
+
+	// call service
+	cs.On("GetStream", "/v1/audit/events").Return(ioutil.NopCloser(bytes.NewReader(dIn)), 499, nil)
+	var eventsOut []types.Event
+	err = ds.StreamEventList(func(event types.Event) error {
+		eventsOut = append(eventsOut, event)
+		return nil
+	})
+	assert.NotNil(err, "We are expecting an status code error")
+	assert.Nil(eventsOut, "Expecting nil output")
+	assert.Contains(err.Error(), "499", "Error should contain http code 499")
+
+	return &eventsOut
+}
+
+// StreamEventListFailJSONMocked test mocked function
+func StreamEventListFailJSONMocked(t *testing.T, eventsIn *[]types.Event) *[]types.Event {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewEventService(cs)
+	assert.Nil(err, "Couldn't load event service")
+	assert.NotNil(ds, "Event service not instanced")
+
+	// wrong json
+	dIn := []byte{10, 20, 30}
+
+	// call service
+	cs.On("GetStream", "/v1/audit/events").Return(ioutil.NopCloser(bytes.NewReader(dIn)), 200, nil)
+	var eventsOut []types.Event
+	err = ds.StreamEventList(func(event types.Event) error {
+		eventsOut = append(eventsOut, event)
+		return nil
+	})
+	assert.NotNil(err, "We are expecting a marshalling error")
+	assert.Nil(eventsOut, "Expecting nil output")
+	assert.Contains(err.Error(), "invalid character", "Error message should include the string 'invalid character'")
+
+	return &eventsOut
+}
+
 // GetSysEventListMocked test mocked function
 func GetSysEventListMocked(t *testing.T, eventsIn *[]types.Event) *[]types.Event {
 
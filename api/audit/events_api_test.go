@@ -21,6 +21,21 @@ func TestGetEventList(t *testing.T) {
 	GetEventListFailJSONMocked(t, eventsIn)
 }
 
+func TestGetEvent(t *testing.T) {
+	eventsIn := testdata.GetEventData()
+	for _, eventIn := range *eventsIn {
+		GetEventMocked(t, &eventIn)
+		GetEventFailStatusMocked(t, &eventIn)
+	}
+}
+
+func TestStreamEventList(t *testing.T) {
+	eventsIn := testdata.GetEventData()
+	StreamEventListMocked(t, eventsIn)
+	StreamEventListFailStatusMocked(t, eventsIn)
+	StreamEventListFailJSONMocked(t, eventsIn)
+}
+
 func TestGetSysEventList(t *testing.T) {
 	eventsIn := testdata.GetEventData()
 	GetSysEventListMocked(t, eventsIn)
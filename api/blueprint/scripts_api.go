@@ -1,7 +1,6 @@
 package blueprint
 
 import (
-	"encoding/json"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
@@ -28,16 +27,7 @@ func NewScriptService(concertoService utils.ConcertoService) (*ScriptService, er
 func (sc *ScriptService) GetScriptList() (scripts []types.Script, err error) {
 	log.Debug("GetScriptsList")
 
-	data, status, err := sc.concertoService.Get("/v1/blueprint/scripts")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &scripts); err != nil {
+	if err = utils.GetJSON(sc.concertoService, "/v1/blueprint/scripts", &scripts); err != nil {
 		return nil, err
 	}
 
@@ -48,16 +38,7 @@ func (sc *ScriptService) GetScriptList() (scripts []types.Script, err error) {
 func (sc *ScriptService) GetScript(ID string) (script *types.Script, err error) {
 	log.Debug("GetScript")
 
-	data, status, err := sc.concertoService.Get(fmt.Sprintf("/v1/blueprint/scripts/%s", ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &script); err != nil {
+	if err = utils.GetJSON(sc.concertoService, fmt.Sprintf("/v1/blueprint/scripts/%s", ID), &script); err != nil {
 		return nil, err
 	}
 
@@ -68,16 +49,7 @@ func (sc *ScriptService) GetScript(ID string) (script *types.Script, err error)
 func (sc *ScriptService) CreateScript(scriptVector *map[string]interface{}) (script *types.Script, err error) {
 	log.Debug("CreateScript")
 
-	data, status, err := sc.concertoService.Post("/v1/blueprint/scripts", scriptVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &script); err != nil {
+	if err = utils.PostJSON(sc.concertoService, "/v1/blueprint/scripts", scriptVector, &script); err != nil {
 		return nil, err
 	}
 
@@ -88,16 +60,7 @@ func (sc *ScriptService) CreateScript(scriptVector *map[string]interface{}) (scr
 func (sc *ScriptService) UpdateScript(scriptVector *map[string]interface{}, ID string) (script *types.Script, err error) {
 	log.Debug("UpdateScript")
 
-	data, status, err := sc.concertoService.Put(fmt.Sprintf("/v1/blueprint/scripts/%s", ID), scriptVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &script); err != nil {
+	if err = utils.PutJSON(sc.concertoService, fmt.Sprintf("/v1/blueprint/scripts/%s", ID), scriptVector, &script); err != nil {
 		return nil, err
 	}
 
@@ -1,8 +1,8 @@
 package blueprint
 
 import (
-	"encoding/json"
 	"fmt"
+	"strings"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
@@ -29,16 +29,7 @@ func NewServicesService(concertoService utils.ConcertoService) (*ServicesService
 func (ss *ServicesService) GetServiceList() (services []types.Service, err error) {
 	log.Debug("GetServiceList")
 
-	data, status, err := ss.concertoService.Get("/v1/blueprint/services")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &services); err != nil {
+	if err = utils.GetJSON(ss.concertoService, "/v1/blueprint/services", &services); err != nil {
 		return nil, err
 	}
 
@@ -49,18 +40,25 @@ func (ss *ServicesService) GetServiceList() (services []types.Service, err error
 func (ss *ServicesService) GetService(ID string) (service *types.Service, err error) {
 	log.Debug("GetService")
 
-	data, status, err := ss.concertoService.Get(fmt.Sprintf("/v1/blueprint/services/%s", ID))
-	if err != nil {
+	if err = utils.GetJSON(ss.concertoService, fmt.Sprintf("/v1/blueprint/services/%s", ID), &service); err != nil {
 		return nil, err
 	}
 
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
+	return service, nil
+}
+
+// GetServiceByName returns a service by its name, regardless of case
+func (ss *ServicesService) GetServiceByName(name string) (service *types.Service, err error) {
+	log.Debug("GetServiceByName")
 
-	if err = json.Unmarshal(data, &service); err != nil {
+	services, err := ss.GetServiceList()
+	if err != nil {
 		return nil, err
 	}
-
-	return service, nil
+	for i, s := range services {
+		if strings.EqualFold(s.Name, name) {
+			return &services[i], nil
+		}
+	}
+	return nil, fmt.Errorf("Service %s not found", name)
 }
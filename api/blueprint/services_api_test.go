@@ -1,9 +1,11 @@
 package blueprint
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/flexiant/concerto/testdata"
+	"github.com/flexiant/concerto/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,3 +33,18 @@ func TestGetService(t *testing.T) {
 		GetServiceFailJSONMocked(t, &serviceIn)
 	}
 }
+
+func TestGetServiceByName(t *testing.T) {
+	assert := assert.New(t)
+	servicesIn := testdata.GetServiceData()
+	cs := &utils.MockConcertoService{}
+	ds, _ := NewServicesService(cs)
+	dIn, _ := json.Marshal(servicesIn)
+	cs.On("Get", "/v1/blueprint/services").Return(dIn, 200, nil)
+
+	service, err := ds.GetServiceByName("FAKENAME1")
+	assert.Nil(err, "Error getting service by name")
+	assert.Equal((*servicesIn)[1], *service, "Service wasn't matched by name")
+	_, err = ds.GetServiceByName("joomla")
+	assert.EqualError(err, "Service joomla not found")
+}
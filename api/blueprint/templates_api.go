@@ -1,7 +1,6 @@
 package blueprint
 
 import (
-	"encoding/json"
 	"fmt"
 
 	log "github.com/Sirupsen/logrus"
@@ -29,16 +28,7 @@ func NewTemplateService(concertoService utils.ConcertoService) (*TemplateService
 func (tp *TemplateService) GetTemplateList() (templates []types.Template, err error) {
 	log.Debug("GetTemplateList")
 
-	data, status, err := tp.concertoService.Get("/v1/blueprint/templates")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &templates); err != nil {
+	if err = utils.GetJSON(tp.concertoService, "/v1/blueprint/templates", &templates); err != nil {
 		return nil, err
 	}
 
@@ -49,16 +39,7 @@ func (tp *TemplateService) GetTemplateList() (templates []types.Template, err er
 func (tp *TemplateService) GetTemplate(ID string) (template *types.Template, err error) {
 	log.Debug("GetTemplate")
 
-	data, status, err := tp.concertoService.Get(fmt.Sprintf("/v1/blueprint/templates/%s", ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &template); err != nil {
+	if err = utils.GetJSON(tp.concertoService, fmt.Sprintf("/v1/blueprint/templates/%s", ID), &template); err != nil {
 		return nil, err
 	}
 
@@ -69,16 +50,7 @@ func (tp *TemplateService) GetTemplate(ID string) (template *types.Template, err
 func (tp *TemplateService) CreateTemplate(templateVector *map[string]interface{}) (template *types.Template, err error) {
 	log.Debug("CreateTemplate")
 
-	data, status, err := tp.concertoService.Post("/v1/blueprint/templates/", templateVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &template); err != nil {
+	if err = utils.PostJSON(tp.concertoService, "/v1/blueprint/templates/", templateVector, &template); err != nil {
 		return nil, err
 	}
 
@@ -89,16 +61,7 @@ func (tp *TemplateService) CreateTemplate(templateVector *map[string]interface{}
 func (tp *TemplateService) UpdateTemplate(templateVector *map[string]interface{}, ID string) (template *types.Template, err error) {
 	log.Debug("UpdateTemplate")
 
-	data, status, err := tp.concertoService.Put(fmt.Sprintf("/v1/blueprint/templates/%s", ID), templateVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &template); err != nil {
+	if err = utils.PutJSON(tp.concertoService, fmt.Sprintf("/v1/blueprint/templates/%s", ID), templateVector, &template); err != nil {
 		return nil, err
 	}
 
@@ -127,16 +90,7 @@ func (tp *TemplateService) DeleteTemplate(ID string) (err error) {
 func (tp *TemplateService) GetTemplateScriptList(templateID string, scriptType string) (templateScript *[]types.TemplateScript, err error) {
 	log.Debug("GetTemplateScriptList")
 
-	data, status, err := tp.concertoService.Get(fmt.Sprintf("/v1/blueprint/templates/%s/scripts?type=%s", templateID, scriptType))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &templateScript); err != nil {
+	if err = utils.GetJSON(tp.concertoService, fmt.Sprintf("/v1/blueprint/templates/%s/scripts?type=%s", templateID, scriptType), &templateScript); err != nil {
 		return nil, err
 	}
 
@@ -147,16 +101,7 @@ func (tp *TemplateService) GetTemplateScriptList(templateID string, scriptType s
 func (tp *TemplateService) GetTemplateScript(templateID string, ID string) (templateScript *types.TemplateScript, err error) {
 	log.Debug("GetTemplateScript")
 
-	data, status, err := tp.concertoService.Get(fmt.Sprintf("/v1/blueprint/templates/%s/scripts/%s", templateID, ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &templateScript); err != nil {
+	if err = utils.GetJSON(tp.concertoService, fmt.Sprintf("/v1/blueprint/templates/%s/scripts/%s", templateID, ID), &templateScript); err != nil {
 		return nil, err
 	}
 
@@ -167,16 +112,7 @@ func (tp *TemplateService) GetTemplateScript(templateID string, ID string) (temp
 func (tp *TemplateService) CreateTemplateScript(templateScriptVector *map[string]interface{}, templateID string) (templateScript *types.TemplateScript, err error) {
 	log.Debug("CreateTemplateScript")
 
-	data, status, err := tp.concertoService.Post(fmt.Sprintf("/v1/blueprint/templates/%s/scripts", templateID), templateScriptVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &templateScript); err != nil {
+	if err = utils.PostJSON(tp.concertoService, fmt.Sprintf("/v1/blueprint/templates/%s/scripts", templateID), templateScriptVector, &templateScript); err != nil {
 		return nil, err
 	}
 
@@ -187,16 +123,7 @@ func (tp *TemplateService) CreateTemplateScript(templateScriptVector *map[string
 func (tp *TemplateService) UpdateTemplateScript(templateScriptVector *map[string]interface{}, templateID string, ID string) (templateScript *types.TemplateScript, err error) {
 	log.Debug("UpdateTemplateScript")
 
-	data, status, err := tp.concertoService.Put(fmt.Sprintf("/v1/blueprint/templates/%s/scripts/%s", templateID, ID), templateScriptVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &templateScript); err != nil {
+	if err = utils.PutJSON(tp.concertoService, fmt.Sprintf("/v1/blueprint/templates/%s/scripts/%s", templateID, ID), templateScriptVector, &templateScript); err != nil {
 		return nil, err
 	}
 
@@ -223,16 +150,7 @@ func (tp *TemplateService) DeleteTemplateScript(templateID string, ID string) (e
 func (tp *TemplateService) ReorderTemplateScript(templateScriptVector *map[string]interface{}, templateID string) (templateScript *[]types.TemplateScript, err error) {
 	log.Debug("ReorderTemplateScript")
 
-	data, status, err := tp.concertoService.Put(fmt.Sprintf("/v1/blueprint/templates/%s/scripts/reorder", templateID), templateScriptVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &templateScript); err != nil {
+	if err = utils.PutJSON(tp.concertoService, fmt.Sprintf("/v1/blueprint/templates/%s/scripts/reorder", templateID), templateScriptVector, &templateScript); err != nil {
 		return nil, err
 	}
 
@@ -245,16 +163,7 @@ func (tp *TemplateService) ReorderTemplateScript(templateScriptVector *map[strin
 func (tp *TemplateService) GetTemplateServerList(templateID string) (templateServer *[]types.TemplateServer, err error) {
 	log.Debug("GetTemplateServersList")
 
-	data, status, err := tp.concertoService.Get(fmt.Sprintf("/v1/blueprint/templates/%s/servers", templateID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &templateServer); err != nil {
+	if err = utils.GetJSON(tp.concertoService, fmt.Sprintf("/v1/blueprint/templates/%s/servers", templateID), &templateServer); err != nil {
 		return nil, err
 	}
 
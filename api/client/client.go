@@ -0,0 +1,135 @@
+// Package client is a Go client of the Concerto API, for programs embedding it as well as for
+// the CLI. It groups the services of the api packages, whose methods return typed results:
+//
+//	c, err := client.New(&utils.Config{
+//		APIEndpoint: "https://clients.concerto.io:886/",
+//		Certificate: utils.Cert{Cert: "cert.crt", Key: "private/cert.key", Ca: "ca_cert.pem"},
+//	})
+//	templates, err := c.Templates.GetTemplateList()
+package client
+
+import (
+	"fmt"
+
+	"github.com/flexiant/concerto/api/admin"
+	"github.com/flexiant/concerto/api/audit"
+	"github.com/flexiant/concerto/api/blueprint"
+	"github.com/flexiant/concerto/api/cloud"
+	"github.com/flexiant/concerto/api/cluster"
+	"github.com/flexiant/concerto/api/dns"
+	"github.com/flexiant/concerto/api/licensee"
+	"github.com/flexiant/concerto/api/network"
+	"github.com/flexiant/concerto/api/node"
+	"github.com/flexiant/concerto/api/settings"
+	"github.com/flexiant/concerto/api/version"
+	"github.com/flexiant/concerto/api/wizard"
+	"github.com/flexiant/concerto/utils"
+)
+
+// Client sends requests to Concerto API
+type Client struct {
+	concertoService utils.ConcertoService
+
+	// blueprint
+	Templates *blueprint.TemplateService
+	Scripts   *blueprint.ScriptService
+	Services  *blueprint.ServicesService
+
+	// cloud
+	Servers        *cloud.ServerService
+	Workspaces     *cloud.WorkspaceService
+	ServerPlans    *cloud.ServerPlanService
+	GenericImages  *cloud.GenericImageService
+	CloudProviders *cloud.CloudProviderService
+	SaasProviders  *cloud.SaasProviderService
+	SSHProfiles    *cloud.SSHProfileService
+
+	// network
+	FirewallProfiles *network.FirewallProfileService
+	LoadBalancers    *network.LoadBalancerService
+
+	// kubernetes
+	Clusters *cluster.ClusterService
+	Nodes    *node.NodeService
+
+	// dns
+	Domains *dns.DomainService
+
+	// settings
+	CloudAccounts   *settings.CloudAccountService
+	SaasAccounts    *settings.SaasAccountService
+	SettingsReports *settings.SettingsReportService
+
+	// wizard
+	Apps              *wizard.AppService
+	Locations         *wizard.LocationService
+	WizCloudProviders *wizard.WizCloudProvidersService
+	WizServerPlans    *wizard.WizServerPlanService
+
+	// reports, audit and version
+	AdminReports    *admin.ReportService
+	LicenseeReports *licensee.LicenseeReportService
+	Events          *audit.EventService
+	Version         *version.VersionService
+}
+
+// New returns a client sending requests with the endpoint and certificates of config
+func New(config *utils.Config) (*Client, error) {
+	hcs, err := utils.NewHTTPConcertoService(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithService(hcs)
+}
+
+// Default returns a client of the configuration the CLI has loaded
+func Default() (*Client, error) {
+	config, err := utils.GetConcertoConfig()
+	if err != nil {
+		return nil, err
+	}
+	return New(config)
+}
+
+// NewWithService returns a client sending requests through concertoService, such as
+// utils.MockConcertoService in tests
+func NewWithService(concertoService utils.ConcertoService) (*Client, error) {
+	if concertoService == nil {
+		return nil, fmt.Errorf("Must initialize ConcertoService before using it")
+	}
+
+	c := &Client{concertoService: concertoService}
+	// services only fail when concertoService is nil
+	c.Templates, _ = blueprint.NewTemplateService(concertoService)
+	c.Scripts, _ = blueprint.NewScriptService(concertoService)
+	c.Services, _ = blueprint.NewServicesService(concertoService)
+	c.Servers, _ = cloud.NewServerService(concertoService)
+	c.Workspaces, _ = cloud.NewWorkspaceService(concertoService)
+	c.ServerPlans, _ = cloud.NewServerPlanService(concertoService)
+	c.GenericImages, _ = cloud.NewGenericImageService(concertoService)
+	c.CloudProviders, _ = cloud.NewCloudProviderService(concertoService)
+	c.SaasProviders, _ = cloud.NewSaasProviderService(concertoService)
+	c.SSHProfiles, _ = cloud.NewSSHProfileService(concertoService)
+	c.FirewallProfiles, _ = network.NewFirewallProfileService(concertoService)
+	c.LoadBalancers, _ = network.NewLoadBalancerService(concertoService)
+	c.Clusters, _ = cluster.NewClusterService(concertoService)
+	c.Nodes, _ = node.NewNodeService(concertoService)
+	c.Domains, _ = dns.NewDomainService(concertoService)
+	c.CloudAccounts, _ = settings.NewCloudAccountService(concertoService)
+	c.SaasAccounts, _ = settings.NewSaasAccountService(concertoService)
+	c.SettingsReports, _ = settings.NewSettingsReportService(concertoService)
+	c.Apps, _ = wizard.NewAppService(concertoService)
+	c.Locations, _ = wizard.NewLocationService(concertoService)
+	c.WizCloudProviders, _ = wizard.NewWizCloudProvidersService(concertoService)
+	c.WizServerPlans, _ = wizard.NewWizServerPlanService(concertoService)
+	c.AdminReports, _ = admin.NewReportService(concertoService)
+	c.LicenseeReports, _ = licensee.NewLicenseeReportService(concertoService)
+	c.Events, _ = audit.NewEventService(concertoService)
+	c.Version, _ = version.NewVersionService(concertoService)
+	return c, nil
+}
+
+// ConcertoService returns the service requests are sent through, for endpoints the client doesn't model
+func (c *Client) ConcertoService() utils.ConcertoService {
+	return c.concertoService
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/flexiant/concerto/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWithServiceNil(t *testing.T) {
+	c, err := NewWithService(nil)
+	assert.Nil(t, c, "Client shouldn't be created without a service")
+	assert.NotNil(t, err, "Expecting an error")
+}
+
+func TestNewWithService(t *testing.T) {
+	assert := assert.New(t)
+
+	cs := &utils.MockConcertoService{}
+	c, err := NewWithService(cs)
+	assert.Nil(err, "Couldn't create client")
+	assert.Equal(cs, c.ConcertoService(), "Client should send requests through the given service")
+	assert.NotNil(c.Templates, "Template service not instanced")
+	assert.NotNil(c.Servers, "Server service not instanced")
+	assert.NotNil(c.SSHProfiles, "SSH profile service not instanced")
+	assert.NotNil(c.Version, "Version service not instanced")
+}
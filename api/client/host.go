@@ -0,0 +1,95 @@
+package client
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+)
+
+// Endpoints of the host API, relative to the endpoint of host configurations
+const (
+	firewallProfileEndpoint   = "cloud/firewall_profile"
+	characterizationsEndpoint = "blueprint/script_characterizations?type=%s"
+	conclusionsEndpoint       = "blueprint/script_conclusions"
+	scriptRunsEndpoint        = "blueprint/script_runs"
+	pingsEndpoint             = "command_polling/pings"
+)
+
+// GetFirewallPolicy returns the firewall rules of the host. Md5 identifies the rules received
+func (c *Client) GetFirewallPolicy() (policy *types.HostFirewallPolicy, err error) {
+	log.Debug("GetFirewallPolicy")
+
+	// the rules are kept as received, so that the same rules get the same Md5
+	var data json.RawMessage
+	if err = utils.GetJSON(c.concertoService, firewallProfileEndpoint, &data); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &policy); err != nil {
+		return nil, err
+	}
+	policy.Md5 = fmt.Sprintf("%x", md5.Sum(data))
+
+	return policy, nil
+}
+
+// AddFirewallRule adds a rule to the firewall of the host
+func (c *Client) AddFirewallRule(rule types.HostFirewallRule) (err error) {
+	log.Debug("AddFirewallRule")
+
+	return utils.PostJSON(c.concertoService, fmt.Sprintf("%s/rules", firewallProfileEndpoint), &map[string]interface{}{"rule": rule}, nil)
+}
+
+// UpdateFirewallPolicy replaces the firewall rules of the host
+func (c *Client) UpdateFirewallPolicy(policy types.HostFirewallPolicy) (err error) {
+	log.Debug("UpdateFirewallPolicy")
+
+	return utils.PutJSON(c.concertoService, firewallProfileEndpoint, &map[string]interface{}{"firewall_profile": policy}, nil)
+}
+
+// GetScriptCharacterizations returns the scripts the host has to execute in phase, such as boot or shutdown
+func (c *Client) GetScriptCharacterizations(phase string) (scripts []types.ScriptCharacterization, err error) {
+	log.Debug("GetScriptCharacterizations")
+
+	if err = utils.GetJSON(c.concertoService, fmt.Sprintf(characterizationsEndpoint, phase), &scripts); err != nil {
+		return nil, err
+	}
+
+	return scripts, nil
+}
+
+// DownloadAttachment downloads a script attachment into directoryPath. It returns the file written
+func (c *Client) DownloadAttachment(path string, directoryPath string) (file string, err error) {
+	log.Debug("DownloadAttachment")
+
+	file, _, err = c.concertoService.GetFile(path, directoryPath)
+	if err != nil {
+		return "", err
+	}
+
+	return file, nil
+}
+
+// CreateScriptConclusion reports the result of a script characterization
+func (c *Client) CreateScriptConclusion(conclusion types.ScriptConclusion) (err error) {
+	log.Debug("CreateScriptConclusion")
+
+	return utils.PostJSON(c.concertoService, conclusionsEndpoint, &map[string]interface{}{"script_conclusion": conclusion}, nil)
+}
+
+// CreateScriptRun reports the result of a script run on demand
+func (c *Client) CreateScriptRun(run types.ScriptRun) (err error) {
+	log.Debug("CreateScriptRun")
+
+	return utils.PostJSON(c.concertoService, scriptRunsEndpoint, &map[string]interface{}{"script_run": run}, nil)
+}
+
+// RegisterHost tells the API that the agent of the host is running, and which version it is
+func (c *Client) RegisterHost(registration types.HostRegistration) (err error) {
+	log.Debug("RegisterHost")
+
+	return utils.PostJSON(c.concertoService, pingsEndpoint, &map[string]interface{}{"ping": registration}, nil)
+}
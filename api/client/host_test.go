@@ -0,0 +1,124 @@
+package client
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFirewallPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []byte(`{"rules":[{"cidr_ip":"0.0.0.0/0","ip_protocol":"tcp","min_port":22,"max_port":22}],"actual_rules":[]}`)
+	cs := &utils.MockConcertoService{}
+	cs.On("Get", "cloud/firewall_profile").Return(data, 200, nil)
+	c, err := NewWithService(cs)
+	assert.Nil(err, "Couldn't create client")
+
+	policy, err := c.GetFirewallPolicy()
+	assert.Nil(err, "Error getting firewall policy")
+	assert.Equal([]types.HostFirewallRule{{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 22, MaxPort: 22}}, policy.Rules, "Unexpected rules")
+	assert.Equal(fmt.Sprintf("%x", md5.Sum(data)), policy.Md5, "Md5 should identify the rules received")
+}
+
+func TestGetFirewallPolicyFailStatus(t *testing.T) {
+	cs := &utils.MockConcertoService{}
+	cs.On("Get", "cloud/firewall_profile").Return([]byte(`{"errors":{"base":["Forbidden"]}}`), 403, nil)
+	c, _ := NewWithService(cs)
+
+	policy, err := c.GetFirewallPolicy()
+	assert.NotNil(t, err, "We are expecting a status code error")
+	assert.Nil(t, policy, "Expecting nil output")
+}
+
+func TestAddFirewallRule(t *testing.T) {
+	rule := types.HostFirewallRule{Cidr: "10.0.0.0/8", Protocol: "udp", MinPort: 53, MaxPort: 53}
+	cs := &utils.MockConcertoService{}
+	cs.On("Post", "cloud/firewall_profile/rules", &map[string]interface{}{"rule": rule}).Return([]byte(`{}`), 201, nil)
+	c, _ := NewWithService(cs)
+
+	assert.Nil(t, c.AddFirewallRule(rule), "Error adding firewall rule")
+	cs.AssertExpectations(t)
+}
+
+func TestUpdateFirewallPolicy(t *testing.T) {
+	policy := types.HostFirewallPolicy{Rules: []types.HostFirewallRule{{Cidr: "10.0.0.0/8", Protocol: "tcp", MinPort: 80, MaxPort: 80}}}
+	cs := &utils.MockConcertoService{}
+	cs.On("Put", "cloud/firewall_profile", &map[string]interface{}{"firewall_profile": policy}).Return([]byte(`{}`), 200, nil)
+	c, _ := NewWithService(cs)
+
+	assert.Nil(t, c.UpdateFirewallPolicy(policy), "Error updating firewall policy")
+	cs.AssertExpectations(t)
+}
+
+func TestGetScriptCharacterizations(t *testing.T) {
+	assert := assert.New(t)
+
+	scriptsIn := []types.ScriptCharacterization{
+		{
+			Order:      1,
+			UUID:       "5630ed8fa6f9db6b84000001",
+			Script:     types.HostScript{Code: "echo $NAME", UUID: "5630ed8fa6f9db6b84000002", AttachmentPaths: []string{"/attachments/1"}},
+			Parameters: map[string]string{"NAME": "web"},
+		},
+	}
+	data, err := json.Marshal(scriptsIn)
+	assert.Nil(err, "Script characterization test data corrupted")
+
+	cs := &utils.MockConcertoService{}
+	cs.On("Get", "blueprint/script_characterizations?type=boot").Return(data, 200, nil)
+	c, _ := NewWithService(cs)
+
+	scriptsOut, err := c.GetScriptCharacterizations("boot")
+	assert.Nil(err, "Error getting script characterizations")
+	assert.Equal(scriptsIn, scriptsOut, "GetScriptCharacterizations returned different outputs")
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	cs := &utils.MockConcertoService{}
+	cs.On("GetFile", "/attachments/1", "/tmp/attachments").Return("/tmp/attachments/setup.sh", 200, nil)
+	cs.On("GetFile", "/attachments/2", "/tmp/attachments").Return("", 404, nil)
+	c, _ := NewWithService(cs)
+
+	file, err := c.DownloadAttachment("/attachments/1", "/tmp/attachments")
+	assert.Nil(t, err, "Error downloading attachment")
+	assert.Equal(t, "/tmp/attachments/setup.sh", file, "Unexpected file")
+
+	_, err = c.DownloadAttachment("/attachments/2", "/tmp/attachments")
+	assert.NotNil(t, err, "We are expecting a status code error")
+}
+
+func TestCreateScriptConclusion(t *testing.T) {
+	conclusion := types.ScriptConclusion{UUID: "5630ed8fa6f9db6b84000001", Output: "web", ExitCode: 0}
+	cs := &utils.MockConcertoService{}
+	cs.On("Post", "blueprint/script_conclusions", &map[string]interface{}{"script_conclusion": conclusion}).Return([]byte(`{}`), 201, nil)
+	c, _ := NewWithService(cs)
+
+	assert.Nil(t, c.CreateScriptConclusion(conclusion), "Error creating script conclusion")
+	cs.AssertExpectations(t)
+}
+
+func TestCreateScriptRun(t *testing.T) {
+	run := types.ScriptRun{ScriptID: "5630ed8fa6f9db6b84000002", Output: "done", ExitCode: 1}
+	cs := &utils.MockConcertoService{}
+	cs.On("Post", "blueprint/script_runs", &map[string]interface{}{"script_run": run}).Return([]byte(`{}`), 201, nil)
+	c, _ := NewWithService(cs)
+
+	assert.Nil(t, c.CreateScriptRun(run), "Error creating script run")
+	cs.AssertExpectations(t)
+}
+
+func TestRegisterHost(t *testing.T) {
+	registration := types.HostRegistration{Hostname: "web-1", OS: "linux", Arch: "amd64", Version: "0.9.0"}
+	cs := &utils.MockConcertoService{}
+	cs.On("Post", "command_polling/pings", &map[string]interface{}{"ping": registration}).Return([]byte(`{}`), 201, nil)
+	c, _ := NewWithService(cs)
+
+	assert.Nil(t, c.RegisterHost(registration), "Error registering host")
+	cs.AssertExpectations(t)
+}
@@ -0,0 +1,21 @@
+package client
+
+import (
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+)
+
+// ExecuteServerScript executes an operational script on a server. Unlike ServerService.ExecuteOperationalScript,
+// it returns the event the execution is recorded as
+func (c *Client) ExecuteServerScript(serverID string, scriptID string) (event *types.Event, err error) {
+	log.Debug("ExecuteServerScript")
+
+	if err = utils.PutJSON(c.concertoService, fmt.Sprintf("/v1/cloud/servers/%s/operational_scripts/%s/execute", serverID, scriptID), nil, &event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
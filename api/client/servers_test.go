@@ -0,0 +1,40 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/flexiant/concerto/testdata"
+	"github.com/flexiant/concerto/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteServerScript(t *testing.T) {
+	assert := assert.New(t)
+
+	eventsIn := testdata.GetEventData()
+	for _, eventIn := range *eventsIn {
+		data, err := json.Marshal(eventIn)
+		assert.Nil(err, "Event test data corrupted")
+
+		cs := &utils.MockConcertoService{}
+		cs.On("Put", "/v1/cloud/servers/s1/operational_scripts/sc1/execute", (*map[string]interface{})(nil)).Return(data, 200, nil)
+		c, _ := NewWithService(cs)
+
+		eventOut, err := c.ExecuteServerScript("s1", "sc1")
+		assert.Nil(err, "Error executing server script")
+		assert.Equal(eventIn.Id, eventOut.Id, "ExecuteServerScript returned a different event")
+	}
+}
+
+func TestExecuteServerScriptFailErr(t *testing.T) {
+	cs := &utils.MockConcertoService{}
+	cs.On("Put", "/v1/cloud/servers/s1/operational_scripts/sc1/execute", (*map[string]interface{})(nil)).Return([]byte{}, 0, fmt.Errorf("Mocked error"))
+	c, _ := NewWithService(cs)
+
+	eventOut, err := c.ExecuteServerScript("s1", "sc1")
+	assert.NotNil(t, err, "We are expecting an error")
+	assert.Nil(t, eventOut, "Expecting nil output")
+	assert.Equal(t, "Mocked error", err.Error(), "Error should be 'Mocked error'")
+}
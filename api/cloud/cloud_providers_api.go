@@ -1,7 +1,6 @@
 package cloud
 
 import (
-	"encoding/json"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
@@ -28,18 +27,65 @@ func NewCloudProviderService(concertoService utils.ConcertoService) (*CloudProvi
 func (cl *CloudProviderService) GetCloudProviderList() (cloudProviders []types.CloudProvider, err error) {
 	log.Debug("GetCloudProviderList")
 
-	data, status, err := cl.concertoService.Get("/v1/cloud/cloud_providers")
-	if err != nil {
+	if err = utils.GetJSON(cl.concertoService, "/v1/cloud/cloud_providers", &cloudProviders); err != nil {
 		return nil, err
 	}
 
-	if err = utils.CheckStandardStatus(status, data); err != nil {
+	return cloudProviders, nil
+}
+
+// GetCloudProvider returns a cloudProvider by its ID
+func (cl *CloudProviderService) GetCloudProvider(ID string) (cloudProvider *types.CloudProvider, err error) {
+	log.Debug("GetCloudProvider")
+
+	if err = utils.GetJSON(cl.concertoService, fmt.Sprintf("/v1/cloud/cloud_providers/%s", ID), &cloudProvider); err != nil {
 		return nil, err
 	}
 
-	if err = json.Unmarshal(data, &cloudProviders); err != nil {
+	return cloudProvider, nil
+}
+
+// GetCloudProviderDetails returns a cloudProvider by its ID, with its server plans and the regions where they
+// deploy servers, in the order of the plans
+func (cl *CloudProviderService) GetCloudProviderDetails(ID string) (details *types.CloudProviderDetails, err error) {
+	log.Debug("GetCloudProviderDetails")
+
+	cloudProvider, err := cl.GetCloudProvider(ID)
+	if err != nil {
+		return nil, err
+	}
+	var serverPlans []types.ServerPlan
+	if err = utils.GetJSON(cl.concertoService, fmt.Sprintf("/v1/cloud/cloud_providers/%s/server_plans", ID), &serverPlans); err != nil {
+		return nil, err
+	}
+	var locations []types.Location
+	if err = utils.GetJSON(cl.concertoService, "/v1/wizard/locations", &locations); err != nil {
 		return nil, err
 	}
 
-	return cloudProviders, nil
+	names := make(map[string]string)
+	for _, l := range locations {
+		names[l.Id] = l.Name
+	}
+	details = &types.CloudProviderDetails{
+		Id:                  cloudProvider.Id,
+		Name:                cloudProvider.Name,
+		RequiredCredentials: cloudProvider.RequiredCredentials,
+		ProvidedServices:    cloudProvider.ProvidedServices,
+		Regions:             []types.Location{},
+		ServerPlans:         serverPlans,
+	}
+	seen := make(map[string]bool)
+	for _, p := range serverPlans {
+		if p.LocationId == "" || seen[p.LocationId] {
+			continue
+		}
+		seen[p.LocationId] = true
+		details.Regions = append(details.Regions, types.Location{Id: p.LocationId, Name: names[p.LocationId]})
+	}
+	if details.ServerPlans == nil {
+		details.ServerPlans = []types.ServerPlan{}
+	}
+
+	return details, nil
 }
@@ -109,3 +109,85 @@ func GetCloudProviderListFailJSONMocked(t *testing.T, cloudProvidersIn *[]types.
 
 	return &cloudProvidersOut
 }
+
+// GetCloudProviderMocked test mocked function
+func GetCloudProviderMocked(t *testing.T, cloudProvider *types.CloudProvider) *types.CloudProvider {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewCloudProviderService(cs)
+	assert.Nil(err, "Couldn't load cloudProvider service")
+	assert.NotNil(ds, "CloudProvider service not instanced")
+
+	// to json
+	dIn, err := json.Marshal(cloudProvider)
+	assert.Nil(err, "CloudProvider test data corrupted")
+
+	// call service
+	cs.On("Get", fmt.Sprintf("/v1/cloud/cloud_providers/%s", cloudProvider.Id)).Return(dIn, 200, nil)
+	cloudProviderOut, err := ds.GetCloudProvider(cloudProvider.Id)
+	assert.Nil(err, "Error getting cloudProvider")
+	assert.Equal(*cloudProvider, *cloudProviderOut, "GetCloudProvider returned different cloudProviders")
+
+	return cloudProviderOut
+}
+
+// GetCloudProviderFailErrMocked test mocked function
+func GetCloudProviderFailErrMocked(t *testing.T, cloudProvider *types.CloudProvider) *types.CloudProvider {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewCloudProviderService(cs)
+	assert.Nil(err, "Couldn't load cloudProvider service")
+	assert.NotNil(ds, "CloudProvider service not instanced")
+
+	// to json
+	dIn, err := json.Marshal(cloudProvider)
+	assert.Nil(err, "CloudProvider test data corrupted")
+
+	// call service
+	cs.On("Get", fmt.Sprintf("/v1/cloud/cloud_providers/%s", cloudProvider.Id)).Return(dIn, 200, fmt.Errorf("Mocked error"))
+	cloudProviderOut, err := ds.GetCloudProvider(cloudProvider.Id)
+
+	assert.NotNil(err, "We are expecting an error")
+	assert.Nil(cloudProviderOut, "Expecting nil output")
+	assert.Equal(err.Error(), "Mocked error", "Error should be 'Mocked error'")
+
+	return cloudProviderOut
+}
+
+// GetCloudProviderDetailsMocked test mocked function
+func GetCloudProviderDetailsMocked(t *testing.T, cloudProvider *types.CloudProvider, serverPlans *[]types.ServerPlan, locations *[]types.Location) *types.CloudProviderDetails {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewCloudProviderService(cs)
+	assert.Nil(err, "Couldn't load cloudProvider service")
+	assert.NotNil(ds, "CloudProvider service not instanced")
+
+	// to json
+	dIn, err := json.Marshal(cloudProvider)
+	assert.Nil(err, "CloudProvider test data corrupted")
+	pIn, err := json.Marshal(serverPlans)
+	assert.Nil(err, "ServerPlan test data corrupted")
+	lIn, err := json.Marshal(locations)
+	assert.Nil(err, "Location test data corrupted")
+
+	// call service
+	cs.On("Get", fmt.Sprintf("/v1/cloud/cloud_providers/%s", cloudProvider.Id)).Return(dIn, 200, nil)
+	cs.On("Get", fmt.Sprintf("/v1/cloud/cloud_providers/%s/server_plans", cloudProvider.Id)).Return(pIn, 200, nil)
+	cs.On("Get", "/v1/wizard/locations").Return(lIn, 200, nil)
+	detailsOut, err := ds.GetCloudProviderDetails(cloudProvider.Id)
+	assert.Nil(err, "Error getting cloudProvider details")
+	assert.Equal(cloudProvider.Id, detailsOut.Id, "GetCloudProviderDetails returned a different cloudProvider")
+	assert.Equal(cloudProvider.RequiredCredentials, detailsOut.RequiredCredentials, "GetCloudProviderDetails returned different credentials")
+	assert.Equal(*serverPlans, detailsOut.ServerPlans, "GetCloudProviderDetails returned different serverPlans")
+
+	return detailsOut
+}
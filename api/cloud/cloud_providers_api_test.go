@@ -1,6 +1,7 @@
 package cloud
 
 import (
+	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/testdata"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -20,3 +21,26 @@ func TestGetCloudProviderList(t *testing.T) {
 	GetCloudProviderListFailStatusMocked(t, cloudProvidersIn)
 	GetCloudProviderListFailJSONMocked(t, cloudProvidersIn)
 }
+
+func TestGetCloudProvider(t *testing.T) {
+	cloudProvidersIn := testdata.GetCloudProviderData()
+	for _, cloudProviderIn := range *cloudProvidersIn {
+		GetCloudProviderMocked(t, &cloudProviderIn)
+		GetCloudProviderFailErrMocked(t, &cloudProviderIn)
+	}
+}
+
+func TestGetCloudProviderDetails(t *testing.T) {
+	assert := assert.New(t)
+	cloudProviderIn := (*testdata.GetCloudProviderData())[0]
+	serverPlansIn := testdata.GetServerPlanData()
+	(*serverPlansIn)[1].LocationId = (*serverPlansIn)[0].LocationId
+	locationsIn := []types.Location{{Id: (*serverPlansIn)[0].LocationId, Name: "Europe"}}
+
+	details := GetCloudProviderDetailsMocked(t, &cloudProviderIn, serverPlansIn, &locationsIn)
+	assert.Equal(locationsIn, details.Regions, "Regions weren't listed once each, with their names")
+
+	details = GetCloudProviderDetailsMocked(t, &cloudProviderIn, &[]types.ServerPlan{}, &[]types.Location{})
+	assert.Empty(details.Regions, "Regions were listed without server plans")
+	assert.NotNil(details.ServerPlans, "Server plans should be an empty list")
+}
@@ -1,8 +1,10 @@
 package cloud
 
 import (
-	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
+
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/utils"
@@ -28,18 +30,145 @@ func NewGenericImageService(concertoService utils.ConcertoService) (*GenericImag
 func (cl *GenericImageService) GetGenericImageList() (genericImages []types.GenericImage, err error) {
 	log.Debug("GetGenericImageList")
 
-	data, status, err := cl.concertoService.Get("/v1/cloud/generic_images")
-	if err != nil {
+	if err = utils.GetJSON(cl.concertoService, "/v1/cloud/generic_images", &genericImages); err != nil {
 		return nil, err
 	}
+	for i := range genericImages {
+		describeGenericImage(&genericImages[i])
+	}
 
-	if err = utils.CheckStandardStatus(status, data); err != nil {
+	return genericImages, nil
+}
+
+// FindGenericImages returns the genericImages of an OS family, version and architecture. Empty values
+// match every image, versions match their minor ones, such as 14 does 14.04, and architectures match
+// their aliases, such as amd64 does x86_64
+func (cl *GenericImageService) FindGenericImages(osFamily string, osVersion string, architecture string) (genericImages []types.GenericImage, err error) {
+	log.Debug("FindGenericImages")
+
+	images, err := cl.GetGenericImageList()
+	if err != nil {
 		return nil, err
 	}
+	genericImages = []types.GenericImage{}
+	for _, gi := range images {
+		if osFamily != "" && !strings.EqualFold(gi.OSFamily, osFamily) {
+			continue
+		}
+		if osVersion != "" && !matchesVersion(gi.OSVersion, osVersion) {
+			continue
+		}
+		if architecture != "" && canonicalArchitecture(gi.Architecture) != canonicalArchitecture(architecture) {
+			continue
+		}
+		genericImages = append(genericImages, gi)
+	}
+	return genericImages, nil
+}
+
+// GetGenericImage returns the genericImage given by its ID, its name regardless of case, or the words
+// of a single name, such as "ubuntu 14.04"
+func (cl *GenericImageService) GetGenericImage(image string) (genericImage *types.GenericImage, err error) {
+	log.Debug("GetGenericImage")
 
-	if err = json.Unmarshal(data, &genericImages); err != nil {
+	images, err := cl.GetGenericImageList()
+	if err != nil {
 		return nil, err
 	}
+	for i, gi := range images {
+		if gi.Id == image || strings.EqualFold(gi.Name, image) {
+			return &images[i], nil
+		}
+	}
+	var matches []string
+	for i, gi := range images {
+		if containsWords(gi.Name, image) {
+			genericImage = &images[i]
+			matches = append(matches, fmt.Sprintf("%q", gi.Name))
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("Generic image %s not found", image)
+	case 1:
+		return genericImage, nil
+	}
+	return nil, fmt.Errorf("Generic image %s is ambiguous, as it matches %s", image, strings.Join(matches, ", "))
+}
 
-	return genericImages, nil
+// osFamilies are the names of the OS families of generic images, by the prefix of their names
+var osFamilies = []struct{ prefix, family string }{
+	{"red hat enterprise linux", "rhel"},
+	{"amazon linux", "amazon"},
+}
+
+var (
+	architectureRegexp = regexp.MustCompile(`(?i)^(x86_64|amd64|i386|i686|x86|arm64|aarch64|armhf)$`)
+	versionRegexp      = regexp.MustCompile(`^\d[\w.]*$`)
+	// releaseRegexp matches the release following some versions, such as the R2 of Windows 2012 R2
+	releaseRegexp = regexp.MustCompile(`^(?i)(R\d+|SP\d+)$`)
+)
+
+// describeGenericImage takes the OS family, version and architecture not given by Concerto from the
+// name of genericImage, such as "Ubuntu 14.04 Trusty Tahr x86_64"
+func describeGenericImage(genericImage *types.GenericImage) {
+	words := strings.Fields(genericImage.Name)
+	if len(words) == 0 {
+		return
+	}
+	if genericImage.Architecture == "" && architectureRegexp.MatchString(words[len(words)-1]) {
+		genericImage.Architecture = words[len(words)-1]
+	}
+	if genericImage.OSFamily == "" {
+		genericImage.OSFamily = strings.ToLower(words[0])
+		name := strings.ToLower(genericImage.Name)
+		for _, f := range osFamilies {
+			if strings.HasPrefix(name, f.prefix) {
+				genericImage.OSFamily = f.family
+				break
+			}
+		}
+	}
+	if genericImage.OSVersion == "" {
+		for i, word := range words[1:] {
+			if versionRegexp.MatchString(word) {
+				genericImage.OSVersion = word
+				if i+2 < len(words) && releaseRegexp.MatchString(words[i+2]) {
+					genericImage.OSVersion += " " + words[i+2]
+				}
+				break
+			}
+		}
+	}
+}
+
+func matchesVersion(version string, wanted string) bool {
+	version, wanted = strings.ToLower(version), strings.ToLower(wanted)
+	return version == wanted || strings.HasPrefix(version, wanted+".") || strings.HasPrefix(version, wanted+" ")
+}
+
+func canonicalArchitecture(architecture string) string {
+	switch architecture = strings.ToLower(architecture); architecture {
+	case "amd64":
+		return "x86_64"
+	case "aarch64":
+		return "arm64"
+	case "i686", "x86":
+		return "i386"
+	}
+	return architecture
+}
+
+// containsWords returns whether name has every word of words, regardless of case
+func containsWords(name string, words string) bool {
+	nameWords := make(map[string]bool)
+	for _, w := range strings.Fields(strings.ToLower(name)) {
+		nameWords[w] = true
+	}
+	for _, w := range strings.Fields(strings.ToLower(words)) {
+		if !nameWords[w] {
+			return false
+		}
+	}
+	return strings.TrimSpace(words) != ""
 }
@@ -1,7 +1,10 @@
 package cloud
 
 import (
+	"encoding/json"
+	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/testdata"
+	"github.com/flexiant/concerto/utils"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -20,3 +23,81 @@ func TestGetGenericImageList(t *testing.T) {
 	GetGenericImageListFailStatusMocked(t, genericImagesIn)
 	GetGenericImageListFailJSONMocked(t, genericImagesIn)
 }
+
+func TestDescribeGenericImage(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name, family, version, architecture string
+	}{
+		{"Ubuntu 14.04 Trusty Tahr x86_64", "ubuntu", "14.04", "x86_64"},
+		{"Red Hat Enterprise Linux 6 x86_64", "rhel", "6", "x86_64"},
+		{"CentOS 5 x86_64", "centos", "5", "x86_64"},
+		{"Windows 2008 R2 - SP1 x86_64", "windows", "2008 R2", "x86_64"},
+		{"SmartOS x86_64", "smartos", "", "x86_64"},
+		{"Debian 9 arm64", "debian", "9", "arm64"},
+	}
+	for _, test := range tests {
+		gi := types.GenericImage{Name: test.name}
+		describeGenericImage(&gi)
+		assert.Equal(types.GenericImage{Name: test.name, OSFamily: test.family, OSVersion: test.version, Architecture: test.architecture}, gi, test.name)
+	}
+
+	gi := types.GenericImage{Name: "Ubuntu 14.04 x86_64", OSFamily: "linux"}
+	describeGenericImage(&gi)
+	assert.Equal("linux", gi.OSFamily, "OS family given by Concerto was replaced")
+}
+
+func TestFindGenericImages(t *testing.T) {
+	assert := assert.New(t)
+	genericImagesIn := []types.GenericImage{
+		{Id: "fakeID0", Name: "Ubuntu 14.04 Trusty Tahr x86_64"},
+		{Id: "fakeID1", Name: "Ubuntu 16.04 Xenial Xerus x86_64"},
+		{Id: "fakeID2", Name: "Windows 2012 R2 x86_64"},
+	}
+	cs := &utils.MockConcertoService{}
+	ds, _ := NewGenericImageService(cs)
+	dIn, _ := json.Marshal(genericImagesIn)
+	cs.On("Get", "/v1/cloud/generic_images").Return(dIn, 200, nil)
+
+	ids := func(images []types.GenericImage) []string {
+		var ids []string
+		for _, gi := range images {
+			ids = append(ids, gi.Id)
+		}
+		return ids
+	}
+	images, err := ds.FindGenericImages("Ubuntu", "", "")
+	assert.Nil(err, "Error finding generic images")
+	assert.Equal([]string{"fakeID0", "fakeID1"}, ids(images), "Images weren't filtered by OS family")
+	images, _ = ds.FindGenericImages("ubuntu", "16", "amd64")
+	assert.Equal([]string{"fakeID1"}, ids(images), "Images weren't filtered by version and architecture")
+	images, _ = ds.FindGenericImages("", "2012", "")
+	assert.Equal([]string{"fakeID2"}, ids(images), "Version didn't match its release")
+	images, _ = ds.FindGenericImages("", "", "arm64")
+	assert.Empty(images, "Images weren't filtered by architecture")
+}
+
+func TestGetGenericImage(t *testing.T) {
+	assert := assert.New(t)
+	genericImagesIn := []types.GenericImage{
+		{Id: "fakeID0", Name: "Ubuntu 14.04 Trusty Tahr x86_64"},
+		{Id: "fakeID1", Name: "Ubuntu 16.04 Xenial Xerus x86_64"},
+	}
+	cs := &utils.MockConcertoService{}
+	ds, _ := NewGenericImageService(cs)
+	dIn, _ := json.Marshal(genericImagesIn)
+	cs.On("Get", "/v1/cloud/generic_images").Return(dIn, 200, nil)
+
+	gi, err := ds.GetGenericImage("fakeID1")
+	assert.Nil(err, "Error getting generic image by ID")
+	assert.Equal("fakeID1", gi.Id, "Image wasn't matched by ID")
+	gi, _ = ds.GetGenericImage("ubuntu 14.04 trusty tahr x86_64")
+	assert.Equal("fakeID0", gi.Id, "Image wasn't matched by name")
+	gi, _ = ds.GetGenericImage("Ubuntu 16.04")
+	assert.Equal("fakeID1", gi.Id, "Image wasn't matched by the words of its name")
+
+	_, err = ds.GetGenericImage("ubuntu")
+	assert.EqualError(err, `Generic image ubuntu is ambiguous, as it matches "Ubuntu 14.04 Trusty Tahr x86_64", "Ubuntu 16.04 Xenial Xerus x86_64"`)
+	_, err = ds.GetGenericImage("Debian 9")
+	assert.EqualError(err, "Generic image Debian 9 not found")
+}
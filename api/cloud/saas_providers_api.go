@@ -1,7 +1,6 @@
 package cloud
 
 import (
-	"encoding/json"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
@@ -28,16 +27,7 @@ func NewSaasProviderService(concertoService utils.ConcertoService) (*SaasProvide
 func (cl *SaasProviderService) GetSaasProviderList() (saasProviders []types.SaasProvider, err error) {
 	log.Debug("GetSaasProviderList")
 
-	data, status, err := cl.concertoService.Get("/v1/cloud/saas_providers")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &saasProviders); err != nil {
+	if err = utils.GetJSON(cl.concertoService, "/v1/cloud/saas_providers", &saasProviders); err != nil {
 		return nil, err
 	}
 
@@ -1,8 +1,9 @@
 package cloud
 
 import (
-	"encoding/json"
 	"fmt"
+	"strings"
+
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/utils"
@@ -28,19 +29,43 @@ func NewServerPlanService(concertoService utils.ConcertoService) (*ServerPlanSer
 func (dm *ServerPlanService) GetServerPlanList(ProviderID string) (serverPlans []types.ServerPlan, err error) {
 	log.Debug("GetServerPlanList")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/cloud/cloud_providers/%s/server_plans", ProviderID))
-	if err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/cloud_providers/%s/server_plans", ProviderID), &serverPlans); err != nil {
 		return nil, err
 	}
 
-	if err = utils.CheckStandardStatus(status, data); err != nil {
+	return serverPlans, nil
+}
+
+// GetServerPlanListByLocation returns the serverPlans of a cloud provider in a location, given by its ID or
+// its name regardless of case
+func (dm *ServerPlanService) GetServerPlanListByLocation(ProviderID string, location string) (serverPlans []types.ServerPlan, err error) {
+	log.Debug("GetServerPlanListByLocation")
+
+	var locations []types.Location
+	if err = utils.GetJSON(dm.concertoService, "/v1/wizard/locations", &locations); err != nil {
 		return nil, err
 	}
+	locationID := ""
+	for _, l := range locations {
+		if l.Id == location || strings.EqualFold(l.Name, location) {
+			locationID = l.Id
+			break
+		}
+	}
+	if locationID == "" {
+		return nil, fmt.Errorf("Location %s not found", location)
+	}
 
-	if err = json.Unmarshal(data, &serverPlans); err != nil {
+	plans, err := dm.GetServerPlanList(ProviderID)
+	if err != nil {
 		return nil, err
 	}
-
+	serverPlans = []types.ServerPlan{}
+	for _, p := range plans {
+		if p.LocationId == locationID {
+			serverPlans = append(serverPlans, p)
+		}
+	}
 	return serverPlans, nil
 }
 
@@ -48,16 +73,7 @@ func (dm *ServerPlanService) GetServerPlanList(ProviderID string) (serverPlans [
 func (dm *ServerPlanService) GetServerPlan(ID string) (serverPlan *types.ServerPlan, err error) {
 	log.Debug("GetServerPlan")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/cloud/server_plans/%s", ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &serverPlan); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/server_plans/%s", ID), &serverPlan); err != nil {
 		return nil, err
 	}
 
@@ -35,6 +35,32 @@ func GetServerPlanListMocked(t *testing.T, serverPlansIn *[]types.ServerPlan, cl
 	return &serverPlansOut
 }
 
+// GetServerPlanListByLocationMocked test mocked function
+func GetServerPlanListByLocationMocked(t *testing.T, serverPlansIn *[]types.ServerPlan, locationsIn *[]types.Location, cloudProviderId string, location string) []types.ServerPlan {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewServerPlanService(cs)
+	assert.Nil(err, "Couldn't load serverPlan service")
+	assert.NotNil(ds, "ServerPlan service not instanced")
+
+	// to json
+	dIn, err := json.Marshal(serverPlansIn)
+	assert.Nil(err, "ServerPlan test data corrupted")
+	lIn, err := json.Marshal(locationsIn)
+	assert.Nil(err, "Location test data corrupted")
+
+	// call service
+	cs.On("Get", "/v1/wizard/locations").Return(lIn, 200, nil)
+	cs.On("Get", fmt.Sprintf("/v1/cloud/cloud_providers/%s/server_plans", cloudProviderId)).Return(dIn, 200, nil)
+	serverPlansOut, err := ds.GetServerPlanListByLocation(cloudProviderId, location)
+	assert.Nil(err, "Error getting serverPlan list by location")
+
+	return serverPlansOut
+}
+
 // GetServerPlanListFailErrMocked test mocked function
 func GetServerPlanListFailErrMocked(t *testing.T, serverPlansIn *[]types.ServerPlan, cloudProviderId string) *[]types.ServerPlan {
 
@@ -1,9 +1,13 @@
 package cloud
 
 import (
+	"encoding/json"
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/testdata"
+	"github.com/flexiant/concerto/utils"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 func TestNewServerPlanServiceNil(t *testing.T) {
@@ -32,3 +36,21 @@ func TestGetServerPlan(t *testing.T) {
 		GetServerPlanFailJSONMocked(t, &serverPlanIn)
 	}
 }
+
+func TestGetServerPlanListByLocation(t *testing.T) {
+	assert := assert.New(t)
+	serverPlansIn := testdata.GetServerPlanData()
+	locationsIn := &[]types.Location{{Id: "fakeLocationID0", Name: "London 1"}, {Id: "fakeLocationID1", Name: "Amsterdam 2"}}
+
+	serverPlans := GetServerPlanListByLocationMocked(t, serverPlansIn, locationsIn, "fakeCloudProviderID0", "london 1")
+	assert.Equal([]types.ServerPlan{(*serverPlansIn)[0]}, serverPlans, "Location wasn't matched by name")
+	serverPlans = GetServerPlanListByLocationMocked(t, serverPlansIn, locationsIn, "fakeCloudProviderID0", "fakeLocationID1")
+	assert.Equal([]types.ServerPlan{(*serverPlansIn)[1]}, serverPlans, "Location wasn't matched by ID")
+
+	cs := &utils.MockConcertoService{}
+	ds, _ := NewServerPlanService(cs)
+	lIn, _ := json.Marshal(locationsIn)
+	cs.On("Get", "/v1/wizard/locations").Return(lIn, 200, nil)
+	_, err := ds.GetServerPlanListByLocation("fakeCloudProviderID0", "Paris")
+	assert.EqualError(err, "Location Paris not found")
+}
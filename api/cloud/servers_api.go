@@ -1,7 +1,6 @@
 package cloud
 
 import (
-	"encoding/json"
 	"fmt"
 
 	log "github.com/Sirupsen/logrus"
@@ -29,16 +28,7 @@ func NewServerService(concertoService utils.ConcertoService) (*ServerService, er
 func (dm *ServerService) GetServerList() (servers []types.Server, err error) {
 	log.Debug("GetServerList")
 
-	data, status, err := dm.concertoService.Get("/v1/cloud/servers")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &servers); err != nil {
+	if err = utils.GetJSON(dm.concertoService, "/v1/cloud/servers", &servers); err != nil {
 		return nil, err
 	}
 
@@ -49,16 +39,7 @@ func (dm *ServerService) GetServerList() (servers []types.Server, err error) {
 func (dm *ServerService) GetServer(ID string) (server *types.Server, err error) {
 	log.Debug("GetServer")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/cloud/servers/%s", ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &server); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/servers/%s", ID), &server); err != nil {
 		return nil, err
 	}
 
@@ -69,16 +50,7 @@ func (dm *ServerService) GetServer(ID string) (server *types.Server, err error)
 func (dm *ServerService) CreateServer(serverVector *map[string]interface{}) (server *types.Server, err error) {
 	log.Debug("CreateServer")
 
-	data, status, err := dm.concertoService.Post("/v1/cloud/servers/", serverVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &server); err != nil {
+	if err = utils.PostJSON(dm.concertoService, "/v1/cloud/servers/", serverVector, &server); err != nil {
 		return nil, err
 	}
 
@@ -89,16 +61,7 @@ func (dm *ServerService) CreateServer(serverVector *map[string]interface{}) (ser
 func (dm *ServerService) UpdateServer(serverVector *map[string]interface{}, ID string) (server *types.Server, err error) {
 	log.Debug("UpdateServer")
 
-	data, status, err := dm.concertoService.Put(fmt.Sprintf("/v1/cloud/servers/%s", ID), serverVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &server); err != nil {
+	if err = utils.PutJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/servers/%s", ID), serverVector, &server); err != nil {
 		return nil, err
 	}
 
@@ -109,16 +72,7 @@ func (dm *ServerService) UpdateServer(serverVector *map[string]interface{}, ID s
 func (dm *ServerService) BootServer(serverVector *map[string]interface{}, ID string) (server *types.Server, err error) {
 	log.Debug("BootServer")
 
-	data, status, err := dm.concertoService.Put(fmt.Sprintf("/v1/cloud/servers/%s/boot", ID), serverVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &server); err != nil {
+	if err = utils.PutJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/servers/%s/boot", ID), serverVector, &server); err != nil {
 		return nil, err
 	}
 
@@ -129,16 +83,7 @@ func (dm *ServerService) BootServer(serverVector *map[string]interface{}, ID str
 func (dm *ServerService) RebootServer(serverVector *map[string]interface{}, ID string) (server *types.Server, err error) {
 	log.Debug("RebootServer")
 
-	data, status, err := dm.concertoService.Put(fmt.Sprintf("/v1/cloud/servers/%s/reboot", ID), serverVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &server); err != nil {
+	if err = utils.PutJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/servers/%s/reboot", ID), serverVector, &server); err != nil {
 		return nil, err
 	}
 
@@ -149,16 +94,7 @@ func (dm *ServerService) RebootServer(serverVector *map[string]interface{}, ID s
 func (dm *ServerService) ShutdownServer(serverVector *map[string]interface{}, ID string) (server *types.Server, err error) {
 	log.Debug("ShutdownServer")
 
-	data, status, err := dm.concertoService.Put(fmt.Sprintf("/v1/cloud/servers/%s/shutdown", ID), serverVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &server); err != nil {
+	if err = utils.PutJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/servers/%s/shutdown", ID), serverVector, &server); err != nil {
 		return nil, err
 	}
 
@@ -169,16 +105,7 @@ func (dm *ServerService) ShutdownServer(serverVector *map[string]interface{}, ID
 func (dm *ServerService) OverrideServer(serverVector *map[string]interface{}, ID string) (server *types.Server, err error) {
 	log.Debug("OverrideServer")
 
-	data, status, err := dm.concertoService.Put(fmt.Sprintf("/v1/cloud/servers/%s/override", ID), serverVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &server); err != nil {
+	if err = utils.PutJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/servers/%s/override", ID), serverVector, &server); err != nil {
 		return nil, err
 	}
 
@@ -206,16 +133,7 @@ func (dm *ServerService) DeleteServer(ID string) (err error) {
 func (dm *ServerService) GetDNSList(serverID string) (dns []types.Dns, err error) {
 	log.Debug("ListDNS")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/cloud/servers/%s/records", serverID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &dns); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/servers/%s/records", serverID), &dns); err != nil {
 		return nil, err
 	}
 
@@ -227,16 +145,7 @@ func (dm *ServerService) GetDNSList(serverID string) (dns []types.Dns, err error
 func (dm *ServerService) GetEventsList(serverID string) (events []types.Event, err error) {
 	log.Debug("ListEvents")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/cloud/servers/%s/events", serverID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &events); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/servers/%s/events", serverID), &events); err != nil {
 		return nil, err
 	}
 
@@ -248,16 +157,7 @@ func (dm *ServerService) GetEventsList(serverID string) (events []types.Event, e
 func (dm *ServerService) GetOperationalScriptsList(serverID string) (scripts []types.ScriptChar, err error) {
 	log.Debug("ListScripts")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/cloud/servers/%s/operational_scripts", serverID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &scripts); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/servers/%s/operational_scripts", serverID), &scripts); err != nil {
 		return nil, err
 	}
 
@@ -268,16 +168,7 @@ func (dm *ServerService) GetOperationalScriptsList(serverID string) (scripts []t
 func (dm *ServerService) ExecuteOperationalScript(serverVector *map[string]interface{}, ID string, script_ID string) (script *types.ScriptChar, err error) {
 	log.Debug("ExecuteOperationalScript")
 
-	data, status, err := dm.concertoService.Put(fmt.Sprintf("/v1/cloud/servers/%s/operational_scripts/%s/execute", ID, script_ID), serverVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &script); err != nil {
+	if err = utils.PutJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/servers/%s/operational_scripts/%s/execute", ID, script_ID), serverVector, &script); err != nil {
 		return nil, err
 	}
 
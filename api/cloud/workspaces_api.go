@@ -1,7 +1,6 @@
 package cloud
 
 import (
-	"encoding/json"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
@@ -28,16 +27,7 @@ func NewWorkspaceService(concertoService utils.ConcertoService) (*WorkspaceServi
 func (dm *WorkspaceService) GetWorkspaceList() (workspaces []types.Workspace, err error) {
 	log.Debug("GetWorkspaceList")
 
-	data, status, err := dm.concertoService.Get("/v1/cloud/workspaces")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &workspaces); err != nil {
+	if err = utils.GetJSON(dm.concertoService, "/v1/cloud/workspaces", &workspaces); err != nil {
 		return nil, err
 	}
 
@@ -48,16 +38,7 @@ func (dm *WorkspaceService) GetWorkspaceList() (workspaces []types.Workspace, er
 func (dm *WorkspaceService) GetWorkspace(ID string) (workspace *types.Workspace, err error) {
 	log.Debug("GetWorkspace")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/cloud/workspaces/%s", ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &workspace); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/workspaces/%s", ID), &workspace); err != nil {
 		return nil, err
 	}
 
@@ -68,16 +49,7 @@ func (dm *WorkspaceService) GetWorkspace(ID string) (workspace *types.Workspace,
 func (dm *WorkspaceService) CreateWorkspace(workspaceVector *map[string]interface{}) (workspace *types.Workspace, err error) {
 	log.Debug("CreateWorkspace")
 
-	data, status, err := dm.concertoService.Post("/v1/cloud/workspaces/", workspaceVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &workspace); err != nil {
+	if err = utils.PostJSON(dm.concertoService, "/v1/cloud/workspaces/", workspaceVector, &workspace); err != nil {
 		return nil, err
 	}
 
@@ -88,16 +60,7 @@ func (dm *WorkspaceService) CreateWorkspace(workspaceVector *map[string]interfac
 func (dm *WorkspaceService) UpdateWorkspace(workspaceVector *map[string]interface{}, ID string) (workspace *types.Workspace, err error) {
 	log.Debug("UpdateWorkspace")
 
-	data, status, err := dm.concertoService.Put(fmt.Sprintf("/v1/cloud/workspaces/%s", ID), workspaceVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &workspace); err != nil {
+	if err = utils.PutJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/workspaces/%s", ID), workspaceVector, &workspace); err != nil {
 		return nil, err
 	}
 
@@ -124,16 +87,7 @@ func (dm *WorkspaceService) DeleteWorkspace(ID string) (err error) {
 func (dm *WorkspaceService) GetWorkspaceServerList(workspaceID string) (workspaceServer *[]types.WorkspaceServer, err error) {
 	log.Debug("ListWorkspaceServers")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/cloud/workspaces/%s/servers", workspaceID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &workspaceServer); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/workspaces/%s/servers", workspaceID), &workspaceServer); err != nil {
 		return nil, err
 	}
 
@@ -1,8 +1,10 @@
+// Code generated by api/generate from swagger.json. DO NOT EDIT.
+
 package cloud
 
 import (
-	"encoding/json"
 	"fmt"
+
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/utils"
@@ -28,16 +30,7 @@ func NewSSHProfileService(concertoService utils.ConcertoService) (*SSHProfileSer
 func (dm *SSHProfileService) GetSSHProfileList() (sshProfiles []types.SSHProfile, err error) {
 	log.Debug("GetSSHProfileList")
 
-	data, status, err := dm.concertoService.Get("/v1/cloud/ssh_profiles")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &sshProfiles); err != nil {
+	if err = utils.GetJSON(dm.concertoService, "/v1/cloud/ssh_profiles", &sshProfiles); err != nil {
 		return nil, err
 	}
 
@@ -48,16 +41,7 @@ func (dm *SSHProfileService) GetSSHProfileList() (sshProfiles []types.SSHProfile
 func (dm *SSHProfileService) GetSSHProfile(ID string) (sshProfile *types.SSHProfile, err error) {
 	log.Debug("GetSSHProfile")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/cloud/ssh_profiles/%s", ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &sshProfile); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/ssh_profiles/%s", ID), &sshProfile); err != nil {
 		return nil, err
 	}
 
@@ -68,16 +52,7 @@ func (dm *SSHProfileService) GetSSHProfile(ID string) (sshProfile *types.SSHProf
 func (dm *SSHProfileService) CreateSSHProfile(sshProfileVector *map[string]interface{}) (sshProfile *types.SSHProfile, err error) {
 	log.Debug("CreateSSHProfile")
 
-	data, status, err := dm.concertoService.Post("/v1/cloud/ssh_profiles/", sshProfileVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &sshProfile); err != nil {
+	if err = utils.PostJSON(dm.concertoService, "/v1/cloud/ssh_profiles/", sshProfileVector, &sshProfile); err != nil {
 		return nil, err
 	}
 
@@ -88,16 +63,7 @@ func (dm *SSHProfileService) CreateSSHProfile(sshProfileVector *map[string]inter
 func (dm *SSHProfileService) UpdateSSHProfile(sshProfileVector *map[string]interface{}, ID string) (sshProfile *types.SSHProfile, err error) {
 	log.Debug("UpdateSSHProfile")
 
-	data, status, err := dm.concertoService.Put(fmt.Sprintf("/v1/cloud/ssh_profiles/%s", ID), sshProfileVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &sshProfile); err != nil {
+	if err = utils.PutJSON(dm.concertoService, fmt.Sprintf("/v1/cloud/ssh_profiles/%s", ID), sshProfileVector, &sshProfile); err != nil {
 		return nil, err
 	}
 
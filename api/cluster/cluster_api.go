@@ -1,7 +1,6 @@
 package cluster
 
 import (
-	"encoding/json"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
@@ -28,16 +27,7 @@ func NewClusterService(concertoService utils.ConcertoService) (*ClusterService,
 func (cl *ClusterService) GetClusterList() (clusters []types.Cluster, err error) {
 	log.Debug("GetClusterList")
 
-	data, status, err := cl.concertoService.Get("/v1/kaas/fleets")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &clusters); err != nil {
+	if err = utils.GetJSON(cl.concertoService, "/v1/kaas/fleets", &clusters); err != nil {
 		return nil, err
 	}
 
@@ -48,16 +38,7 @@ func (cl *ClusterService) GetClusterList() (clusters []types.Cluster, err error)
 func (cl *ClusterService) CreateCluster(clusterVector *map[string]interface{}) (cluster *types.Cluster, err error) {
 	log.Debug("CreateCluster")
 
-	data, status, err := cl.concertoService.Post("/v1/kaas/fleets", clusterVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &cluster); err != nil {
+	if err = utils.PostJSON(cl.concertoService, "/v1/kaas/fleets", clusterVector, &cluster); err != nil {
 		return nil, err
 	}
 
@@ -127,3 +108,26 @@ func (cl *ClusterService) EmptyCluster(clusterVector *map[string]interface{}, ID
 
 	return nil
 }
+
+// ScaleCluster changes the number of nodes of a cluster by its ID
+func (cl *ClusterService) ScaleCluster(clusterVector *map[string]interface{}, ID string) (cluster *types.Cluster, err error) {
+	log.Debug("ScaleCluster")
+
+	if err = utils.PutJSON(cl.concertoService, fmt.Sprintf("/v1/kaas/fleets/%s/scale", ID), clusterVector, &cluster); err != nil {
+		return nil, err
+	}
+
+	return cluster, nil
+}
+
+// GetKubeconfig downloads the kubeconfig of a cluster by its ID into directoryPath, and returns the file location
+func (cl *ClusterService) GetKubeconfig(ID string, directoryPath string) (fileLocation string, err error) {
+	log.Debug("GetKubeconfig")
+
+	fileLocation, _, err = cl.concertoService.GetFile(fmt.Sprintf("/v1/kaas/fleets/%s/kubeconfig", ID), directoryPath)
+	if err != nil {
+		return "", err
+	}
+
+	return fileLocation, nil
+}
@@ -537,3 +537,185 @@ func EmptyClusterFailStatusMocked(t *testing.T, clusterIn *types.Cluster) {
 	assert.NotNil(err, "We are expecting an status code error")
 	assert.Contains(err.Error(), "499", "Error should contain http code 499")
 }
+
+// ScaleClusterMocked test mocked function
+func ScaleClusterMocked(t *testing.T, clusterIn *types.Cluster) *types.Cluster {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewClusterService(cs)
+	assert.Nil(err, "Couldn't load cluster service")
+	assert.NotNil(ds, "Cluster service not instanced")
+
+	// convertMap
+	mapIn, err := utils.ItemConvertParams(*clusterIn)
+	assert.Nil(err, "Cluster test data corrupted")
+
+	// to json
+	dOut, err := json.Marshal(clusterIn)
+	assert.Nil(err, "Cluster test data corrupted")
+
+	// call service
+	cs.On("Put", fmt.Sprintf("/v1/kaas/fleets/%s/scale", clusterIn.Id), mapIn).Return(dOut, 200, nil)
+	clusterOut, err := ds.ScaleCluster(mapIn, clusterIn.Id)
+	assert.Nil(err, "Error scaling cluster")
+	assert.Equal(*clusterIn, *clusterOut, "ScaleCluster returned different clusters")
+
+	return clusterOut
+}
+
+// ScaleClusterFailErrMocked test mocked function
+func ScaleClusterFailErrMocked(t *testing.T, clusterIn *types.Cluster) *types.Cluster {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewClusterService(cs)
+	assert.Nil(err, "Couldn't load cluster service")
+	assert.NotNil(ds, "Cluster service not instanced")
+
+	// convertMap
+	mapIn, err := utils.ItemConvertParams(*clusterIn)
+	assert.Nil(err, "Cluster test data corrupted")
+
+	// to json
+	dOut, err := json.Marshal(clusterIn)
+	assert.Nil(err, "Cluster test data corrupted")
+
+	// call service
+	cs.On("Put", fmt.Sprintf("/v1/kaas/fleets/%s/scale", clusterIn.Id), mapIn).Return(dOut, 200, fmt.Errorf("Mocked error"))
+	clusterOut, err := ds.ScaleCluster(mapIn, clusterIn.Id)
+
+	assert.NotNil(err, "We are expecting an error")
+	assert.Nil(clusterOut, "Expecting nil output")
+	assert.Equal(err.Error(), "Mocked error", "Error should be 'Mocked error'")
+
+	return clusterOut
+}
+
+// ScaleClusterFailStatusMocked test mocked function
+func ScaleClusterFailStatusMocked(t *testing.T, clusterIn *types.Cluster) *types.Cluster {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewClusterService(cs)
+	assert.Nil(err, "Couldn't load cluster service")
+	assert.NotNil(ds, "Cluster service not instanced")
+
+	// convertMap
+	mapIn, err := utils.ItemConvertParams(*clusterIn)
+	assert.Nil(err, "Cluster test data corrupted")
+
+	// to json
+	dOut, err := json.Marshal(clusterIn)
+	assert.Nil(err, "Cluster test data corrupted")
+
+	// call service
+	cs.On("Put", fmt.Sprintf("/v1/kaas/fleets/%s/scale", clusterIn.Id), mapIn).Return(dOut, 499, nil)
+	clusterOut, err := ds.ScaleCluster(mapIn, clusterIn.Id)
+
+	assert.NotNil(err, "We are expecting an status code error")
+	assert.Nil(clusterOut, "Expecting nil output")
+	assert.Contains(err.Error(), "499", "Error should contain http code 499")
+
+	return clusterOut
+}
+
+// ScaleClusterFailJSONMocked test mocked function
+func ScaleClusterFailJSONMocked(t *testing.T, clusterIn *types.Cluster) *types.Cluster {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewClusterService(cs)
+	assert.Nil(err, "Couldn't load cluster service")
+	assert.NotNil(ds, "Cluster service not instanced")
+
+	// convertMap
+	mapIn, err := utils.ItemConvertParams(*clusterIn)
+	assert.Nil(err, "Cluster test data corrupted")
+
+	// wrong json
+	dIn := []byte{10, 20, 30}
+
+	// call service
+	cs.On("Put", fmt.Sprintf("/v1/kaas/fleets/%s/scale", clusterIn.Id), mapIn).Return(dIn, 200, nil)
+	clusterOut, err := ds.ScaleCluster(mapIn, clusterIn.Id)
+
+	assert.NotNil(err, "We are expecting a marshalling error")
+	assert.Nil(clusterOut, "Expecting nil output")
+	assert.Contains(err.Error(), "invalid character", "Error message should include the string 'invalid character'")
+
+	return clusterOut
+}
+
+// GetKubeconfigMocked test mocked function
+func GetKubeconfigMocked(t *testing.T, clusterIn *types.Cluster) string {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewClusterService(cs)
+	assert.Nil(err, "Couldn't load cluster service")
+	assert.NotNil(ds, "Cluster service not instanced")
+
+	// call service
+	fileIn := fmt.Sprintf("/tmp/%s/kubeconfig", clusterIn.Id)
+	cs.On("GetFile", fmt.Sprintf("/v1/kaas/fleets/%s/kubeconfig", clusterIn.Id), "/tmp").Return(fileIn, 200, nil)
+	fileOut, err := ds.GetKubeconfig(clusterIn.Id, "/tmp")
+	assert.Nil(err, "Error getting kubeconfig")
+	assert.Equal(fileIn, fileOut, "GetKubeconfig returned a different file")
+
+	return fileOut
+}
+
+// GetKubeconfigFailErrMocked test mocked function
+func GetKubeconfigFailErrMocked(t *testing.T, clusterIn *types.Cluster) string {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewClusterService(cs)
+	assert.Nil(err, "Couldn't load cluster service")
+	assert.NotNil(ds, "Cluster service not instanced")
+
+	// call service
+	cs.On("GetFile", fmt.Sprintf("/v1/kaas/fleets/%s/kubeconfig", clusterIn.Id), "/tmp").Return("", 200, fmt.Errorf("Mocked error"))
+	fileOut, err := ds.GetKubeconfig(clusterIn.Id, "/tmp")
+
+	assert.NotNil(err, "We are expecting an error")
+	assert.Equal("", fileOut, "Expecting empty output")
+	assert.Equal(err.Error(), "Mocked error", "Error should be 'Mocked error'")
+
+	return fileOut
+}
+
+// GetKubeconfigFailStatusMocked test mocked function
+func GetKubeconfigFailStatusMocked(t *testing.T, clusterIn *types.Cluster) string {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	ds, err := NewClusterService(cs)
+	assert.Nil(err, "Couldn't load cluster service")
+	assert.NotNil(ds, "Cluster service not instanced")
+
+	// call service
+	cs.On("GetFile", fmt.Sprintf("/v1/kaas/fleets/%s/kubeconfig", clusterIn.Id), "/tmp").Return("", 499, nil)
+	fileOut, err := ds.GetKubeconfig(clusterIn.Id, "/tmp")
+
+	assert.NotNil(err, "We are expecting an status code error")
+	assert.Equal("", fileOut, "Expecting empty output")
+	assert.Contains(err.Error(), "499", "Error should contain http code 499")
+
+	return fileOut
+}
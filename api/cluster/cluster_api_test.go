@@ -66,3 +66,22 @@ func TestEmptyCluster(t *testing.T) {
 		EmptyClusterFailStatusMocked(t, &clusterIn)
 	}
 }
+
+func TestScaleCluster(t *testing.T) {
+	clustersIn := testdata.GetClusterData()
+	for _, clusterIn := range *clustersIn {
+		ScaleClusterMocked(t, &clusterIn)
+		ScaleClusterFailErrMocked(t, &clusterIn)
+		ScaleClusterFailStatusMocked(t, &clusterIn)
+		ScaleClusterFailJSONMocked(t, &clusterIn)
+	}
+}
+
+func TestGetKubeconfig(t *testing.T) {
+	clustersIn := testdata.GetClusterData()
+	for _, clusterIn := range *clustersIn {
+		GetKubeconfigMocked(t, &clusterIn)
+		GetKubeconfigFailErrMocked(t, &clusterIn)
+		GetKubeconfigFailStatusMocked(t, &clusterIn)
+	}
+}
@@ -1,7 +1,6 @@
 package dns
 
 import (
-	"encoding/json"
 	"fmt"
 
 	log "github.com/Sirupsen/logrus"
@@ -29,16 +28,7 @@ func NewDomainService(concertoService utils.ConcertoService) (*DomainService, er
 func (dm *DomainService) GetDomainList() (domains []types.Domain, err error) {
 	log.Debug("GetDomainList")
 
-	data, status, err := dm.concertoService.Get("/v1/dns/domains")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &domains); err != nil {
+	if err = utils.GetJSON(dm.concertoService, "/v1/dns/domains", &domains); err != nil {
 		return nil, err
 	}
 
@@ -49,16 +39,7 @@ func (dm *DomainService) GetDomainList() (domains []types.Domain, err error) {
 func (dm *DomainService) GetDomain(ID string) (domain *types.Domain, err error) {
 	log.Debug("GetDomain")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/dns/domains/%s", ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &domain); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/dns/domains/%s", ID), &domain); err != nil {
 		return nil, err
 	}
 
@@ -69,16 +50,7 @@ func (dm *DomainService) GetDomain(ID string) (domain *types.Domain, err error)
 func (dm *DomainService) CreateDomain(domainVector *map[string]interface{}) (domain *types.Domain, err error) {
 	log.Debug("CreateDomain")
 
-	data, status, err := dm.concertoService.Post("/v1/dns/domains/", domainVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &domain); err != nil {
+	if err = utils.PostJSON(dm.concertoService, "/v1/dns/domains/", domainVector, &domain); err != nil {
 		return nil, err
 	}
 
@@ -89,16 +61,7 @@ func (dm *DomainService) CreateDomain(domainVector *map[string]interface{}) (dom
 func (dm *DomainService) UpdateDomain(domainVector *map[string]interface{}, ID string) (domain *types.Domain, err error) {
 	log.Debug("UpdateDomain")
 
-	data, status, err := dm.concertoService.Put(fmt.Sprintf("/v1/dns/domains/%s", ID), domainVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &domain); err != nil {
+	if err = utils.PutJSON(dm.concertoService, fmt.Sprintf("/v1/dns/domains/%s", ID), domainVector, &domain); err != nil {
 		return nil, err
 	}
 
@@ -125,16 +88,7 @@ func (dm *DomainService) DeleteDomain(ID string) (err error) {
 func (dm *DomainService) GetDomainRecordList(domainID string) (domainRecord *[]types.DomainRecord, err error) {
 	log.Debug("ListDomainRecords")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/dns/domains/%s/records", domainID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &domainRecord); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/dns/domains/%s/records", domainID), &domainRecord); err != nil {
 		return nil, err
 	}
 
@@ -145,16 +99,7 @@ func (dm *DomainService) GetDomainRecordList(domainID string) (domainRecord *[]t
 func (dm *DomainService) GetDomainRecord(domID string, ID string) (domainRecord *types.DomainRecord, err error) {
 	log.Debug("GetDomainRecord")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/dns/domains/%s/records/%s", domID, ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &domainRecord); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/dns/domains/%s/records/%s", domID, ID), &domainRecord); err != nil {
 		return nil, err
 	}
 
@@ -165,16 +110,7 @@ func (dm *DomainService) GetDomainRecord(domID string, ID string) (domainRecord
 func (dm *DomainService) CreateDomainRecord(domainRecordVector *map[string]interface{}, domID string) (domainRecord *types.DomainRecord, err error) {
 	log.Debug("CreateDomainRecord")
 
-	data, status, err := dm.concertoService.Post(fmt.Sprintf("/v1/dns/domains/%s/records", domID), domainRecordVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &domainRecord); err != nil {
+	if err = utils.PostJSON(dm.concertoService, fmt.Sprintf("/v1/dns/domains/%s/records", domID), domainRecordVector, &domainRecord); err != nil {
 		return nil, err
 	}
 
@@ -185,16 +121,7 @@ func (dm *DomainService) CreateDomainRecord(domainRecordVector *map[string]inter
 func (dm *DomainService) UpdateDomainRecord(domainRecordVector *map[string]interface{}, domID string, ID string) (domainRecord *types.DomainRecord, err error) {
 	log.Debug("UpdateDomainRecord")
 
-	data, status, err := dm.concertoService.Put(fmt.Sprintf("/v1/dns/domains/%s/records/%s", domID, ID), domainRecordVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &domainRecord); err != nil {
+	if err = utils.PutJSON(dm.concertoService, fmt.Sprintf("/v1/dns/domains/%s/records/%s", domID, ID), domainRecordVector, &domainRecord); err != nil {
 		return nil, err
 	}
 
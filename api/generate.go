@@ -0,0 +1,5 @@
+// Package api groups the Concerto API clients. Types and client services of the resources
+// described in swagger.json are generated from it: edit the specification and run go generate
+package api
+
+//go:generate go run ./generate -spec swagger.json -types types/zz_generated_types.go -clients .
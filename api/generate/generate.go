@@ -0,0 +1,463 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// Spec holds the parts of a Swagger 2.0 specification used to generate code
+type Spec struct {
+	Swagger     string              `json:"swagger"`
+	Paths       map[string]PathItem `json:"paths"`
+	Definitions map[string]Schema   `json:"definitions"`
+}
+
+// PathItem holds the operations of a path by HTTP method
+type PathItem map[string]Operation
+
+// Operation describes an API call. Vendor extensions set the names of the generated service and method
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Tags        []string            `json:"tags"`
+	Responses   map[string]Response `json:"responses"`
+	Service     string              `json:"x-go-service"`
+}
+
+// Response describes the body returned by an operation
+type Response struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema describes a type
+type Schema struct {
+	Ref         string            `json:"$ref"`
+	Type        string            `json:"type"`
+	Format      string            `json:"format"`
+	Description string            `json:"description"`
+	Items       *Schema           `json:"items"`
+	Properties  map[string]Schema `json:"properties"`
+
+	GoName    string `json:"x-go-name"`
+	Order     int    `json:"x-order"`
+	OmitEmpty bool   `json:"x-omitempty"`
+	Header    string `json:"x-header"`
+	Show      string `json:"x-show"`
+}
+
+// LoadSpec reads a specification file
+func LoadSpec(file string) (*Spec, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	spec := &Spec{}
+	if err = json.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("Couldn't parse %s: %s", file, err)
+	}
+	if spec.Swagger != "2.0" {
+		return nil, fmt.Errorf("Unsupported specification version %q. Only swagger 2.0 is supported", spec.Swagger)
+	}
+	return spec, nil
+}
+
+// field is a struct field of a generated type
+type field struct {
+	Name string
+	Type string
+	Tag  string
+}
+
+// typeDef is a generated type
+type typeDef struct {
+	Name        string
+	Description string
+	Fields      []field
+}
+
+// method is a generated client method
+type method struct {
+	Name     string
+	Summary  string
+	Verb     string
+	Path     string
+	HasID    bool
+	Result   string
+	IsList   bool
+	Variable string
+}
+
+// service is a generated client service
+type service struct {
+	Name     string
+	Resource string
+	Methods  []method
+}
+
+// GenerateTypes returns the source of a file declaring every definition of the spec in package pkg
+func GenerateTypes(spec *Spec, pkg string) ([]byte, error) {
+	var defs []typeDef
+	imports := make(map[string]bool)
+	for _, name := range sortedKeys(spec.Definitions) {
+		schema := spec.Definitions[name]
+		def := typeDef{Name: name, Description: schema.Description}
+		if def.Description == "" {
+			def.Description = fmt.Sprintf("%s is generated from the API specification", name)
+		} else {
+			def.Description = fmt.Sprintf("%s %s", name, lowerFirst(def.Description))
+		}
+
+		props := sortedProperties(schema.Properties)
+		for _, p := range props {
+			prop := schema.Properties[p]
+			goType, imp, err := goType(prop)
+			if err != nil {
+				return nil, fmt.Errorf("Couldn't generate %s.%s: %s", name, p, err)
+			}
+			if imp != "" {
+				imports[imp] = true
+			}
+			def.Fields = append(def.Fields, field{Name: fieldName(p, prop), Type: goType, Tag: fieldTag(p, prop)})
+		}
+		defs = append(defs, def)
+	}
+
+	return render(typesTemplate, map[string]interface{}{
+		"Package": pkg,
+		"Imports": sortedKeys(imports),
+		"Types":   defs,
+	})
+}
+
+// GenerateClients returns the source of the client services of every tag, keyed by tag.
+// Services belong to the package named after the first tag of their operations
+func GenerateClients(spec *Spec, typesImport string) (map[string][]byte, error) {
+	services := make(map[string]map[string]*service)
+	for _, path := range sortedKeys(spec.Paths) {
+		item := spec.Paths[path]
+		for _, verb := range []string{"get", "post", "put", "delete"} {
+			op, ok := item[verb]
+			if !ok {
+				continue
+			}
+			if op.OperationID == "" || op.Service == "" || len(op.Tags) == 0 {
+				return nil, fmt.Errorf("Operation %s %s needs operationId, x-go-service and tags", strings.ToUpper(verb), path)
+			}
+			m, err := newMethod(verb, path, op)
+			if err != nil {
+				return nil, err
+			}
+
+			tag := op.Tags[0]
+			if services[tag] == nil {
+				services[tag] = make(map[string]*service)
+			}
+			svc := services[tag][op.Service]
+			if svc == nil {
+				svc = &service{Name: op.Service, Resource: lowerFirst(strings.TrimSuffix(op.Service, "Service"))}
+				services[tag][op.Service] = svc
+			}
+			svc.Methods = append(svc.Methods, m)
+		}
+	}
+
+	sources := make(map[string][]byte)
+	for tag, byName := range services {
+		var list []*service
+		for _, name := range sortedKeys(byName) {
+			sort.Sort(byVerb(byName[name].Methods))
+			list = append(list, byName[name])
+		}
+		src, err := render(clientTemplate, map[string]interface{}{
+			"Package":     tag,
+			"TypesImport": typesImport,
+			"Services":    list,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't generate %s clients: %s", tag, err)
+		}
+		sources[tag] = src
+	}
+	return sources, nil
+}
+
+var pathParam = regexp.MustCompile(`\{[^}]+\}`)
+
+func newMethod(verb string, path string, op Operation) (method, error) {
+	m := method{
+		Name:    op.OperationID,
+		Summary: op.Summary,
+		Verb:    strings.Title(verb),
+		Path:    path,
+	}
+
+	params := pathParam.FindAllString(path, -1)
+	switch len(params) {
+	case 0:
+	case 1:
+		m.HasID = true
+		m.Path = pathParam.ReplaceAllString(path, "%s")
+	default:
+		return m, fmt.Errorf("Operation %s has %d path parameters. Only one is supported", op.OperationID, len(params))
+	}
+
+	if verb == "delete" {
+		return m, nil
+	}
+	resp, ok := op.Responses["200"]
+	if !ok || resp.Schema == nil {
+		return m, fmt.Errorf("Operation %s has no 200 response schema", op.OperationID)
+	}
+	schema := resp.Schema
+	if schema.Type == "array" && schema.Items != nil {
+		m.IsList = true
+		schema = schema.Items
+	}
+	if schema.Ref == "" {
+		return m, fmt.Errorf("Operation %s must respond with a definition or an array of definitions", op.OperationID)
+	}
+	m.Result = refName(schema.Ref)
+	m.Variable = lowerFirst(m.Result)
+	if m.IsList {
+		m.Variable += "s"
+	}
+	return m, nil
+}
+
+// byVerb sorts methods in the order they're usually written: list, get, create, update, delete
+type byVerb []method
+
+func (a byVerb) Len() int      { return len(a) }
+func (a byVerb) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byVerb) Less(i, j int) bool {
+	if ri, rj := verbRank(a[i]), verbRank(a[j]); ri != rj {
+		return ri < rj
+	}
+	return a[i].Name < a[j].Name
+}
+
+func verbRank(m method) int {
+	switch {
+	case m.Verb == "Get" && m.IsList:
+		return 0
+	case m.Verb == "Get":
+		return 1
+	case m.Verb == "Post":
+		return 2
+	case m.Verb == "Put":
+		return 3
+	}
+	return 4
+}
+
+func goType(s Schema) (string, string, error) {
+	if s.Ref != "" {
+		return refName(s.Ref), "", nil
+	}
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			return "time.Time", "time", nil
+		}
+		return "string", "", nil
+	case "integer":
+		return "int", "", nil
+	case "number":
+		return "float64", "", nil
+	case "boolean":
+		return "bool", "", nil
+	case "array":
+		if s.Items == nil {
+			return "", "", fmt.Errorf("array without items")
+		}
+		t, imp, err := goType(*s.Items)
+		return "[]" + t, imp, err
+	case "object", "":
+		return "*json.RawMessage", "encoding/json", nil
+	}
+	return "", "", fmt.Errorf("unsupported type %s", s.Type)
+}
+
+func fieldName(name string, s Schema) string {
+	if s.GoName != "" {
+		return s.GoName
+	}
+	var b bytes.Buffer
+	for _, part := range strings.Split(name, "_") {
+		switch strings.ToLower(part) {
+		case "id", "ip", "url", "fqdn", "ssh", "dns", "cidr":
+			b.WriteString(strings.ToUpper(part))
+		default:
+			b.WriteString(upperFirst(part))
+		}
+	}
+	return b.String()
+}
+
+func fieldTag(name string, s Schema) string {
+	jsonTag := name
+	if s.OmitEmpty {
+		jsonTag += ",omitempty"
+	}
+	header := s.Header
+	if header == "" {
+		header = strings.ToUpper(name)
+	}
+	tag := fmt.Sprintf(`json:"%s" header:"%s"`, jsonTag, header)
+	if s.Show != "" {
+		tag += fmt.Sprintf(` show:"%s"`, s.Show)
+	}
+	return "`" + tag + "`"
+}
+
+// sortedProperties returns property names by x-order, then by name
+func sortedProperties(props map[string]Schema) []string {
+	names := sortedKeys(props)
+	sort.Stable(byOrder{names, props})
+	return names
+}
+
+type byOrder struct {
+	names []string
+	props map[string]Schema
+}
+
+func (a byOrder) Len() int           { return len(a.names) }
+func (a byOrder) Swap(i, j int)      { a.names[i], a.names[j] = a.names[j], a.names[i] }
+func (a byOrder) Less(i, j int) bool { return a.props[a.names[i]].Order < a.props[a.names[j]].Order }
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// lowerFirst lowers the leading upper case letters of s, keeping the last one of an initialism: SSHProfile is sshProfile
+func lowerFirst(s string) string {
+	r := []rune(s)
+	for i := 0; i < len(r) && unicode.IsUpper(r[i]); i++ {
+		if i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch t := m.(type) {
+	case map[string]Schema:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string]PathItem:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string]bool:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string]*service:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func render(tmpl *template.Template, data interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("Generated code doesn't compile: %s", err)
+	}
+	return src, nil
+}
+
+const header = "// Code generated by api/generate from swagger.json. DO NOT EDIT.\n\n"
+
+var typesTemplate = template.Must(template.New("types").Parse(header + `package {{.Package}}
+{{if .Imports}}
+import (
+{{range .Imports}}	"{{.}}"
+{{end}})
+{{end}}
+{{range .Types}}
+// {{.Description}}
+type {{.Name}} struct {
+{{range .Fields}}	{{.Name}} {{.Type}} {{.Tag}}
+{{end}}}
+{{end}}`))
+
+var clientTemplate = template.Must(template.New("client").Parse(header + `package {{.Package}}
+
+import (
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+	"{{.TypesImport}}"
+	"github.com/flexiant/concerto/utils"
+)
+{{range $svc := .Services}}
+// {{.Name}} manages {{.Resource}} operations
+type {{.Name}} struct {
+	concertoService utils.ConcertoService
+}
+
+// New{{.Name}} returns a Concerto {{.Resource}} service
+func New{{.Name}}(concertoService utils.ConcertoService) (*{{.Name}}, error) {
+	if concertoService == nil {
+		return nil, fmt.Errorf("Must initialize ConcertoService before using it")
+	}
+
+	return &{{.Name}}{
+		concertoService: concertoService,
+	}, nil
+}
+{{range .Methods}}
+// {{.Name}} {{.Summary}}
+{{if eq .Verb "Delete"}}func (dm *{{$svc.Name}}) {{.Name}}(ID string) (err error) {
+	log.Debug("{{.Name}}")
+
+	data, status, err := dm.concertoService.Delete(fmt.Sprintf("{{.Path}}", ID))
+	if err != nil {
+		return err
+	}
+
+	if err = utils.CheckStandardStatus(status, data); err != nil {
+		return err
+	}
+
+	return nil
+}
+{{else}}func (dm *{{$svc.Name}}) {{.Name}}({{if or (eq .Verb "Post") (eq .Verb "Put")}}{{$svc.Resource}}Vector *map[string]interface{}{{if .HasID}}, {{end}}{{end}}{{if .HasID}}ID string{{end}}) ({{.Variable}} {{if .IsList}}[]{{else}}*{{end}}types.{{.Result}}, err error) {
+	log.Debug("{{.Name}}")
+
+	if err = utils.{{.Verb}}JSON(dm.concertoService, {{if .HasID}}fmt.Sprintf("{{.Path}}", ID){{else}}"{{.Path}}"{{end}}{{if or (eq .Verb "Post") (eq .Verb "Put")}}, {{$svc.Resource}}Vector{{end}}, &{{.Variable}}); err != nil {
+		return nil, err
+	}
+
+	return {{.Variable}}, nil
+}
+{{end}}{{end}}{{end}}`))
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	spec := &Spec{Definitions: map[string]Schema{
+		"Node": {Properties: map[string]Schema{
+			"public_ip":  {Type: "string", Order: 2},
+			"id":         {Type: "string", Order: 1},
+			"created_at": {Type: "string", Format: "date-time", Order: 3, OmitEmpty: true},
+			"attributes": {Type: "object", Order: 4, Show: "nolist"},
+			"tags":       {Type: "array", Items: &Schema{Type: "string"}, Order: 5},
+		}},
+	}}
+	src, err := GenerateTypes(spec, "types")
+	assert.Nil(err, "Couldn't generate types")
+	assert.Contains(string(src), `import (
+	"encoding/json"
+	"time"
+)`, "Imports should be added")
+	assert.Contains(string(src), "// Node is generated from the API specification\ntype Node struct {\n"+
+		"\tID         string           `json:\"id\" header:\"ID\"`\n"+
+		"\tPublicIP   string           `json:\"public_ip\" header:\"PUBLIC_IP\"`\n"+
+		"\tCreatedAt  time.Time        `json:\"created_at,omitempty\" header:\"CREATED_AT\"`\n"+
+		"\tAttributes *json.RawMessage `json:\"attributes\" header:\"ATTRIBUTES\" show:\"nolist\"`\n"+
+		"\tTags       []string         `json:\"tags\" header:\"TAGS\"`\n}", "Unexpected type")
+}
+
+func TestGenerateClientsInvalid(t *testing.T) {
+	spec := &Spec{Paths: map[string]PathItem{
+		"/v1/nodes/{id}/{other}": {"get": {OperationID: "GetNode", Service: "NodeService", Tags: []string{"node"}}},
+	}}
+	_, err := GenerateClients(spec, "github.com/flexiant/concerto/api/types")
+	assert.NotNil(t, err, "Several path parameters should fail")
+
+	spec.Paths = map[string]PathItem{"/v1/nodes": {"get": {OperationID: "GetNodeList"}}}
+	_, err = GenerateClients(spec, "github.com/flexiant/concerto/api/types")
+	assert.NotNil(t, err, "Operations without service should fail")
+}
+
+func TestLowerFirst(t *testing.T) {
+	assert.Equal(t, "sshProfile", lowerFirst("SSHProfile"), "Initialisms should be lowered")
+	assert.Equal(t, "workspace", lowerFirst("Workspace"), "Unexpected name")
+	assert.Equal(t, "id", lowerFirst("ID"), "Unexpected name")
+}
+
+// TestGeneratedUpToDate fails when generated files weren't regenerated after changing the specification
+func TestGeneratedUpToDate(t *testing.T) {
+	assert := assert.New(t)
+
+	spec, err := LoadSpec(filepath.Join("..", "swagger.json"))
+	assert.Nil(err, "Couldn't load specification")
+
+	src, err := GenerateTypes(spec, "types")
+	assert.Nil(err, "Couldn't generate types")
+	current, err := ioutil.ReadFile(filepath.Join("..", "types", "zz_generated_types.go"))
+	assert.Nil(err, "Couldn't read generated types")
+	assert.Equal(string(src), string(current), "Generated types are stale. Run go generate in the api directory")
+
+	clients, err := GenerateClients(spec, "github.com/flexiant/concerto/api/types")
+	assert.Nil(err, "Couldn't generate clients")
+	for tag, src := range clients {
+		current, err := ioutil.ReadFile(filepath.Join("..", tag, "zz_generated_api.go"))
+		assert.Nil(err, "Couldn't read generated clients")
+		assert.Equal(string(src), string(current), "Generated clients are stale. Run go generate in the api directory")
+	}
+}
@@ -0,0 +1,50 @@
+// Command generate writes API types and client services described in a Swagger 2.0 specification.
+// It's run by go generate in the api directory
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+func main() {
+	specFile := flag.String("spec", "swagger.json", "Swagger 2.0 specification")
+	typesFile := flag.String("types", "types/zz_generated_types.go", "File where types are written")
+	typesImport := flag.String("types-import", "github.com/flexiant/concerto/api/types", "Import path of the types package")
+	clientsDir := flag.String("clients", ".", "Directory holding a package for every tag, where client services are written")
+	flag.Parse()
+
+	spec, err := LoadSpec(*specFile)
+	if err != nil {
+		log.Fatalf("Couldn't load specification: %s", err)
+	}
+
+	src, err := GenerateTypes(spec, filepath.Base(filepath.Dir(*typesFile)))
+	if err != nil {
+		log.Fatalf("Couldn't generate types: %s", err)
+	}
+	write(*typesFile, src)
+
+	clients, err := GenerateClients(spec, *typesImport)
+	if err != nil {
+		log.Fatalf("Couldn't generate clients: %s", err)
+	}
+	for tag, src := range clients {
+		dir := filepath.Join(*clientsDir, tag)
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			log.Fatalf("Couldn't create %s: %s", dir, err)
+		}
+		write(filepath.Join(dir, "zz_generated_api.go"), src)
+	}
+}
+
+func write(file string, src []byte) {
+	if err := ioutil.WriteFile(file, src, 0644); err != nil {
+		log.Fatalf("Couldn't write %s: %s", file, err)
+	}
+	log.Infof("Generated %s", file)
+}
@@ -1,7 +1,6 @@
 package licensee
 
 import (
-	"encoding/json"
 	"fmt"
 
 	log "github.com/Sirupsen/logrus"
@@ -30,16 +29,7 @@ func NewLicenseeReportService(concertoService utils.ConcertoService) (*LicenseeR
 func (rs *LicenseeReportService) GetLicenseeReportList() (reports []types.LicenseeReport, err error) {
 	log.Debug("GetReportList")
 
-	data, status, err := rs.concertoService.Get("/v1/licensee/reports")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &reports); err != nil {
+	if err = utils.GetJSON(rs.concertoService, "/v1/licensee/reports", &reports); err != nil {
 		return nil, err
 	}
 
@@ -50,16 +40,7 @@ func (rs *LicenseeReportService) GetLicenseeReportList() (reports []types.Licens
 func (rs *LicenseeReportService) GetLicenseeReport(ID string) (report *types.LicenseeReport, err error) {
 	log.Debug("GetReport")
 
-	data, status, err := rs.concertoService.Get(fmt.Sprintf("/v1/licensee/reports/%s", ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &report); err != nil {
+	if err = utils.GetJSON(rs.concertoService, fmt.Sprintf("/v1/licensee/reports/%s", ID), &report); err != nil {
 		return nil, err
 	}
 
@@ -1,7 +1,6 @@
 package network
 
 import (
-	"encoding/json"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
@@ -28,16 +27,7 @@ func NewFirewallProfileService(concertoService utils.ConcertoService) (*Firewall
 func (dm *FirewallProfileService) GetFirewallProfileList() (firewallProfiles []types.FirewallProfile, err error) {
 	log.Debug("GetFirewallProfileList")
 
-	data, status, err := dm.concertoService.Get("/v1/network/firewall_profiles")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &firewallProfiles); err != nil {
+	if err = utils.GetJSON(dm.concertoService, "/v1/network/firewall_profiles", &firewallProfiles); err != nil {
 		return nil, err
 	}
 
@@ -48,16 +38,7 @@ func (dm *FirewallProfileService) GetFirewallProfileList() (firewallProfiles []t
 func (dm *FirewallProfileService) GetFirewallProfile(ID string) (firewallProfile *types.FirewallProfile, err error) {
 	log.Debug("GetFirewallProfile")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/network/firewall_profiles/%s", ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &firewallProfile); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/network/firewall_profiles/%s", ID), &firewallProfile); err != nil {
 		return nil, err
 	}
 
@@ -68,16 +49,7 @@ func (dm *FirewallProfileService) GetFirewallProfile(ID string) (firewallProfile
 func (dm *FirewallProfileService) CreateFirewallProfile(firewallProfileVector *map[string]interface{}) (firewallProfile *types.FirewallProfile, err error) {
 	log.Debug("CreateFirewallProfile")
 
-	data, status, err := dm.concertoService.Post("/v1/network/firewall_profiles/", firewallProfileVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &firewallProfile); err != nil {
+	if err = utils.PostJSON(dm.concertoService, "/v1/network/firewall_profiles/", firewallProfileVector, &firewallProfile); err != nil {
 		return nil, err
 	}
 
@@ -88,16 +60,7 @@ func (dm *FirewallProfileService) CreateFirewallProfile(firewallProfileVector *m
 func (dm *FirewallProfileService) UpdateFirewallProfile(firewallProfileVector *map[string]interface{}, ID string) (firewallProfile *types.FirewallProfile, err error) {
 	log.Debug("UpdateFirewallProfile")
 
-	data, status, err := dm.concertoService.Put(fmt.Sprintf("/v1/network/firewall_profiles/%s", ID), firewallProfileVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &firewallProfile); err != nil {
+	if err = utils.PutJSON(dm.concertoService, fmt.Sprintf("/v1/network/firewall_profiles/%s", ID), firewallProfileVector, &firewallProfile); err != nil {
 		return nil, err
 	}
 
@@ -1,7 +1,6 @@
 package network
 
 import (
-	"encoding/json"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
@@ -28,16 +27,7 @@ func NewLoadBalancerService(concertoService utils.ConcertoService) (*LoadBalance
 func (lb *LoadBalancerService) GetLoadBalancerList() (loadBalancers []types.LoadBalancer, err error) {
 	log.Debug("GetLoadBalancerList")
 
-	data, status, err := lb.concertoService.Get("/v1/network/load_balancers")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &loadBalancers); err != nil {
+	if err = utils.GetJSON(lb.concertoService, "/v1/network/load_balancers", &loadBalancers); err != nil {
 		return nil, err
 	}
 
@@ -48,16 +38,7 @@ func (lb *LoadBalancerService) GetLoadBalancerList() (loadBalancers []types.Load
 func (lb *LoadBalancerService) GetLoadBalancer(ID string) (loadBalancer *types.LoadBalancer, err error) {
 	log.Debug("GetLoadBalancer")
 
-	data, status, err := lb.concertoService.Get(fmt.Sprintf("/v1/network/load_balancers/%s", ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &loadBalancer); err != nil {
+	if err = utils.GetJSON(lb.concertoService, fmt.Sprintf("/v1/network/load_balancers/%s", ID), &loadBalancer); err != nil {
 		return nil, err
 	}
 
@@ -68,16 +49,7 @@ func (lb *LoadBalancerService) GetLoadBalancer(ID string) (loadBalancer *types.L
 func (lb *LoadBalancerService) CreateLoadBalancer(loadBalancerVector *map[string]interface{}) (loadBalancer *types.LoadBalancer, err error) {
 	log.Debug("CreateLoadBalancer")
 
-	data, status, err := lb.concertoService.Post("/v1/network/load_balancers/", loadBalancerVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &loadBalancer); err != nil {
+	if err = utils.PostJSON(lb.concertoService, "/v1/network/load_balancers/", loadBalancerVector, &loadBalancer); err != nil {
 		return nil, err
 	}
 
@@ -88,16 +60,7 @@ func (lb *LoadBalancerService) CreateLoadBalancer(loadBalancerVector *map[string
 func (lb *LoadBalancerService) UpdateLoadBalancer(loadBalancerVector *map[string]interface{}, ID string) (loadBalancer *types.LoadBalancer, err error) {
 	log.Debug("UpdateLoadBalancer")
 
-	data, status, err := lb.concertoService.Put(fmt.Sprintf("/v1/network/load_balancers/%s", ID), loadBalancerVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &loadBalancer); err != nil {
+	if err = utils.PutJSON(lb.concertoService, fmt.Sprintf("/v1/network/load_balancers/%s", ID), loadBalancerVector, &loadBalancer); err != nil {
 		return nil, err
 	}
 
@@ -124,16 +87,7 @@ func (lb *LoadBalancerService) DeleteLoadBalancer(ID string) (err error) {
 func (lb *LoadBalancerService) GetLBNodeList(loadBalancerID string) (lBNode *[]types.LBNode, err error) {
 	log.Debug("ListLBNodes")
 
-	data, status, err := lb.concertoService.Get(fmt.Sprintf("/v1/network/load_balancers/%s/nodes", loadBalancerID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &lBNode); err != nil {
+	if err = utils.GetJSON(lb.concertoService, fmt.Sprintf("/v1/network/load_balancers/%s/nodes", loadBalancerID), &lBNode); err != nil {
 		return nil, err
 	}
 
@@ -144,16 +98,7 @@ func (lb *LoadBalancerService) GetLBNodeList(loadBalancerID string) (lBNode *[]t
 func (lb *LoadBalancerService) CreateLBNode(lBNodeVector *map[string]interface{}, lbID string) (lBNode *types.LBNode, err error) {
 	log.Debug("CreateLBNode")
 
-	data, status, err := lb.concertoService.Post(fmt.Sprintf("/v1/network/load_balancers/%s/nodes", lbID), lBNodeVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &lBNode); err != nil {
+	if err = utils.PostJSON(lb.concertoService, fmt.Sprintf("/v1/network/load_balancers/%s/nodes", lbID), lBNodeVector, &lBNode); err != nil {
 		return nil, err
 	}
 
@@ -1,7 +1,6 @@
 package node
 
 import (
-	"encoding/json"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
@@ -28,16 +27,7 @@ func NewNodeService(concertoService utils.ConcertoService) (*NodeService, error)
 func (cl *NodeService) GetNodeList() (nodes []types.Node, err error) {
 	log.Debug("GetNodeList")
 
-	data, status, err := cl.concertoService.Get("/v1/kaas/ships")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &nodes); err != nil {
+	if err = utils.GetJSON(cl.concertoService, "/v1/kaas/ships", &nodes); err != nil {
 		return nil, err
 	}
 
@@ -48,16 +38,7 @@ func (cl *NodeService) GetNodeList() (nodes []types.Node, err error) {
 func (cl *NodeService) CreateNode(nodeVector *map[string]interface{}) (node *types.Node, err error) {
 	log.Debug("CreateNode")
 
-	data, status, err := cl.concertoService.Post("/v1/kaas/ships", nodeVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &node); err != nil {
+	if err = utils.PostJSON(cl.concertoService, "/v1/kaas/ships", nodeVector, &node); err != nil {
 		return nil, err
 	}
 
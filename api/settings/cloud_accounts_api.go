@@ -1,7 +1,6 @@
 package settings
 
 import (
-	"encoding/json"
 	"fmt"
 
 	log "github.com/Sirupsen/logrus"
@@ -29,16 +28,7 @@ func NewCloudAccountService(concertoService utils.ConcertoService) (*CloudAccoun
 func (ca *CloudAccountService) GetCloudAccountList() (cloudAccounts []types.CloudAccount, err error) {
 	log.Debug("GetCloudAccountList")
 
-	data, status, err := ca.concertoService.Get("/v1/settings/cloud_accounts")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &cloudAccounts); err != nil {
+	if err = utils.GetJSON(ca.concertoService, "/v1/settings/cloud_accounts", &cloudAccounts); err != nil {
 		return nil, err
 	}
 
@@ -49,16 +39,7 @@ func (ca *CloudAccountService) GetCloudAccountList() (cloudAccounts []types.Clou
 func (ca *CloudAccountService) CreateCloudAccount(cloudAccountVector *map[string]interface{}) (cloudAccount *types.CloudAccount, err error) {
 	log.Debug("CreateCloudAccount")
 
-	data, status, err := ca.concertoService.Post("/v1/settings/cloud_accounts/", cloudAccountVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &cloudAccount); err != nil {
+	if err = utils.PostJSON(ca.concertoService, "/v1/settings/cloud_accounts/", cloudAccountVector, &cloudAccount); err != nil {
 		return nil, err
 	}
 
@@ -69,16 +50,7 @@ func (ca *CloudAccountService) CreateCloudAccount(cloudAccountVector *map[string
 func (ca *CloudAccountService) UpdateCloudAccount(cloudAccountVector *map[string]interface{}, ID string) (cloudAccount *types.CloudAccount, err error) {
 	log.Debug("UpdateCloudAccount")
 
-	data, status, err := ca.concertoService.Put(fmt.Sprintf("/v1/settings/cloud_accounts/%s", ID), cloudAccountVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &cloudAccount); err != nil {
+	if err = utils.PutJSON(ca.concertoService, fmt.Sprintf("/v1/settings/cloud_accounts/%s", ID), cloudAccountVector, &cloudAccount); err != nil {
 		return nil, err
 	}
 
@@ -1,7 +1,6 @@
 package settings
 
 import (
-	"encoding/json"
 	"fmt"
 
 	log "github.com/Sirupsen/logrus"
@@ -29,16 +28,7 @@ func NewSaasAccountService(concertoService utils.ConcertoService) (*SaasAccountS
 func (dm *SaasAccountService) GetSaasAccountList() (saasAccounts []types.SaasAccount, err error) {
 	log.Debug("GetSaasAccountList")
 
-	data, status, err := dm.concertoService.Get("/v1/settings/saas_accounts")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &saasAccounts); err != nil {
+	if err = utils.GetJSON(dm.concertoService, "/v1/settings/saas_accounts", &saasAccounts); err != nil {
 		return nil, err
 	}
 
@@ -49,16 +39,7 @@ func (dm *SaasAccountService) GetSaasAccountList() (saasAccounts []types.SaasAcc
 func (dm *SaasAccountService) CreateSaasAccount(saasAccountVector *map[string]interface{}) (saasAccount *types.SaasAccount, err error) {
 	log.Debug("CreateSaasAccount")
 
-	data, status, err := dm.concertoService.Post("/v1/settings/saas_accounts/", saasAccountVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &saasAccount); err != nil {
+	if err = utils.PostJSON(dm.concertoService, "/v1/settings/saas_accounts/", saasAccountVector, &saasAccount); err != nil {
 		return nil, err
 	}
 
@@ -69,16 +50,7 @@ func (dm *SaasAccountService) CreateSaasAccount(saasAccountVector *map[string]in
 func (dm *SaasAccountService) UpdateSaasAccount(saasAccountVector *map[string]interface{}, ID string) (saasAccount *types.SaasAccount, err error) {
 	log.Debug("UpdateSaasAccount")
 
-	data, status, err := dm.concertoService.Put(fmt.Sprintf("/v1/settings/saas_accounts/%s", ID), saasAccountVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &saasAccount); err != nil {
+	if err = utils.PutJSON(dm.concertoService, fmt.Sprintf("/v1/settings/saas_accounts/%s", ID), saasAccountVector, &saasAccount); err != nil {
 		return nil, err
 	}
 
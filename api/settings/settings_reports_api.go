@@ -1,7 +1,6 @@
 package settings
 
 import (
-	"encoding/json"
 	"fmt"
 
 	log "github.com/Sirupsen/logrus"
@@ -30,16 +29,7 @@ func NewSettingsReportService(concertoService utils.ConcertoService) (*SettingsR
 func (rs *SettingsReportService) GetSettingsReportList() (reports []types.SettingsReport, err error) {
 	log.Debug("GetReportList")
 
-	data, status, err := rs.concertoService.Get("/v1/settings/reports")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &reports); err != nil {
+	if err = utils.GetJSON(rs.concertoService, "/v1/settings/reports", &reports); err != nil {
 		return nil, err
 	}
 
@@ -50,16 +40,7 @@ func (rs *SettingsReportService) GetSettingsReportList() (reports []types.Settin
 func (rs *SettingsReportService) GetSettingsReport(ID string) (report *types.SettingsReport, err error) {
 	log.Debug("GetReport")
 
-	data, status, err := rs.concertoService.Get(fmt.Sprintf("/v1/settings/reports/%s", ID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &report); err != nil {
+	if err = utils.GetJSON(rs.concertoService, fmt.Sprintf("/v1/settings/reports/%s", ID), &report); err != nil {
 		return nil, err
 	}
 
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 )
 
+// WizardApp stores an application the wizard can deploy, and the server flavour it requires
 type WizardApp struct {
 	Id                   string          `json:"id" header:"ID"`
 	Name                 string          `json:"name" header:"NAME"`
@@ -1,8 +1,10 @@
 package types
 
+// CloudAccount stores the credentials of a cloud provider account, which are never returned by the API
 type CloudAccount struct {
 	Id          string `json:"id" header:"ID"`
 	CloudProvId string `json:"cloud_provider_id" header:"CLOUD_PROVIDER_ID"`
 }
 
+// RequiredCredentials holds the credentials a cloud provider requires, by name
 type RequiredCredentials interface{}
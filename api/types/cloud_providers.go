@@ -1,8 +1,19 @@
 package types
 
+// CloudProvider stores a cloud provider, the credentials its accounts require, and the services it provides
 type CloudProvider struct {
 	Id                  string   `json:"id" header:"ID"`
 	Name                string   `json:"name" header:"NAME"`
 	RequiredCredentials []string `json:"required_credentials" header:"REQUIRED_CREDENTIALS"`
 	ProvidedServices    []string `json:"provided_services" header:"PROVIDED_SERVICES"`
 }
+
+// CloudProviderDetails describes a cloud provider with the regions and server plans its accounts can use
+type CloudProviderDetails struct {
+	Id                  string       `json:"id" header:"ID"`
+	Name                string       `json:"name" header:"NAME"`
+	RequiredCredentials []string     `json:"required_credentials" header:"REQUIRED_CREDENTIALS"`
+	ProvidedServices    []string     `json:"provided_services" header:"PROVIDED_SERVICES"`
+	Regions             []Location   `json:"regions" header:"REGIONS"`
+	ServerPlans         []ServerPlan `json:"server_plans" header:"SERVER_PLANS"`
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"strings"
 	"time"
 )
 
@@ -12,3 +13,8 @@ type Event struct {
 	Header      string    `json:"header" header:"HEADER"`
 	Description string    `json:"description" header:"DESCRIPTION"`
 }
+
+// CloudEventAttributes returns the attributes of the event when printed as a CloudEvent
+func (e Event) CloudEventAttributes() (string, string, time.Time) {
+	return e.Id, "event." + strings.ToLower(e.Level), e.Timestamp
+}
@@ -1,5 +1,6 @@
 package types
 
+// FirewallProfile stores a set of rules applied to the firewall of the servers of a workspace
 type FirewallProfile struct {
 	Id          string `json:"id" header:"ID"`
 	Name        string `json:"name,omitempty" header:"NAME"`
@@ -8,9 +9,13 @@ type FirewallProfile struct {
 	Rules       []Rule `json:"rules,omitempty" header:"RULES"`
 }
 
+// Rule allows inbound traffic of a protocol to a range of ports from a source CIDR
 type Rule struct {
+	// Protocol is tcp, udp, icmp, icmpv4 or icmpv6
 	Protocol string `json:"ip_protocol" header:"IP_PROTOCOL"`
-	MinPort  int    `json:"min_port" header:"MIN_PORT"`
-	MaxPort  int    `json:"max_port" header:"MAX_PORT"`
-	CidrIp   string `json:"source" header:"SOURCE"`
+	// MinPort and MaxPort are the first and last ports of the range allowed
+	MinPort int `json:"min_port" header:"MIN_PORT"`
+	MaxPort int `json:"max_port" header:"MAX_PORT"`
+	// CidrIp is the source of the traffic allowed, such as 0.0.0.0/0
+	CidrIp string `json:"source" header:"SOURCE"`
 }
@@ -1,6 +1,11 @@
 package types
 
+// GenericImage stores an operating system image available in every cloud provider
 type GenericImage struct {
 	Id   string `json:"id" header:"ID"`
 	Name string `json:"name" header:"NAME"`
+	// OSFamily, OSVersion and Architecture are taken from the name when Concerto doesn't give them
+	OSFamily     string `json:"os_family,omitempty" header:"OS FAMILY"`
+	OSVersion    string `json:"os_version,omitempty" header:"OS VERSION"`
+	Architecture string `json:"architecture,omitempty" header:"ARCHITECTURE"`
 }
@@ -0,0 +1,92 @@
+package types
+
+import "strings"
+
+// HostFirewallPolicy holds the firewall rules of the host the agent runs on
+type HostFirewallPolicy struct {
+	Rules       []HostFirewallRule `json:"rules"`
+	Md5         string             `json:"md5,omitempty"`
+	ActualRules []HostFirewallRule `json:"actual_rules,omitempty"`
+}
+
+// Directions of the traffic firewall rules accept. Rules without direction accept ingress traffic
+const (
+	DirectionIngress = "ingress"
+	DirectionEgress  = "egress"
+)
+
+// HostFirewallRule holds a firewall rule of the host the agent runs on. Cidr is the source of ingress
+// traffic, and the destination of egress traffic
+type HostFirewallRule struct {
+	Cidr      string `json:"cidr_ip" header:"CIDR"`
+	Protocol  string `json:"ip_protocol" header:"PROTOCOL"`
+	MinPort   int    `json:"min_port" header:"MIN"`
+	MaxPort   int    `json:"max_port" header:"MAX"`
+	Direction string `json:"direction,omitempty" header:"DIRECTION"`
+}
+
+// Egress returns whether the rule accepts outbound traffic
+func (r HostFirewallRule) Egress() bool {
+	return strings.ToLower(r.Direction) == DirectionEgress
+}
+
+// Normalized returns the rule with its direction omitted when it's ingress, so that rules compare
+// regardless of how they give it
+func (r HostFirewallRule) Normalized() HostFirewallRule {
+	r.Direction = strings.ToLower(r.Direction)
+	if r.Direction == DirectionIngress {
+		r.Direction = ""
+	}
+	return r
+}
+
+// HasEgress returns whether any rule of the policy accepts outbound traffic, which is otherwise not filtered
+func (p HostFirewallPolicy) HasEgress() bool {
+	for _, r := range p.Rules {
+		if r.Egress() {
+			return true
+		}
+	}
+	return false
+}
+
+// ScriptCharacterization holds a script the agent has to execute, and its parameters
+type ScriptCharacterization struct {
+	Order      int               `json:"execution_order"`
+	UUID       string            `json:"uuid"`
+	Script     HostScript        `json:"script"`
+	Parameters map[string]string `json:"parameter_values"`
+}
+
+// HostScript holds the code and attachments of a script characterization
+type HostScript struct {
+	Code            string   `json:"code"`
+	UUID            string   `json:"uuid"`
+	AttachmentPaths []string `json:"attachment_paths"`
+}
+
+// ScriptConclusion holds the result of a script characterization executed by the agent
+type ScriptConclusion struct {
+	UUID       string `json:"script_characterization_id"`
+	Output     string `json:"output"`
+	ExitCode   int    `json:"exit_code"`
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at"`
+}
+
+// ScriptRun holds the result of a script run in the host on demand, out of its characterizations
+type ScriptRun struct {
+	ScriptID   string `json:"script_id"`
+	Output     string `json:"output"`
+	ExitCode   int    `json:"exit_code"`
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at"`
+}
+
+// HostRegistration identifies the host the agent runs on each time it polls the API
+type HostRegistration struct {
+	Hostname string `json:"hostname"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Version  string `json:"agent_version"`
+}
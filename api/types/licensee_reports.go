@@ -4,6 +4,7 @@ import (
 	"time"
 )
 
+// LicenseeReport holds the server usage of a licensee for a month
 type LicenseeReport struct {
 	Id            string     `json:"id" header:"ID"`
 	Year          int        `json:"year" header:"YEAR"`
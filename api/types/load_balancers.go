@@ -1,5 +1,6 @@
 package types
 
+// LoadBalancer stores a load balancer, and where its traffic is received
 type LoadBalancer struct {
 	Id                          string `json:"id" header:"ID"`
 	Name                        string `json:"name" header:"NAME"`
@@ -15,6 +16,7 @@ type LoadBalancer struct {
 	Traffic_out                 int    `json:"traffic_out" header:"TRAFFIC_OUT"`
 }
 
+// LBNode stores a server receiving the traffic of a load balancer
 type LBNode struct {
 	Id       string `json:"id" header:"ID"`
 	Name     string `json:"name" header:"NAME"`
@@ -1,6 +1,15 @@
 package types
 
+// Location stores a region where servers can be deployed
 type Location struct {
 	Id   string `json:"id" header:"ID"`
 	Name string `json:"name" header:"NAME"`
 }
+
+// String returns the name of the location, or its ID when it's unknown
+func (l Location) String() string {
+	if l.Name == "" {
+		return l.Id
+	}
+	return l.Name
+}
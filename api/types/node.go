@@ -1,5 +1,6 @@
 package types
 
+// Node stores a server of a cluster fleet
 type Node struct {
 	Id        string `json:"id" header:"ID"`
 	Name      string `json:"name" header:"NAME"`
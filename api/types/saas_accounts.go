@@ -1,8 +1,10 @@
 package types
 
+// SaasAccount stores the credentials of a SaaS provider account, which are never returned by the API
 type SaasAccount struct {
 	Id         string `json:"id" header:"ID"`
 	SaasProvId string `json:"saas_provider_id" header:"SAAS PROVIDER ID"`
 }
 
+// SaasRequiredCredentials holds the account data a SaaS provider requires, by name
 type SaasRequiredCredentials interface{}
@@ -1,5 +1,6 @@
 package types
 
+// SaasProvider stores a SaaS provider, and the account data it requires
 type SaasProvider struct {
 	Id                    string   `json:"id" header:"ID"`
 	Name                  string   `json:"name" header:"NAME"`
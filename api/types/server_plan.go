@@ -1,5 +1,8 @@
 package types
 
+import "fmt"
+
+// ServerPlan stores the size and location of the servers a cloud provider offers
 type ServerPlan struct {
 	Id              string  `json:"id" header:"ID"`
 	Name            string  `json:"name" header:"NAME"`
@@ -8,4 +11,11 @@ type ServerPlan struct {
 	Storage         int     `json:"storage" header:"STORAGE"`
 	LocationId      string  `json:"location_id" header:"LOCATION_ID"`
 	CloudProviderId string  `json:"cloud_provider_id" header:"CLOUD_PROVIDER_ID"`
+	// HourlyPrice is the price of running a server of the plan for an hour, when the cloud provider publishes it
+	HourlyPrice float32 `json:"hourly_price,omitempty" header:"HOURLY_PRICE"`
+}
+
+// String returns the name of the plan, followed by its ID
+func (p ServerPlan) String() string {
+	return fmt.Sprintf("%s (%s)", p.Name, p.Id)
 }
@@ -1,5 +1,10 @@
 package types
 
+import (
+	"encoding/json"
+)
+
+// Server stores a server, and the template and workspace it belongs to
 type Server struct {
 	Id             string `json:"id" header:"ID"`
 	Name           string `json:"name" header:"NAME"`
@@ -12,6 +17,7 @@ type Server struct {
 	Ssh_profile_id string `json:"ssh_profile_id" header:"SSH_PROFILE_ID"`
 }
 
+// Dns stores a DNS record of a server
 type Dns struct {
 	Id        string `json:"id" header:"ID"`
 	Name      string `json:"name" header:"NAME"`
@@ -21,10 +27,11 @@ type Dns struct {
 	Domain_id string `json:"domain_id" header:"DOMAIN_ID"`
 }
 
+// ScriptChar stores a script characterization of a server, the script and the parameter values it runs with
 type ScriptChar struct {
-	Id               string   `json:"id" header:"ID"`
-	Type             string   `json:"type" header:"TYPE"`
-	Parameter_values struct{} `json:"parameter_values" header:"PARAMETER_VALUES"`
-	Template_id      string   `json:"template_id" header:"TEMPLATE_ID"`
-	Script_id        string   `json:"script_id" header:"SCRIPT_ID"`
+	Id               string           `json:"id" header:"ID"`
+	Type             string           `json:"type" header:"TYPE"`
+	Parameter_values *json.RawMessage `json:"parameter_values" header:"PARAMETER_VALUES" show:"nolist"`
+	Template_id      string           `json:"template_id" header:"TEMPLATE_ID"`
+	Script_id        string           `json:"script_id" header:"SCRIPT_ID"`
 }
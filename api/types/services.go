@@ -1,5 +1,8 @@
 package types
 
+import "encoding/json"
+
+// Service stores a service of the blueprint catalog, and the recipes installing it
 type Service struct {
 	Id          string   `json:"id" header:"ID"`
 	Name        string   `json:"name" header:"NAME"`
@@ -7,4 +10,6 @@ type Service struct {
 	Public      bool     `json:"public" header:"PUBLIC"`
 	License     string   `json:"license" header:"LICENSE"`
 	Recipes     []string `json:"recipes"  header:"RECIPES"`
+	// Attributes are the configuration attributes the service honors, with their defaults
+	Attributes *json.RawMessage `json:"attributes,omitempty" header:"ATTRIBUTES" show:"nolist"`
 }
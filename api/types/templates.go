@@ -13,6 +13,9 @@ type Template struct {
 	ConfigurationAttributes *json.RawMessage `json:"configuration_attributes,omitempty" header:"CONFIGURATION ATTRIBUTES" show:"nolist"`
 }
 
+// TemplateScriptTypes are the types of script characterisations, in the order they're run
+var TemplateScriptTypes = []string{"boot", "operational", "migration", "shutdown"}
+
 // TemplateScript stores a templates' script info
 type TemplateScript struct {
 	ID              string           `json:"id" header:"ID"`
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Validator is implemented by models which can be checked before they're sent to the API
+// or applied in the host
+type Validator interface {
+	Validate() error
+}
+
+// Validate checks that the rule has a known protocol, a valid port range and a source CIDR
+func (r Rule) Validate() error {
+	return validateRule(r.Protocol, r.MinPort, r.MaxPort, r.CidrIp)
+}
+
+// Validate checks every rule of the firewall profile
+func (p FirewallProfile) Validate() error {
+	for i, rule := range p.Rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("Rule %d is invalid: %s", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the rule has a known protocol, a valid port range, a CIDR and a known direction
+func (r HostFirewallRule) Validate() error {
+	switch strings.ToLower(r.Direction) {
+	case "", DirectionIngress, DirectionEgress:
+	default:
+		return fmt.Errorf("Direction %q isn't ingress or egress", r.Direction)
+	}
+	return validateRule(r.Protocol, r.MinPort, r.MaxPort, r.Cidr)
+}
+
+// Validate checks every rule of the firewall policy
+func (p HostFirewallPolicy) Validate() error {
+	for i, rule := range p.Rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("Rule %d is invalid: %s", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validateRule(protocol string, minPort, maxPort int, cidr string) error {
+	switch strings.ToLower(protocol) {
+	case "tcp", "udp", "icmp", "icmpv4", "icmpv6":
+	default:
+		return fmt.Errorf("Protocol %q isn't tcp, udp, icmp, icmpv4 or icmpv6", protocol)
+	}
+	if minPort < 0 || maxPort > 65535 || minPort > maxPort {
+		return fmt.Errorf("Port range %d-%d must go from lower to higher port, within 0-65535", minPort, maxPort)
+	}
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		return fmt.Errorf("Source %q isn't a CIDR such as 0.0.0.0/0", cidr)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(Rule{Protocol: "tcp", MinPort: 22, MaxPort: 22, CidrIp: "0.0.0.0/0"}.Validate(), "Valid rules shouldn't fail")
+	assert.Nil(HostFirewallRule{Protocol: "UDP", MinPort: 0, MaxPort: 65535, Cidr: "10.0.0.0/8"}.Validate(), "Protocols should be case insensitive")
+
+	assert.Nil(HostFirewallRule{Protocol: "icmpv4", Cidr: "10.0.0.0/8"}.Validate(), "ICMP rules without ports shouldn't fail")
+	assert.NotNil(Rule{Protocol: "gre", MinPort: 22, MaxPort: 22, CidrIp: "0.0.0.0/0"}.Validate(), "Unknown protocols should fail")
+	assert.NotNil(Rule{Protocol: "tcp", MinPort: 443, MaxPort: 80, CidrIp: "0.0.0.0/0"}.Validate(), "Reversed port ranges should fail")
+	assert.NotNil(Rule{Protocol: "tcp", MinPort: 80, MaxPort: 70000, CidrIp: "0.0.0.0/0"}.Validate(), "Ports beyond 65535 should fail")
+	assert.NotNil(HostFirewallRule{Protocol: "tcp", MinPort: 80, MaxPort: 80, Cidr: "10.0.0.1; reboot"}.Validate(), "Invalid sources should fail")
+	assert.Nil(HostFirewallRule{Protocol: "tcp", MinPort: 443, MaxPort: 443, Cidr: "10.0.0.0/8", Direction: "Egress"}.Validate(), "Egress rules shouldn't fail")
+	assert.NotNil(HostFirewallRule{Protocol: "tcp", MinPort: 443, MaxPort: 443, Cidr: "10.0.0.0/8", Direction: "outbound"}.Validate(), "Unknown directions should fail")
+}
+
+func TestPolicyValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := HostFirewallPolicy{Rules: []HostFirewallRule{
+		{Protocol: "tcp", MinPort: 22, MaxPort: 22, Cidr: "0.0.0.0/0"},
+		{Protocol: "tcp", MinPort: 22, MaxPort: 22, Cidr: "0.0.0.0"},
+	}}
+	err := policy.Validate()
+	assert.NotNil(err, "Policies with invalid rules should fail")
+	assert.Contains(err.Error(), "Rule 2", "Error should point to the invalid rule")
+
+	profile := FirewallProfile{Rules: []Rule{{Protocol: "icmp", MinPort: 0, MaxPort: 0, CidrIp: "::/0"}}}
+	assert.Nil(profile.Validate(), "Valid profiles shouldn't fail")
+}
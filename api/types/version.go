@@ -0,0 +1,8 @@
+package types
+
+// APIVersion stores the version of the API, and the range of CLI versions it supports
+type APIVersion struct {
+	Version       string `json:"version" header:"VERSION"`
+	MinCLIVersion string `json:"min_cli_version,omitempty" header:"MIN CLI VERSION"`
+	MaxCLIVersion string `json:"max_cli_version,omitempty" header:"MAX CLI VERSION"`
+}
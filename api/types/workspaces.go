@@ -1,5 +1,6 @@
 package types
 
+// Workspace stores a group of servers sharing domain, SSH profile and firewall profile
 type Workspace struct {
 	Id                  string `json:"id" header:"ID"`
 	Name                string `json:"name" header:"NAME"`
@@ -9,6 +10,7 @@ type Workspace struct {
 	Firewall_profile_id string `json:"firewall_profile_id" header:"FIREWALL_PROFILE_ID"`
 }
 
+// WorkspaceServer stores a server of a workspace
 type WorkspaceServer struct {
 	Id             string `json:"id" header:"ID"`
 	Name           string `json:"name" header:"NAME"`
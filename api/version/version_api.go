@@ -0,0 +1,36 @@
+package version
+
+import (
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+)
+
+// VersionService queries the API version
+type VersionService struct {
+	concertoService utils.ConcertoService
+}
+
+// NewVersionService returns a Concerto version service
+func NewVersionService(concertoService utils.ConcertoService) (*VersionService, error) {
+	if concertoService == nil {
+		return nil, fmt.Errorf("Must initialize ConcertoService before using it")
+	}
+
+	return &VersionService{
+		concertoService: concertoService,
+	}, nil
+}
+
+// GetAPIVersion returns the API version, and the CLI versions it supports
+func (vs *VersionService) GetAPIVersion() (version *types.APIVersion, err error) {
+	log.Debug("GetAPIVersion")
+
+	if err = utils.GetJSON(vs.concertoService, "/v1/version", &version); err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
@@ -0,0 +1,111 @@
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// GetAPIVersionMocked test mocked function
+func GetAPIVersionMocked(t *testing.T, versionIn *types.APIVersion) *types.APIVersion {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	vs, err := NewVersionService(cs)
+	assert.Nil(err, "Couldn't load version service")
+	assert.NotNil(vs, "Version service not instanced")
+
+	// to json
+	vIn, err := json.Marshal(versionIn)
+	assert.Nil(err, "Version test data corrupted")
+
+	// call service
+	cs.On("Get", "/v1/version").Return(vIn, 200, nil)
+	versionOut, err := vs.GetAPIVersion()
+	assert.Nil(err, "Error getting version")
+	assert.Equal(*versionIn, *versionOut, "GetAPIVersion returned different version")
+
+	return versionOut
+}
+
+// GetAPIVersionFailErrMocked test mocked function
+func GetAPIVersionFailErrMocked(t *testing.T, versionIn *types.APIVersion) *types.APIVersion {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	vs, err := NewVersionService(cs)
+	assert.Nil(err, "Couldn't load version service")
+	assert.NotNil(vs, "Version service not instanced")
+
+	// to json
+	vIn, err := json.Marshal(versionIn)
+	assert.Nil(err, "Version test data corrupted")
+
+	// call service
+	cs.On("Get", "/v1/version").Return(vIn, 200, fmt.Errorf("Mocked error"))
+	versionOut, err := vs.GetAPIVersion()
+
+	assert.NotNil(err, "We are expecting an error")
+	assert.Nil(versionOut, "Expecting nil output")
+	assert.Equal(err.Error(), "Mocked error", "Error should be 'Mocked error'")
+
+	return versionOut
+}
+
+// GetAPIVersionFailStatusMocked test mocked function
+func GetAPIVersionFailStatusMocked(t *testing.T, versionIn *types.APIVersion) *types.APIVersion {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	vs, err := NewVersionService(cs)
+	assert.Nil(err, "Couldn't load version service")
+	assert.NotNil(vs, "Version service not instanced")
+
+	// to json
+	vIn, err := json.Marshal(versionIn)
+	assert.Nil(err, "Version test data corrupted")
+
+	// call service
+	cs.On("Get", "/v1/version").Return(vIn, 499, nil)
+	versionOut, err := vs.GetAPIVersion()
+
+	assert.NotNil(err, "We are expecting an status code error")
+	assert.Nil(versionOut, "Expecting nil output")
+	assert.Contains(err.Error(), "499", "Error should contain http code 499")
+
+	return versionOut
+}
+
+// GetAPIVersionFailJSONMocked test mocked function
+func GetAPIVersionFailJSONMocked(t *testing.T, versionIn *types.APIVersion) *types.APIVersion {
+
+	assert := assert.New(t)
+
+	// wire up
+	cs := &utils.MockConcertoService{}
+	vs, err := NewVersionService(cs)
+	assert.Nil(err, "Couldn't load version service")
+	assert.NotNil(vs, "Version service not instanced")
+
+	// wrong json
+	vIn := []byte{10, 20, 30}
+
+	// call service
+	cs.On("Get", "/v1/version").Return(vIn, 200, nil)
+	versionOut, err := vs.GetAPIVersion()
+
+	assert.NotNil(err, "We are expecting a marshalling error")
+	assert.Nil(versionOut, "Expecting nil output")
+	assert.Contains(err.Error(), "invalid character", "Error message should include the string 'invalid character'")
+
+	return versionOut
+}
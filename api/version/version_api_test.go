@@ -0,0 +1,22 @@
+package version
+
+import (
+	"github.com/flexiant/concerto/testdata"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewVersionServiceNil(t *testing.T) {
+	assert := assert.New(t)
+	rs, err := NewVersionService(nil)
+	assert.Nil(rs, "Uninitialized service should return nil")
+	assert.NotNil(err, "Uninitialized service should return error")
+}
+
+func TestGetAPIVersion(t *testing.T) {
+	versionIn := testdata.GetAPIVersionData()
+	GetAPIVersionMocked(t, versionIn)
+	GetAPIVersionFailErrMocked(t, versionIn)
+	GetAPIVersionFailStatusMocked(t, versionIn)
+	GetAPIVersionFailJSONMocked(t, versionIn)
+}
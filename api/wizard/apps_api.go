@@ -1,7 +1,6 @@
 package wizard
 
 import (
-	"encoding/json"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
@@ -28,16 +27,7 @@ func NewAppService(concertoService utils.ConcertoService) (*AppService, error) {
 func (as *AppService) GetAppList() (apps []types.WizardApp, err error) {
 	log.Debug("GetAppList")
 
-	data, status, err := as.concertoService.Get("/v1/wizard/apps")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &apps); err != nil {
+	if err = utils.GetJSON(as.concertoService, "/v1/wizard/apps", &apps); err != nil {
 		return nil, err
 	}
 
@@ -48,16 +38,7 @@ func (as *AppService) GetAppList() (apps []types.WizardApp, err error) {
 func (as *AppService) DeployApp(appVector *map[string]interface{}) (app *types.WizardApp, err error) {
 	log.Debug("DeployApp")
 
-	data, status, err := as.concertoService.Post("/v1/wizard/apps/", appVector)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &app); err != nil {
+	if err = utils.PostJSON(as.concertoService, "/v1/wizard/apps/", appVector, &app); err != nil {
 		return nil, err
 	}
 
@@ -1,7 +1,6 @@
 package wizard
 
 import (
-	"encoding/json"
 	"fmt"
 
 	log "github.com/Sirupsen/logrus"
@@ -29,16 +28,7 @@ func NewLocationService(concertoService utils.ConcertoService) (*LocationService
 func (dm *LocationService) GetLocationList() (locations []types.Location, err error) {
 	log.Debug("GetLocationList")
 
-	data, status, err := dm.concertoService.Get("/v1/wizard/locations")
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &locations); err != nil {
+	if err = utils.GetJSON(dm.concertoService, "/v1/wizard/locations", &locations); err != nil {
 		return nil, err
 	}
 
@@ -1,7 +1,6 @@
 package wizard
 
 import (
-	"encoding/json"
 	"fmt"
 
 	log "github.com/Sirupsen/logrus"
@@ -29,16 +28,7 @@ func NewWizCloudProvidersService(concertoService utils.ConcertoService) (*WizClo
 func (dm *WizCloudProvidersService) GetWizCloudProviderList(AppID string, LocID string) (wizCloudProviderss []types.CloudProvider, err error) {
 	log.Debug("GetWizCloudProvidersList")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/wizard/cloud_providers?app_id=%s&location_id=%s", AppID, LocID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &wizCloudProviderss); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/wizard/cloud_providers?app_id=%s&location_id=%s", AppID, LocID), &wizCloudProviderss); err != nil {
 		return nil, err
 	}
 
@@ -1,7 +1,6 @@
 package wizard
 
 import (
-	"encoding/json"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/api/types"
@@ -28,16 +27,7 @@ func NewWizServerPlanService(concertoService utils.ConcertoService) (*WizServerP
 func (dm *WizServerPlanService) GetWizServerPlanList(AppID string, LocID string, ProviderID string) (serverPlans []types.ServerPlan, err error) {
 	log.Debug("GetWizServerPlanList")
 
-	data, status, err := dm.concertoService.Get(fmt.Sprintf("/v1/wizard/server_plans?app_id=%s&location_id=%s&cloud_provider_id=%s", AppID, LocID, ProviderID))
-	if err != nil {
-		return nil, err
-	}
-
-	if err = utils.CheckStandardStatus(status, data); err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(data, &serverPlans); err != nil {
+	if err = utils.GetJSON(dm.concertoService, fmt.Sprintf("/v1/wizard/server_plans?app_id=%s&location_id=%s&cloud_provider_id=%s", AppID, LocID, ProviderID), &serverPlans); err != nil {
 		return nil, err
 	}
 
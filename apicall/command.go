@@ -0,0 +1,22 @@
+package apicall
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+)
+
+// Command returns the api CLI command
+func Command() cli.Command {
+	return cli.Command{
+		Name:      "api",
+		Usage:     "Sends a request to any API endpoint, such as those not modelled by other commands, using the configured credentials",
+		ArgsUsage: "<GET|HEAD|POST|PUT|PATCH|DELETE> <path>",
+		Action:    cmd.APIRequest,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "data, d",
+				Usage: "JSON request body. Use @file to read it from a file, or @- from standard input",
+			},
+		},
+	}
+}
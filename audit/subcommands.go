@@ -1,6 +1,8 @@
 package audit
 
 import (
+	"time"
+
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/cmd"
 )
@@ -11,11 +13,72 @@ func SubCommands() []cli.Command {
 			Name:   "list_events",
 			Usage:  "Returns information about the events related to the account group.",
 			Action: cmd.EventList,
+			Flags:  cmd.ListFlags(),
+		},
+		{
+			Name:   "list",
+			Usage:  "Lists the events of the account group between --since and --until, following new ones with --follow, to trace who changed which blueprint or server.",
+			Action: cmd.EventListRange,
+			Flags: append([]cli.Flag{
+				cli.StringFlag{
+					Name:  "since",
+					Usage: "Only events from this date, such as 2016-01-02 or 2016-01-02T15:04:05Z, or this long ago, such as 30m, 2h or 7d",
+				},
+				cli.StringFlag{
+					Name:  "until",
+					Usage: "Only events up to this date, or this long ago, given as --since",
+				},
+				cli.BoolFlag{
+					Name:  "follow, f",
+					Usage: "Keep printing new events till --until passes or the command is interrupted",
+				},
+				cli.DurationFlag{
+					Name:  "interval",
+					Usage: "Time between checks for new events of --follow",
+					Value: 10 * time.Second,
+				},
+			}, cmd.ListFlags()...),
+		},
+		{
+			Name:   "show",
+			Usage:  "Shows information about a specific event.",
+			Action: cmd.EventShow,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "id",
+					Usage: "Event Id",
+				},
+			},
 		},
 		{
 			Name:   "list_system_events",
 			Usage:  "Returns information about system-wide events.",
 			Action: cmd.SysEventList,
+			Flags:  cmd.ListFlags(),
+		},
+		{
+			Name:   "subscribe",
+			Usage:  "Watches new events of the account group, printing them or running a command for each one.",
+			Action: cmd.EventSubscribe,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "exec",
+					Usage: "Command run for every event, receiving the event JSON on stdin",
+				},
+				cli.StringFlag{
+					Name:  "level",
+					Usage: "Only events with this level",
+				},
+				cli.StringFlag{
+					Name:  "match",
+					Usage: "Only events whose header or description match this regular expression",
+				},
+				cli.StringFlag{
+					Name:  "interval",
+					Usage: "Time between checks for new events",
+					Value: "30s",
+				},
+			},
 		},
 	}
 }
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+)
+
+func SubCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:   "sync",
+			Usage:  "Makes scripts and templates match the definitions in a git repository, creating, updating, reordering and deleting as needed.",
+			Action: cmd.BlueprintSync,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "repo",
+					Usage: "URL of the git repository holding scripts/*.json and templates/*.json definitions",
+				},
+				cli.StringFlag{
+					Name:  "branch",
+					Usage: "Branch of the repository to synchronize",
+					Value: "main",
+				},
+				cli.StringFlag{
+					Name:  "path",
+					Usage: "Directory of the repository holding the definitions",
+				},
+				cli.StringFlag{
+					Name:  "dir",
+					Usage: "Local directory where the repository is checked out. Defaults to a directory under the configuration directory",
+				},
+				cli.BoolFlag{
+					Name:  "prune",
+					Usage: "Delete scripts and templates which aren't defined in the repository",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Show the plan without applying it",
+				},
+			},
+		},
+		{
+			Name:   "apply",
+			Usage:  "Makes scripts, templates and servers match the definitions in a stack file, showing the changes needed and applying them once confirmed.",
+			Action: cmd.BlueprintApply,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "YAML file listing scripts, templates and servers definitions under scripts, templates and servers keys",
+				},
+				cli.BoolFlag{
+					Name:  "prune",
+					Usage: "Delete scripts and templates which aren't defined in the file, and servers of its templates which aren't either",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Show the plan without applying it",
+				},
+				cli.BoolFlag{
+					Name:  "yes",
+					Usage: "Apply the plan without asking for confirmation",
+				},
+			},
+		},
+		{
+			Name:   "export",
+			Usage:  "Writes templates, their script characterisations and the servers built from them as terraform resources, so that they can be managed with terraform.",
+			Action: cmd.BlueprintExport,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "Export format [ terraform ]",
+					Value: "terraform",
+				},
+				cli.StringFlag{
+					Name:  "template_id",
+					Usage: "Template Id. All templates are exported when not given",
+				},
+				cli.StringFlag{
+					Name:  "dir",
+					Usage: "Directory where exported files are written",
+					Value: ".",
+				},
+				cli.BoolFlag{
+					Name:  "skip-imports",
+					Usage: "Don't write import blocks adopting the exported resources",
+				},
+				cli.StringFlag{
+					Name:  "upload",
+					Usage: "S3 compatible location, such as s3://bucket/prefix, where exported files are uploaded",
+				},
+			},
+		},
+	}
+}
@@ -11,6 +11,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists all available scripts",
 			Action: cmd.ScriptsList,
+			Flags:  cmd.ListFlags(),
 		},
 		{
 			Name:   "show",
@@ -38,11 +39,11 @@ func SubCommands() []cli.Command {
 				},
 				cli.StringFlag{
 					Name:  "code",
-					Usage: "The script's code",
+					Usage: "The script's code, read from a file when given as @file, or from standard input with @-",
 				},
 				cli.StringFlag{
 					Name:  "parameters",
-					Usage: "The names of the script's parameters",
+					Usage: "The names of the script's parameters, comma separated such as PORT,VERSION, or as a JSON array",
 				},
 			},
 		},
@@ -65,22 +66,33 @@ func SubCommands() []cli.Command {
 				},
 				cli.StringFlag{
 					Name:  "code",
-					Usage: "The script's code",
+					Usage: "The script's code, read from a file when given as @file, or from standard input with @-",
 				},
 				cli.StringFlag{
 					Name:  "parameters",
-					Usage: "The names of the script's parameters",
+					Usage: "The names of the script's parameters, comma separated such as PORT,VERSION, or as a JSON array",
 				},
 			},
 		},
+		{
+			Name:   "list_templates",
+			Usage:  "Lists the templates running a script, with the type and execution order of each characterisation",
+			Action: cmd.ScriptTemplateList,
+			Flags: append([]cli.Flag{
+				cli.StringFlag{
+					Name:  "id",
+					Usage: "Script Id",
+				},
+			}, cmd.ListFlags()...),
+		},
 		{
 			Name:   "delete",
 			Usage:  "Deletes a script",
 			Action: cmd.ScriptDelete,
 			Flags: []cli.Flag{
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "id",
-					Usage: "Script Id",
+					Usage: "Script Id. Repeat it to delete several scripts",
 				},
 			},
 		},
@@ -11,6 +11,12 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists all available services",
 			Action: cmd.ServiceList,
+			Flags: append([]cli.Flag{
+				cli.BoolFlag{
+					Name:  "recipes",
+					Usage: "List the recipes of every service instead, as they're given in the service list of templates",
+				},
+			}, cmd.ListFlags()...),
 		},
 		{
 			Name:   "show",
@@ -21,6 +27,14 @@ func SubCommands() []cli.Command {
 					Name:  "id",
 					Usage: "Service Id",
 				},
+				cli.StringFlag{
+					Name:  "name",
+					Usage: "Service name, instead of its Id",
+				},
+				cli.BoolFlag{
+					Name:  "attributes",
+					Usage: "List the configuration attributes the service honors instead, by their path in the configuration attributes of templates, with their defaults",
+				},
 			},
 		},
 	}
@@ -1,6 +1,8 @@
 package templates
 
 import (
+	"time"
+
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/cmd"
 )
@@ -11,6 +13,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists all available templates",
 			Action: cmd.TemplateList,
+			Flags:  cmd.ListFlags(),
 		},
 		{
 			Name:   "show",
@@ -36,6 +39,10 @@ func SubCommands() []cli.Command {
 					Name:  "generic_image_id",
 					Usage: "Identifier of the OS image that the template builds on",
 				},
+				cli.StringFlag{
+					Name:  "generic_image",
+					Usage: "Name of the OS image that the template builds on, or some of its words such as \"ubuntu 14.04\", instead of --generic_image_id",
+				},
 				cli.StringFlag{
 					Name:  "service_list",
 					Usage: "A list of space separated service recipes that is run on the servers at start-up",
@@ -67,6 +74,14 @@ func SubCommands() []cli.Command {
 					Name:  "configuration_attributes",
 					Usage: "The attributes used to configure the services in the service_list",
 				},
+				cli.BoolFlag{
+					Name:  "merge-attributes",
+					Usage: "Deep merge the given configuration_attributes into the current ones, instead of replacing them",
+				},
+				cli.StringSliceFlag{
+					Name:  "unset",
+					Usage: "Path of a configuration attribute to remove, such as nginx.ssl.port. Repeat it to remove several attributes. Implies --merge-attributes",
+				},
 			},
 		},
 		{
@@ -74,9 +89,9 @@ func SubCommands() []cli.Command {
 			Usage:  "Deletes a template",
 			Action: cmd.TemplateDelete,
 			Flags: []cli.Flag{
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "id",
-					Usage: "Template Id",
+					Usage: "Template Id. Repeat it to delete several templates",
 				},
 			},
 		},
@@ -84,16 +99,16 @@ func SubCommands() []cli.Command {
 			Name:   "list_template_scripts",
 			Usage:  "Shows the script characterisations of a template",
 			Action: cmd.TemplateScriptList,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "template_id",
 					Usage: "Template Id",
 				},
 				cli.StringFlag{
 					Name:  "type",
-					Usage: "Must be \"operational\", \"boot\" or \"shutdown\"",
+					Usage: "Must be \"operational\", \"boot\", \"migration\" or \"shutdown\"",
 				},
-			},
+			}, cmd.ListFlags()...),
 		},
 		{
 			Name:   "show_template_script",
@@ -108,6 +123,10 @@ func SubCommands() []cli.Command {
 					Name:  "id",
 					Usage: "Script Id",
 				},
+				cli.BoolFlag{
+					Name:  "show-source",
+					Usage: "Also show the code of the script run, with the parameter values in place of the variables referencing them",
+				},
 			},
 		},
 		{
@@ -121,7 +140,7 @@ func SubCommands() []cli.Command {
 				},
 				cli.StringFlag{
 					Name:  "type",
-					Usage: "Must be \"operational\", \"boot\" or \"shutdown\"",
+					Usage: "Must be \"operational\", \"boot\", \"migration\" or \"shutdown\"",
 				},
 				cli.StringFlag{
 					Name:  "script_id",
@@ -129,7 +148,11 @@ func SubCommands() []cli.Command {
 				},
 				cli.StringFlag{
 					Name:  "parameter_values",
-					Usage: "A map that assigns a value to each script parameter. Example: '{\"param1\":\"val1\",\"param2\":\"val2\"}'",
+					Usage: "A map that assigns a value to each script parameter. Example: '{\"param1\":\"val1\",\"param2\":\"val2\"}'. Use @file to read it from a file, - to read it from standard input, or vault:<path> or keyring:<account> to read it from Vault or the OS keyring",
+				},
+				cli.StringSliceFlag{
+					Name:  "parameter",
+					Usage: "Assigns a value to a script parameter, such as param1=val1, taking precedence over --parameter_values. Repeat it to assign several parameters",
 				},
 			},
 		},
@@ -142,17 +165,25 @@ func SubCommands() []cli.Command {
 					Name:  "template_id",
 					Usage: "Template Id",
 				},
-				cli.StringFlag{
-					Name:  "script_id",
-					Usage: "Identifier for the script that is parameterised by the script characterisation",
-				},
 				cli.StringFlag{
 					Name:  "id",
 					Usage: "Identifier for the template-script that is parameterised by the script characterisation",
 				},
+				cli.StringFlag{
+					Name:  "type",
+					Usage: "Type of script characterisation to move it to, one of boot, operational, migration or shutdown. It's run after the scripts already of that type",
+				},
+				cli.StringFlag{
+					Name:  "script_id",
+					Usage: "Identifier for the script that is parameterised by the script characterisation, to make it run another script",
+				},
 				cli.StringFlag{
 					Name:  "parameter_values",
-					Usage: "A map that assigns a value to each script parameter. Example: '{\"param1\":\"val1\",\"param2\":\"val2\"}'",
+					Usage: "A map that assigns a value to each script parameter. Example: '{\"param1\":\"val1\",\"param2\":\"val2\"}'. Use @file to read it from a file, - to read it from standard input, or vault:<path> or keyring:<account> to read it from Vault or the OS keyring",
+				},
+				cli.StringSliceFlag{
+					Name:  "parameter",
+					Usage: "Assigns a value to a script parameter, such as param1=val1, taking precedence over --parameter_values. Repeat it to assign several parameters",
 				},
 			},
 		},
@@ -167,11 +198,23 @@ func SubCommands() []cli.Command {
 				},
 				cli.StringFlag{
 					Name:  "type",
-					Usage: "Must be \"operational\", \"boot\", or \"shutdown\"",
+					Usage: "Must be \"operational\", \"boot\", \"migration\" or \"shutdown\"",
 				},
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "script_ids",
-					Usage: "An array that must contain all the ids of scripts of the given template and type in the desired execution order",
+					Usage: "The ids of all the template scripts of the given template and type in the desired execution order, comma separated or as a JSON array. Repeat it to give them one by one",
+				},
+				cli.StringFlag{
+					Name:  "move",
+					Usage: "Id of a template script to move to the position given with --to, keeping the order of the others",
+				},
+				cli.IntFlag{
+					Name:  "to",
+					Usage: "Position, counted from 1, the template script given with --move is moved to",
+				},
+				cli.BoolFlag{
+					Name:  "edit",
+					Usage: "Open the current order in the editor set in $VISUAL or $EDITOR, one template script per line, to rearrange them",
 				},
 			},
 		},
@@ -184,9 +227,9 @@ func SubCommands() []cli.Command {
 					Name:  "template_id",
 					Usage: "Template Id",
 				},
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "id",
-					Usage: "Identifier for the template-script that is parameterised by the script characterisation",
+					Usage: "Identifier for the template-script that is parameterised by the script characterisation. Repeat it to delete several template scripts",
 				},
 			},
 		},
@@ -194,11 +237,111 @@ func SubCommands() []cli.Command {
 			Name:   "list_template_servers",
 			Usage:  "Returns information about the servers that use a specific template. ",
 			Action: cmd.TemplateServersList,
+			Flags: append([]cli.Flag{
+				cli.StringFlag{
+					Name:  "template_id",
+					Usage: "Template Id",
+				},
+				cli.StringFlag{
+					Name:  "state",
+					Usage: "Only list servers in one of these comma separated states, such as operational,bootstrapping",
+				},
+				cli.StringFlag{
+					Name:  "columns",
+					Usage: "Comma separated fields to print, in order, such as id,name,state,public_ip",
+				},
+				cli.BoolFlag{
+					Name:  "watch",
+					Usage: "Refresh the list every interval till interrupted, highlighting state changes",
+				},
+				cli.DurationFlag{
+					Name:  "interval",
+					Usage: "Time between refreshes of --watch",
+					Value: 5 * time.Second,
+				},
+			}, cmd.ListFlags()...),
+		},
+		{
+			Name:   "sync_scripts",
+			Usage:  "Makes the scripts of a template match the ones listed in the template_scripts.json file of a directory, creating or updating the scripts defined in its scripts subdirectory",
+			Action: cmd.TemplateSyncScripts,
 			Flags: []cli.Flag{
 				cli.StringFlag{
 					Name:  "template_id",
 					Usage: "Template Id",
 				},
+				cli.StringFlag{
+					Name:  "dir",
+					Usage: "Directory with the template_scripts.json file",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Show the changes without applying them",
+				},
+			},
+		},
+		{
+			Name:   "export",
+			Usage:  "Writes a template, its script characterisations and the scripts they run to a JSON file",
+			Action: cmd.TemplateExport,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "id",
+					Usage: "Template Id",
+				},
+				cli.StringFlag{
+					Name:  "file",
+					Usage: "File to write the template to",
+				},
+			},
+		},
+		{
+			Name:   "diff",
+			Usage:  "Shows the differences between a template file, written by export or in a blueprint directory, and the live template",
+			Action: cmd.TemplateDiff,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "id",
+					Usage: "Template Id",
+				},
+				cli.StringFlag{
+					Name:  "file",
+					Usage: "File to read the template from",
+				},
+			},
+		},
+		{
+			Name:   "validate",
+			Usage:  "Checks the services, configuration attributes and script parameter values of a template file against the catalog",
+			Action: cmd.TemplateValidate,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file",
+					Usage: "File to read the template from",
+				},
+				cli.BoolFlag{
+					Name:  "offline",
+					Usage: "Only check the file itself, without receiving services and scripts",
+				},
+			},
+		},
+		{
+			Name:   "import",
+			Usage:  "Creates or updates, by name, the template and scripts of a file written by export",
+			Action: cmd.TemplateImport,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file",
+					Usage: "File to read the template from",
+				},
+				cli.StringFlag{
+					Name:  "name",
+					Usage: "Name of the template, instead of the exported one",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Show the changes without applying them",
+				},
 			},
 		},
 	}
@@ -0,0 +1,21 @@
+package cache
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+)
+
+// Command returns the cache CLI command
+func Command() cli.Command {
+	return cli.Command{
+		Name:  "cache",
+		Usage: "Manages the API responses cached on disk",
+		Subcommands: []cli.Command{
+			{
+				Name:   "clear",
+				Usage:  "Removes every cached API response",
+				Action: cmd.CacheClear,
+			},
+		},
+	}
+}
@@ -11,6 +11,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists all available cloud providers.",
 			Action: cmd.CloudProviderList,
+			Flags:  cmd.ListFlags(),
 		},
 	}
 }
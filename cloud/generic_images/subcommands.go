@@ -11,6 +11,20 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "This action lists the available generic images.",
 			Action: cmd.GenericImageList,
+			Flags: append([]cli.Flag{
+				cli.StringFlag{
+					Name:  "os_family",
+					Usage: "Only list images of an OS family, such as ubuntu, centos, rhel or windows",
+				},
+				cli.StringFlag{
+					Name:  "os_version",
+					Usage: "Only list images of an OS version, such as 14.04, or of its minor versions, such as 14",
+				},
+				cli.StringFlag{
+					Name:  "architecture",
+					Usage: "Only list images of an architecture, such as x86_64 or arm64",
+				},
+			}, cmd.ListFlags()...),
 		},
 	}
 }
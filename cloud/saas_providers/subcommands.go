@@ -11,6 +11,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists the SaaS providers supported by the platform.",
 			Action: cmd.SaasProviderList,
+			Flags:  cmd.ListFlags(),
 		},
 	}
 }
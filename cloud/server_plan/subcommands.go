@@ -11,12 +11,16 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "This action lists the server plans offered by the cloud provider identified by the given id.",
 			Action: cmd.ServerPlanList,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "cloud_provider_id",
 					Usage: "Cloud provider id",
 				},
-			},
+				cli.StringFlag{
+					Name:  "location",
+					Usage: "Only list the server plans of a location, given by its id or name, such as \"London 1\"",
+				},
+			}, cmd.ListFlags()...),
 		},
 		{
 			Name:   "show",
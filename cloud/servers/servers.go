@@ -1,36 +1,48 @@
 package servers
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"text/tabwriter"
 	// "time"
 
 	// log "github.com/Sirupsen/logrus"
 	"github.com/codegangsta/cli"
-	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/api/client"
 	"github.com/flexiant/concerto/utils"
-	"github.com/flexiant/concerto/webservice"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/pool"
 )
 
 func cmdExecuteScript(c *cli.Context) error {
 	utils.FlagsRequired(c, []string{"server_id", "script_id"})
-	webservice, err := webservice.NewWebService()
-	utils.CheckError(err)
+	hc, err := client.Default()
+	if err != nil {
+		return err
+	}
 
-	err, res, code := webservice.Put(fmt.Sprintf("/v1/cloud/servers/%s/operational_scripts/%s/execute", c.String("server_id"), c.String("script_id")), nil)
-	utils.CheckError(err)
-	utils.CheckReturnCode(code, res)
+	serverIDs := c.StringSlice("server_id")
+	if len(serverIDs) > 1 {
+		f := format.GetFormatter()
+		results := pool.Run(serverIDs, func(serverID string) error {
+			_, err := hc.ExecuteServerScript(serverID, c.String("script_id"))
+			return err
+		})
+		if err := f.PrintList(results); err != nil {
+			f.PrintFatal("Couldn't print/format result", err)
+		}
+		if failed := pool.Failed(results); failed > 0 {
+			f.PrintFatal("Script execution didn't complete", fmt.Errorf("%d of %d servers failed", failed, len(serverIDs)))
+		}
+		return nil
+	}
 
-	var event types.Event
-	err = json.Unmarshal(res, &event)
-	utils.CheckError(err)
+	event, err := hc.ExecuteServerScript(serverIDs[0], c.String("script_id"))
+	if err != nil {
+		return err
+	}
 
-	w := tabwriter.NewWriter(os.Stdout, 15, 1, 3, ' ', 0)
-	fmt.Fprintln(w, "ID\tTIMESTAMP\tLEVEL\tHEADER\tDESCRIPTION\r")
-	fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%t\n", event.Id, event.Timestamp, event.Level, event.Header, event.Description)
-
-	w.Flush()
+	f := format.GetFormatter()
+	if err = f.PrintItem(*event); err != nil {
+		f.PrintFatal("Couldn't print/format result", err)
+	}
 	return nil
 }
@@ -1,6 +1,9 @@
 package servers
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/cmd"
 )
@@ -11,6 +14,17 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists information about all the servers on this account.",
 			Action: cmd.ServerList,
+			Flags: append([]cli.Flag{
+				cli.BoolFlag{
+					Name:  "watch",
+					Usage: "Refresh the list every interval till interrupted, highlighting state changes",
+				},
+				cli.DurationFlag{
+					Name:  "interval",
+					Usage: "Time between refreshes of --watch",
+					Value: 5 * time.Second,
+				},
+			}, cmd.ListFlags()...),
 		},
 		{
 			Name:   "show",
@@ -48,6 +62,19 @@ func SubCommands() []cli.Command {
 					Name:  "server_plan_id",
 					Usage: "Identifier of the server plan in which the server shall be deployed",
 				},
+				cli.StringFlag{
+					Name:  "ssh_profile_id",
+					Usage: "Identifier of the SSH profile whose keys the server shall accept. The one of the workspace is used by default",
+				},
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "CSV or YAML file defining several servers to create, with name, fqdn, template, plan, workspace, ssh_profile and labels. Templates, workspaces and SSH profiles may be given by name",
+				},
+				cli.BoolFlag{
+					Name:  "wait",
+					Usage: "Wait till the server is created, printing its progress. Servers created from a file are booted and waited till they get operational",
+				},
+				waitTimeoutFlag(),
 			},
 		},
 		{
@@ -69,93 +96,109 @@ func SubCommands() []cli.Command {
 				},
 			},
 		},
+		{
+			Name:   "rename",
+			Usage:  "Renames an existing server",
+			Action: cmd.ServerRename,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "id",
+					Usage: "Server Id",
+				},
+				cli.StringFlag{
+					Name:  "name",
+					Usage: "New name of the server",
+				},
+			},
+		},
 		{
 			Name:   "boot",
 			Usage:  "Boots a server with the given id",
 			Action: cmd.ServerBoot,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "id",
 					Usage: "Server Id",
 				},
-			},
+			}, waitFlags("gets operational")...),
 		},
 		{
 			Name:   "reboot",
 			Usage:  "Reboots a server with the given id",
 			Action: cmd.ServerReboot,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "id",
 					Usage: "Server Id",
 				},
-			},
+			}, waitFlags("gets operational again")...),
 		},
 		{
 			Name:   "shutdown",
 			Usage:  "Shuts down a server with the given id",
 			Action: cmd.ServerShutdown,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "id",
 					Usage: "Server Id",
 				},
-			},
+			}, waitFlags("is inactive")...),
 		},
 		{
-			Name:   "override_server",
-			Usage:  "This action takes the server with the given id from a stalled state to the operational state, at the user's own risk.",
-			Action: cmd.ServerOverride,
-			Flags: []cli.Flag{
+			Name:    "override_server",
+			Aliases: []string{"override"},
+			Usage:   "This action takes the server with the given id from a stalled state to the operational state, at the user's own risk.",
+			Action:  cmd.ServerOverride,
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "id",
 					Usage: "Server Id",
 				},
-			},
+			}, waitFlags("gets operational")...),
 		},
 		{
 			Name:   "delete",
 			Usage:  "This action decommissions the server with the given id. The server must be in a inactive, stalled or commission_stalled state.",
 			Action: cmd.ServerDelete,
-			Flags: []cli.Flag{
-				cli.StringFlag{
+			Flags: append([]cli.Flag{
+				cli.StringSliceFlag{
 					Name:  "id",
-					Usage: "Server Id",
+					Usage: "Server Id. Repeat it to delete several servers",
 				},
-			},
+			}, waitFlags("is decommissioned")...),
 		},
 		{
 			Name:   "list_dns_records",
 			Usage:  "This action returns information on the DNS records associated to the server with the given id.",
 			Action: cmd.DNSList,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "id",
 					Usage: "Server Id",
 				},
-			},
+			}, cmd.ListFlags()...),
 		},
 		{
 			Name:   "list_events",
 			Usage:  "This action returns information about the events related to the server with the given id.",
 			Action: cmd.EventsList,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "id",
 					Usage: "Server Id",
 				},
-			},
+			}, cmd.ListFlags()...),
 		},
 		{
 			Name:   "list_operational_scripts",
 			Usage:  "This action returns information about the operational scripts characterisations related to the server with the given id.",
 			Action: cmd.OperationalScriptsList,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "id",
 					Usage: "Server Id",
 				},
-			},
+			}, cmd.ListFlags()...),
 		},
 		{
 			Name:  "execute_script",
@@ -163,9 +206,9 @@ func SubCommands() []cli.Command {
 			// Action: cmd.OperationalScriptExecute,
 			Action: cmdExecuteScript,
 			Flags: []cli.Flag{
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "server_id",
-					Usage: "Server Id",
+					Usage: "Server Id. Repeat it to run the script on several servers",
 				},
 				cli.StringFlag{
 					Name:  "script_id",
@@ -173,5 +216,87 @@ func SubCommands() []cli.Command {
 				},
 			},
 		},
+		{
+			Name:      "ssh",
+			Usage:     "Opens an SSH session on the server identified by the given id, or runs the given command.",
+			ArgsUsage: "[-- <command>]",
+			Action:    cmd.ServerSSH,
+			Flags:     sshFlags("id"),
+		},
+		{
+			Name:      "scp",
+			Usage:     "Copies files from and to the server identified by the given id. Server paths are prefixed with a colon.",
+			ArgsUsage: "-- <source>... <destination>",
+			Action:    cmd.ServerSCP,
+			Flags:     sshFlags("id"),
+		},
+		{
+			Name:      "exec",
+			Usage:     "Runs a command on the server identified by the given id, without allocating a terminal.",
+			ArgsUsage: "-- <command>",
+			Action:    cmd.ServerExec,
+			Flags:     sshFlags("id"),
+		},
+	}
+}
+
+// waitFlags are the flags of commands which can wait till servers reach the end of asynchronous operations
+func waitFlags(what string) []cli.Flag {
+	return []cli.Flag{
+		cli.BoolFlag{
+			Name:  "wait",
+			Usage: fmt.Sprintf("Wait till the server %s, printing its progress", what),
+		},
+		waitTimeoutFlag(),
+	}
+}
+
+func waitTimeoutFlag() cli.Flag {
+	return cli.StringFlag{
+		Name:  "timeout",
+		Usage: "Maximum time to wait for each server",
+		Value: "20m",
+	}
+}
+
+// SSHCommand returns the top level ssh command, opening SSH sessions on servers as servers ssh does
+func SSHCommand() cli.Command {
+	return cli.Command{
+		Name:      "ssh",
+		Usage:     "Opens an SSH session on the server identified by the given id, with its public IP and SSH profile key, or runs the given command.",
+		ArgsUsage: "[-- <command>]",
+		Action:    cmd.ServerSSH,
+		Flags:     sshFlags("server_id"),
+	}
+}
+
+// sshFlags are the flags shared by commands reaching servers with OpenSSH clients, identifying the server by idFlag
+func sshFlags(idFlag string) []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  idFlag,
+			Usage: "Server Id",
+		},
+		cli.StringFlag{
+			Name:  "user",
+			Usage: "User to log in as",
+			Value: "root",
+		},
+		cli.StringFlag{
+			Name:  "bastion",
+			Usage: "Jump host ([user@]host[:port]) used to reach servers in private networks. Defaults to the bastion of the server workspace in the configuration file",
+		},
+		cli.BoolFlag{
+			Name:  "agent",
+			Usage: "Authenticate with the keys in ssh-agent instead of the server SSH profile",
+		},
+		cli.StringFlag{
+			Name:  "known_hosts",
+			Usage: "Known hosts file used to verify server keys. Defaults to known_hosts in the configuration directory",
+		},
+		cli.BoolFlag{
+			Name:  "strict_host_key_checking",
+			Usage: "Refuse to connect to servers whose key isn't in the known hosts file",
+		},
 	}
 }
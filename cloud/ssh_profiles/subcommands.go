@@ -11,6 +11,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists all available SSH profiles.",
 			Action: cmd.SSHProfileList,
+			Flags:  cmd.ListFlags(),
 		},
 		{
 			Name:   "show",
@@ -34,11 +35,11 @@ func SubCommands() []cli.Command {
 				},
 				cli.StringFlag{
 					Name:  "public_key",
-					Usage: "Public key of the SSH profile",
+					Usage: "Public key of the SSH profile, or @ followed by the file holding it, such as @~/.ssh/id_rsa.pub",
 				},
 				cli.StringFlag{
 					Name:  "private_key",
-					Usage: "Private key of the SSH profile",
+					Usage: "Private key of the SSH profile, or @ followed by the file holding it, such as @~/.ssh/id_rsa",
 				},
 			},
 		},
@@ -57,22 +58,23 @@ func SubCommands() []cli.Command {
 				},
 				cli.StringFlag{
 					Name:  "public_key",
-					Usage: "Public key of the SSH profile",
+					Usage: "Public key of the SSH profile, or @ followed by the file holding it, such as @~/.ssh/id_rsa.pub",
 				},
 				cli.StringFlag{
 					Name:  "private_key",
-					Usage: "Private key of the SSH profile",
+					Usage: "Private key of the SSH profile, or @ followed by the file holding it, such as @~/.ssh/id_rsa",
 				},
 			},
 		},
 		{
-			Name:   "destroy",
-			Usage:  "Destroys an SSH profile",
-			Action: cmd.SSHProfileDelete,
+			Name:    "destroy",
+			Aliases: []string{"delete"},
+			Usage:   "Destroys an SSH profile",
+			Action:  cmd.SSHProfileDelete,
 			Flags: []cli.Flag{
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "id",
-					Usage: "SSH profile id",
+					Usage: "SSH profile id. Repeat it to delete several SSH profiles",
 				},
 			},
 		},
@@ -1,6 +1,8 @@
 package workspaces
 
 import (
+	"time"
+
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/cmd"
 )
@@ -11,6 +13,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists all available workspaces",
 			Action: cmd.WorkspaceList,
+			Flags:  cmd.ListFlags(),
 		},
 		{
 			Name:   "show",
@@ -78,9 +81,9 @@ func SubCommands() []cli.Command {
 			Usage:  "Deletes a workspace",
 			Action: cmd.WorkspaceDelete,
 			Flags: []cli.Flag{
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "id",
-					Usage: "Workspace Id",
+					Usage: "Workspace Id. Repeat it to delete several workspaces",
 				},
 			},
 		},
@@ -88,12 +91,21 @@ func SubCommands() []cli.Command {
 			Name:   "list_workspace_servers",
 			Usage:  "Shows  the servers belonging to the workspace identified by the given id.",
 			Action: cmd.WorkspaceServerList,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "workspace_id",
 					Usage: "Workspace Id",
 				},
-			},
+				cli.BoolFlag{
+					Name:  "watch",
+					Usage: "Refresh the list every interval till interrupted, highlighting state changes",
+				},
+				cli.DurationFlag{
+					Name:  "interval",
+					Usage: "Time between refreshes of --watch",
+					Value: 5 * time.Second,
+				},
+			}, cmd.ListFlags()...),
 		},
 	}
 }
@@ -11,6 +11,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists all available Clusters",
 			Action: cmd.ClusterList,
+			Flags:  cmd.ListFlags(),
 		},
 		{
 			Name:   "start",
@@ -64,21 +65,52 @@ func SubCommands() []cli.Command {
 			Name:   "delete",
 			Usage:  "Deletes a given Cluster",
 			Action: cmd.ClusterDelete,
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "id",
+					Usage: "Cluster Id. Repeat it to delete several clusters",
+				},
+			},
+		},
+		{
+			Name:   "scale",
+			Usage:  "Changes the number of nodes of a given Cluster",
+			Action: cmd.ClusterScale,
 			Flags: []cli.Flag{
 				cli.StringFlag{
 					Name:  "id",
 					Usage: "Cluster Id",
 				},
+				cli.StringFlag{
+					Name:  "slave_count",
+					Usage: "Number of slave nodes",
+				},
 			},
 		},
 		{
-			Name:   "kubectl",
-			Usage:  "Kubectl command line wrapper",
-			Action: cmdKubectlHijack,
+			Name:   "kubeconfig",
+			Usage:  "Downloads kubeconfig of a given Cluster",
+			Action: cmd.ClusterKubeconfig,
 			Flags: []cli.Flag{
 				cli.StringFlag{
 					Name:  "cluster",
-					Usage: "Cluster Name",
+					Usage: "Cluster Name or Id",
+				},
+				cli.StringFlag{
+					Name:  "dir",
+					Usage: "Directory where kubeconfig is written. Defaults to concerto configuration directory",
+				},
+			},
+		},
+		{
+			Name:      "kubectl",
+			Usage:     "Kubectl command line wrapper",
+			ArgsUsage: "-- <kubectl arguments>",
+			Action:    cmd.ClusterKubectl,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "cluster",
+					Usage: "Cluster Name or Id",
 				},
 			},
 		},
@@ -1,60 +1,139 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/admin"
-	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/flags"
 	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/timerange"
+	"github.com/flexiant/concerto/utils/usage"
 )
 
 // WireUpReport prepares common resources to send request to Concerto API
 func WireUpReport(c *cli.Context) (ns *admin.ReportService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ns, err = admin.NewReportService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up report service", err)
-	}
-
-	return ns, f
+	return wireUpClient(f).AdminReports, f
 }
 
-// AdminReportList subcommand function
+// AdminReportList subcommand function. Lists the reports with some time between --from and --to or,
+// with --group-by, the consumption of their servers within that time
 func AdminReportList(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	reportSvc, formatter := WireUpReport(c)
 
+	r := reportRange(c, formatter)
 	reports, err := reportSvc.GetAdminReportList()
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive report data", err)
 	}
-	if err = formatter.PrintList(reports); err != nil {
-		formatter.PrintFatal("Couldn't print/format result", err)
+	inRange := []types.Report{}
+	for _, report := range reports {
+		if usage.Overlaps(report, r) {
+			inRange = append(inRange, report)
+		}
+	}
+	if !c.IsSet("group-by") {
+		printReportResult(c, formatter, inRange)
+		return nil
+	}
+
+	// lines only come with the details of each report
+	for i, report := range inRange {
+		detailed, err := reportSvc.GetAdminReport(report.ID)
+		if err != nil {
+			formatter.PrintFatal("Couldn't receive report data", err)
+		}
+		inRange[i] = *detailed
 	}
+	printConsumption(c, formatter, inRange, r)
 	return nil
 }
 
-// AdminReportShow subcommand function
+// AdminReportShow subcommand function. Shows a report with the lines running some time between --from
+// and --to or, with --group-by, the consumption of its servers within that time
 func AdminReportShow(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	reportSvc, formatter := WireUpReport(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
+	r := reportRange(c, formatter)
 	report, err := reportSvc.GetAdminReport(c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive report data", err)
 	}
-	if err = formatter.PrintList(report); err != nil {
+	if c.IsSet("group-by") {
+		printConsumption(c, formatter, []types.Report{*report}, r)
+		return nil
+	}
+
+	*report = usage.Within(*report, r)
+	if c.IsSet("csv") {
+		exportReportCSV(c, formatter, report.Lines)
+	}
+	if err = formatter.PrintItem(*report); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
+	// text output shows report lines as a table of their own
+	if _, ok := formatter.(*format.TextFormatter); ok {
+		if err = formatter.PrintList(report.Lines); err != nil {
+			formatter.PrintFatal("Couldn't print/format result", err)
+		}
+	}
 	return nil
 }
+
+// reportRange checks the report flags, and returns the range given by --from and --to
+func reportRange(c *cli.Context, f format.Formatter) timerange.Range {
+	validateFlags(c, flags.New(c).Enum("group-by", usage.Groupings...), f)
+	var r timerange.Range
+	var err error
+	now := time.Now()
+	if r.Since, err = timerange.ParseTime(c.String("from"), now); err != nil {
+		f.PrintFatal("Incorrect usage.", exit.NewValidationError(fmt.Errorf("Invalid --from %s. %s", c.String("from"), err)))
+	}
+	if r.Until, err = timerange.ParseTime(c.String("to"), now); err != nil {
+		f.PrintFatal("Incorrect usage.", exit.NewValidationError(fmt.Errorf("Invalid --to %s. %s", c.String("to"), err)))
+	}
+	if !r.Since.IsZero() && !r.Until.IsZero() && r.Until.Before(r.Since) {
+		f.PrintFatal("Incorrect usage.", exit.NewValidationError(fmt.Errorf("--to %s is before --from %s", c.String("to"), c.String("from"))))
+	}
+	return r
+}
+
+func printConsumption(c *cli.Context, f format.Formatter, reports []types.Report, r timerange.Range) {
+	consumption, err := usage.Aggregate(reports, r, c.String("group-by"))
+	if err != nil {
+		f.PrintFatal("Couldn't aggregate consumption", err)
+	}
+	printReportResult(c, f, consumption)
+}
+
+// printReportResult prints items, exporting them to the --csv file as well when given
+func printReportResult(c *cli.Context, f format.Formatter, items interface{}) {
+	items = filterList(c, f, items)
+	if c.IsSet("csv") {
+		exportReportCSV(c, f, items)
+	}
+	if err := f.PrintList(items); err != nil {
+		f.PrintFatal("Couldn't print/format result", err)
+	}
+}
+
+// exportReportCSV writes items to the --csv file, whatever the output format, so that they can be
+// opened as a spreadsheet
+func exportReportCSV(c *cli.Context, f format.Formatter, items interface{}) {
+	file, err := os.Create(c.String("csv"))
+	if err != nil {
+		f.PrintFatal("Couldn't export to CSV", err)
+	}
+	defer file.Close()
+	if err = format.NewCSVFormatter(file).PrintList(items); err != nil {
+		f.PrintFatal("Couldn't export to CSV", fmt.Errorf("%s: %s", c.String("csv"), err))
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/format"
+)
+
+// apiMethods lists the methods accepted by the api command
+var apiMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}
+
+// APIRequest command function. Sends a request to any API endpoint using the configured
+// credentials, and prints the response with the selected output format
+func APIRequest(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+
+	if len(c.Args()) != 2 {
+		formatter.PrintFatal("Incorrect usage", fmt.Errorf("Please, provide the method and the path, such as GET /v1/blueprint/templates"))
+	}
+	method := strings.ToUpper(c.Args()[0])
+	if !isAPIMethod(method) {
+		formatter.PrintFatal("Incorrect usage", fmt.Errorf("Unsupported method %s. Please, use one of %s", method, strings.Join(apiMethods, ", ")))
+	}
+	path := c.Args()[1]
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	var body io.Reader
+	if c.IsSet("data") {
+		if method == "GET" || method == "HEAD" || method == "DELETE" {
+			formatter.PrintFatal("Incorrect usage", fmt.Errorf("%s requests can't send data", method))
+		}
+		data, err := readAPIData(c.String("data"))
+		if err != nil {
+			formatter.PrintFatal("Couldn't read request data", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	config, err := utils.GetConcertoConfig()
+	if err != nil {
+		formatter.PrintFatal("Couldn't wire up config", err)
+	}
+	hcs, err := utils.NewHTTPConcertoService(config)
+	if err != nil {
+		formatter.PrintFatal("Couldn't wire up concerto service", err)
+	}
+	data, status, err := hcs.Do(method, path, body, nil)
+	if _, ok := err.(*utils.HTTPError); ok {
+		formatter.PrintFatal(fmt.Sprintf("%s %s failed with status %d", method, path, status), err)
+	}
+	if err != nil {
+		formatter.PrintFatal(fmt.Sprintf("Couldn't send %s request", method), err)
+	}
+
+	if err = printAPIResponse(data, formatter); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// readAPIData reads request data given inline, from a file prefixed with @, or from standard input with @-.
+// Data must be JSON, as every API endpoint expects
+func readAPIData(value string) ([]byte, error) {
+	data, err := readFlagValue(value)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err = json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("Request data isn't valid JSON: %s", err)
+	}
+	return data, nil
+}
+
+// printAPIResponse prints JSON responses. Text output is indented JSON, as responses aren't modelled
+// as types, while other formats print arrays as lists so that line delimited outputs get one item per line
+func printAPIResponse(data []byte, f format.Formatter) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if _, ok := f.(*format.TextFormatter); ok {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", "  "); err != nil {
+			// not JSON, print it as received
+			indented.Reset()
+			indented.Write(data)
+		}
+		indented.WriteString("\n")
+		_, err := indented.WriteTo(format.Output())
+		return err
+	}
+
+	var response interface{}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("Response isn't valid JSON: %s", err)
+	}
+	if items, ok := response.([]interface{}); ok {
+		return f.PrintList(items)
+	}
+	return f.PrintItem(response)
+}
+
+func isAPIMethod(method string) bool {
+	for _, m := range apiMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
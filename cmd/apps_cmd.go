@@ -3,29 +3,13 @@ package cmd
 import (
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/wizard"
-	"github.com/flexiant/concerto/utils"
 	"github.com/flexiant/concerto/utils/format"
 )
 
 // WireUpApp prepares common resources to send request to Concerto API
 func WireUpApp(c *cli.Context) (ds *wizard.AppService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ds, err = wizard.NewAppService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up app service", err)
-	}
-
-	return ds, f
+	return wireUpClient(f).Apps, f
 }
 
 // AppList subcommand function
@@ -37,7 +21,7 @@ func AppList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive app data", err)
 	}
-	if err = formatter.PrintList(apps); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, apps)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -49,7 +33,7 @@ func AppDeploy(c *cli.Context) error {
 	appSvc, formatter := WireUpApp(c)
 
 	checkRequiredFlags(c, []string{"id", "location_id", "cloud_provider_id", "hostname", "domain_id"}, formatter)
-	app, err := appSvc.DeployApp(utils.FlagConvertParams(c))
+	app, err := appSvc.DeployApp(flagParams(c, formatter))
 	if err != nil {
 		formatter.PrintFatal("Couldn't deploy app", err)
 	}
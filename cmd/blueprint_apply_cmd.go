@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/cloud"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/manifest"
+	"github.com/flexiant/concerto/utils/notify"
+)
+
+// BlueprintApply subcommand function
+func BlueprintApply(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	scriptSvc, formatter := WireUpScript(c)
+	templateSvc, _ := WireUpTemplate(c)
+	serverSvc, _ := WireUpServer(c)
+	notify.Track()
+
+	checkRequiredFlags(c, []string{"file"}, formatter)
+	stack, err := manifest.LoadStack(c.String("file"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't read stack", err)
+	}
+
+	sync := &blueprintSync{
+		manifest:    &stack.Manifest,
+		scriptSvc:   scriptSvc,
+		templateSvc: templateSvc,
+		formatter:   formatter,
+		prune:       c.Bool("prune"),
+		dryRun:      true,
+	}
+	plan := planStack(c, stack, sync, serverSvc, formatter)
+
+	if c.Bool("dry-run") {
+		if err = formatter.PrintList(plan); err != nil {
+			formatter.PrintFatal("Couldn't print/format result", err)
+		}
+		return nil
+	}
+	if len(plan) == 0 {
+		log.Info("Nothing to apply")
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "The following changes will be applied:\n")
+	for _, ch := range plan {
+		fmt.Fprintf(os.Stderr, "\t%s %s %s\n", ch.Action, ch.Kind, ch.Name)
+	}
+	if !c.Bool("yes") {
+		fmt.Fprintf(os.Stderr, "Do you want to continue? [y/N]: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			formatter.PrintFatal("Stack not applied", fmt.Errorf("Changes weren't confirmed"))
+		}
+	}
+
+	// the live state may have changed while confirming, so it's planned again, and nothing is applied unless
+	// the plan is still the one confirmed. Changes are checked again while applying them, stopping at the
+	// first one made after this second plan
+	if !samePlan(plan, planStack(c, stack, sync, serverSvc, formatter)) {
+		formatter.PrintFatal("Stack not applied", fmt.Errorf("Stack changed after planning. Please, apply it again"))
+	}
+	sync.confirmed = make(map[manifest.Change]bool)
+	for _, ch := range plan {
+		sync.confirmed[ch] = true
+	}
+	sync.dryRun = false
+	changes, deletions := sync.apply()
+	params, serverChanges := planStackServers(c, stack, serverSvc, sync.templateIDs, formatter)
+	for _, ch := range serverChanges {
+		sync.checkConfirmed(ch)
+		changes = append(changes, applyStackServer(ch, params[ch.Name], serverSvc, formatter))
+	}
+	changes = append(changes, sync.applyDeletions(deletions)...)
+
+	log.Infof("Applied %d changes", len(changes))
+	if err = formatter.PrintList(changes); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// planStack returns the changes applying the stack needs, without applying any
+func planStack(c *cli.Context, stack *manifest.Stack, sync *blueprintSync, serverSvc *cloud.ServerService, formatter format.Formatter) []manifest.Change {
+	sync.dryRun = true
+	changes, deletions := sync.apply()
+	_, serverChanges := planStackServers(c, stack, serverSvc, sync.templateIDs, formatter)
+	return append(append(changes, serverChanges...), deletions...)
+}
+
+// samePlan returns whether both plans hold the same changes in the same order
+func samePlan(a []manifest.Change, b []manifest.Change) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// planStackServers resolves the servers of the stack, and returns their creation parameters by name and the
+// changes they need, with deletions last. templateIDs holds the IDs of templates by name, empty for those a
+// dry run would create
+func planStackServers(c *cli.Context, stack *manifest.Stack, serverSvc *cloud.ServerService, templateIDs map[string]string, formatter format.Formatter) (map[string]*map[string]interface{}, []manifest.Change) {
+	if len(stack.Servers) == 0 && !c.Bool("prune") {
+		return nil, nil
+	}
+	params, err := resolveServerDefinitions(wireUpServerReferences(c), stack.Servers, templateIDs)
+	if err != nil {
+		formatter.PrintFatal("Couldn't validate stack servers", err)
+	}
+	servers, err := serverSvc.GetServerList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive server data", err)
+	}
+
+	// servers may refer to templates by name or ID
+	ids := make(map[string]string)
+	for name, id := range templateIDs {
+		ids[name] = id
+		if id != "" {
+			ids[id] = id
+		}
+	}
+	changes, err := manifest.PlanServers(stack, servers, ids, c.Bool("prune"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't plan stack servers", err)
+	}
+	return params, changes
+}
+
+// applyStackServer creates, updates or deletes a server of the stack
+func applyStackServer(ch manifest.Change, params *map[string]interface{}, serverSvc *cloud.ServerService, formatter format.Formatter) manifest.Change {
+	var server *types.Server
+	var err error
+	switch ch.Action {
+	case manifest.Create:
+		server, err = serverSvc.CreateServer(params)
+	case manifest.Update:
+		server, err = serverSvc.UpdateServer(&map[string]interface{}{"fqdn": (*params)["fqdn"]}, ch.ID)
+	case manifest.Delete:
+		err = serverSvc.DeleteServer(ch.ID)
+	}
+	if err != nil {
+		formatter.PrintFatal(fmt.Sprintf("Couldn't %s server %s", ch.Action, ch.Name), err)
+	}
+	if server != nil {
+		ch.ID = server.Id
+	}
+	log.Infof("Server %s: %s", ch.Name, ch.Action)
+	return ch
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/terraform"
+)
+
+// blueprintExportFormats lists the formats blueprints can be exported to
+var blueprintExportFormats = []string{"terraform"}
+
+// BlueprintExport subcommand function. Writes templates, their script characterisations and the servers
+// built from them as terraform resources referring to each other, so that they can be adopted by terraform
+func BlueprintExport(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	templateSvc, formatter := WireUpTemplate(c)
+	serverSvc, _ := WireUpServer(c)
+
+	if f := c.String("format"); f != "terraform" {
+		formatter.PrintFatal("Incorrect usage.", exit.NewValidationError(fmt.Errorf("Unsupported export format %s. Please, use one of [ %s ]", f, strings.Join(blueprintExportFormats, " | "))))
+	}
+
+	var templates []types.Template
+	if id := c.String("template_id"); id != "" {
+		template, err := templateSvc.GetTemplate(id)
+		if err != nil {
+			formatter.PrintFatal("Couldn't receive template data", err)
+		}
+		templates = append(templates, *template)
+	} else {
+		var err error
+		if templates, err = templateSvc.GetTemplateList(); err != nil {
+			formatter.PrintFatal("Couldn't receive template data", err)
+		}
+	}
+
+	servers, err := serverSvc.GetServerList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive server data", err)
+	}
+	serversByTemplate := make(map[string][]types.Server)
+	for _, s := range servers {
+		serversByTemplate[s.Template_id] = append(serversByTemplate[s.Template_id], s)
+	}
+
+	// templates are named first, so that references to them use their final names
+	var templateResources []terraform.Resource
+	for _, t := range templates {
+		templateResources = append(templateResources, templateResource(t))
+	}
+	terraform.SortByName(templateResources)
+	terraform.UniqueNames(templateResources)
+
+	resources := append([]terraform.Resource{}, templateResources...)
+	for _, tr := range templateResources {
+		for _, scriptType := range types.TemplateScriptTypes {
+			tss, err := templateSvc.GetTemplateScriptList(tr.ID, scriptType)
+			if err != nil {
+				formatter.PrintFatal("Couldn't receive template script data", err)
+			}
+			for _, ts := range *tss {
+				resources = append(resources, templateScriptResource(tr, ts))
+			}
+		}
+		for _, s := range serversByTemplate[tr.ID] {
+			resources = append(resources, serverResource(s, terraform.Ref(tr)))
+		}
+	}
+	terraform.UniqueNames(resources)
+	terraform.SortByName(resources)
+
+	dir := c.String("dir")
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		formatter.PrintFatal("Couldn't create export directory", err)
+	}
+	path := filepath.Join(dir, "blueprint.tf")
+	if err = writeTerraformFile(path, resources, terraform.WriteResources); err != nil {
+		formatter.PrintFatal("Couldn't write terraform file", err)
+	}
+	exported := []ExportedFile{{File: path, Resources: len(resources)}}
+	if !c.Bool("skip-imports") {
+		path = filepath.Join(dir, "imports.tf")
+		if err = writeTerraformFile(path, resources, terraform.WriteImports); err != nil {
+			formatter.PrintFatal("Couldn't write terraform file", err)
+		}
+		exported = append(exported, ExportedFile{File: path, Resources: len(resources)})
+	}
+	uploadExportedFiles(c, exported, formatter)
+
+	if err = formatter.PrintList(exported); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// templateScriptResource returns the resource of script characterisation ts of template resource tr.
+// Characterisations are imported by template and characterisation ids
+func templateScriptResource(tr terraform.Resource, ts types.TemplateScript) terraform.Resource {
+	r := terraform.Resource{
+		Type: "concerto_template_script",
+		Name: terraform.ResourceName(fmt.Sprintf("%s_%s_%d", tr.Name, ts.Type, ts.ExecutionOrder)),
+		ID:   fmt.Sprintf("%s/%s", tr.ID, ts.ID),
+		Attributes: []terraform.Attribute{
+			{Name: "template_id", Value: terraform.Ref(tr)},
+			{Name: "script_id", Value: ts.ScriptID},
+			{Name: "type", Value: ts.Type},
+			{Name: "execution_order", Value: ts.ExecutionOrder},
+		},
+	}
+	if ts.ParameterValues != nil {
+		r.Attributes = append(r.Attributes, terraform.Attribute{Name: "parameter_values", Value: ts.ParameterValues})
+	}
+	return r
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/blueprint"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/manifest"
+	"github.com/flexiant/concerto/utils/notify"
+)
+
+// BlueprintSync subcommand function
+func BlueprintSync(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	scriptSvc, formatter := WireUpScript(c)
+	templateSvc, _ := WireUpTemplate(c)
+	notify.Track()
+
+	checkRequiredFlags(c, []string{"repo"}, formatter)
+	dir := c.String("dir")
+	if dir == "" {
+		config, err := utils.GetConcertoConfig()
+		if err != nil {
+			formatter.PrintFatal("Couldn't wire up config", err)
+		}
+		dir = filepath.Join(config.ConfLocation, "blueprints", fmt.Sprintf("%x", sha1.Sum([]byte(c.String("repo"))))[:12])
+	}
+	if err := manifest.Checkout(c.String("repo"), c.String("branch"), dir); err != nil {
+		formatter.PrintFatal("Couldn't checkout blueprint repository", err)
+	}
+	if rev, err := manifest.Revision(dir); err == nil {
+		log.Infof("Synchronizing blueprints from %s at %s", c.String("repo"), rev)
+	}
+
+	m, err := manifest.LoadDir(filepath.Join(dir, c.String("path")))
+	if err != nil {
+		formatter.PrintFatal("Couldn't read blueprint repository", err)
+	}
+
+	sync := &blueprintSync{
+		manifest:    m,
+		scriptSvc:   scriptSvc,
+		templateSvc: templateSvc,
+		formatter:   formatter,
+		prune:       c.Bool("prune"),
+		dryRun:      c.Bool("dry-run"),
+	}
+	changes := sync.run()
+
+	if err = formatter.PrintList(changes); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// blueprintSync matches scripts and templates against a manifest
+type blueprintSync struct {
+	manifest    *manifest.Manifest
+	scriptSvc   *blueprint.ScriptService
+	templateSvc *blueprint.TemplateService
+	formatter   format.Formatter
+	prune       bool
+	dryRun      bool
+	templateIDs map[string]string
+	// confirmed holds the changes confirmed, when only those may be applied
+	confirmed map[manifest.Change]bool
+	// applied holds the confirmed changes checked so far, which have been applied
+	applied []manifest.Change
+}
+
+// checkConfirmed stops before applying a change that wasn't confirmed, as it means the live state has changed
+// since the plan was checked. Changes applied till then are kept, and listed so that they can be reviewed
+func (s *blueprintSync) checkConfirmed(ch manifest.Change) {
+	if s.confirmed == nil {
+		return
+	}
+	if s.confirmed[ch] {
+		s.applied = append(s.applied, ch)
+		return
+	}
+	err := fmt.Errorf("%s %s %s wasn't confirmed, as it changed after planning. Please, apply the stack again", ch.Action, ch.Kind, ch.Name)
+	if len(s.applied) > 0 {
+		applied := make([]string, len(s.applied))
+		for i, a := range s.applied {
+			applied[i] = fmt.Sprintf("%s %s %s", a.Action, a.Kind, a.Name)
+		}
+		err = fmt.Errorf("%s. Changes already applied: %s", err, strings.Join(applied, ", "))
+	}
+	s.formatter.PrintFatal("Stack partially applied", err)
+}
+
+// run plans, and unless it's a dry run applies, the changes needed.
+// Scripts go first so that templates can use them, and deletions last so that nothing in use is removed
+func (s *blueprintSync) run() []manifest.Change {
+	changes, deletions := s.apply()
+	return append(changes, s.applyDeletions(deletions)...)
+}
+
+// apply plans, and unless it's a dry run applies, the changes needed but deletions, which are returned
+// to be applied once nothing uses what they remove. IDs of templates are kept by name
+func (s *blueprintSync) apply() ([]manifest.Change, []manifest.Change) {
+	scripts, err := s.scriptSvc.GetScriptList()
+	if err != nil {
+		s.formatter.PrintFatal("Couldn't receive script data", err)
+	}
+	templates, err := s.templateSvc.GetTemplateList()
+	if err != nil {
+		s.formatter.PrintFatal("Couldn't receive template data", err)
+	}
+
+	scriptIDs := make(map[string]string)
+	for _, sc := range scripts {
+		scriptIDs[sc.Name] = sc.ID
+	}
+	s.templateIDs = make(map[string]string)
+	for _, t := range templates {
+		s.templateIDs[t.Name] = t.ID
+	}
+
+	var changes, deletions []manifest.Change
+	for _, ch := range manifest.PlanScripts(s.manifest, scripts, s.prune) {
+		if ch.Action == manifest.Delete {
+			deletions = append(deletions, ch)
+			continue
+		}
+		ch.ID = s.applyScript(ch)
+		scriptIDs[ch.Name] = ch.ID
+		changes = append(changes, ch)
+	}
+
+	var templateDeletions []manifest.Change
+	for _, ch := range manifest.PlanTemplates(s.manifest, templates, s.prune) {
+		if ch.Action == manifest.Delete {
+			templateDeletions = append(templateDeletions, ch)
+			continue
+		}
+		ch.ID = s.applyTemplate(ch)
+		s.templateIDs[ch.Name] = ch.ID
+		changes = append(changes, ch)
+	}
+
+	for i := range s.manifest.Templates {
+		changes = append(changes, s.syncTemplateScripts(&s.manifest.Templates[i], s.templateIDs, scriptIDs)...)
+	}
+	return changes, append(templateDeletions, deletions...)
+}
+
+// applyDeletions applies deletions returned by apply
+func (s *blueprintSync) applyDeletions(deletions []manifest.Change) []manifest.Change {
+	for _, ch := range deletions {
+		s.applyDeletion(ch)
+	}
+	return deletions
+}
+
+func (s *blueprintSync) applyScript(ch manifest.Change) string {
+	if s.dryRun {
+		return ch.ID
+	}
+	s.checkConfirmed(ch)
+	def := s.manifest.Script(ch.Name)
+	var script *types.Script
+	var err error
+	if ch.Action == manifest.Create {
+		script, err = s.scriptSvc.CreateScript(def.Params())
+	} else {
+		script, err = s.scriptSvc.UpdateScript(def.Params(), ch.ID)
+	}
+	if err != nil {
+		s.formatter.PrintFatal(fmt.Sprintf("Couldn't %s script %s", ch.Action, ch.Name), err)
+	}
+	log.Infof("Script %s: %s", ch.Name, ch.Action)
+	return script.ID
+}
+
+func (s *blueprintSync) applyTemplate(ch manifest.Change) string {
+	if s.dryRun {
+		return ch.ID
+	}
+	s.checkConfirmed(ch)
+	def := s.manifest.Template(ch.Name)
+	var template *types.Template
+	var err error
+	if ch.Action == manifest.Create {
+		template, err = s.templateSvc.CreateTemplate(def.Params())
+	} else {
+		template, err = s.templateSvc.UpdateTemplate(def.Params(), ch.ID)
+	}
+	if err != nil {
+		s.formatter.PrintFatal(fmt.Sprintf("Couldn't %s template %s", ch.Action, ch.Name), err)
+	}
+	log.Infof("Template %s: %s", ch.Name, ch.Action)
+	return template.ID
+}
+
+func (s *blueprintSync) syncTemplateScripts(def *manifest.TemplateDefinition, templateIDs map[string]string, scriptIDs map[string]string) []manifest.Change {
+	templateID := templateIDs[def.Name]
+	var current []types.TemplateScript
+	if templateID != "" {
+		for _, t := range types.TemplateScriptTypes {
+			templateScripts, err := s.templateSvc.GetTemplateScriptList(templateID, t)
+			if err != nil {
+				s.formatter.PrintFatal("Couldn't receive templateScript data", err)
+			}
+			current = append(current, *templateScripts...)
+		}
+	}
+
+	plan, err := manifest.PlanTemplateScripts(def, current, scriptIDs)
+	if err != nil {
+		s.formatter.PrintFatal("Couldn't plan template scripts", err)
+	}
+	if s.dryRun {
+		return plan.Changes()
+	}
+	for _, ch := range plan.Changes() {
+		s.checkConfirmed(ch)
+	}
+
+	for i, slot := range plan.Slots {
+		switch slot.Action {
+		case manifest.Create:
+			params := &map[string]interface{}{"type": slot.Type, "script_id": slot.ScriptID, "parameter_values": slot.ParameterValues}
+			ts, err := s.templateSvc.CreateTemplateScript(params, templateID)
+			if err != nil {
+				s.formatter.PrintFatal(fmt.Sprintf("Couldn't add script %s to template %s", slot.Script, def.Name), err)
+			}
+			plan.Slots[i].ID = ts.ID
+		case manifest.Update:
+			params := &map[string]interface{}{"parameter_values": slot.ParameterValues}
+			if _, err := s.templateSvc.UpdateTemplateScript(params, templateID, slot.ID); err != nil {
+				s.formatter.PrintFatal(fmt.Sprintf("Couldn't update script %s of template %s", slot.Script, def.Name), err)
+			}
+		}
+	}
+	for _, ts := range plan.Deletes {
+		if err := s.templateSvc.DeleteTemplateScript(templateID, ts.ID); err != nil {
+			s.formatter.PrintFatal(fmt.Sprintf("Couldn't remove script %s from template %s", ts.ScriptID, def.Name), err)
+		}
+	}
+	for _, t := range plan.Reorders {
+		params := &map[string]interface{}{"type": t, "script_ids": plan.OrderedIDs(t)}
+		if _, err := s.templateSvc.ReorderTemplateScript(params, templateID); err != nil {
+			s.formatter.PrintFatal(fmt.Sprintf("Couldn't reorder %s scripts of template %s", t, def.Name), err)
+		}
+	}
+	return plan.Changes()
+}
+
+func (s *blueprintSync) applyDeletion(ch manifest.Change) {
+	if s.dryRun {
+		return
+	}
+	s.checkConfirmed(ch)
+	var err error
+	if ch.Kind == manifest.KindTemplate {
+		err = s.templateSvc.DeleteTemplate(ch.ID)
+	} else {
+		err = s.scriptSvc.DeleteScript(ch.ID)
+	}
+	if err != nil {
+		s.formatter.PrintFatal(fmt.Sprintf("Couldn't delete %s %s", ch.Kind, ch.Name), err)
+	}
+	log.Infof("%s %s: deleted", ch.Kind, ch.Name)
+}
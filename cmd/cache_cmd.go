@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/httpcache"
+)
+
+// CacheClear subcommand function. Removes every API response cached on disk
+func CacheClear(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+
+	config, err := utils.GetConcertoConfig()
+	if err != nil {
+		formatter.PrintFatal("Couldn't wire up config", err)
+	}
+	removed, err := httpcache.New(config.CacheDir(), 0).Clear()
+	if err != nil {
+		formatter.PrintFatal("Couldn't clear cache", err)
+	}
+	log.Infof("Removed %d cached responses from %s", removed, config.CacheDir())
+	return nil
+}
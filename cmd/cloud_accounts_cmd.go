@@ -1,31 +1,27 @@
 package cmd
 
 import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/settings"
+	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/cloudcreds"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/flags"
 	"github.com/flexiant/concerto/utils/format"
 )
 
 // WireUpCloudAccount prepares common resources to send request to Concerto API
 func WireUpCloudAccount(c *cli.Context) (ds *settings.CloudAccountService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ds, err = settings.NewCloudAccountService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up cloudAccount service", err)
-	}
-
-	return ds, f
+	return wireUpClient(f).CloudAccounts, f
 }
 
 // CloudAccountList subcommand function
@@ -37,7 +33,7 @@ func CloudAccountList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive cloudAccount data", err)
 	}
-	if err = formatter.PrintList(cloudAccounts); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, cloudAccounts)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -48,14 +44,30 @@ func CloudAccountCreate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	cloudAccountSvc, formatter := WireUpCloudAccount(c)
 
-	checkRequiredFlags(c, []string{"cloud_provider_id", "credentials"}, formatter)
+	if c.Bool("from-default-credentials") && c.Bool("interactive") {
+		formatter.PrintFatal("Invalid parameters", fmt.Errorf("Use either --from-default-credentials or --interactive"))
+	}
+	if c.Bool("from-default-credentials") || c.Bool("interactive") {
+		checkRequiredFlags(c, []string{"cloud_provider_id"}, formatter)
+	} else {
+		validateFlags(c, flags.New(c).Required("cloud_provider_id", "credentials").Check("credentials", checkCredentials), formatter)
+	}
 
-	//cloudAccount, err := cloudAccountSvc.CreateCloudAccount(utils.FlagConvertParams(c))
+	//cloudAccount, err := cloudAccountSvc.CreateCloudAccount(flagParams(c, formatter))
 
 	// parse json parameter values
-	params, err := utils.FlagConvertParamsJSON(c, []string{"credentials"})
+	params, err := utils.FlagConvertParamsJSON(c, []string{"credentials"}, "credentials")
 	if err != nil {
-		formatter.PrintFatal("Error parsing parameters", err)
+		formatter.PrintFatal("Error parsing parameters", exit.NewValidationError(err))
+	}
+	if c.Bool("from-default-credentials") {
+		delete(*params, "from-default-credentials")
+		delete(*params, "yes")
+		(*params)["credentials"] = defaultCloudCredentials(c, formatter)
+	}
+	if c.Bool("interactive") {
+		delete(*params, "interactive")
+		(*params)["credentials"] = promptCloudCredentials(c, formatter)
 	}
 
 	cloudAccount, err := cloudAccountSvc.CreateCloudAccount(params)
@@ -69,13 +81,26 @@ func CloudAccountCreate(c *cli.Context) error {
 	return nil
 }
 
+// checkCredentials checks that credentials are a JSON mapping, unless they're read from Vault or the OS keyring
+func checkCredentials(value string) error {
+	if utils.IsSecretReference(value) {
+		return nil
+	}
+	var credentials map[string]interface{}
+	return json.Unmarshal([]byte(value), &credentials)
+}
+
 // CloudAccountUpdate subcommand function
 func CloudAccountUpdate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	cloudAccountSvc, formatter := WireUpCloudAccount(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	cloudAccount, err := cloudAccountSvc.UpdateCloudAccount(utils.FlagConvertParams(c), c.String("id"))
+	params, err := utils.FlagConvertParamsJSON(c, nil, "credentials")
+	if err != nil {
+		formatter.PrintFatal("Error parsing parameters", exit.NewValidationError(err))
+	}
+	cloudAccount, err := cloudAccountSvc.UpdateCloudAccount(params, c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't update cloudAccount", err)
 	}
@@ -91,9 +116,94 @@ func CloudAccountDelete(c *cli.Context) error {
 	cloudAccountSvc, formatter := WireUpCloudAccount(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := cloudAccountSvc.DeleteCloudAccount(c.String("id"))
+	deleteByID(c, "id", "cloudAccount", cloudAccountSvc.DeleteCloudAccount, formatter)
+	return nil
+}
+
+// defaultCloudCredentials reads the local credential chain of the cloud provider, and returns
+// the credentials it requires once the user has confirmed they can be uploaded
+func defaultCloudCredentials(c *cli.Context, f format.Formatter) map[string]string {
+	provider := cloudProviderByID(c, f)
+	kind, err := cloudcreds.ProviderKind(provider.Name)
 	if err != nil {
-		formatter.PrintFatal("Couldn't delete cloudAccount", err)
+		f.PrintFatal("Couldn't read default credentials", err)
+	}
+	creds, err := cloudcreds.Load(kind)
+	if err != nil {
+		f.PrintFatal("Couldn't read default credentials", err)
+	}
+	payload, missing := cloudcreds.Map(kind, creds, provider.RequiredCredentials)
+	if len(missing) > 0 {
+		f.PrintFatal("Couldn't read default credentials", fmt.Errorf("No value found for %s. Please, use --credentials", strings.Join(missing, ", ")))
+	}
+
+	fmt.Fprintf(os.Stderr, "The following %s credentials will be uploaded to Concerto:\n", provider.Name)
+	for _, line := range cloudcreds.Describe(payload) {
+		fmt.Fprintf(os.Stderr, "\t%s\n", line)
+	}
+	if !c.Bool("yes") {
+		fmt.Fprintf(os.Stderr, "Do you want to continue? [y/N]: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			f.PrintFatal("Cloud account not created", fmt.Errorf("Credentials upload wasn't confirmed"))
+		}
+	}
+	return payload
+}
+
+// promptCloudCredentials asks the user for each credential the cloud provider requires, hiding
+// secrets as they're typed
+func promptCloudCredentials(c *cli.Context, f format.Formatter) map[string]string {
+	provider := cloudProviderByID(c, f)
+	if len(provider.RequiredCredentials) == 0 {
+		f.PrintFatal("Couldn't prompt for credentials", fmt.Errorf("Cloud provider %s requires no credentials", provider.Name))
+	}
+
+	fd := int(os.Stdin.Fd())
+	tty := isTerminal(fd)
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Fprintf(os.Stderr, "Enter the %s credentials\n", provider.Name)
+
+	payload := make(map[string]string)
+	for _, name := range provider.RequiredCredentials {
+		for payload[name] == "" {
+			fmt.Fprintf(os.Stderr, "%s: ", name)
+			var value string
+			var err error
+			if tty && cloudcreds.IsSecret(name) {
+				var b []byte
+				b, err = readPassword(fd)
+				fmt.Fprintln(os.Stderr)
+				value = string(b)
+			} else {
+				value, err = reader.ReadString('\n')
+				if err == io.EOF && value != "" {
+					err = nil
+				}
+			}
+			if err != nil {
+				f.PrintFatal("Couldn't read credentials", fmt.Errorf("No value entered for %s", name))
+			}
+			payload[name] = strings.TrimSpace(value)
+		}
+	}
+	return payload
+}
+
+// cloudProviderByID returns the cloud provider given by cloud_provider_id
+func cloudProviderByID(c *cli.Context, f format.Formatter) *types.CloudProvider {
+	cloudProviderSvc, _ := WireUpCloudProvider(c)
+	providers, err := cloudProviderSvc.GetCloudProviderList()
+	if err != nil {
+		f.PrintFatal("Couldn't receive cloud provider data", err)
+	}
+
+	for i := range providers {
+		if providers[i].Id == c.String("cloud_provider_id") {
+			return &providers[i]
+		}
 	}
+	f.PrintFatal("Couldn't find cloud provider", fmt.Errorf("Cloud provider %s not found", c.String("cloud_provider_id")))
 	return nil
 }
@@ -1,31 +1,25 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	log "github.com/Sirupsen/logrus"
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/cluster"
+	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/utils"
 	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/shutdown"
 )
 
 // WireUpCluster prepares common resources to send request to Concerto API
 func WireUpCluster(c *cli.Context) (cs *cluster.ClusterService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	cs, err = cluster.NewClusterService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up cluster service", err)
-	}
-
-	return cs, f
+	return wireUpClient(f).Clusters, f
 }
 
 // ClusterList subcommand function
@@ -37,7 +31,7 @@ func ClusterList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive cluster data", err)
 	}
-	if err = formatter.PrintList(clusters); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, clusters)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -49,7 +43,7 @@ func ClusterCreate(c *cli.Context) error {
 	clusterSvc, formatter := WireUpCluster(c)
 
 	checkRequiredFlags(c, []string{"name"}, formatter)
-	cluster, err := clusterSvc.CreateCluster(utils.FlagConvertParams(c))
+	cluster, err := clusterSvc.CreateCluster(flagParams(c, formatter))
 	if err != nil {
 		formatter.PrintFatal("Couldn't create cluster", err)
 	}
@@ -65,10 +59,7 @@ func ClusterDelete(c *cli.Context) error {
 	clusterSvc, formatter := WireUpCluster(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := clusterSvc.DeleteCluster(c.String("id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't delete cluster", err)
-	}
+	deleteByID(c, "id", "cluster", clusterSvc.DeleteCluster, formatter)
 	return nil
 }
 
@@ -78,7 +69,7 @@ func ClusterStart(c *cli.Context) error {
 	clusterSvc, formatter := WireUpCluster(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := clusterSvc.StartCluster(utils.FlagConvertParams(c), c.String("id"))
+	err := clusterSvc.StartCluster(flagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't start cluster", err)
 	}
@@ -91,7 +82,7 @@ func ClusterStop(c *cli.Context) error {
 	clusterSvc, formatter := WireUpCluster(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := clusterSvc.StopCluster(utils.FlagConvertParams(c), c.String("id"))
+	err := clusterSvc.StopCluster(flagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't stop cluster", err)
 	}
@@ -104,9 +95,131 @@ func ClusterEmpty(c *cli.Context) error {
 	clusterSvc, formatter := WireUpCluster(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := clusterSvc.EmptyCluster(utils.FlagConvertParams(c), c.String("id"))
+	err := clusterSvc.EmptyCluster(flagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't empty cluster", err)
 	}
 	return nil
 }
+
+// ClusterScale subcommand function
+func ClusterScale(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	clusterSvc, formatter := WireUpCluster(c)
+
+	checkRequiredFlags(c, []string{"id", "slave_count"}, formatter)
+	cluster, err := clusterSvc.ScaleCluster(flagParams(c, formatter), c.String("id"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't scale cluster", err)
+	}
+	if err = formatter.PrintItem(*cluster); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// ClusterKubeconfig subcommand function
+func ClusterKubeconfig(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	clusterSvc, formatter := WireUpCluster(c)
+
+	checkRequiredFlags(c, []string{"cluster"}, formatter)
+	cluster := findCluster(clusterSvc, c.String("cluster"), formatter)
+
+	dir := c.String("dir")
+	if dir == "" {
+		dir = kubeconfigDir(cluster, formatter)
+	}
+	file, err := clusterSvc.GetKubeconfig(cluster.Id, dir)
+	if err != nil {
+		formatter.PrintFatal("Couldn't download kubeconfig", err)
+	}
+	fmt.Fprintln(format.Output(), file)
+	return nil
+}
+
+// ClusterKubectl subcommand function. Runs kubectl against the cluster with
+// its credentials, passing through arguments, input, output and exit code
+func ClusterKubectl(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	clusterSvc, formatter := WireUpCluster(c)
+
+	checkRequiredFlags(c, []string{"cluster"}, formatter)
+	cluster := findCluster(clusterSvc, c.String("cluster"), formatter)
+	if cluster.State != "operational" && cluster.State != "partially_operational" {
+		log.Warnf("Cluster %s is not operational. Wait till it gets operational.", cluster.Name)
+	}
+
+	kubectl, err := exec.LookPath("kubectl")
+	if err != nil {
+		formatter.PrintFatal("Couldn't find kubectl in your environment. Please, install it", err)
+	}
+	log.Debugf("Found kubectl at %s", kubectl)
+
+	args := []string(c.Args())
+	kubeconfig, err := clusterSvc.GetKubeconfig(cluster.Id, kubeconfigDir(cluster, formatter))
+	if err == nil {
+		args = append([]string{"--kubeconfig", kubeconfig}, args...)
+	} else {
+		// clusters without kubeconfig are reached with the client certificate
+		log.Debugf("Couldn't download kubeconfig, using client certificate instead: %s", err)
+		config, err := utils.GetConcertoConfig()
+		if err != nil {
+			formatter.PrintFatal("Couldn't wire up config", err)
+		}
+		if len(cluster.Masters) == 0 {
+			formatter.PrintFatal("Couldn't connect to cluster", fmt.Errorf("Cluster %s has no masters", cluster.Name))
+		}
+		keyFile, err := config.KeyFile()
+		if err != nil {
+			formatter.PrintFatal("Couldn't read client certificate key", err)
+		}
+		args = append([]string{
+			fmt.Sprintf("--server=https://%s:6443", cluster.Masters[0]),
+			fmt.Sprintf("--client-certificate=%s", config.Certificate.Cert),
+			fmt.Sprintf("--client-key=%s", keyFile),
+			fmt.Sprintf("--certificate-authority=%s", config.Certificate.Ca),
+		}, args...)
+	}
+
+	log.Debugf("Going to execute %s %s", kubectl, args)
+	cmd := exec.Command(kubectl, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			shutdown.Exit(exitErr.Sys().(syscall.WaitStatus).ExitStatus())
+		}
+		formatter.PrintFatal("Couldn't run kubectl", err)
+	}
+	return nil
+}
+
+// findCluster returns the cluster with the given name or ID
+func findCluster(clusterSvc *cluster.ClusterService, nameOrID string, f format.Formatter) *types.Cluster {
+	clusters, err := clusterSvc.GetClusterList()
+	if err != nil {
+		f.PrintFatal("Couldn't receive cluster data", err)
+	}
+	for _, cl := range clusters {
+		if cl.Name == nameOrID || cl.Id == nameOrID {
+			return &cl
+		}
+	}
+	f.PrintFatal("Couldn't find cluster", fmt.Errorf("Cluster %s is not in your account", nameOrID))
+	return nil
+}
+
+// kubeconfigDir returns the directory where kubeconfig of a cluster is stored
+func kubeconfigDir(cluster *types.Cluster, f format.Formatter) string {
+	config, err := utils.GetConcertoConfig()
+	if err != nil {
+		f.PrintFatal("Couldn't wire up config", err)
+	}
+	dir := filepath.Join(config.ConfLocation, "kube", cluster.Id)
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		f.PrintFatal("Couldn't create kubeconfig directory", err)
+	}
+	return dir
+}
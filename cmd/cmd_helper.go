@@ -1,14 +1,32 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/client"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/cloudcreds"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/flags"
 	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/pool"
+	"github.com/flexiant/concerto/utils/query"
+	"github.com/flexiant/concerto/utils/s3"
+	"github.com/flexiant/concerto/utils/shutdown"
+	"github.com/flexiant/concerto/utils/watch"
+	"github.com/mitchellh/go-homedir"
 )
 
 // debugCmdFuncInfo writes context info about the calling function
@@ -38,33 +56,322 @@ func debugCmdFuncInfo(c *cli.Context) {
 	log.Debugf(dbgMsg)
 }
 
+// wireUpClient returns the API client commands send their requests with
+func wireUpClient(f format.Formatter) *client.Client {
+	config, err := utils.GetConcertoConfig()
+	if err != nil {
+		f.PrintFatal("Couldn't wire up config", err)
+	}
+	c, err := client.New(config)
+	if err != nil {
+		f.PrintFatal("Couldn't wire up concerto service", err)
+	}
+	return c
+}
+
 // checkRequiredFlags checks for required flags, and show usage if requirements not met
-func checkRequiredFlags(c *cli.Context, flags []string, f format.Formatter) {
-	missing := ""
-	for _, flag := range flags {
-		if !c.IsSet(flag) {
-			missing = fmt.Sprintf("%s\n\t--%s", missing, flag)
+func checkRequiredFlags(c *cli.Context, required []string, f format.Formatter) {
+	validateFlags(c, flags.New(c).Required(required...), f)
+}
+
+// checkRequiredFlagsOr checks that at least one of required flags is present, and show usage if requirements not met
+func checkRequiredFlagsOr(c *cli.Context, required []string, f format.Formatter) {
+	validateFlags(c, flags.New(c).AnyOf(required...), f)
+}
+
+// validateFlags reports every problem found by v at once, with the usage of the flags involved, and exits
+func validateFlags(c *cli.Context, v *flags.Validator, f format.Formatter) {
+	err := v.Err()
+	if err == nil {
+		return
+	}
+	err = exit.NewValidationError(err)
+	if !format.ReportError("Incorrect usage.", err) {
+		f.PrintError("Incorrect usage.", err)
+		printFlagsUsage(c, v.Flags())
+	}
+	shutdown.Exit(exit.Validation)
+}
+
+// printFlagsUsage prints the usage of the given flags of the command, pointing to its help for the rest
+func printFlagsUsage(c *cli.Context, names []string) {
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[name] = true
+	}
+	fmt.Fprintf(c.App.Writer, "\nUSAGE:\n")
+	for _, flag := range c.Command.Flags {
+		if wanted[strings.TrimSpace(strings.Split(flag.GetName(), ",")[0])] {
+			fmt.Fprintf(c.App.Writer, "   %s\n", flag)
 		}
 	}
+	name := c.Command.HelpName
+	if name == "" {
+		name = fmt.Sprintf("%s %s", c.App.Name, c.Command.Name)
+	}
+	fmt.Fprintf(c.App.Writer, "\nSee '%s --help' for every parameter.\n", name)
+}
 
-	if missing != "" {
-		f.PrintError("Incorrect usage.", fmt.Errorf("Mandatory parameters missing: %s\n", missing))
-		cli.ShowCommandHelp(c, c.Command.Name)
-		os.Exit(2)
+// flagParams returns the parameters given as flags, as expected by the API
+func flagParams(c *cli.Context, f format.Formatter) *map[string]interface{} {
+	params, err := utils.FlagConvertParamsJSON(c, nil)
+	if err != nil {
+		f.PrintFatal("Error parsing parameters", exit.NewValidationError(err))
 	}
+	return params
 }
 
-// checkRequiredFlagsOr checks that at least one of required flags is present, and show usage if requirements not met
-func checkRequiredFlagsOr(c *cli.Context, flags []string, f format.Formatter) {
-	missing := ""
-	for _, flag := range flags {
-		if c.IsSet(flag) {
-			return
+// deleteByID deletes the resources given by a repeatable flag, in parallel and reporting the outcome
+// of each one when there are several. Resources not found when trying again were deleted by the failed attempt
+func deleteByID(c *cli.Context, flag string, what string, fn func(id string) error, f format.Formatter) {
+	ids := c.StringSlice(flag)
+	if len(ids) > 1 {
+		var mu sync.Mutex
+		attempted := make(map[string]bool)
+		bulkExecute(ids, func(id string) error {
+			mu.Lock()
+			retry := attempted[id]
+			attempted[id] = true
+			mu.Unlock()
+
+			err := fn(id)
+			if e, ok := err.(*utils.HTTPError); ok && e.Status == 404 && retry {
+				log.Infof("%s %s was deleted by a failed attempt", what, id)
+				return nil
+			}
+			return err
+		}, f)
+		return
+	}
+	if err := fn(ids[0]); err != nil {
+		f.PrintFatal(fmt.Sprintf("Couldn't delete %s", what), err)
+	}
+}
+
+// bulkExecute calls fn for every item using the shared worker pool, and prints the outcome of each one
+func bulkExecute(items []string, fn func(item string) error, f format.Formatter) {
+	results := pool.Run(items, fn)
+	if err := f.PrintList(results); err != nil {
+		f.PrintFatal("Couldn't print/format result", err)
+	}
+	if pending := pool.Pending(results); pending > 0 {
+		f.PrintFatal("Bulk operation was cancelled", fmt.Errorf("%d of %d items are pending", pending, len(items)))
+	}
+	if failed := pool.Failed(results); failed > 0 {
+		f.PrintFatal("Bulk operation didn't complete", fmt.Errorf("%d of %d items failed", failed, len(items)))
+	}
+}
+
+// newUploader returns the client and location where artifacts are uploaded, or nil when --upload isn't given
+func newUploader(c *cli.Context, f format.Formatter) (*s3.Client, *s3.Location) {
+	if c.String("upload") == "" {
+		return nil, nil
+	}
+	location, err := s3.ParseLocation(c.String("upload"))
+	if err != nil {
+		f.PrintFatal("Incorrect usage", err)
+	}
+	config, err := utils.GetConcertoConfig()
+	if err != nil {
+		f.PrintFatal("Couldn't wire up config", err)
+	}
+
+	storage := s3.Config{
+		Endpoint:  firstNonEmpty(os.Getenv("AWS_ENDPOINT_URL_S3"), os.Getenv("AWS_ENDPOINT_URL"), config.Storage.Endpoint),
+		Region:    firstNonEmpty(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), config.Storage.Region),
+		AccessKey: config.Storage.AccessKey,
+		SecretKey: config.Storage.SecretKey,
+	}
+	if os.Getenv("AWS_ACCESS_KEY_ID") != "" || storage.AccessKey == "" {
+		creds, err := cloudcreds.Load(cloudcreds.AWS)
+		if err != nil {
+			f.PrintFatal("Couldn't read S3 credentials", err)
 		}
-		missing = fmt.Sprintf("%s\n\t--%s", missing, flag)
+		storage.AccessKey = creds["access_key_id"]
+		storage.SecretKey = creds["secret_access_key"]
+		storage.SessionToken = creds["session_token"]
+	}
+
+	client, err := s3.NewClient(storage)
+	if err != nil {
+		f.PrintFatal("Couldn't wire up S3 client", err)
 	}
+	return client, location
+}
+
+// uploadFiles uploads local files under the --upload location, when given, named after their base name
+func uploadFiles(c *cli.Context, files []string, f format.Formatter) {
+	client, location := newUploader(c, f)
+	if client == nil {
+		return
+	}
+	for _, file := range files {
+		key := location.Key(filepath.Base(file))
+		if err := client.UploadFile(location.Bucket, key, file); err != nil {
+			f.PrintFatal("Couldn't upload file", err)
+		}
+		log.Infof("Uploaded %s to s3://%s/%s", file, location.Bucket, key)
+	}
+}
 
-	f.PrintError("Incorrect usage.", fmt.Errorf("Please use one of these parameters: %s\n", missing))
-	cli.ShowCommandHelp(c, c.Command.Name)
-	os.Exit(2)
+// uploadItem uploads item encoded as JSON under the --upload location, when given
+func uploadItem(c *cli.Context, name string, item interface{}, f format.Formatter) {
+	client, location := newUploader(c, f)
+	if client == nil {
+		return
+	}
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		f.PrintFatal("Couldn't encode item", err)
+	}
+	key := location.Key(name)
+	if err = client.Upload(location.Bucket, key, bytes.NewReader(data), int64(len(data))); err != nil {
+		f.PrintFatal("Couldn't upload item", err)
+	}
+	log.Infof("Uploaded %s to s3://%s/%s", name, location.Bucket, key)
+}
+
+// readFlagValue returns a flag value given inline, read from a file prefixed with @, whose path may start
+// with ~, or read from standard input with @-
+func readFlagValue(value string) ([]byte, error) {
+	switch {
+	case value == "@-":
+		return ioutil.ReadAll(os.Stdin)
+	case strings.HasPrefix(value, "@"):
+		file, err := homedir.Expand(value[1:])
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadFile(file)
+	}
+	return []byte(value), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ListFlags returns the flags list commands take to filter and sort what they print, as read by filterList
+func ListFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "filter",
+			Usage: "Only list items whose field matches a value, such as state=active, name=web-* or state!=inactive. Repeat it to match several fields",
+		},
+		cli.StringFlag{
+			Name:  "sort",
+			Usage: "Sort items by comma separated fields, each followed by :desc to sort them in descending order, such as state,name:desc",
+		},
+	}
+}
+
+// listFilters returns the filters given with --filter
+func listFilters(c *cli.Context, f format.Formatter) []query.Filter {
+	var filters []query.Filter
+	for _, s := range c.StringSlice("filter") {
+		filter, err := query.ParseFilter(s)
+		if err != nil {
+			f.PrintFatal("Incorrect usage.", exit.NewValidationError(err))
+		}
+		filters = append(filters, filter)
+	}
+	return filters
+}
+
+// apiFilter returns the value --filter gives to field, when it's matched exactly, so that it can be passed
+// on to endpoints filtering by it. Items received are still filtered by filterList
+func apiFilter(c *cli.Context, f format.Formatter, field string) string {
+	value, _ := query.Exact(listFilters(c, f), field)
+	return value
+}
+
+// filterList returns the items matching every --filter, sorted by --sort
+func filterList(c *cli.Context, f format.Formatter, items interface{}) interface{} {
+	filters := listFilters(c, f)
+	orders, err := query.ParseOrder(c.String("sort"))
+	if err != nil {
+		f.PrintFatal("Incorrect usage.", exit.NewValidationError(err))
+	}
+	items, err = query.Apply(items, filters, orders)
+	if err != nil {
+		f.PrintFatal("Incorrect usage.", exit.NewValidationError(err))
+	}
+	return items
+}
+
+// selectColumns returns items with only the fields given with --columns, if any
+func selectColumns(c *cli.Context, f format.Formatter, items interface{}) interface{} {
+	items, err := query.Select(items, query.ParseColumns(c.String("columns")))
+	if err != nil {
+		f.PrintFatal("Incorrect usage.", exit.NewValidationError(err))
+	}
+	return items
+}
+
+// printWatchedList prints the list returned by list. With --watch, it refreshes the list every --interval
+// till the command is cancelled, highlighting the items whose state changed
+func printWatchedList(c *cli.Context, f format.Formatter, context string, list func() (interface{}, error)) {
+	if !c.Bool("watch") {
+		items, err := list()
+		if err != nil {
+			f.PrintFatal(context, err)
+		}
+		if err = f.PrintList(selectColumns(c, f, filterList(c, f, items))); err != nil {
+			f.PrintFatal("Couldn't print/format result", err)
+		}
+		return
+	}
+
+	interval := c.Duration("interval")
+	if interval <= 0 {
+		f.PrintFatal("Incorrect usage.", exit.NewValidationError(fmt.Errorf("Invalid interval %s", interval)))
+	}
+	_, text := f.(*format.TextFormatter)
+	terminal := text && isTerminal(int(os.Stdout.Fd()))
+	out := format.Output()
+	tracker := watch.NewTracker()
+	for {
+		items, err := list()
+		if err != nil {
+			if cancel.Err() != nil {
+				return
+			}
+			f.PrintFatal(context, err)
+		}
+		items = filterList(c, f, items)
+		transitions := tracker.Update(items)
+		items = selectColumns(c, f, items)
+
+		if text {
+			var table bytes.Buffer
+			if err = format.NewTextFormatter(&table).PrintList(items); err != nil {
+				f.PrintFatal("Couldn't print/format result", err)
+			}
+			rendered := table.Bytes()
+			if terminal {
+				// clear the screen, so that the list is refreshed in place
+				fmt.Fprint(out, "\x1b[H\x1b[2J")
+				rendered = watch.Highlight(rendered, transitions)
+			}
+			fmt.Fprintf(out, "Every %s: %s\n\n", interval, time.Now().Format(time.RFC1123))
+			out.Write(rendered)
+			if len(transitions) > 0 {
+				fmt.Fprintln(out)
+			}
+			for _, t := range transitions {
+				fmt.Fprintf(out, "%s %s: %s -> %s\n", t.ID, t.Name, firstNonEmpty(t.From, "new"), t.To)
+			}
+		} else if err = f.PrintList(items); err != nil {
+			f.PrintFatal("Couldn't print/format result", err)
+		}
+
+		if !cancel.Sleep(interval) {
+			return
+		}
+	}
 }
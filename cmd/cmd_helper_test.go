@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFlagValue(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := readFlagValue(`{"name":"web"}`)
+	assert.Nil(err, "Inline values should be valid")
+	assert.Equal(`{"name":"web"}`, string(data), "Inline values should be returned as given")
+
+	dir, err := ioutil.TempDir("", "flag_value")
+	assert.Nil(err, "Couldn't create temp dir")
+	defer os.RemoveAll(dir)
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "value"), []byte("from file"), 0600), "Couldn't write value")
+
+	data, err = readFlagValue("@" + filepath.Join(dir, "value"))
+	assert.Nil(err, "Values in a file should be valid")
+	assert.Equal("from file", string(data), "Value should be read from the file")
+
+	_, err = readFlagValue("@" + filepath.Join(dir, "missing"))
+	assert.NotNil(err, "A missing file should be rejected")
+
+	// ~ is expanded to the home directory
+	home := os.Getenv("HOME")
+	defer os.Setenv("HOME", home)
+	defer func(cache bool) { homedir.DisableCache = cache }(homedir.DisableCache)
+	homedir.DisableCache = true
+	os.Setenv("HOME", dir)
+	data, err = readFlagValue("@~/value")
+	assert.Nil(err, "Paths starting with ~ should be valid")
+	assert.Equal("from file", string(data), "Value should be read from the home directory")
+}
+
+func TestReadFlagValueStdin(t *testing.T) {
+	assert := assert.New(t)
+
+	r, w, err := os.Pipe()
+	assert.Nil(err, "Couldn't create pipe")
+	stdin := os.Stdin
+	defer func() { os.Stdin = stdin }()
+	os.Stdin = r
+
+	_, err = w.WriteString("from stdin")
+	assert.Nil(err, "Couldn't write to pipe")
+	w.Close()
+
+	data, err := readFlagValue("@-")
+	assert.Nil(err, "Values in standard input should be valid")
+	assert.Equal("from stdin", string(data), "Value should be read from standard input")
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/client"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/completion"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/format"
+)
+
+// completionListers list the resources whose IDs are completed, by the name of their command
+var completionListers = map[string]func(cl *client.Client) (interface{}, error){
+	"servers":           func(cl *client.Client) (interface{}, error) { return cl.Servers.GetServerList() },
+	"workspaces":        func(cl *client.Client) (interface{}, error) { return cl.Workspaces.GetWorkspaceList() },
+	"templates":         func(cl *client.Client) (interface{}, error) { return cl.Templates.GetTemplateList() },
+	"scripts":           func(cl *client.Client) (interface{}, error) { return cl.Scripts.GetScriptList() },
+	"services":          func(cl *client.Client) (interface{}, error) { return cl.Services.GetServiceList() },
+	"generic_images":    func(cl *client.Client) (interface{}, error) { return cl.GenericImages.GetGenericImageList() },
+	"cloud_providers":   func(cl *client.Client) (interface{}, error) { return cl.CloudProviders.GetCloudProviderList() },
+	"ssh_profiles":      func(cl *client.Client) (interface{}, error) { return cl.SSHProfiles.GetSSHProfileList() },
+	"firewall_profiles": func(cl *client.Client) (interface{}, error) { return cl.FirewallProfiles.GetFirewallProfileList() },
+	"load_balancers":    func(cl *client.Client) (interface{}, error) { return cl.LoadBalancers.GetLoadBalancerList() },
+	"clusters":          func(cl *client.Client) (interface{}, error) { return cl.Clusters.GetClusterList() },
+	"nodes":             func(cl *client.Client) (interface{}, error) { return cl.Nodes.GetNodeList() },
+	"domains":           func(cl *client.Client) (interface{}, error) { return cl.Domains.GetDomainList() },
+	"cloud_accounts":    func(cl *client.Client) (interface{}, error) { return cl.CloudAccounts.GetCloudAccountList() },
+	"saas_accounts":     func(cl *client.Client) (interface{}, error) { return cl.SaasAccounts.GetSaasAccountList() },
+	"apps":              func(cl *client.Client) (interface{}, error) { return cl.Apps.GetAppList() },
+	"locations":         func(cl *client.Client) (interface{}, error) { return cl.Locations.GetLocationList() },
+	"saas_providers":    func(cl *client.Client) (interface{}, error) { return cl.SaasProviders.GetSaasProviderList() },
+}
+
+func init() {
+	// commands and flags naming resources otherwise
+	completionListers["cluster"] = completionListers["clusters"]
+	completionListers["dns_domains"] = completionListers["domains"]
+	completionListers["balancers"] = completionListers["load_balancers"]
+}
+
+// Completion subcommand function. Prints the completion script of a shell
+func Completion(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		formatter.PrintFatal("Incorrect usage.", exit.NewValidationError(fmt.Errorf("Please name one of these shells: %s", strings.Join(completion.Shells, ", "))))
+	}
+	script, err := completion.Script(c.Args().First(), c.App.Name)
+	if err != nil {
+		formatter.PrintFatal("Couldn't generate completion script", exit.NewValidationError(err))
+	}
+	fmt.Fprint(c.App.Writer, script)
+	return nil
+}
+
+// Complete subcommand function. Prints the candidates completing the last of its arguments, one per line
+func Complete(c *cli.Context) error {
+	candidates := completion.Candidates(c.App.Commands, c.App.Flags, c.Args(), resolveCompletion)
+	for _, candidate := range candidates {
+		fmt.Fprintln(c.App.Writer, candidate)
+	}
+	return nil
+}
+
+// resolveCompletion returns the IDs of the resources an id flag refers to: the ones of the command
+// it's given to for --id, or the ones named by the flag for --<resource>_id. Failures complete nothing
+func resolveCompletion(flag string, path []string) []string {
+	var resource string
+	if flag == "id" {
+		for i := len(path) - 1; i >= 0 && resource == ""; i-- {
+			if _, ok := completionListers[path[i]]; ok {
+				resource = path[i]
+			}
+		}
+	} else if strings.HasSuffix(flag, "_id") {
+		resource = strings.TrimSuffix(flag, "_id") + "s"
+	}
+	list, ok := completionListers[resource]
+	if !ok {
+		return nil
+	}
+
+	config, err := utils.GetConcertoConfig()
+	if err != nil {
+		return nil
+	}
+	cl, err := client.New(config)
+	if err != nil {
+		return nil
+	}
+	items, err := list(cl)
+	if err != nil {
+		return nil
+	}
+	return completion.Describe(items)
+}
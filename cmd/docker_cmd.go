@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/flags"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/notify"
+	"github.com/flexiant/concerto/utils/wait"
+	"github.com/mitchellh/go-homedir"
+)
+
+// dockerPort is where Docker engines of Concerto servers listen for TLS connections
+const dockerPort = 2376
+
+// dockerShells are the shells the environment can be printed for
+var dockerShells = []string{"bash", "fish", "powershell", "cmd"}
+
+// dockerCertFiles are the files docker looks for inside DOCKER_CERT_PATH
+var dockerCertFiles = []string{"ca.pem", "cert.pem", "key.pem"}
+
+// DockerCreateHost subcommand function
+func DockerCreateHost(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	serverSvc, formatter := WireUpServer(c)
+	notify.Track()
+
+	validateFlags(c, flags.New(c).Required("name", "fqdn", "workspace_id", "template_id", "server_plan_id", "tls_dir").Enum("shell", dockerShells...), formatter)
+	timeout, err := time.ParseDuration(c.String("timeout"))
+	if err != nil {
+		formatter.PrintFatal("Incorrect usage", exit.NewValidationError(fmt.Errorf("Invalid timeout %s", c.String("timeout"))))
+	}
+	// certificates are checked before creating anything
+	tlsConfig, err := dockerTLSConfig(c.String("tls_dir"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't read Docker client certificates", exit.NewValidationError(err))
+	}
+	deadline := time.Now().Add(timeout)
+
+	serverIn := map[string]interface{}{
+		"name":           c.String("name"),
+		"fqdn":           c.String("fqdn"),
+		"workspace_id":   c.String("workspace_id"),
+		"template_id":    c.String("template_id"),
+		"server_plan_id": c.String("server_plan_id"),
+	}
+	server, err := serverSvc.CreateServer(&serverIn)
+	if err != nil {
+		formatter.PrintFatal("Couldn't create server", err)
+	}
+	log.Infof("Server %s created with Id %s", server.Name, server.Id)
+	certPath, err := storeDockerCerts(c.String("tls_dir"), server.Id)
+	if err != nil {
+		formatter.PrintFatal("Couldn't store Docker client certificates", err)
+	}
+
+	if _, err = serverSvc.BootServer(&map[string]interface{}{}, server.Id); err != nil {
+		formatter.PrintFatal("Couldn't boot server", err)
+	}
+	log.Infof("Booting server %s. Waiting till it gets operational", server.Name)
+
+	server, err = waitServer(serverSvc, server.Id, serverBooted, time.Until(deadline))
+	if err != nil {
+		formatter.PrintFatal("Couldn't boot server", err)
+	}
+
+	// the engine is installed by the template services, or by the given script
+	if c.IsSet("script_id") {
+		log.Infof("Executing script %s to set up Docker engine", c.String("script_id"))
+		if _, err = serverSvc.ExecuteOperationalScript(&map[string]interface{}{}, server.Id, c.String("script_id")); err != nil {
+			formatter.PrintFatal("Couldn't set up Docker engine", err)
+		}
+	}
+	log.Infof("Waiting till the Docker engine of server %s accepts the client certificates", server.Name)
+	if err = waitDockerEngine(dockerHost(server), tlsConfig, time.Until(deadline)); err != nil {
+		formatter.PrintFatal("Couldn't reach Docker engine", err)
+	}
+
+	printDockerShellEnv(c.String("shell"), server, certPath)
+	return nil
+}
+
+// DockerEnv subcommand function
+func DockerEnv(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	serverSvc, formatter := WireUpServer(c)
+
+	validateFlags(c, flags.New(c).Required("id").Enum("shell", dockerShells...), formatter)
+	server, err := serverSvc.GetServer(c.String("id"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive server data", err)
+	}
+
+	certPath := c.String("tls_dir")
+	if certPath != "" {
+		if _, err = dockerTLSConfig(certPath); err != nil {
+			formatter.PrintFatal("Couldn't read Docker client certificates", exit.NewValidationError(err))
+		}
+	} else if certPath, err = dockerCertPath(server.Id); err != nil {
+		formatter.PrintFatal("Couldn't find Docker client certificates", err)
+	} else if _, err = dockerTLSConfig(certPath); err != nil {
+		formatter.PrintFatal("Couldn't find Docker client certificates", fmt.Errorf("%s. Please, give the directory of the ones the engine trusts with --tls_dir", err))
+	}
+	printDockerShellEnv(c.String("shell"), server, certPath)
+	return nil
+}
+
+// dockerTLSConfig returns the TLS configuration of the client certificates in dir, as docker expects them
+func dockerTLSConfig(dir string) (*tls.Config, error) {
+	dir, err := homedir.Expand(dir)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+	if err != nil {
+		return nil, err
+	}
+	ca, err := ioutil.ReadFile(filepath.Join(dir, "ca.pem"))
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("No CA certificate found in %s", filepath.Join(dir, "ca.pem"))
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: pool}, nil
+}
+
+// dockerCertPath returns the directory where the Docker client certificates of a server are kept
+func dockerCertPath(serverID string) (string, error) {
+	config, err := utils.GetConcertoConfig()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(config.ConfLocation, "docker", serverID), nil
+}
+
+// storeDockerCerts copies the Docker client certificates in dir to the directory of the server, so that
+// docker env finds them later. It returns that directory
+func storeDockerCerts(dir string, serverID string) (string, error) {
+	dir, err := homedir.Expand(dir)
+	if err != nil {
+		return "", err
+	}
+	certPath, err := dockerCertPath(serverID)
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(certPath, 0700); err != nil {
+		return "", err
+	}
+	for _, name := range dockerCertFiles {
+		if err = copyFile(filepath.Join(dir, name), filepath.Join(certPath, name)); err != nil {
+			return "", err
+		}
+	}
+	return certPath, nil
+}
+
+// dockerHost returns the address of the server engine
+func dockerHost(server *types.Server) string {
+	host := server.Fqdn
+	if host == "" {
+		host = server.Public_ip
+	}
+	return net.JoinHostPort(host, strconv.Itoa(dockerPort))
+}
+
+// waitDockerEngine polls the engine at host till it answers pings over TLS with the client certificates,
+// which tells its set up is done
+func waitDockerEngine(host string, tlsConfig *tls.Config, timeout time.Duration) error {
+	client := &http.Client{Timeout: 10 * time.Second, Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	poller := &wait.Poller{
+		Interval: serverPollInterval,
+		Timeout:  timeout,
+		Progress: func(state string) { log.Infof("Docker engine at %s is %s", host, state) },
+	}
+	_, err := poller.Until(fmt.Sprintf("Docker engine at %s", host), func() (string, error) {
+		response, err := client.Get(fmt.Sprintf("https://%s/_ping", host))
+		if err != nil {
+			log.Debugf("Docker engine at %s can't be reached: %s", host, err)
+			return "unreachable", nil
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			return fmt.Sprintf("answering %s", response.Status), nil
+		}
+		return "ready", nil
+	}, wait.Target{Done: []string{"ready"}})
+	return err
+}
+
+// printDockerShellEnv prints the variables reaching the server engine with the syntax of shell, along
+// with the formatted output of commands
+func printDockerShellEnv(shell string, server *types.Server, certPath string) {
+	env := [][2]string{
+		{"DOCKER_TLS_VERIFY", "1"},
+		{"DOCKER_HOST", "tcp://" + dockerHost(server)},
+		{"DOCKER_CERT_PATH", certPath},
+	}
+
+	w := format.Output()
+	for _, e := range env {
+		switch shell {
+		case "fish":
+			fmt.Fprintf(w, "set -gx %s %s;\n", e[0], fishQuote(e[1]))
+		case "powershell":
+			fmt.Fprintf(w, "$Env:%s = %s\n", e[0], powershellQuote(e[1]))
+		case "cmd":
+			fmt.Fprintf(w, "SET \"%s=%s\"\n", e[0], e[1])
+		default:
+			fmt.Fprintf(w, "export %s=%s\n", e[0], shellQuote(e[1]))
+		}
+	}
+	switch shell {
+	case "fish":
+		fmt.Fprintf(w, "# Run this command to configure your shell:\n# eval (concerto docker env --id %s --shell fish)\n", server.Id)
+	case "bash":
+		fmt.Fprintf(w, "# Run this command to configure your shell:\n# eval $(concerto docker env --id %s)\n", server.Id)
+	}
+}
+
+// shellQuote quotes value for POSIX shells, within single quotes
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+// fishQuote quotes value for fish, within single quotes, where only backslashes and quotes are escaped
+func fishQuote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+}
+
+// powershellQuote quotes value for PowerShell, within single quotes, where quotes are doubled
+func powershellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
+
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
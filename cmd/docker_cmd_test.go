@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintDockerShellEnv(t *testing.T) {
+	defer format.InitializeFormatter("text", os.Stdout)
+
+	server := &types.Server{Id: "5641e7497aa4b1a67800006c", Fqdn: "docker1.example.com", Public_ip: "203.0.113.10"}
+	for _, shell := range []string{"bash", "fish", "powershell", "cmd"} {
+		var out bytes.Buffer
+		format.InitializeFormatter("json", &out)
+		printDockerShellEnv(shell, server, "/home/user/.concerto/docker/5641e7497aa4b1a67800006c")
+		assertGolden(t, "docker_env."+shell, out.Bytes())
+	}
+
+	var out bytes.Buffer
+	format.InitializeFormatter("text", &out)
+	printDockerShellEnv("bash", &types.Server{Id: "5641e7497aa4b1a67800006c", Public_ip: "203.0.113.10"}, "/tmp/docker")
+	assertGolden(t, "docker_env_ip.bash", out.Bytes())
+}
+
+func TestDockerShellQuoting(t *testing.T) {
+	assert := assert.New(t)
+
+	value := `C:\it's $HOME`
+	assert.Equal(`'C:\it'\''s $HOME'`, shellQuote(value), "Unexpected POSIX shell quoting")
+	assert.Equal(`'C:\\it\'s $HOME'`, fishQuote(value), "Unexpected fish quoting")
+	assert.Equal(`'C:\it''s $HOME'`, powershellQuote(value), "Unexpected PowerShell quoting")
+}
+
+// writeDockerCerts writes to dir a self signed client certificate acting as its own CA, as docker expects them
+func writeDockerCerts(t *testing.T, dir string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	files := map[string][]byte{
+		"ca.pem":   certPEM,
+		"cert.pem": certPEM,
+		"key.pem":  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+	}
+	for name, data := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestDockerTLSConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "concerto-docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, err = dockerTLSConfig(dir)
+	assert.NotNil(err, "Missing certificates should fail")
+
+	writeDockerCerts(t, dir)
+	config, err := dockerTLSConfig(dir)
+	assert.Nil(err, "Couldn't read certificates")
+	assert.Len(config.Certificates, 1, "Client certificate should be loaded")
+	assert.NotNil(config.RootCAs, "CA should be loaded")
+
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "ca.pem"), []byte("garbage"), 0600))
+	_, err = dockerTLSConfig(dir)
+	assert.NotNil(err, "Invalid CA should fail")
+}
+
+func TestWaitDockerEngine(t *testing.T) {
+	assert := assert.New(t)
+	defer func(interval time.Duration) { serverPollInterval = interval }(serverPollInterval)
+	serverPollInterval = 10 * time.Millisecond
+
+	dir, err := ioutil.TempDir("", "concerto-docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	clientCert := writeDockerCerts(t, dir)
+	config, err := dockerTLSConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pings := 0
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the engine is answering, but still being set up
+		if pings++; pings < 3 {
+			http.Error(w, "starting", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	ts.StartTLS()
+	defer ts.Close()
+	config.RootCAs = x509.NewCertPool()
+	config.RootCAs.AddCert(ts.Certificate())
+	host := strings.TrimPrefix(ts.URL, "https://")
+
+	assert.Nil(waitDockerEngine(host, config, time.Minute), "Engine should get ready")
+	assert.Equal(3, pings, "Engine should be polled till ready")
+
+	// certificates the engine doesn't trust never get it ready
+	config.Certificates = nil
+	assert.NotNil(waitDockerEngine(host, config, 50*time.Millisecond), "Untrusted certificates should time out")
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/doctor"
+	"github.com/flexiant/concerto/utils/format"
+)
+
+// DoctorRun command function. Runs every diagnostic check and prints how to fix failed ones
+func DoctorRun(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+
+	config, err := utils.GetConcertoConfig()
+	if err != nil {
+		formatter.PrintFatal("Couldn't wire up config", err)
+	}
+	results := doctor.New(config).Run()
+	if err = formatter.PrintList(results); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	if failed := doctor.Failed(results); failed > 0 {
+		formatter.PrintFatal("Diagnostics found problems", fmt.Errorf("%d checks failed", failed))
+	}
+	return nil
+}
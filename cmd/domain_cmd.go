@@ -1,31 +1,27 @@
 package cmd
 
 import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/dns"
-	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/flags"
 	"github.com/flexiant/concerto/utils/format"
 )
 
+// recordTypes are the types of DNS records the platform serves
+var recordTypes = []string{"A", "AAAA", "CNAME", "MX", "TXT"}
+
+// maxTTL is the largest TTL allowed by RFC 2181
+const maxTTL = 1<<31 - 1
+
 // WireUpDomain prepares common resources to send request to Concerto API
 func WireUpDomain(c *cli.Context) (ds *dns.DomainService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ds, err = dns.NewDomainService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up domain service", err)
-	}
-
-	return ds, f
+	return wireUpClient(f).Domains, f
 }
 
 // DomainList subcommand function
@@ -37,7 +33,7 @@ func DomainList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive domain data", err)
 	}
-	if err = formatter.PrintList(domains); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, domains)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -64,8 +60,8 @@ func DomainCreate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	domainSvc, formatter := WireUpDomain(c)
 
-	checkRequiredFlags(c, []string{"name", "contact"}, formatter)
-	domain, err := domainSvc.CreateDomain(utils.FlagConvertParams(c))
+	validateFlags(c, flags.New(c).Required("name", "contact").Check("ttl", checkTTL).Check("minimum", checkTTL), formatter)
+	domain, err := domainSvc.CreateDomain(domainParams(c, formatter))
 	if err != nil {
 		formatter.PrintFatal("Couldn't create domain", err)
 	}
@@ -80,8 +76,8 @@ func DomainUpdate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	domainSvc, formatter := WireUpDomain(c)
 
-	checkRequiredFlags(c, []string{"id"}, formatter)
-	domain, err := domainSvc.UpdateDomain(utils.FlagConvertParams(c), c.String("id"))
+	validateFlags(c, flags.New(c).Required("id").AnyOf("ttl", "contact", "minimum").Check("ttl", checkTTL).Check("minimum", checkTTL), formatter)
+	domain, err := domainSvc.UpdateDomain(domainParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't update domain", err)
 	}
@@ -97,10 +93,7 @@ func DomainDelete(c *cli.Context) error {
 	domainSvc, formatter := WireUpDomain(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := domainSvc.DeleteDomain(c.String("id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't delete domain", err)
-	}
+	deleteByID(c, "id", "domain", domainSvc.DeleteDomain, formatter)
 	return nil
 }
 
@@ -114,7 +107,7 @@ func DomainRecordList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't list domain records", err)
 	}
-	if err = formatter.PrintList(*domainRecords); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, *domainRecords)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -141,18 +134,18 @@ func DomainRecordCreate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	domainSvc, formatter := WireUpDomain(c)
 
-	checkRequiredFlags(c, []string{"domain_id", "type", "name"}, formatter)
-
+	v := flags.New(c).Required("domain_id", "type", "name").Enum("type", recordTypes...)
 	switch c.String("type") {
-	case "A":
-		checkRequiredFlagsOr(c, []string{"content", "server_id"}, formatter)
-	case "AAAA", "CNAME":
-		checkRequiredFlags(c, []string{"content"}, formatter)
+	case "A", "AAAA":
+		v.AnyOf("content", "server_id")
+	case "CNAME", "TXT":
+		v.Required("content")
 	case "MX":
-		checkRequiredFlags(c, []string{"content", "prio"}, formatter)
+		v.Required("content", "prio")
 	}
+	validateFlags(c, checkRecordFlags(v, c.String("type")), formatter)
 
-	domain, err := domainSvc.CreateDomainRecord(utils.FlagConvertParams(c), c.String("domain_id"))
+	domain, err := domainSvc.CreateDomainRecord(domainParams(c, formatter), c.String("domain_id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't create domain record", err)
 	}
@@ -167,9 +160,15 @@ func DomainRecordUpdate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	domainSvc, formatter := WireUpDomain(c)
 
-	checkRequiredFlags(c, []string{"domain_id", "id"}, formatter)
+	validateFlags(c, flags.New(c).Required("domain_id", "id").AnyOf("name", "content", "ttl", "prio", "server_id"), formatter)
+	// the type of a record can't be changed, so the given fields are checked against the current one
+	record, err := domainSvc.GetDomainRecord(c.String("domain_id"), c.String("id"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive domain record data", err)
+	}
+	validateFlags(c, checkRecordFlags(flags.New(c), record.Type), formatter)
 
-	domain, err := domainSvc.UpdateDomainRecord(utils.FlagConvertParams(c), c.String("domain_id"), c.String("id"))
+	domain, err := domainSvc.UpdateDomainRecord(domainParams(c, formatter), c.String("domain_id"), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't update domain record", err)
 	}
@@ -185,9 +184,87 @@ func DomainRecordDelete(c *cli.Context) error {
 	domainSvc, formatter := WireUpDomain(c)
 
 	checkRequiredFlags(c, []string{"domain_id", "id"}, formatter)
-	err := domainSvc.DeleteDomainRecord(c.String("domain_id"), c.String("id"))
+	deleteByID(c, "id", "domain record", func(id string) error {
+		return domainSvc.DeleteDomainRecord(c.String("domain_id"), id)
+	}, formatter)
+	return nil
+}
+
+// checkRecordFlags checks the fields of a record of type recordType given in flags
+func checkRecordFlags(v *flags.Validator, recordType string) *flags.Validator {
+	v.Check("ttl", checkTTL).Check("prio", checkPriority)
+	switch recordType {
+	case "A":
+		v.Check("content", checkIP(false))
+	case "AAAA":
+		v.Check("content", checkIP(true))
+	}
+	if recordType != "MX" {
+		v.Check("prio", func(string) error { return fmt.Errorf("only MX records have a priority") })
+	}
+	if recordType != "A" && recordType != "AAAA" {
+		v.Check("server_id", func(string) error { return fmt.Errorf("only A and AAAA records have a server") })
+	}
+	return v
+}
+
+// checkIP returns a check of IPv4 addresses, or of IPv6 ones when v6 is set
+func checkIP(v6 bool) func(value string) error {
+	return func(value string) error {
+		ip := net.ParseIP(value)
+		if v6 && (ip == nil || ip.To4() != nil) {
+			return fmt.Errorf("%q isn't an IPv6 address", value)
+		}
+		if !v6 && (ip == nil || ip.To4() == nil) {
+			return fmt.Errorf("%q isn't an IPv4 address", value)
+		}
+		return nil
+	}
+}
+
+// parseTTL returns the seconds of a TTL given in seconds, such as 3600, or as a duration, such as 1h
+func parseTTL(value string) (int, error) {
+	seconds, err := strconv.Atoi(value)
 	if err != nil {
-		formatter.PrintFatal("Couldn't delete domain record", err)
+		d, derr := time.ParseDuration(value)
+		if derr != nil {
+			return 0, fmt.Errorf("%q isn't a number of seconds or a duration such as 1h", value)
+		}
+		if d%time.Second != 0 {
+			return 0, fmt.Errorf("%q isn't a whole number of seconds", value)
+		}
+		seconds = int(d / time.Second)
+	}
+	if seconds <= 0 || seconds > maxTTL {
+		return 0, fmt.Errorf("TTL must be between 1 and %d seconds", maxTTL)
+	}
+	return seconds, nil
+}
+
+func checkTTL(value string) error {
+	_, err := parseTTL(value)
+	return err
+}
+
+func checkPriority(value string) error {
+	prio, err := strconv.Atoi(value)
+	if err != nil || prio < 0 || prio > 65535 {
+		return fmt.Errorf("%q isn't a priority between 0 and 65535", value)
 	}
 	return nil
 }
+
+// domainParams returns the parameters of domains and records given in flags, with TTLs in seconds and
+// priorities as numbers, as the API expects them
+func domainParams(c *cli.Context, f format.Formatter) *map[string]interface{} {
+	params := flagParams(c, f)
+	for _, flag := range []string{"ttl", "minimum"} {
+		if value, ok := (*params)[flag].(string); ok {
+			(*params)[flag], _ = parseTTL(value)
+		}
+	}
+	if value, ok := (*params)["prio"].(string); ok {
+		(*params)["prio"], _ = strconv.Atoi(value)
+	}
+	return params
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/flexiant/concerto/utils/flags"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeFlags holds the flags given to a command
+type fakeFlags map[string]string
+
+func (c fakeFlags) IsSet(name string) bool {
+	_, ok := c[name]
+	return ok
+}
+
+func (c fakeFlags) String(name string) string {
+	return c[name]
+}
+
+func TestParseTTL(t *testing.T) {
+	assert := assert.New(t)
+
+	for value, seconds := range map[string]int{"3600": 3600, "1h": 3600, "90s": 90, "1h30m": 5400, "1": 1, "2147483647": maxTTL} {
+		ttl, err := parseTTL(value)
+		assert.Nil(err, "TTL %s should be valid", value)
+		assert.Equal(seconds, ttl, "Unexpected seconds of TTL %s", value)
+	}
+	for _, value := range []string{"0", "-60", "0s", "2147483648", "1500ms", "1 hour", ""} {
+		_, err := parseTTL(value)
+		assert.NotNil(err, "TTL %q should be rejected", value)
+	}
+}
+
+func TestCheckIP(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(checkIP(false)("203.0.113.10"), "IPv4 addresses should be valid A contents")
+	assert.NotNil(checkIP(false)("2001:db8::1"), "IPv6 addresses shouldn't be valid A contents")
+	assert.NotNil(checkIP(false)("web.example.com"), "Names shouldn't be valid A contents")
+
+	assert.Nil(checkIP(true)("2001:db8::1"), "IPv6 addresses should be valid AAAA contents")
+	assert.NotNil(checkIP(true)("203.0.113.10"), "IPv4 addresses shouldn't be valid AAAA contents")
+	assert.NotNil(checkIP(true)("::ffff:203.0.113.10"), "IPv4 mapped addresses shouldn't be valid AAAA contents")
+	assert.NotNil(checkIP(true)(""), "Empty contents shouldn't be valid AAAA contents")
+}
+
+func TestCheckRecordFlags(t *testing.T) {
+	assert := assert.New(t)
+	problems := func(recordType string, c fakeFlags) []string {
+		return checkRecordFlags(flags.New(c), recordType).Flags()
+	}
+
+	assert.Empty(problems("A", fakeFlags{"content": "203.0.113.10", "ttl": "1h", "server_id": "s1"}), "Valid A records shouldn't have problems")
+	assert.Empty(problems("AAAA", fakeFlags{"content": "2001:db8::1", "ttl": "300"}), "Valid AAAA records shouldn't have problems")
+	assert.Empty(problems("MX", fakeFlags{"content": "mail.example.com", "prio": "10"}), "Valid MX records shouldn't have problems")
+	assert.Empty(problems("CNAME", fakeFlags{"content": "web.example.com"}), "Valid CNAME records shouldn't have problems")
+
+	assert.Equal([]string{"content"}, problems("A", fakeFlags{"content": "2001:db8::1"}), "A records should hold IPv4 addresses")
+	assert.Equal([]string{"content"}, problems("AAAA", fakeFlags{"content": "203.0.113.10"}), "AAAA records should hold IPv6 addresses")
+	assert.Equal([]string{"ttl"}, problems("A", fakeFlags{"content": "203.0.113.10", "ttl": "0"}), "TTLs should be checked")
+	assert.Equal([]string{"prio"}, problems("MX", fakeFlags{"prio": "65536"}), "Priorities should be checked")
+	assert.Equal([]string{"prio"}, problems("A", fakeFlags{"content": "203.0.113.10", "prio": "10"}), "Only MX records should have a priority")
+	assert.Equal([]string{"prio"}, problems("CNAME", fakeFlags{"prio": "10"}), "Only MX records should have a priority")
+	assert.Equal([]string{"server_id"}, problems("CNAME", fakeFlags{"server_id": "s1"}), "Only A and AAAA records should have a server")
+}
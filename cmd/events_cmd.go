@@ -1,43 +1,127 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/audit"
-	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/exit"
 	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/timerange"
 )
 
 // WireUpEvent prepares common resources to send request to Concerto API
 func WireUpEvent(c *cli.Context) (ns *audit.EventService, f format.Formatter) {
-
 	f = format.GetFormatter()
+	return wireUpClient(f).Events, f
+}
+
+// EventList subcommand function
+func EventList(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	eventSvc, formatter := WireUpEvent(c)
 
-	config, err := utils.GetConcertoConfig()
+	// line delimited output is printed while events are received
+	if format.IsLineDelimited(formatter) {
+		if err := eventSvc.StreamEventList(func(event types.Event) error { return formatter.PrintItem(event) }); err != nil {
+			formatter.PrintFatal("Couldn't receive event data", err)
+		}
+		return nil
+	}
+
+	events, err := eventSvc.GetEventList()
 	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
+		formatter.PrintFatal("Couldn't receive event data", err)
 	}
-	hcs, err := utils.NewHTTPConcertoService(config)
+	if err = formatter.PrintList(filterList(c, formatter, events)); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// EventListRange subcommand function. Lists the events between --since and --until in time order and,
+// with --follow, keeps printing new ones till --until passes or the command is cancelled
+func EventListRange(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	eventSvc, formatter := WireUpEvent(c)
+
+	r, err := timerange.Parse(c.String("since"), c.String("until"), time.Now())
 	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
+		formatter.PrintFatal("Incorrect usage.", exit.NewValidationError(err))
+	}
+	interval := c.Duration("interval")
+	if c.Bool("follow") && interval <= 0 {
+		formatter.PrintFatal("Incorrect usage.", exit.NewValidationError(fmt.Errorf("Invalid interval %s", interval)))
 	}
-	ns, err = audit.NewEventService(hcs)
+
+	events, err := eventSvc.GetEventList()
 	if err != nil {
-		f.PrintFatal("Couldn't wire up event service", err)
+		formatter.PrintFatal("Couldn't receive event data", err)
+	}
+	if !c.Bool("follow") {
+		if err = formatter.PrintList(filterList(c, formatter, eventsInRange(events, r, nil))); err != nil {
+			formatter.PrintFatal("Couldn't print/format result", err)
+		}
+		return nil
 	}
 
-	return ns, f
+	seen := make(map[string]bool)
+	for {
+		for _, event := range filterList(c, formatter, eventsInRange(events, r, seen)).([]types.Event) {
+			if err = formatter.PrintItem(event); err != nil {
+				formatter.PrintFatal("Couldn't print/format result", err)
+			}
+		}
+		for _, event := range events {
+			seen[event.Id] = true
+		}
+		if r.Ended(time.Now()) || !cancel.Sleep(interval) {
+			return nil
+		}
+		if events, err = eventSvc.GetEventList(); err != nil {
+			if cancel.Err() != nil {
+				return nil
+			}
+			log.Errorf("Couldn't receive event data: %s", err)
+		}
+	}
 }
 
-// EventList subcommand function
-func EventList(c *cli.Context) error {
+// eventsInRange returns the events within r which haven't been seen, sorted by time
+func eventsInRange(events []types.Event, r timerange.Range, seen map[string]bool) []types.Event {
+	in := []types.Event{}
+	for _, event := range events {
+		if r.Contains(event.Timestamp) && !seen[event.Id] {
+			in = append(in, event)
+		}
+	}
+	sort.SliceStable(in, func(i, j int) bool { return in[i].Timestamp.Before(in[j].Timestamp) })
+	return in
+}
+
+// EventShow subcommand function
+func EventShow(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	eventSvc, formatter := WireUpEvent(c)
 
-	events, err := eventSvc.GetEventList()
+	checkRequiredFlags(c, []string{"id"}, formatter)
+	event, err := eventSvc.GetEvent(c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive event data", err)
 	}
-	if err = formatter.PrintList(events); err != nil {
+	if err = formatter.PrintItem(*event); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -52,8 +136,111 @@ func SysEventList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive system event data", err)
 	}
-	if err = formatter.PrintList(events); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, events)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
 }
+
+// EventSubscribe subcommand function. Polls events, and runs a command for every new event matching filters
+func EventSubscribe(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	eventSvc, formatter := WireUpEvent(c)
+
+	interval, err := time.ParseDuration(c.String("interval"))
+	if err != nil || interval <= 0 {
+		formatter.PrintFatal("Incorrect usage", fmt.Errorf("Invalid interval %s", c.String("interval")))
+	}
+	var match *regexp.Regexp
+	if c.IsSet("match") {
+		if match, err = regexp.Compile(c.String("match")); err != nil {
+			formatter.PrintFatal("Incorrect usage", fmt.Errorf("Invalid match expression: %s", err))
+		}
+	}
+	level := c.String("level")
+	command := c.String("exec")
+
+	sub := newEventSubscription(func(event types.Event) error {
+		if level != "" && !strings.EqualFold(event.Level, level) {
+			return nil
+		}
+		if match != nil && !match.MatchString(event.Header) && !match.MatchString(event.Description) {
+			return nil
+		}
+
+		if command == "" {
+			return formatter.PrintItem(event)
+		}
+		if err := runEventHook(command, event); err != nil {
+			log.Errorf("Command failed for event %s: %s", event.Id, err)
+		}
+		return nil
+	})
+	for {
+		if err := sub.poll(eventSvc.StreamEventList); err != nil {
+			log.Errorf("Couldn't receive event data: %s", err)
+		}
+		if !cancel.Sleep(interval) {
+			return nil
+		}
+	}
+}
+
+// eventSubscription hands each new event to handle once, ignoring events existing before the first poll
+type eventSubscription struct {
+	handle func(event types.Event) error
+	seen   map[string]bool
+	first  bool
+}
+
+func newEventSubscription(handle func(event types.Event) error) *eventSubscription {
+	return &eventSubscription{handle: handle, seen: make(map[string]bool), first: true}
+}
+
+// poll streams events, handling those not seen yet. Events are taken as seen as soon as they're handled, so
+// that they aren't handled again when the stream fails partway. Events no longer listed are forgotten once a
+// whole stream is read
+func (s *eventSubscription) poll(stream func(fn func(event types.Event) error) error) error {
+	current := make(map[string]bool)
+	err := stream(func(event types.Event) error {
+		current[event.Id] = true
+		known := s.first || s.seen[event.Id]
+		s.seen[event.Id] = true
+		if known {
+			return nil
+		}
+		return s.handle(event)
+	})
+	if err != nil {
+		return err
+	}
+	s.seen = current
+	s.first = false
+	return nil
+}
+
+// runEventHook runs command through the shell with event JSON on stdin
+func runEventHook(command string, event types.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("/bin/sh", "-c", command)
+	}
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"CONCERTO_EVENT_ID="+event.Id,
+		"CONCERTO_EVENT_LEVEL="+event.Level,
+		"CONCERTO_EVENT_HEADER="+event.Header,
+	)
+
+	log.Debugf("Running %q for event %s", command, event.Id)
+	return cmd.Run()
+}
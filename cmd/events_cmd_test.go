@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// streamedEvents returns a stream of the events with the given IDs, failing after the first failAfter of them
+// when failAfter isn't negative
+func streamedEvents(failAfter int, ids ...string) func(fn func(event types.Event) error) error {
+	return func(fn func(event types.Event) error) error {
+		for i, id := range ids {
+			if i == failAfter {
+				return fmt.Errorf("connection reset")
+			}
+			if err := fn(types.Event{Id: id}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestEventSubscription(t *testing.T) {
+	assert := assert.New(t)
+
+	var handled []string
+	sub := newEventSubscription(func(event types.Event) error {
+		handled = append(handled, event.Id)
+		return nil
+	})
+
+	assert.Nil(sub.poll(streamedEvents(-1, "e1", "e2")), "Polling shouldn't fail")
+	assert.Empty(handled, "Events existing before subscribing should be ignored")
+
+	assert.Nil(sub.poll(streamedEvents(-1, "e1", "e2", "e3")), "Polling shouldn't fail")
+	assert.Equal([]string{"e3"}, handled, "New events should be handled")
+
+	assert.NotNil(sub.poll(streamedEvents(2, "e4", "e5", "e6")), "A failed stream should be returned")
+	assert.Equal([]string{"e3", "e4", "e5"}, handled, "Events read before the stream failed should be handled")
+
+	assert.Nil(sub.poll(streamedEvents(-1, "e4", "e5", "e6")), "Polling shouldn't fail")
+	assert.Equal([]string{"e3", "e4", "e5", "e6"}, handled, "Events handled before the stream failed shouldn't be handled again")
+	assert.Equal(map[string]bool{"e4": true, "e5": true, "e6": true}, sub.seen, "Events no longer listed should be forgotten")
+}
+
+func TestEventSubscriptionFirstPollFails(t *testing.T) {
+	assert := assert.New(t)
+
+	var handled []string
+	sub := newEventSubscription(func(event types.Event) error {
+		handled = append(handled, event.Id)
+		return nil
+	})
+
+	assert.NotNil(sub.poll(streamedEvents(1, "e1", "e2")), "A failed stream should be returned")
+	assert.Nil(sub.poll(streamedEvents(-1, "e1", "e2")), "Polling shouldn't fail")
+	assert.Empty(handled, "Events existing before the first whole stream should be ignored")
+
+	assert.Nil(sub.poll(streamedEvents(-1, "e1", "e2", "e3")), "Polling shouldn't fail")
+	assert.Equal([]string{"e3"}, handled, "New events should be handled")
+}
@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/chef"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/terraform"
+)
+
+// ExportedFile stores the outcome of an export
+type ExportedFile struct {
+	File      string `json:"file" header:"FILE"`
+	Resources int    `json:"resources" header:"RESOURCES"`
+}
+
+// ExportTerraform subcommand function
+func ExportTerraform(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+
+	dir := c.String("dir")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		formatter.PrintFatal("Couldn't create export directory", err)
+	}
+
+	files := []struct {
+		name      string
+		resources []terraform.Resource
+	}{
+		{"servers.tf", serverResources(c)},
+		{"templates.tf", templateResources(c)},
+		{"dns_domains.tf", domainResources(c)},
+		{"firewall_profiles.tf", firewallProfileResources(c)},
+	}
+
+	var exported []ExportedFile
+	var all []terraform.Resource
+	for _, f := range files {
+		terraform.SortByName(f.resources)
+		terraform.UniqueNames(f.resources)
+		path := filepath.Join(dir, f.name)
+		if err := writeTerraformFile(path, f.resources, terraform.WriteResources); err != nil {
+			formatter.PrintFatal("Couldn't write terraform file", err)
+		}
+		exported = append(exported, ExportedFile{File: path, Resources: len(f.resources)})
+		all = append(all, f.resources...)
+	}
+
+	if !c.Bool("skip-imports") {
+		path := filepath.Join(dir, "imports.tf")
+		if err := writeTerraformFile(path, all, terraform.WriteImports); err != nil {
+			formatter.PrintFatal("Couldn't write terraform file", err)
+		}
+		exported = append(exported, ExportedFile{File: path, Resources: len(all)})
+	}
+	uploadExportedFiles(c, exported, formatter)
+
+	if err := formatter.PrintList(exported); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// ExportChef subcommand function
+func ExportChef(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	templateSvc, formatter := WireUpTemplate(c)
+
+	checkRequiredFlags(c, []string{"template_id"}, formatter)
+	template, err := templateSvc.GetTemplate(c.String("template_id"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive template data", err)
+	}
+
+	cookbooks, err := chef.ParseServiceList(template.ServiceList)
+	if err != nil {
+		formatter.PrintFatal("Couldn't parse template service list", err)
+	}
+	name := chef.EnvironmentName(template.Name)
+	env := chef.NewEnvironment(name, fmt.Sprintf("Exported from Concerto template %s", template.ID), cookbooks, template.ConfigurationAttributes)
+
+	dir := c.String("dir")
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		formatter.PrintFatal("Couldn't create export directory", err)
+	}
+
+	envPath := filepath.Join(dir, name+".json")
+	if err = writeExportFile(envPath, func(w io.Writer) error { return chef.WriteEnvironment(w, env) }); err != nil {
+		formatter.PrintFatal("Couldn't write chef environment", err)
+	}
+	berksPath := filepath.Join(dir, "Berksfile")
+	if err = writeExportFile(berksPath, func(w io.Writer) error { return chef.WriteBerksfile(w, c.String("source"), cookbooks) }); err != nil {
+		formatter.PrintFatal("Couldn't write Berksfile", err)
+	}
+
+	exported := []ExportedFile{
+		{File: envPath, Resources: 1},
+		{File: berksPath, Resources: len(cookbooks)},
+	}
+	uploadExportedFiles(c, exported, formatter)
+
+	if err = formatter.PrintList(exported); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// uploadExportedFiles uploads exported files when --upload is given
+func uploadExportedFiles(c *cli.Context, exported []ExportedFile, f format.Formatter) {
+	files := make([]string, len(exported))
+	for i, e := range exported {
+		files[i] = e.File
+	}
+	uploadFiles(c, files, f)
+}
+
+func writeTerraformFile(path string, resources []terraform.Resource, write func(w io.Writer, resources []terraform.Resource) error) error {
+	return writeExportFile(path, func(w io.Writer) error { return write(w, resources) })
+}
+
+func writeExportFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func serverResources(c *cli.Context) []terraform.Resource {
+	serverSvc, formatter := WireUpServer(c)
+	servers, err := serverSvc.GetServerList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive server data", err)
+	}
+
+	var resources []terraform.Resource
+	for _, s := range servers {
+		resources = append(resources, serverResource(s, s.Template_id))
+	}
+	return resources
+}
+
+// serverResource returns the resource of server s, whose template is given either as an id or as a reference
+func serverResource(s types.Server, templateID interface{}) terraform.Resource {
+	return terraform.Resource{
+		Type: "concerto_server",
+		Name: terraform.ResourceName(s.Name),
+		ID:   s.Id,
+		Attributes: []terraform.Attribute{
+			{Name: "name", Value: s.Name},
+			{Name: "fqdn", Value: s.Fqdn},
+			{Name: "workspace_id", Value: s.Workspace_id},
+			{Name: "template_id", Value: templateID},
+			{Name: "server_plan_id", Value: s.Server_plan_id},
+			{Name: "ssh_profile_id", Value: s.Ssh_profile_id},
+		},
+	}
+}
+
+func templateResources(c *cli.Context) []terraform.Resource {
+	templateSvc, formatter := WireUpTemplate(c)
+	templates, err := templateSvc.GetTemplateList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive template data", err)
+	}
+
+	var resources []terraform.Resource
+	for _, t := range templates {
+		resources = append(resources, templateResource(t))
+	}
+	return resources
+}
+
+func templateResource(t types.Template) terraform.Resource {
+	r := terraform.Resource{
+		Type: "concerto_template",
+		Name: terraform.ResourceName(t.Name),
+		ID:   t.ID,
+		Attributes: []terraform.Attribute{
+			{Name: "name", Value: t.Name},
+			{Name: "generic_image_id", Value: t.GenericImgID},
+		},
+	}
+	if len(t.ServiceList) > 0 {
+		r.Attributes = append(r.Attributes, terraform.Attribute{Name: "service_list", Value: t.ServiceList})
+	}
+	if t.ConfigurationAttributes != nil {
+		r.Attributes = append(r.Attributes, terraform.Attribute{Name: "configuration_attributes", Value: t.ConfigurationAttributes})
+	}
+	return r
+}
+
+func domainResources(c *cli.Context) []terraform.Resource {
+	domainSvc, formatter := WireUpDomain(c)
+	domains, err := domainSvc.GetDomainList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive domain data", err)
+	}
+
+	var resources []terraform.Resource
+	for _, d := range domains {
+		resources = append(resources, terraform.Resource{
+			Type: "concerto_dns_domain",
+			Name: terraform.ResourceName(d.Name),
+			ID:   d.ID,
+			Attributes: []terraform.Attribute{
+				{Name: "name", Value: d.Name},
+				{Name: "ttl", Value: d.TTL},
+				{Name: "contact", Value: d.Contact},
+				{Name: "minimum", Value: d.Minimum},
+			},
+		})
+
+		records, err := domainSvc.GetDomainRecordList(d.ID)
+		if err != nil {
+			formatter.PrintFatal("Couldn't receive domain records data", err)
+		}
+		for _, rec := range *records {
+			resources = append(resources, domainRecordResource(d, rec))
+		}
+	}
+	return resources
+}
+
+func domainRecordResource(d types.Domain, rec types.DomainRecord) terraform.Resource {
+	r := terraform.Resource{
+		Type: "concerto_dns_record",
+		Name: terraform.ResourceName(fmt.Sprintf("%s_%s_%s", d.Name, rec.Type, rec.Name)),
+		ID:   fmt.Sprintf("%s/%s", d.ID, rec.ID),
+		Attributes: []terraform.Attribute{
+			{Name: "domain_id", Value: d.ID},
+			{Name: "type", Value: rec.Type},
+			{Name: "name", Value: rec.Name},
+			{Name: "content", Value: rec.Content},
+			{Name: "ttl", Value: rec.TTL},
+		},
+	}
+	if rec.Prio != 0 {
+		r.Attributes = append(r.Attributes, terraform.Attribute{Name: "prio", Value: rec.Prio})
+	}
+	if rec.ServerID != "" {
+		r.Attributes = append(r.Attributes, terraform.Attribute{Name: "server_id", Value: rec.ServerID})
+	}
+	return r
+}
+
+func firewallProfileResources(c *cli.Context) []terraform.Resource {
+	firewallProfileSvc, formatter := WireUpFirewallProfile(c)
+	profiles, err := firewallProfileSvc.GetFirewallProfileList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive firewallProfile data", err)
+	}
+
+	var resources []terraform.Resource
+	for _, p := range profiles {
+		r := terraform.Resource{
+			Type: "concerto_firewall_profile",
+			Name: terraform.ResourceName(p.Name),
+			ID:   p.Id,
+			Attributes: []terraform.Attribute{
+				{Name: "name", Value: p.Name},
+				{Name: "description", Value: p.Description},
+			},
+		}
+		for _, rule := range p.Rules {
+			r.Blocks = append(r.Blocks, terraform.Block{
+				Name: "rule",
+				Attributes: []terraform.Attribute{
+					{Name: "ip_protocol", Value: rule.Protocol},
+					{Name: "min_port", Value: rule.MinPort},
+					{Name: "max_port", Value: rule.MaxPort},
+					{Name: "source", Value: rule.CidrIp},
+				},
+			})
+		}
+		resources = append(resources, r)
+	}
+	return resources
+}
@@ -1,31 +1,19 @@
 package cmd
 
 import (
+	"encoding/json"
+
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/network"
-	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/exit"
 	"github.com/flexiant/concerto/utils/format"
 )
 
 // WireUpFirewallProfile prepares common resources to send request to Concerto API
 func WireUpFirewallProfile(c *cli.Context) (ds *network.FirewallProfileService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ds, err = network.NewFirewallProfileService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up firewallProfile service", err)
-	}
-
-	return ds, f
+	return wireUpClient(f).FirewallProfiles, f
 }
 
 // FirewallProfileList subcommand function
@@ -37,7 +25,7 @@ func FirewallProfileList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive firewallProfile data", err)
 	}
-	if err = formatter.PrintList(firewallProfiles); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, firewallProfiles)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -65,7 +53,8 @@ func FirewallProfileCreate(c *cli.Context) error {
 	firewallProfileSvc, formatter := WireUpFirewallProfile(c)
 
 	checkRequiredFlags(c, []string{"name", "description"}, formatter)
-	firewallProfile, err := firewallProfileSvc.CreateFirewallProfile(utils.FlagConvertParams(c))
+	checkFirewallRules(c, formatter)
+	firewallProfile, err := firewallProfileSvc.CreateFirewallProfile(flagParams(c, formatter))
 	if err != nil {
 		formatter.PrintFatal("Couldn't create firewallProfile", err)
 	}
@@ -81,7 +70,8 @@ func FirewallProfileUpdate(c *cli.Context) error {
 	firewallProfileSvc, formatter := WireUpFirewallProfile(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	firewallProfile, err := firewallProfileSvc.UpdateFirewallProfile(utils.FlagConvertParams(c), c.String("id"))
+	checkFirewallRules(c, formatter)
+	firewallProfile, err := firewallProfileSvc.UpdateFirewallProfile(flagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't update firewallProfile", err)
 	}
@@ -97,9 +87,20 @@ func FirewallProfileDelete(c *cli.Context) error {
 	firewallProfileSvc, formatter := WireUpFirewallProfile(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := firewallProfileSvc.DeleteFirewallProfile(c.String("id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't delete firewallProfile", err)
-	}
+	deleteByID(c, "id", "firewallProfile", firewallProfileSvc.DeleteFirewallProfile, formatter)
 	return nil
 }
+
+// checkFirewallRules validates the rules given with --rules, before they're sent to the API
+func checkFirewallRules(c *cli.Context, f format.Formatter) {
+	if !c.IsSet("rules") {
+		return
+	}
+	var profile types.FirewallProfile
+	if err := json.Unmarshal([]byte(c.String("rules")), &profile.Rules); err != nil {
+		f.PrintFatal("Invalid rules", exit.NewValidationError(err))
+	}
+	if err := profile.Validate(); err != nil {
+		f.PrintFatal("Invalid rules", exit.NewValidationError(err))
+	}
+}
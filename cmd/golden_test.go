@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update golden files of command output")
+
+// assertGolden checks output against the golden file name in testdata, rewriting it with -update
+func assertGolden(t *testing.T, name string, output []byte) {
+	file := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(file, output, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(expected), string(output), "Output doesn't match golden file %s", file)
+}
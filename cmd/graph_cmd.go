@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/graph"
+)
+
+// GraphExport command function. Renders templates, scripts, servers, workspaces, firewall profiles
+// and DNS records, with their relationships, as a graph
+func GraphExport(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+
+	g := graph.New()
+	addTemplateNodes(c, g)
+	addWorkspaceNodes(c, g)
+	addServerNodes(c, g, !c.Bool("skip-dns"))
+
+	w := format.Output()
+	if file := c.String("file"); file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			formatter.PrintFatal("Couldn't create graph file", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := g.Write(w, c.String("output")); err != nil {
+		formatter.PrintFatal("Couldn't write graph", err)
+	}
+	return nil
+}
+
+// addTemplateNodes adds templates, and the scripts they run
+func addTemplateNodes(c *cli.Context, g *graph.Graph) {
+	templateSvc, formatter := WireUpTemplate(c)
+	scriptSvc, _ := WireUpScript(c)
+
+	scripts, err := scriptSvc.GetScriptList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive script data", err)
+	}
+	scriptNames := make(map[string]string)
+	for _, s := range scripts {
+		scriptNames[s.ID] = s.Name
+	}
+
+	templates, err := templateSvc.GetTemplateList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive template data", err)
+	}
+	for _, t := range templates {
+		templateKey := g.AddNode("template", t.ID, t.Name)
+		for _, scriptType := range types.TemplateScriptTypes {
+			templateScripts, err := templateSvc.GetTemplateScriptList(t.ID, scriptType)
+			if err != nil {
+				formatter.PrintFatal("Couldn't receive templateScript data", err)
+			}
+			for _, ts := range *templateScripts {
+				name := scriptNames[ts.ScriptID]
+				if name == "" {
+					name = ts.ScriptID
+				}
+				scriptKey := g.AddNode("script", ts.ScriptID, name)
+				g.AddEdge(templateKey, scriptKey, fmt.Sprintf("%s #%d", ts.Type, ts.ExecutionOrder))
+			}
+		}
+	}
+}
+
+// addWorkspaceNodes adds workspaces, and the firewall profiles protecting them
+func addWorkspaceNodes(c *cli.Context, g *graph.Graph) {
+	workspaceSvc, formatter := WireUpWorkspace(c)
+	firewallProfileSvc, _ := WireUpFirewallProfile(c)
+
+	profiles, err := firewallProfileSvc.GetFirewallProfileList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive firewall profile data", err)
+	}
+	for _, p := range profiles {
+		g.AddNode("firewall_profile", p.Id, p.Name)
+	}
+
+	workspaces, err := workspaceSvc.GetWorkspaceList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive workspace data", err)
+	}
+	for _, w := range workspaces {
+		workspaceKey := g.AddNode("workspace", w.Id, w.Name)
+		if w.Firewall_profile_id != "" {
+			profileKey := g.AddNode("firewall_profile", w.Firewall_profile_id, w.Firewall_profile_id)
+			g.AddEdge(workspaceKey, profileKey, "protected by")
+		}
+	}
+}
+
+// addServerNodes adds servers, linked to their template and workspace, and optionally their DNS records
+func addServerNodes(c *cli.Context, g *graph.Graph, withDNS bool) {
+	serverSvc, formatter := WireUpServer(c)
+
+	servers, err := serverSvc.GetServerList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive server data", err)
+	}
+	for _, s := range servers {
+		serverKey := g.AddNode("server", s.Id, s.Name)
+		if s.Template_id != "" {
+			g.AddEdge(serverKey, g.AddNode("template", s.Template_id, s.Template_id), "uses")
+		}
+		if s.Workspace_id != "" {
+			g.AddEdge(serverKey, g.AddNode("workspace", s.Workspace_id, s.Workspace_id), "belongs to")
+		}
+		if !withDNS {
+			continue
+		}
+
+		records, err := serverSvc.GetDNSList(s.Id)
+		if err != nil {
+			formatter.PrintFatal("Couldn't receive dns data", err)
+		}
+		for _, r := range records {
+			recordKey := g.AddNode("dns_record", r.Id, dnsRecordLabel(r))
+			g.AddEdge(recordKey, serverKey, "resolves to")
+		}
+	}
+}
+
+func dnsRecordLabel(r types.Dns) string {
+	return fmt.Sprintf("%s %s %s", r.Name, r.Type, r.Content)
+}
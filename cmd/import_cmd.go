@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/chef"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/manifest"
+	"github.com/flexiant/concerto/utils/terraform"
+)
+
+// ImportedServer stores a server written to a manifest, and the resource it comes from
+type ImportedServer struct {
+	Address   string `json:"address" header:"ADDRESS"`
+	Name      string `json:"name" header:"NAME"`
+	Template  string `json:"template" header:"TEMPLATE"`
+	Plan      string `json:"plan" header:"PLAN"`
+	Workspace string `json:"workspace" header:"WORKSPACE"`
+}
+
+// ImportChefRole subcommand function
+func ImportChefRole(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	templateSvc, formatter := WireUpTemplate(c)
+
+	if len(c.Args()) != 1 {
+		formatter.PrintFatal("Incorrect usage", fmt.Errorf("Please, provide the role file"))
+	}
+	if !c.Bool("dry-run") {
+		checkRequiredFlags(c, []string{"generic_image_id"}, formatter)
+	}
+
+	role, err := chef.LoadRole(c.Args().First())
+	if err != nil {
+		formatter.PrintFatal("Couldn't read role", err)
+	}
+	attributes, err := role.Attributes()
+	if err != nil {
+		formatter.PrintFatal("Couldn't convert role attributes", err)
+	}
+	template := &types.Template{
+		Name:                    role.Name,
+		GenericImgID:            c.String("generic_image_id"),
+		ServiceList:             role.ServiceList(),
+		ConfigurationAttributes: attributes,
+	}
+	if c.IsSet("name") {
+		template.Name = c.String("name")
+	}
+
+	if !c.Bool("dry-run") {
+		template, err = templateSvc.CreateTemplate(&map[string]interface{}{
+			"name":                     template.Name,
+			"generic_image_id":         template.GenericImgID,
+			"service_list":             template.ServiceList,
+			"configuration_attributes": template.ConfigurationAttributes,
+		})
+		if err != nil {
+			formatter.PrintFatal("Couldn't create template", err)
+		}
+	}
+	if err = formatter.PrintItem(*template); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// ImportTerraform subcommand function
+func ImportTerraform(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+
+	if len(c.Args()) != 1 {
+		formatter.PrintFatal("Incorrect usage", fmt.Errorf("Please, provide the plan file exported with terraform show -json"))
+	}
+	plans, err := parsePlanMapping(c.StringSlice("plan"))
+	if err != nil {
+		formatter.PrintFatal("Incorrect usage", err)
+	}
+
+	instances, skipped, err := terraform.LoadInstances(c.Args().First())
+	if err != nil {
+		formatter.PrintFatal("Couldn't read terraform plan", err)
+	}
+	if len(instances) == 0 {
+		formatter.PrintFatal("Couldn't import terraform plan", fmt.Errorf("No instances found in %s", c.Args().First()))
+	}
+	log.Debugf("Skipped %d resources which aren't instances", skipped)
+
+	defs := make([]manifest.ServerDefinition, len(instances))
+	imported := make([]ImportedServer, len(instances))
+	for i, inst := range instances {
+		defs[i] = serverDefinition(c, inst, plans)
+		imported[i] = ImportedServer{
+			Address:   inst.Address,
+			Name:      defs[i].Name,
+			Template:  defs[i].Template,
+			Plan:      defs[i].Plan,
+			Workspace: defs[i].Workspace,
+		}
+	}
+
+	if err = writeExportFile(c.String("file"), func(w io.Writer) error { return manifest.WriteServersYAML(w, defs) }); err != nil {
+		formatter.PrintFatal("Couldn't write server manifest", err)
+	}
+	log.Infof("Server manifest written to %s. Review it and create the servers with concerto cloud servers create -f %s", c.String("file"), c.String("file"))
+
+	if err = formatter.PrintList(imported); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// serverDefinition converts an instance, completing the references it lacks with flags
+func serverDefinition(c *cli.Context, inst terraform.Instance, plans map[string]string) manifest.ServerDefinition {
+	def := manifest.ServerDefinition{
+		Name:       inst.Name,
+		Fqdn:       inst.Fqdn,
+		Template:   firstNonEmpty(inst.Template, c.String("template")),
+		Plan:       inst.Plan,
+		Workspace:  firstNonEmpty(inst.Workspace, c.String("workspace")),
+		SSHProfile: firstNonEmpty(inst.SSHProfile, c.String("ssh_profile")),
+		Labels:     inst.Labels,
+	}
+	if def.Fqdn == "" {
+		def.Fqdn = def.Name
+	}
+	if plan, ok := plans[inst.Plan]; ok {
+		def.Plan = plan
+	} else if inst.Type != "concerto_server" {
+		log.Warnf("Instance size %s of %s has no server plan. Use --plan %s=<server_plan_id> or edit the manifest", inst.Plan, inst.Address, inst.Plan)
+	}
+	return def
+}
+
+// parsePlanMapping parses size=server_plan_id pairs
+func parsePlanMapping(pairs []string) (map[string]string, error) {
+	plans := make(map[string]string)
+	for _, p := range pairs {
+		i := strings.Index(p, "=")
+		if i <= 0 || i == len(p)-1 {
+			return nil, fmt.Errorf("Invalid plan %s. Please, use size=server_plan_id", p)
+		}
+		plans[p[:i]] = p[i+1:]
+	}
+	return plans, nil
+}
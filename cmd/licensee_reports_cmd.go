@@ -1,31 +1,17 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/licensee"
-	"github.com/flexiant/concerto/utils"
 	"github.com/flexiant/concerto/utils/format"
 )
 
 // WireUpLicenseeReport prepares common resources to send request to Concerto API
 func WireUpLicenseeReport(c *cli.Context) (ns *licensee.LicenseeReportService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ns, err = licensee.NewLicenseeReportService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up report service", err)
-	}
-
-	return ns, f
+	return wireUpClient(f).LicenseeReports, f
 }
 
 // ReportList subcommand function
@@ -53,6 +39,8 @@ func LicenseeReportShow(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive report data", err)
 	}
+	uploadItem(c, fmt.Sprintf("licensee-report-%s.json", c.String("id")), report, formatter)
+
 	if err = formatter.PrintItem(*report); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
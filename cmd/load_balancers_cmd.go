@@ -3,29 +3,13 @@ package cmd
 import (
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/network"
-	"github.com/flexiant/concerto/utils"
 	"github.com/flexiant/concerto/utils/format"
 )
 
 // WireUpLoadBalancer prepares common resources to send request to Concerto API
 func WireUpLoadBalancer(c *cli.Context) (ds *network.LoadBalancerService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ds, err = network.NewLoadBalancerService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up loadBalancer service", err)
-	}
-
-	return ds, f
+	return wireUpClient(f).LoadBalancers, f
 }
 
 // LoadBalancerList subcommand function
@@ -37,7 +21,7 @@ func LoadBalancerList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive loadBalancer data", err)
 	}
-	if err = formatter.PrintList(loadBalancers); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, loadBalancers)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -72,7 +56,7 @@ func LoadBalancerCreate(c *cli.Context) error {
 		checkRequiredFlags(c, []string{"name", "fqdn", "protocol", "domain_id", "cloud_provider_id", "ssl_certificate", "ssl_certificate_private_key"}, formatter)
 	}
 
-	loadBalancer, err := loadBalancerSvc.CreateLoadBalancer(utils.FlagConvertParams(c))
+	loadBalancer, err := loadBalancerSvc.CreateLoadBalancer(flagParams(c, formatter))
 	if err != nil {
 		formatter.PrintFatal("Couldn't create loadBalancer", err)
 	}
@@ -88,7 +72,7 @@ func LoadBalancerUpdate(c *cli.Context) error {
 	loadBalancerSvc, formatter := WireUpLoadBalancer(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	loadBalancer, err := loadBalancerSvc.UpdateLoadBalancer(utils.FlagConvertParams(c), c.String("id"))
+	loadBalancer, err := loadBalancerSvc.UpdateLoadBalancer(flagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't update loadBalancer", err)
 	}
@@ -104,10 +88,7 @@ func LoadBalancerDelete(c *cli.Context) error {
 	loadBalancerSvc, formatter := WireUpLoadBalancer(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := loadBalancerSvc.DeleteLoadBalancer(c.String("id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't delete loadBalancer", err)
-	}
+	deleteByID(c, "id", "loadBalancer", loadBalancerSvc.DeleteLoadBalancer, formatter)
 	return nil
 }
 
@@ -121,7 +102,7 @@ func LBNodeList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't list loadBalancer nodes", err)
 	}
-	if err = formatter.PrintList(*loadBalancerRecords); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, *loadBalancerRecords)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -133,7 +114,7 @@ func LBNodeCreate(c *cli.Context) error {
 	loadBalancerSvc, formatter := WireUpLoadBalancer(c)
 
 	checkRequiredFlags(c, []string{"balancer_id", "server_id", "port"}, formatter)
-	loadBalancer, err := loadBalancerSvc.CreateLBNode(utils.FlagConvertParams(c), c.String("balancer_id"))
+	loadBalancer, err := loadBalancerSvc.CreateLBNode(flagParams(c, formatter), c.String("balancer_id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't create loadBalancer node", err)
 	}
@@ -149,9 +130,8 @@ func LBNodeDelete(c *cli.Context) error {
 	loadBalancerSvc, formatter := WireUpLoadBalancer(c)
 
 	checkRequiredFlags(c, []string{"balancer_id", "node_id"}, formatter)
-	err := loadBalancerSvc.DeleteLBNode(c.String("balancer_id"), c.String("node_id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't delete loadBalancer node", err)
-	}
+	deleteByID(c, "node_id", "loadBalancer node", func(id string) error {
+		return loadBalancerSvc.DeleteLBNode(c.String("balancer_id"), id)
+	}, formatter)
 	return nil
 }
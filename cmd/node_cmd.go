@@ -3,29 +3,13 @@ package cmd
 import (
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/node"
-	"github.com/flexiant/concerto/utils"
 	"github.com/flexiant/concerto/utils/format"
 )
 
 // WireUpNode prepares common resources to send request to Concerto API
 func WireUpNode(c *cli.Context) (ns *node.NodeService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ns, err = node.NewNodeService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up node service", err)
-	}
-
-	return ns, f
+	return wireUpClient(f).Nodes, f
 }
 
 // NodeList subcommand function
@@ -37,7 +21,7 @@ func NodeList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive node data", err)
 	}
-	if err = formatter.PrintList(nodes); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, nodes)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -49,7 +33,7 @@ func NodeCreate(c *cli.Context) error {
 	nodeSvc, formatter := WireUpNode(c)
 
 	checkRequiredFlags(c, []string{"cluster", "plan"}, formatter)
-	node, err := nodeSvc.CreateNode(utils.FlagConvertParams(c))
+	node, err := nodeSvc.CreateNode(flagParams(c, formatter))
 	if err != nil {
 		formatter.PrintFatal("Couldn't create node", err)
 	}
@@ -65,10 +49,7 @@ func NodeDelete(c *cli.Context) error {
 	nodeSvc, formatter := WireUpNode(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := nodeSvc.DeleteNode(c.String("id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't delete node", err)
-	}
+	deleteByID(c, "id", "node", nodeSvc.DeleteNode, formatter)
 	return nil
 }
 
@@ -78,7 +59,7 @@ func NodeStart(c *cli.Context) error {
 	nodeSvc, formatter := WireUpNode(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := nodeSvc.StartNode(utils.FlagConvertParams(c), c.String("id"))
+	err := nodeSvc.StartNode(flagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't start node", err)
 	}
@@ -91,7 +72,7 @@ func NodeStop(c *cli.Context) error {
 	nodeSvc, formatter := WireUpNode(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := nodeSvc.StopNode(utils.FlagConvertParams(c), c.String("id"))
+	err := nodeSvc.StopNode(flagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't stop node", err)
 	}
@@ -104,7 +85,7 @@ func NodeRestart(c *cli.Context) error {
 	nodeSvc, formatter := WireUpNode(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := nodeSvc.RestartNode(utils.FlagConvertParams(c), c.String("id"))
+	err := nodeSvc.RestartNode(flagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't restart node", err)
 	}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/blueprint"
+	"github.com/flexiant/concerto/api/cloud"
+	"github.com/flexiant/concerto/api/network"
+	"github.com/flexiant/concerto/api/settings"
+	"github.com/flexiant/concerto/utils/crash"
+	"github.com/flexiant/concerto/utils/format"
+)
+
+// overviewSection summarizes a kind of resource of the account, or why it couldn't be received
+type overviewSection struct {
+	Section string `json:"section" header:"SECTION"`
+	Count   int    `json:"count" header:"COUNT"`
+	Details string `json:"details,omitempty" header:"DETAILS"`
+	Error   string `json:"error,omitempty" header:"ERROR"`
+}
+
+// overviewFetcher receives a kind of resource, returning how many there are and a summary of them
+type overviewFetcher func() (count int, details string, err error)
+
+// OverviewShow command function. Receives templates, servers, cloud accounts and firewall profiles at once
+// and summarizes them. Sections that can't be received report their error without hiding the others
+func OverviewShow(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+
+	templateSvc, _ := WireUpTemplate(c)
+	serverSvc, _ := WireUpServer(c)
+	cloudAccountSvc, _ := WireUpCloudAccount(c)
+	firewallProfileSvc, _ := WireUpFirewallProfile(c)
+
+	names := []string{"templates", "servers", "cloud accounts", "firewall profiles"}
+	sections := fetchOverview(names, overviewFetchers(templateSvc, serverSvc, cloudAccountSvc, firewallProfileSvc))
+
+	if err := formatter.PrintList(sections); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	failed := 0
+	for _, s := range sections {
+		if s.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		formatter.PrintFatal("Overview is incomplete", fmt.Errorf("%d of %d sections couldn't be received", failed, len(sections)))
+	}
+	return nil
+}
+
+// overviewFetchers returns the fetchers of the templates, servers, cloud accounts and firewall profiles sections
+func overviewFetchers(templateSvc *blueprint.TemplateService, serverSvc *cloud.ServerService,
+	cloudAccountSvc *settings.CloudAccountService, firewallProfileSvc *network.FirewallProfileService) []overviewFetcher {
+	return []overviewFetcher{
+		func() (int, string, error) {
+			templates, err := templateSvc.GetTemplateList()
+			return len(templates), "", err
+		},
+		func() (int, string, error) {
+			servers, err := serverSvc.GetServerList()
+			states := make([]string, len(servers))
+			for i, s := range servers {
+				states[i] = s.State
+			}
+			return len(servers), countValues(states), err
+		},
+		func() (int, string, error) {
+			accounts, err := cloudAccountSvc.GetCloudAccountList()
+			providers := make(map[string]bool)
+			for _, a := range accounts {
+				providers[a.CloudProvId] = true
+			}
+			return len(accounts), fmt.Sprintf("cloud providers: %d", len(providers)), err
+		},
+		func() (int, string, error) {
+			profiles, err := firewallProfileSvc.GetFirewallProfileList()
+			var details []string
+			rules := 0
+			for _, p := range profiles {
+				if p.Default {
+					details = append(details, fmt.Sprintf("default %s", p.Name))
+				}
+				rules += len(p.Rules)
+			}
+			details = append(details, fmt.Sprintf("%d rules", rules))
+			return len(profiles), strings.Join(details, ", "), err
+		},
+	}
+}
+
+// fetchOverview calls every fetcher in its own goroutine, returning the sections in the same order
+func fetchOverview(names []string, fetchers []overviewFetcher) []overviewSection {
+	sections := make([]overviewSection, len(fetchers))
+	var wg sync.WaitGroup
+	for i, fetch := range fetchers {
+		wg.Add(1)
+		go func(i int, fetch overviewFetcher) {
+			defer wg.Done()
+			defer crash.Recover()
+			sections[i].Section = names[i]
+			count, details, err := fetch()
+			if err != nil {
+				sections[i].Error = err.Error()
+				return
+			}
+			sections[i].Count, sections[i].Details = count, details
+		}(i, fetch)
+	}
+	wg.Wait()
+	return sections
+}
+
+// countValues summarizes values as how many times each one appears, most frequent first, such as
+// "3 operational, 1 inactive"
+func countValues(values []string) string {
+	counts := make(map[string]int)
+	var distinct []string
+	for _, v := range values {
+		if counts[v] == 0 {
+			distinct = append(distinct, v)
+		}
+		counts[v]++
+	}
+	sort.Slice(distinct, func(i, j int) bool {
+		if counts[distinct[i]] != counts[distinct[j]] {
+			return counts[distinct[i]] > counts[distinct[j]]
+		}
+		return distinct[i] < distinct[j]
+	})
+	parts := make([]string, len(distinct))
+	for i, v := range distinct {
+		parts[i] = fmt.Sprintf("%d %s", counts[v], v)
+	}
+	return strings.Join(parts, ", ")
+}
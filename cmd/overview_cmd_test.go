@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/flexiant/concerto/api/blueprint"
+	"github.com/flexiant/concerto/api/cloud"
+	"github.com/flexiant/concerto/api/network"
+	"github.com/flexiant/concerto/api/settings"
+	"github.com/flexiant/concerto/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockedOverviewFetchers returns the overview fetchers of services receiving responses, by path, from a mocked API
+func mockedOverviewFetchers(t *testing.T, responses map[string]string) []overviewFetcher {
+	cs := &utils.MockConcertoService{}
+	for path, body := range responses {
+		status := 200
+		if body == "" {
+			status = 500
+		}
+		cs.On("Get", path).Return([]byte(body), status, nil)
+	}
+	templateSvc, err := blueprint.NewTemplateService(cs)
+	assert.Nil(t, err, "Couldn't load template service")
+	serverSvc, err := cloud.NewServerService(cs)
+	assert.Nil(t, err, "Couldn't load server service")
+	cloudAccountSvc, err := settings.NewCloudAccountService(cs)
+	assert.Nil(t, err, "Couldn't load cloud account service")
+	firewallProfileSvc, err := network.NewFirewallProfileService(cs)
+	assert.Nil(t, err, "Couldn't load firewall profile service")
+	return overviewFetchers(templateSvc, serverSvc, cloudAccountSvc, firewallProfileSvc)
+}
+
+func TestFetchOverview(t *testing.T) {
+	assert := assert.New(t)
+
+	names := []string{"templates", "servers", "cloud accounts", "firewall profiles"}
+	sections := fetchOverview(names, mockedOverviewFetchers(t, map[string]string{
+		"/v1/blueprint/templates":     `[{"id":"t1"},{"id":"t2"}]`,
+		"/v1/cloud/servers":           `[{"id":"s1","state":"operational"},{"id":"s2","state":"inactive"},{"id":"s3","state":"operational"}]`,
+		"/v1/settings/cloud_accounts": `[{"id":"a1","cloud_provider_id":"p1"},{"id":"a2","cloud_provider_id":"p1"},{"id":"a3","cloud_provider_id":"p2"}]`,
+		"/v1/network/firewall_profiles": `[{"id":"f1","name":"Default","default":true,"rules":[{"protocol":"tcp"},{"protocol":"udp"}]},` +
+			`{"id":"f2","name":"web","rules":[{"protocol":"tcp"}]}]`,
+	}))
+	assert.Equal([]overviewSection{
+		{Section: "templates", Count: 2},
+		{Section: "servers", Count: 3, Details: "2 operational, 1 inactive"},
+		{Section: "cloud accounts", Count: 3, Details: "cloud providers: 2"},
+		{Section: "firewall profiles", Count: 2, Details: "default Default, 3 rules"},
+	}, sections, "Every section should be summarized, in the given order")
+}
+
+func TestFetchOverviewFailedSection(t *testing.T) {
+	assert := assert.New(t)
+
+	names := []string{"templates", "servers", "cloud accounts", "firewall profiles"}
+	sections := fetchOverview(names, mockedOverviewFetchers(t, map[string]string{
+		"/v1/blueprint/templates":       `[{"id":"t1"}]`,
+		"/v1/cloud/servers":             "",
+		"/v1/settings/cloud_accounts":   `[]`,
+		"/v1/network/firewall_profiles": `[]`,
+	}))
+	assert.Equal(overviewSection{Section: "templates", Count: 1}, sections[0], "Sections received should be summarized")
+	assert.Equal("servers", sections[1].Section)
+	assert.NotEmpty(sections[1].Error, "Sections that couldn't be received should report their error")
+	assert.Zero(sections[1].Count, "Sections that couldn't be received shouldn't be counted")
+	assert.Equal(overviewSection{Section: "cloud accounts", Details: "cloud providers: 0"}, sections[2])
+	assert.Equal(overviewSection{Section: "firewall profiles", Details: "0 rules"}, sections[3])
+
+	sections = fetchOverview([]string{"a", "b"}, []overviewFetcher{
+		func() (int, string, error) { return 0, "", fmt.Errorf("Timed out") },
+		func() (int, string, error) { return 4, "fine", nil },
+	})
+	assert.Equal([]overviewSection{{Section: "a", Error: "Timed out"}, {Section: "b", Count: 4, Details: "fine"}}, sections)
+}
+
+func TestCountValues(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("3 operational, 1 inactive", countValues([]string{"operational", "inactive", "operational", "operational"}))
+	assert.Equal("1 booting, 1 inactive", countValues([]string{"inactive", "booting"}), "Ties should be sorted by value")
+	assert.Equal("", countValues(nil), "No values should have no summary")
+}
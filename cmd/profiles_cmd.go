@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/fanout"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/release"
+)
+
+// profileFlags maps the global flags passed on to the command of every profile to their environment variables.
+// Endpoint and certificates come from the configuration of each profile
+var profileFlags = map[string]string{
+	"log-level":         "CONCERTO_LOG_LEVEL",
+	"timeout":           "CONCERTO_TIMEOUT",
+	"max-response-size": "CONCERTO_MAX_RESPONSE_SIZE",
+}
+
+// profileVariables are removed from the environment of profile commands, so that they take
+// the configuration of the profile and don't fan out again
+var profileVariables = []string{
+	"CONCERTO_CONFIG", "CONCERTO_ENDPOINT", "CONCERTO_CLIENT_CERT", "CONCERTO_CLIENT_KEY", "CONCERTO_CA_CERT", "CONCERTO_PROFILE", "CONCERTO_PROFILES",
+}
+
+// ProfilesFanOut runs the read-only command of c against the selected profiles concurrently,
+// and prints their outputs merged, with a profile column
+func ProfilesFanOut(c *cli.Context, config *utils.Config) {
+	formatter := format.GetFormatter()
+
+	profiles, err := selectProfiles(config, c.String("profiles"), c.Bool("all-profiles"))
+	if err != nil {
+		formatter.PrintFatal("Incorrect usage", err)
+	}
+	args := []string(c.Args())
+	if !fanout.ReadOnly(args) {
+		formatter.PrintFatal("Incorrect usage", fmt.Errorf("Only read-only commands, such as list, show or api GET, can run against several profiles"))
+	}
+	executable, err := release.Executable()
+	if err != nil {
+		formatter.PrintFatal("Couldn't find concerto binary", err)
+	}
+
+	outputs := make([][]byte, len(profiles))
+	errs := make([]error, len(profiles))
+	var wg sync.WaitGroup
+	for i, p := range profiles {
+		wg.Add(1)
+		go func(i int, p utils.Profile) {
+			defer wg.Done()
+			log.Debugf("Running concerto %s for profile %s", strings.Join(args, " "), p.Name)
+			outputs[i], errs[i] = runConcerto(executable, args, profileEnvironment(c, config, p))
+		}(i, p)
+	}
+	wg.Wait()
+
+	table := fanout.NewTable()
+	failed := 0
+	for i, p := range profiles {
+		if errs[i] == nil {
+			errs[i] = table.Add(p.Name, outputs[i])
+		}
+		if errs[i] != nil {
+			log.Errorf("Profile %s failed: %s", p.Name, errs[i])
+			failed++
+		}
+	}
+
+	if _, ok := formatter.(*format.TextFormatter); ok {
+		err = table.WriteText(format.Output())
+	} else {
+		err = formatter.PrintList(table.Rows)
+	}
+	if err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	if failed > 0 {
+		formatter.PrintFatal("Command didn't complete", fmt.Errorf("%d of %d profiles failed", failed, len(profiles)))
+	}
+}
+
+// selectProfiles returns the profiles named in a comma separated list, or all of them,
+// with absolute configuration paths
+func selectProfiles(config *utils.Config, names string, all bool) ([]utils.Profile, error) {
+	if len(config.Profiles) == 0 {
+		return nil, fmt.Errorf("No profiles defined in %s", config.ConfFile)
+	}
+	defined := make(map[string]utils.Profile)
+	for _, p := range config.Profiles {
+		defined[p.Name] = p
+	}
+
+	var selected []utils.Profile
+	if all {
+		selected = config.Profiles
+	} else {
+		for _, name := range strings.Split(names, ",") {
+			p, ok := defined[strings.TrimSpace(name)]
+			if !ok {
+				return nil, fmt.Errorf("Profile %s isn't defined in %s", name, config.ConfFile)
+			}
+			selected = append(selected, p)
+		}
+	}
+
+	profiles := make([]utils.Profile, len(selected))
+	for i, p := range selected {
+		if p.Config != "" {
+			file, err := config.ProfileFile(p)
+			if err != nil {
+				return nil, err
+			}
+			p.Config = file
+		}
+		profiles[i] = p
+	}
+	return profiles, nil
+}
+
+// profileEnvironment returns the environment of the command run for profile p. Profiles without
+// a configuration file of their own are selected in the configuration of c
+func profileEnvironment(c *cli.Context, config *utils.Config, p utils.Profile) []string {
+	var env []string
+	for _, v := range os.Environ() {
+		if !isProfileVariable(v) {
+			env = append(env, v)
+		}
+	}
+	for flag, name := range profileFlags {
+		if value := c.GlobalString(flag); value != "" {
+			env = append(env, fmt.Sprintf("%s=%s", name, value))
+		}
+	}
+	if p.Config != "" {
+		return append(env, fmt.Sprintf("CONCERTO_CONFIG=%s", p.Config))
+	}
+	return append(env, fmt.Sprintf("CONCERTO_CONFIG=%s", config.ConfFile), fmt.Sprintf("CONCERTO_PROFILE=%s", p.Name))
+}
+
+func isProfileVariable(v string) bool {
+	for _, name := range profileVariables {
+		if strings.HasPrefix(v, name+"=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/manifest"
+	"github.com/flexiant/concerto/utils/release"
+)
+
+// stepSkippedFlags are the global flags of the run which aren't passed on to its steps: steps print
+// JSON so that their values can be captured, while bulk progress, notifications, metrics and recordings
+// belong to the run
+var stepSkippedFlags = map[string]bool{
+	"help":         true,
+	"version":      true,
+	"formatter":    true,
+	"quiet":        true,
+	"wide":         true,
+	"no-header":    true,
+	"error-format": true,
+	"state-file":   true,
+	"restate":      true,
+	"notify":       true,
+	"metrics-addr": true,
+	"metrics-file": true,
+	"record":       true,
+}
+
+// StepResult stores the outcome of a step of an operations file
+type StepResult struct {
+	Step     int    `json:"step" header:"STEP"`
+	Name     string `json:"name" header:"NAME"`
+	Status   string `json:"status" header:"STATUS"`
+	Duration string `json:"duration,omitempty" header:"DURATION"`
+	Captured string `json:"captured,omitempty" header:"CAPTURED"`
+	Error    string `json:"error,omitempty" header:"ERROR"`
+}
+
+// RunOperations command function. Runs the steps of an operations file in order, passing values
+// captured from their outputs on to later steps, and prints the outcome of each one
+func RunOperations(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+	checkRequiredFlags(c, []string{"file"}, formatter)
+
+	ops, err := manifest.LoadOperations(c.String("file"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't read operations", err)
+	}
+	vars := ops.Vars
+	for _, v := range c.StringSlice("var") {
+		i := strings.Index(v, "=")
+		if i <= 0 {
+			formatter.PrintFatal("Incorrect usage", fmt.Errorf("Invalid variable %s. Please, use name=value", v))
+		}
+		vars[v[:i]] = v[i+1:]
+	}
+
+	executable, err := release.Executable()
+	if err != nil {
+		formatter.PrintFatal("Couldn't find concerto binary", err)
+	}
+	globalFlags := stepGlobalFlags(c)
+
+	results := make([]StepResult, len(ops.Steps))
+	aborted := false
+	failed := 0
+	for i, step := range ops.Steps {
+		results[i] = StepResult{Step: i + 1, Name: step.Name, Status: "skipped"}
+		if aborted {
+			continue
+		}
+
+		start := time.Now()
+		captured, err := runOperation(executable, globalFlags, step, vars)
+		results[i].Duration = (time.Since(start) / time.Millisecond * time.Millisecond).String()
+		results[i].Captured = strings.Join(captured, ", ")
+		if err != nil {
+			log.Errorf("Step %s failed: %s", step.Name, err)
+			results[i].Status = "failed"
+			results[i].Error = err.Error()
+			failed++
+			aborted = step.OnError == manifest.OnErrorAbort || cancel.Err() != nil
+			continue
+		}
+		results[i].Status = "done"
+	}
+
+	if err = formatter.PrintList(results); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	if failed > 0 {
+		formatter.PrintFatal("Operations didn't complete", fmt.Errorf("%d of %d steps failed", failed, len(results)))
+	}
+	return nil
+}
+
+// runOperation runs a step with the JSON formatter and the global flags of the run, and stores the values
+// it captures into vars
+func runOperation(executable string, globalFlags []string, step manifest.Operation, vars map[string]string) ([]string, error) {
+	args, err := step.Args(vars)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Running %s: concerto %s", step.Name, strings.Join(args, " "))
+
+	output, err := runConcerto(executable, append(append([]string{}, globalFlags...), args...), os.Environ())
+	if err != nil {
+		return nil, err
+	}
+
+	var captured []string
+	for _, c := range step.Capture {
+		value, err := manifest.CaptureValue(output, c.Path)
+		if err != nil {
+			return captured, fmt.Errorf("Couldn't capture %s: %s", c.Variable, err)
+		}
+		vars[c.Variable] = value
+		captured = append(captured, fmt.Sprintf("%s=%s", c.Variable, value))
+	}
+	return captured, nil
+}
+
+// runConcerto runs executable with the JSON formatter and returns its output. Once the command is
+// cancelled, the child is interrupted so that it cleans up as it would on Ctrl-C
+func runConcerto(executable string, args []string, env []string) ([]byte, error) {
+	var stdout bytes.Buffer
+	command := exec.Command(executable, append([]string{"--formatter", "json"}, args...)...)
+	command.Env = env
+	command.Stdout = &stdout
+	command.Stderr = os.Stderr
+	if err := command.Start(); err != nil {
+		return nil, err
+	}
+	done := make(chan error, 1)
+	go func() { done <- command.Wait() }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-cancel.Done():
+		if command.Process.Signal(os.Interrupt) != nil {
+			command.Process.Kill()
+		}
+		<-done
+		return nil, cancel.Err()
+	}
+	log.Debugf("Output of concerto %s: %s", strings.Join(args, " "), stdout.String())
+
+	if err != nil {
+		var msg format.JSONMessage
+		if json.Unmarshal(stdout.Bytes(), &msg) == nil && msg.Type == "Error" {
+			return nil, fmt.Errorf("%s: %s", msg.Context, msg.Message)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// stepGlobalFlags returns the global flags given to the run, under any of their names, as flags of its
+// steps. Those given as environment variables reach the steps in their environment
+func stepGlobalFlags(c *cli.Context) []string {
+	var globalFlags []string
+	for _, f := range c.App.Flags {
+		names := strings.Split(f.GetName(), ",")
+		if stepSkippedFlags[strings.TrimSpace(names[0])] {
+			continue
+		}
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			if c.GlobalIsSet(name) {
+				globalFlags = append(globalFlags, fmt.Sprintf("--%s=%v", name, c.GlobalGeneric(name)))
+				break
+			}
+		}
+	}
+	return globalFlags
+}
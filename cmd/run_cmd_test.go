@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepGlobalFlags(t *testing.T) {
+	assert := assert.New(t)
+
+	var globalFlags []string
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{
+		cli.BoolFlag{Name: "debug, D"},
+		cli.StringFlag{Name: "profile"},
+		cli.StringFlag{Name: "api-token"},
+		cli.BoolFlag{Name: "insecure"},
+		cli.IntFlag{Name: "max-connections"},
+		cli.StringFlag{Name: "proxy-url"},
+		cli.StringFlag{Name: "formatter, output"},
+		cli.StringFlag{Name: "state-file"},
+	}
+	app.Commands = []cli.Command{{
+		Name: "run",
+		Action: func(c *cli.Context) error {
+			globalFlags = stepGlobalFlags(c)
+			return nil
+		},
+	}}
+
+	err := app.Run([]string{"concerto", "-D", "--profile", "staging", "--insecure", "--max-connections", "8",
+		"--output", "text", "--state-file", "run.json", "run"})
+	assert.Nil(err, "Running the app shouldn't fail")
+	assert.Equal([]string{"--debug=true", "--profile=staging", "--insecure=true", "--max-connections=8"}, globalFlags,
+		"Global flags given to the run, but output and progress ones, should be passed on to steps")
+}
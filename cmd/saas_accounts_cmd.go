@@ -3,29 +3,13 @@ package cmd
 import (
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/settings"
-	"github.com/flexiant/concerto/utils"
 	"github.com/flexiant/concerto/utils/format"
 )
 
 // WireUpSaasAccount prepares common resources to send request to Concerto API
 func WireUpSaasAccount(c *cli.Context) (ds *settings.SaasAccountService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ds, err = settings.NewSaasAccountService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up saasAccount service", err)
-	}
-
-	return ds, f
+	return wireUpClient(f).SaasAccounts, f
 }
 
 // SaasAccountList subcommand function
@@ -37,7 +21,7 @@ func SaasAccountList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive saasAccount data", err)
 	}
-	if err = formatter.PrintList(saasAccounts); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, saasAccounts)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -49,7 +33,7 @@ func SaasAccountCreate(c *cli.Context) error {
 	saasAccountSvc, formatter := WireUpSaasAccount(c)
 
 	checkRequiredFlags(c, []string{"saas_provider_id", "account_data"}, formatter)
-	saasAccount, err := saasAccountSvc.CreateSaasAccount(utils.FlagConvertParams(c))
+	saasAccount, err := saasAccountSvc.CreateSaasAccount(flagParams(c, formatter))
 	if err != nil {
 		formatter.PrintFatal("Couldn't create saasAccount", err)
 	}
@@ -65,7 +49,7 @@ func SaasAccountUpdate(c *cli.Context) error {
 	saasAccountSvc, formatter := WireUpSaasAccount(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	saasAccount, err := saasAccountSvc.UpdateSaasAccount(utils.FlagConvertParams(c), c.String("id"))
+	saasAccount, err := saasAccountSvc.UpdateSaasAccount(flagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't update saasAccount", err)
 	}
@@ -81,9 +65,6 @@ func SaasAccountDelete(c *cli.Context) error {
 	saasAccountSvc, formatter := WireUpSaasAccount(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := saasAccountSvc.DeleteSaasAccount(c.String("id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't delete saasAccount", err)
-	}
+	deleteByID(c, "id", "saasAccount", saasAccountSvc.DeleteSaasAccount, formatter)
 	return nil
 }
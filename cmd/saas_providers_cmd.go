@@ -3,29 +3,13 @@ package cmd
 import (
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/cloud"
-	"github.com/flexiant/concerto/utils"
 	"github.com/flexiant/concerto/utils/format"
 )
 
 // WireUpSaasProvider prepares common resources to send request to Concerto API
 func WireUpSaasProvider(c *cli.Context) (cs *cloud.SaasProviderService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	cs, err = cloud.NewSaasProviderService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up saasProvider service", err)
-	}
-
-	return cs, f
+	return wireUpClient(f).SaasProviders, f
 }
 
 // SaasProviderList subcommand function
@@ -37,7 +21,7 @@ func SaasProviderList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive saasProvider data", err)
 	}
-	if err = formatter.PrintList(saasProviders); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, saasProviders)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -1,31 +1,38 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/blueprint"
-	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/crash"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/flags"
 	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/pool"
 )
 
+// scriptParameterRegexp matches valid parameter names, as hosts receive parameters as environment variables
+var scriptParameterRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// scriptReference is a characterisation of a template running a script
+type scriptReference struct {
+	TemplateID       string `json:"template_id" header:"TEMPLATE ID"`
+	TemplateName     string `json:"template_name" header:"TEMPLATE NAME"`
+	Type             string `json:"type" header:"TYPE"`
+	ExecutionOrder   int    `json:"execution_order" header:"EXECUTION ORDER"`
+	TemplateScriptID string `json:"template_script_id" header:"TEMPLATE SCRIPT ID"`
+}
+
 // WireUpScript prepares common resources to send request to Concerto API
 func WireUpScript(c *cli.Context) (scs *blueprint.ScriptService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	scs, err = blueprint.NewScriptService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up script service", err)
-	}
-
-	return scs, f
+	return wireUpClient(f).Scripts, f
 }
 
 // ScriptsList subcommand function
@@ -37,7 +44,7 @@ func ScriptsList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive script data", err)
 	}
-	if err = formatter.PrintList(scripts); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, scripts)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -64,8 +71,8 @@ func ScriptCreate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	scriptSvc, formatter := WireUpScript(c)
 
-	checkRequiredFlags(c, []string{"name", "description", "code"}, formatter)
-	script, err := scriptSvc.CreateScript(utils.FlagConvertParams(c))
+	validateFlags(c, flags.New(c).Required("name", "description", "code").Check("parameters", checkScriptParameters), formatter)
+	script, err := scriptSvc.CreateScript(scriptParams(c, formatter))
 	if err != nil {
 		formatter.PrintFatal("Couldn't create script", err)
 	}
@@ -80,8 +87,8 @@ func ScriptUpdate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	scriptSvc, formatter := WireUpScript(c)
 
-	checkRequiredFlags(c, []string{"id"}, formatter)
-	script, err := scriptSvc.UpdateScript(utils.FlagConvertParams(c), c.String("id"))
+	validateFlags(c, flags.New(c).Required("id").AnyOf("name", "description", "code", "parameters").Check("parameters", checkScriptParameters), formatter)
+	script, err := scriptSvc.UpdateScript(scriptParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't update script", err)
 	}
@@ -97,9 +104,151 @@ func ScriptDelete(c *cli.Context) error {
 	scriptSvc, formatter := WireUpScript(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := scriptSvc.DeleteScript(c.String("id"))
+	deleteByID(c, "id", "script", scriptSvc.DeleteScript, formatter)
+	return nil
+}
+
+// ScriptTemplateList subcommand function
+func ScriptTemplateList(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	scriptSvc, formatter := WireUpScript(c)
+	templateSvc, _ := WireUpTemplate(c)
+
+	checkRequiredFlags(c, []string{"id"}, formatter)
+	script, err := scriptSvc.GetScript(c.String("id"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive script data", err)
+	}
+	templates, err := templateSvc.GetTemplateList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive template data", err)
+	}
+	references, err := scriptReferences(templateSvc, script.ID, templates)
 	if err != nil {
-		formatter.PrintFatal("Couldn't delete script", err)
+		formatter.PrintFatal("Couldn't receive templateScript data", err)
+	}
+	if err = formatter.PrintList(filterList(c, formatter, references)); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
 }
+
+// scriptReferences returns the templates running the script, receiving the scripts of every template
+// and characterisation type using as many workers as bulk operations
+func scriptReferences(templateSvc *blueprint.TemplateService, scriptID string, templates []types.Template) ([]scriptReference, error) {
+	scriptTypes := types.TemplateScriptTypes
+	lists := make([]*[]types.TemplateScript, len(templates)*len(scriptTypes))
+	errs := make([]error, len(lists))
+	indexes := make(chan int)
+
+	workers := pool.Concurrency()
+	if workers > len(lists) {
+		workers = len(lists)
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer crash.Recover()
+			for i := range indexes {
+				t := templates[i/len(scriptTypes)]
+				lists[i], errs[i] = templateSvc.GetTemplateScriptList(t.ID, scriptTypes[i%len(scriptTypes)])
+			}
+		}()
+	}
+	for i := range lists {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	references := []scriptReference{}
+	for i, templateScripts := range lists {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if templateScripts == nil {
+			continue
+		}
+		t := templates[i/len(scriptTypes)]
+		for _, ts := range *templateScripts {
+			if ts.ScriptID == scriptID {
+				references = append(references, scriptReference{
+					TemplateID:       t.ID,
+					TemplateName:     t.Name,
+					Type:             ts.Type,
+					ExecutionOrder:   ts.ExecutionOrder,
+					TemplateScriptID: ts.ID,
+				})
+			}
+		}
+	}
+	return references, nil
+}
+
+// scriptParams returns the parameters of a script given in flags, with its code read from a file when
+// given as @file, and its parameters as a list of names
+func scriptParams(c *cli.Context, f format.Formatter) *map[string]interface{} {
+	params := flagParams(c, f)
+	if c.IsSet("code") {
+		code, err := readScriptCode(c.String("code"))
+		if err != nil {
+			f.PrintFatal("Couldn't read script code", exit.NewValidationError(err))
+		}
+		(*params)["code"] = code
+	}
+	if c.IsSet("parameters") {
+		// already checked
+		(*params)["parameters"], _ = parseScriptParameters(c.String("parameters"))
+	}
+	return params
+}
+
+// readScriptCode reads the code of a script given inline, from a file prefixed with @, or from standard
+// input with @-
+func readScriptCode(value string) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return value, nil
+	}
+	data, err := readFlagValue(value)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("%s is empty", value[1:])
+	}
+	return string(data), nil
+}
+
+// parseScriptParameters returns the parameter names given comma separated, such as PORT,VERSION, or as
+// a JSON array
+func parseScriptParameters(value string) ([]string, error) {
+	var names []string
+	if strings.HasPrefix(strings.TrimSpace(value), "[") {
+		if err := json.Unmarshal([]byte(value), &names); err != nil {
+			return nil, fmt.Errorf("it must be a JSON array of parameter names. %s", err)
+		}
+	} else {
+		names = strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if !scriptParameterRegexp.MatchString(name) {
+			return nil, fmt.Errorf("%q isn't a valid parameter name, as parameters are set as environment variables", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%q is repeated", name)
+		}
+		seen[name] = true
+	}
+	if names == nil {
+		names = []string{}
+	}
+	return names, nil
+}
+
+func checkScriptParameters(value string) error {
+	_, err := parseScriptParameters(value)
+	return err
+}
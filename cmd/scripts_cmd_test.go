@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flexiant/concerto/api/blueprint"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScriptParameters(t *testing.T) {
+	assert := assert.New(t)
+
+	names, err := parseScriptParameters("PORT,VERSION")
+	assert.Nil(err, "Comma separated names should be valid")
+	assert.Equal([]string{"PORT", "VERSION"}, names, "Names should be split by commas")
+
+	names, err = parseScriptParameters("PORT, VERSION")
+	assert.Nil(err, "Spaces after commas should be ignored")
+	assert.Equal([]string{"PORT", "VERSION"}, names, "Names should be split by commas and spaces")
+
+	names, err = parseScriptParameters(` ["PORT", "_VERSION2"]`)
+	assert.Nil(err, "A JSON array should be valid")
+	assert.Equal([]string{"PORT", "_VERSION2"}, names, "Names should be read from the JSON array")
+
+	names, err = parseScriptParameters("")
+	assert.Nil(err, "No names should be valid")
+	assert.Equal([]string{}, names, "No names should be an empty list")
+
+	_, err = parseScriptParameters(`["PORT"`)
+	assert.NotNil(err, "An invalid JSON array should be rejected")
+	_, err = parseScriptParameters("PORT,2ND")
+	assert.NotNil(err, "Names starting with a digit should be rejected")
+	_, err = parseScriptParameters("MY-PORT")
+	assert.NotNil(err, "Names with dashes should be rejected")
+	_, err = parseScriptParameters("PORT,VERSION,PORT")
+	assert.NotNil(err, "Repeated names should be rejected")
+}
+
+func TestReadScriptCode(t *testing.T) {
+	assert := assert.New(t)
+
+	code, err := readScriptCode("echo hello")
+	assert.Nil(err, "Inline code should be valid")
+	assert.Equal("echo hello", code, "Inline code should be returned as given")
+
+	dir, err := ioutil.TempDir("", "script_code")
+	assert.Nil(err, "Couldn't create temp dir")
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "install.sh")
+	assert.Nil(ioutil.WriteFile(file, []byte("#!/bin/sh\necho hello\n"), 0600), "Couldn't write script")
+	code, err = readScriptCode("@" + file)
+	assert.Nil(err, "Code in a file should be valid")
+	assert.Equal("#!/bin/sh\necho hello\n", code, "Code should be read from the file")
+
+	empty := filepath.Join(dir, "empty.sh")
+	assert.Nil(ioutil.WriteFile(empty, []byte(" \n"), 0600), "Couldn't write script")
+	_, err = readScriptCode("@" + empty)
+	assert.NotNil(err, "An empty file should be rejected")
+
+	_, err = readScriptCode("@" + filepath.Join(dir, "missing.sh"))
+	assert.NotNil(err, "A missing file should be rejected")
+}
+
+func TestScriptReferences(t *testing.T) {
+	assert := assert.New(t)
+
+	cs := &utils.MockConcertoService{}
+	templateSvc, err := blueprint.NewTemplateService(cs)
+	assert.Nil(err, "Couldn't load template service")
+
+	templates := []types.Template{{ID: "t1", Name: "web"}, {ID: "t2", Name: "db"}}
+	scripts := map[string][]types.TemplateScript{
+		"t1 boot":      {{ID: "ts1", Type: "boot", ScriptID: "s1", ExecutionOrder: 1}, {ID: "ts2", Type: "boot", ScriptID: "s2"}},
+		"t1 migration": {{ID: "ts3", Type: "migration", ScriptID: "s1", ExecutionOrder: 2}},
+		"t2 shutdown":  {{ID: "ts4", Type: "shutdown", ScriptID: "s1"}},
+	}
+	for _, template := range templates {
+		for _, scriptType := range types.TemplateScriptTypes {
+			data, err := json.Marshal(scripts[template.ID+" "+scriptType])
+			assert.Nil(err, "Template script test data corrupted")
+			cs.On("Get", fmt.Sprintf("/v1/blueprint/templates/%s/scripts?type=%s", template.ID, scriptType)).Return(data, 200, nil)
+		}
+	}
+
+	references, err := scriptReferences(templateSvc, "s1", templates)
+	assert.Nil(err, "Receiving template scripts shouldn't fail")
+	assert.Equal([]scriptReference{
+		{TemplateID: "t1", TemplateName: "web", Type: "boot", ExecutionOrder: 1, TemplateScriptID: "ts1"},
+		{TemplateID: "t1", TemplateName: "web", Type: "migration", ExecutionOrder: 2, TemplateScriptID: "ts3"},
+		{TemplateID: "t2", TemplateName: "db", Type: "shutdown", TemplateScriptID: "ts4"},
+	}, references, "References should keep templates and types order")
+	cs.AssertNumberOfCalls(t, "Get", len(templates)*len(types.TemplateScriptTypes))
+
+	references, err = scriptReferences(templateSvc, "s3", templates)
+	assert.Nil(err, "Receiving template scripts shouldn't fail")
+	assert.Empty(references, "A script not in any template shouldn't have references")
+}
+
+func TestScriptReferencesFails(t *testing.T) {
+	assert := assert.New(t)
+
+	cs := &utils.MockConcertoService{}
+	templateSvc, err := blueprint.NewTemplateService(cs)
+	assert.Nil(err, "Couldn't load template service")
+
+	for _, scriptType := range types.TemplateScriptTypes {
+		status := 200
+		if scriptType == "operational" {
+			status = 500
+		}
+		cs.On("Get", fmt.Sprintf("/v1/blueprint/templates/t1/scripts?type=%s", scriptType)).Return([]byte("[]"), status, nil)
+	}
+
+	_, err = scriptReferences(templateSvc, "s1", []types.Template{{ID: "t1", Name: "web"}})
+	assert.NotNil(err, "A failed request should be returned")
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/release"
+)
+
+// UpdateStatus stores the outcome of a self update
+type UpdateStatus struct {
+	CurrentVersion string `json:"current_version" header:"CURRENT VERSION"`
+	LatestVersion  string `json:"latest_version" header:"LATEST VERSION"`
+	Status         string `json:"status" header:"STATUS"`
+	Binary         string `json:"binary,omitempty" header:"BINARY"`
+}
+
+// SelfUpdate command function. Replaces the running binary with the latest signed release
+func SelfUpdate(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+
+	latest, err := release.Latest(c.String("url"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't check latest release", err)
+	}
+	status := UpdateStatus{
+		CurrentVersion: utils.VERSION,
+		LatestVersion:  latest.Version,
+		Status:         "up to date",
+	}
+	newer := utils.CompareVersions(latest.Version, utils.VERSION) > 0
+	if newer {
+		status.Status = "available"
+	}
+	// the latest version is signed, so older ones are never installed, even when forced
+	older := utils.CompareVersions(latest.Version, utils.VERSION) < 0
+	if older && c.Bool("force") {
+		formatter.PrintFatal("Couldn't install release", fmt.Errorf("Latest release %s is older than the running %s", latest.Version, utils.VERSION))
+	}
+
+	if !c.Bool("check-only") && (newer || c.Bool("force")) {
+		path, err := release.Executable()
+		if err != nil {
+			formatter.PrintFatal("Couldn't find running binary", err)
+		}
+		binary, err := latest.Download()
+		if err != nil {
+			formatter.PrintFatal("Couldn't download release", err)
+		}
+		if err = release.Install(path, binary); err != nil {
+			formatter.PrintFatal("Couldn't install release", err)
+		}
+		log.Infof("Updated %s to %s. Previous binary kept as %s.bak", path, latest.Version, path)
+		status.Status = "updated"
+		status.Binary = path
+	}
+
+	if err = formatter.PrintItem(status); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
@@ -3,29 +3,15 @@ package cmd
 import (
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/cloud"
-	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/flags"
 	"github.com/flexiant/concerto/utils/format"
 )
 
 // WireUpServerPlan prepares common resources to send request to Concerto API
 func WireUpServerPlan(c *cli.Context) (ds *cloud.ServerPlanService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ds, err = cloud.NewServerPlanService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up serverPlan service", err)
-	}
-
-	return ds, f
+	return wireUpClient(f).ServerPlans, f
 }
 
 // ServerPlanList subcommand function
@@ -33,11 +19,18 @@ func ServerPlanList(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	serverPlanSvc, formatter := WireUpServerPlan(c)
 
-	serverPlans, err := serverPlanSvc.GetServerPlanList(c.String("cloud_provider_id"))
+	validateFlags(c, flags.New(c).Required("cloud_provider_id"), formatter)
+	var serverPlans []types.ServerPlan
+	var err error
+	if c.IsSet("location") {
+		serverPlans, err = serverPlanSvc.GetServerPlanListByLocation(c.String("cloud_provider_id"), c.String("location"))
+	} else {
+		serverPlans, err = serverPlanSvc.GetServerPlanList(c.String("cloud_provider_id"))
+	}
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive serverPlan data", err)
 	}
-	if err = formatter.PrintList(serverPlans); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, serverPlans)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/blueprint"
+	"github.com/flexiant/concerto/api/cloud"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/manifest"
+	"github.com/flexiant/concerto/utils/pool"
+)
+
+// serverCreateResult reports the outcome of creating a server of a manifest
+type serverCreateResult struct {
+	Name     string `json:"name" header:"NAME"`
+	ID       string `json:"id" header:"ID"`
+	State    string `json:"state" header:"STATE"`
+	Status   string `json:"status" header:"STATUS"`
+	Attempts int    `json:"attempts" header:"ATTEMPTS"`
+	Error    string `json:"error,omitempty" header:"ERROR"`
+}
+
+// serverBulkCreate creates every server defined in the manifest file in parallel.
+// The whole file is validated, and its references resolved, before any server is created
+func serverBulkCreate(c *cli.Context, serverSvc *cloud.ServerService, formatter format.Formatter) {
+	timeout := serverWaitTimeout(c, formatter)
+
+	defs, err := manifest.LoadServers(c.String("file"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't read server manifest", err)
+	}
+	params, err := resolveServerDefinitions(wireUpServerReferences(c), defs, nil)
+	if err != nil {
+		formatter.PrintFatal("Couldn't validate server manifest", err)
+	}
+
+	// servers created or booted by a failed attempt aren't created or booted again when retrying. A failed
+	// create request may still have created the server, so it's looked up by name among the servers that
+	// didn't exist before creating it again
+	servers, err := serverSvc.GetServerList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive server data", err)
+	}
+	existing := make(map[string]bool)
+	for _, s := range servers {
+		existing[s.Id] = true
+	}
+	var mu sync.Mutex
+	created := make(map[string]*types.Server)
+	attempted := make(map[string]bool)
+	booted := make(map[string]bool)
+	names := make([]string, len(defs))
+	for i, def := range defs {
+		names[i] = def.Name
+	}
+
+	results := pool.Run(names, func(name string) error {
+		mu.Lock()
+		server := created[name]
+		retry := attempted[name]
+		attempted[name] = true
+		mu.Unlock()
+
+		if server == nil && retry {
+			s, err := newServerNamed(serverSvc, name, existing)
+			if err != nil {
+				return err
+			}
+			if s != nil {
+				log.Infof("Server %s was created by a failed attempt with Id %s", s.Name, s.Id)
+				mu.Lock()
+				created[name] = s
+				mu.Unlock()
+				server = s
+			}
+		}
+		if server == nil {
+			s, err := serverSvc.CreateServer(params[name])
+			if err != nil {
+				return err
+			}
+			log.Infof("Server %s created with Id %s", s.Name, s.Id)
+			mu.Lock()
+			created[name] = s
+			mu.Unlock()
+			server = s
+		}
+		if !c.Bool("wait") {
+			return nil
+		}
+
+		mu.Lock()
+		boot := !booted[name]
+		mu.Unlock()
+		if boot {
+			if _, err := serverSvc.BootServer(&map[string]interface{}{}, server.Id); err != nil {
+				return err
+			}
+			mu.Lock()
+			booted[name] = true
+			mu.Unlock()
+		}
+		log.Infof("Booting server %s. Waiting till it gets operational", server.Name)
+		s, err := waitServer(serverSvc, server.Id, serverBooted, timeout)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		created[name] = s
+		mu.Unlock()
+		return nil
+	})
+
+	report := make([]serverCreateResult, len(results))
+	for i, r := range results {
+		report[i] = serverCreateResult{Name: r.Item, Status: r.Status, Attempts: r.Attempts, Error: r.Error}
+		if server := created[r.Item]; server != nil {
+			report[i].ID = server.Id
+			report[i].State = server.State
+		}
+	}
+	if err = formatter.PrintList(report); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	if failed := pool.Failed(results); failed > 0 {
+		formatter.PrintFatal("Bulk operation didn't complete", fmt.Errorf("%d of %d servers failed", failed, len(results)))
+	}
+}
+
+// newServerNamed returns the server with the given name that isn't one of the existing ones, or nil when
+// there's none
+func newServerNamed(serverSvc *cloud.ServerService, name string, existing map[string]bool) (*types.Server, error) {
+	servers, err := serverSvc.GetServerList()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range servers {
+		if s.Name == name && !existing[s.Id] {
+			server := s
+			return &server, nil
+		}
+	}
+	return nil, nil
+}
+
+// serverReferences are the services resources referenced by servers are looked up with
+type serverReferences struct {
+	templates   *blueprint.TemplateService
+	workspaces  *cloud.WorkspaceService
+	sshProfiles *cloud.SSHProfileService
+	serverPlans *cloud.ServerPlanService
+}
+
+func wireUpServerReferences(c *cli.Context) *serverReferences {
+	templateSvc, _ := WireUpTemplate(c)
+	workspaceSvc, _ := WireUpWorkspace(c)
+	sshProfileSvc, _ := WireUpSSHProfile(c)
+	serverPlanSvc, _ := WireUpServerPlan(c)
+	return &serverReferences{templates: templateSvc, workspaces: workspaceSvc, sshProfiles: sshProfileSvc, serverPlans: serverPlanSvc}
+}
+
+// referenceIDs holds the IDs of resources by their ID and by their name, which several of them may share
+type referenceIDs map[string][]string
+
+func (r referenceIDs) add(id string, name string) {
+	r[id] = append(r[id], id)
+	if name != "" && name != id {
+		r[name] = append(r[name], id)
+	}
+}
+
+// resolve returns the ID of the resource given by its ID or by its name, logging the ID names resolve to,
+// and failing when the name is shared by several resources
+func (r referenceIDs) resolve(what string, ref string) (string, error) {
+	ids := r[ref]
+	for _, id := range ids {
+		if id == ref {
+			return id, nil
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("%s %s not found", what, ref)
+	case 1:
+		log.Infof("Using %s %s, whose ID is %s", what, ref, ids[0])
+		return ids[0], nil
+	}
+	return "", fmt.Errorf("%s %s is ambiguous, as it's the name of %s", what, ref, strings.Join(ids, ", "))
+}
+
+// resolveServerDefinitions translates names of templates, workspaces and SSH profiles into IDs, and checks that
+// server plans exist. Every problem found is reported, as a validation error. Returns the creation parameters of
+// each server by name. Templates in pending are taken as existing with the ID given, so that those yet to be
+// created can be used
+func resolveServerDefinitions(refs *serverReferences, defs []manifest.ServerDefinition, pending map[string]string) (map[string]*map[string]interface{}, error) {
+	templates, err := refs.templates.GetTemplateList()
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't receive template data: %s", err)
+	}
+	templateIDs := make(referenceIDs)
+	for _, t := range templates {
+		templateIDs.add(t.ID, t.Name)
+	}
+	for name, id := range pending {
+		templateIDs[name] = []string{id}
+	}
+
+	workspaces, err := refs.workspaces.GetWorkspaceList()
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't receive workspace data: %s", err)
+	}
+	workspaceIDs := make(referenceIDs)
+	for _, w := range workspaces {
+		workspaceIDs.add(w.Id, w.Name)
+	}
+
+	sshProfileIDs := make(referenceIDs)
+	for _, def := range defs {
+		if def.SSHProfile != "" {
+			sshProfiles, err := refs.sshProfiles.GetSSHProfileList()
+			if err != nil {
+				return nil, fmt.Errorf("Couldn't receive ssh profile data: %s", err)
+			}
+			for _, p := range sshProfiles {
+				sshProfileIDs.add(p.Id, p.Name)
+			}
+			break
+		}
+	}
+
+	plans := make(map[string]error)
+	var problems []string
+	// definitions given as flags have no line
+	problem := func(def manifest.ServerDefinition, format string, a ...interface{}) {
+		if def.Line > 0 {
+			format = fmt.Sprintf("line %d: %s", def.Line, format)
+		}
+		problems = append(problems, fmt.Sprintf(format, a...))
+	}
+	params := make(map[string]*map[string]interface{})
+	for _, def := range defs {
+		p := map[string]interface{}{
+			"name": def.Name,
+			"fqdn": def.Fqdn,
+		}
+
+		if id, err := templateIDs.resolve("template", def.Template); err == nil {
+			p["template_id"] = id
+		} else {
+			problem(def, "%s", err)
+		}
+		if id, err := workspaceIDs.resolve("workspace", def.Workspace); err == nil {
+			p["workspace_id"] = id
+		} else {
+			problem(def, "%s", err)
+		}
+		if def.SSHProfile != "" {
+			if id, err := sshProfileIDs.resolve("ssh profile", def.SSHProfile); err == nil {
+				p["ssh_profile_id"] = id
+			} else {
+				problem(def, "%s", err)
+			}
+		}
+
+		perr, checked := plans[def.Plan]
+		if !checked {
+			_, perr = refs.serverPlans.GetServerPlan(def.Plan)
+			plans[def.Plan] = perr
+		}
+		if perr != nil {
+			problem(def, "server plan %s not found: %s", def.Plan, perr)
+		} else {
+			p["server_plan_id"] = def.Plan
+		}
+
+		if len(def.Labels) > 0 {
+			p["labels"] = def.Labels
+		}
+		params[def.Name] = &p
+	}
+
+	if len(problems) > 0 {
+		return nil, exit.NewValidationError(fmt.Errorf("Invalid server definitions:\n\t%s", strings.Join(problems, "\n\t")))
+	}
+	return params, nil
+}
@@ -1,31 +1,23 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/cloud"
-	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/flags"
 	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/manifest"
+	"github.com/flexiant/concerto/utils/wait"
 )
 
 // WireUpServer prepares common resources to send request to Concerto API
 func WireUpServer(c *cli.Context) (ds *cloud.ServerService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ds, err = cloud.NewServerService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up server service", err)
-	}
-
-	return ds, f
+	return wireUpClient(f).Servers, f
 }
 
 // ServerList subcommand function
@@ -33,13 +25,9 @@ func ServerList(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	serverSvc, formatter := WireUpServer(c)
 
-	servers, err := serverSvc.GetServerList()
-	if err != nil {
-		formatter.PrintFatal("Couldn't receive server data", err)
-	}
-	if err = formatter.PrintList(servers); err != nil {
-		formatter.PrintFatal("Couldn't print/format result", err)
-	}
+	printWatchedList(c, formatter, "Couldn't receive server data", func() (interface{}, error) {
+		return serverSvc.GetServerList()
+	})
 	return nil
 }
 
@@ -64,11 +52,35 @@ func ServerCreate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	serverSvc, formatter := WireUpServer(c)
 
+	if c.IsSet("file") {
+		serverBulkCreate(c, serverSvc, formatter)
+		return nil
+	}
+
 	checkRequiredFlags(c, []string{"name", "fqdn", "workspace_id", "template_id", "server_plan_id"}, formatter)
-	server, err := serverSvc.CreateServer(utils.FlagConvertParams(c))
+	params := serverFlagParams(c, formatter)
+	// referenced resources are looked up first, so that mistyped IDs don't get as far as the API
+	def := manifest.ServerDefinition{
+		Name:       c.String("name"),
+		Fqdn:       c.String("fqdn"),
+		Template:   c.String("template_id"),
+		Plan:       c.String("server_plan_id"),
+		Workspace:  c.String("workspace_id"),
+		SSHProfile: c.String("ssh_profile_id"),
+	}
+	resolved, err := resolveServerDefinitions(wireUpServerReferences(c), []manifest.ServerDefinition{def}, nil)
 	if err != nil {
 		formatter.PrintFatal("Couldn't create server", err)
 	}
+	for k, v := range *resolved[def.Name] {
+		(*params)[k] = v
+	}
+
+	server, err := serverSvc.CreateServer(params)
+	if err != nil {
+		formatter.PrintFatal("Couldn't create server", err)
+	}
+	server = waitServerIfAsked(c, serverSvc, server, serverCreated, formatter)
 	if err = formatter.PrintItem(*server); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
@@ -80,8 +92,8 @@ func ServerUpdate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	serverSvc, formatter := WireUpServer(c)
 
-	checkRequiredFlags(c, []string{"id"}, formatter)
-	server, err := serverSvc.UpdateServer(utils.FlagConvertParams(c), c.String("id"))
+	validateFlags(c, flags.New(c).Required("id").AnyOf("name", "fqdn"), formatter)
+	server, err := serverSvc.UpdateServer(flagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't update server", err)
 	}
@@ -91,16 +103,42 @@ func ServerUpdate(c *cli.Context) error {
 	return nil
 }
 
+// ServerRename subcommand function
+func ServerRename(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	serverSvc, formatter := WireUpServer(c)
+
+	validateFlags(c, flags.New(c).Required("id", "name"), formatter)
+	server, err := renameServer(serverSvc, c.String("id"), c.String("name"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't rename server", err)
+	}
+	if err = formatter.PrintItem(*server); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// renameServer updates just the name of the server, which can't be blank
+func renameServer(serverSvc *cloud.ServerService, id string, name string) (*types.Server, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, exit.NewValidationError(fmt.Errorf("Server name can't be blank"))
+	}
+	return serverSvc.UpdateServer(&map[string]interface{}{"name": name}, id)
+}
+
 // ServerBoot subcommand function
 func ServerBoot(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	serverSvc, formatter := WireUpServer(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	server, err := serverSvc.BootServer(utils.FlagConvertParams(c), c.String("id"))
+	server, err := serverSvc.BootServer(serverFlagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't boot server", err)
 	}
+	server = waitServerIfAsked(c, serverSvc, server, serverBooted, formatter)
 	if err = formatter.PrintItem(*server); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
@@ -113,26 +151,37 @@ func ServerReboot(c *cli.Context) error {
 	serverSvc, formatter := WireUpServer(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	server, err := serverSvc.RebootServer(utils.FlagConvertParams(c), c.String("id"))
+	server, err := serverSvc.RebootServer(serverFlagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't reboot server", err)
 	}
+	server = waitServerIfAsked(c, serverSvc, server, rebootTarget(server), formatter)
 	if err = formatter.PrintItem(*server); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
 }
 
+// rebootTarget returns what a reboot waits for. Servers already rebooting when it's accepted are done once
+// operational, while those still operational have to leave that state first
+func rebootTarget(server *types.Server) wait.Target {
+	if server.State == "operational" {
+		return serverRebooted
+	}
+	return serverBooted
+}
+
 // ServerShutdown subcommand function
 func ServerShutdown(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	serverSvc, formatter := WireUpServer(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	server, err := serverSvc.ShutdownServer(utils.FlagConvertParams(c), c.String("id"))
+	server, err := serverSvc.ShutdownServer(serverFlagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't shutdown server", err)
 	}
+	server = waitServerIfAsked(c, serverSvc, server, serverShutDown, formatter)
 	if err = formatter.PrintItem(*server); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
@@ -145,10 +194,11 @@ func ServerOverride(c *cli.Context) error {
 	serverSvc, formatter := WireUpServer(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	server, err := serverSvc.OverrideServer(utils.FlagConvertParams(c), c.String("id"))
+	server, err := serverSvc.OverrideServer(serverFlagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't override server", err)
 	}
+	server = waitServerIfAsked(c, serverSvc, server, serverBooted, formatter)
 	if err = formatter.PrintItem(*server); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
@@ -161,10 +211,14 @@ func ServerDelete(c *cli.Context) error {
 	serverSvc, formatter := WireUpServer(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := serverSvc.DeleteServer(c.String("id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't delete server", err)
-	}
+	timeout := serverWaitTimeout(c, formatter)
+	deleteByID(c, "id", "server", func(id string) error {
+		if err := serverSvc.DeleteServer(id); err != nil || !c.Bool("wait") {
+			return err
+		}
+		_, err := waitServer(serverSvc, id, serverDeleted, timeout)
+		return err
+	}, formatter)
 	return nil
 }
 
@@ -179,7 +233,7 @@ func DNSList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive dns data", err)
 	}
-	if err = formatter.PrintList(dnss); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, dnss)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -197,7 +251,7 @@ func EventsList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive event data", err)
 	}
-	if err = formatter.PrintList(events); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, events)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -215,7 +269,7 @@ func OperationalScriptsList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive script data", err)
 	}
-	if err = formatter.PrintList(scripts); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, scripts)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -227,7 +281,7 @@ func OperationalScriptExecute(c *cli.Context) error {
 	serverSvc, formatter := WireUpServer(c)
 
 	checkRequiredFlags(c, []string{"server_id", "script_id"}, formatter)
-	server, err := serverSvc.ExecuteOperationalScript(utils.FlagConvertParams(c), c.String("server_id"), c.String("script_id"))
+	server, err := serverSvc.ExecuteOperationalScript(flagParams(c, formatter), c.String("server_id"), c.String("script_id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't execute operational script", err)
 	}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/flexiant/concerto/api/blueprint"
+	"github.com/flexiant/concerto/api/cloud"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebootTarget(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(rebootTarget(&types.Server{State: "operational"}).Restart, "Operational servers should leave that state before the reboot is done")
+	assert.False(rebootTarget(&types.Server{State: "rebooting"}).Restart, "Rebooting servers should be done once operational")
+}
+
+// mockedServerReferences returns the services to look up server references with, answering with the given resources
+func mockedServerReferences(t *testing.T, templates []types.Template, workspaces []types.Workspace, sshProfiles []types.SSHProfile) (*serverReferences, *utils.MockConcertoService) {
+	assert := assert.New(t)
+
+	cs := &utils.MockConcertoService{}
+	templateSvc, err := blueprint.NewTemplateService(cs)
+	assert.Nil(err, "Couldn't load template service")
+	workspaceSvc, err := cloud.NewWorkspaceService(cs)
+	assert.Nil(err, "Couldn't load workspace service")
+	sshProfileSvc, err := cloud.NewSSHProfileService(cs)
+	assert.Nil(err, "Couldn't load ssh profile service")
+	serverPlanSvc, err := cloud.NewServerPlanService(cs)
+	assert.Nil(err, "Couldn't load server plan service")
+
+	for path, v := range map[string]interface{}{
+		"/v1/blueprint/templates":  templates,
+		"/v1/cloud/workspaces":     workspaces,
+		"/v1/cloud/ssh_profiles":   sshProfiles,
+		"/v1/cloud/server_plans/p": types.ServerPlan{Id: "p"},
+	} {
+		data, err := json.Marshal(v)
+		assert.Nil(err, "Server references test data corrupted")
+		cs.On("Get", path).Return(data, 200, nil)
+	}
+	cs.On("Get", "/v1/cloud/server_plans/missing").Return([]byte(`{}`), 404, nil)
+
+	return &serverReferences{templates: templateSvc, workspaces: workspaceSvc, sshProfiles: sshProfileSvc, serverPlans: serverPlanSvc}, cs
+}
+
+func TestResolveServerDefinitions(t *testing.T) {
+	assert := assert.New(t)
+
+	refs, _ := mockedServerReferences(t,
+		[]types.Template{{ID: "t1", Name: "web"}, {ID: "t2", Name: "db"}, {ID: "t3", Name: "db"}, {ID: "t4", Name: "t1"}},
+		[]types.Workspace{{Id: "w1", Name: "prod"}},
+		[]types.SSHProfile{{Id: "k1", Name: "admins"}},
+	)
+
+	def := manifest.ServerDefinition{Name: "web1", Fqdn: "web1.example.com", Template: "web", Plan: "p", Workspace: "prod", SSHProfile: "admins"}
+	params, err := resolveServerDefinitions(refs, []manifest.ServerDefinition{def}, nil)
+	assert.Nil(err, "Names of existing resources should be resolved")
+	assert.Equal(map[string]interface{}{
+		"name":           "web1",
+		"fqdn":           "web1.example.com",
+		"template_id":    "t1",
+		"workspace_id":   "w1",
+		"ssh_profile_id": "k1",
+		"server_plan_id": "p",
+	}, *params["web1"], "Names should be replaced by IDs")
+
+	def = manifest.ServerDefinition{Name: "web1", Template: "t1", Plan: "p", Workspace: "w1"}
+	params, err = resolveServerDefinitions(refs, []manifest.ServerDefinition{def}, nil)
+	assert.Nil(err, "IDs of existing resources should be valid")
+	assert.Equal("t1", (*params["web1"])["template_id"], "An ID should win over a name equal to it")
+	assert.NotContains(*params["web1"], "ssh_profile_id", "No ssh profile should be sent unless given")
+
+	def = manifest.ServerDefinition{Name: "web1", Template: "web2", Plan: "p", Workspace: "w1"}
+	params, err = resolveServerDefinitions(refs, []manifest.ServerDefinition{def}, map[string]string{"web2": "t5"})
+	assert.Nil(err, "Pending templates should be valid")
+	assert.Equal("t5", (*params["web1"])["template_id"], "Pending templates should resolve to the ID given")
+}
+
+func TestResolveServerDefinitionsProblems(t *testing.T) {
+	assert := assert.New(t)
+
+	refs, _ := mockedServerReferences(t,
+		[]types.Template{{ID: "t2", Name: "db"}, {ID: "t3", Name: "db"}},
+		[]types.Workspace{{Id: "w1", Name: "prod"}},
+		[]types.SSHProfile{},
+	)
+
+	def := manifest.ServerDefinition{Name: "db1", Template: "db", Plan: "missing", Workspace: "staging", SSHProfile: "admins"}
+	_, err := resolveServerDefinitions(refs, []manifest.ServerDefinition{def}, nil)
+	assert.NotNil(err, "Unknown references should be rejected")
+	assert.Equal(exit.Validation, exit.Code(err), "Unknown references should be validation errors")
+	assert.Contains(err.Error(), "template db is ambiguous, as it's the name of t2, t3", "Shared names should be reported")
+	assert.Contains(err.Error(), "workspace staging not found", "Missing workspaces should be reported")
+	assert.Contains(err.Error(), "ssh profile admins not found", "Missing ssh profiles should be reported")
+	assert.Contains(err.Error(), "server plan missing not found", "Missing server plans should be reported")
+}
+
+func TestRenameServer(t *testing.T) {
+	assert := assert.New(t)
+
+	cs := &utils.MockConcertoService{}
+	serverSvc, err := cloud.NewServerService(cs)
+	assert.Nil(err, "Couldn't load server service")
+
+	in := map[string]interface{}{"name": "web2"}
+	data, err := json.Marshal(types.Server{Id: "s1", Name: "web2"})
+	assert.Nil(err, "Server test data corrupted")
+	cs.On("Put", "/v1/cloud/servers/s1", &in).Return(data, 200, nil)
+
+	server, err := renameServer(serverSvc, "s1", " web2 ")
+	assert.Nil(err, "Renaming a server shouldn't fail")
+	assert.Equal("web2", server.Name, "The renamed server should be returned")
+	cs.AssertCalled(t, "Put", "/v1/cloud/servers/s1", &in)
+
+	_, err = renameServer(serverSvc, "s1", "  ")
+	assert.Equal(exit.Validation, exit.Code(err), "Blank names should be validation errors")
+	cs.AssertNumberOfCalls(t, "Put", 1)
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/shutdown"
+	"github.com/flexiant/concerto/utils/ssh"
+)
+
+// ServerSSH subcommand function
+func ServerSSH(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+
+	opts := serverSSHOptions(c, formatter)
+	runSSHClient("ssh", ssh.SSHArgs(opts, c.Args()), formatter)
+	return nil
+}
+
+// ServerExec subcommand function
+func ServerExec(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+
+	if len(c.Args()) == 0 {
+		formatter.PrintFatal("Incorrect usage", fmt.Errorf("Please, provide the command to execute after --"))
+	}
+	opts := serverSSHOptions(c, formatter)
+	opts.BatchMode = true
+	runSSHClient("ssh", ssh.SSHArgs(opts, c.Args()), formatter)
+	return nil
+}
+
+// ServerSCP subcommand function
+func ServerSCP(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+
+	if len(c.Args()) < 2 {
+		formatter.PrintFatal("Incorrect usage", fmt.Errorf("Please, provide source and destination paths. Prefix server paths with a colon"))
+	}
+	opts := serverSSHOptions(c, formatter)
+	opts.BatchMode = true
+	runSSHClient("scp", ssh.SCPArgs(opts, c.Args()), formatter)
+	return nil
+}
+
+// serverSSHOptions resolves how the server is reached: its address, bastion and credentials
+func serverSSHOptions(c *cli.Context, f format.Formatter) *ssh.Options {
+	idFlag := serverIDFlag(c)
+	checkRequiredFlags(c, []string{idFlag}, f)
+
+	config, err := utils.GetConcertoConfig()
+	if err != nil {
+		f.PrintFatal("Couldn't wire up config", err)
+	}
+
+	serverSvc, _ := WireUpServer(c)
+	server, err := serverSvc.GetServer(c.String(idFlag))
+	if err != nil {
+		f.PrintFatal("Couldn't receive server data", err)
+	}
+	if server.State != "operational" {
+		log.Warnf("Server %s is %s", server.Name, server.State)
+	}
+
+	opts := &ssh.Options{
+		User:                  c.String("user"),
+		Host:                  server.Public_ip,
+		Bastion:               c.String("bastion"),
+		KnownHostsFile:        c.String("known_hosts"),
+		StrictHostKeyChecking: c.Bool("strict_host_key_checking"),
+	}
+	if opts.Host == "" {
+		opts.Host = server.Fqdn
+	}
+	if opts.Bastion == "" {
+		opts.Bastion = config.BastionFor(server.Workspace_id)
+	}
+	if opts.KnownHostsFile == "" {
+		opts.KnownHostsFile = filepath.Join(config.ConfLocation, "known_hosts")
+	}
+	if opts.Bastion != "" {
+		log.Debugf("Reaching server %s through %s", server.Name, opts.Bastion)
+	}
+
+	if c.Bool("agent") {
+		if err = ssh.CheckAgent(); err != nil {
+			f.PrintFatal("Couldn't use ssh-agent", err)
+		}
+		return opts
+	}
+
+	if server.Ssh_profile_id == "" {
+		f.PrintFatal("Couldn't find server credentials", fmt.Errorf("Server %s has no SSH profile. Please, use --agent", server.Name))
+	}
+	sshProfileSvc, _ := WireUpSSHProfile(c)
+	profile, err := sshProfileSvc.GetSSHProfile(server.Ssh_profile_id)
+	if err != nil {
+		f.PrintFatal("Couldn't receive ssh profile data", err)
+	}
+	opts.IdentityFile, err = ssh.WriteIdentity(filepath.Join(config.ConfLocation, "ssh"), profile.Id, profile.Private_key)
+	if err != nil {
+		f.PrintFatal("Couldn't write ssh profile key", err)
+	}
+	return opts
+}
+
+// serverIDFlag returns the flag identifying the server: server_id for the top level ssh command, and id
+// for servers subcommands
+func serverIDFlag(c *cli.Context) string {
+	for _, flag := range c.Command.Flags {
+		if flag.GetName() == "server_id" {
+			return "server_id"
+		}
+	}
+	return "id"
+}
+
+// runSSHClient runs an OpenSSH client attached to the terminal, exiting with its exit code
+func runSSHClient(name string, args []string, f format.Formatter) {
+	bin, err := exec.LookPath(name)
+	if err != nil {
+		f.PrintFatal(fmt.Sprintf("Couldn't find %s in your environment. Please, install OpenSSH client", name), err)
+	}
+
+	log.Debugf("Going to execute %s %s", bin, args)
+	cmd := exec.Command(bin, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			shutdown.Exit(exitErr.Sys().(syscall.WaitStatus).ExitStatus())
+		}
+		f.PrintFatal(fmt.Sprintf("Couldn't run %s", name), err)
+	}
+}
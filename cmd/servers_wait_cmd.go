@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/cloud"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/wait"
+)
+
+// serverPollInterval is the time between server state checks
+var serverPollInterval = 10 * time.Second
+
+// serverDecommissioned is the state of servers which no longer exist
+const serverDecommissioned = "decommissioned"
+
+// Terminal states of the asynchronous operations on servers
+var (
+	serverCreated = wait.Target{Done: []string{"inactive"}, Failed: []string{"commission_stalled", "error"}}
+	serverBooted  = wait.Target{Done: []string{"operational"}, Failed: []string{"stalled", "error"}}
+	// serverRebooted waits for servers still operational when the reboot is accepted to leave that state
+	serverRebooted = wait.Target{Done: []string{"operational"}, Failed: []string{"stalled", "error"}, Restart: true}
+	serverShutDown = wait.Target{Done: []string{"inactive"}, Failed: []string{"stalled", "error"}}
+	serverDeleted  = wait.Target{Done: []string{serverDecommissioned}, Failed: []string{"decommission_stalled", "error"}}
+)
+
+// waitServer polls a server till it reaches a terminal state of target, logging its progress
+func waitServer(serverSvc *cloud.ServerService, ID string, target wait.Target, timeout time.Duration) (*types.Server, error) {
+	var server *types.Server
+	poller := &wait.Poller{
+		Interval: serverPollInterval,
+		Timeout:  timeout,
+		Progress: func(state string) { log.Infof("Server %s is %s", ID, state) },
+	}
+	_, err := poller.Until(fmt.Sprintf("Server %s", ID), func() (string, error) {
+		s, err := serverSvc.GetServer(ID)
+		if e, ok := err.(*utils.HTTPError); ok && e.Status == 404 {
+			return serverDecommissioned, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		server = s
+		return s.State, nil
+	}, target)
+	// waiting again wouldn't help, so bulk operations don't retry it
+	return server, utils.Final(err)
+}
+
+// serverWaitTimeout returns the --timeout of commands given --wait
+func serverWaitTimeout(c *cli.Context, f format.Formatter) time.Duration {
+	if !c.Bool("wait") {
+		return 0
+	}
+	timeout, err := time.ParseDuration(c.String("timeout"))
+	if err != nil {
+		f.PrintFatal("Incorrect usage.", exit.NewValidationError(fmt.Errorf("Invalid timeout %s", c.String("timeout"))))
+	}
+	return timeout
+}
+
+// waitServerIfAsked waits, when --wait is given, till server reaches a terminal state of target, returning it then
+func waitServerIfAsked(c *cli.Context, serverSvc *cloud.ServerService, server *types.Server, target wait.Target, f format.Formatter) *types.Server {
+	if !c.Bool("wait") {
+		return server
+	}
+	s, err := waitServer(serverSvc, server.Id, target, serverWaitTimeout(c, f))
+	if err != nil {
+		f.PrintFatal(fmt.Sprintf("Couldn't wait for server %s", server.Name), err)
+	}
+	return s
+}
+
+// serverFlagParams returns the parameters given as flags, without those telling how to wait for the server
+func serverFlagParams(c *cli.Context, f format.Formatter) *map[string]interface{} {
+	params := flagParams(c, f)
+	delete(*params, "wait")
+	delete(*params, "timeout")
+	return params
+}
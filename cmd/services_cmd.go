@@ -1,31 +1,36 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/blueprint"
-	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/attributes"
+	"github.com/flexiant/concerto/utils/flags"
 	"github.com/flexiant/concerto/utils/format"
 )
 
+// serviceRecipe is a recipe of a service, as given in the service list of templates
+type serviceRecipe struct {
+	Service string `json:"service" header:"SERVICE"`
+	Recipe  string `json:"recipe" header:"RECIPE"`
+	Public  bool   `json:"public" header:"PUBLIC"`
+}
+
+// serviceAttribute is a configuration attribute honored by a service, by its path in the configuration
+// attributes of templates
+type serviceAttribute struct {
+	Path        string `json:"path" header:"PATH"`
+	Default     string `json:"default" header:"DEFAULT"`
+	Description string `json:"description" header:"DESCRIPTION"`
+}
+
 // WireUpService prepares common resources to send request to Concerto API
 func WireUpService(c *cli.Context) (sv *blueprint.ServicesService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	sv, err = blueprint.NewServicesService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up service service", err)
-	}
-
-	return sv, f
+	return wireUpClient(f).Services, f
 }
 
 // ServiceList subcommand function
@@ -37,7 +42,12 @@ func ServiceList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive service data", err)
 	}
-	if err = formatter.PrintList(services); err != nil {
+	if c.Bool("recipes") {
+		err = formatter.PrintList(filterList(c, formatter, serviceRecipes(services)))
+	} else {
+		err = formatter.PrintList(filterList(c, formatter, services))
+	}
+	if err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -48,13 +58,70 @@ func ServiceShow(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	serviceSvc, formatter := WireUpService(c)
 
-	checkRequiredFlags(c, []string{"id"}, formatter)
-	service, err := serviceSvc.GetService(c.String("id"))
+	validateFlags(c, flags.New(c).AnyOf("id", "name").Exclusive("id", "name"), formatter)
+	var service *types.Service
+	var err error
+	if c.IsSet("name") {
+		service, err = serviceSvc.GetServiceByName(c.String("name"))
+	} else {
+		service, err = serviceSvc.GetService(c.String("id"))
+	}
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive service data", err)
 	}
-	if err = formatter.PrintItem(*service); err != nil {
+	if c.Bool("attributes") {
+		attrs, err := serviceAttributes(service.Attributes)
+		if err != nil {
+			formatter.PrintFatal("Couldn't read service attributes", err)
+		}
+		err = formatter.PrintList(attrs)
+	} else {
+		err = formatter.PrintItem(*service)
+	}
+	if err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
 }
+
+// serviceRecipes returns the recipes of services, or the service itself when it doesn't list them
+func serviceRecipes(services []types.Service) []serviceRecipe {
+	recipes := []serviceRecipe{}
+	for _, s := range services {
+		if len(s.Recipes) == 0 {
+			recipes = append(recipes, serviceRecipe{Service: s.Name, Recipe: s.Name, Public: s.Public})
+		}
+		for _, r := range s.Recipes {
+			recipes = append(recipes, serviceRecipe{Service: s.Name, Recipe: r, Public: s.Public})
+		}
+	}
+	return recipes
+}
+
+// serviceAttributes returns the attributes of a service sorted by path, with their defaults as text
+func serviceAttributes(raw *json.RawMessage) ([]serviceAttribute, error) {
+	attrs := []serviceAttribute{}
+	if raw == nil {
+		return attrs, nil
+	}
+	var given map[string]interface{}
+	if err := json.Unmarshal(*raw, &given); err != nil {
+		return nil, fmt.Errorf("Attributes must be a JSON object. %s", err)
+	}
+	for _, d := range attributes.Definitions(given) {
+		attrs = append(attrs, serviceAttribute{Path: d.Path, Default: attributeValue(d.Default), Description: d.Description})
+	}
+	return attrs, nil
+}
+
+// attributeValue returns strings as they are, and other values as JSON
+func attributeValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if value == nil {
+		return ""
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
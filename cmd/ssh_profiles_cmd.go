@@ -1,31 +1,19 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/cloud"
-	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/exit"
 	"github.com/flexiant/concerto/utils/format"
 )
 
 // WireUpSSHProfile prepares common resources to send request to Concerto API
 func WireUpSSHProfile(c *cli.Context) (ds *cloud.SSHProfileService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ds, err = cloud.NewSSHProfileService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up sshProfile service", err)
-	}
-
-	return ds, f
+	return wireUpClient(f).SSHProfiles, f
 }
 
 // SSHProfileList subcommand function
@@ -37,7 +25,7 @@ func SSHProfileList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive sshProfile data", err)
 	}
-	if err = formatter.PrintList(sshProfiles); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, sshProfiles)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -65,7 +53,7 @@ func SSHProfileCreate(c *cli.Context) error {
 	sshProfileSvc, formatter := WireUpSSHProfile(c)
 
 	checkRequiredFlags(c, []string{"name", "public_key"}, formatter)
-	sshProfile, err := sshProfileSvc.CreateSSHProfile(utils.FlagConvertParams(c))
+	sshProfile, err := sshProfileSvc.CreateSSHProfile(sshProfileParams(c, formatter))
 	if err != nil {
 		formatter.PrintFatal("Couldn't create sshProfile", err)
 	}
@@ -81,7 +69,7 @@ func SSHProfileUpdate(c *cli.Context) error {
 	sshProfileSvc, formatter := WireUpSSHProfile(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	sshProfile, err := sshProfileSvc.UpdateSSHProfile(utils.FlagConvertParams(c), c.String("id"))
+	sshProfile, err := sshProfileSvc.UpdateSSHProfile(sshProfileParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't update sshProfile", err)
 	}
@@ -91,15 +79,64 @@ func SSHProfileUpdate(c *cli.Context) error {
 	return nil
 }
 
+// sshProfileParams returns the parameters given as flags, with the keys given as @file, such as
+// @~/.ssh/id_rsa.pub, read from their files
+func sshProfileParams(c *cli.Context, f format.Formatter) *map[string]interface{} {
+	params := flagParams(c, f)
+	for _, flag := range []string{"public_key", "private_key"} {
+		value, ok := (*params)[flag].(string)
+		if !ok {
+			continue
+		}
+		private := flag == "private_key"
+		key, err := readSSHKey(value, private)
+		if err == nil {
+			err = checkSSHKey(key, private)
+		}
+		if err != nil {
+			f.PrintFatal(fmt.Sprintf("Invalid --%s", flag), exit.NewValidationError(err))
+		}
+		(*params)[flag] = key
+	}
+	return params
+}
+
+// readSSHKey returns a key given inline, read from a file prefixed with @, whose path may start with ~, or
+// read from standard input with @-. Private keys read keep a trailing newline, as OpenSSH rejects them without it
+func readSSHKey(value string, private bool) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return value, nil
+	}
+	data, err := readFlagValue(value)
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(data))
+	if private {
+		key += "\n"
+	}
+	return key, nil
+}
+
+// checkSSHKey makes sure public and private keys aren't swapped, as uploading a private key as the public
+// one would leak it
+func checkSSHKey(key string, private bool) error {
+	isPrivate := strings.Contains(key, "PRIVATE KEY-----")
+	if private && !isPrivate {
+		return fmt.Errorf("it isn't a PEM encoded private key")
+	}
+	if !private && isPrivate {
+		return fmt.Errorf("it's a private key. Give the public one, such as ~/.ssh/id_rsa.pub")
+	}
+	return nil
+}
+
 // SSHProfileDelete subcommand function
 func SSHProfileDelete(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	sshProfileSvc, formatter := WireUpSSHProfile(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := sshProfileSvc.DeleteSSHProfile(c.String("id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't delete sshProfile", err)
-	}
+	deleteByID(c, "id", "sshProfile", sshProfileSvc.DeleteSSHProfile, formatter)
 	return nil
 }
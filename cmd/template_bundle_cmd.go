@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/blueprint"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/manifest"
+	"github.com/flexiant/concerto/utils/shutdown"
+)
+
+// TemplateExport subcommand function. Writes a template, its script characterisations and the scripts
+// they run to a file that can be kept in version control and imported into another account
+func TemplateExport(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	templateSvc, formatter := WireUpTemplate(c)
+	scriptSvc, _ := WireUpScript(c)
+
+	checkRequiredFlags(c, []string{"id", "file"}, formatter)
+	bundle, err := templateBundle(c.String("id"), templateSvc, scriptSvc, formatter)
+	if err != nil {
+		formatter.PrintFatal("Couldn't export template", err)
+	}
+	err = writeExportFile(c.String("file"), func(w io.Writer) error {
+		return manifest.WriteBundle(w, bundle)
+	})
+	if err != nil {
+		formatter.PrintFatal("Couldn't write export file", err)
+	}
+
+	if err = formatter.PrintItem(ExportedFile{File: c.String("file"), Resources: 1 + len(bundle.Scripts)}); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// TemplateDiff subcommand function. Shows the fields of a template file, as written by export or in a blueprint
+// directory, which differ from the live template, so that they can be reviewed before updating it
+func TemplateDiff(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	templateSvc, formatter := WireUpTemplate(c)
+	scriptSvc, _ := WireUpScript(c)
+
+	checkRequiredFlags(c, []string{"id", "file"}, formatter)
+	local, err := manifest.LoadTemplate(c.String("file"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't read template file", err)
+	}
+	bundle, err := templateBundle(c.String("id"), templateSvc, scriptSvc, formatter)
+	if err != nil {
+		formatter.PrintFatal("Couldn't compare template", err)
+	}
+
+	if err = formatter.PrintList(manifest.DiffTemplate(local, &bundle.Template)); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// TemplateValidate subcommand function. Checks the services, configuration attributes and script parameter
+// values of a template file against the catalog before anything reaches the API, and fails with a validation
+// exit code when any of them is wrong
+func TemplateValidate(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	serviceSvc, formatter := WireUpService(c)
+	scriptSvc, _ := WireUpScript(c)
+
+	checkRequiredFlags(c, []string{"file"}, formatter)
+	bundle, err := manifest.LoadTemplateBundle(c.String("file"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't read template file", exit.NewValidationError(err))
+	}
+
+	catalog := &manifest.Catalog{}
+	if !c.Bool("offline") {
+		if catalog.Services, err = serviceSvc.GetServiceList(); err != nil {
+			formatter.PrintFatal("Couldn't receive service data", err)
+		}
+		scripts, err := scriptSvc.GetScriptList()
+		if err != nil {
+			formatter.PrintFatal("Couldn't receive script data", err)
+		}
+		catalog.Scripts = make(map[string][]string)
+		for _, s := range scripts {
+			catalog.Scripts[s.Name] = s.Parameters
+		}
+	}
+
+	issues := manifest.ValidateTemplate(&bundle.Template, bundle.Scripts, catalog)
+	if err = formatter.PrintList(issues); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	if manifest.HasErrors(issues) {
+		shutdown.Exit(exit.Validation)
+	}
+	return nil
+}
+
+// templateBundle receives a template, its script characterisations of every type and the scripts they run
+func templateBundle(id string, templateSvc *blueprint.TemplateService, scriptSvc *blueprint.ScriptService, formatter format.Formatter) (*manifest.Bundle, error) {
+	template, err := templateSvc.GetTemplate(id)
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive template data", err)
+	}
+
+	var templateScripts []types.TemplateScript
+	scripts := make(map[string]*types.Script)
+	for _, scriptType := range types.TemplateScriptTypes {
+		tss, err := templateSvc.GetTemplateScriptList(template.ID, scriptType)
+		if err != nil {
+			formatter.PrintFatal("Couldn't receive template script data", err)
+		}
+		for _, ts := range *tss {
+			if _, ok := scripts[ts.ScriptID]; !ok {
+				if scripts[ts.ScriptID], err = scriptSvc.GetScript(ts.ScriptID); err != nil {
+					formatter.PrintFatal("Couldn't receive script data", err)
+				}
+			}
+			templateScripts = append(templateScripts, ts)
+		}
+	}
+	return manifest.NewBundle(template, templateScripts, scripts)
+}
+
+// TemplateImport subcommand function. Creates or updates the template and scripts of an exported file,
+// matching them by name
+func TemplateImport(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	templateSvc, formatter := WireUpTemplate(c)
+	scriptSvc, _ := WireUpScript(c)
+
+	checkRequiredFlags(c, []string{"file"}, formatter)
+	m, err := manifest.LoadBundle(c.String("file"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't read template file", err)
+	}
+	if name := c.String("name"); name != "" {
+		m.Templates[0].Name = name
+	}
+
+	sync := &blueprintSync{
+		manifest:    m,
+		scriptSvc:   scriptSvc,
+		templateSvc: templateSvc,
+		formatter:   formatter,
+		dryRun:      c.Bool("dry-run"),
+	}
+	changes := sync.run()
+
+	if err = formatter.PrintList(changes); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// TemplateSyncScripts subcommand function. Creates, updates, removes and reorders the script characterisations
+// of a template to match the ones listed in a directory, creating or updating the scripts defined there first
+func TemplateSyncScripts(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	templateSvc, formatter := WireUpTemplate(c)
+	scriptSvc, _ := WireUpScript(c)
+
+	checkRequiredFlags(c, []string{"template_id", "dir"}, formatter)
+	template, err := templateSvc.GetTemplate(c.String("template_id"))
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive template data", err)
+	}
+	m, err := manifest.LoadTemplateScripts(c.String("dir"), template.Name)
+	if err != nil {
+		formatter.PrintFatal("Couldn't read template scripts", err)
+	}
+	scripts, err := scriptSvc.GetScriptList()
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive script data", err)
+	}
+
+	sync := &blueprintSync{
+		manifest:    m,
+		scriptSvc:   scriptSvc,
+		templateSvc: templateSvc,
+		formatter:   formatter,
+		dryRun:      c.Bool("dry-run"),
+	}
+	scriptIDs := make(map[string]string)
+	for _, sc := range scripts {
+		scriptIDs[sc.Name] = sc.ID
+	}
+	var changes []manifest.Change
+	for _, ch := range manifest.PlanScripts(m, scripts, false) {
+		ch.ID = sync.applyScript(ch)
+		scriptIDs[ch.Name] = ch.ID
+		changes = append(changes, ch)
+	}
+	changes = append(changes, sync.syncTemplateScripts(&m.Templates[0], map[string]string{template.Name: template.ID}, scriptIDs)...)
+
+	if err = formatter.PrintList(changes); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
@@ -1,31 +1,30 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/blueprint"
+	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/attributes"
+	"github.com/flexiant/concerto/utils/editor"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/flags"
 	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/manifest"
+	"github.com/flexiant/concerto/utils/order"
+	"github.com/flexiant/concerto/utils/query"
 )
 
 // WireUpTemplate prepares common resources to send request to Concerto API
 func WireUpTemplate(c *cli.Context) (ts *blueprint.TemplateService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ts, err = blueprint.NewTemplateService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up template service", err)
-	}
-
-	return ts, f
+	return wireUpClient(f).Templates, f
 }
 
 // TemplateList subcommand function
@@ -37,7 +36,7 @@ func TemplateList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive template data", err)
 	}
-	if err = formatter.PrintList(templates); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, templates)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -64,12 +63,22 @@ func TemplateCreate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	templateSvc, formatter := WireUpTemplate(c)
 
-	checkRequiredFlags(c, []string{"name", "generic_image_id"}, formatter)
+	validateFlags(c, flags.New(c).Required("name").AnyOf("generic_image_id", "generic_image").Exclusive("generic_image_id", "generic_image").JSON("service_list", "configuration_attributes"), formatter)
 
 	// parse json parameter values
 	params, err := utils.FlagConvertParamsJSON(c, []string{"service_list", "configuration_attributes"})
 	if err != nil {
-		formatter.PrintFatal("Error parsing parameters", err)
+		formatter.PrintFatal("Error parsing parameters", exit.NewValidationError(err))
+	}
+	if c.IsSet("generic_image") {
+		genericImageSvc, _ := WireUpGenericImage(c)
+		genericImage, err := genericImageSvc.GetGenericImage(c.String("generic_image"))
+		if err != nil {
+			formatter.PrintFatal("Couldn't resolve generic image", err)
+		}
+		log.Infof("Using generic image %s (%s)", genericImage.Name, genericImage.Id)
+		(*params)["generic_image_id"] = genericImage.Id
+		delete(*params, "generic_image")
 	}
 
 	template, err := templateSvc.CreateTemplate(params)
@@ -87,12 +96,26 @@ func TemplateUpdate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	templateSvc, formatter := WireUpTemplate(c)
 
-	checkRequiredFlags(c, []string{"id"}, formatter)
+	v := flags.New(c).Required("id").JSON("service_list", "configuration_attributes")
+	if c.Bool("merge-attributes") {
+		v.AnyOf("configuration_attributes", "unset")
+	}
+	validateFlags(c, v, formatter)
 
 	// parse json parameter values
 	params, err := utils.FlagConvertParamsJSON(c, []string{"service_list", "configuration_attributes"})
 	if err != nil {
-		formatter.PrintFatal("Error parsing parameters", err)
+		formatter.PrintFatal("Error parsing parameters", exit.NewValidationError(err))
+	}
+	delete(*params, "merge-attributes")
+	delete(*params, "unset")
+
+	if c.Bool("merge-attributes") || c.IsSet("unset") {
+		attrs, err := mergeTemplateAttributes(templateSvc, c.String("id"), (*params)["configuration_attributes"], c.StringSlice("unset"))
+		if err != nil {
+			formatter.PrintFatal("Couldn't merge configuration attributes", err)
+		}
+		(*params)["configuration_attributes"] = attrs
 	}
 
 	template, err := templateSvc.UpdateTemplate(params, c.String("id"))
@@ -105,16 +128,50 @@ func TemplateUpdate(c *cli.Context) error {
 	return nil
 }
 
+// mergeTemplateAttributes returns the current configuration attributes of a template, with those given
+// deep merged into them and the keys at the unset paths removed
+func mergeTemplateAttributes(templateSvc *blueprint.TemplateService, id string, given interface{}, unset []string) (map[string]interface{}, error) {
+	template, err := templateSvc.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	attrs := make(map[string]interface{})
+	if template.ConfigurationAttributes != nil {
+		// templates without attributes hold null
+		var current interface{}
+		if err = json.Unmarshal(*template.ConfigurationAttributes, &current); err != nil {
+			return nil, err
+		}
+		if current != nil {
+			var ok bool
+			if attrs, ok = current.(map[string]interface{}); !ok {
+				return nil, fmt.Errorf("Current attributes of template %s aren't a JSON object", id)
+			}
+		}
+	}
+
+	if given != nil {
+		src, ok := given.(map[string]interface{})
+		if !ok {
+			return nil, exit.NewValidationError(fmt.Errorf("--configuration_attributes must be a JSON object to be merged"))
+		}
+		attributes.Merge(attrs, src)
+	}
+	for _, path := range unset {
+		if err = attributes.Unset(attrs, path); err != nil {
+			return nil, exit.NewValidationError(err)
+		}
+	}
+	return attrs, nil
+}
+
 // TemplateDelete subcommand function
 func TemplateDelete(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	templateSvc, formatter := WireUpTemplate(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := templateSvc.DeleteTemplate(c.String("id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't delete template", err)
-	}
+	deleteByID(c, "id", "template", templateSvc.DeleteTemplate, formatter)
 	return nil
 }
 
@@ -125,17 +182,43 @@ func TemplateScriptList(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	templateScriptSvc, formatter := WireUpTemplate(c)
 
-	checkRequiredFlags(c, []string{"template_id", "type"}, formatter)
-	templateScripts, err := templateScriptSvc.GetTemplateScriptList(c.String("template_id"), c.String("type"))
+	validateFlags(c, flags.New(c).Required("template_id").Enum("type", types.TemplateScriptTypes...), formatter)
+	// the endpoint lists the script characterisations of a type, which can be given as a filter too
+	scriptType := firstNonEmpty(c.String("type"), strings.ToLower(apiFilter(c, formatter, "type")))
+	if !isTemplateScriptType(scriptType) {
+		formatter.PrintFatal("Incorrect usage.", exit.NewValidationError(fmt.Errorf("Please give --type, or --filter type=<type>, with one of %s", strings.Join(types.TemplateScriptTypes, ", "))))
+	}
+	templateScripts, err := templateScriptSvc.GetTemplateScriptList(c.String("template_id"), scriptType)
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive templateScript data", err)
 	}
-	if err = formatter.PrintList(*templateScripts); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, *templateScripts)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
 }
 
+func isTemplateScriptType(scriptType string) bool {
+	for _, t := range types.TemplateScriptTypes {
+		if t == scriptType {
+			return true
+		}
+	}
+	return false
+}
+
+// TemplateScriptSource is a script characterisation with the code of the script it runs, its parameter
+// values in place
+type TemplateScriptSource struct {
+	ID              string           `json:"id" header:"ID"`
+	Type            string           `json:"type" header:"TYPE"`
+	ExecutionOrder  int              `json:"execution_order" header:"EXECUTION ORDER"`
+	ScriptID        string           `json:"script_id" header:"SCRIPT ID"`
+	ScriptName      string           `json:"script_name" header:"SCRIPT NAME"`
+	ParameterValues *json.RawMessage `json:"parameter_values" header:"PARAMETER VALUES"`
+	Code            string           `json:"code" header:"CODE"`
+}
+
 // TemplateScriptShow subcommand function
 func TemplateScriptShow(c *cli.Context) error {
 	debugCmdFuncInfo(c)
@@ -146,24 +229,99 @@ func TemplateScriptShow(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive templateScript data", err)
 	}
-	if err = formatter.PrintItem(*templateScript); err != nil {
+	if !c.Bool("show-source") {
+		if err = formatter.PrintItem(*templateScript); err != nil {
+			formatter.PrintFatal("Couldn't print/format result", err)
+		}
+		return nil
+	}
+
+	scriptSvc, _ := WireUpScript(c)
+	script, err := scriptSvc.GetScript(templateScript.ScriptID)
+	if err != nil {
+		formatter.PrintFatal("Couldn't receive script data", err)
+	}
+	source := TemplateScriptSource{
+		ID:              templateScript.ID,
+		Type:            templateScript.Type,
+		ExecutionOrder:  templateScript.ExecutionOrder,
+		ScriptID:        script.ID,
+		ScriptName:      script.Name,
+		ParameterValues: templateScript.ParameterValues,
+		Code:            manifest.Render(script.Code, templateScript.ParameterValues),
+	}
+	if err = formatter.PrintItem(source); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
 }
 
+// templateScriptParams validates the flags of a script characterisation and converts them in API params.
+// Its parameter values are read from --parameter_values, given inline, from a file prefixed with @, or
+// from standard input with -, and from repeated --parameter key=value flags, which take precedence
+func templateScriptParams(c *cli.Context, v *flags.Validator, f format.Formatter) *map[string]interface{} {
+	values := make(map[string]interface{})
+	var valuesErr, pairsErr error
+	if c.IsSet("parameter_values") {
+		// parameter values may hold passwords, so they can be read from Vault or the OS keyring
+		value, err := utils.SecretFlagValue(c, "parameter_values")
+		if err == nil {
+			values, err = readParameterValues(value)
+		}
+		if valuesErr = err; err != nil {
+			values = make(map[string]interface{})
+		}
+	}
+	for _, pair := range c.StringSlice("parameter") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			pairsErr = fmt.Errorf("%q isn't a key=value pair", pair)
+			break
+		}
+		values[kv[0]] = kv[1]
+	}
+	validateFlags(c, v.Enum("type", types.TemplateScriptTypes...).
+		Check("parameter_values", func(string) error { return valuesErr }).
+		Check("parameter", func(string) error { return pairsErr }), f)
+
+	params, err := utils.FlagConvertParamsJSON(c, nil)
+	if err != nil {
+		f.PrintFatal("Error parsing parameters", exit.NewValidationError(err))
+	}
+	delete(*params, "parameter")
+	delete(*params, "parameter_values")
+	if c.IsSet("parameter_values") || c.IsSet("parameter") {
+		(*params)["parameter_values"] = values
+	}
+	return params
+}
+
+// readParameterValues reads the JSON map of parameter values given inline, from a file prefixed with @,
+// or from standard input with - or @-
+func readParameterValues(value string) (map[string]interface{}, error) {
+	if value == "-" {
+		value = "@-"
+	}
+	data, err := readFlagValue(value)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err = json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("it must be a JSON map of parameter values. %s", err)
+	}
+	if values == nil {
+		return nil, fmt.Errorf("it must be a JSON map of parameter values")
+	}
+	return values, nil
+}
+
 // TemplateScriptCreate subcommand function
 func TemplateScriptCreate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	templateScriptSvc, formatter := WireUpTemplate(c)
 
-	checkRequiredFlags(c, []string{"template_id", "type", "script_id"}, formatter)
-
-	// parse json parameter values
-	params, err := utils.FlagConvertParamsJSON(c, []string{"parameter_values"})
-	if err != nil {
-		formatter.PrintFatal("Error parsing parameters", err)
-	}
+	params := templateScriptParams(c, flags.New(c).Required("template_id", "type", "script_id"), formatter)
 
 	templateScript, err := templateScriptSvc.CreateTemplateScript(params, c.String("template_id"))
 	if err != nil {
@@ -180,19 +338,32 @@ func TemplateScriptUpdate(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	templateScriptSvc, formatter := WireUpTemplate(c)
 
-	// TODO si necessary: type script_id parameter_values ?
-	checkRequiredFlags(c, []string{"id", "template_id"}, formatter)
-
-	// parse json parameter values
-	params, err := utils.FlagConvertParamsJSON(c, []string{"parameter_values"})
-	if err != nil {
-		formatter.PrintFatal("Error parsing parameters", err)
+	params := templateScriptParams(c, flags.New(c).
+		Required("id", "template_id").
+		AnyOf("type", "script_id", "parameter_values", "parameter"), formatter)
+	// both identify the characterisation in the path, and aren't to be changed
+	delete(*params, "id")
+	delete(*params, "template_id")
+
+	var previous *types.TemplateScript
+	if c.IsSet("type") {
+		var err error
+		if previous, err = templateScriptSvc.GetTemplateScript(c.String("template_id"), c.String("id")); err != nil {
+			formatter.PrintFatal("Couldn't receive templateScript data", err)
+		}
+	}
+	if c.IsSet("script_id") && (*params)["parameter_values"] == nil {
+		log.Warn("Parameter values are kept as they are. Give them with --parameter_values or --parameter if the new script takes other parameters")
 	}
 
 	templateScript, err := templateScriptSvc.UpdateTemplateScript(params, c.String("template_id"), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't update templateScript", err)
 	}
+	// the API runs a characterisation moved to another type after the ones already there
+	if previous != nil && previous.Type != templateScript.Type {
+		log.Infof("Moved from %s scripts to %s scripts, in execution order %d", previous.Type, templateScript.Type, templateScript.ExecutionOrder)
+	}
 	if err = formatter.PrintItem(*templateScript); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
@@ -205,10 +376,9 @@ func TemplateScriptDelete(c *cli.Context) error {
 	templateScriptSvc, formatter := WireUpTemplate(c)
 
 	checkRequiredFlags(c, []string{"id", "template_id"}, formatter)
-	err := templateScriptSvc.DeleteTemplateScript(c.String("template_id"), c.String("id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't delete templateScript", err)
-	}
+	deleteByID(c, "id", "templateScript", func(id string) error {
+		return templateScriptSvc.DeleteTemplateScript(c.String("template_id"), id)
+	}, formatter)
 	return nil
 }
 
@@ -217,13 +387,54 @@ func TemplateScriptReorder(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	templateScriptSvc, formatter := WireUpTemplate(c)
 
-	checkRequiredFlags(c, []string{"template_id", "type", "script_ids"}, formatter)
-	params, err := utils.FlagConvertParamsJSON(c, []string{"script_ids"})
-	if err != nil {
-		formatter.PrintFatal("Error parsing parameters", err)
+	v := flags.New(c).
+		Required("template_id", "type").
+		Enum("type", types.TemplateScriptTypes...).
+		AnyOf("script_ids", "move", "edit").
+		Exclusive("script_ids", "move", "edit")
+	if c.IsSet("move") {
+		v.Required("to")
+	}
+	validateFlags(c, v, formatter)
+
+	var scriptIDs []string
+	var err error
+	if c.IsSet("script_ids") {
+		scriptIDs, err = order.Split(c.StringSlice("script_ids"))
+		if err != nil {
+			formatter.PrintFatal("Error parsing parameters", exit.NewValidationError(err))
+		}
+	} else {
+		templateScripts, err := templateScriptSvc.GetTemplateScriptList(c.String("template_id"), c.String("type"))
+		if err != nil {
+			formatter.PrintFatal("Couldn't receive templateScript data", err)
+		}
+		sort.SliceStable(*templateScripts, func(i, j int) bool {
+			return (*templateScripts)[i].ExecutionOrder < (*templateScripts)[j].ExecutionOrder
+		})
+		var current []string
+		for _, ts := range *templateScripts {
+			current = append(current, ts.ID)
+		}
+
+		if c.IsSet("move") {
+			scriptIDs, err = order.Move(current, c.String("move"), c.Int("to"))
+			if err != nil {
+				formatter.PrintFatal("Couldn't move templateScript", exit.NewValidationError(err))
+			}
+		} else {
+			scriptIDs = editTemplateScriptOrder(c, current, *templateScripts, formatter)
+		}
+		if order.Equal(current, scriptIDs) {
+			log.Info("Order hasn't changed")
+			if err = formatter.PrintList(*templateScripts); err != nil {
+				formatter.PrintFatal("Couldn't print/format result", err)
+			}
+			return nil
+		}
 	}
 
-	templateScript, err := templateScriptSvc.ReorderTemplateScript(params, c.String("template_id"))
+	templateScript, err := templateScriptSvc.ReorderTemplateScript(&map[string]interface{}{"script_ids": scriptIDs}, c.String("template_id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't reorder templateScript", err)
 	}
@@ -233,6 +444,38 @@ func TemplateScriptReorder(c *cli.Context) error {
 	return nil
 }
 
+// editTemplateScriptOrder opens the current order of template scripts in the editor, each described by the
+// name of its script, and returns the order saved
+func editTemplateScriptOrder(c *cli.Context, current []string, templateScripts []types.TemplateScript, formatter format.Formatter) []string {
+	names := make(map[string]string)
+	scriptSvc, _ := WireUpScript(c)
+	if scripts, err := scriptSvc.GetScriptList(); err != nil {
+		log.Warnf("Couldn't receive script names: %s", err)
+	} else {
+		for _, s := range scripts {
+			names[s.ID] = s.Name
+		}
+	}
+	descriptions := make(map[string]string)
+	for _, ts := range templateScripts {
+		if name := names[ts.ScriptID]; name != "" {
+			descriptions[ts.ID] = fmt.Sprintf("%s (script %s)", name, ts.ScriptID)
+		} else {
+			descriptions[ts.ID] = fmt.Sprintf("script %s", ts.ScriptID)
+		}
+	}
+
+	text, err := editor.Edit("template_scripts.txt", order.Format(current, descriptions))
+	if err != nil {
+		formatter.PrintFatal("Couldn't edit templateScript order", err)
+	}
+	scriptIDs, err := order.Parse(text, current)
+	if err != nil {
+		formatter.PrintFatal("Couldn't read templateScript order", exit.NewValidationError(err))
+	}
+	return scriptIDs
+}
+
 // =========== Template Servers =============
 
 // TemplateServersList subcommand function
@@ -240,13 +483,40 @@ func TemplateServersList(c *cli.Context) error {
 	debugCmdFuncInfo(c)
 	templateSvc, formatter := WireUpTemplate(c)
 
-	checkRequiredFlags(c, []string{"template_id"}, formatter)
-	templateServers, err := templateSvc.GetTemplateServerList(c.String("template_id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't receive template servers data", err)
-	}
-	if err = formatter.PrintList(*templateServers); err != nil {
-		formatter.PrintFatal("Couldn't print/format result", err)
+	validateFlags(c, flags.New(c).
+		Required("template_id").
+		Check("columns", func(columns string) error {
+			_, err := query.Select([]types.TemplateServer(nil), query.ParseColumns(columns))
+			return err
+		}), formatter)
+
+	var states []string
+	if c.String("state") != "" {
+		states = strings.Split(c.String("state"), ",")
 	}
+	printWatchedList(c, formatter, "Couldn't receive template servers data", func() (interface{}, error) {
+		templateServers, err := templateSvc.GetTemplateServerList(c.String("template_id"))
+		if err != nil {
+			return nil, err
+		}
+		return serversInStates(*templateServers, states), nil
+	})
 	return nil
 }
+
+// serversInStates returns the servers in any of states, regardless of case, or every server when there are none
+func serversInStates(servers []types.TemplateServer, states []string) []types.TemplateServer {
+	if len(states) == 0 {
+		return servers
+	}
+	var matching []types.TemplateServer
+	for _, server := range servers {
+		for _, state := range states {
+			if strings.EqualFold(server.State, strings.TrimSpace(state)) {
+				matching = append(matching, server)
+				break
+			}
+		}
+	}
+	return matching
+}
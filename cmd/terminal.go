@@ -0,0 +1,16 @@
+// +build !solaris
+
+package cmd
+
+import "golang.org/x/crypto/ssh/terminal"
+
+// isTerminal returns whether fd is a terminal, where secrets can be typed without echo and
+// screens can be refreshed
+func isTerminal(fd int) bool {
+	return terminal.IsTerminal(fd)
+}
+
+// readPassword reads a line from the terminal fd without echo
+func readPassword(fd int) ([]byte, error) {
+	return terminal.ReadPassword(fd)
+}
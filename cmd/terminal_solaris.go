@@ -0,0 +1,15 @@
+// +build solaris
+
+package cmd
+
+import "fmt"
+
+// isTerminal returns false, as echo can't be disabled in Solaris. Secrets are read as any other value
+func isTerminal(fd int) bool {
+	return false
+}
+
+// readPassword isn't supported in Solaris
+func readPassword(fd int) ([]byte, error) {
+	return nil, fmt.Errorf("Reading passwords isn't supported in Solaris")
+}
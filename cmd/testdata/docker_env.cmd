@@ -0,0 +1,3 @@
+SET "DOCKER_TLS_VERIFY=1"
+SET "DOCKER_HOST=tcp://docker1.example.com:2376"
+SET "DOCKER_CERT_PATH=/home/user/.concerto/docker/5641e7497aa4b1a67800006c"
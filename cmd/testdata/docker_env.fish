@@ -0,0 +1,5 @@
+set -gx DOCKER_TLS_VERIFY '1';
+set -gx DOCKER_HOST 'tcp://docker1.example.com:2376';
+set -gx DOCKER_CERT_PATH '/home/user/.concerto/docker/5641e7497aa4b1a67800006c';
+# Run this command to configure your shell:
+# eval (concerto docker env --id 5641e7497aa4b1a67800006c --shell fish)
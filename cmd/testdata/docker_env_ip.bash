@@ -0,0 +1,5 @@
+export DOCKER_TLS_VERIFY='1'
+export DOCKER_HOST='tcp://203.0.113.10:2376'
+export DOCKER_CERT_PATH='/tmp/docker'
+# Run this command to configure your shell:
+# eval $(concerto docker env --id 5641e7497aa4b1a67800006c)
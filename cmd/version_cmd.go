@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/api/version"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/format"
+)
+
+// VersionInfo stores the CLI build, the API version and whether they're compatible
+type VersionInfo struct {
+	Version       string `json:"version" header:"VERSION"`
+	Commit        string `json:"commit" header:"COMMIT"`
+	BuildDate     string `json:"build_date" header:"BUILD DATE"`
+	GoVersion     string `json:"go_version" header:"GO VERSION"`
+	Platform      string `json:"platform" header:"PLATFORM"`
+	APIVersion    string `json:"api_version,omitempty" header:"API VERSION"`
+	Compatibility string `json:"compatibility" header:"COMPATIBILITY"`
+}
+
+// VersionShow command function. Prints the CLI version and checks that the API supports it
+func VersionShow(c *cli.Context) error {
+	debugCmdFuncInfo(c)
+	formatter := format.GetFormatter()
+
+	info := VersionInfo{
+		Version:       utils.VERSION,
+		Commit:        utils.GitCommit,
+		BuildDate:     utils.BuildDate,
+		GoVersion:     runtime.Version(),
+		Platform:      fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		Compatibility: "unknown",
+	}
+
+	if !c.Bool("offline") {
+		apiVersion, err := getAPIVersion()
+		if err != nil {
+			log.Warnf("Couldn't check API version: %s", err)
+		} else {
+			info.APIVersion = apiVersion.Version
+			info.Compatibility = checkCompatibility(utils.VERSION, apiVersion)
+		}
+	}
+
+	if err := formatter.PrintItem(info); err != nil {
+		formatter.PrintFatal("Couldn't print/format result", err)
+	}
+	return nil
+}
+
+// getAPIVersion doesn't use PrintFatal, so that the CLI version is printed even without a working configuration
+func getAPIVersion() (*types.APIVersion, error) {
+	config, err := utils.GetConcertoConfig()
+	if err != nil {
+		return nil, err
+	}
+	hcs, err := utils.NewHTTPConcertoService(config)
+	if err != nil {
+		return nil, err
+	}
+	versionSvc, err := version.NewVersionService(hcs)
+	if err != nil {
+		return nil, err
+	}
+	return versionSvc.GetAPIVersion()
+}
+
+// checkCompatibility warns when cli isn't within the range of CLI versions supported by the API
+func checkCompatibility(cli string, api *types.APIVersion) string {
+	if api.MinCLIVersion != "" && utils.CompareVersions(cli, api.MinCLIVersion) < 0 {
+		log.Warnf("CLI version %s is too old for API version %s, which requires %s or newer. Update it with: %s", cli, api.Version, api.MinCLIVersion, utils.UpdateHint)
+		return "too old"
+	}
+	if api.MaxCLIVersion != "" && utils.CompareVersions(cli, api.MaxCLIVersion) > 0 {
+		log.Warnf("CLI version %s is newer than the latest supported by API version %s, %s. Some commands may fail", cli, api.Version, api.MaxCLIVersion)
+		return "too new"
+	}
+	return "compatible"
+}
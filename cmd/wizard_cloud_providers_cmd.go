@@ -3,29 +3,13 @@ package cmd
 import (
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/wizard"
-	"github.com/flexiant/concerto/utils"
 	"github.com/flexiant/concerto/utils/format"
 )
 
 // WireUpWizCloudProvider prepares common resources to send request to Concerto API
 func WireUpWizCloudProvider(c *cli.Context) (cs *wizard.WizCloudProvidersService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	cs, err = wizard.NewWizCloudProvidersService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up cloudProvider service", err)
-	}
-
-	return cs, f
+	return wireUpClient(f).WizCloudProviders, f
 }
 
 // WizCloudProviderList subcommand function
@@ -3,29 +3,13 @@ package cmd
 import (
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/wizard"
-	"github.com/flexiant/concerto/utils"
 	"github.com/flexiant/concerto/utils/format"
 )
 
 // WireUpWizServerPlan prepares common resources to send request to Concerto API
 func WireUpWizServerPlan(c *cli.Context) (ds *wizard.WizServerPlanService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ds, err = wizard.NewWizServerPlanService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up serverPlan service", err)
-	}
-
-	return ds, f
+	return wireUpClient(f).WizServerPlans, f
 }
 
 // WizServerPlanList subcommand function
@@ -3,29 +3,13 @@ package cmd
 import (
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/api/cloud"
-	"github.com/flexiant/concerto/utils"
 	"github.com/flexiant/concerto/utils/format"
 )
 
 // WireUpWorkspace prepares common resources to send request to Concerto API
 func WireUpWorkspace(c *cli.Context) (ds *cloud.WorkspaceService, f format.Formatter) {
-
 	f = format.GetFormatter()
-
-	config, err := utils.GetConcertoConfig()
-	if err != nil {
-		f.PrintFatal("Couldn't wire up config", err)
-	}
-	hcs, err := utils.NewHTTPConcertoService(config)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up concerto service", err)
-	}
-	ds, err = cloud.NewWorkspaceService(hcs)
-	if err != nil {
-		f.PrintFatal("Couldn't wire up workspace service", err)
-	}
-
-	return ds, f
+	return wireUpClient(f).Workspaces, f
 }
 
 // WorkspaceList subcommand function
@@ -37,7 +21,7 @@ func WorkspaceList(c *cli.Context) error {
 	if err != nil {
 		formatter.PrintFatal("Couldn't receive workspace data", err)
 	}
-	if err = formatter.PrintList(workspaces); err != nil {
+	if err = formatter.PrintList(filterList(c, formatter, workspaces)); err != nil {
 		formatter.PrintFatal("Couldn't print/format result", err)
 	}
 	return nil
@@ -65,7 +49,7 @@ func WorkspaceCreate(c *cli.Context) error {
 	workspaceSvc, formatter := WireUpWorkspace(c)
 
 	checkRequiredFlags(c, []string{"name", "domain_id", "ssh_profile_id", "firewall_profile_id"}, formatter)
-	workspace, err := workspaceSvc.CreateWorkspace(utils.FlagConvertParams(c))
+	workspace, err := workspaceSvc.CreateWorkspace(flagParams(c, formatter))
 	if err != nil {
 		formatter.PrintFatal("Couldn't create workspace", err)
 	}
@@ -81,7 +65,7 @@ func WorkspaceUpdate(c *cli.Context) error {
 	workspaceSvc, formatter := WireUpWorkspace(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	workspace, err := workspaceSvc.UpdateWorkspace(utils.FlagConvertParams(c), c.String("id"))
+	workspace, err := workspaceSvc.UpdateWorkspace(flagParams(c, formatter), c.String("id"))
 	if err != nil {
 		formatter.PrintFatal("Couldn't update workspace", err)
 	}
@@ -97,10 +81,7 @@ func WorkspaceDelete(c *cli.Context) error {
 	workspaceSvc, formatter := WireUpWorkspace(c)
 
 	checkRequiredFlags(c, []string{"id"}, formatter)
-	err := workspaceSvc.DeleteWorkspace(c.String("id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't delete workspace", err)
-	}
+	deleteByID(c, "id", "workspace", workspaceSvc.DeleteWorkspace, formatter)
 	return nil
 }
 
@@ -110,12 +91,12 @@ func WorkspaceServerList(c *cli.Context) error {
 	workspaceSvc, formatter := WireUpWorkspace(c)
 
 	checkRequiredFlags(c, []string{"workspace_id"}, formatter)
-	workspaceServers, err := workspaceSvc.GetWorkspaceServerList(c.String("workspace_id"))
-	if err != nil {
-		formatter.PrintFatal("Couldn't list workspace records", err)
-	}
-	if err = formatter.PrintList(*workspaceServers); err != nil {
-		formatter.PrintFatal("Couldn't print/format result", err)
-	}
+	printWatchedList(c, formatter, "Couldn't list workspace records", func() (interface{}, error) {
+		workspaceServers, err := workspaceSvc.GetWorkspaceServerList(c.String("workspace_id"))
+		if err != nil {
+			return nil, err
+		}
+		return *workspaceServers, nil
+	})
 	return nil
 }
@@ -0,0 +1,27 @@
+package completion
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+)
+
+// Command returns the completion CLI command
+func Command() cli.Command {
+	return cli.Command{
+		Name:      "completion",
+		Usage:     "Prints the shell completion script for bash, zsh or fish",
+		ArgsUsage: "bash|zsh|fish",
+		Action:    cmd.Completion,
+	}
+}
+
+// CompleteCommand returns the hidden CLI command completion scripts ask for candidates
+func CompleteCommand() cli.Command {
+	return cli.Command{
+		Name:            "__complete",
+		Usage:           "Prints the candidates completing the last of its arguments",
+		Hidden:          true,
+		SkipFlagParsing: true,
+		Action:          cmd.Complete,
+	}
+}
@@ -3,15 +3,25 @@ package converge
 import (
 	"bufio"
 	"errors"
+	"fmt"
 	"io"
 	"os/exec"
 	"path"
 	"regexp"
 	"runtime"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/codegangsta/cli"
 	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/metrics"
+	"github.com/flexiant/concerto/utils/notify"
+)
+
+var (
+	convergeRuns     = metrics.NewCounter("concerto_converge_runs_total", "Chef convergence runs, by result.", "result")
+	convergeDuration = metrics.NewGauge("concerto_converge_last_duration_seconds", "Duration of the last Chef convergence run.")
+	convergeLastRun  = metrics.NewGauge("concerto_converge_last_run_timestamp_seconds", "Time of the last Chef convergence run, by result.", "result")
 )
 
 func CmbConverge(c *cli.Context) error {
@@ -27,6 +37,7 @@ func CmbConverge(c *cli.Context) error {
 	if utils.FileExists(firstBootJsonChef) {
 		garbageOutput, _ := regexp.Compile("[\\[][^\\[|^\\]]*[\\]]\\s[A-Z]*:\\s")
 		output, _ := regexp.Compile("Chef Run")
+		start := time.Now()
 		cmd := exec.Command("chef-client", "-j", firstBootJsonChef)
 		stdout, err := cmd.StdoutPipe()
 		if err != nil {
@@ -61,11 +72,21 @@ func CmbConverge(c *cli.Context) error {
 
 		}
 		err = cmd.Wait()
+		result := "success"
 		if err != nil {
 			log.Errorf("%s", err.Error())
+			result = "failure"
+		}
+		if result == "success" {
+			notify.Record(1, 0)
+		} else {
+			notify.Record(0, 1)
 		}
+		convergeRuns.Inc(result)
+		convergeDuration.Set(time.Since(start).Seconds())
+		convergeLastRun.Set(float64(time.Now().Unix()), result)
 	} else {
-		log.Fatalf("Make sure %s chef client configuration exists.", firstBootJsonChef)
+		return fmt.Errorf("Make sure %s chef client configuration exists.", firstBootJsonChef)
 	}
 	return nil
 }
@@ -1,7 +1,11 @@
 package dispatcher
 
+import (
+	"github.com/flexiant/concerto/api/types"
+)
+
 // ByOrder implements sort.Interface for []ScriptCharacterization based on the Order field
-type ByOrder []ScriptCharacterization
+type ByOrder []types.ScriptCharacterization
 
 func (a ByOrder) Len() int {
 	return len(a)
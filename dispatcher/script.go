@@ -5,43 +5,25 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/client"
+	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/utils"
-	"github.com/flexiant/concerto/webservice"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/flags"
+	"github.com/flexiant/concerto/utils/metrics"
 )
 
-const (
-	characterizationsEndpoint = "blueprint/script_characterizations?type=%s"
-	conclusionsEndpoint       = "blueprint/script_conclusions"
+var (
+	scriptRuns     = metrics.NewCounter("concerto_script_runs_total", "Scripts executed, by phase and result.", "phase", "result")
+	scriptDuration = metrics.NewSummary("concerto_script_duration_seconds", "Time spent executing scripts, by phase.", "phase")
 )
 
-type ScriptCharacterization struct {
-	Order      int               `json:"execution_order"`
-	UUID       string            `json:"uuid"`
-	Script     Script            `json:"script"`
-	Parameters map[string]string `json:"parameter_values"`
-}
-
-type Script struct {
-	Code            string   `json:"code"`
-	UUID            string   `json:"uuid"`
-	AttachmentPaths []string `json:"attachment_paths"`
-}
-
-type ScriptConclusion struct {
-	UUID       string `json:"script_characterization_id"`
-	Output     string `json:"output"`
-	ExitCode   int    `json:"exit_code"`
-	StartedAt  string `json:"started_at"`
-	FinishedAt string `json:"finished_at"`
-}
-
-type ScriptConclusionRoot struct {
-	Root ScriptConclusion `json:"script_conclusion"`
-}
-
 func SubCommands() []cli.Command {
 	return []cli.Command{
 		{
@@ -59,104 +41,252 @@ func SubCommands() []cli.Command {
 			Usage:  "Executes scripts characterization associated to shutdown state of host",
 			Action: cmdShutdown,
 		},
+		{
+			Name:   "run",
+			Usage:  "Executes a script in the host, showing its output as it runs, and reports its result",
+			Action: cmdRun,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "script_id",
+					Usage: "Identifier of the script to run",
+				},
+				cli.StringFlag{
+					Name:  "parameters",
+					Usage: "The parameters of the script, as a JSON object of names and values",
+				},
+			},
+		},
 	}
 }
 
-func executeScriptCharacterization(script ScriptCharacterization, directoryPath string) (conclusion ScriptConclusionRoot) {
-	output, exitCode, startedAt, finishedAt := utils.ExecCode(script.Script.Code, directoryPath, script.Script.UUID)
+func executeScriptCharacterization(script types.ScriptCharacterization, directoryPath string, env []string) (conclusion types.ScriptConclusion, err error) {
+	output, exitCode, startedAt, finishedAt, err := utils.ExecCode(script.Script.Code, directoryPath, script.Script.UUID, env)
+	if err != nil {
+		return conclusion, err
+	}
 
-	conclusion.Root.UUID = script.UUID
-	conclusion.Root.Output = output
-	conclusion.Root.ExitCode = exitCode
-	conclusion.Root.StartedAt = startedAt.Format(utils.TimeStampLayout)
-	conclusion.Root.FinishedAt = finishedAt.Format(utils.TimeStampLayout)
+	conclusion.UUID = script.UUID
+	conclusion.Output = output
+	conclusion.ExitCode = exitCode
+	conclusion.StartedAt = startedAt.Format(utils.TimeStampLayout)
+	conclusion.FinishedAt = finishedAt.Format(utils.TimeStampLayout)
 
-	return conclusion
+	return conclusion, nil
 }
 
-func execute(phase string) {
-	var scriptChars []ScriptCharacterization
-	webservice, err := webservice.NewWebService()
+func execute(phase string) error {
+	hc, err := client.Default()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Debugf("Current Script Characterization %s", phase)
-	err, data, _ := webservice.Get(fmt.Sprintf(characterizationsEndpoint, phase))
-
-	json.Unmarshal(data, &scriptChars)
-
-	log.Debugf(string(data))
-
-	err = json.Unmarshal(data, &scriptChars)
+	scriptChars, err := hc.GetScriptCharacterizations(phase)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	scripts := ByOrder(scriptChars)
 
 	for _, ex := range scripts {
-		log.Infof("------------------------------------------------------------------------------------------------")
-		path, err := ioutil.TempDir("", "concerto")
-		if err != nil {
-			log.Fatal(err)
+		if _, err = Run(hc, phase, ex); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		os.Setenv("ATTACHMENT_DIR", fmt.Sprintf("%s/%s", path, "attachments"))
+// Run executes a script characterization of phase, with its parameters as environment variables and its
+// attachments downloaded, and reports the result
+func Run(hc *client.Client, phase string, ex types.ScriptCharacterization) (conclusion types.ScriptConclusion, err error) {
+	log.Infof("------------------------------------------------------------------------------------------------")
+	path, err := ioutil.TempDir("", "concerto")
+	if err != nil {
+		return conclusion, err
+	}
 
-		log.Infof("UUID: %s", ex.UUID)
-		log.Infof("Home Folder: %s", path)
-		err = os.Mkdir(os.Getenv("ATTACHMENT_DIR"), 0777)
-		if err != nil {
-			log.Fatal(err)
-		}
+	attachmentDir := fmt.Sprintf("%s/%s", path, "attachments")
 
-		// Seting up Enviroment Variables
-		log.Infof("Enviroment Variables")
-		for index, value := range ex.Parameters {
-			os.Setenv(index, value)
-			log.Infof("\t - %s=%s", index, value)
-		}
+	log.Infof("UUID: %s", ex.UUID)
+	log.Infof("Home Folder: %s", path)
+	err = os.Mkdir(attachmentDir, 0777)
+	if err != nil {
+		return conclusion, err
+	}
+
+	// Seting up Enviroment Variables
+	log.Infof("Enviroment Variables")
+	// values may hold secrets, so only names are logged
+	for _, name := range parameterNames(ex.Parameters) {
+		log.Infof("\t - %s", name)
+	}
 
-		if len(ex.Script.AttachmentPaths) > 0 {
-			log.Infof("Attachment Folder: %s", os.Getenv("ATTACHMENT_DIR"))
-			// Downloading Attachements
-			log.Infof("Attachments")
+	if len(ex.Script.AttachmentPaths) > 0 {
+		log.Infof("Attachment Folder: %s", attachmentDir)
+		// Downloading Attachements
+		log.Infof("Attachments")
+		for _, endpoint := range ex.Script.AttachmentPaths {
+			filename, err := hc.DownloadAttachment(endpoint, attachmentDir)
 			if err != nil {
-				log.Fatal(err)
-			}
-			for _, endpoint := range ex.Script.AttachmentPaths {
-				filename, err := webservice.GetFile(endpoint, os.Getenv("ATTACHMENT_DIR"))
-				if err != nil {
-					log.Fatal(err)
-				}
-				log.Infof("\t - %s --> %s", endpoint, filename)
+				return conclusion, err
 			}
+			log.Infof("\t - %s --> %s", endpoint, filename)
 		}
+	}
 
-		json, err := json.Marshal(executeScriptCharacterization(ex, path))
-		if err != nil {
-			log.Fatal(err)
-		}
+	conclusion, err = executeScriptCharacterization(ex, path, scriptEnv(ex.Parameters, map[string]string{"ATTACHMENT_DIR": attachmentDir}))
+	if err != nil {
+		return conclusion, err
+	}
+	recordScriptMetrics(phase, conclusion)
 
-		err, _, _ = webservice.Post(conclusionsEndpoint, json)
-		if err != nil {
-			log.Fatal(err)
+	err = hc.CreateScriptConclusion(conclusion)
+	if err != nil {
+		return conclusion, err
+	}
+
+	log.Infof("------------------------------------------------------------------------------------------------")
+	return conclusion, nil
+}
+
+// parameterNames returns the names of parameters, sorted
+func parameterNames(params map[string]string) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// scriptEnv returns the environment variables, as name=value sorted by name, a script runs with. Variables
+// are set for the script alone, so that those of a script never leak into the ones run after it
+func scriptEnv(vars ...map[string]string) []string {
+	env := []string{}
+	for _, v := range vars {
+		for name, value := range v {
+			env = append(env, name+"="+value)
 		}
+	}
+	sort.Strings(env)
+	return env
+}
 
-		log.Infof("------------------------------------------------------------------------------------------------")
+func recordScriptMetrics(phase string, conclusion types.ScriptConclusion) {
+	result := "success"
+	if conclusion.ExitCode != 0 {
+		result = "failure"
 	}
+	scriptRuns.Inc(phase, result)
+
+	startedAt, err := time.Parse(utils.TimeStampLayout, conclusion.StartedAt)
+	if err != nil {
+		return
+	}
+	finishedAt, err := time.Parse(utils.TimeStampLayout, conclusion.FinishedAt)
+	if err != nil {
+		return
+	}
+	scriptDuration.Observe(finishedAt.Sub(startedAt).Seconds(), phase)
 }
 
 func cmdBoot(c *cli.Context) error {
-	execute("boot")
-	return nil
+	return execute("boot")
 }
 
 func cmdOperational(c *cli.Context) error {
-	execute("operational")
-	return nil
+	return execute("operational")
 }
 
 func cmdShutdown(c *cli.Context) error {
-	execute("shutdown")
+	return execute("shutdown")
+}
+
+// scriptRunParameters reads the parameters given to run a script, as strings
+func scriptRunParameters(value string) (map[string]string, error) {
+	params := make(map[string]string)
+	if value == "" {
+		return params, nil
+	}
+	var values map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(value))
+	dec.UseNumber()
+	if err := dec.Decode(&values); err != nil {
+		return nil, fmt.Errorf("Invalid parameters: %s", err)
+	}
+	for name, v := range values {
+		switch v := v.(type) {
+		case nil:
+			params[name] = ""
+		case string:
+			params[name] = v
+		case json.Number, bool:
+			params[name] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("Invalid parameters: %s must be a string, number or boolean", name)
+		}
+	}
+	return params, nil
+}
+
+func cmdRun(c *cli.Context) error {
+	if err := flags.New(c).Required("script_id").JSON("parameters").Err(); err != nil {
+		return exit.NewValidationError(err)
+	}
+	params, err := scriptRunParameters(c.String("parameters"))
+	if err != nil {
+		return exit.NewValidationError(err)
+	}
+
+	hc, err := client.Default()
+	if err != nil {
+		return err
+	}
+	script, err := hc.Scripts.GetScript(c.String("script_id"))
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool)
+	for _, name := range script.Parameters {
+		known[name] = true
+		if _, ok := params[name]; !ok {
+			log.Warnf("Parameter %s of script %s isn't given", name, script.Name)
+		}
+	}
+	for name := range params {
+		if !known[name] {
+			return exit.NewValidationError(fmt.Errorf("Script %s has no parameter %s", script.Name, name))
+		}
+	}
+
+	path, err := ioutil.TempDir("", "concerto")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(path)
+
+	log.Infof("Script: %s (%s)", script.Name, script.ID)
+	log.Infof("Enviroment Variables")
+	for _, name := range parameterNames(params) {
+		log.Infof("\t - %s", name)
+	}
+
+	output, exitCode, startedAt, finishedAt, err := utils.StreamCode(script.Code, path, script.ID, scriptEnv(params), os.Stdout, os.Stderr)
+	if err != nil {
+		return err
+	}
+	err = hc.CreateScriptRun(types.ScriptRun{
+		ScriptID:   script.ID,
+		Output:     output,
+		ExitCode:   exitCode,
+		StartedAt:  startedAt.Format(utils.TimeStampLayout),
+		FinishedAt: finishedAt.Format(utils.TimeStampLayout),
+	})
+	if err != nil {
+		return err
+	}
+
+	if exitCode != 0 {
+		return &exit.Error{Err: fmt.Errorf("Script %s failed with exit code %d", script.Name, exitCode), Code: exit.Failure}
+	}
 	return nil
 }
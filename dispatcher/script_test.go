@@ -0,0 +1,36 @@
+package dispatcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	env := scriptEnv(map[string]string{"PORT": "80", "HOST": "web 1"}, map[string]string{"ATTACHMENT_DIR": "/tmp/concerto1/attachments"})
+	assert.Equal([]string{"ATTACHMENT_DIR=/tmp/concerto1/attachments", "HOST=web 1", "PORT=80"}, env, "Unexpected environment")
+	assert.Equal([]string{}, scriptEnv(nil), "Scripts without parameters should run with the environment of the process")
+}
+
+func TestScriptRunParameters(t *testing.T) {
+	assert := assert.New(t)
+
+	params, err := scriptRunParameters(`{"HOST": "web1", "PORT": 8080, "RATIO": 0.5, "DEBUG": true, "EMPTY": null}`)
+	assert.Nil(err, "Valid parameters shouldn't fail")
+	assert.Equal(map[string]string{"HOST": "web1", "PORT": "8080", "RATIO": "0.5", "DEBUG": "true", "EMPTY": ""}, params, "Values should be read as strings")
+
+	params, err = scriptRunParameters("")
+	assert.Nil(err, "Missing parameters shouldn't fail")
+	assert.Empty(params, "Missing parameters should be empty")
+
+	for _, value := range []string{`{"HOSTS": ["a", "b"]}`, `{"DB": {"host": "a"}}`, `["a"]`, `{"HOST": `} {
+		_, err = scriptRunParameters(value)
+		assert.NotNil(err, "Parameters %s should fail", value)
+	}
+}
+
+func TestParameterNames(t *testing.T) {
+	assert.Equal(t, []string{"HOST", "PASSWORD"}, parameterNames(map[string]string{"PASSWORD": "secret", "HOST": "web1"}), "Names should be sorted")
+}
@@ -7,11 +7,79 @@ import (
 
 // SubCommands return CLI subcommands
 func SubCommands() []cli.Command {
+	commands := append(domainCommands(),
+		cli.Command{
+			Name:        "domains",
+			Usage:       "Manages the domains of the account group",
+			Subcommands: domainCommands(),
+		},
+		cli.Command{
+			Name:        "records",
+			Usage:       "Manages the DNS records of a domain",
+			Subcommands: recordCommands(),
+		},
+	)
+	return append(commands, legacyRecordCommands()...)
+}
+
+// domainFlags are the fields of a domain that can be given on create and update
+func domainFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  "ttl",
+			Usage: "Time to live (TTL) of the Start of Authority (SOA) record, in seconds or as a duration such as 1h",
+		},
+		cli.StringFlag{
+			Name:  "contact",
+			Usage: "Contact e-mail",
+		},
+		cli.StringFlag{
+			Name:  "minimum",
+			Usage: "The minimum TTL of the SOA record, in seconds or as a duration such as 5m",
+		},
+	}
+}
+
+// recordFlags are the fields of a record that can be given on create and update
+func recordFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  "name",
+			Usage: "Record name",
+		},
+		cli.StringFlag{
+			Name:  "content",
+			Usage: "Record content: an IPv4 address for A records, an IPv6 one for AAAA records, and a host name for CNAME and MX records",
+		},
+		cli.StringFlag{
+			Name:  "ttl",
+			Usage: "Time to live (TTL), in seconds or as a duration such as 1h. The TTL of the domain is used when not given",
+		},
+		cli.StringFlag{
+			Name:  "prio",
+			Usage: "Priority (only MX records)",
+		},
+		cli.StringFlag{
+			Name:  "server_id",
+			Usage: "Identifier of the associated server (only A and AAAA records)",
+		},
+	}
+}
+
+func domainIDFlag() cli.Flag {
+	return cli.StringFlag{
+		Name:  "domain_id",
+		Usage: "Domain Id",
+	}
+}
+
+func domainCommands() []cli.Command {
 	return []cli.Command{
 		{
 			Name:   "list",
 			Usage:  "Lists the domains of the account group.",
 			Action: cmd.DomainList,
+			Flags:  cmd.ListFlags(),
 		},
 		{
 			Name:   "show",
@@ -28,79 +96,52 @@ func SubCommands() []cli.Command {
 			Name:   "create",
 			Usage:  "Creates a new domain.",
 			Action: cmd.DomainCreate,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "name",
 					Usage: "Fully-qualified domain name (FQDN)",
 				},
-				cli.StringFlag{
-					Name:  "ttl",
-					Usage: "Time to live (TTL) of the Start of Authority (SOA) record",
-				},
-				cli.StringFlag{
-					Name:  "contact",
-					Usage: "Contact e-mail",
-				},
-				cli.StringFlag{
-					Name:  "minimum",
-					Usage: "The minimum TTL of the SOA record",
-				},
-			},
+			}, domainFlags()...),
 		},
 		{
 			Name:   "update",
 			Usage:  "Updates an existing domain",
 			Action: cmd.DomainUpdate,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "id",
 					Usage: "Domain Id",
 				},
-				cli.StringFlag{
-					Name:  "ttl",
-					Usage: "Time to live (TTL) of the Start of Authority (SOA) record",
-				},
-				cli.StringFlag{
-					Name:  "contact",
-					Usage: "Contact e-mail",
-				},
-				cli.StringFlag{
-					Name:  "minimum",
-					Usage: "The minimum TTL of the SOA record",
-				},
-			},
+			}, domainFlags()...),
 		},
 		{
 			Name:   "delete",
 			Usage:  "Deletes a domain",
 			Action: cmd.DomainDelete,
 			Flags: []cli.Flag{
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "id",
-					Usage: "Domain Id",
+					Usage: "Domain Id. Repeat it to delete several domains",
 				},
 			},
 		},
+	}
+}
+
+func recordCommands() []cli.Command {
+	return []cli.Command{
 		{
-			Name:   "list_domain_records",
+			Name:   "list",
 			Usage:  "Lists the DNS records of a domain.",
 			Action: cmd.DomainRecordList,
-			Flags: []cli.Flag{
-				cli.StringFlag{
-					Name:  "domain_id",
-					Usage: "Domain Id",
-				},
-			},
+			Flags:  append([]cli.Flag{domainIDFlag()}, cmd.ListFlags()...),
 		},
 		{
-			Name:   "show_domain_record",
+			Name:   "show",
 			Usage:  "Shows information about a specific DNS record.",
 			Action: cmd.DomainRecordShow,
 			Flags: []cli.Flag{
-				cli.StringFlag{
-					Name:  "domain_id",
-					Usage: "Domain Id",
-				},
+				domainIDFlag(),
 				cli.StringFlag{
 					Name:  "id",
 					Usage: "Record Id",
@@ -108,89 +149,57 @@ func SubCommands() []cli.Command {
 			},
 		},
 		{
-			Name:   "create_domain_record",
+			Name:   "create",
 			Usage:  "Creates a new DNS record.",
 			Action: cmd.DomainRecordCreate,
-			Flags: []cli.Flag{
-				cli.StringFlag{
-					Name:  "domain_id",
-					Usage: "Domain Id",
-				},
+			Flags: append([]cli.Flag{
+				domainIDFlag(),
 				cli.StringFlag{
 					Name:  "type",
 					Usage: "Type of record (A, AAAA, CNAME, MX, TXT)",
 				},
-				cli.StringFlag{
-					Name:  "name",
-					Usage: "Record name",
-				},
-				cli.StringFlag{
-					Name:  "content",
-					Usage: "Record content",
-				},
-				cli.StringFlag{
-					Name:  "ttl",
-					Usage: "Time to live (TTL)",
-				},
-				cli.StringFlag{
-					Name:  "prio",
-					Usage: "Priority (only MX records)",
-				},
-				cli.StringFlag{
-					Name:  "server_id",
-					Usage: "Identifier of the associated server (only A and AAAA records)",
-				},
-			},
+			}, recordFlags()...),
 		},
 		{
-			Name:   "update_domain_record",
+			Name:   "update",
 			Usage:  "Updates an existing DNS record.",
 			Action: cmd.DomainRecordUpdate,
-			Flags: []cli.Flag{
-				cli.StringFlag{
-					Name:  "domain_id",
-					Usage: "Domain Id",
-				},
+			Flags: append([]cli.Flag{
+				domainIDFlag(),
 				cli.StringFlag{
 					Name:  "id",
 					Usage: "Record Id",
 				},
-				cli.StringFlag{
-					Name:  "name",
-					Usage: "Record name",
-				},
-				cli.StringFlag{
-					Name:  "content",
-					Usage: "Record content",
-				},
-				cli.StringFlag{
-					Name:  "ttl",
-					Usage: "Time to live (TTL)",
-				},
-				cli.StringFlag{
-					Name:  "prio",
-					Usage: "Priority (only MX records)",
-				},
-				cli.StringFlag{
-					Name:  "server_id",
-					Usage: "Identifier of the associated server (only A and AAAA records)",
-				},
-			},
+			}, recordFlags()...),
 		},
 		{
-			Name:   "delete_domain_record",
+			Name:   "delete",
 			Usage:  "Deletes a DNS record",
 			Action: cmd.DomainRecordDelete,
 			Flags: []cli.Flag{
-				cli.StringFlag{
+				domainIDFlag(),
+				cli.StringSliceFlag{
 					Name:  "id",
-					Usage: "Record Id",
-				},
-				cli.StringFlag{
-					Name:  "domain_id",
-					Usage: "Record Id",
+					Usage: "Record Id. Repeat it to delete several records",
 				},
 			},
 		},
 	}
 }
+
+// legacyRecordCommands keep the names records had before the records group, hidden from help
+func legacyRecordCommands() []cli.Command {
+	names := map[string]string{
+		"list":   "list_domain_records",
+		"show":   "show_domain_record",
+		"create": "create_domain_record",
+		"update": "update_domain_record",
+		"delete": "delete_domain_record",
+	}
+	commands := recordCommands()
+	for i := range commands {
+		commands[i].Name = names[commands[i].Name]
+		commands[i].Hidden = true
+	}
+	return commands
+}
@@ -0,0 +1,41 @@
+package dns
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLegacyRecordCommands(t *testing.T) {
+	assert := assert.New(t)
+
+	commands := make(map[string]cli.Command)
+	for _, c := range SubCommands() {
+		commands[c.Name] = c
+	}
+	records := make(map[string]cli.Command)
+	for _, c := range commands["records"].Subcommands {
+		records[c.Name] = c
+	}
+
+	legacy := map[string]string{
+		"list_domain_records":  "list",
+		"show_domain_record":   "show",
+		"create_domain_record": "create",
+		"update_domain_record": "update",
+		"delete_domain_record": "delete",
+	}
+	for name, recordName := range legacy {
+		c, ok := commands[name]
+		if !assert.True(ok, "Legacy command %s should be kept", name) {
+			continue
+		}
+		assert.True(c.Hidden, "Legacy command %s should be hidden from help", name)
+		record := records[recordName]
+		assert.Equal(reflect.ValueOf(record.Action).Pointer(), reflect.ValueOf(c.Action).Pointer(), "Legacy command %s should run records %s", name, recordName)
+		assert.Equal(len(record.Flags), len(c.Flags), "Legacy command %s should take the flags of records %s", name, recordName)
+	}
+	assert.False(records["list"].Hidden, "Records commands shouldn't be hidden")
+}
@@ -0,0 +1,75 @@
+package docker
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+)
+
+var shellFlag = cli.StringFlag{
+	Name:  "shell",
+	Usage: "Shell the environment is printed for [ bash | fish | powershell | cmd ]",
+	Value: "bash",
+}
+
+// SubCommands return CLI subcommands
+func SubCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:   "create-host",
+			Usage:  "Creates a server from a Docker ready template, and prints the environment to reach its Docker engine.",
+			Action: cmd.DockerCreateHost,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "name",
+					Usage: "Name of the server",
+				},
+				cli.StringFlag{
+					Name:  "fqdn",
+					Usage: "Fully qualified domain name (FQDN) of the server",
+				},
+				cli.StringFlag{
+					Name:  "workspace_id",
+					Usage: "Identifier of the workspace to which the server shall belong",
+				},
+				cli.StringFlag{
+					Name:  "template_id",
+					Usage: "Identifier of a template installing Docker engine",
+				},
+				cli.StringFlag{
+					Name:  "server_plan_id",
+					Usage: "Identifier of the server plan in which the server shall be deployed",
+				},
+				cli.StringFlag{
+					Name:  "script_id",
+					Usage: "Identifier of a script installing and configuring Docker engine with TLS, for templates that don't",
+				},
+				cli.StringFlag{
+					Name:  "tls_dir",
+					Usage: "Directory with the ca.pem, cert.pem and key.pem client certificates the Docker engine is set up to trust",
+				},
+				cli.StringFlag{
+					Name:  "timeout",
+					Usage: "Maximum time to wait till the Docker engine gets operational",
+					Value: "20m",
+				},
+				shellFlag,
+			},
+		},
+		{
+			Name:   "env",
+			Usage:  "Prints the environment to reach the Docker engine of a server.",
+			Action: cmd.DockerEnv,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "id",
+					Usage: "Server Id",
+				},
+				cli.StringFlag{
+					Name:  "tls_dir",
+					Usage: "Directory with the ca.pem, cert.pem and key.pem client certificates the Docker engine trusts, when not the ones given on create-host",
+				},
+				shellFlag,
+			},
+		},
+	}
+}
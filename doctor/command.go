@@ -0,0 +1,15 @@
+package doctor
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+)
+
+// Command returns the doctor CLI command
+func Command() cli.Command {
+	return cli.Command{
+		Name:   "doctor",
+		Usage:  "Diagnoses configuration, certificates, connectivity and clock, suggesting how to fix problems",
+		Action: cmd.DoctorRun,
+	}
+}
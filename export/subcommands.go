@@ -0,0 +1,58 @@
+package export
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+	"github.com/flexiant/concerto/utils/chef"
+)
+
+// SubCommands return CLI subcommands
+func SubCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:   "terraform",
+			Usage:  "Writes terraform configuration describing existing servers, templates, domains and firewall profiles.",
+			Action: cmd.ExportTerraform,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "dir",
+					Usage: "Directory where terraform files are written",
+					Value: ".",
+				},
+				cli.BoolFlag{
+					Name:  "skip-imports",
+					Usage: "Don't write import blocks adopting the exported resources",
+				},
+				cli.StringFlag{
+					Name:  "upload",
+					Usage: "S3 compatible location, such as s3://bucket/prefix, where exported files are uploaded",
+				},
+			},
+		},
+		{
+			Name:   "chef",
+			Usage:  "Writes a Chef environment and Berksfile mirroring a template's services and configuration attributes.",
+			Action: cmd.ExportChef,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "template_id",
+					Usage: "Template Id",
+				},
+				cli.StringFlag{
+					Name:  "dir",
+					Usage: "Directory where Chef files are written",
+					Value: ".",
+				},
+				cli.StringFlag{
+					Name:  "source",
+					Usage: "Cookbooks source written in Berksfile",
+					Value: chef.DefaultSource,
+				},
+				cli.StringFlag{
+					Name:  "upload",
+					Usage: "S3 compatible location, such as s3://bucket/prefix, where exported files are uploaded",
+				},
+			},
+		},
+	}
+}
@@ -1,92 +1,332 @@
 package firewall
 
 import (
-	"crypto/md5"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"os"
-	"text/tabwriter"
+	"strconv"
+	"strings"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/client"
+	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/utils"
-	"github.com/flexiant/concerto/webservice"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/format"
+	"github.com/flexiant/concerto/utils/metrics"
 )
 
-const endpoint = "cloud/firewall_profile"
+// Firewall drivers. Auto selects firewalld in Linux hosts where it's running, nftables in the ones where
+// iptables is missing or a shim of nftables, and netsh in Windows hosts
+const (
+	DriverAuto       = "auto"
+	DriverIptables   = "iptables"
+	DriverNftables   = "nftables"
+	DriverFirewalld  = "firewalld"
+	DriverNetsh      = "netsh"
+	DriverPowerShell = "powershell"
+)
+
+// driver is the firewall driver selected. Only Linux and Windows have several drivers
+var driver = DriverAuto
+
+// egressDrivers lists the drivers filtering outbound traffic
+var egressDrivers = map[string]bool{DriverIptables: true, DriverNftables: true}
+
+// ipv4Drivers lists the drivers only filtering IPv4 traffic
+var ipv4Drivers = map[string]bool{DriverIptables: true, DriverNftables: true, DriverFirewalld: true}
+
+var (
+	firewallDrift = metrics.NewGauge("concerto_firewall_drift", "Whether firewall rules applied in host differ from the ones in its firewall profile.")
+	firewallRules = metrics.NewGauge("concerto_firewall_rules", "Firewall rules in host firewall profile.")
+)
+
+// SelectDriver sets the firewall driver given with --driver, or in the configuration
+func SelectDriver(c *cli.Context) error {
+	name := c.String("driver")
+	if name == "" {
+		config, err := utils.GetConcertoConfig()
+		if err != nil {
+			return err
+		}
+		name = config.Firewall.Driver
+	}
+	if name == "" {
+		driver = DriverAuto
+		return nil
+	}
+	for _, d := range drivers {
+		if d == name {
+			driver = name
+			return nil
+		}
+	}
+	return exit.NewValidationError(fmt.Errorf("Unsupported firewall driver %s. Please, use one of %s", name, strings.Join(drivers, ", ")))
+}
+
+func list(policy types.HostFirewallPolicy) error {
+	return format.GetFormatter().PrintList(policy.ActualRules)
+}
+
+func get() (*types.HostFirewallPolicy, error) {
+	hc, err := client.Default()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Current firewall driver %s", driverName())
+	policy, err := hc.GetFirewallPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	firewallRules.Set(float64(len(policy.Rules)))
+	if sameRules(policy.Rules, policy.ActualRules) {
+		firewallDrift.Set(0)
+	} else {
+		firewallDrift.Set(1)
+	}
+	return policy, nil
+}
+
+// Plan is what applying a firewall policy would do in the host
+type Plan struct {
+	Driver string
+	// Commands are run in order by apply
+	Commands []string
+	// Installed and Wanted are the rules in the host and in the policy, in the syntax of the driver.
+	// Installed is nil when the driver can't read the rules in the host
+	Installed []string
+	Wanted    []string
+}
 
-type FirewallProfile struct {
-	Profile Policy `json:"firewall_profile"`
+// printPlan writes the commands of p, and the rules they'd add and remove
+func printPlan(w io.Writer, p *Plan) {
+	fmt.Fprintf(w, "# Commands %s would run\n", p.Driver)
+	for _, command := range p.Commands {
+		fmt.Fprintln(w, command)
+	}
+	fmt.Fprintln(w)
+	if p.Installed == nil {
+		fmt.Fprintf(w, "# Installed rules can't be read with %s\n", p.Driver)
+		return
+	}
+	added, removed := diffRules(p.Installed, p.Wanted)
+	if len(added) == 0 && len(removed) == 0 {
+		fmt.Fprintln(w, "# Installed rules already match the policy")
+		return
+	}
+	fmt.Fprintf(w, "# Changes to installed rules: %d added, %d removed\n", len(added), len(removed))
+	for _, rule := range removed {
+		fmt.Fprintf(w, "- %s\n", rule)
+	}
+	for _, rule := range added {
+		fmt.Fprintf(w, "+ %s\n", rule)
+	}
 }
 
-type Policy struct {
-	Rules       []Rule `json:"rules"`
-	Md5         string `json:"md5,omitempty"`
-	ActualRules []Rule `json:"actual_rules,omitempty"`
+// diffRules returns the rules in wanted missing from installed, and those in installed missing from wanted,
+// regardless of order
+func diffRules(installed []string, wanted []string) (added []string, removed []string) {
+	count := make(map[string]int)
+	for _, r := range installed {
+		count[r]++
+	}
+	for _, r := range wanted {
+		if count[r] > 0 {
+			count[r]--
+			continue
+		}
+		added = append(added, r)
+	}
+	for _, r := range installed {
+		if count[r] > 0 {
+			count[r]--
+			removed = append(removed, r)
+		}
+	}
+	return added, removed
 }
 
-type Rule struct {
-	Protocol string `json:"ip_protocol"`
-	Cidr     string `json:"cidr_ip"`
-	MinPort  int    `json:"min_port"`
-	MaxPort  int    `json:"max_port"`
+// sameRules returns whether both sets contain the same rules, regardless of order
+func sameRules(a []types.HostFirewallRule, b []types.HostFirewallRule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := make(map[types.HostFirewallRule]int)
+	for _, r := range a {
+		count[r.Normalized()]++
+	}
+	for _, r := range b {
+		if count[r.Normalized()] == 0 {
+			return false
+		}
+		count[r.Normalized()]--
+	}
+	return true
 }
 
-func list(policy Policy) error {
-	w := tabwriter.NewWriter(os.Stdout, 15, 1, 3, ' ', 0)
-	fmt.Fprintln(w, "CIDR\tPROTOCOL\tMIN\tMAX")
+// checkDriver returns an error when the driver can't apply every rule of policy, so that rules are never
+// left out
+func checkDriver(policy types.HostFirewallPolicy) error {
+	return driverSupports(driverName(), policy)
+}
 
-	for _, rule := range policy.ActualRules {
-		fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", rule.Cidr, rule.Protocol, rule.MinPort, rule.MaxPort)
+// driverSupports returns an error when driver can't apply every rule of policy: egress rules, unless it filters
+// outbound traffic, and icmpv6 rules or rules of IPv6 CIDRs, when it only filters IPv4 traffic
+func driverSupports(driver string, policy types.HostFirewallPolicy) error {
+	if policy.HasEgress() && !egressDrivers[driver] {
+		return fmt.Errorf("Firewall driver %s doesn't filter outbound traffic, and the policy has egress rules. Please, use the %s or %s driver", driver, DriverIptables, DriverNftables)
+	}
+	if !ipv4Drivers[driver] {
+		return nil
+	}
+	for i, rule := range policy.Rules {
+		if strings.ToLower(rule.Protocol) == "icmpv6" {
+			return fmt.Errorf("Firewall driver %s only filters IPv4 traffic, and rule %d is icmpv6", driver, i+1)
+		}
+		if ip, _, err := net.ParseCIDR(rule.Cidr); err == nil && ip.To4() == nil {
+			return fmt.Errorf("Firewall driver %s only filters IPv4 traffic, and rule %d is for IPv6 CIDR %s", driver, i+1, rule.Cidr)
+		}
 	}
-	w.Flush()
 	return nil
 }
 
-func get() Policy {
-	var policy Policy
-	webservice, err := webservice.NewWebService()
+// agentPolicy returns policy with the egress rules the agent needs to resolve and reach the API endpoint, so
+// that dropping outbound traffic never cuts it off. Policies without egress rules are returned as they are
+func agentPolicy(policy types.HostFirewallPolicy) (types.HostFirewallPolicy, error) {
+	if !policy.HasEgress() {
+		return policy, nil
+	}
+	config, err := utils.GetConcertoConfig()
 	if err != nil {
-		log.Fatal(err)
+		return policy, err
 	}
-
-	log.Debugf("Current firewall driver %s", driverName())
-	err, data, _ := webservice.Get(endpoint)
+	rules, err := agentEgressRules(config.APIEndpoint, net.LookupIP)
 	if err != nil {
-		log.Fatal(err)
+		return policy, fmt.Errorf("Egress rules can't let the agent reach the API. %s", err)
 	}
+	policy.Rules = append(rules, policy.Rules...)
+	return policy, nil
+}
 
-	err = json.Unmarshal(data, &policy)
-	if err != nil {
-		log.Fatal(err)
+// agentEgressRules returns the rules accepting DNS queries, and connections to the IPv4 addresses of the
+// endpoint host on its port
+func agentEgressRules(endpoint string, lookupIP func(host string) ([]net.IP, error)) ([]types.HostFirewallRule, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("Couldn't find the host of API endpoint %q", endpoint)
 	}
-	policy.Md5 = fmt.Sprintf("%x", md5.Sum(data))
-	return policy
+	port := 443
+	if u.Port() != "" {
+		if port, err = strconv.Atoi(u.Port()); err != nil {
+			return nil, fmt.Errorf("Couldn't find the port of API endpoint %q", endpoint)
+		}
+	} else if u.Scheme == "http" {
+		port = 80
+	}
+	rules := []types.HostFirewallRule{
+		{Cidr: "0.0.0.0/0", Protocol: "udp", MinPort: 53, MaxPort: 53, Direction: types.DirectionEgress},
+		{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 53, MaxPort: 53, Direction: types.DirectionEgress},
+	}
+	ips := []net.IP{net.ParseIP(u.Hostname())}
+	if ips[0] == nil {
+		if ips, err = lookupIP(u.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+	found := false
+	for _, ip := range ips {
+		// only IPv4 traffic is filtered
+		if ip4 := ip.To4(); ip4 != nil {
+			rules = append(rules, types.HostFirewallRule{Cidr: ip4.String() + "/32", Protocol: "tcp", MinPort: port, MaxPort: port, Direction: types.DirectionEgress})
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("API endpoint host %s has no IPv4 address", u.Hostname())
+	}
+	return rules, nil
 }
 
 func cmdList(c *cli.Context) error {
-	list(get())
-	return nil
+	policy, err := get()
+	if err != nil {
+		return err
+	}
+	return list(*policy)
 }
 
 func cmdApply(c *cli.Context) error {
-	policy := get()
+	policy, err := get()
+	if err != nil {
+		return err
+	}
+	// rules end up in firewall tool commands, so they're checked before anything is applied
+	if err = policy.Validate(); err != nil {
+		return fmt.Errorf("Firewall policy won't be applied. %s", err)
+	}
+	if err = checkDriver(*policy); err != nil {
+		return fmt.Errorf("Firewall policy won't be applied. %s", err)
+	}
+	if *policy, err = agentPolicy(*policy); err != nil {
+		return fmt.Errorf("Firewall policy won't be applied. %s", err)
+	}
+	if c.Bool("dry-run") {
+		p, err := plan(*policy)
+		if err != nil {
+			return err
+		}
+		printPlan(os.Stdout, p)
+		return nil
+	}
 	// Only apply firewall if we get a non-empty set of rules
 	if len(policy.Rules) > 0 {
-		apply(policy)
+		return apply(*policy)
 	}
 	return nil
 }
 
+// Sync applies the firewall policy of the host when it isn't the one applied before, identified by appliedMd5.
+// It returns the Md5 of the policy in place afterwards
+func Sync(appliedMd5 string) (string, error) {
+	policy, err := get()
+	if err != nil {
+		return appliedMd5, err
+	}
+	if policy.Md5 == appliedMd5 {
+		return appliedMd5, nil
+	}
+	if err = policy.Validate(); err != nil {
+		return appliedMd5, fmt.Errorf("Firewall policy won't be applied. %s", err)
+	}
+	if err = checkDriver(*policy); err != nil {
+		return appliedMd5, fmt.Errorf("Firewall policy won't be applied. %s", err)
+	}
+	if *policy, err = agentPolicy(*policy); err != nil {
+		return appliedMd5, fmt.Errorf("Firewall policy won't be applied. %s", err)
+	}
+	// Only apply firewall if we get a non-empty set of rules
+	if len(policy.Rules) > 0 {
+		if err = apply(*policy); err != nil {
+			return appliedMd5, err
+		}
+	}
+	return policy.Md5, nil
+}
+
 func cmdFlush(c *cli.Context) error {
-	flush()
-	return nil
+	return flush()
 }
 
-func check(policy Policy, rule Rule) bool {
+func check(policy types.HostFirewallPolicy, rule types.HostFirewallRule) bool {
 	exists := false
 	for _, policyRule := range policy.Rules {
-		if (policyRule.Cidr == rule.Cidr) && (policyRule.MaxPort == rule.MaxPort) && (policyRule.MinPort == rule.MinPort) && (policyRule.Protocol == rule.Protocol) {
+		if policyRule.Normalized() == rule.Normalized() {
 			exists = true
 		}
 	}
@@ -96,15 +336,18 @@ func check(policy Policy, rule Rule) bool {
 func cmdCheck(c *cli.Context) error {
 	utils.FlagsRequired(c, []string{"cidr", "minPort", "maxPort", "ipProtocol"})
 
-	newRule := &Rule{
-		c.String("ipProtocol"),
-		c.String("cidr"),
-		c.Int("minPort"),
-		c.Int("maxPort"),
+	newRule := &types.HostFirewallRule{
+		Cidr:     c.String("cidr"),
+		Protocol: c.String("ipProtocol"),
+		MinPort:  c.Int("minPort"),
+		MaxPort:  c.Int("maxPort"),
+	}
+	policy, err := get()
+	if err != nil {
+		return err
 	}
-	policy := get()
 
-	fmt.Printf("%t\n", check(policy, *newRule))
+	fmt.Printf("%t\n", check(*policy, *newRule))
 	return nil
 }
 
@@ -112,32 +355,28 @@ func cmdAdd(c *cli.Context) error {
 	utils.FlagsRequired(c, []string{"cidr", "minPort", "maxPort", "ipProtocol"})
 
 	// API accepts only 1 rule
-	newRule := &Rule{
-		c.String("ipProtocol"),
-		c.String("cidr"),
-		c.Int("minPort"),
-		c.Int("maxPort"),
+	newRule := &types.HostFirewallRule{
+		Cidr:     c.String("cidr"),
+		Protocol: c.String("ipProtocol"),
+		MinPort:  c.Int("minPort"),
+		MaxPort:  c.Int("maxPort"),
+	}
+	if err := newRule.Validate(); err != nil {
+		return exit.NewValidationError(err)
+	}
+	policy, err := get()
+	if err != nil {
+		return err
 	}
-	policy := get()
 
-	exists := check(policy, *newRule)
+	exists := check(*policy, *newRule)
 
 	if exists == false {
-		policy.Rules = append(policy.Rules, *newRule)
-		webservice, err := webservice.NewWebService()
-		utils.CheckError(err)
-
-		nRule := make(map[string]Rule)
-		nRule["rule"] = *newRule
-
-		json, err := json.Marshal(nRule)
-		utils.CheckError(err)
-		err, res, code := webservice.Post(fmt.Sprintf("%s/rules", endpoint), json)
-		if res == nil {
-			log.Fatal(err)
+		hc, err := client.Default()
+		if err != nil {
+			return err
 		}
-		utils.CheckError(err)
-		utils.CheckReturnCode(code, res)
+		return hc.AddFirewallRule(*newRule)
 	}
 
 	return nil
@@ -146,65 +385,52 @@ func cmdAdd(c *cli.Context) error {
 func cmdUpdate(c *cli.Context) error {
 	utils.FlagsRequired(c, []string{"rules"})
 
-	fp := &FirewallProfile{
-		Policy{},
-	}
-
-	var rules []Rule
+	var rules []types.HostFirewallRule
 	err := json.Unmarshal([]byte(c.String("rules")), &rules)
-	utils.CheckError(err)
-	fp.Profile.Rules = rules
-
-	webservice, err := webservice.NewWebService()
-	utils.CheckError(err)
+	if err != nil {
+		return exit.NewValidationError(fmt.Errorf("Invalid rules: %s", err))
+	}
+	policy := types.HostFirewallPolicy{Rules: rules}
+	if err = policy.Validate(); err != nil {
+		return exit.NewValidationError(err)
+	}
 
-	json, err := json.Marshal(fp)
-	utils.CheckError(err)
-	err, res, code := webservice.Put(endpoint, json)
-	if res == nil {
-		log.Fatal(err)
+	hc, err := client.Default()
+	if err != nil {
+		return err
 	}
-	utils.CheckError(err)
-	utils.CheckReturnCode(code, res)
-	return nil
+	return hc.UpdateFirewallPolicy(policy)
 }
 
 func cmdRemove(c *cli.Context) error {
 	utils.FlagsRequired(c, []string{"cidr", "minPort", "maxPort", "ipProtocol"})
 
-	existingRule := &Rule{
-		c.String("ipProtocol"),
-		c.String("cidr"),
-		c.Int("minPort"),
-		c.Int("maxPort"),
+	existingRule := &types.HostFirewallRule{
+		Cidr:     c.String("cidr"),
+		Protocol: c.String("ipProtocol"),
+		MinPort:  c.Int("minPort"),
+		MaxPort:  c.Int("maxPort"),
+	}
+	policy, err := get()
+	if err != nil {
+		return err
 	}
-	policy := get()
 
-	exists := check(policy, *existingRule)
+	exists := check(*policy, *existingRule)
 
 	if exists == true {
 		for i, rule := range policy.Rules {
-			if rule == *existingRule {
+			if rule.Normalized() == existingRule.Normalized() {
 				policy.Rules = append(policy.Rules[:i], policy.Rules[1+i:]...)
 				break
 			}
 		}
 
-		webservice, err := webservice.NewWebService()
-		utils.CheckError(err)
-
-		profile := &FirewallProfile{
-			policy,
-		}
-
-		json, err := json.Marshal(profile)
-		utils.CheckError(err)
-		err, res, code := webservice.Put(endpoint, json)
-		if res == nil {
-			log.Fatal(err)
+		hc, err := client.Default()
+		if err != nil {
+			return err
 		}
-		utils.CheckError(err)
-		utils.CheckReturnCode(code, res)
+		return hc.UpdateFirewallPolicy(*policy)
 	}
 	return nil
 }
@@ -215,6 +441,12 @@ func SubCommands() []cli.Command {
 			Name:   "apply",
 			Usage:  "Applies selected firewall rules in host",
 			Action: cmdApply,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Show the commands that would be run, and how installed rules would change, without applying them",
+				},
+			},
 		},
 		{
 			Name:   "flush",
@@ -274,7 +506,7 @@ func SubCommands() []cli.Command {
 			Flags: []cli.Flag{
 				cli.StringFlag{
 					Name:  "rules",
-					Usage: `JSON array in the form '[{"ip_protocol":"...", "min_port":..., "max_port":..., "cidr_ip":"..."}, ... ]'`,
+					Usage: `JSON array in the form '[{"ip_protocol":"...", "min_port":..., "max_port":..., "cidr_ip":"...", "direction":"ingress|egress"}, ... ]'`,
 				},
 			},
 		},
@@ -306,5 +538,7 @@ func SubCommands() []cli.Command {
 			Usage:  "Lists all firewall rules associated to host",
 			Action: cmdList,
 		},
+		rulesCommand(),
+		statusCommand(),
 	}
 }
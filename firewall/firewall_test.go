@@ -0,0 +1,87 @@
+package firewall
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffRules(t *testing.T) {
+	assert := assert.New(t)
+
+	added, removed := diffRules([]string{"a", "b", "b"}, []string{"b", "c", "a"})
+	assert.Equal([]string{"c"}, added, "Unexpected added rules")
+	assert.Equal([]string{"b"}, removed, "Duplicated installed rules should be removed")
+
+	added, removed = diffRules([]string{}, nil)
+	assert.Empty(added, "No rules shouldn't add any")
+	assert.Empty(removed, "No rules shouldn't remove any")
+}
+
+func TestPrintPlan(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	printPlan(&out, &Plan{Driver: "iptables", Commands: []string{"iptables -N CONCERTO"}, Installed: []string{"old"}, Wanted: []string{"new"}})
+	assert.Equal("# Commands iptables would run\niptables -N CONCERTO\n\n# Changes to installed rules: 1 added, 1 removed\n- old\n+ new\n", out.String(), "Unexpected plan")
+
+	out.Reset()
+	printPlan(&out, &Plan{Driver: "netsh", Commands: []string{"netsh"}})
+	assert.Contains(out.String(), "can't be read with netsh", "Unread rules should be reported")
+}
+
+func TestDriverSupports(t *testing.T) {
+	assert := assert.New(t)
+
+	ingress := types.HostFirewallPolicy{Rules: []types.HostFirewallRule{{Cidr: "10.0.0.0/8", Protocol: "icmpv4"}}}
+	assert.Nil(driverSupports(DriverIptables, ingress), "IPv4 rules should be supported")
+	assert.Nil(driverSupports(DriverNetsh, ingress), "IPv4 rules should be supported")
+
+	icmpv6 := types.HostFirewallPolicy{Rules: []types.HostFirewallRule{{Cidr: "::/0", Protocol: "icmpv6"}}}
+	for _, driver := range []string{DriverIptables, DriverNftables, DriverFirewalld} {
+		assert.NotNil(driverSupports(driver, icmpv6), "icmpv6 rules shouldn't be supported by %s", driver)
+	}
+	assert.Nil(driverSupports(DriverNetsh, icmpv6), "netsh should support icmpv6 rules")
+
+	ipv6 := types.HostFirewallPolicy{Rules: []types.HostFirewallRule{{Cidr: "2001:db8::/32", Protocol: "tcp", MinPort: 22, MaxPort: 22}}}
+	err := driverSupports(DriverNftables, ipv6)
+	if assert.NotNil(err, "IPv6 CIDRs shouldn't be supported by nftables") {
+		assert.Contains(err.Error(), "2001:db8::/32", "The IPv6 CIDR should be reported")
+	}
+
+	egress := types.HostFirewallPolicy{Rules: []types.HostFirewallRule{{Cidr: "10.0.0.0/8", Protocol: "tcp", MinPort: 443, MaxPort: 443, Direction: types.DirectionEgress}}}
+	assert.NotNil(driverSupports(DriverFirewalld, egress), "Egress rules shouldn't be supported by firewalld")
+}
+
+func TestAgentEgressRules(t *testing.T) {
+	assert := assert.New(t)
+
+	lookupIP := func(host string) ([]net.IP, error) {
+		if host != "clients.concerto.io" {
+			return nil, fmt.Errorf("no such host %s", host)
+		}
+		return []net.IP{net.ParseIP("2001:db8::1"), net.ParseIP("10.1.2.3")}, nil
+	}
+	rules, err := agentEgressRules("https://clients.concerto.io:886/", lookupIP)
+	assert.Nil(err, "Resolved endpoint shouldn't fail")
+	assert.Equal([]types.HostFirewallRule{
+		{Cidr: "0.0.0.0/0", Protocol: "udp", MinPort: 53, MaxPort: 53, Direction: types.DirectionEgress},
+		{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 53, MaxPort: 53, Direction: types.DirectionEgress},
+		{Cidr: "10.1.2.3/32", Protocol: "tcp", MinPort: 886, MaxPort: 886, Direction: types.DirectionEgress},
+	}, rules, "DNS and the endpoint IPv4 addresses on its port should be accepted")
+
+	rules, err = agentEgressRules("https://10.0.0.1/", lookupIP)
+	assert.Nil(err, "Endpoint addresses shouldn't be resolved")
+	assert.Equal(types.HostFirewallRule{Cidr: "10.0.0.1/32", Protocol: "tcp", MinPort: 443, MaxPort: 443, Direction: types.DirectionEgress}, rules[2], "HTTPS endpoints should default to port 443")
+
+	_, err = agentEgressRules("https://unknown.example.com/", lookupIP)
+	assert.NotNil(err, "Unresolved endpoints should fail")
+	_, err = agentEgressRules("https://[2001:db8::1]/", lookupIP)
+	assert.NotNil(err, "Endpoints without IPv4 addresses should fail")
+	_, err = agentEgressRules("", lookupIP)
+	assert.NotNil(err, "Missing endpoints should fail")
+}
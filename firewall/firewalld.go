@@ -0,0 +1,134 @@
+// +build linux
+
+package firewall
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+)
+
+const (
+	// firewalldZone holds concerto rules. It's made the default zone, so that it filters the traffic of
+	// interfaces not bound to other zones
+	firewalldZone = "concerto"
+	// firewalldFallbackZone is made the default zone again when concerto rules are flushed
+	firewalldFallbackZone = "public"
+)
+
+func firewalldApply(policy types.HostFirewallPolicy) error {
+	return runCommands(firewalldCommands(policy, firewalldZoneExists(), firewalldInstalledRules()), runCmd)
+}
+
+func firewalldFlush() error {
+	if output, _, _, _ := utils.RunCmd(firewallCmdCommand + " --get-default-zone"); output == firewalldZone {
+		utils.RunCmd(fmt.Sprintf("%s --set-default-zone=%s", firewallCmdCommand, firewalldFallbackZone))
+	}
+	utils.RunCmd(fmt.Sprintf("%s --permanent --delete-zone=%s", firewallCmdCommand, firewalldZone))
+	utils.RunCmd(firewallCmdCommand + " --reload")
+	return nil
+}
+
+func firewalldPlan(policy types.HostFirewallPolicy) (*Plan, error) {
+	p := &Plan{Driver: DriverFirewalld}
+	for _, rule := range policy.Rules {
+		p.Wanted = append(p.Wanted, firewalldRichRule(rule))
+	}
+	if _, err := exec.LookPath(firewallCmdCommand); err != nil {
+		log.Debugf("Couldn't read installed rules: %s", err)
+		p.Commands = firewalldCommands(policy, false, nil)
+		return p, nil
+	}
+	p.Installed = firewalldInstalledRules()
+	p.Commands = firewalldCommands(policy, firewalldZoneExists(), p.Installed)
+	return p, nil
+}
+
+// firewalldCommands returns the commands making the concerto zone hold the rules of policy, dropping any
+// other traffic, and making it the default zone. Only rules which differ from the installed ones are
+// changed, in the permanent configuration, which is then loaded. Loopback and established connections
+// are accepted by firewalld itself
+func firewalldCommands(policy types.HostFirewallPolicy, zoneExists bool, installed []string) []string {
+	var commands []string
+	if !zoneExists {
+		commands = append(commands, fmt.Sprintf("%s --permanent --new-zone=%s", firewallCmdCommand, firewalldZone))
+	}
+	commands = append(commands, fmt.Sprintf("%s --permanent --zone=%s --set-target=DROP", firewallCmdCommand, firewalldZone))
+
+	var wanted []string
+	for _, rule := range policy.Rules {
+		wanted = append(wanted, firewalldRichRule(rule))
+	}
+	added, removed := diffRules(installed, wanted)
+	for _, rule := range removed {
+		commands = append(commands, fmt.Sprintf("%s --permanent --zone=%s --remove-rich-rule='%s'", firewallCmdCommand, firewalldZone, rule))
+	}
+	for _, rule := range added {
+		commands = append(commands, fmt.Sprintf("%s --permanent --zone=%s --add-rich-rule='%s'", firewallCmdCommand, firewalldZone, rule))
+	}
+
+	// new zones can't be made the default one until they're loaded
+	commands = append(commands, firewallCmdCommand+" --reload")
+	commands = append(commands, fmt.Sprintf("%s --set-default-zone=%s", firewallCmdCommand, firewalldZone))
+	return commands
+}
+
+// firewalldRichRule returns the rich rule accepting the traffic of rule, as firewall-cmd lists it
+func firewalldRichRule(rule types.HostFirewallRule) string {
+	match := []string{`rule family="ipv4"`}
+	if rule.Cidr != "" {
+		match = append(match, fmt.Sprintf(`source address="%s"`, nftablesAddress(rule.Cidr)))
+	}
+	protocol := linuxProtocol(rule.Protocol)
+	switch protocol {
+	case "tcp", "udp":
+		ports := fmt.Sprintf("%d-%d", rule.MinPort, rule.MaxPort)
+		if rule.MinPort == rule.MaxPort {
+			ports = fmt.Sprintf("%d", rule.MinPort)
+		}
+		match = append(match, fmt.Sprintf(`port port="%s" protocol="%s"`, ports, protocol))
+	default:
+		match = append(match, fmt.Sprintf(`protocol value="%s"`, protocol))
+	}
+	return strings.Join(append(match, "accept"), " ")
+}
+
+// firewalldZoneExists returns whether the concerto zone is in the permanent configuration
+func firewalldZoneExists() bool {
+	output, err := exec.Command(firewallCmdCommand, "--permanent", "--get-zones").Output()
+	if err != nil {
+		return false
+	}
+	for _, zone := range strings.Fields(string(output)) {
+		if zone == firewalldZone {
+			return true
+		}
+	}
+	return false
+}
+
+// firewalldInstalledRules returns the rich rules of the concerto zone in the permanent configuration
+func firewalldInstalledRules() []string {
+	rules := []string{}
+	// listing fails when the zone doesn't exist yet, as it has no rules
+	output, err := exec.Command(firewallCmdCommand, "--permanent", "--zone="+firewalldZone, "--list-rich-rules").Output()
+	if err != nil {
+		return rules
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			rules = append(rules, line)
+		}
+	}
+	return rules
+}
+
+// firewalldRunning returns whether firewalld is running, in which case it owns the rules of iptables and nftables
+func firewalldRunning() bool {
+	output, err := exec.Command(firewallCmdCommand, "--state").Output()
+	return err == nil && strings.TrimSpace(string(output)) == "running"
+}
@@ -0,0 +1,51 @@
+// +build linux
+
+package firewall
+
+import (
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFirewalldRichRule(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(`rule family="ipv4" source address="0.0.0.0/0" port port="22" protocol="tcp" accept`,
+		firewalldRichRule(types.HostFirewallRule{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 22, MaxPort: 22}))
+	assert.Equal(`rule family="ipv4" source address="10.0.0.1" port port="1000-2000" protocol="udp" accept`,
+		firewalldRichRule(types.HostFirewallRule{Cidr: "10.0.0.1/32", Protocol: "UDP", MinPort: 1000, MaxPort: 2000}))
+	assert.Equal(`rule family="ipv4" source address="10.0.0.0/8" protocol value="icmp" accept`,
+		firewalldRichRule(types.HostFirewallRule{Cidr: "10.0.0.0/8", Protocol: "icmp"}))
+	assert.Equal(`rule family="ipv4" source address="10.0.0.0/8" protocol value="icmp" accept`,
+		firewalldRichRule(types.HostFirewallRule{Cidr: "10.0.0.0/8", Protocol: "icmpv4"}), "icmpv4 should be given as icmp")
+}
+
+func TestFirewalldCommands(t *testing.T) {
+	policy := types.HostFirewallPolicy{Rules: []types.HostFirewallRule{
+		{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 22, MaxPort: 22},
+		{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 443, MaxPort: 443},
+	}}
+
+	assert.Equal(t, []string{
+		"/usr/bin/firewall-cmd --permanent --new-zone=concerto",
+		"/usr/bin/firewall-cmd --permanent --zone=concerto --set-target=DROP",
+		`/usr/bin/firewall-cmd --permanent --zone=concerto --add-rich-rule='rule family="ipv4" source address="0.0.0.0/0" port port="22" protocol="tcp" accept'`,
+		`/usr/bin/firewall-cmd --permanent --zone=concerto --add-rich-rule='rule family="ipv4" source address="0.0.0.0/0" port port="443" protocol="tcp" accept'`,
+		"/usr/bin/firewall-cmd --reload",
+		"/usr/bin/firewall-cmd --set-default-zone=concerto",
+	}, firewalldCommands(policy, false, nil), "Unexpected commands creating the zone")
+
+	installed := []string{
+		`rule family="ipv4" source address="0.0.0.0/0" port port="22" protocol="tcp" accept`,
+		`rule family="ipv4" source address="0.0.0.0/0" port port="80" protocol="tcp" accept`,
+	}
+	assert.Equal(t, []string{
+		"/usr/bin/firewall-cmd --permanent --zone=concerto --set-target=DROP",
+		`/usr/bin/firewall-cmd --permanent --zone=concerto --remove-rich-rule='rule family="ipv4" source address="0.0.0.0/0" port port="80" protocol="tcp" accept'`,
+		`/usr/bin/firewall-cmd --permanent --zone=concerto --add-rich-rule='rule family="ipv4" source address="0.0.0.0/0" port port="443" protocol="tcp" accept'`,
+		"/usr/bin/firewall-cmd --reload",
+		"/usr/bin/firewall-cmd --set-default-zone=concerto",
+	}, firewalldCommands(policy, true, installed), "Only rules which changed should be applied")
+}
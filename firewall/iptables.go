@@ -4,48 +4,195 @@ package firewall
 
 import (
 	"fmt"
+	"net"
+	"os/exec"
+	"strings"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/utils"
 )
 
-func driverName() string {
-	return "iptables"
+const iptablesSaveCommand = "/sbin/iptables-save"
+
+// iptablesApply runs the commands applying policy, stopping at the first one failing so that traffic isn't
+// dropped unless the rules accepting it are in place
+func iptablesApply(policy types.HostFirewallPolicy) error {
+	return runCommands(iptablesCommands(policy, iptablesChainExists, iptablesRuleExists), runCmd)
 }
 
-func apply(policy Policy) error {
-	var exitCode int
-	utils.RunCmd("/sbin/iptables -w -N CONCERTO")
+func iptablesFlush() error {
+	utils.RunCmd("/sbin/iptables -w -P INPUT ACCEPT")
 	utils.RunCmd("/sbin/iptables -w -F CONCERTO")
-	utils.RunCmd("/sbin/iptables -w -P INPUT DROP")
+	utils.RunCmd("/sbin/iptables -w -D INPUT -j CONCERTO")
+	utils.RunCmd("/sbin/iptables -w -X CONCERTO")
+	for _, command := range iptablesFlushOutputCommands() {
+		utils.RunCmd(command)
+	}
+	return nil
+}
+
+// iptablesOutputChain holds egress rules, jumped to from OUTPUT
+const iptablesOutputChain = "CONCERTO-OUT"
 
-	_, exitCode, _, _ = utils.RunCmd("/sbin/iptables -w -C INPUT -i lo -j ACCEPT")
-	if exitCode != 0 {
-		utils.RunCmd("/sbin/iptables -w -A INPUT -i lo -j ACCEPT")
+// iptablesFlushOutputCommands returns the commands letting outbound traffic through again, removing egress rules
+func iptablesFlushOutputCommands() []string {
+	return []string{
+		"/sbin/iptables -w -P OUTPUT ACCEPT",
+		"/sbin/iptables -w -F " + iptablesOutputChain,
+		"/sbin/iptables -w -D OUTPUT -j " + iptablesOutputChain,
+		"/sbin/iptables -w -X " + iptablesOutputChain,
 	}
+}
 
-	_, exitCode, _, _ = utils.RunCmd("/sbin/iptables -w -C INPUT -m state --state ESTABLISHED,RELATED -j ACCEPT")
-	if exitCode != 0 {
-		utils.RunCmd("/sbin/iptables -w -A INPUT -m state --state ESTABLISHED,RELATED -j ACCEPT")
+// iptablesCommands returns the commands replacing the rules of the CONCERTO chain with the ingress ones of policy,
+// and those of the CONCERTO-OUT chain with its egress ones. Chains are only created when chainExists reports
+// they're missing, and rules of the INPUT and OUTPUT chains only appended when exists does. Traffic is accepted
+// while chains are replaced, and only dropped last, once the rules accepting it are in place. Outbound traffic
+// is only filtered when policy has egress rules, and is let through again when it no longer has them
+func iptablesCommands(policy types.HostFirewallPolicy, chainExists func(chain string) bool, exists func(rule string) bool) []string {
+	var commands []string
+	if !chainExists("CONCERTO") {
+		commands = append(commands, "/sbin/iptables -w -N CONCERTO")
 	}
+	commands = append(commands, "/sbin/iptables -w -P INPUT ACCEPT", "/sbin/iptables -w -F CONCERTO")
+	for _, rule := range []string{"INPUT -i lo -j ACCEPT", "INPUT -m state --state ESTABLISHED,RELATED -j ACCEPT"} {
+		if !exists(rule) {
+			commands = append(commands, "/sbin/iptables -w -A "+rule)
+		}
+	}
+	for _, rule := range policy.Rules {
+		if !rule.Egress() {
+			commands = append(commands, iptablesRuleCommand("CONCERTO", rule))
+		}
+	}
+	if !exists("INPUT -j CONCERTO") {
+		log.Debugln("Concerto Chain is not existant adding it to INPUT")
+		commands = append(commands, "/sbin/iptables -w -A INPUT -j CONCERTO")
+	}
+	commands = append(commands, "/sbin/iptables -w -P INPUT DROP")
 
+	jumped := exists("OUTPUT -j " + iptablesOutputChain)
+	if !policy.HasEgress() {
+		if jumped {
+			commands = append(commands, iptablesFlushOutputCommands()...)
+		}
+		return commands
+	}
+	if !chainExists(iptablesOutputChain) {
+		commands = append(commands, "/sbin/iptables -w -N "+iptablesOutputChain)
+	}
+	commands = append(commands, "/sbin/iptables -w -P OUTPUT ACCEPT", "/sbin/iptables -w -F "+iptablesOutputChain)
+	for _, rule := range []string{"OUTPUT -o lo -j ACCEPT", "OUTPUT -m state --state ESTABLISHED,RELATED -j ACCEPT"} {
+		if !exists(rule) {
+			commands = append(commands, "/sbin/iptables -w -A "+rule)
+		}
+	}
 	for _, rule := range policy.Rules {
-		utils.RunCmd(fmt.Sprintf("/sbin/iptables -w -A CONCERTO -s %s -p %s --dport %d:%d -j ACCEPT", rule.Cidr, rule.Protocol, rule.MinPort, rule.MaxPort))
+		if rule.Egress() {
+			commands = append(commands, iptablesRuleCommand(iptablesOutputChain, rule))
+		}
+	}
+	if !jumped {
+		commands = append(commands, "/sbin/iptables -w -A OUTPUT -j "+iptablesOutputChain)
 	}
+	return append(commands, "/sbin/iptables -w -P OUTPUT DROP")
+}
 
-	_, exitCode, _, _ = utils.RunCmd("/sbin/iptables -w -C INPUT -j CONCERTO")
-	if exitCode != 0 {
-		log.Debugln("Concerto Chain is not existant adding it to INPUT")
-		utils.RunCmd("/sbin/iptables -w -A INPUT -j CONCERTO")
+// iptablesRuleCommand returns the command appending rule to chain, accepting traffic from its CIDR when chain
+// is CONCERTO, and to it when chain is CONCERTO-OUT. Ports are only matched for tcp and udp
+func iptablesRuleCommand(chain string, rule types.HostFirewallRule) string {
+	flag := "-s"
+	if chain == iptablesOutputChain {
+		flag = "-d"
+	}
+	protocol := linuxProtocol(rule.Protocol)
+	command := fmt.Sprintf("/sbin/iptables -w -A %s %s %s -p %s", chain, flag, rule.Cidr, protocol)
+	if protocol == "tcp" || protocol == "udp" {
+		command = fmt.Sprintf("%s --dport %d:%d", command, rule.MinPort, rule.MaxPort)
 	}
+	return command + " -j ACCEPT"
+}
 
-	return nil
+// iptablesChainExists returns whether chain has been created
+func iptablesChainExists(chain string) bool {
+	_, exitCode, _, _ := utils.RunCmd("/sbin/iptables -w -n -L " + chain)
+	return exitCode == 0
 }
 
-func flush() error {
-	utils.RunCmd("/sbin/iptables -w -P INPUT ACCEPT")
-	utils.RunCmd("/sbin/iptables -w -F CONCERTO")
-	utils.RunCmd("/sbin/iptables -w -D INPUT -j CONCERTO")
-	utils.RunCmd("/sbin/iptables -w -X CONCERTO")
-	return nil
+// iptablesRuleExists returns whether rule, given as chain and rule specification, is installed
+func iptablesRuleExists(rule string) bool {
+	_, exitCode, _, _ := utils.RunCmd("/sbin/iptables -w -C " + rule)
+	return exitCode == 0
+}
+
+func iptablesPlan(policy types.HostFirewallPolicy) (*Plan, error) {
+	p := &Plan{Driver: DriverIptables, Commands: iptablesCommands(policy, iptablesChainExists, iptablesRuleExists)}
+	for _, rule := range policy.Rules {
+		ports := ""
+		protocol := linuxProtocol(rule.Protocol)
+		if protocol == "tcp" || protocol == "udp" {
+			ports = fmt.Sprintf("%d:%d", rule.MinPort, rule.MaxPort)
+		}
+		if rule.Egress() {
+			p.Wanted = append(p.Wanted, iptablesRuleSpec(iptablesOutputChain, rule.Cidr, protocol, ports))
+		} else {
+			p.Wanted = append(p.Wanted, iptablesRuleSpec("CONCERTO", rule.Cidr, protocol, ports))
+		}
+	}
+	output, err := exec.Command(iptablesSaveCommand, "-t", "filter").Output()
+	if err != nil {
+		log.Debugf("Couldn't read installed rules: %s", err)
+		return p, nil
+	}
+	p.Installed = iptablesInstalledRules(string(output))
+	return p, nil
+}
+
+// iptablesInstalledRules returns the rules of the CONCERTO and CONCERTO-OUT chains in iptables-save output
+func iptablesInstalledRules(save string) []string {
+	rules := []string{}
+	for _, line := range strings.Split(save, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "-A" || (fields[1] != "CONCERTO" && fields[1] != iptablesOutputChain) {
+			continue
+		}
+		// iptables-save omits any source or destination, and the upper port of single port ranges
+		address, protocol, ports := "0.0.0.0/0", "", ""
+		for i := 2; i < len(fields)-1; i++ {
+			switch fields[i] {
+			case "-s", "-d":
+				address = fields[i+1]
+			case "-p":
+				protocol = fields[i+1]
+			case "--dport":
+				ports = fields[i+1]
+			}
+		}
+		if ports != "" && !strings.Contains(ports, ":") {
+			ports = ports + ":" + ports
+		}
+		rules = append(rules, iptablesRuleSpec(fields[1], address, protocol, ports))
+	}
+	return rules
+}
+
+// iptablesRuleSpec returns a rule of chain as iptables-save would list it, so that installed and wanted rules
+// compare. address is the source of CONCERTO rules, and the destination of CONCERTO-OUT ones
+func iptablesRuleSpec(chain string, address string, protocol string, ports string) string {
+	if !strings.Contains(address, "/") {
+		address += "/32"
+	}
+	if _, network, err := net.ParseCIDR(address); err == nil {
+		address = network.String()
+	}
+	flag := "-s"
+	if chain == iptablesOutputChain {
+		flag = "-d"
+	}
+	spec := fmt.Sprintf("-A %s %s %s -p %s", chain, flag, address, strings.ToLower(protocol))
+	if ports != "" {
+		spec = fmt.Sprintf("%s --dport %s", spec, ports)
+	}
+	return spec + " -j ACCEPT"
 }
@@ -0,0 +1,124 @@
+// +build linux
+
+package firewall
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIptablesCommands(t *testing.T) {
+	policy := types.HostFirewallPolicy{Rules: []types.HostFirewallRule{{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 22, MaxPort: 22}}}
+	installed := func(rule string) bool { return rule == "INPUT -i lo -j ACCEPT" }
+	assert.Equal(t, []string{
+		"/sbin/iptables -w -N CONCERTO",
+		"/sbin/iptables -w -P INPUT ACCEPT",
+		"/sbin/iptables -w -F CONCERTO",
+		"/sbin/iptables -w -A INPUT -m state --state ESTABLISHED,RELATED -j ACCEPT",
+		"/sbin/iptables -w -A CONCERTO -s 0.0.0.0/0 -p tcp --dport 22:22 -j ACCEPT",
+		"/sbin/iptables -w -A INPUT -j CONCERTO",
+		"/sbin/iptables -w -P INPUT DROP",
+	}, iptablesCommands(policy, func(chain string) bool { return false }, installed), "Installed INPUT rules shouldn't be appended again")
+}
+
+func TestIptablesCommandsEgress(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := types.HostFirewallPolicy{Rules: []types.HostFirewallRule{
+		{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 22, MaxPort: 22},
+		{Cidr: "10.0.0.0/8", Protocol: "tcp", MinPort: 443, MaxPort: 443, Direction: types.DirectionEgress},
+	}}
+	created := func(chain string) bool { return chain == "CONCERTO" }
+	installed := func(rule string) bool { return rule != "OUTPUT -j CONCERTO-OUT" }
+	assert.Equal([]string{
+		"/sbin/iptables -w -P INPUT ACCEPT",
+		"/sbin/iptables -w -F CONCERTO",
+		"/sbin/iptables -w -A CONCERTO -s 0.0.0.0/0 -p tcp --dport 22:22 -j ACCEPT",
+		"/sbin/iptables -w -P INPUT DROP",
+		"/sbin/iptables -w -N CONCERTO-OUT",
+		"/sbin/iptables -w -P OUTPUT ACCEPT",
+		"/sbin/iptables -w -F CONCERTO-OUT",
+		"/sbin/iptables -w -A CONCERTO-OUT -d 10.0.0.0/8 -p tcp --dport 443:443 -j ACCEPT",
+		"/sbin/iptables -w -A OUTPUT -j CONCERTO-OUT",
+		"/sbin/iptables -w -P OUTPUT DROP",
+	}, iptablesCommands(policy, created, installed), "Egress rules should be applied to OUTPUT, which is dropped last")
+
+	policy.Rules = policy.Rules[:1]
+	commands := iptablesCommands(policy, created, func(rule string) bool { return true })
+	assert.Equal("/sbin/iptables -w -P OUTPUT ACCEPT", commands[4], "OUTPUT should accept again without egress rules")
+	assert.Equal("/sbin/iptables -w -X CONCERTO-OUT", commands[len(commands)-1], "Egress chain should be removed without egress rules")
+}
+
+func TestIptablesInstalledRules(t *testing.T) {
+	save := `# Generated by iptables-save v1.6.1
+*filter
+:INPUT DROP [0:0]
+:CONCERTO - [0:0]
+-A INPUT -i lo -j ACCEPT
+-A INPUT -j CONCERTO
+-A CONCERTO -p tcp -m tcp --dport 22 -j ACCEPT
+-A CONCERTO -s 10.0.0.0/8 -p udp -m udp --dport 1000:2000 -j ACCEPT
+-A CONCERTO -s 10.1.2.3/32 -p icmp -j ACCEPT
+-A CONCERTO-OUT -d 10.0.0.0/8 -p tcp -m tcp --dport 443 -j ACCEPT
+COMMIT
+`
+	assert.Equal(t, []string{
+		iptablesRuleSpec("CONCERTO", "0.0.0.0/0", "tcp", "22:22"),
+		iptablesRuleSpec("CONCERTO", "10.0.0.0/8", "UDP", "1000:2000"),
+		iptablesRuleSpec("CONCERTO", "10.1.2.3", "icmp", ""),
+		"-A CONCERTO-OUT -d 10.0.0.0/8 -p tcp --dport 443:443 -j ACCEPT",
+	}, iptablesInstalledRules(save), "Installed rules should compare with the policy ones")
+}
+
+func TestNftablesInstalledRules(t *testing.T) {
+	list := `table ip concerto {
+	chain input {
+		type filter hook input priority filter; policy drop;
+		iif "lo" accept
+		ct state established,related accept
+		ip saddr 0.0.0.0/0 tcp dport 22 accept
+		ip saddr 10.1.2.3 meta l4proto icmp accept
+	}
+}
+`
+	assert.Equal(t, []string{
+		nftablesMatch(types.HostFirewallRule{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 22, MaxPort: 22}) + " accept",
+		nftablesMatch(types.HostFirewallRule{Cidr: "10.1.2.3/32", Protocol: "icmp"}) + " accept",
+	}, nftablesInstalledRules(list), "Installed rules should compare with the policy ones")
+}
+
+func TestIptablesCommandsICMP(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("/sbin/iptables -w -A CONCERTO -s 10.0.0.0/8 -p icmp -j ACCEPT",
+		iptablesRuleCommand("CONCERTO", types.HostFirewallRule{Cidr: "10.0.0.0/8", Protocol: "icmpv4"}), "icmpv4 should be given as icmp, without ports")
+	assert.Equal("/sbin/iptables -w -A CONCERTO-OUT -d 10.1.2.3/32 -p icmp -j ACCEPT",
+		iptablesRuleCommand(iptablesOutputChain, types.HostFirewallRule{Cidr: "10.1.2.3/32", Protocol: "ICMP", Direction: types.DirectionEgress}), "ICMP rules shouldn't match ports")
+	assert.Equal("/sbin/iptables -w -A CONCERTO -s 0.0.0.0/0 -p udp --dport 53:53 -j ACCEPT",
+		iptablesRuleCommand("CONCERTO", types.HostFirewallRule{Cidr: "0.0.0.0/0", Protocol: "UDP", MinPort: 53, MaxPort: 53}), "UDP rules should match ports")
+}
+
+func TestIptablesApplyStopsAtFailure(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := types.HostFirewallPolicy{Rules: []types.HostFirewallRule{
+		{Cidr: "10.0.0.0/8", Protocol: "tcp", MinPort: 443, MaxPort: 443, Direction: types.DirectionEgress},
+	}}
+	commands := iptablesCommands(policy, func(chain string) bool { return true }, func(rule string) bool { return true })
+	var run []string
+	err := runCommands(commands, func(command string) (string, int) {
+		run = append(run, command)
+		if strings.Contains(command, "-A CONCERTO-OUT") {
+			return "iptables: No chain/target/match by that name.", 1
+		}
+		return "", 0
+	})
+
+	assert.NotNil(err, "A failed command should be returned")
+	assert.Contains(err.Error(), "No chain/target/match", "The output of the failed command should be returned")
+	assert.NotContains(run, "/sbin/iptables -w -P OUTPUT DROP", "Outbound traffic shouldn't be dropped when its rules failed")
+	assert.Equal(commands[len(run)-1], "/sbin/iptables -w -A CONCERTO-OUT -d 10.0.0.0/8 -p tcp --dport 443:443 -j ACCEPT", "Commands should stop at the failed one")
+}
@@ -0,0 +1,141 @@
+// +build linux
+
+package firewall
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+)
+
+const (
+	iptablesCommand    = "/sbin/iptables"
+	nftCommand         = "/usr/sbin/nft"
+	firewallCmdCommand = "/usr/bin/firewall-cmd"
+)
+
+// drivers lists the firewall drivers supported in Linux
+var drivers = []string{DriverAuto, DriverIptables, DriverNftables, DriverFirewalld}
+
+// resolvedDriver caches the driver auto selects
+var resolvedDriver string
+
+func driverName() string {
+	if resolvedDriver == "" {
+		resolvedDriver = linuxDriver(driver, exec.LookPath, iptablesVersion, firewalldRunning)
+	}
+	return resolvedDriver
+}
+
+func apply(policy types.HostFirewallPolicy) error {
+	var err error
+	switch driverName() {
+	case DriverNftables:
+		err = nftablesApply(policy)
+	case DriverFirewalld:
+		err = firewalldApply(policy)
+	default:
+		err = iptablesApply(policy)
+	}
+	if err != nil {
+		return err
+	}
+	persistOrWarn()
+	return nil
+}
+
+func plan(policy types.HostFirewallPolicy) (*Plan, error) {
+	switch driverName() {
+	case DriverNftables:
+		return nftablesPlan(policy)
+	case DriverFirewalld:
+		return firewalldPlan(policy)
+	}
+	return iptablesPlan(policy)
+}
+
+func flush() error {
+	var err error
+	switch driverName() {
+	case DriverNftables:
+		err = nftablesFlush()
+	case DriverFirewalld:
+		err = firewalldFlush()
+	default:
+		err = iptablesFlush()
+	}
+	if err != nil {
+		return err
+	}
+	persistOrWarn()
+	return nil
+}
+
+// linuxProtocol returns protocol as Linux firewall tools name it, icmp for icmpv4
+func linuxProtocol(protocol string) string {
+	protocol = strings.ToLower(protocol)
+	if protocol == "icmpv4" {
+		return "icmp"
+	}
+	return protocol
+}
+
+// runCommands runs commands in order, stopping at the first one failing
+func runCommands(commands []string, run func(command string) (output string, exitCode int)) error {
+	for _, command := range commands {
+		if output, exit := run(command); exit != 0 {
+			return fmt.Errorf("Error executing firewall apply: (%d) %s", exit, output)
+		}
+	}
+	return nil
+}
+
+func runCmd(command string) (string, int) {
+	output, exitCode, _, _ := utils.RunCmd(command)
+	return output, exitCode
+}
+
+// persistOrWarn saves the rules in place. Failing to do so doesn't undo them, so it's only reported
+func persistOrWarn() {
+	if err := persist(); err != nil {
+		log.Warnf("Firewall rules won't be restored after a reboot. %s", err)
+	}
+}
+
+// linuxDriver resolves auto to the driver of the tools available in the host. firewalld is used whenever
+// it's running, as it would revert or conflict with rules applied otherwise. nftables is preferred when
+// iptables is missing or is the shim translating rules to nftables
+func linuxDriver(name string, lookPath func(string) (string, error), iptablesVersion func() string, firewalldRunning func() bool) string {
+	if name != DriverAuto {
+		return name
+	}
+	if _, err := lookPath(firewallCmdCommand); err == nil && firewalldRunning() {
+		log.Debugf("firewalld is running, using firewalld")
+		return DriverFirewalld
+	}
+	if _, err := lookPath(nftCommand); err != nil {
+		return DriverIptables
+	}
+	if _, err := lookPath(iptablesCommand); err != nil {
+		log.Debugf("%s not found, using nftables", iptablesCommand)
+		return DriverNftables
+	}
+	if strings.Contains(iptablesVersion(), "nf_tables") {
+		log.Debugf("%s is an nftables shim, using nftables", iptablesCommand)
+		return DriverNftables
+	}
+	return DriverIptables
+}
+
+// iptablesVersion returns iptables --version, such as "iptables v1.8.7 (nf_tables)"
+func iptablesVersion() string {
+	output, err := exec.Command(iptablesCommand, "--version").CombinedOutput()
+	if err != nil {
+		return ""
+	}
+	return string(output)
+}
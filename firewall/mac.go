@@ -4,22 +4,38 @@ package firewall
 
 import (
 	"fmt"
+
+	"github.com/flexiant/concerto/api/types"
 )
 
+// drivers lists the firewall drivers supported in macOS. Rules are only printed
+var drivers = []string{DriverAuto}
+
 func driverName() string {
 	return "darwin"
 }
 
-func apply(policy Policy) error {
-	fmt.Println("iptables -A INPUT -i lo -j ACCEPT")
-	fmt.Println("iptables -A INPUT -m state --state ESTABLISHED,RELATED -j ACCEPT")
+func apply(policy types.HostFirewallPolicy) error {
+	for _, command := range macCommands(policy) {
+		fmt.Println(command)
+	}
+	return nil
+}
+
+func plan(policy types.HostFirewallPolicy) (*Plan, error) {
+	return &Plan{Driver: driverName(), Commands: macCommands(policy)}, nil
+}
 
+// macCommands returns the rules apply prints
+func macCommands(policy types.HostFirewallPolicy) []string {
+	commands := []string{
+		"iptables -A INPUT -i lo -j ACCEPT",
+		"iptables -A INPUT -m state --state ESTABLISHED,RELATED -j ACCEPT",
+	}
 	for _, rule := range policy.Rules {
-		fmt.Printf("iptables -A INPUT -s %s -p %s --dport %d:%d -j ACCEPT\n", rule.Cidr, rule.Protocol, rule.MinPort, rule.MaxPort)
+		commands = append(commands, fmt.Sprintf("iptables -A INPUT -s %s -p %s --dport %d:%d -j ACCEPT", rule.Cidr, rule.Protocol, rule.MinPort, rule.MaxPort))
 	}
-	fmt.Println("iptables -P INPUT ACCEPT")
-	fmt.Println("iptables -F INPUT")
-	return nil
+	return append(commands, "iptables -P INPUT ACCEPT", "iptables -F INPUT")
 }
 
 func flush() error {
@@ -27,3 +43,8 @@ func flush() error {
 	fmt.Println("iptables -P INPUT DROP")
 	return nil
 }
+
+// status reports rules as unknown, as they're only printed
+func status() (*Status, error) {
+	return &Status{Driver: driverName(), Persistence: PersistenceUnknown}, nil
+}
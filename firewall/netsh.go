@@ -0,0 +1,69 @@
+// +build windows
+
+package firewall
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+)
+
+// windowsRuleName names the rules concerto adds to Windows firewall, so that other rules are left alone
+const windowsRuleName = "Concerto firewall"
+
+var netshFlushRulesCommand = fmt.Sprintf("netsh advfirewall firewall delete rule name=%q dir=in", windowsRuleName)
+
+func netshApply(policy types.HostFirewallPolicy) error {
+	netshFlushRules()
+	for _, command := range netshCommands(policy) {
+		if output, exit, _, _ := utils.RunCmd(command); exit != 0 {
+			return fmt.Errorf("Error executing firewall apply: (%d) %s", exit, output)
+		}
+	}
+	return nil
+}
+
+func netshFlush() error {
+	netshFlushRules()
+	if output, exit, _, _ := utils.RunCmd("netsh advfirewall set allprofiles firewallpolicy allowinbound,allowoutbound"); exit != 0 {
+		return fmt.Errorf("Error executing firewall flush: (%d) %s", exit, output)
+	}
+	return nil
+}
+
+// netshFlushRules deletes concerto rules. netsh fails when there are none
+func netshFlushRules() {
+	utils.RunCmd(netshFlushRulesCommand)
+}
+
+// netshCommands returns the netsh commands allowing the rules of policy and blocking any other inbound
+// traffic. Rules are added before blocking, so that connections allowed by them aren't cut.
+// Windows firewall is stateful and exempts loopback, so neither needs a rule
+func netshCommands(policy types.HostFirewallPolicy) []string {
+	var commands []string
+	for _, rule := range policy.Rules {
+		command := fmt.Sprintf("netsh advfirewall firewall add rule name=%q dir=in action=allow remoteip=%s protocol=%s", windowsRuleName, rule.Cidr, strings.ToLower(rule.Protocol))
+		if ports := windowsPorts(rule); ports != "" {
+			command = fmt.Sprintf("%s localport=%s", command, ports)
+		}
+		commands = append(commands, command)
+	}
+	return append(commands,
+		"netsh advfirewall set allprofiles firewallpolicy blockinbound,allowoutbound",
+		"netsh advfirewall set allprofiles state on",
+	)
+}
+
+// windowsPorts returns the local ports of rule as Windows firewall expects them. Ports only apply to TCP and UDP
+func windowsPorts(rule types.HostFirewallRule) string {
+	protocol := strings.ToLower(rule.Protocol)
+	if (protocol != "tcp" && protocol != "udp") || rule.MaxPort == 0 {
+		return ""
+	}
+	if rule.MinPort == rule.MaxPort {
+		return fmt.Sprint(rule.MinPort)
+	}
+	return fmt.Sprintf("%d-%d", rule.MinPort, rule.MaxPort)
+}
@@ -0,0 +1,152 @@
+// +build linux
+
+package firewall
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+)
+
+// nftTable holds concerto rules. As iptables rules, they only filter IPv4 traffic
+const nftTable = "ip concerto"
+
+func nftablesApply(policy types.HostFirewallPolicy) error {
+	file, err := ioutil.TempFile("", "concerto-nft")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(nftablesRuleset(policy))
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	// nft applies the whole ruleset or nothing
+	if output, exit, _, _ := utils.RunCmd(fmt.Sprintf("%s -f %s", nftCommand, file.Name())); exit != 0 {
+		return fmt.Errorf("Error executing firewall apply: (%d) %s", exit, output)
+	}
+	return nil
+}
+
+func nftablesFlush() error {
+	utils.RunCmd(fmt.Sprintf("%s delete table %s", nftCommand, nftTable))
+	return nil
+}
+
+func nftablesPlan(policy types.HostFirewallPolicy) (*Plan, error) {
+	ruleset := nftablesRuleset(policy)
+	p := &Plan{Driver: DriverNftables}
+	p.Commands = append(p.Commands, fmt.Sprintf("%s -f - <<EOF", nftCommand))
+	p.Commands = append(p.Commands, strings.Split(strings.TrimSuffix(ruleset, "\n"), "\n")...)
+	p.Commands = append(p.Commands, "EOF")
+	for _, rule := range policy.Rules {
+		p.Wanted = append(p.Wanted, nftablesMatch(rule)+" accept")
+	}
+
+	if _, err := exec.LookPath(nftCommand); err != nil {
+		log.Debugf("Couldn't read installed rules: %s", err)
+		return p, nil
+	}
+	// listing fails when the table doesn't exist yet, as it has no rules
+	output, _ := exec.Command(nftCommand, "list", "table", nftTable).Output()
+	p.Installed = nftablesInstalledRules(string(output))
+	return p, nil
+}
+
+// nftablesInstalledRules returns the rules of the concerto table in nft list output, leaving out
+// the ones accepting loopback and established connections, which aren't part of the policy
+func nftablesInstalledRules(list string) []string {
+	rules := []string{}
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasSuffix(line, " accept") || strings.HasPrefix(line, "iif ") || strings.HasPrefix(line, "oif ") || strings.HasPrefix(line, "ct state ") {
+			continue
+		}
+		rules = append(rules, line)
+	}
+	return rules
+}
+
+// nftablesRuleset returns the nft script replacing the concerto table with the rules of policy, ingress ones
+// in its input chain and egress ones in its output chain. The table is declared before deleting it, so that
+// the script works whether it exists or not
+func nftablesRuleset(policy types.HostFirewallPolicy) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "table %s\n", nftTable)
+	fmt.Fprintf(&b, "delete table %s\n", nftTable)
+	fmt.Fprintf(&b, "table %s {\n", nftTable)
+	b.WriteString("\tchain input {\n")
+	b.WriteString("\t\ttype filter hook input priority 0; policy drop;\n")
+	b.WriteString("\t\tiif lo accept\n")
+	b.WriteString("\t\tct state established,related accept\n")
+	for _, rule := range policy.Rules {
+		if !rule.Egress() {
+			fmt.Fprintf(&b, "\t\t%s accept\n", nftablesMatch(rule))
+		}
+	}
+	b.WriteString("\t}\n")
+	// outbound traffic is only filtered when there are egress rules
+	if policy.HasEgress() {
+		b.WriteString("\tchain output {\n")
+		b.WriteString("\t\ttype filter hook output priority 0; policy drop;\n")
+		b.WriteString("\t\toif lo accept\n")
+		b.WriteString("\t\tct state established,related accept\n")
+		for _, rule := range policy.Rules {
+			if rule.Egress() {
+				fmt.Fprintf(&b, "\t\t%s accept\n", nftablesMatch(rule))
+			}
+		}
+		b.WriteString("\t}\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// nftablesMatch returns the nft expression matching the traffic a rule accepts, from its source when it's
+// ingress and to its destination when it's egress
+func nftablesMatch(rule types.HostFirewallRule) string {
+	var match []string
+	if rule.Cidr != "" {
+		address := "ip saddr "
+		if rule.Egress() {
+			address = "ip daddr "
+		}
+		match = append(match, address+nftablesAddress(rule.Cidr))
+	}
+	protocol := linuxProtocol(rule.Protocol)
+	switch protocol {
+	case "tcp", "udp":
+		ports := fmt.Sprintf("%d-%d", rule.MinPort, rule.MaxPort)
+		if rule.MinPort == rule.MaxPort {
+			ports = fmt.Sprintf("%d", rule.MinPort)
+		}
+		match = append(match, fmt.Sprintf("%s dport %s", protocol, ports))
+	default:
+		match = append(match, "meta l4proto "+protocol)
+	}
+	return strings.Join(match, " ")
+}
+
+// nftablesAddress returns cidr as nft lists it: the network address, without prefix for single hosts
+func nftablesAddress(cidr string) string {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return cidr
+	}
+	if ones, bits := network.Mask.Size(); ones == bits {
+		return network.IP.String()
+	}
+	return network.String()
+}
@@ -0,0 +1,79 @@
+// +build linux
+
+package firewall
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNftablesMatchICMP(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("ip saddr 10.0.0.0/8 meta l4proto icmp", nftablesMatch(types.HostFirewallRule{Cidr: "10.0.0.0/8", Protocol: "icmpv4", MinPort: 0, MaxPort: 0}), "icmpv4 should be given as icmp")
+	assert.Equal("ip daddr 10.1.2.3 meta l4proto icmp", nftablesMatch(types.HostFirewallRule{Cidr: "10.1.2.3/32", Protocol: "ICMP", Direction: types.DirectionEgress}), "ICMP rules shouldn't match ports")
+}
+
+func TestNftablesRuleset(t *testing.T) {
+	policy := types.HostFirewallPolicy{
+		Rules: []types.HostFirewallRule{
+			{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 22, MaxPort: 22},
+			{Cidr: "10.0.0.0/8", Protocol: "UDP", MinPort: 1000, MaxPort: 2000},
+			{Cidr: "10.0.0.0/8", Protocol: "icmp"},
+		},
+	}
+	assert.Equal(t, `table ip concerto
+delete table ip concerto
+table ip concerto {
+	chain input {
+		type filter hook input priority 0; policy drop;
+		iif lo accept
+		ct state established,related accept
+		ip saddr 0.0.0.0/0 tcp dport 22 accept
+		ip saddr 10.0.0.0/8 udp dport 1000-2000 accept
+		ip saddr 10.0.0.0/8 meta l4proto icmp accept
+	}
+}
+`, nftablesRuleset(policy), "Unexpected ruleset")
+
+	policy.Rules = append(policy.Rules, types.HostFirewallRule{Cidr: "10.1.2.3/32", Protocol: "tcp", MinPort: 443, MaxPort: 443, Direction: types.DirectionEgress})
+	assert.Contains(t, nftablesRuleset(policy), `	chain output {
+		type filter hook output priority 0; policy drop;
+		oif lo accept
+		ct state established,related accept
+		ip daddr 10.1.2.3 tcp dport 443 accept
+	}
+`, "Egress rules should be in the output chain")
+}
+
+func TestLinuxDriver(t *testing.T) {
+	assert := assert.New(t)
+
+	lookPath := func(found ...string) func(string) (string, error) {
+		return func(file string) (string, error) {
+			for _, f := range found {
+				if f == file {
+					return file, nil
+				}
+			}
+			return "", fmt.Errorf("%s not found", file)
+		}
+	}
+	legacy := func() string { return "iptables v1.6.1" }
+	shim := func() string { return "iptables v1.8.7 (nf_tables)" }
+	running := func() bool { return true }
+	stopped := func() bool { return false }
+
+	assert.Equal(DriverNftables, linuxDriver(DriverNftables, lookPath(), legacy, stopped), "Selected drivers shouldn't be detected")
+	assert.Equal(DriverIptables, linuxDriver(DriverIptables, lookPath(nftCommand), shim, stopped), "Selected drivers shouldn't be detected")
+	assert.Equal(DriverIptables, linuxDriver(DriverAuto, lookPath(iptablesCommand), shim, stopped), "iptables should be used without nft")
+	assert.Equal(DriverNftables, linuxDriver(DriverAuto, lookPath(nftCommand), legacy, stopped), "nftables should be used without iptables")
+	assert.Equal(DriverNftables, linuxDriver(DriverAuto, lookPath(nftCommand, iptablesCommand), shim, stopped), "nftables should be used instead of the iptables shim")
+	assert.Equal(DriverIptables, linuxDriver(DriverAuto, lookPath(nftCommand, iptablesCommand), legacy, stopped), "Legacy iptables should be kept")
+	assert.Equal(DriverFirewalld, linuxDriver(DriverAuto, lookPath(nftCommand, iptablesCommand, firewallCmdCommand), shim, running), "firewalld should be used while running")
+	assert.Equal(DriverNftables, linuxDriver(DriverAuto, lookPath(nftCommand, iptablesCommand, firewallCmdCommand), shim, stopped), "Stopped firewalld should be ignored")
+	assert.Equal(DriverIptables, linuxDriver(DriverIptables, lookPath(firewallCmdCommand), legacy, running), "Selected drivers shouldn't be detected")
+}
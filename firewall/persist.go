@@ -0,0 +1,231 @@
+// +build linux
+
+package firewall
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils"
+)
+
+const (
+	iptablesRestoreCommand = "/sbin/iptables-restore"
+	systemctlCommand       = "systemctl"
+
+	// persistUnit restores rules at boot where no service of the distribution does
+	persistUnitName = "concerto-firewall.service"
+	persistUnit     = "/etc/systemd/system/" + persistUnitName
+	// nftablesRulesFile holds the concerto table, as there's no common file restored by nftables
+	nftablesRulesFile = "/etc/concerto/firewall.nft"
+)
+
+// iptablesPersistence is where the distribution keeps iptables rules to be restored at boot, and the
+// service restoring them, which is empty when it isn't installed or isn't enabled
+type iptablesPersistence struct {
+	file    string
+	service string
+}
+
+// iptablesPersistenceOf returns where iptables rules are restored from in the host: netfilter-persistent
+// in Debian and Ubuntu, and iptables-services in RHEL and CentOS. Other distributions keep them as Debian.
+// A disabled service doesn't restore rules, so they're restored by the concerto unit instead
+func iptablesPersistenceOf(exists func(file string) bool, enabled func(service string) bool) iptablesPersistence {
+	p := iptablesPersistence{file: "/etc/iptables/rules.v4"}
+	service, installed := "netfilter-persistent", exists("/usr/sbin/netfilter-persistent")
+	if exists("/etc/redhat-release") || exists("/etc/sysconfig/iptables-config") {
+		p.file = "/etc/sysconfig/iptables"
+		service, installed = "iptables.service", exists("/usr/lib/systemd/system/iptables.service")
+	}
+	if installed && enabled(service) {
+		p.service = service
+	}
+	return p
+}
+
+func fileExists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
+
+// persist saves the rules applied with the driver, so that they're restored at boot. firewalld keeps
+// them in its permanent configuration already
+func persist() error {
+	switch driverName() {
+	case DriverIptables:
+		return iptablesPersist()
+	case DriverNftables:
+		return nftablesPersist()
+	}
+	return nil
+}
+
+// iptablesPersist saves every iptables rule, as the distribution does, to its rules file
+func iptablesPersist() error {
+	output, err := exec.Command(iptablesSaveCommand).Output()
+	if err != nil {
+		return fmt.Errorf("Couldn't save firewall rules: %s", err)
+	}
+	p := iptablesPersistenceOf(fileExists, distributionServiceEnabled)
+	if err = writeRulesFile(p.file, output); err != nil {
+		return err
+	}
+	if p.service != "" {
+		return nil
+	}
+	return installPersistUnit(fmt.Sprintf("%s %s", iptablesRestoreCommand, p.file))
+}
+
+// nftablesPersist saves the concerto table, replacing it as apply does when restored, or removes the
+// saved one once the table is flushed
+func nftablesPersist() error {
+	output, err := exec.Command(nftCommand, "list", "table", nftTable).Output()
+	if err != nil {
+		// the table doesn't exist once flushed
+		if err = os.Remove(nftablesRulesFile); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	rules := fmt.Sprintf("table %s\ndelete table %s\n%s", nftTable, nftTable, output)
+	if err = writeRulesFile(nftablesRulesFile, []byte(rules)); err != nil {
+		return err
+	}
+	// a missing rules file, as after a flush, isn't a failure of the unit
+	return installPersistUnit(fmt.Sprintf("-%s -f %s", nftCommand, nftablesRulesFile))
+}
+
+func writeRulesFile(file string, rules []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("Couldn't save firewall rules: %s", err)
+	}
+	if err := ioutil.WriteFile(file, rules, 0600); err != nil {
+		return fmt.Errorf("Couldn't save firewall rules: %s", err)
+	}
+	log.Debugf("Firewall rules saved to %s", file)
+	return nil
+}
+
+// persistUnitContent returns the systemd unit running restore at boot, before the network is up
+func persistUnitContent(restore string) string {
+	return fmt.Sprintf(`[Unit]
+Description=Concerto firewall rules
+Before=network-pre.target
+Wants=network-pre.target
+
+[Service]
+Type=oneshot
+ExecStart=%s
+RemainAfterExit=yes
+
+[Install]
+WantedBy=multi-user.target
+`, restore)
+}
+
+// installPersistUnit writes and enables the unit restoring rules with restore, unless it's already there
+func installPersistUnit(restore string) error {
+	content := persistUnitContent(restore)
+	if current, err := ioutil.ReadFile(persistUnit); err == nil && string(current) == content {
+		return nil
+	}
+	if _, err := exec.LookPath(systemctlCommand); err != nil {
+		return fmt.Errorf("Firewall rules were saved, but won't be restored at boot, as there's no systemd to run %s", restore)
+	}
+	if err := ioutil.WriteFile(persistUnit, []byte(content), 0644); err != nil {
+		return fmt.Errorf("Couldn't install %s: %s", persistUnit, err)
+	}
+	for _, command := range []string{systemctlCommand + " daemon-reload", systemctlCommand + " enable " + persistUnitName} {
+		if output, exit, _, _ := utils.RunCmd(command); exit != 0 {
+			return fmt.Errorf("Couldn't enable %s: (%d) %s", persistUnitName, exit, output)
+		}
+	}
+	return nil
+}
+
+// persistUnitEnabled returns whether the unit restoring rules is enabled
+func persistUnitEnabled() bool {
+	return serviceEnabled(persistUnitName)
+}
+
+// distributionServiceEnabled returns whether a service of the distribution restores rules at boot. Without
+// systemd it can't be told, and installed services are taken as enabled
+func distributionServiceEnabled(service string) bool {
+	if _, err := exec.LookPath(systemctlCommand); err != nil {
+		return true
+	}
+	return serviceEnabled(service)
+}
+
+func serviceEnabled(service string) bool {
+	output, err := exec.Command(systemctlCommand, "is-enabled", service).Output()
+	return err == nil && strings.TrimSpace(string(output)) == "enabled"
+}
+
+func status() (*Status, error) {
+	switch driverName() {
+	case DriverNftables:
+		return nftablesStatus()
+	case DriverFirewalld:
+		return firewalldStatus()
+	}
+	return iptablesStatus()
+}
+
+func iptablesStatus() (*Status, error) {
+	output, err := exec.Command(iptablesSaveCommand, "-t", "filter").Output()
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read installed rules: %s", err)
+	}
+	live := iptablesInstalledRules(string(output))
+
+	p := iptablesPersistenceOf(fileExists, distributionServiceEnabled)
+	restoredBy := p.service
+	if restoredBy == "" && persistUnitEnabled() {
+		restoredBy = persistUnitName
+	}
+	var persisted []string
+	if saved, err := ioutil.ReadFile(p.file); err == nil {
+		persisted = iptablesInstalledRules(string(saved))
+	}
+	return newStatus(DriverIptables, p.file, restoredBy, live, persisted), nil
+}
+
+func nftablesStatus() (*Status, error) {
+	// listing fails when the table doesn't exist, as it has no rules
+	output, _ := exec.Command(nftCommand, "list", "table", nftTable).Output()
+	live := nftablesInstalledRules(string(output))
+
+	restoredBy := ""
+	if persistUnitEnabled() {
+		restoredBy = persistUnitName
+	}
+	var persisted []string
+	if saved, err := ioutil.ReadFile(nftablesRulesFile); err == nil {
+		persisted = nftablesInstalledRules(string(saved))
+	} else if os.IsNotExist(err) && len(live) == 0 {
+		// nothing applied, and nothing to restore
+		persisted = []string{}
+	}
+	return newStatus(DriverNftables, nftablesRulesFile, restoredBy, live, persisted), nil
+}
+
+// firewalldStatus compares the rules firewalld is running with those of its permanent configuration,
+// which it loads at boot
+func firewalldStatus() (*Status, error) {
+	live := []string{}
+	output, err := exec.Command(firewallCmdCommand, "--zone="+firewalldZone, "--list-rich-rules").Output()
+	if err == nil {
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				live = append(live, line)
+			}
+		}
+	}
+	return newStatus(DriverFirewalld, "firewalld permanent configuration", "firewalld", live, firewalldInstalledRules()), nil
+}
@@ -0,0 +1,47 @@
+// +build linux
+
+package firewall
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIptablesPersistenceOf(t *testing.T) {
+	assert := assert.New(t)
+	exists := func(files ...string) func(string) bool {
+		return func(file string) bool {
+			for _, f := range files {
+				if f == file {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	enabled := func(service string) bool { return true }
+	disabled := func(service string) bool { return false }
+
+	assert.Equal(iptablesPersistence{file: "/etc/iptables/rules.v4", service: "netfilter-persistent"}, iptablesPersistenceOf(exists("/usr/sbin/netfilter-persistent"), enabled))
+	assert.Equal(iptablesPersistence{file: "/etc/iptables/rules.v4"}, iptablesPersistenceOf(exists(), enabled), "Missing netfilter-persistent should be reported")
+	assert.Equal(iptablesPersistence{file: "/etc/iptables/rules.v4"}, iptablesPersistenceOf(exists("/usr/sbin/netfilter-persistent"), disabled), "Disabled netfilter-persistent should be reported")
+	assert.Equal(iptablesPersistence{file: "/etc/sysconfig/iptables", service: "iptables.service"}, iptablesPersistenceOf(exists("/etc/redhat-release", "/usr/lib/systemd/system/iptables.service"), enabled))
+	assert.Equal(iptablesPersistence{file: "/etc/sysconfig/iptables"}, iptablesPersistenceOf(exists("/etc/redhat-release"), enabled), "Missing iptables-services should be reported")
+	assert.Equal(iptablesPersistence{file: "/etc/sysconfig/iptables"}, iptablesPersistenceOf(exists("/etc/redhat-release", "/usr/lib/systemd/system/iptables.service"), disabled), "Disabled iptables.service should be reported")
+
+	var checked []string
+	iptablesPersistenceOf(exists("/etc/redhat-release", "/usr/lib/systemd/system/iptables.service"), func(service string) bool {
+		checked = append(checked, service)
+		return true
+	})
+	assert.Equal([]string{"iptables.service"}, checked, "Only the service of the distribution should be checked")
+}
+
+func TestPersistUnitContent(t *testing.T) {
+	unit := persistUnitContent("/sbin/iptables-restore /etc/iptables/rules.v4")
+	assert.Contains(t, unit, "ExecStart=/sbin/iptables-restore /etc/iptables/rules.v4\n")
+	assert.Contains(t, unit, "Before=network-pre.target\n", "Rules should be restored before the network is up")
+	assert.Contains(t, unit, "WantedBy=multi-user.target\n")
+}
@@ -0,0 +1,71 @@
+// +build windows
+
+package firewall
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils"
+)
+
+// windowsRuleGroup groups the rules concerto adds with the NetSecurity cmdlets
+const windowsRuleGroup = "Concerto"
+
+func powershellApply(policy types.HostFirewallPolicy) error {
+	return powershellRun("apply", powershellScript(policy))
+}
+
+func powershellFlush() error {
+	return powershellRun("flush", fmt.Sprintf(`$ErrorActionPreference = 'Stop'
+Remove-NetFirewallRule -Group '%s' -ErrorAction SilentlyContinue
+Set-NetFirewallProfile -All -DefaultInboundAction Allow -DefaultOutboundAction Allow
+`, windowsRuleGroup))
+}
+
+// powershellRun runs script encoded, so that it needs no quoting, and stops at the first failing cmdlet
+func powershellRun(action string, script string) error {
+	command := fmt.Sprintf("powershell -NoProfile -NonInteractive -EncodedCommand %s", powershellEncode(script))
+	if output, exit, _, _ := utils.RunCmd(command); exit != 0 {
+		return fmt.Errorf("Error executing firewall %s: (%d) %s", action, exit, output)
+	}
+	return nil
+}
+
+// powershellScript returns the script replacing concerto rules with the ones of policy, and blocking any
+// other inbound traffic once they've been added
+func powershellScript(policy types.HostFirewallPolicy) string {
+	var b bytes.Buffer
+	b.WriteString("$ErrorActionPreference = 'Stop'\n")
+	fmt.Fprintf(&b, "Remove-NetFirewallRule -Group '%s' -ErrorAction SilentlyContinue\n", windowsRuleGroup)
+	for _, rule := range policy.Rules {
+		fmt.Fprintf(&b, "New-NetFirewallRule -DisplayName '%s' -Group '%s' -Direction Inbound -Action Allow -RemoteAddress '%s' -Protocol '%s'",
+			windowsRuleName, windowsRuleGroup, powershellQuote(rule.Cidr), powershellQuote(strings.ToUpper(rule.Protocol)))
+		if ports := windowsPorts(rule); ports != "" {
+			fmt.Fprintf(&b, " -LocalPort '%s'", ports)
+		}
+		b.WriteString(" | Out-Null\n")
+	}
+	b.WriteString("Set-NetFirewallProfile -All -Enabled True -DefaultInboundAction Block -DefaultOutboundAction Allow\n")
+	return b.String()
+}
+
+// powershellQuote escapes s to be used inside single quotes
+func powershellQuote(s string) string {
+	return strings.Replace(s, "'", "''", -1)
+}
+
+// powershellEncode returns script as expected by -EncodedCommand: base64 of its UTF-16LE bytes
+func powershellEncode(script string) string {
+	units := utf16.Encode([]rune(script))
+	data := make([]byte, 2*len(units))
+	for i, u := range units {
+		data[2*i] = byte(u)
+		data[2*i+1] = byte(u >> 8)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
@@ -0,0 +1,260 @@
+package firewall
+
+import (
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/client"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/flags"
+	"github.com/flexiant/concerto/utils/format"
+)
+
+// Rule states, telling whether rules of the firewall profile are applied in host
+const (
+	RuleApplied = "applied"
+	RulePending = "pending"
+	// RuleStale rules are applied in host, but no longer in its firewall profile
+	RuleStale = "stale"
+)
+
+// RuleStatus is a rule of the host firewall and whether it's applied
+type RuleStatus struct {
+	Cidr      string `json:"cidr_ip" header:"CIDR"`
+	Protocol  string `json:"ip_protocol" header:"PROTOCOL"`
+	MinPort   int    `json:"min_port" header:"MIN"`
+	MaxPort   int    `json:"max_port" header:"MAX"`
+	Direction string `json:"direction,omitempty" header:"DIRECTION"`
+	State     string `json:"state" header:"STATE"`
+}
+
+// ruleStatuses returns the rules of policy, applied or pending, followed by the applied rules no longer in it
+func ruleStatuses(policy types.HostFirewallPolicy) []RuleStatus {
+	statuses := []RuleStatus{}
+	status := func(r types.HostFirewallRule, state string) RuleStatus {
+		direction := types.DirectionIngress
+		if r.Egress() {
+			direction = types.DirectionEgress
+		}
+		return RuleStatus{Cidr: r.Cidr, Protocol: r.Protocol, MinPort: r.MinPort, MaxPort: r.MaxPort, Direction: direction, State: state}
+	}
+	for _, r := range policy.Rules {
+		state := RulePending
+		if containsRule(policy.ActualRules, r) {
+			state = RuleApplied
+		}
+		statuses = append(statuses, status(r, state))
+	}
+	for _, r := range policy.ActualRules {
+		if !containsRule(policy.Rules, r) {
+			statuses = append(statuses, status(r, RuleStale))
+		}
+	}
+	return statuses
+}
+
+func containsRule(rules []types.HostFirewallRule, rule types.HostFirewallRule) bool {
+	for _, r := range rules {
+		if r.Normalized() == rule.Normalized() {
+			return true
+		}
+	}
+	return false
+}
+
+// addRule returns rules with rule appended, and whether it wasn't there yet
+func addRule(rules []types.HostFirewallRule, rule types.HostFirewallRule) ([]types.HostFirewallRule, bool) {
+	if containsRule(rules, rule) {
+		return rules, false
+	}
+	return append(append([]types.HostFirewallRule{}, rules...), rule), true
+}
+
+// removeRule returns rules without rule, and whether it was there
+func removeRule(rules []types.HostFirewallRule, rule types.HostFirewallRule) ([]types.HostFirewallRule, bool) {
+	kept := []types.HostFirewallRule{}
+	for _, r := range rules {
+		if r.Normalized() != rule.Normalized() {
+			kept = append(kept, r)
+		}
+	}
+	return kept, len(kept) != len(rules)
+}
+
+// ruleEditor reads, applies and updates firewall policies. Its functions are replaced in tests
+type ruleEditor struct {
+	get    func() (*types.HostFirewallPolicy, error)
+	apply  func(policy types.HostFirewallPolicy) error
+	update func(policy types.HostFirewallPolicy) error
+}
+
+// edit applies in host the rules of the policy changed by fn, and then updates the policy in Concerto.
+// Policies changed in Concerto meanwhile aren't overwritten: the host is left with their rules, and the
+// conflict is returned. It returns whether the policy changed
+func (e *ruleEditor) edit(fn func(rules []types.HostFirewallRule) ([]types.HostFirewallRule, bool)) (bool, error) {
+	read, err := e.get()
+	if err != nil {
+		return false, err
+	}
+	rules, changed := fn(read.Rules)
+	if !changed {
+		return false, nil
+	}
+	edited := types.HostFirewallPolicy{Rules: rules}
+	if err = edited.Validate(); err != nil {
+		return false, exit.NewValidationError(err)
+	}
+
+	if err = e.applyRules(edited); err != nil {
+		return false, err
+	}
+
+	current, err := e.get()
+	if err != nil {
+		return false, err
+	}
+	if !sameRules(current.Rules, read.Rules) {
+		log.Warn("Restoring the firewall rules of the profile in Concerto")
+		if err = e.applyRules(*current); err != nil {
+			log.Errorf("Couldn't restore firewall rules: %s", err)
+		}
+		return false, fmt.Errorf("Firewall profile was changed in Concerto while editing it. Please, review its rules and try again")
+	}
+	return true, e.update(edited)
+}
+
+// applyRules applies policy in host. Hosts aren't left without rules, as in apply
+func (e *ruleEditor) applyRules(policy types.HostFirewallPolicy) error {
+	if len(policy.Rules) == 0 {
+		log.Warn("Firewall profile has no rules. Rules applied in host are kept")
+		return nil
+	}
+	return e.apply(policy)
+}
+
+// defaultRuleEditor edits the policy of the host in Concerto with the selected driver
+func defaultRuleEditor() (*ruleEditor, error) {
+	hc, err := client.Default()
+	if err != nil {
+		return nil, err
+	}
+	checkedApply := func(policy types.HostFirewallPolicy) error {
+		if err := checkDriver(policy); err != nil {
+			return err
+		}
+		return apply(policy)
+	}
+	return &ruleEditor{get: get, apply: checkedApply, update: hc.UpdateFirewallPolicy}, nil
+}
+
+// ruleFromFlags returns the rule given with flags, failing with every missing or invalid flag
+func ruleFromFlags(c *cli.Context) (types.HostFirewallRule, error) {
+	if err := flags.New(c).Required("cidr", "ipProtocol").Err(); err != nil {
+		return types.HostFirewallRule{}, exit.NewValidationError(err)
+	}
+	rule := types.HostFirewallRule{
+		Cidr:      c.String("cidr"),
+		Protocol:  c.String("ipProtocol"),
+		MinPort:   c.Int("minPort"),
+		MaxPort:   c.Int("maxPort"),
+		Direction: c.String("direction"),
+	}
+	if err := rule.Validate(); err != nil {
+		return rule, exit.NewValidationError(err)
+	}
+	return rule.Normalized(), nil
+}
+
+func cmdRulesList(c *cli.Context) error {
+	policy, err := get()
+	if err != nil {
+		return err
+	}
+	return format.GetFormatter().PrintList(ruleStatuses(*policy))
+}
+
+func cmdRulesAdd(c *cli.Context) error {
+	return editRule(c, addRule, "Rule is already in the firewall profile")
+}
+
+func cmdRulesRemove(c *cli.Context) error {
+	return editRule(c, removeRule, "Rule isn't in the firewall profile")
+}
+
+// editRule changes the rule given with flags in host and in Concerto
+func editRule(c *cli.Context, fn func([]types.HostFirewallRule, types.HostFirewallRule) ([]types.HostFirewallRule, bool), unchanged string) error {
+	rule, err := ruleFromFlags(c)
+	if err != nil {
+		return err
+	}
+	editor, err := defaultRuleEditor()
+	if err != nil {
+		return err
+	}
+	changed, err := editor.edit(func(rules []types.HostFirewallRule) ([]types.HostFirewallRule, bool) {
+		return fn(rules, rule)
+	})
+	if err != nil {
+		return err
+	}
+	if !changed {
+		log.Info(unchanged)
+	}
+	return nil
+}
+
+// ruleFlags are the flags identifying a single rule
+func ruleFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  "cidr",
+			Usage: "CIDR",
+		},
+		cli.IntFlag{
+			Name:  "minPort",
+			Usage: "Minimum Port",
+		},
+		cli.IntFlag{
+			Name:  "maxPort",
+			Usage: "Maximum Port",
+		},
+		cli.StringFlag{
+			Name:  "ipProtocol",
+			Usage: "Ip protocol udp, tcp or icmp",
+		},
+		cli.StringFlag{
+			Name:  "direction",
+			Usage: "Traffic accepted, ingress from the CIDR or egress to it",
+			Value: types.DirectionIngress,
+		},
+	}
+}
+
+// rulesCommand manages single rules, applying them in host and updating the firewall profile in Concerto
+func rulesCommand() cli.Command {
+	return cli.Command{
+		Name:  "rules",
+		Usage: "Manages single firewall rules, applied in host and updated in its Concerto firewall profile",
+		Subcommands: []cli.Command{
+			{
+				Name:   "list",
+				Usage:  "Lists the firewall rules of host, and whether they're applied",
+				Action: cmdRulesList,
+			},
+			{
+				Name:   "add",
+				Usage:  "Applies a firewall rule in host and adds it to its firewall profile",
+				Action: cmdRulesAdd,
+				Flags:  ruleFlags(),
+			},
+			{
+				Name:   "remove",
+				Usage:  "Removes a firewall rule from host and from its firewall profile",
+				Action: cmdRulesRemove,
+				Flags:  ruleFlags(),
+			},
+		},
+	}
+}
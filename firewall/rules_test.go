@@ -0,0 +1,149 @@
+package firewall
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	https = types.HostFirewallRule{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 443, MaxPort: 443}
+	ssh   = types.HostFirewallRule{Cidr: "10.0.0.0/8", Protocol: "tcp", MinPort: 22, MaxPort: 22}
+	http  = types.HostFirewallRule{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 80, MaxPort: 80}
+)
+
+// fakeEditor returns an editor reading policies in turn, recording the policies applied and updated
+func fakeEditor(policies ...types.HostFirewallPolicy) (e *ruleEditor, applied *[][]types.HostFirewallRule, updated *[][]types.HostFirewallRule) {
+	applied = &[][]types.HostFirewallRule{}
+	updated = &[][]types.HostFirewallRule{}
+	e = &ruleEditor{
+		get: func() (*types.HostFirewallPolicy, error) {
+			policy := policies[0]
+			if len(policies) > 1 {
+				policies = policies[1:]
+			}
+			return &policy, nil
+		},
+		apply: func(policy types.HostFirewallPolicy) error {
+			*applied = append(*applied, policy.Rules)
+			return nil
+		},
+		update: func(policy types.HostFirewallPolicy) error {
+			*updated = append(*updated, policy.Rules)
+			return nil
+		},
+	}
+	return e, applied, updated
+}
+
+func add(rule types.HostFirewallRule) func([]types.HostFirewallRule) ([]types.HostFirewallRule, bool) {
+	return func(rules []types.HostFirewallRule) ([]types.HostFirewallRule, bool) { return addRule(rules, rule) }
+}
+
+func remove(rule types.HostFirewallRule) func([]types.HostFirewallRule) ([]types.HostFirewallRule, bool) {
+	return func(rules []types.HostFirewallRule) ([]types.HostFirewallRule, bool) { return removeRule(rules, rule) }
+}
+
+func TestEditRuleAppliesAndUpdates(t *testing.T) {
+	assert := assert.New(t)
+
+	e, applied, updated := fakeEditor(types.HostFirewallPolicy{Rules: []types.HostFirewallRule{https}})
+	changed, err := e.edit(add(ssh))
+	assert.Nil(err, "Adding a rule shouldn't fail")
+	assert.True(changed, "Adding a new rule should change the policy")
+	assert.Equal([][]types.HostFirewallRule{{https, ssh}}, *applied, "Rule should be applied in host")
+	assert.Equal([][]types.HostFirewallRule{{https, ssh}}, *updated, "Rule should be updated in Concerto")
+
+	e, applied, updated = fakeEditor(types.HostFirewallPolicy{Rules: []types.HostFirewallRule{https, ssh}})
+	changed, err = e.edit(remove(https))
+	assert.Nil(err, "Removing a rule shouldn't fail")
+	assert.True(changed, "Removing a rule should change the policy")
+	assert.Equal([][]types.HostFirewallRule{{ssh}}, *applied, "Rule should be removed from host")
+	assert.Equal([][]types.HostFirewallRule{{ssh}}, *updated, "Rule should be removed in Concerto")
+}
+
+func TestEditRuleUnchanged(t *testing.T) {
+	assert := assert.New(t)
+
+	e, applied, updated := fakeEditor(types.HostFirewallPolicy{Rules: []types.HostFirewallRule{https}})
+	changed, err := e.edit(add(https))
+	assert.Nil(err, "Adding an existing rule shouldn't fail")
+	assert.False(changed, "Adding an existing rule shouldn't change the policy")
+
+	changed, err = e.edit(remove(ssh))
+	assert.Nil(err, "Removing a missing rule shouldn't fail")
+	assert.False(changed, "Removing a missing rule shouldn't change the policy")
+	assert.Empty(*applied, "Nothing should be applied")
+	assert.Empty(*updated, "Nothing should be updated")
+}
+
+func TestEditRuleKeepsHostWithRules(t *testing.T) {
+	assert := assert.New(t)
+
+	e, applied, updated := fakeEditor(types.HostFirewallPolicy{Rules: []types.HostFirewallRule{https}})
+	changed, err := e.edit(remove(https))
+	assert.Nil(err, "Removing the last rule shouldn't fail")
+	assert.True(changed, "Removing the last rule should change the policy")
+	assert.Empty(*applied, "Hosts shouldn't be left without rules")
+	assert.Equal([][]types.HostFirewallRule{{}}, *updated, "Rule should be removed in Concerto")
+}
+
+func TestEditRuleConflict(t *testing.T) {
+	assert := assert.New(t)
+
+	e, applied, updated := fakeEditor(
+		types.HostFirewallPolicy{Rules: []types.HostFirewallRule{https}},
+		types.HostFirewallPolicy{Rules: []types.HostFirewallRule{https, http}},
+	)
+	changed, err := e.edit(add(ssh))
+	assert.Error(err, "Policies changed meanwhile shouldn't be overwritten")
+	assert.False(changed, "Conflicts shouldn't change the policy")
+	assert.Equal([][]types.HostFirewallRule{{https, ssh}, {https, http}}, *applied, "Rules in Concerto should be applied again")
+	assert.Empty(*updated, "Nothing should be updated")
+}
+
+func TestEditRuleInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	e, applied, _ := fakeEditor(types.HostFirewallPolicy{})
+	_, err := e.edit(add(types.HostFirewallRule{Cidr: "any", Protocol: "tcp"}))
+	assert.Error(err, "Invalid rules shouldn't be applied")
+	assert.Empty(*applied, "Nothing should be applied")
+
+	e.apply = func(policy types.HostFirewallPolicy) error { return fmt.Errorf("iptables failed") }
+	_, err = e.edit(add(ssh))
+	assert.EqualError(err, "iptables failed", "Errors applying rules should be returned")
+}
+
+func TestRuleStatuses(t *testing.T) {
+	assert := assert.New(t)
+
+	statuses := ruleStatuses(types.HostFirewallPolicy{
+		Rules:       []types.HostFirewallRule{https, ssh},
+		ActualRules: []types.HostFirewallRule{http, https},
+	})
+	assert.Equal([]RuleStatus{
+		{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 443, MaxPort: 443, Direction: types.DirectionIngress, State: RuleApplied},
+		{Cidr: "10.0.0.0/8", Protocol: "tcp", MinPort: 22, MaxPort: 22, Direction: types.DirectionIngress, State: RulePending},
+		{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 80, MaxPort: 80, Direction: types.DirectionIngress, State: RuleStale},
+	}, statuses, "Unexpected rule states")
+}
+
+func TestRuleDirections(t *testing.T) {
+	assert := assert.New(t)
+
+	explicit := https
+	explicit.Direction = "Ingress"
+	egress := https
+	egress.Direction = types.DirectionEgress
+
+	assert.True(containsRule([]types.HostFirewallRule{https}, explicit), "Rules without direction should be ingress")
+	assert.False(containsRule([]types.HostFirewallRule{https}, egress), "Egress rules should differ from ingress ones")
+	assert.True(sameRules([]types.HostFirewallRule{explicit, egress}, []types.HostFirewallRule{egress, https}), "Directions should compare regardless of how they're given")
+
+	rules, changed := removeRule([]types.HostFirewallRule{https, egress}, egress)
+	assert.True(changed, "Egress rules should be removed")
+	assert.Equal([]types.HostFirewallRule{https}, rules, "Only the egress rule should be removed")
+}
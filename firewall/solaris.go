@@ -7,39 +7,62 @@ import (
 
 	"os"
 
+	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/utils"
 )
 
+// drivers lists the firewall drivers supported in Solaris, where ipfilter is used
+var drivers = []string{DriverAuto}
+
 func driverName() string {
-	return "iptables"
+	return "ipfilter"
 }
 
-func apply(policy Policy) error {
+const (
+	ipfConfig  = "/etc/ipf/ipf.conf"
+	ipfCommand = "svcadm enable ipfilter; svcadm restart ipfilter; ipf -Fa -f /etc/ipf/ipf.conf"
+)
+
+func apply(policy types.HostFirewallPolicy) error {
 
 	// NO!
-	f, err := os.Create("/etc/ipf/ipf.conf")
+	f, err := os.Create(ipfConfig)
 
 	if err != nil {
 		return fmt.Errorf("Error opening /etc/ipf/ipf.conf : %s", err)
 	}
 	defer f.Close()
 
-	f.WriteString("pass out on net0 from any to any keep state\n")
-	f.WriteString("pass in quick on net0 proto icmp from any to any keep state\n")
-
-	for _, rule := range policy.Rules {
-		f.WriteString(fmt.Sprintf("pass in quick on net0 proto %s from %s to any %s\n", rule.Protocol, rule.Cidr, determinePort(rule.MinPort, rule.MaxPort)))
+	for _, rule := range ipfRules(policy) {
+		f.WriteString(rule + "\n")
 	}
 
-	f.WriteString("block in on net0 from any to any\n")
-
-	if output, exit, _, _ := utils.RunCmd("svcadm enable ipfilter; svcadm restart ipfilter; ipf -Fa -f /etc/ipf/ipf.conf"); exit != 0 {
+	if output, exit, _, _ := utils.RunCmd(ipfCommand); exit != 0 {
 		return fmt.Errorf("Error executing firewall enable: (%d) %s", exit, output)
 	}
 
 	return nil
 }
 
+func plan(policy types.HostFirewallPolicy) (*Plan, error) {
+	commands := []string{fmt.Sprintf("cat > %s <<EOF", ipfConfig)}
+	commands = append(commands, ipfRules(policy)...)
+	commands = append(commands, "EOF", ipfCommand)
+	return &Plan{Driver: "ipfilter", Commands: commands}, nil
+}
+
+// ipfRules returns the ipfilter configuration allowing the rules of policy
+func ipfRules(policy types.HostFirewallPolicy) []string {
+	rules := []string{
+		"pass out on net0 from any to any keep state",
+		"pass in quick on net0 proto icmp from any to any keep state",
+	}
+	for _, rule := range policy.Rules {
+		rules = append(rules, fmt.Sprintf("pass in quick on net0 proto %s from %s to any %s", rule.Protocol, rule.Cidr, determinePort(rule.MinPort, rule.MaxPort)))
+	}
+	return append(rules, "block in on net0 from any to any")
+}
+
 func determinePort(min, max int) string {
 	if min == max {
 		return fmt.Sprintf("port = %d", min)
@@ -63,3 +86,9 @@ func flush() error {
 	}
 	return nil
 }
+
+// status reports the ipfilter configuration, which the ipfilter service loads at boot. Loaded rules
+// aren't read back, as ipfstat lists them in another form
+func status() (*Status, error) {
+	return &Status{Driver: driverName(), Persistence: PersistenceUnknown, PersistedTo: ipfConfig, RestoredBy: "svc:/network/ipfilter"}, nil
+}
@@ -0,0 +1,71 @@
+package firewall
+
+import (
+	"fmt"
+
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/format"
+)
+
+// Persistence states, telling whether rules applied in host are restored after a reboot
+const (
+	PersistenceInSync = "in_sync"
+	// PersistenceDrift rules are persisted, but differ from the ones applied
+	PersistenceDrift = "drift"
+	PersistenceNone  = "not_persisted"
+	// PersistenceUnknown is the state of drivers whose rules can't be read back
+	PersistenceUnknown = "unknown"
+)
+
+// Status tells whether the rules applied in host, in the syntax of the driver, are the ones restored at boot
+type Status struct {
+	Driver      string `json:"driver" header:"DRIVER"`
+	Persistence string `json:"persistence" header:"PERSISTENCE"`
+	// PersistedTo is where rules are saved, and RestoredBy what applies them at boot
+	PersistedTo  string   `json:"persisted_to" header:"PERSISTED TO"`
+	RestoredBy   string   `json:"restored_by" header:"RESTORED BY"`
+	LiveRules    int      `json:"live_rules" header:"LIVE RULES"`
+	NotPersisted []string `json:"not_persisted" header:"NOT PERSISTED"`
+	NotApplied   []string `json:"not_applied" header:"NOT APPLIED"`
+}
+
+// newStatus compares the live rules with the persisted ones, which are nil when nothing is persisted
+// or when nothing restores them at boot
+func newStatus(driver string, persistedTo string, restoredBy string, live []string, persisted []string) *Status {
+	s := &Status{Driver: driver, PersistedTo: persistedTo, RestoredBy: restoredBy, LiveRules: len(live)}
+	if persisted == nil || restoredBy == "" {
+		s.Persistence = PersistenceNone
+		s.NotPersisted = live
+		return s
+	}
+	s.NotPersisted, s.NotApplied = diffRules(persisted, live)
+	s.Persistence = PersistenceInSync
+	if len(s.NotPersisted) > 0 || len(s.NotApplied) > 0 {
+		s.Persistence = PersistenceDrift
+	}
+	return s
+}
+
+func cmdStatus(c *cli.Context) error {
+	s, err := status()
+	if err != nil {
+		return err
+	}
+	if err = format.GetFormatter().PrintItem(*s); err != nil {
+		return err
+	}
+	// checks and scripts tell from the exit code whether a reboot would change the rules
+	if s.Persistence == PersistenceDrift || s.Persistence == PersistenceNone {
+		return &exit.Error{Err: fmt.Errorf("Firewall rules applied in host won't be restored as they are after a reboot"), Code: exit.Failure}
+	}
+	return nil
+}
+
+func statusCommand() cli.Command {
+	return cli.Command{
+		Name:   "status",
+		Usage:  "Shows whether the firewall rules applied in host are persisted, and restored as they are after a reboot",
+		Action: cmdStatus,
+	}
+}
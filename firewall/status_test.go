@@ -0,0 +1,27 @@
+package firewall
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newStatus("iptables", "/etc/iptables/rules.v4", "netfilter-persistent", []string{"a", "b"}, []string{"b", "a"})
+	assert.Equal(PersistenceInSync, s.Persistence, "Same rules in another order should be in sync")
+	assert.Equal(2, s.LiveRules)
+
+	s = newStatus("iptables", "/etc/iptables/rules.v4", "netfilter-persistent", []string{"a", "c"}, []string{"a", "b"})
+	assert.Equal(PersistenceDrift, s.Persistence)
+	assert.Equal([]string{"c"}, s.NotPersisted)
+	assert.Equal([]string{"b"}, s.NotApplied)
+
+	s = newStatus("iptables", "/etc/iptables/rules.v4", "", []string{"a"}, []string{"a"})
+	assert.Equal(PersistenceNone, s.Persistence, "Rules nothing restores shouldn't be persisted")
+
+	s = newStatus("nftables", "/etc/concerto/firewall.nft", "concerto-firewall.service", []string{"a"}, nil)
+	assert.Equal(PersistenceNone, s.Persistence, "Rules never saved shouldn't be persisted")
+	assert.Equal([]string{"a"}, s.NotPersisted)
+}
@@ -3,30 +3,46 @@
 package firewall
 
 import (
-	"fmt"
-	"github.com/flexiant/concerto/utils"
+	"strings"
+
+	"github.com/flexiant/concerto/api/types"
 )
 
+// drivers lists the firewall drivers supported in Windows. Auto uses netsh, available in every version
+var drivers = []string{DriverAuto, DriverNetsh, DriverPowerShell}
+
 func driverName() string {
-	return "windows"
+	if driver == DriverPowerShell {
+		return DriverPowerShell
+	}
+	return DriverNetsh
 }
 
-func apply(policy Policy) error {
-	utils.RunCmd("netsh advfirewall set allprofiles state off")
-	utils.RunCmd("netsh advfirewall set allprofiles firewallpolicy blockinbound,allowoutbound")
-	utils.RunCmd("netsh advfirewall firewall delete rule name=all")
-
-	for _, rule := range policy.Rules {
-		utils.RunCmd(fmt.Sprintf("netsh advfirewall firewall add rule name=\"Concerto firewall\" dir=in action=allow remoteip=#{%s} protocol=#{%s} localport=#{%d}-#{%s}", rule.Cidr, rule.Protocol, rule.MinPort, rule.MaxPort))
+func apply(policy types.HostFirewallPolicy) error {
+	if driverName() == DriverPowerShell {
+		return powershellApply(policy)
 	}
+	return netshApply(policy)
+}
 
-	utils.RunCmd("netsh advfirewall set allprofiles state on")
-	return nil
+// plan shows the commands of the driver. Windows firewall rules aren't read back, as they
+// carry no port ranges or sources in a form comparable with the policy
+func plan(policy types.HostFirewallPolicy) (*Plan, error) {
+	if driverName() == DriverPowerShell {
+		return &Plan{Driver: DriverPowerShell, Commands: strings.Split(strings.TrimSuffix(powershellScript(policy), "\n"), "\n")}, nil
+	}
+	return &Plan{Driver: DriverNetsh, Commands: append([]string{netshFlushRulesCommand}, netshCommands(policy)...)}, nil
 }
 
 func flush() error {
-	utils.RunCmd("netsh advfirewall set allprofiles state off")
-	utils.RunCmd("netsh advfirewall set allprofiles firewallpolicy allowinbound,allowoutbound")
-	utils.RunCmd("netsh advfirewall firewall delete rule name=all")
-	return nil
+	if driverName() == DriverPowerShell {
+		return powershellFlush()
+	}
+	return netshFlush()
+}
+
+// status reports where rules are kept: Windows Firewall keeps the rules added with netsh and PowerShell
+// across reboots. Their state is unknown, as they aren't read back, as plan explains
+func status() (*Status, error) {
+	return &Status{Driver: driverName(), Persistence: PersistenceUnknown, PersistedTo: "Windows Firewall", RestoredBy: "Windows Firewall"}, nil
 }
@@ -0,0 +1,45 @@
+// +build windows
+
+package firewall
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var windowsPolicy = types.HostFirewallPolicy{
+	Rules: []types.HostFirewallRule{
+		{Cidr: "0.0.0.0/0", Protocol: "tcp", MinPort: 3389, MaxPort: 3389},
+		{Cidr: "10.0.0.0/8", Protocol: "UDP", MinPort: 1000, MaxPort: 2000},
+		{Cidr: "10.0.0.0/8", Protocol: "icmpv4"},
+	},
+}
+
+func TestNetshCommands(t *testing.T) {
+	assert.Equal(t, []string{
+		`netsh advfirewall firewall add rule name="Concerto firewall" dir=in action=allow remoteip=0.0.0.0/0 protocol=tcp localport=3389`,
+		`netsh advfirewall firewall add rule name="Concerto firewall" dir=in action=allow remoteip=10.0.0.0/8 protocol=udp localport=1000-2000`,
+		`netsh advfirewall firewall add rule name="Concerto firewall" dir=in action=allow remoteip=10.0.0.0/8 protocol=icmpv4`,
+		"netsh advfirewall set allprofiles firewallpolicy blockinbound,allowoutbound",
+		"netsh advfirewall set allprofiles state on",
+	}, netshCommands(windowsPolicy), "Unexpected netsh commands")
+}
+
+func TestPowershellScript(t *testing.T) {
+	assert.Equal(t, `$ErrorActionPreference = 'Stop'
+Remove-NetFirewallRule -Group 'Concerto' -ErrorAction SilentlyContinue
+New-NetFirewallRule -DisplayName 'Concerto firewall' -Group 'Concerto' -Direction Inbound -Action Allow -RemoteAddress '0.0.0.0/0' -Protocol 'TCP' -LocalPort '3389' | Out-Null
+New-NetFirewallRule -DisplayName 'Concerto firewall' -Group 'Concerto' -Direction Inbound -Action Allow -RemoteAddress '10.0.0.0/8' -Protocol 'UDP' -LocalPort '1000-2000' | Out-Null
+New-NetFirewallRule -DisplayName 'Concerto firewall' -Group 'Concerto' -Direction Inbound -Action Allow -RemoteAddress '10.0.0.0/8' -Protocol 'ICMPV4' | Out-Null
+Set-NetFirewallProfile -All -Enabled True -DefaultInboundAction Block -DefaultOutboundAction Allow
+`, powershellScript(windowsPolicy), "Unexpected PowerShell script")
+}
+
+func TestPowershellEncode(t *testing.T) {
+	data, err := base64.StdEncoding.DecodeString(powershellEncode("ls é"))
+	assert.Nil(t, err, "Encoded command should be base64")
+	assert.Equal(t, []byte{'l', 0, 's', 0, ' ', 0, 0xe9, 0}, data, "Encoded command should be UTF-16LE")
+}
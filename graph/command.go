@@ -0,0 +1,30 @@
+package graph
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+)
+
+// Command returns the graph CLI command
+func Command() cli.Command {
+	return cli.Command{
+		Name:   "graph",
+		Usage:  "Renders templates, their scripts, servers, workspaces, firewall profiles and DNS records as a graph",
+		Action: cmd.GraphExport,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "output",
+				Usage: "Graph format [ dot | mermaid ]",
+				Value: "dot",
+			},
+			cli.StringFlag{
+				Name:  "file",
+				Usage: "File where the graph is written. Defaults to standard output",
+			},
+			cli.BoolFlag{
+				Name:  "skip-dns",
+				Usage: "Don't include DNS records, which are requested server by server",
+			},
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package importer
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+)
+
+// SubCommands return CLI subcommands
+func SubCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:      "chef-role",
+			Usage:     "Creates a template from a Chef role, using its run list as service list and its attributes as configuration attributes.",
+			ArgsUsage: "<role.json>",
+			Action:    cmd.ImportChefRole,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "name",
+					Usage: "Name of the template. Defaults to the role name",
+				},
+				cli.StringFlag{
+					Name:  "generic_image_id",
+					Usage: "Identifier of the OS image that the template builds on",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Show the template without creating it",
+				},
+			},
+		},
+		{
+			Name:      "terraform",
+			Usage:     "Writes a server manifest, read by servers create -f, from the instances of a plan or state exported with terraform show -json.",
+			ArgsUsage: "<plan.json>",
+			Action:    cmd.ImportTerraform,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "YAML server manifest to be written",
+					Value: "servers.yaml",
+				},
+				cli.StringFlag{
+					Name:  "template",
+					Usage: "Template name or Id of servers whose template isn't known",
+				},
+				cli.StringFlag{
+					Name:  "workspace",
+					Usage: "Workspace name or Id of servers whose workspace isn't known",
+				},
+				cli.StringFlag{
+					Name:  "ssh_profile",
+					Usage: "SSH profile name or Id of servers whose SSH profile isn't known",
+				},
+				cli.StringSliceFlag{
+					Name:  "plan",
+					Usage: "Server plan Id of an instance size, as size=server_plan_id. Can be repeated",
+				},
+			},
+		},
+	}
+}
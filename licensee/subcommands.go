@@ -21,6 +21,10 @@ func SubCommands() []cli.Command {
 					Name:  "id",
 					Usage: "Report id",
 				},
+				cli.StringFlag{
+					Name:  "upload",
+					Usage: "S3 compatible location, such as s3://bucket/prefix, where the report is uploaded",
+				},
 			},
 		},
 	}
@@ -11,6 +11,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists all existing firewall profiles",
 			Action: cmd.FirewallProfileList,
+			Flags:  cmd.ListFlags(),
 		},
 		{
 			Name:   "show",
@@ -70,9 +71,9 @@ func SubCommands() []cli.Command {
 			Usage:  "Destroy a firewall profile",
 			Action: cmd.FirewallProfileDelete,
 			Flags: []cli.Flag{
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "id",
-					Usage: "Firewall profile Id",
+					Usage: "Firewall profile Id. Repeat it to delete several firewall profiles",
 				},
 			},
 		},
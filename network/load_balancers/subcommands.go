@@ -11,6 +11,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists all available load balancers",
 			Action: cmd.LoadBalancerList,
+			Flags:  cmd.ListFlags(),
 		},
 		{
 			Name:   "show",
@@ -110,9 +111,9 @@ func SubCommands() []cli.Command {
 			Usage:  "Destroys a load balancer",
 			Action: cmd.LoadBalancerDelete,
 			Flags: []cli.Flag{
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "id",
-					Usage: "Load balancer Id",
+					Usage: "Load balancer Id. Repeat it to delete several load balancers",
 				},
 			},
 		},
@@ -120,12 +121,12 @@ func SubCommands() []cli.Command {
 			Name:   "list_balancer_nodes",
 			Usage:  "This action provides information about the nodes of the load balancer identified by the given id.",
 			Action: cmd.LBNodeList,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "balancer_id",
 					Usage: "Load balancer Id",
 				},
-			},
+			}, cmd.ListFlags()...),
 		},
 		{
 			Name:   "add_balancer_node",
@@ -155,9 +156,9 @@ func SubCommands() []cli.Command {
 					Name:  "balancer_id",
 					Usage: "Load balancer Id",
 				},
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "node_id",
-					Usage: "Identifier of the node",
+					Usage: "Identifier of the node. Repeat it to remove several nodes",
 				},
 			},
 		},
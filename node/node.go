@@ -2,7 +2,6 @@ package node
 
 import (
 	"bufio"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -14,42 +13,24 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/api/client"
+	"github.com/flexiant/concerto/api/types"
 	"github.com/flexiant/concerto/utils"
-	"github.com/flexiant/concerto/webservice"
 )
 
-type Node struct {
-	Id        string `json:"id"`
-	Name      string `json:"name"`
-	Fqdn      string `json:"fqdn"`
-	PublicIp  string `json:"public_ip"`
-	State     string `json:"state"`
-	Os        string `json:"os"`
-	Plan      string `json:"plan"`
-	FleetName string `json:"fleet_name"`
-	Master    bool   `json:"is_master"`
-}
-
-func cmdCreate(c *cli.Context) {
+func cmdCreate(c *cli.Context) error {
 	utils.FlagsRequired(c, []string{"cluster", "plan"})
 
-	webservice, err := webservice.NewWebService()
-	utils.CheckError(err)
-
-	v := make(map[string]string)
-
-	v["fleet_name"] = c.String("cluster")
-	v["plan"] = c.String("plan")
-
-	json, err := json.Marshal(v)
-	utils.CheckError(err)
-	err, res, code := webservice.Post("/v1/kaas/ships", json)
-	if res == nil {
-		log.Fatal(err)
+	hc, err := client.Default()
+	if err != nil {
+		return err
 	}
-	utils.CheckError(err)
-	utils.CheckReturnCode(code, res)
 
+	_, err = hc.Nodes.CreateNode(&map[string]interface{}{
+		"fleet_name": c.String("cluster"),
+		"plan":       c.String("plan"),
+	})
+	return err
 }
 
 // func cmdStart(c *cli.Context) {
@@ -130,8 +111,7 @@ func cmdCreate(c *cli.Context) {
 
 func cmdDockerHijack(c *cli.Context) error {
 
-	var nodes []Node
-	var node Node
+	var node types.Node
 
 	discovered := false
 
@@ -146,15 +126,15 @@ func cmdDockerHijack(c *cli.Context) error {
 
 	nodeName := c.String("node")
 
-	webservice, err := webservice.NewWebService()
-	utils.CheckError(err)
-
-	err, data, res := webservice.Get("/v1/kaas/ships")
-	utils.CheckError(err)
-	utils.CheckReturnCode(res, data)
+	hc, err := client.Default()
+	if err != nil {
+		return err
+	}
 
-	err = json.Unmarshal(data, &nodes)
-	utils.CheckError(err)
+	nodes, err := hc.Nodes.GetNodeList()
+	if err != nil {
+		return err
+	}
 
 	// Validating if node exist
 	for _, element := range nodes {
@@ -168,18 +148,24 @@ func cmdDockerHijack(c *cli.Context) error {
 
 		dockerLocation, err := exec.LookPath("docker")
 		if err != nil {
-			log.Warn(fmt.Sprintf("We could not find docker in your enviroment. Please install it."))
-			os.Exit(1)
+			return fmt.Errorf("We could not find docker in your enviroment. Please install it.")
 		}
 
 		log.Debug(fmt.Sprintf("Found docker at %s", dockerLocation))
 		config, err := utils.GetConcertoConfig()
-		utils.CheckError(err)
+		if err != nil {
+			return err
+		}
+
+		keyFile, err := config.KeyFile()
+		if err != nil {
+			return err
+		}
 
 		nodeParameters := fmt.Sprintf("--host=tcp://%s:2376", node.Fqdn)
 		tls := "--tls=true"
 		clientCertificate := fmt.Sprintf("--tlscert=%s", config.Certificate.Cert)
-		clientKey := fmt.Sprintf("--tlskey=%s", config.Certificate.Key)
+		clientKey := fmt.Sprintf("--tlskey=%s", keyFile)
 		clientCA := fmt.Sprintf("--tlscacert=%s", config.Certificate.Ca)
 
 		arguments := append([]string{nodeParameters, tls, clientCertificate, clientKey, clientCA, firstArgument}, c.Args().Tail()...)
@@ -189,15 +175,20 @@ func cmdDockerHijack(c *cli.Context) error {
 		cmd := exec.Command(dockerLocation, arguments...)
 
 		stdout, err := cmd.StdoutPipe()
-		utils.CheckError(err)
+		if err != nil {
+			return err
+		}
 
 		stderr, err := cmd.StderrPipe()
-		utils.CheckError(err)
+		if err != nil {
+			return err
+		}
 
 		// Start command
 		err = cmd.Start()
-		utils.CheckError(err)
-		defer cmd.Wait()
+		if err != nil {
+			return err
+		}
 
 		go io.Copy(os.Stderr, stderr)
 
@@ -217,16 +208,15 @@ func cmdDockerHijack(c *cli.Context) error {
 			fmt.Printf("%s\n", strings.Replace(string(line), "docker", fmt.Sprintf("concerto nodes docker --node %s", nodeName), -1))
 		}
 
-		go func() {
-			time.Sleep(30 * time.Second)
-			log.Fatal(fmt.Sprintf("Timeout out. Check conectivity to %s", nodeParameters))
-		}()
-
-		return nil
-
-	} else {
-		log.Warn(fmt.Sprintf("Node \"%s\" is not in your account please create it. Thank you.", nodeName))
-		os.Exit(1)
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+		select {
+		case <-done:
+			return nil
+		case <-time.After(30 * time.Second):
+			return fmt.Errorf("Timed out. Check connectivity to %s", nodeParameters)
+		}
 	}
-	return nil
+
+	return fmt.Errorf("Node \"%s\" is not in your account please create it. Thank you.", nodeName)
 }
@@ -26,6 +26,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists all available Nodes",
 			Action: cmd.NodeList,
+			Flags:  cmd.ListFlags(),
 		},
 		{
 			Name:   "start",
@@ -65,9 +66,9 @@ func SubCommands() []cli.Command {
 			Usage:  "Deletes a given Node",
 			Action: cmd.NodeDelete,
 			Flags: []cli.Flag{
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "id",
-					Usage: "Node Id",
+					Usage: "Node Id. Repeat it to delete several nodes",
 				},
 			},
 		},
@@ -0,0 +1,15 @@
+package overview
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+)
+
+// Command returns the overview CLI command
+func Command() cli.Command {
+	return cli.Command{
+		Name:   "overview",
+		Usage:  "Summarizes templates, servers, cloud accounts and firewall profiles of the account in one screen",
+		Action: cmd.OverviewShow,
+	}
+}
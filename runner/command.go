@@ -0,0 +1,25 @@
+package runner
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+)
+
+// Command returns the run CLI command
+func Command() cli.Command {
+	return cli.Command{
+		Name:   "run",
+		Usage:  "Runs the CLI operations defined in a YAML file, passing values captured from outputs on to later steps",
+		Action: cmd.RunOperations,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "file, f",
+				Usage: "Operations file",
+			},
+			cli.StringSliceFlag{
+				Name:  "var",
+				Usage: "Variable given as name=value, overriding those of the file. Can be repeated",
+			},
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package selfupdate
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+	"github.com/flexiant/concerto/utils/release"
+)
+
+// Command returns the self-update CLI command
+func Command() cli.Command {
+	return cli.Command{
+		Name:   "self-update",
+		Usage:  "Replaces the CLI with the latest release for this platform, once its signature has been verified",
+		Action: cmd.SelfUpdate,
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "check-only",
+				Usage: "Only check whether a newer release is available",
+			},
+			cli.BoolFlag{
+				Name:  "force",
+				Usage: "Install the latest release again when it's the running one. Older releases are never installed",
+			},
+			cli.StringFlag{
+				EnvVar: "CONCERTO_RELEASE_URL",
+				Name:   "url",
+				Usage:  "Location where releases are published",
+				Value:  release.DefaultURL,
+			},
+		},
+	}
+}
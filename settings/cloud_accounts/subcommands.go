@@ -11,6 +11,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists the cloud accounts of the account group.",
 			Action: cmd.CloudAccountList,
+			Flags:  cmd.ListFlags(),
 		},
 		{
 			Name:   "create",
@@ -23,7 +24,19 @@ func SubCommands() []cli.Command {
 				},
 				cli.StringFlag{
 					Name:  "credentials",
-					Usage: "A mapping assigning a value to each of the required yes credentials of the cloud provider (JSON String)",
+					Usage: "A mapping assigning a value to each of the required yes credentials of the cloud provider (JSON String). Use vault:<path> or keyring:<account> to read them from Vault or the OS keyring",
+				},
+				cli.BoolFlag{
+					Name:  "from-default-credentials",
+					Usage: "Read credentials from the local AWS, Google Cloud or Azure CLI configuration",
+				},
+				cli.BoolFlag{
+					Name:  "yes",
+					Usage: "Upload default credentials without asking for confirmation",
+				},
+				cli.BoolFlag{
+					Name:  "interactive",
+					Usage: "Prompt for each credential required by the cloud provider, hiding secrets",
 				},
 			},
 		},
@@ -38,7 +51,7 @@ func SubCommands() []cli.Command {
 				},
 				cli.StringFlag{
 					Name:  "credentials",
-					Usage: "A mapping assigning a value to each of the required yes credentials of the cloud provider (JSON String)",
+					Usage: "A mapping assigning a value to each of the required yes credentials of the cloud provider (JSON String). Use vault:<path> or keyring:<account> to read them from Vault or the OS keyring",
 				},
 			},
 		},
@@ -47,9 +60,9 @@ func SubCommands() []cli.Command {
 			Usage:  "Deletes a cloud account",
 			Action: cmd.CloudAccountDelete,
 			Flags: []cli.Flag{
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "id",
-					Usage: "Account Id",
+					Usage: "Account Id. Repeat it to delete several accounts",
 				},
 			},
 		},
@@ -0,0 +1,28 @@
+package cloud_providers
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+)
+
+func SubCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "Lists the cloud providers, with the credentials their cloud accounts require.",
+			Action: cmd.CloudProviderList,
+			Flags:  cmd.ListFlags(),
+		},
+		{
+			Name:   "show",
+			Usage:  "Shows a cloud provider, with the credentials its cloud accounts require, its regions and server plans.",
+			Action: cmd.CloudProviderShow,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "id",
+					Usage: "Cloud provider Id",
+				},
+			},
+		},
+	}
+}
@@ -11,6 +11,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists the SaaS accounts of the account group.",
 			Action: cmd.SaasAccountList,
+			Flags:  cmd.ListFlags(),
 		},
 		{
 			Name:   "create",
@@ -47,9 +48,9 @@ func SubCommands() []cli.Command {
 			Usage:  "Deletes a SaaS account",
 			Action: cmd.SaasAccountDelete,
 			Flags: []cli.Flag{
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "id",
-					Usage: "Account Id",
+					Usage: "Account Id. Repeat it to delete several accounts",
 				},
 			},
 		},
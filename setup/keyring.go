@@ -0,0 +1,107 @@
+// +build !solaris
+
+package setup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/keyring"
+
+	"github.com/codegangsta/cli"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// clientKeyAccount names the keyring entry of the API key of an endpoint, so that
+// profiles for different endpoints don't overwrite each other's key
+func clientKeyAccount(config *utils.Config) string {
+	host := config.ConcertoURL
+	if u, err := url.Parse(config.ConcertoURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return fmt.Sprintf("api_key@%s", host)
+}
+
+// storeClientKey moves the downloaded API key into the OS keyring, and points the
+// configuration at it when it referenced the downloaded file
+func storeClientKey(config *utils.Config) error {
+	keyPath := path.Join(config.ConfLocation, "ssl", "private", "cert.key")
+	key, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return err
+	}
+	account := clientKeyAccount(config)
+	if err = keyring.Set(account, string(key)); err != nil {
+		return err
+	}
+	if err = os.Remove(keyPath); err != nil {
+		return err
+	}
+	log.Debugf("Moved %s to keyring account %s", keyPath, account)
+
+	reference := keyring.Reference(account)
+	if config.Certificate.Key == reference {
+		return nil
+	}
+	if config.Certificate.Key == keyPath {
+		conf, err := ioutil.ReadFile(config.ConfFile)
+		if err == nil && strings.Count(string(conf), fmt.Sprintf("key=%q", keyPath)) == 1 {
+			conf = []byte(strings.Replace(string(conf), fmt.Sprintf("key=%q", keyPath), fmt.Sprintf("key=%q", reference), 1))
+			if err = ioutil.WriteFile(config.ConfFile, conf, 0600); err != nil {
+				return err
+			}
+			fmt.Printf("\nUpdated %s to read the key from the keyring", config.ConfFile)
+			return nil
+		}
+	}
+	fmt.Printf("\nPlease, set key=%q in the ssl element of %s", reference, config.ConfFile)
+	return nil
+}
+
+func cmdSetupKeyring(c *cli.Context) error {
+	account := c.String("account")
+	if account == "" {
+		return exit.NewValidationError(fmt.Errorf("Please, use --account to name the secret"))
+	}
+
+	if c.Bool("delete") {
+		if err := keyring.Delete(account); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted %s from keyring\n", account)
+		return nil
+	}
+
+	var secret []byte
+	var err error
+	switch {
+	case c.String("file") != "":
+		secret, err = ioutil.ReadFile(c.String("file"))
+	case terminal.IsTerminal(int(syscall.Stdin)):
+		fmt.Printf("Secret: ")
+		secret, err = terminal.ReadPassword(int(syscall.Stdin))
+		fmt.Printf("\n")
+	default:
+		secret, err = ioutil.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(secret)) == "" {
+		return exit.NewValidationError(fmt.Errorf("Secret for %s is empty", account))
+	}
+
+	if err = keyring.Set(account, strings.TrimRight(string(secret), "\r\n")); err != nil {
+		return err
+	}
+	fmt.Printf("Stored %s in keyring. Use %s wherever the secret is needed\n", account, keyring.Reference(account))
+	return nil
+}
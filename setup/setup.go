@@ -19,6 +19,7 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/exit"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/codegangsta/cli"
@@ -34,8 +35,8 @@ type WebClient struct {
 	cookie *http.Cookie
 }
 
-func NewWebClient(endpoint string) (*WebClient, error) {
-	transport := &http.Transport{}
+func NewWebClient(endpoint string, proxy func(*http.Request) (*url.URL, error)) (*WebClient, error) {
+	transport := &http.Transport{Proxy: proxy}
 	options := cookiejar.Options{
 		PublicSuffixList: publicsuffix.List,
 	}
@@ -90,11 +91,14 @@ func (w *WebClient) login(email string, password string) error {
 	w.url.Path = "/accounts/login"
 
 	response, err := w.client.Get(w.url.String())
+	if err != nil {
+		return err
+	}
 	defer response.Body.Close()
 	err = w.obtainCsrf(response.Body)
 
 	if err != nil {
-		log.Fatalf("%#v", err)
+		return err
 	}
 
 	if w.csrf == "" {
@@ -125,13 +129,6 @@ func (w *WebClient) login(email string, password string) error {
 	return err
 }
 
-func debug(data []byte, err error) {
-	if err == nil {
-		fmt.Printf("%s\n\n", data)
-	} else {
-		log.Fatalf("%s\n\n", err)
-	}
-}
 func (w *WebClient) generateAPIKeys() error {
 	w.url.Path = "/settings/api_key"
 
@@ -198,18 +195,34 @@ func (w *WebClient) getApiKeys() error {
 }
 
 func cmdSetupApiKeys(c *cli.Context) error {
-	var emailUnClean string
-	var passwordUnClean []byte
-
-	reader := bufio.NewReader(os.Stdin)
 	config, err := utils.GetConcertoConfig()
 	if err != nil {
-		log.Fatalf("Error getting current configuration: %s", err.Error)
+		return fmt.Errorf("Error getting current configuration: %s", err)
+	}
+
+	if err = downloadAPIKeys(c, bufio.NewReader(os.Stdin), config.ConcertoURL); err != nil {
+		return err
 	}
 
-	loginURL := config.ConcertoURL
+	if c.Bool("use-keyring") {
+		fmt.Printf("Moving API key to keyring ...")
+		if err = storeClientKey(config); err != nil {
+			return err
+		}
+		fmt.Printf(" OK\n")
+	}
+	return nil
+}
+
+// downloadAPIKeys logs into the Concerto web site at loginURL, asking for the email and password not
+// given with flags, and unzips the API keys of the account in the configuration location
+func downloadAPIKeys(c *cli.Context, reader *bufio.Reader, loginURL string) error {
+	var emailUnClean string
+	var passwordUnClean []byte
+
+	config, err := utils.GetConcertoConfig()
 	if err != nil {
-		log.Fatalf("Error getting Concerto URL: %s", err.Error)
+		return fmt.Errorf("Error getting current configuration: %s", err)
 	}
 
 	fmt.Printf("Using Concerto endpoint %s \n", loginURL)
@@ -231,36 +244,39 @@ func cmdSetupApiKeys(c *cli.Context) error {
 	password := strings.TrimSpace(string(passwordUnClean))
 	fmt.Printf("\n")
 
-	if govalidator.IsEmail(email) {
-		client, err := NewWebClient(loginURL)
-		if err != nil {
-			log.Fatal(err)
-		}
+	if !govalidator.IsEmail(email) {
+		return exit.NewValidationError(fmt.Errorf("Email address %s is not a valid email", email))
+	}
 
-		fmt.Printf("Logging into Concerto ...")
-		err = client.login(email, password)
-		if err != nil {
-			log.Fatal(err)
-		}
-		fmt.Printf(" OK\n")
+	proxy, err := config.ProxyFunc()
+	if err != nil {
+		return err
+	}
+	client, err := NewWebClient(loginURL, proxy)
+	if err != nil {
+		return err
+	}
 
-		fmt.Printf("Checking/Generating API keys ...")
-		err = client.generateAPIKeys()
-		if err != nil {
-			log.Fatal(err)
-		}
-		fmt.Printf(" OK\n")
+	fmt.Printf("Logging into Concerto ...")
+	err = client.login(email, password)
+	if err != nil {
+		return &exit.Error{Err: err, Code: exit.Auth}
+	}
+	fmt.Printf(" OK\n")
 
-		fmt.Printf("Downloading API keys ...")
-		err = client.getApiKeys()
-		if err != nil {
-			log.Fatal(err)
-		}
-		fmt.Printf(" OK\n")
+	fmt.Printf("Checking/Generating API keys ...")
+	err = client.generateAPIKeys()
+	if err != nil {
+		return err
+	}
+	fmt.Printf(" OK\n")
 
-	} else {
-		log.Fatalf("Email address %s is not a valid email", email)
+	fmt.Printf("Downloading API keys ...")
+	err = client.getApiKeys()
+	if err != nil {
+		return err
 	}
+	fmt.Printf(" OK\n")
 	return nil
 }
 
@@ -279,6 +295,29 @@ func SubCommands() []cli.Command {
 					Name:  "password",
 					Usage: "Password used to log into concerto",
 				},
+				cli.BoolFlag{
+					Name:  "use-keyring",
+					Usage: "Keep the API key in the OS keyring instead of a plaintext file",
+				},
+			},
+		},
+		{
+			Name:   "keyring",
+			Usage:  "Stores a secret, such as cloud account credentials, in the OS keyring to be referenced as keyring:<account>",
+			Action: cmdSetupKeyring,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "account",
+					Usage: "Name of the secret",
+				},
+				cli.StringFlag{
+					Name:  "file",
+					Usage: "File with the secret. It's read from stdin otherwise",
+				},
+				cli.BoolFlag{
+					Name:  "delete",
+					Usage: "Delete the secret instead",
+				},
 			},
 		},
 	}
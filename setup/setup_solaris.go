@@ -3,6 +3,7 @@
 package setup
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
@@ -29,8 +30,8 @@ type WebClient struct {
 	cookie *http.Cookie
 }
 
-func NewWebClient(endpoint string) (*WebClient, error) {
-	transport := &http.Transport{}
+func NewWebClient(endpoint string, proxy func(*http.Request) (*url.URL, error)) (*WebClient, error) {
+	transport := &http.Transport{Proxy: proxy}
 	options := cookiejar.Options{
 		PublicSuffixList: publicsuffix.List,
 	}
@@ -85,11 +86,14 @@ func (w *WebClient) login(email string, password string) error {
 	w.url.Path = "/accounts/login"
 
 	response, err := w.client.Get(w.url.String())
+	if err != nil {
+		return err
+	}
 	defer response.Body.Close()
 	err = w.obtainCsrf(response.Body)
 
 	if err != nil {
-		log.Fatalf("%#v", err)
+		return err
 	}
 
 	if w.csrf == "" {
@@ -120,13 +124,6 @@ func (w *WebClient) login(email string, password string) error {
 	return err
 }
 
-func debug(data []byte, err error) {
-	if err == nil {
-		fmt.Printf("%s\n\n", data)
-	} else {
-		log.Fatalf("%s\n\n", err)
-	}
-}
 func (w *WebClient) generateAPIKeys() error {
 	w.url.Path = "/settings/api_key"
 
@@ -196,6 +193,10 @@ func cmdSetupApiKeys(c *cli.Context) error {
 	return fmt.Errorf("Setup not supported for Solaris")
 }
 
+func downloadAPIKeys(c *cli.Context, reader *bufio.Reader, loginURL string) error {
+	return fmt.Errorf("Downloading API keys is not supported for Solaris. Please, give the client certificates with --cert, --key and --ca")
+}
+
 func SubCommands() []cli.Command {
 	return []cli.Command{
 		{
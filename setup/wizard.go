@@ -0,0 +1,291 @@
+package setup
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/doctor"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/format"
+)
+
+// Certificates are the files authenticating concerto against the API
+type Certificates struct {
+	Cert string
+	Key  string
+	Ca   string
+}
+
+// missing returns the certificate files which don't exist
+func (certs Certificates) missing() []string {
+	var missing []string
+	for _, file := range []string{certs.Cert, certs.Key, certs.Ca} {
+		if file == "" || !utils.FileExists(file) {
+			missing = append(missing, file)
+		}
+	}
+	return missing
+}
+
+// locateCertificates returns the certificates given with flags, or else the ones unzipped from API
+// keys in the configuration location
+func locateCertificates(c *cli.Context, confLocation string) Certificates {
+	ssl := filepath.Join(confLocation, "ssl")
+	certs := Certificates{
+		Cert: filepath.Join(ssl, "cert.crt"),
+		Key:  filepath.Join(ssl, "private", "cert.key"),
+		Ca:   filepath.Join(ssl, "ca_cert.pem"),
+	}
+	if c.String("cert") != "" {
+		certs.Cert = c.String("cert")
+	}
+	if c.String("key") != "" {
+		certs.Key = c.String("key")
+	}
+	if c.String("ca") != "" {
+		certs.Ca = c.String("ca")
+	}
+	return certs
+}
+
+// configElement is an element of a configuration file, kept as read so that the settings the wizard doesn't
+// ask for, such as profiles, are written back unchanged
+type configElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr      `xml:",any,attr"`
+	Children []configElement `xml:",any"`
+	Text     string          `xml:",chardata"`
+}
+
+// set sets the value of an attribute, adding it when it's missing
+func (e *configElement) set(name string, value string) {
+	for i, a := range e.Attrs {
+		if a.Name.Local == name {
+			e.Attrs[i].Value = value
+			return
+		}
+	}
+	e.Attrs = append(e.Attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+// child returns the first child element named name, adding it when it's missing
+func (e *configElement) child(name string) *configElement {
+	for i := range e.Children {
+		if e.Children[i].XMLName.Local == name {
+			return &e.Children[i]
+		}
+	}
+	e.Children = append(e.Children, configElement{XMLName: xml.Name{Local: name}})
+	return &e.Children[len(e.Children)-1]
+}
+
+// trimSpace removes the indentation read between elements, as it's written again
+func (e *configElement) trimSpace() {
+	if strings.TrimSpace(e.Text) == "" {
+		e.Text = ""
+	}
+	for i := range e.Children {
+		e.Children[i].trimSpace()
+	}
+}
+
+// renderConfig returns the configuration file current with the endpoint and certificates given. Other settings
+// are kept. When there's no current configuration, a new one is written as the setup script does
+func renderConfig(current []byte, endpoint string, certs Certificates) ([]byte, error) {
+	root := configElement{
+		XMLName: xml.Name{Local: "concerto"},
+		Attrs:   []xml.Attr{{Name: xml.Name{Local: "version"}, Value: "1.0"}, {Name: xml.Name{Local: "log_level"}, Value: "info"}},
+	}
+	if len(bytes.TrimSpace(current)) > 0 {
+		root = configElement{}
+		if err := xml.Unmarshal(current, &root); err != nil {
+			return nil, fmt.Errorf("Couldn't read current configuration: %s", err)
+		}
+	}
+	root.set("server", endpoint)
+	ssl := root.child("ssl")
+	ssl.set("cert", certs.Cert)
+	ssl.set("key", certs.Key)
+	ssl.set("server_ca", certs.Ca)
+	root.trimSpace()
+
+	data, err := xml.MarshalIndent(root, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// writeConfig writes the configuration to file, keeping the one it replaces as file.bak
+func writeConfig(file string, config []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if utils.FileExists(file) {
+		if err := os.Rename(file, file+".bak"); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(file, config, 0600)
+}
+
+// ask prints question and returns the answer read, or def when it's empty
+func ask(reader *bufio.Reader, w io.Writer, question string, def string) string {
+	if def != "" {
+		fmt.Fprintf(w, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(w, "%s: ", question)
+	}
+	answer, _ := reader.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return def
+	}
+	return answer
+}
+
+// confirm asks a yes or no question, returning def when it isn't answered
+func confirm(reader *bufio.Reader, w io.Writer, question string, def bool) bool {
+	options := "y/N"
+	if def {
+		options = "Y/n"
+	}
+	switch strings.ToLower(ask(reader, w, fmt.Sprintf("%s (%s)", question, options), "")) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
+
+// checkConfig checks the certificate expiry and that the API accepts the certificates of config
+func checkConfig(config *utils.Config) []doctor.Result {
+	d := doctor.New(config)
+	cert := d.CheckCertificate()
+	reach := d.CheckReachability()
+	results := []doctor.Result{cert, reach}
+	if cert.Status == doctor.Fail || reach.Status == doctor.Fail {
+		return append(results, doctor.Result{Check: "api auth", Status: doctor.Skip, Detail: "API can't be called"})
+	}
+	return append(results, d.CheckAPI()...)
+}
+
+// Run is the setup wizard. It asks for the API endpoint, downloads API keys when there are no client
+// certificates, writes the configuration file and checks it with a call to the API
+func Run(c *cli.Context) error {
+	config, err := utils.GetConcertoConfig()
+	if err != nil {
+		return fmt.Errorf("Error getting current configuration: %s", err)
+	}
+	if config.IsHost {
+		return fmt.Errorf("You are trying to overwrite server configuration. Please contact your administrator")
+	}
+	reader := bufio.NewReader(os.Stdin)
+
+	endpoint := c.String("endpoint")
+	if endpoint == "" {
+		endpoint = ask(reader, os.Stdout, "Concerto API endpoint", config.APIEndpoint)
+	}
+	if u, err := url.ParseRequestURI(endpoint); err != nil || u.Host == "" {
+		return exit.NewValidationError(fmt.Errorf("API endpoint %s isn't a URL such as https://clients.concerto.io:886/", endpoint))
+	}
+
+	certs := locateCertificates(c, config.ConfLocation)
+	if missing := certs.missing(); len(missing) > 0 {
+		fmt.Printf("Client certificates not found: %s\n", strings.Join(missing, ", "))
+		if !confirm(reader, os.Stdout, "Download API keys from Concerto?", true) {
+			return exit.NewValidationError(fmt.Errorf("Client certificates are needed. Please, give them with --cert, --key and --ca"))
+		}
+		loginURL, err := utils.WebURL(endpoint)
+		if err != nil {
+			return err
+		}
+		if err = downloadAPIKeys(c, reader, loginURL); err != nil {
+			return err
+		}
+		if missing = certs.missing(); len(missing) > 0 {
+			return fmt.Errorf("API keys downloaded, but %s are still missing", strings.Join(missing, ", "))
+		}
+	}
+
+	if utils.FileExists(config.ConfFile) && !c.Bool("force") {
+		if !confirm(reader, os.Stdout, fmt.Sprintf("Overwrite %s?", config.ConfFile), false) {
+			fmt.Printf("Configuration %s left unchanged\n", config.ConfFile)
+			return nil
+		}
+	}
+	var current []byte
+	if utils.FileExists(config.ConfFile) {
+		if current, err = ioutil.ReadFile(config.ConfFile); err != nil {
+			return fmt.Errorf("Couldn't read configuration: %s", err)
+		}
+	}
+	rendered, err := renderConfig(current, endpoint, certs)
+	if err != nil {
+		return err
+	}
+	if err = writeConfig(config.ConfFile, rendered); err != nil {
+		return fmt.Errorf("Couldn't write configuration: %s", err)
+	}
+	fmt.Printf("Configuration written to %s\n", config.ConfFile)
+
+	written := &utils.Config{
+		APIEndpoint:  endpoint,
+		Proxy:        config.Proxy,
+		Certificate:  utils.Cert{Cert: certs.Cert, Key: certs.Key, Ca: certs.Ca},
+		ConfLocation: config.ConfLocation,
+		ConfFile:     config.ConfFile,
+	}
+	results := checkConfig(written)
+	if err = format.GetFormatter().PrintList(results); err != nil {
+		return err
+	}
+	if failed := doctor.Failed(results); failed > 0 {
+		return fmt.Errorf("Configuration was written, but %d checks failed. Run concerto doctor once they're fixed", failed)
+	}
+	return nil
+}
+
+// Flags are the flags of the setup wizard. The settings not given are asked for
+func Flags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  "endpoint",
+			Usage: "Concerto API endpoint, such as https://clients.concerto.io:886/",
+		},
+		cli.StringFlag{
+			Name:  "cert",
+			Usage: "Client certificate. The one of the API keys in the configuration location by default",
+		},
+		cli.StringFlag{
+			Name:  "key",
+			Usage: "Client certificate key. The one of the API keys in the configuration location by default",
+		},
+		cli.StringFlag{
+			Name:  "ca",
+			Usage: "CA certificate of the API endpoint. The one of the API keys in the configuration location by default",
+		},
+		cli.StringFlag{
+			Name:  "email",
+			Usage: "Email used to log into concerto when downloading API keys",
+		},
+		cli.StringFlag{
+			Name:  "password",
+			Usage: "Password used to log into concerto when downloading API keys",
+		},
+		cli.BoolFlag{
+			Name:  "force",
+			Usage: "Overwrite the configuration file without asking. The previous one is kept as .bak",
+		},
+	}
+}
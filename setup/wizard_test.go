@@ -0,0 +1,90 @@
+package setup
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flexiant/concerto/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	certs := Certificates{Cert: "/home/u/.concerto/ssl/cert.crt", Key: "/home/u/.concerto/ssl/private/cert.key", Ca: "/home/u/.concerto/ssl/ca_cert.pem"}
+	rendered, err := renderConfig(nil, "https://clients.concerto.io:886/", certs)
+	assert.Nil(err, "Couldn't render configuration")
+	var config utils.Config
+	assert.Nil(xml.Unmarshal(rendered, &config), "Configuration should be valid XML")
+	assert.Equal("https://clients.concerto.io:886/", config.APIEndpoint, "Unexpected endpoint")
+	assert.Equal(utils.Cert{Cert: certs.Cert, Key: certs.Key, Ca: certs.Ca}, config.Certificate, "Unexpected certificates")
+	assert.Empty(config.LogFile, "Log file shouldn't be set")
+
+	certs.Cert = `C:\Users\"Jo" & <co>\cert.crt`
+	rendered, err = renderConfig(nil, "https://clients.concerto.io:886/", certs)
+	assert.Nil(err, "Couldn't render configuration")
+	assert.Nil(xml.Unmarshal(rendered, &config), "Paths should be escaped")
+	assert.Equal(certs.Cert, config.Certificate.Cert, "Paths should be kept as given")
+}
+
+func TestRenderConfigMerges(t *testing.T) {
+	assert := assert.New(t)
+
+	current := []byte(`<concerto version="1.0" server="https://old.example.com:886/" log_file="/tmp/concerto.log" retries="5">
+	<ssl cert="old.crt" key="old.key" server_ca="old.pem" pins="sha256/abc" />
+	<alias name="web" command="cloud servers list"/>
+	<profile name="staging" server="https://staging.example.com:886/"><ssl cert="staging.crt"/></profile>
+</concerto>
+`)
+	certs := Certificates{Cert: "new.crt", Key: "new.key", Ca: "new.pem"}
+	rendered, err := renderConfig(current, "https://clients.concerto.io:886/", certs)
+	assert.Nil(err, "Couldn't render configuration")
+
+	var config utils.Config
+	assert.Nil(xml.Unmarshal(rendered, &config), "Configuration should be valid XML")
+	assert.Equal("https://clients.concerto.io:886/", config.APIEndpoint, "Endpoint should be replaced")
+	assert.Equal(utils.Cert{Cert: "new.crt", Key: "new.key", Ca: "new.pem", Pins: "sha256/abc"}, config.Certificate, "Certificates should be replaced, keeping other settings")
+	assert.Equal("/tmp/concerto.log", config.LogFile, "Settings should be kept")
+	assert.Equal(5, config.Retries, "Settings should be kept")
+	assert.Equal([]utils.Alias{{Name: "web", Command: "cloud servers list"}}, config.Aliases, "Aliases should be kept")
+	assert.Len(config.Profiles, 1, "Profiles should be kept")
+	assert.Equal("staging.crt", config.Profiles[0].Certificate.Cert, "Profiles should be kept unchanged")
+
+	_, err = renderConfig([]byte("<concerto"), "https://clients.concerto.io:886/", certs)
+	assert.NotNil(err, "Invalid configurations shouldn't be overwritten")
+}
+
+func TestWriteConfigKeepsPrevious(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "concerto-setup")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, ".concerto", "client.xml")
+
+	assert.Nil(writeConfig(file, []byte("old")), "Configuration should be written in a new location")
+	assert.Nil(writeConfig(file, []byte("new")), "Configuration should be overwritten")
+	written, _ := ioutil.ReadFile(file)
+	assert.Equal("new", string(written), "Unexpected configuration")
+	previous, _ := ioutil.ReadFile(file + ".bak")
+	assert.Equal("old", string(previous), "Previous configuration should be kept")
+}
+
+func TestAsk(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	reader := bufio.NewReader(strings.NewReader("\nhttps://staging.concerto.io:886/\n\nno\n"))
+	assert.Equal("https://clients.concerto.io:886/", ask(reader, &out, "Concerto API endpoint", "https://clients.concerto.io:886/"), "Empty answers should be the default")
+	assert.Equal("https://staging.concerto.io:886/", ask(reader, &out, "Concerto API endpoint", "https://clients.concerto.io:886/"), "Unexpected answer")
+	assert.True(confirm(reader, &out, "Download API keys?", true), "Empty answers should be the default")
+	assert.False(confirm(reader, &out, "Download API keys?", true), "Unexpected answer")
+	assert.False(confirm(reader, &out, "Overwrite?", false), "Unanswered questions should be the default")
+	assert.Equal("Concerto API endpoint [https://clients.concerto.io:886/]: ", strings.SplitAfter(out.String(), ": ")[0], "Unexpected question")
+}
@@ -9,12 +9,18 @@ func GetGenericImageData() *[]types.GenericImage {
 
 	testGenericImages := []types.GenericImage{
 		{
-			Id:   "fakeID0",
-			Name: "fakeName0",
+			Id:           "fakeID0",
+			Name:         "Ubuntu 14.04 Trusty Tahr x86_64",
+			OSFamily:     "ubuntu",
+			OSVersion:    "14.04",
+			Architecture: "x86_64",
 		},
 		{
-			Id:   "fakeID1",
-			Name: "fakeName1",
+			Id:           "fakeID1",
+			Name:         "Windows 2012 R2 x86_64",
+			OSFamily:     "windows",
+			OSVersion:    "2012 R2",
+			Architecture: "x86_64",
 		},
 	}
 
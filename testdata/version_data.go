@@ -0,0 +1,14 @@
+package testdata
+
+import (
+	"github.com/flexiant/concerto/api/types"
+)
+
+// GetAPIVersionData loads test data
+func GetAPIVersionData() *types.APIVersion {
+	return &types.APIVersion{
+		Version:       "3.4.1",
+		MinCLIVersion: "0.3.40",
+		MaxCLIVersion: "0.4.0",
+	}
+}
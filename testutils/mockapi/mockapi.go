@@ -0,0 +1,323 @@
+// Package mockapi emulates the Concerto v1 endpoints of templates, scripts and cloud accounts in an
+// httptest.Server, so that commands can be tested end to end without a live tenant. Collections start with
+// the fixtures of package testdata, and are listed, shown, created, updated and deleted as the API does.
+// Any other endpoint is stubbed with Stub
+package mockapi
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/flexiant/concerto/testdata"
+	"github.com/flexiant/concerto/utils"
+)
+
+// Token is the bearer token requests must be authenticated with
+const Token = "mockapi-token"
+
+// Collections emulated, as in the paths of their endpoints
+const (
+	Templates     = "blueprint/templates"
+	Scripts       = "blueprint/scripts"
+	CloudAccounts = "settings/cloud_accounts"
+)
+
+// hiddenFields are the fields of each collection which are stored but never returned, as credentials
+var hiddenFields = map[string][]string{
+	CloudAccounts: {"credentials"},
+}
+
+// Request is a request received by the server
+type Request struct {
+	Method string
+	// Path is the path of the request, without its query
+	Path  string
+	Query string
+	// Body is the JSON object sent, if any
+	Body map[string]interface{}
+}
+
+// stub is the response given to requests of a method and path
+type stub struct {
+	status int
+	body   []byte
+}
+
+// collection holds the items of a collection, by ID and in the order they were created
+type collection struct {
+	ids   []string
+	items map[string]map[string]interface{}
+}
+
+// Server is an httptest.Server emulating Concerto API
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	collections map[string]*collection
+	stubs       map[string]stub
+	requests    []Request
+	lastID      int
+}
+
+// New starts a server whose collections hold the fixtures of package testdata. It must be closed once done
+func New() *Server {
+	s := &Server{
+		collections: make(map[string]*collection),
+		stubs:       make(map[string]stub),
+	}
+	// fixtures are valid JSON, as they're the API test data
+	s.Set(Templates, testdata.GetTemplateData())
+	s.Set(Scripts, testdata.GetScriptData())
+	s.Set(CloudAccounts, testdata.GetCloudAccountData())
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Set replaces the items of a collection with items, a slice of API types such as []types.Template. Items
+// are identified by their id field
+func (s *Server) Set(name string, items interface{}) error {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	var objects []map[string]interface{}
+	if err = json.Unmarshal(data, &objects); err != nil {
+		return fmt.Errorf("Items of %s must be a list of objects: %s", name, err)
+	}
+
+	c := &collection{items: make(map[string]map[string]interface{})}
+	for _, o := range objects {
+		id, _ := o["id"].(string)
+		if id == "" {
+			return fmt.Errorf("Items of %s must have an id", name)
+		}
+		c.ids = append(c.ids, id)
+		c.items[id] = o
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collections[name] = c
+	return nil
+}
+
+// Items decodes the items of a collection into v, such as a *[]types.Template, so that tests can check
+// the changes made by commands
+func (s *Server) Items(name string, v interface{}) error {
+	s.mu.Lock()
+	c, ok := s.collections[name]
+	var items []map[string]interface{}
+	if ok {
+		items = c.list(name)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("Unknown collection %s", name)
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Stub makes requests of method to path, without query, be answered with status and body encoded as JSON.
+// Stubs take precedence over collections, so that their failures can be emulated too
+func (s *Server) Stub(method string, path string, status int, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stubs[method+" "+path] = stub{status: status, body: data}
+	return nil
+}
+
+// Requests returns the requests received, in order
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Config returns the configuration of a CLI sending requests to the server
+func (s *Server) Config() *utils.Config {
+	return &utils.Config{
+		APIEndpoint: s.URL,
+		Token:       utils.Token{Value: Token},
+	}
+}
+
+// Service returns a service sending requests to the server, to build API services and clients with
+func (s *Server) Service() (*utils.HTTPConcertoservice, error) {
+	return utils.NewHTTPConcertoService(s.Config())
+}
+
+// WriteConfig writes the configuration file of a CLI sending requests to the server in dir, returning its
+// path, so that the concerto binary can be run against the server with --concerto-config
+func (s *Server) WriteConfig(dir string) (string, error) {
+	file := filepath.Join(dir, "client.xml")
+	data := fmt.Sprintf("<concerto version=\"1.0\" server=\"%s\">\n  <token value=\"%s\"/>\n</concerto>\n", s.URL, Token)
+	if err := ioutil.WriteFile(file, []byte(data), 0600); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	req := Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery}
+	body := io.Reader(r.Body)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			respond(w, http.StatusBadRequest, errorBody("body", err.Error()))
+			return
+		}
+		body = zr
+	}
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		respond(w, http.StatusBadRequest, errorBody("body", err.Error()))
+		return
+	}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &req.Body); err != nil {
+			respond(w, http.StatusBadRequest, errorBody("body", "must be a JSON object"))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+
+	if r.Header.Get("Authorization") != "Bearer "+Token {
+		respond(w, http.StatusUnauthorized, errorBody("token", "is invalid"))
+		return
+	}
+	if st, ok := s.stubs[r.Method+" "+req.Path]; ok {
+		respond(w, st.status, st.body)
+		return
+	}
+
+	name, id := route(req.Path)
+	c, ok := s.collections[name]
+	if !ok {
+		respond(w, http.StatusNotFound, errorBody("path", "not found"))
+		return
+	}
+	if id == "" {
+		switch r.Method {
+		case "GET":
+			respondJSON(w, http.StatusOK, c.list(name))
+		case "POST":
+			s.lastID++
+			id = fmt.Sprintf("%024x", s.lastID)
+			item := map[string]interface{}{"id": id}
+			for k, v := range req.Body {
+				item[k] = v
+			}
+			c.ids = append(c.ids, id)
+			c.items[id] = item
+			respondJSON(w, http.StatusCreated, visible(name, item))
+		default:
+			respond(w, http.StatusMethodNotAllowed, errorBody("method", "not allowed"))
+		}
+		return
+	}
+
+	item, ok := c.items[id]
+	if !ok {
+		respond(w, http.StatusNotFound, errorBody("id", "not found"))
+		return
+	}
+	switch r.Method {
+	case "GET":
+		respondJSON(w, http.StatusOK, visible(name, item))
+	case "PUT", "PATCH":
+		for k, v := range req.Body {
+			if k != "id" {
+				item[k] = v
+			}
+		}
+		respondJSON(w, http.StatusOK, visible(name, item))
+	case "DELETE":
+		c.remove(id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		respond(w, http.StatusMethodNotAllowed, errorBody("method", "not allowed"))
+	}
+}
+
+// route returns the collection and item ID a path refers to, as in /v1/blueprint/templates/<id>
+func route(path string) (name string, id string) {
+	path = strings.Trim(strings.TrimPrefix(path, "/v1/"), "/")
+	parts := strings.Split(path, "/")
+	if len(parts) == 3 {
+		return strings.Join(parts[:2], "/"), parts[2]
+	}
+	return path, ""
+}
+
+// list returns the items of the collection, as returned by the API
+func (c *collection) list(name string) []map[string]interface{} {
+	items := []map[string]interface{}{}
+	for _, id := range c.ids {
+		items = append(items, visible(name, c.items[id]))
+	}
+	return items
+}
+
+func (c *collection) remove(id string) {
+	delete(c.items, id)
+	for i, v := range c.ids {
+		if v == id {
+			c.ids = append(c.ids[:i], c.ids[i+1:]...)
+			return
+		}
+	}
+}
+
+// visible returns item without the fields of its collection which aren't returned
+func visible(name string, item map[string]interface{}) map[string]interface{} {
+	v := make(map[string]interface{})
+	for k, value := range item {
+		v[k] = value
+	}
+	for _, field := range hiddenFields[name] {
+		delete(v, field)
+	}
+	return v
+}
+
+// errorBody returns an error as the API reports them
+func errorBody(field string, message string) []byte {
+	data, _ := json.Marshal(map[string]interface{}{"errors": map[string][]string{field: {message}}})
+	return data
+}
+
+func respondJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		respond(w, http.StatusInternalServerError, errorBody("response", err.Error()))
+		return
+	}
+	respond(w, status, data)
+}
+
+func respond(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
@@ -0,0 +1,118 @@
+package mockapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/flexiant/concerto/api/blueprint"
+	"github.com/flexiant/concerto/api/settings"
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/testdata"
+	"github.com/flexiant/concerto/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplates(t *testing.T) {
+	assert := assert.New(t)
+	s := New()
+	defer s.Close()
+	cs, err := s.Service()
+	assert.Nil(err, "Couldn't create service")
+	svc, err := blueprint.NewTemplateService(cs)
+	assert.Nil(err, "Couldn't create template service")
+
+	templates, err := svc.GetTemplateList()
+	assert.Nil(err, "Couldn't list templates")
+	assert.Equal(*testdata.GetTemplateData(), templates, "Templates aren't the fixtures")
+
+	created, err := svc.CreateTemplate(&map[string]interface{}{"name": "web", "generic_image_id": "img"})
+	assert.Nil(err, "Couldn't create template")
+	assert.Len(created.ID, 24, "Created template has no ID")
+	assert.Equal("web", created.Name)
+
+	updated, err := svc.UpdateTemplate(&map[string]interface{}{"name": "www"}, created.ID)
+	assert.Nil(err, "Couldn't update template")
+	assert.Equal(types.Template{ID: created.ID, Name: "www", GenericImgID: "img"}, *updated)
+
+	shown, err := svc.GetTemplate(created.ID)
+	assert.Nil(err, "Couldn't show template")
+	assert.Equal(*updated, *shown)
+
+	assert.Nil(svc.DeleteTemplate(created.ID), "Couldn't delete template")
+	_, err = svc.GetTemplate(created.ID)
+	assert.NotNil(err, "Deleted template was shown")
+
+	var items []types.Template
+	assert.Nil(s.Items(Templates, &items))
+	assert.Equal(*testdata.GetTemplateData(), items, "Templates weren't restored")
+
+	requests := s.Requests()
+	assert.Len(requests, 6)
+	assert.Equal(Request{Method: "PUT", Path: "/v1/blueprint/templates/" + created.ID, Body: map[string]interface{}{"name": "www"}}, requests[2])
+}
+
+func TestCompressedRequests(t *testing.T) {
+	assert := assert.New(t)
+	s := New()
+	defer s.Close()
+	config := s.Config()
+	config.Compress = true
+	cs, err := utils.NewHTTPConcertoService(config)
+	assert.Nil(err, "Couldn't create service")
+	svc, err := blueprint.NewScriptService(cs)
+	assert.Nil(err, "Couldn't create script service")
+
+	code := strings.Repeat("echo hello\n", 200)
+	script, err := svc.CreateScript(&map[string]interface{}{"name": "hello", "code": code})
+	assert.Nil(err, "Couldn't create script")
+	assert.Equal(code, script.Code, "Compressed body wasn't read")
+}
+
+func TestCloudAccountCredentials(t *testing.T) {
+	assert := assert.New(t)
+	s := New()
+	defer s.Close()
+	cs, err := s.Service()
+	assert.Nil(err, "Couldn't create service")
+	svc, err := settings.NewCloudAccountService(cs)
+	assert.Nil(err, "Couldn't create cloud account service")
+
+	_, err = svc.CreateCloudAccount(&map[string]interface{}{"cloud_provider_id": "p", "credentials": map[string]string{"key": "secret"}})
+	assert.Nil(err, "Couldn't create cloud account")
+
+	data, status, err := cs.Get("/v1/settings/cloud_accounts")
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, status)
+	assert.NotContains(string(data), "secret", "Credentials were returned")
+}
+
+func TestStubAndToken(t *testing.T) {
+	assert := assert.New(t)
+	s := New()
+	defer s.Close()
+
+	assert.Nil(s.Stub("GET", "/v1/blueprint/scripts", http.StatusInternalServerError, map[string]string{"error": "down"}))
+	cs, err := s.Service()
+	assert.Nil(err, "Couldn't create service")
+	svc, err := blueprint.NewScriptService(cs)
+	assert.Nil(err, "Couldn't create script service")
+	_, err = svc.GetScriptList()
+	assert.NotNil(err, "Stubbed failure wasn't returned")
+
+	_, status, err := cs.Get("/v1/cloud/servers")
+	assert.Equal(http.StatusNotFound, status, "Unknown endpoint was found")
+	if assert.IsType(&utils.HTTPError{}, err, "Unknown endpoint should fail with HTTP error") {
+		assert.Equal(http.StatusNotFound, err.(*utils.HTTPError).Status, "Unknown endpoint should fail with its status")
+	}
+
+	config := s.Config()
+	config.Token.Value = "wrong"
+	cs, err = utils.NewHTTPConcertoService(config)
+	assert.Nil(err, "Couldn't create service")
+	_, status, err = cs.Get("/v1/blueprint/templates")
+	assert.Equal(http.StatusUnauthorized, status, "Wrong token was accepted")
+	if assert.IsType(&utils.HTTPError{}, err, "Wrong token should fail with HTTP error") {
+		assert.Equal(http.StatusUnauthorized, err.(*utils.HTTPError).Status, "Wrong token should fail with its status")
+	}
+}
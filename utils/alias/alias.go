@@ -0,0 +1,166 @@
+package alias
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+)
+
+// Expand replaces the command in args with its alias definition, if it's an alias. Definitions are
+// split as a shell would, and may start with global flags. Arguments given after the alias are
+// appended to its definition, so that definition flags can be completed or overridden
+func Expand(args []string, aliases map[string]string, flags []cli.Flag) ([]string, error) {
+	seen := make(map[string]bool)
+	for {
+		i := commandIndex(args, flags)
+		if i < 0 {
+			return args, nil
+		}
+		definition, ok := aliases[args[i]]
+		if !ok {
+			return args, nil
+		}
+		if seen[args[i]] {
+			return nil, fmt.Errorf("Alias %s is defined in terms of itself", args[i])
+		}
+		seen[args[i]] = true
+
+		words, err := Split(definition)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid alias %s: %s", args[i], err)
+		}
+		if len(words) == 0 {
+			return nil, fmt.Errorf("Alias %s is empty", args[i])
+		}
+		expanded := append([]string{}, args[:i]...)
+		expanded = append(expanded, words...)
+		args = append(expanded, args[i+1:]...)
+	}
+}
+
+// FlagValue returns the value given to global flag name in args, or else in its environment variable
+func FlagValue(args []string, flags []cli.Flag, name string) string {
+	for i := 1; i < len(args) && strings.HasPrefix(args[i], "-"); i++ {
+		flagName, value, hasValue := parseFlag(args[i])
+		if flagName == "" {
+			break
+		}
+		f := lookup(flags, flagName)
+		if f == nil || isBool(f) {
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		if matches(f, name) {
+			return value
+		}
+	}
+	if f, ok := lookup(flags, name).(cli.StringFlag); ok && f.EnvVar != "" {
+		return os.Getenv(f.EnvVar)
+	}
+	return ""
+}
+
+// Split splits s into words as a shell would, honouring single and double quotes and backslashes
+func Split(s string) ([]string, error) {
+	var words []string
+	var word []rune
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			word = append(word, r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word = append(word, r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, string(word))
+				word, inWord = nil, false
+			}
+		default:
+			word, inWord = append(word, r), true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inWord {
+		words = append(words, string(word))
+	}
+	return words, nil
+}
+
+// commandIndex returns the position of the command in args, skipping global flags and their values,
+// or -1 when there's no command
+func commandIndex(args []string, flags []cli.Flag) int {
+	for i := 1; i < len(args); i++ {
+		if args[i] == "--" {
+			if i+1 < len(args) {
+				return i + 1
+			}
+			return -1
+		}
+		if !strings.HasPrefix(args[i], "-") || args[i] == "-" {
+			return i
+		}
+		flagName, _, hasValue := parseFlag(args[i])
+		if f := lookup(flags, flagName); f != nil && !isBool(f) && !hasValue {
+			i++
+		}
+	}
+	return -1
+}
+
+// parseFlag splits -name, --name and --name=value arguments
+func parseFlag(arg string) (name string, value string, hasValue bool) {
+	name = strings.TrimLeft(arg, "-")
+	if i := strings.Index(name, "="); i >= 0 {
+		return name[:i], name[i+1:], true
+	}
+	return name, "", false
+}
+
+func lookup(flags []cli.Flag, name string) cli.Flag {
+	for _, f := range flags {
+		if matches(f, name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// matches returns whether name is the name or one of the short names of f, such as "debug, D"
+func matches(f cli.Flag, name string) bool {
+	for _, n := range strings.Split(f.GetName(), ",") {
+		if strings.TrimSpace(n) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isBool(f cli.Flag) bool {
+	switch f.(type) {
+	case cli.BoolFlag, cli.BoolTFlag:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package alias
+
+import (
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFlags = []cli.Flag{
+	cli.BoolFlag{Name: "debug, D"},
+	cli.StringFlag{Name: "formatter", Value: "text"},
+	cli.StringFlag{Name: "concerto-config", EnvVar: "CONCERTO_TEST_CONFIG"},
+}
+
+func TestExpand(t *testing.T) {
+	assert := assert.New(t)
+	aliases := map[string]string{
+		"prodservers": "--formatter json cloud servers list --workspace_id 'prod ws'",
+		"ps":          "prodservers",
+		"loop":        "loop",
+	}
+
+	args, err := Expand([]string{"concerto", "--debug", "prodservers", "--page", "2"}, aliases, testFlags)
+	assert.Nil(err, "Couldn't expand alias")
+	assert.Equal([]string{"concerto", "--debug", "--formatter", "json", "cloud", "servers", "list", "--workspace_id", "prod ws", "--page", "2"}, args, "Unexpected expansion")
+
+	args, err = Expand([]string{"concerto", "--formatter", "ps", "ps"}, aliases, testFlags)
+	assert.Nil(err, "Couldn't expand nested alias")
+	assert.Equal([]string{"concerto", "--formatter", "ps", "--formatter", "json", "cloud", "servers", "list", "--workspace_id", "prod ws"}, args, "Flag values shouldn't be expanded")
+
+	args, err = Expand([]string{"concerto", "cloud", "prodservers"}, aliases, testFlags)
+	assert.Nil(err, "Non aliased commands shouldn't fail")
+	assert.Equal([]string{"concerto", "cloud", "prodservers"}, args, "Only commands should be expanded")
+
+	_, err = Expand([]string{"concerto", "loop"}, aliases, testFlags)
+	assert.NotNil(err, "Recursive aliases should fail")
+}
+
+func TestFlagValue(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("a.xml", FlagValue([]string{"concerto", "-D", "--concerto-config", "a.xml", "cloud"}, testFlags, "concerto-config"), "Unexpected value")
+	assert.Equal("b.xml", FlagValue([]string{"concerto", "--concerto-config=b.xml"}, testFlags, "concerto-config"), "Unexpected value")
+	assert.Equal("", FlagValue([]string{"concerto", "cloud", "--concerto-config", "c.xml"}, testFlags, "concerto-config"), "Command flags shouldn't be taken")
+}
+
+func TestSplit(t *testing.T) {
+	assert := assert.New(t)
+
+	words, err := Split(`cloud servers list  --name "web 1" --tag 'a"b' c\ d`)
+	assert.Nil(err, "Couldn't split")
+	assert.Equal([]string{"cloud", "servers", "list", "--name", "web 1", "--tag", `a"b`, "c d"}, words, "Unexpected words")
+
+	words, err = Split(`--label ""`)
+	assert.Nil(err, "Couldn't split")
+	assert.Equal([]string{"--label", ""}, words, "Empty quoted words should be kept")
+
+	_, err = Split(`list --name "web`)
+	assert.NotNil(err, "Unterminated quotes should fail")
+}
@@ -0,0 +1,114 @@
+// Package attributes edits configuration attributes, the JSON objects templates and Chef roles
+// configure their services with, merging them deeply, and listing and removing keys by their path
+package attributes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Definition is a configuration attribute honored by a service, by its path, such as joomla.db.hostname
+type Definition struct {
+	Path        string
+	Default     interface{}
+	Description string
+}
+
+// Merge deep merges src into dst, src values winning over dst ones. Objects of src are copied,
+// so that later changes to dst don't reach them
+func Merge(dst map[string]interface{}, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			Merge(dstMap, srcMap)
+			continue
+		}
+		if srcIsMap {
+			copied := make(map[string]interface{})
+			Merge(copied, srcMap)
+			v = copied
+		}
+		dst[k] = v
+	}
+}
+
+// Unset removes the key at path, such as nginx.worker_processes, from attrs. It fails when the
+// key isn't there, so that mistyped paths aren't silently ignored
+func Unset(attrs map[string]interface{}, path string) error {
+	keys := strings.Split(path, ".")
+	for i, k := range keys {
+		if k == "" {
+			return fmt.Errorf("%q isn't a valid path", path)
+		}
+		v, ok := attrs[k]
+		if !ok {
+			return fmt.Errorf("%s isn't set", strings.Join(keys[:i+1], "."))
+		}
+		if i == len(keys)-1 {
+			delete(attrs, k)
+			break
+		}
+		if attrs, ok = v.(map[string]interface{}); !ok {
+			return fmt.Errorf("%s isn't an object", strings.Join(keys[:i+1], "."))
+		}
+	}
+	return nil
+}
+
+// Flatten returns the values of attrs that aren't objects, or are empty ones, by their path, such as
+// nginx.worker_processes
+func Flatten(attrs map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	flatten(flat, "", attrs)
+	return flat
+}
+
+func flatten(flat map[string]interface{}, prefix string, attrs map[string]interface{}) {
+	for k, v := range attrs {
+		if m, ok := v.(map[string]interface{}); ok && len(m) > 0 {
+			flatten(flat, prefix+k+".", m)
+			continue
+		}
+		flat[prefix+k] = v
+	}
+}
+
+// Definitions returns the attributes a service honors sorted by path. They're given as in Chef metadata,
+// by their path split with slashes, such as "joomla/db/hostname", with their default and description,
+// or as the objects of their default values
+func Definitions(given map[string]interface{}) []Definition {
+	definitions := []Definition{}
+	for k, v := range given {
+		metadata, ok := v.(map[string]interface{})
+		if !strings.Contains(k, "/") || !ok {
+			for path, value := range Flatten(map[string]interface{}{k: v}) {
+				definitions = append(definitions, Definition{Path: path, Default: value})
+			}
+			continue
+		}
+		description, _ := metadata["description"].(string)
+		if description == "" {
+			description, _ = metadata["display_name"].(string)
+		}
+		definitions = append(definitions, Definition{
+			Path:        strings.Replace(k, "/", ".", -1),
+			Default:     metadata["default"],
+			Description: description,
+		})
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Path < definitions[j].Path })
+	return definitions
+}
+
+// Declared returns whether the attribute at path is one of definitions, is under one, as the keys of
+// an object, or holds some of them, as an empty object does
+func Declared(definitions []Definition, path string) bool {
+	for _, d := range definitions {
+		if d.Path == path || strings.HasPrefix(path, d.Path+".") || strings.HasPrefix(d.Path, path+".") {
+			return true
+		}
+	}
+	return false
+}
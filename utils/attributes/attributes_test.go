@@ -0,0 +1,80 @@
+package attributes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	dst := map[string]interface{}{
+		"nginx": map[string]interface{}{"port": 80, "workers": 2},
+		"ntp":   map[string]interface{}{"servers": []interface{}{"a"}},
+	}
+	src := map[string]interface{}{
+		"nginx": map[string]interface{}{"port": 8080, "ssl": map[string]interface{}{"enabled": true}},
+		"ntp":   map[string]interface{}{"servers": []interface{}{"b", "c"}},
+		"mysql": "5.7",
+	}
+	Merge(dst, src)
+	assert.Equal(t, map[string]interface{}{
+		"nginx": map[string]interface{}{"port": 8080, "workers": 2, "ssl": map[string]interface{}{"enabled": true}},
+		"ntp":   map[string]interface{}{"servers": []interface{}{"b", "c"}},
+		"mysql": "5.7",
+	}, dst, "Attributes weren't deep merged")
+
+	dst["nginx"].(map[string]interface{})["ssl"].(map[string]interface{})["enabled"] = false
+	assert.Equal(t, true, src["nginx"].(map[string]interface{})["ssl"].(map[string]interface{})["enabled"], "Source object was shared")
+}
+
+func TestUnset(t *testing.T) {
+	assert := assert.New(t)
+	attrs := map[string]interface{}{
+		"nginx": map[string]interface{}{"port": 80, "workers": 2},
+		"mysql": "5.7",
+	}
+
+	assert.Nil(Unset(attrs, "nginx.workers"))
+	assert.Nil(Unset(attrs, "mysql"))
+	assert.Equal(map[string]interface{}{"nginx": map[string]interface{}{"port": 80}}, attrs)
+
+	assert.EqualError(Unset(attrs, "nginx.ssl.enabled"), "nginx.ssl isn't set")
+	assert.EqualError(Unset(attrs, "nginx.port.value"), "nginx.port isn't an object")
+	assert.EqualError(Unset(attrs, "nginx..port"), `"nginx..port" isn't a valid path`)
+}
+
+func TestFlatten(t *testing.T) {
+	attrs := map[string]interface{}{
+		"nginx": map[string]interface{}{"port": 80, "ssl": map[string]interface{}{"enabled": true}},
+		"ntp":   map[string]interface{}{"servers": []interface{}{"a"}},
+		"mysql": map[string]interface{}{},
+	}
+	assert.Equal(t, map[string]interface{}{
+		"nginx.port":        80,
+		"nginx.ssl.enabled": true,
+		"ntp.servers":       []interface{}{"a"},
+		"mysql":             map[string]interface{}{},
+	}, Flatten(attrs), "Attributes weren't flattened")
+}
+
+func TestDefinitions(t *testing.T) {
+	assert := assert.New(t)
+
+	definitions := Definitions(map[string]interface{}{
+		"joomla/db/hostname": map[string]interface{}{"default": "127.0.0.1", "description": "Database host"},
+		"joomla/db/name":     map[string]interface{}{"default": "joomla", "display_name": "Database name"},
+		"ntp":                map[string]interface{}{"servers": []interface{}{"pool.ntp.org"}, "sync": map[string]interface{}{}},
+	})
+	assert.Equal([]Definition{
+		{Path: "joomla.db.hostname", Default: "127.0.0.1", Description: "Database host"},
+		{Path: "joomla.db.name", Default: "joomla", Description: "Database name"},
+		{Path: "ntp.servers", Default: []interface{}{"pool.ntp.org"}},
+		{Path: "ntp.sync", Default: map[string]interface{}{}},
+	}, definitions, "Definitions should be read from metadata and default values, sorted by path")
+
+	assert.True(Declared(definitions, "joomla.db.hostname"), "Defined paths should be declared")
+	assert.True(Declared(definitions, "ntp.sync.interval"), "Keys of defined objects should be declared")
+	assert.True(Declared(definitions, "joomla.db"), "Objects holding definitions should be declared")
+	assert.False(Declared(definitions, "joomla.db.host"), "Mistyped paths shouldn't be declared")
+	assert.False(Declared(definitions, "ntp.server"), "Paths sharing a prefix shouldn't be declared")
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GitCommit and BuildDate are set at build time, with
+// go build -ldflags "-X github.com/flexiant/concerto/utils.GitCommit=$(git rev-parse --short HEAD) -X github.com/flexiant/concerto/utils.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	GitCommit = "unknown"
+	BuildDate = "unknown"
+)
+
+// UpdateHint tells how to replace the CLI binary with the latest release
+const UpdateHint = "concerto self-update"
+
+// CompareVersions compares dotted versions such as 0.3.62 or v1.2, returning -1, 0 or 1.
+// Missing components count as zero, and pre-release suffixes are ignored
+func CompareVersions(a string, b string) int {
+	as, bs := versionComponents(a), versionComponents(b)
+	for len(as) < len(bs) {
+		as = append(as, 0)
+	}
+	for len(bs) < len(as) {
+		bs = append(bs, 0)
+	}
+	for i := range as {
+		switch {
+		case as[i] < bs[i]:
+			return -1
+		case as[i] > bs[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionComponents(v string) []int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+ "); i >= 0 {
+		v = v[:i]
+	}
+	var components []int
+	for _, s := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(s)
+		components = append(components, n)
+	}
+	return components
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils/httpcache"
+)
+
+var responseCache *httpcache.Cache
+
+// CacheAPI makes API services keep the responses of GET requests in c, revalidating them with their ETag
+func CacheAPI(c *httpcache.Cache) {
+	responseCache = c
+}
+
+// cacheKey identifies the responses of url received with the client certificate or token of the service, so
+// that profiles sharing a cache don't see each other's responses
+func (hcs *HTTPConcertoservice) cacheKey(url string) string {
+	if hcs.config.UsesToken() {
+		return fmt.Sprintf("%v %s", hcs.config.Token, url)
+	}
+	return fmt.Sprintf("%s %s", hcs.config.Certificate.Cert, url)
+}
+
+// cachedResponse returns the cached response of a request, if any
+func (hcs *HTTPConcertoservice) cachedResponse(method string, url string) *httpcache.Entry {
+	if responseCache == nil || method != "GET" {
+		return nil
+	}
+	return responseCache.Get(hcs.cacheKey(url))
+}
+
+// cacheResponse keeps the responses of GET requests, returning the cached one when the API tells it hasn't
+// changed. Any change made through the API clears the cache, as it may make cached responses stale
+func (hcs *HTTPConcertoservice) cacheResponse(method string, url string, cached *httpcache.Entry, etag string, body []byte, status int) ([]byte, int) {
+	if responseCache == nil {
+		return body, status
+	}
+	var err error
+	switch {
+	case method == "GET" && status == http.StatusNotModified && cached != nil:
+		log.Debugf("Cached response of %s hasn't changed", url)
+		err = responseCache.Put(hcs.cacheKey(url), *cached)
+		body, status = cached.Body, cached.Status
+	case method == "GET" && status == http.StatusOK && (etag != "" || responseCache.MaxAge() > 0):
+		err = responseCache.Put(hcs.cacheKey(url), httpcache.Entry{Status: status, ETag: etag, Body: body})
+	case method != "GET" && method != "HEAD" && status < 300:
+		_, err = responseCache.Clear()
+	}
+	if err != nil {
+		log.Debugf("Couldn't update the cache of API responses: %s", err)
+	}
+	return body, status
+}
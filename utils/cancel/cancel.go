@@ -0,0 +1,142 @@
+package cancel
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils/shutdown"
+)
+
+const (
+	// TimeoutExitCode is the exit code of commands exceeding their maximum duration, as timeout(1) does
+	TimeoutExitCode = 124
+	// InterruptExitCode is the exit code of commands interrupted with Ctrl-C or SIGTERM
+	InterruptExitCode = 130
+)
+
+// Error is the reason a command was cancelled
+type Error struct {
+	Reason string
+	Code   int
+}
+
+func (e *Error) Error() string {
+	return e.Reason
+}
+
+var (
+	mu        sync.Mutex
+	done      = make(chan struct{})
+	cancelled *Error
+
+	// grace is the time a cancelled command has to clean up before the process is stopped
+	grace = 10 * time.Second
+	// exit is replaced in tests
+	exit = shutdown.Exit
+)
+
+// Initialize cancels the command once maxDuration has elapsed, if positive, or when the process
+// is interrupted. A second interrupt stops the process right away
+func Initialize(maxDuration time.Duration) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for s := range signals {
+			reason := "Interrupted"
+			if s == syscall.SIGTERM {
+				reason = "Terminated"
+			}
+			if !Cancel(&Error{Reason: reason, Code: InterruptExitCode}) {
+				log.Warn("Stopping right away")
+				exit(InterruptExitCode)
+			}
+		}
+	}()
+
+	if maxDuration > 0 {
+		time.AfterFunc(maxDuration, func() {
+			Cancel(&Error{Reason: fmt.Sprintf("Command exceeded its maximum duration of %s", maxDuration), Code: TimeoutExitCode})
+		})
+	}
+}
+
+// Cancel makes in-flight requests and pending work stop. Commands that don't finish within the
+// grace period are stopped. It returns false when the command had already been cancelled
+func Cancel(e *Error) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if cancelled != nil {
+		return false
+	}
+	cancelled = e
+	close(done)
+
+	log.Warnf("%s. Cancelling in-flight requests and pending work", e.Reason)
+	time.AfterFunc(grace, func() {
+		log.Errorf("Command didn't stop within %s", grace)
+		exit(e.Code)
+	})
+	return true
+}
+
+// Done returns a channel closed once the command is cancelled
+func Done() <-chan struct{} {
+	return done
+}
+
+var (
+	ctx     context.Context
+	ctxOnce sync.Once
+)
+
+// Context returns a context cancelled once the command is cancelled. Requests sent with it, or with contexts
+// derived from it, are aborted on Ctrl-C or when the command exceeds its maximum duration
+func Context() context.Context {
+	ctxOnce.Do(func() {
+		var cancelCtx context.CancelFunc
+		ctx, cancelCtx = context.WithCancel(context.Background())
+		go func() {
+			<-done
+			cancelCtx()
+		}()
+	})
+	return ctx
+}
+
+// Err returns why the command was cancelled, or nil
+func Err() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if cancelled == nil {
+		return nil
+	}
+	return cancelled
+}
+
+// ExitCode returns the exit code of the cancelled command, or code when it wasn't cancelled
+func ExitCode(code int) int {
+	mu.Lock()
+	defer mu.Unlock()
+	if cancelled == nil {
+		return code
+	}
+	return cancelled.Code
+}
+
+// Sleep waits for d, unless the command is cancelled meanwhile. It returns whether d elapsed
+func Sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-done:
+		return false
+	}
+}
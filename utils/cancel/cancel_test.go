@@ -0,0 +1,88 @@
+package cancel
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func reset() {
+	done = make(chan struct{})
+	cancelled = nil
+	ctxOnce = sync.Once{}
+}
+
+func TestCancel(t *testing.T) {
+	assert := assert.New(t)
+	reset()
+	defer reset()
+
+	exited := make(chan int, 1)
+	grace = 10 * time.Millisecond
+	exit = func(code int) { exited <- code }
+
+	assert.Nil(Err(), "Commands shouldn't start cancelled")
+	assert.Equal(1, ExitCode(1), "Exit code shouldn't change till cancelled")
+
+	assert.True(Cancel(&Error{Reason: "Interrupted", Code: InterruptExitCode}), "First cancel should succeed")
+	assert.False(Cancel(&Error{Reason: "Timeout", Code: TimeoutExitCode}), "Commands can only be cancelled once")
+	assert.EqualError(Err(), "Interrupted", "Unexpected reason")
+	assert.Equal(InterruptExitCode, ExitCode(1), "Unexpected exit code")
+
+	select {
+	case <-Done():
+	default:
+		t.Error("Done channel should be closed")
+	}
+	select {
+	case code := <-exited:
+		assert.Equal(InterruptExitCode, code, "Process should stop with the cancel exit code")
+	case <-time.After(time.Second):
+		t.Error("Process should stop after the grace period")
+	}
+}
+
+func TestContext(t *testing.T) {
+	assert := assert.New(t)
+	reset()
+	defer reset()
+	grace = time.Hour
+
+	ctx := Context()
+	assert.Nil(ctx.Err(), "Context shouldn't start cancelled")
+	Cancel(&Error{Reason: "Interrupted", Code: InterruptExitCode})
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Error("Context should be cancelled with the command")
+	}
+}
+
+func TestSleep(t *testing.T) {
+	reset()
+	defer reset()
+
+	assert.True(t, Sleep(time.Millisecond), "Sleep should elapse")
+
+	exit = func(code int) {}
+	time.AfterFunc(10*time.Millisecond, func() { Cancel(&Error{Reason: "Timeout", Code: TimeoutExitCode}) })
+	start := time.Now()
+	assert.False(t, Sleep(time.Minute), "Sleep should be interrupted")
+	assert.True(t, time.Since(start) < time.Second, "Sleep should return when cancelled")
+}
+
+func TestInitializeMaxDuration(t *testing.T) {
+	reset()
+	defer reset()
+	exit = func(code int) {}
+
+	Initialize(10 * time.Millisecond)
+	select {
+	case <-Done():
+		assert.Equal(t, TimeoutExitCode, ExitCode(0), "Unexpected exit code")
+	case <-time.After(time.Second):
+		t.Error("Command should be cancelled after its maximum duration")
+	}
+}
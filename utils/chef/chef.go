@@ -0,0 +1,131 @@
+package chef
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultSource is the cookbooks source written in Berksfiles
+const DefaultSource = "https://supermarket.chef.io"
+
+// Cookbook is a cookbook required by a blueprint, with its version when pinned
+type Cookbook struct {
+	Name    string
+	Version string
+}
+
+// Environment is a Chef environment as read by knife and chef-server
+type Environment struct {
+	Name               string            `json:"name"`
+	Description        string            `json:"description"`
+	JSONClass          string            `json:"json_class"`
+	ChefType           string            `json:"chef_type"`
+	CookbookVersions   map[string]string `json:"cookbook_versions"`
+	DefaultAttributes  *json.RawMessage  `json:"default_attributes"`
+	OverrideAttributes map[string]string `json:"override_attributes"`
+}
+
+var invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// EnvironmentName converts a template name into a valid Chef environment name
+func EnvironmentName(name string) string {
+	s := strings.Trim(invalidNameChars.ReplaceAllString(strings.TrimSpace(name), "_"), "_")
+	if s == "" {
+		return "concerto"
+	}
+	return s
+}
+
+// ParseServiceList extracts the cookbooks used by a service list.
+// Services are recipes such as "nginx", "nginx::default" or "nginx@1.2.0::default"
+func ParseServiceList(services []string) ([]Cookbook, error) {
+	versions := make(map[string]string)
+	for _, service := range services {
+		recipe := strings.TrimSpace(service)
+		if recipe == "" {
+			continue
+		}
+		if i := strings.Index(recipe, "::"); i >= 0 {
+			recipe = recipe[:i]
+		}
+		name, version := recipe, ""
+		if i := strings.Index(recipe, "@"); i >= 0 {
+			name, version = recipe[:i], recipe[i+1:]
+		}
+		if name == "" {
+			return nil, fmt.Errorf("Invalid service %s", service)
+		}
+		if v, ok := versions[name]; ok && v != "" && version != "" && v != version {
+			return nil, fmt.Errorf("Cookbook %s is required with versions %s and %s", name, v, version)
+		}
+		if version != "" || versions[name] == "" {
+			versions[name] = version
+		}
+	}
+
+	cookbooks := make([]Cookbook, 0, len(versions))
+	for name, version := range versions {
+		cookbooks = append(cookbooks, Cookbook{Name: name, Version: version})
+	}
+	sort.Sort(byName(cookbooks))
+	return cookbooks, nil
+}
+
+// NewEnvironment returns a Chef environment pinning cookbooks and using attributes as default attributes
+func NewEnvironment(name string, description string, cookbooks []Cookbook, attributes *json.RawMessage) *Environment {
+	env := &Environment{
+		Name:               name,
+		Description:        description,
+		JSONClass:          "Chef::Environment",
+		ChefType:           "environment",
+		CookbookVersions:   make(map[string]string),
+		DefaultAttributes:  attributes,
+		OverrideAttributes: make(map[string]string),
+	}
+	for _, cb := range cookbooks {
+		if cb.Version != "" {
+			env.CookbookVersions[cb.Name] = "= " + cb.Version
+		}
+	}
+	if env.DefaultAttributes == nil {
+		empty := json.RawMessage("{}")
+		env.DefaultAttributes = &empty
+	}
+	return env
+}
+
+// WriteEnvironment writes env as indented JSON
+func WriteEnvironment(w io.Writer, env *Environment) error {
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteBerksfile writes a Berksfile requiring cookbooks from source
+func WriteBerksfile(w io.Writer, source string, cookbooks []Cookbook) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "source %q\n\n", source)
+	for _, cb := range cookbooks {
+		if cb.Version != "" {
+			fmt.Fprintf(buf, "cookbook %q, %q\n", cb.Name, "= "+cb.Version)
+		} else {
+			fmt.Fprintf(buf, "cookbook %q\n", cb.Name)
+		}
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+type byName []Cookbook
+
+func (a byName) Len() int           { return len(a) }
+func (a byName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byName) Less(i, j int) bool { return a[i].Name < a[j].Name }
@@ -0,0 +1,42 @@
+package chef
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseServiceList(t *testing.T) {
+	assert := assert.New(t)
+
+	cookbooks, err := ParseServiceList([]string{"nginx::default", "php@5.1.0::fpm", "nginx", "php", "redis@2.0.0"})
+	assert.Nil(err, "Couldn't parse service list")
+	assert.Equal([]Cookbook{{"nginx", ""}, {"php", "5.1.0"}, {"redis", "2.0.0"}}, cookbooks, "Unexpected cookbooks")
+
+	_, err = ParseServiceList([]string{"php@5.1.0", "php@5.2.0::fpm"})
+	assert.NotNil(err, "Conflicting versions should fail")
+}
+
+func TestWriteEnvironment(t *testing.T) {
+	assert := assert.New(t)
+
+	attrs := json.RawMessage(`{"nginx":{"port":8080}}`)
+	env := NewEnvironment(EnvironmentName("Web servers"), "", []Cookbook{{"nginx", ""}, {"php", "5.1.0"}}, &attrs)
+
+	buf := new(bytes.Buffer)
+	assert.Nil(WriteEnvironment(buf, env), "Couldn't write environment")
+
+	var written map[string]interface{}
+	assert.Nil(json.Unmarshal(buf.Bytes(), &written), "Environment should be valid JSON")
+	assert.Equal("Web_servers", written["name"], "Unexpected environment name")
+	assert.Equal(map[string]interface{}{"php": "= 5.1.0"}, written["cookbook_versions"], "Only pinned cookbooks should be constrained")
+	assert.Equal(map[string]interface{}{"nginx": map[string]interface{}{"port": 8080.0}}, written["default_attributes"], "Attributes should be kept")
+}
+
+func TestWriteBerksfile(t *testing.T) {
+	buf := new(bytes.Buffer)
+	assert.Nil(t, WriteBerksfile(buf, DefaultSource, []Cookbook{{"nginx", ""}, {"php", "5.1.0"}}), "Couldn't write Berksfile")
+	assert.Equal(t, "source \"https://supermarket.chef.io\"\n\ncookbook \"nginx\"\ncookbook \"php\", \"= 5.1.0\"\n", buf.String(), "Unexpected Berksfile")
+}
@@ -0,0 +1,120 @@
+package chef
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/flexiant/concerto/utils/attributes"
+)
+
+// Role is a Chef role as written by knife role show -F json
+type Role struct {
+	Name               string                 `json:"name"`
+	Description        string                 `json:"description"`
+	RunList            []string               `json:"run_list"`
+	DefaultAttributes  map[string]interface{} `json:"default_attributes"`
+	OverrideAttributes map[string]interface{} `json:"override_attributes"`
+}
+
+// LoadRole reads a role from a JSON file. Roles nested in its run list are read from
+// files named after them in the same directory, and their recipes and attributes merged
+func LoadRole(file string) (*Role, error) {
+	return loadRole(file, make(map[string]bool))
+}
+
+func loadRole(file string, loading map[string]bool) (*Role, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	role := new(Role)
+	if err = json.Unmarshal(data, role); err != nil {
+		return nil, fmt.Errorf("Couldn't parse role %s: %s", file, err)
+	}
+	if role.Name == "" {
+		role.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	if loading[role.Name] {
+		return nil, fmt.Errorf("Role %s includes itself", role.Name)
+	}
+	loading[role.Name] = true
+	defer delete(loading, role.Name)
+
+	expanded := &Role{
+		Name:               role.Name,
+		Description:        role.Description,
+		DefaultAttributes:  make(map[string]interface{}),
+		OverrideAttributes: make(map[string]interface{}),
+	}
+	for _, item := range role.RunList {
+		kind, name, err := parseRunListItem(item)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid run list of role %s: %s", role.Name, err)
+		}
+		if kind == "recipe" {
+			expanded.RunList = appendUnique(expanded.RunList, name)
+			continue
+		}
+		nested, err := loadRole(filepath.Join(filepath.Dir(file), name+".json"), loading)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't expand role %s of role %s: %s", name, role.Name, err)
+		}
+		for _, recipe := range nested.RunList {
+			expanded.RunList = appendUnique(expanded.RunList, recipe)
+		}
+		attributes.Merge(expanded.DefaultAttributes, nested.DefaultAttributes)
+		attributes.Merge(expanded.OverrideAttributes, nested.OverrideAttributes)
+	}
+	// attributes of the including role take precedence over those of nested ones
+	attributes.Merge(expanded.DefaultAttributes, role.DefaultAttributes)
+	attributes.Merge(expanded.OverrideAttributes, role.OverrideAttributes)
+	return expanded, nil
+}
+
+// ServiceList returns the recipes of the role in the format of template service lists
+func (r *Role) ServiceList() []string {
+	services := make([]string, len(r.RunList))
+	copy(services, r.RunList)
+	return services
+}
+
+// Attributes returns the configuration attributes of the role, with override attributes applied over default ones
+func (r *Role) Attributes() (*json.RawMessage, error) {
+	attrs := make(map[string]interface{})
+	attributes.Merge(attrs, r.DefaultAttributes)
+	attributes.Merge(attrs, r.OverrideAttributes)
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return nil, err
+	}
+	raw := json.RawMessage(data)
+	return &raw, nil
+}
+
+// parseRunListItem splits items such as "recipe[nginx::default]", "role[base]" or "nginx"
+func parseRunListItem(item string) (kind string, name string, err error) {
+	item = strings.TrimSpace(item)
+	kind, name = "recipe", item
+	if i := strings.Index(item, "["); i >= 0 {
+		if !strings.HasSuffix(item, "]") {
+			return "", "", fmt.Errorf("malformed item %q", item)
+		}
+		kind, name = item[:i], strings.TrimSpace(item[i+1:len(item)-1])
+	}
+	if (kind != "recipe" && kind != "role") || name == "" {
+		return "", "", fmt.Errorf("unsupported item %q", item)
+	}
+	return kind, name, nil
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, l := range list {
+		if l == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,57 @@
+package chef
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeRoles(t *testing.T, roles map[string]string) string {
+	dir, err := ioutil.TempDir("", "roles")
+	assert.Nil(t, err, "Couldn't create temporary directory")
+	for name, content := range roles {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name+".json"), []byte(content), 0600), "Couldn't write role")
+	}
+	return dir
+}
+
+func TestLoadRole(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := writeRoles(t, map[string]string{
+		"base": `{"name": "base", "run_list": ["recipe[ntp]", "recipe[users::sysadmins]"],
+			"default_attributes": {"ntp": {"servers": ["0.pool.ntp.org"]}, "nginx": {"port": 80, "workers": 2}}}`,
+		"web": `{"name": "web", "description": "Web servers", "run_list": ["role[base]", "recipe[nginx@1.2.0]", "ntp"],
+			"default_attributes": {"nginx": {"port": 8080}},
+			"override_attributes": {"nginx": {"workers": 4}}}`,
+	})
+	defer os.RemoveAll(dir)
+
+	role, err := LoadRole(filepath.Join(dir, "web.json"))
+	assert.Nil(err, "Couldn't load role")
+	assert.Equal("web", role.Name, "Unexpected name")
+	assert.Equal([]string{"ntp", "users::sysadmins", "nginx@1.2.0"}, role.ServiceList(), "Nested roles should be expanded")
+
+	attrs, err := role.Attributes()
+	assert.Nil(err, "Couldn't build attributes")
+	assert.JSONEq(`{"ntp": {"servers": ["0.pool.ntp.org"]}, "nginx": {"port": 8080, "workers": 4}}`, string(*attrs), "Unexpected attributes")
+}
+
+func TestLoadRoleInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := writeRoles(t, map[string]string{
+		"loop":    `{"name": "loop", "run_list": ["role[loop]"]}`,
+		"missing": `{"name": "missing", "run_list": ["role[absent]"]}`,
+		"bad":     `{"name": "bad", "run_list": ["policy[web]"]}`,
+	})
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"loop", "missing", "bad"} {
+		_, err := LoadRole(filepath.Join(dir, name+".json"))
+		assert.NotNil(err, "Role %s should fail", name)
+	}
+}
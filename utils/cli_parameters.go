@@ -7,19 +7,15 @@ import (
 	"reflect"
 )
 
-// FlagConvertParams converts cli parameters in API callable params
-func FlagConvertParams(c *cli.Context) *map[string]interface{} {
-	v := make(map[string]interface{})
-	for _, flag := range c.FlagNames() {
-		if c.IsSet(flag) {
-			v[flag] = c.String(flag)
-		}
-	}
-	return &v
+// SecretFlagValue returns the value of a flag, reading it from Vault or the OS keyring when it's a reference.
+// Only flags expecting secrets are read this way, so that other values are sent as typed
+func SecretFlagValue(c *cli.Context, flag string) (string, error) {
+	return resolveSecret(c.String(flag))
 }
 
-// FlagConvertParamsJSON converts cli parameters in API callable params, and encodes JSON parameters
-func FlagConvertParamsJSON(c *cli.Context, jsonFlags []string) (*map[string]interface{}, error) {
+// FlagConvertParamsJSON converts cli parameters in API callable params, and encodes JSON parameters.
+// References in secretFlags are read from Vault or the OS keyring
+func FlagConvertParamsJSON(c *cli.Context, jsonFlags []string, secretFlags ...string) (*map[string]interface{}, error) {
 	v := make(map[string]interface{})
 	for _, flag := range c.FlagNames() {
 		if c.IsSet(flag) {
@@ -35,16 +31,27 @@ func FlagConvertParamsJSON(c *cli.Context, jsonFlags []string) (*map[string]inte
 				}
 			}
 
+			value := c.String(flag)
+			for _, secret := range secretFlags {
+				if secret == flag {
+					var err error
+					if value, err = SecretFlagValue(c, flag); err != nil {
+						return nil, fmt.Errorf("flag %s couldn't be read. %s", flag, err)
+					}
+					break
+				}
+			}
+
 			if isJSON {
 				// parse json before assigning to map
 				var p interface{}
-				err := json.Unmarshal([]byte(c.String(flag)), &p)
+				err := json.Unmarshal([]byte(value), &p)
 				if err != nil {
 					return nil, fmt.Errorf("flag %s isn't a valid JSON. %s", flag, err)
 				}
 				v[flag] = p
 			} else {
-				v[flag] = c.String(flag)
+				v[flag] = value
 			}
 		}
 	}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlagConvertParamsJSONSecretFlags(t *testing.T) {
+	assert := assert.New(t)
+
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		fmt.Fprint(w, `{"data":{"data":{"key":"AKIA"},"metadata":{"version":1}}}`)
+	}))
+	defer server.Close()
+	os.Setenv("VAULT_ADDR", server.URL)
+	os.Setenv("VAULT_TOKEN", "testtoken")
+	defer os.Unsetenv("VAULT_ADDR")
+	defer os.Unsetenv("VAULT_TOKEN")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("name", "", "")
+	fs.String("credentials", "", "")
+	fs.Parse([]string{"--name", "vault:not-a-reference", "--credentials", "vault:secret/data/aws"})
+	c := cli.NewContext(nil, fs, nil)
+	c.Command = cli.Command{Flags: []cli.Flag{cli.StringFlag{Name: "name"}, cli.StringFlag{Name: "credentials"}}}
+
+	params, err := FlagConvertParamsJSON(c, []string{"credentials"}, "credentials")
+	assert.Nil(err, "Couldn't convert parameters")
+	assert.Equal("vault:not-a-reference", (*params)["name"], "Flags not expecting secrets should be sent as typed")
+	assert.Equal(map[string]interface{}{"key": "AKIA"}, (*params)["credentials"], "Secret flags should be read from Vault")
+	assert.Equal(1, lookups, "Only secret flags should be looked up")
+
+	params, err = FlagConvertParamsJSON(c, nil)
+	assert.Nil(err, "Couldn't convert parameters")
+	assert.Equal("vault:secret/data/aws", (*params)["credentials"], "Flags not opting in should be sent as typed")
+	assert.Equal(1, lookups, "Flags not opting in shouldn't be looked up")
+}
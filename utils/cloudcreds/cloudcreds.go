@@ -0,0 +1,292 @@
+package cloudcreds
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/mitchellh/go-homedir"
+)
+
+// supported providers
+const (
+	AWS   = "aws"
+	GCP   = "gcp"
+	Azure = "azure"
+)
+
+// Credentials are values found in the local credential chain of a provider, by name
+type Credentials map[string]string
+
+// ProviderKind returns which local credential chain applies to a Concerto cloud provider name
+func ProviderKind(providerName string) (string, error) {
+	name := strings.ToLower(providerName)
+	switch {
+	case strings.Contains(name, "aws") || strings.Contains(name, "amazon") || strings.Contains(name, "ec2"):
+		return AWS, nil
+	case strings.Contains(name, "google") || strings.Contains(name, "gce") || strings.Contains(name, "gcp"):
+		return GCP, nil
+	case strings.Contains(name, "azure") || strings.Contains(name, "microsoft"):
+		return Azure, nil
+	}
+	return "", fmt.Errorf("Default credentials aren't supported for cloud provider %s. Please, use --credentials", providerName)
+}
+
+// Load reads credentials from the local credential chain of provider kind
+func Load(kind string) (Credentials, error) {
+	switch kind {
+	case AWS:
+		return loadAWS()
+	case GCP:
+		return loadGCP()
+	case Azure:
+		return loadAzure()
+	}
+	return nil, fmt.Errorf("Unknown provider %s", kind)
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+func normalize(name string) string {
+	return nonAlphanumeric.ReplaceAllString(strings.ToLower(name), "")
+}
+
+// aliases lists, by provider kind, names used by Concerto providers for credentials known under another name
+// locally. Aliases are unique within each provider, so that every one maps to a single credential
+var aliases = map[string]map[string][]string{
+	AWS: {
+		"accesskeyid":     {"accesskey", "awsaccesskeyid", "key"},
+		"secretaccesskey": {"secretkey", "awssecretaccesskey", "secret"},
+		"sessiontoken":    {"securitytoken", "awssessiontoken", "token"},
+	},
+	GCP: {
+		"projectid":       {"project"},
+		"clientemail":     {"email", "serviceaccount", "serviceaccountemail"},
+		"privatekey":      {"key"},
+		"credentialsjson": {"credentials", "jsonkey", "keyjson", "serviceaccountjson"},
+	},
+	Azure: {
+		"subscriptionid": {"subscription"},
+		"tenantid":       {"tenant", "directoryid"},
+		"clientid":       {"applicationid", "appid"},
+		"clientsecret":   {"applicationsecret", "password", "secret"},
+	},
+}
+
+// Map assigns a value to each of the required credentials, read from the local credential chain of provider
+// kind. Required credentials without value are returned as missing
+func Map(kind string, creds Credentials, required []string) (payload map[string]string, missing []string) {
+	byName := make(map[string]string)
+	for k, v := range creds {
+		byName[normalize(k)] = v
+	}
+	for k, names := range aliases[kind] {
+		v, ok := byName[k]
+		if !ok {
+			continue
+		}
+		for _, alias := range names {
+			if _, exists := byName[alias]; !exists {
+				byName[alias] = v
+			}
+		}
+	}
+
+	payload = make(map[string]string)
+	for _, r := range required {
+		if v, ok := byName[normalize(r)]; ok && v != "" {
+			payload[r] = v
+		} else {
+			missing = append(missing, r)
+		}
+	}
+	return payload, missing
+}
+
+// Describe lists credential names with masked values, so that they can be confirmed before uploading
+func Describe(payload map[string]string) []string {
+	names := make([]string, 0, len(payload))
+	for k := range payload {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, len(names))
+	for i, k := range names {
+		lines[i] = fmt.Sprintf("%s: %s", k, mask(payload[k]))
+	}
+	return lines
+}
+
+// IsSecret returns whether a credential should be hidden while typed, judging by its name
+func IsSecret(name string) bool {
+	n := normalize(name)
+	for _, word := range []string{"secret", "password", "token", "privatekey", "apikey"} {
+		if strings.Contains(n, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func mask(v string) string {
+	if len(v) <= 8 {
+		return strings.Repeat("*", len(v))
+	}
+	return v[:4] + strings.Repeat("*", 8) + fmt.Sprintf(" (%d characters)", len(v))
+}
+
+func loadAWS() (Credentials, error) {
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		log.Debug("Using AWS credentials from environment")
+		return Credentials{
+			"access_key_id":     id,
+			"secret_access_key": os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			"session_token":     os.Getenv("AWS_SESSION_TOKEN"),
+			"region":            os.Getenv("AWS_DEFAULT_REGION"),
+		}, nil
+	}
+
+	file := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if file == "" {
+		home, err := homedir.Dir()
+		if err != nil {
+			return nil, err
+		}
+		file = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read AWS credentials: %s", err)
+	}
+	defer f.Close()
+
+	sections, err := parseINI(f)
+	if err != nil {
+		return nil, err
+	}
+	section, ok := sections[profile]
+	if !ok {
+		return nil, fmt.Errorf("AWS profile %s not found in %s", profile, file)
+	}
+	log.Debugf("Using AWS credentials from profile %s in %s", profile, file)
+	return Credentials{
+		"access_key_id":     section["aws_access_key_id"],
+		"secret_access_key": section["aws_secret_access_key"],
+		"session_token":     section["aws_session_token"],
+	}, nil
+}
+
+// parseINI parses an INI file into key/values by section
+func parseINI(f *os.File) (map[string]map[string]string, error) {
+	sections := make(map[string]map[string]string)
+	current := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(strings.TrimPrefix(line[1:len(line)-1], "profile "))
+			sections[current] = make(map[string]string)
+			continue
+		}
+		if i := strings.Index(line, "="); i >= 0 && current != "" {
+			sections[current][strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+		}
+	}
+	return sections, scanner.Err()
+}
+
+func loadGCP() (Credentials, error) {
+	file := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if file == "" {
+		home, err := homedir.Dir()
+		if err != nil {
+			return nil, err
+		}
+		file = filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read Google application default credentials: %s", err)
+	}
+	var fields map[string]interface{}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("Couldn't parse %s: %s", file, err)
+	}
+	log.Debugf("Using Google credentials from %s", file)
+
+	creds := Credentials{"credentials_json": string(data)}
+	for k, v := range fields {
+		if s, ok := v.(string); ok {
+			creds[k] = s
+		}
+	}
+	if p := os.Getenv("GOOGLE_CLOUD_PROJECT"); p != "" && creds["project_id"] == "" {
+		creds["project_id"] = p
+	}
+	return creds, nil
+}
+
+func loadAzure() (Credentials, error) {
+	creds := Credentials{
+		"subscription_id": os.Getenv("AZURE_SUBSCRIPTION_ID"),
+		"tenant_id":       os.Getenv("AZURE_TENANT_ID"),
+		"client_id":       os.Getenv("AZURE_CLIENT_ID"),
+		"client_secret":   os.Getenv("AZURE_CLIENT_SECRET"),
+	}
+	if creds["subscription_id"] != "" && creds["tenant_id"] != "" {
+		log.Debug("Using Azure credentials from environment")
+		return creds, nil
+	}
+
+	// az CLI stores the selected subscription in its profile
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, err
+	}
+	file := filepath.Join(home, ".azure", "azureProfile.json")
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read Azure CLI profile: %s", err)
+	}
+	var profile struct {
+		Subscriptions []struct {
+			ID        string `json:"id"`
+			TenantID  string `json:"tenantId"`
+			IsDefault bool   `json:"isDefault"`
+		} `json:"subscriptions"`
+	}
+	// az CLI writes the profile with a byte order mark
+	if err = json.Unmarshal([]byte(strings.TrimPrefix(string(data), "\ufeff")), &profile); err != nil {
+		return nil, fmt.Errorf("Couldn't parse %s: %s", file, err)
+	}
+	for _, s := range profile.Subscriptions {
+		if s.IsDefault {
+			log.Debugf("Using Azure subscription %s from %s", s.ID, file)
+			if creds["subscription_id"] == "" {
+				creds["subscription_id"] = s.ID
+			}
+			if creds["tenant_id"] == "" {
+				creds["tenant_id"] = s.TenantID
+			}
+			return creds, nil
+		}
+	}
+	return nil, fmt.Errorf("No default subscription found in %s. Please, run az login", file)
+}
@@ -0,0 +1,94 @@
+package cloudcreds
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderKind(t *testing.T) {
+	assert := assert.New(t)
+
+	kind, err := ProviderKind("Amazon Web Services")
+	assert.Nil(err, "AWS should be supported")
+	assert.Equal(AWS, kind, "Unexpected provider kind")
+
+	kind, _ = ProviderKind("Google Compute Engine")
+	assert.Equal(GCP, kind, "Unexpected provider kind")
+
+	kind, _ = ProviderKind("Microsoft Azure")
+	assert.Equal(Azure, kind, "Unexpected provider kind")
+
+	_, err = ProviderKind("Mock")
+	assert.NotNil(err, "Unknown providers should fail")
+}
+
+func TestMap(t *testing.T) {
+	assert := assert.New(t)
+
+	creds := Credentials{"access_key_id": "AKIA", "secret_access_key": "s3cr3t"}
+	payload, missing := Map(AWS, creds, []string{"access_key", "Secret Key", "region"})
+	assert.Equal(map[string]string{"access_key": "AKIA", "Secret Key": "s3cr3t"}, payload, "Credentials should be mapped by alias")
+	assert.Equal([]string{"region"}, missing, "Region should be missing")
+
+	// key and secret are aliases of different credentials for each provider
+	payload, _ = Map(AWS, Credentials{"access_key_id": "AKIA", "secret_access_key": "s3cr3t"}, []string{"key", "secret"})
+	assert.Equal(map[string]string{"key": "AKIA", "secret": "s3cr3t"}, payload, "AWS aliases should be used")
+	payload, _ = Map(GCP, Credentials{"private_key": "PEM", "client_email": "sa@example.com"}, []string{"key", "email"})
+	assert.Equal(map[string]string{"key": "PEM", "email": "sa@example.com"}, payload, "Google aliases should be used")
+	payload, _ = Map(Azure, Credentials{"client_id": "app", "client_secret": "pass"}, []string{"appid", "secret"})
+	assert.Equal(map[string]string{"appid": "app", "secret": "pass"}, payload, "Azure aliases should be used")
+}
+
+func TestAliasesAreUnique(t *testing.T) {
+	for kind, names := range aliases {
+		seen := make(map[string]string)
+		for name, list := range names {
+			for _, alias := range list {
+				if other, ok := seen[alias]; ok {
+					t.Errorf("%s alias %s is used for both %s and %s", kind, alias, other, name)
+				}
+				seen[alias] = name
+			}
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	lines := Describe(map[string]string{"secret": "0123456789abcdef", "id": "abc"})
+	assert.Equal(t, []string{"id: ***", "secret: 0123******** (16 characters)"}, lines, "Values should be masked")
+}
+
+func TestLoadAWSProfile(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "concerto-creds")
+	assert.Nil(err, "Couldn't create temp dir")
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "credentials")
+	ini := "[default]\naws_access_key_id = AKIADEFAULT\naws_secret_access_key = default\n\n[work]\naws_access_key_id=AKIAWORK\naws_secret_access_key=work\n"
+	assert.Nil(ioutil.WriteFile(file, []byte(ini), 0600), "Couldn't write credentials file")
+
+	os.Unsetenv("AWS_ACCESS_KEY_ID")
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", file)
+	os.Setenv("AWS_PROFILE", "work")
+	defer os.Unsetenv("AWS_SHARED_CREDENTIALS_FILE")
+	defer os.Unsetenv("AWS_PROFILE")
+
+	creds, err := Load(AWS)
+	assert.Nil(err, "Couldn't load AWS credentials")
+	assert.Equal("AKIAWORK", creds["access_key_id"], "Selected profile should be used")
+	assert.Equal("work", creds["secret_access_key"], "Selected profile should be used")
+}
+
+func TestIsSecret(t *testing.T) {
+	assert.True(t, IsSecret("secret_access_key"), "Secret keys should be hidden")
+	assert.True(t, IsSecret("Password"), "Passwords should be hidden")
+	assert.True(t, IsSecret("private-key"), "Private keys should be hidden")
+	assert.False(t, IsSecret("access_key_id"), "Key IDs shouldn't be hidden")
+	assert.False(t, IsSecret("username"), "User names shouldn't be hidden")
+}
@@ -2,23 +2,17 @@ package utils
 
 import (
 	"fmt"
-	log "github.com/Sirupsen/logrus"
-	"github.com/codegangsta/cli"
 	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/utils/flags"
 )
 
+// FlagsRequired reports every missing flag at once, with the command usage, and exits
 // TODO remove after migration
-func FlagsRequired(c *cli.Context, flags []string) {
-	parameters := false
-	for _, flag := range flags {
-		if !c.IsSet(flag) {
-			log.Warn(fmt.Sprintf("Please use parameter --%s", flag))
-			parameters = true
-		}
-	}
-
-	if parameters {
-		fmt.Printf("\n")
+func FlagsRequired(c *cli.Context, required []string) {
+	if err := flags.New(c).Required(required...).Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Incorrect usage.\n -> %s\n", err)
 		cli.ShowCommandHelp(c, c.Command.Name)
 		os.Exit(2)
 	}
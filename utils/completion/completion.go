@@ -0,0 +1,165 @@
+package completion
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/codegangsta/cli"
+)
+
+// Resolver returns the values a flag may take, given the names of the commands it's used in
+type Resolver func(flag string, path []string) []string
+
+// Candidates returns the words that may complete the last of words, which are the arguments
+// typed after the program name. Candidates may carry a description after a tab
+func Candidates(commands []cli.Command, flags []cli.Flag, words []string, resolve Resolver) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+
+	var path []string
+	for i := 0; i < len(words)-1; i++ {
+		word := words[i]
+		if strings.HasPrefix(word, "-") {
+			name := strings.TrimLeft(word, "-")
+			if strings.Contains(name, "=") {
+				continue
+			}
+			f := lookup(flags, name)
+			if f == nil || isBool(f) {
+				continue
+			}
+			if i == len(words)-2 {
+				if resolve == nil {
+					return nil
+				}
+				return filter(resolve(primaryName(f), path), current)
+			}
+			i++
+			continue
+		}
+		if command := find(commands, word); command != nil {
+			path = append(path, command.Name)
+			commands, flags = command.Subcommands, command.Flags
+		}
+	}
+
+	var candidates []string
+	if strings.HasPrefix(current, "-") || len(commands) == 0 {
+		for _, f := range flags {
+			for _, name := range names(f) {
+				if len(name) == 1 {
+					candidates = append(candidates, "-"+name)
+				} else {
+					candidates = append(candidates, "--"+name)
+				}
+			}
+		}
+		return filter(candidates, current)
+	}
+	for _, command := range commands {
+		if command.Hidden {
+			continue
+		}
+		candidates = append(candidates, command.Name+"\t"+command.Usage)
+	}
+	return filter(candidates, current)
+}
+
+// Describe returns the ID of every item in list, a slice of API resources, followed by its name
+// after a tab when the resource has one
+func Describe(list interface{}) []string {
+	var candidates []string
+	v := reflect.Indirect(reflect.ValueOf(list))
+	if v.Kind() != reflect.Slice {
+		return nil
+	}
+	for i := 0; i < v.Len(); i++ {
+		item := reflect.Indirect(v.Index(i))
+		if item.Kind() != reflect.Struct {
+			continue
+		}
+		id := stringField(item, "ID", "Id")
+		if id == "" {
+			continue
+		}
+		if name := stringField(item, "Name"); name != "" {
+			id = id + "\t" + name
+		}
+		candidates = append(candidates, id)
+	}
+	return candidates
+}
+
+// stringField returns the first of the fields of item which is a string
+func stringField(item reflect.Value, fields ...string) string {
+	for _, field := range fields {
+		f := item.FieldByName(field)
+		if f.IsValid() && f.Kind() == reflect.String {
+			return f.String()
+		}
+	}
+	return ""
+}
+
+// filter returns the candidates starting with prefix
+func filter(candidates []string, prefix string) []string {
+	var filtered []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered
+}
+
+func find(commands []cli.Command, name string) *cli.Command {
+	for i := range commands {
+		if commands[i].Name == name || (commands[i].ShortName != "" && commands[i].ShortName == name) {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func lookup(flags []cli.Flag, name string) cli.Flag {
+	for _, f := range flags {
+		for _, n := range names(f) {
+			if n == name {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// names returns the name and aliases of f
+func names(f cli.Flag) []string {
+	var names []string
+	for _, name := range strings.Split(f.GetName(), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// primaryName returns the first long name of f, by which values are resolved
+func primaryName(f cli.Flag) string {
+	all := names(f)
+	for _, name := range all {
+		if len(name) > 1 {
+			return name
+		}
+	}
+	return all[0]
+}
+
+func isBool(f cli.Flag) bool {
+	switch f.(type) {
+	case cli.BoolFlag, cli.BoolTFlag:
+		return true
+	}
+	return false
+}
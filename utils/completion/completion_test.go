@@ -0,0 +1,86 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFlags = []cli.Flag{
+	cli.BoolFlag{Name: "debug, D"},
+	cli.StringFlag{Name: "formatter", Value: "text"},
+}
+
+var testCommands = []cli.Command{
+	{
+		Name:      "cloud",
+		ShortName: "clo",
+		Usage:     "Manages cloud resources",
+		Subcommands: []cli.Command{
+			{
+				Name:  "servers",
+				Usage: "Provides information on servers",
+				Subcommands: []cli.Command{
+					{Name: "list", Usage: "Lists servers", Flags: []cli.Flag{cli.StringFlag{Name: "workspace_id"}}},
+					{Name: "show", Usage: "Shows a server", Flags: []cli.Flag{cli.StringFlag{Name: "id"}, cli.BoolFlag{Name: "all"}}},
+				},
+			},
+			{Name: "scripts", Usage: "Provides information on scripts"},
+		},
+	},
+	{Name: "__complete", Hidden: true},
+}
+
+func resolveTest(flag string, path []string) []string {
+	if flag == "id" && len(path) > 1 && path[1] == "servers" {
+		return []string{"5aa1\tweb", "5bb2\tdb"}
+	}
+	if flag == "workspace_id" {
+		return []string{"6cc3\tprod"}
+	}
+	return nil
+}
+
+func TestCandidates(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"cloud\tManages cloud resources"}, Candidates(testCommands, testFlags, nil, resolveTest), "Hidden commands shouldn't be completed")
+	assert.Equal([]string{"servers\tProvides information on servers", "scripts\tProvides information on scripts"},
+		Candidates(testCommands, testFlags, []string{"--formatter", "json", "clo", "s"}, resolveTest), "Unexpected subcommands")
+	assert.Equal([]string{"show\tShows a server"}, Candidates(testCommands, testFlags, []string{"-D", "cloud", "servers", "sh"}, resolveTest), "Unexpected subcommands after bool flag")
+	assert.Equal([]string{"--debug", "-D", "--formatter"}, Candidates(testCommands, testFlags, []string{"-"}, resolveTest), "Unexpected global flags")
+	assert.Equal([]string{"--id", "--all"}, Candidates(testCommands, testFlags, []string{"cloud", "servers", "show", ""}, resolveTest), "Leaf commands should complete flags")
+	assert.Equal([]string{"5bb2\tdb"}, Candidates(testCommands, testFlags, []string{"cloud", "servers", "show", "--id", "5b"}, resolveTest), "Unexpected flag values")
+	assert.Equal([]string{"6cc3\tprod"}, Candidates(testCommands, testFlags, []string{"cloud", "servers", "list", "--workspace_id", ""}, resolveTest), "Unexpected flag values")
+	assert.Equal([]string{"--id", "--all"}, Candidates(testCommands, testFlags, []string{"cloud", "servers", "show", "--id", "5aa1", "--"}, resolveTest), "Flag values should be skipped")
+	assert.Nil(Candidates(testCommands, testFlags, []string{"--formatter", ""}, nil), "Flags without resolver shouldn't complete")
+}
+
+func TestDescribe(t *testing.T) {
+	assert := assert.New(t)
+
+	type server struct {
+		Id   string
+		Name string
+	}
+	type script struct {
+		ID string
+	}
+	assert.Equal([]string{"5aa1\tweb", "5bb2"}, Describe([]server{{"5aa1", "web"}, {"5bb2", ""}, {"", "noid"}}), "Unexpected servers")
+	assert.Equal([]string{"7dd4"}, Describe(&[]script{{"7dd4"}}), "Unexpected scripts")
+	assert.Nil(Describe("notalist"), "Non slices should be ignored")
+}
+
+func TestScript(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, shell := range Shells {
+		script, err := Script(shell, "concerto")
+		assert.Nil(err, "Couldn't generate %s script", shell)
+		assert.Contains(script, "concerto __complete", "%s script should ask for candidates", shell)
+		assert.NotContains(script, "{{name}}", "%s script should name the program", shell)
+	}
+	_, err := Script("tcsh", "concerto")
+	assert.NotNil(err, "Unsupported shells should fail")
+}
@@ -0,0 +1,58 @@
+package completion
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shells lists the shells completion scripts are generated for
+var Shells = []string{"bash", "zsh", "fish"}
+
+const bashScript = `# bash completion for {{name}}
+_{{name}}() {
+    local IFS=$'\n'
+    COMPREPLY=($({{name}} __complete "${COMP_WORDS[@]:1:$COMP_CWORD}" 2>/dev/null | cut -f1))
+}
+complete -o default -F _{{name}} {{name}}
+`
+
+const zshScript = `#compdef {{name}}
+# zsh completion for {{name}}
+_{{name}}() {
+    local line
+    local -a candidates
+    for line in "${(@f)$({{name}} __complete "${(@)words[2,$CURRENT]}" 2>/dev/null)}"; do
+        [[ -n $line ]] || continue
+        line=${line//:/\\:}
+        candidates+=("${line/$'\t'/:}")
+    done
+    _describe '{{name}}' candidates
+}
+compdef _{{name}} {{name}}
+`
+
+const fishScript = `# fish completion for {{name}}
+function __{{name}}_complete
+    set -l words (commandline -opc)
+    set -l current (commandline -ct)
+    {{name}} __complete $words[2..-1] "$current" 2>/dev/null
+end
+complete -c {{name}} -f -a '(__{{name}}_complete)'
+`
+
+// Script returns the completion script of program for shell. Scripts ask the program for
+// candidates through its hidden __complete command
+func Script(shell, program string) (string, error) {
+	var script string
+	switch shell {
+	case "bash":
+		script = bashScript
+	case "zsh":
+		script = zshScript
+	case "fish":
+		script = fishScript
+	default:
+		return "", fmt.Errorf("unsupported shell %s, use one of %s", shell, strings.Join(Shells, ", "))
+	}
+	return strings.Replace(script, "{{name}}", program, -1), nil
+}
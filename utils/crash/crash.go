@@ -0,0 +1,247 @@
+package crash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/logging"
+	"github.com/flexiant/concerto/utils/shutdown"
+)
+
+// uploadTimeout is the maximum time uploading a report can take
+const uploadTimeout = 10 * time.Second
+
+// Report describes a crash. It's anonymous: it holds no host or user names, as hosts of the
+// configured endpoints are hashed, credentials are redacted, and paths under the home directory
+// are relative to ~
+type Report struct {
+	ID        string            `json:"id"`
+	Time      time.Time         `json:"time"`
+	Kind      string            `json:"kind"`
+	Version   string            `json:"version"`
+	Commit    string            `json:"commit"`
+	GoVersion string            `json:"go_version"`
+	Platform  string            `json:"platform"`
+	Command   string            `json:"command"`
+	Message   string            `json:"message"`
+	Stack     string            `json:"stack"`
+	Config    map[string]string `json:"config,omitempty"`
+}
+
+var (
+	enabled   bool
+	uploadURL string
+	dir       string
+	command   string
+	config    map[string]string
+	// hosts are the configured hosts, replaced by their hashes in messages
+	hosts []string
+
+	// exit is replaced in tests
+	exit = shutdown.Exit
+)
+
+// Initialize enables crash reports for command. Reports are written to the crash directory of
+// the configuration location, and posted to config.CrashReports.URL when set
+func Initialize(c *utils.Config, cmd string) {
+	enabled = c.CrashReports.Enabled
+	uploadURL = c.CrashReports.URL
+	dir = filepath.Join(c.ConfLocation, "crash")
+	command = cmd
+	config = SanitizeConfig(c)
+	hosts = nil
+	for _, u := range []string{c.APIEndpoint, c.Proxy, c.Storage.Endpoint} {
+		if parsed, err := url.Parse(u); err == nil && parsed.Hostname() != "" {
+			hosts = append(hosts, parsed.Hostname())
+		}
+	}
+}
+
+// Recover reports a panic of the goroutine it's deferred in. It must be deferred by the main
+// goroutine and by every goroutine running commands, as panics aren't recovered across goroutines.
+// When crash reports aren't enabled the panic goes on as usual
+func Recover() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if !enabled {
+		panic(r)
+	}
+	file := write(newReport("panic", fmt.Sprint(r), string(debug.Stack())))
+	fmt.Fprintf(os.Stderr, "concerto crashed: %v\n", r)
+	if file != "" {
+		fmt.Fprintf(os.Stderr, "A crash report has been written to %s. Please, attach it when reporting the issue\n", file)
+	}
+	exit(2)
+}
+
+// Fatal reports fatal errors coming from the API, such as server errors or unreachable endpoints.
+// Other errors, such as wrong usage or not found resources, aren't reported
+func Fatal(context string, err error) {
+	if !enabled || !isAPIError(err) {
+		return
+	}
+	file := write(newReport("fatal", fmt.Sprintf("%s: %s", context, err), string(debug.Stack())))
+	if file != "" {
+		log.Infof("A crash report has been written to %s", file)
+	}
+}
+
+// SanitizeConfig returns the configuration settings that help reproducing issues, without credentials
+func SanitizeConfig(c *utils.Config) map[string]string {
+	settings := map[string]string{
+		"server":            anonymousURL(c.APIEndpoint),
+		"log_level":         c.LogLevel,
+		"timeout":           c.Timeout,
+		"retries":           fmt.Sprint(c.Retries),
+		"max_response_size": c.MaxResponse,
+		"is_host":           fmt.Sprint(c.IsHost),
+		"bastions":          fmt.Sprint(len(c.Bastions)),
+		"conf_file":         homeRelative(c.ConfFile),
+		"cert":              homeRelative(c.Certificate.Cert),
+		"server_ca":         homeRelative(c.Certificate.Ca),
+		"insecure":          fmt.Sprint(c.Certificate.Insecure),
+	}
+	if c.Notify != "" {
+		// webhook URLs hold tokens
+		settings["notify"] = "[REDACTED]"
+	}
+	if c.Proxy != "" {
+		settings["proxy_url"] = anonymousURL(c.Proxy)
+	}
+	if c.ProfileName != "" {
+		settings["profile"] = c.ProfileName
+	}
+	if c.Storage.Endpoint != "" || c.Storage.Region != "" {
+		settings["s3"] = strings.TrimSpace(anonymousURL(c.Storage.Endpoint) + " " + c.Storage.Region)
+	}
+	return settings
+}
+
+func newReport(kind string, message string, stack string) *Report {
+	return &Report{
+		ID:        logging.NewRequestID(),
+		Time:      time.Now().UTC(),
+		Kind:      kind,
+		Version:   utils.VERSION,
+		Commit:    utils.GitCommit,
+		GoVersion: runtime.Version(),
+		Platform:  fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		Command:   logging.Redact(command),
+		Message:   logging.Redact(anonymousHosts(homeRelative(message))),
+		Stack:     anonymousHosts(homeRelative(stack)),
+		Config:    config,
+	}
+}
+
+// write saves r and uploads it when an endpoint is configured. It returns the file written, if any
+func write(r *Report) string {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		log.Errorf("Couldn't encode crash report: %s", err)
+		return ""
+	}
+
+	file := ""
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		log.Errorf("Couldn't create crash reports directory: %s", err)
+	} else {
+		file = filepath.Join(dir, fmt.Sprintf("crash-%s-%s.json", r.Time.Format("20060102T150405"), r.ID))
+		if err = ioutil.WriteFile(file, data, 0600); err != nil {
+			log.Errorf("Couldn't write crash report: %s", err)
+			file = ""
+		}
+	}
+
+	if uploadURL != "" {
+		if err = upload(data); err != nil {
+			log.Errorf("Couldn't upload crash report: %s", err)
+		} else {
+			log.Debugf("Crash report %s uploaded", r.ID)
+		}
+	}
+	return file
+}
+
+func upload(data []byte) error {
+	client := &http.Client{Timeout: uploadTimeout}
+	resp, err := client.Post(uploadURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s responded %s", uploadURL, resp.Status)
+	}
+	return nil
+}
+
+// isAPIError returns whether err is a server error or a failed request
+func isAPIError(err error) bool {
+	switch e := err.(type) {
+	case *utils.HTTPError:
+		return e.Status >= 500
+	case *url.Error:
+		return true
+	}
+	return false
+}
+
+// anonymousURL returns the scheme and port of u, with its host hashed, so that reports tell whether
+// endpoints are the same without naming them. Paths and credentials are dropped
+func anonymousURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Hostname() == "" {
+		if u == "" {
+			return ""
+		}
+		return "[REDACTED]"
+	}
+	host := hashHost(parsed.Hostname())
+	if port := parsed.Port(); port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	return parsed.Scheme + "://" + host
+}
+
+// anonymousHosts replaces the configured hosts found in s by their hashes
+func anonymousHosts(s string) string {
+	for _, host := range hosts {
+		s = strings.Replace(s, host, hashHost(host), -1)
+	}
+	return s
+}
+
+// hashHost returns a short hash of host, the same for every report
+func hashHost(host string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(host)))
+	return "host-" + hex.EncodeToString(sum[:6])
+}
+
+// homeRelative replaces the home directory with ~, so that user names aren't reported
+func homeRelative(s string) string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		home = os.Getenv("USERPROFILE")
+	}
+	if home == "" || home == "/" {
+		return s
+	}
+	return strings.Replace(s, home, "~", -1)
+}
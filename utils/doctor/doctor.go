@@ -0,0 +1,428 @@
+package doctor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/keyring"
+	"github.com/flexiant/concerto/utils/pin"
+)
+
+// Statuses of a check
+const (
+	OK   = "ok"
+	Warn = "warn"
+	Fail = "fail"
+	Skip = "skip"
+)
+
+const (
+	// expiryWarning is how early certificates about to expire are warned
+	expiryWarning = 30 * 24 * time.Hour
+	// skewWarning and skewFailure are the clock differences with the API that are warned and failed.
+	// Certificate validation fails with big differences
+	skewWarning = time.Minute
+	skewFailure = 5 * time.Minute
+	// dialTimeout is the maximum time connecting to the API can take
+	dialTimeout = 10 * time.Second
+)
+
+// firewallTools are the commands used to apply firewall profiles in every OS. Any of them is enough
+var firewallTools = map[string][]string{
+	"linux":   {"/sbin/iptables", "/usr/sbin/nft"},
+	"windows": {"netsh", "powershell"},
+	"solaris": {"/usr/sbin/ipf"},
+}
+
+// Result is the outcome of a check, and how to fix it when it fails
+type Result struct {
+	Check      string `json:"check" header:"CHECK"`
+	Status     string `json:"status" header:"STATUS"`
+	Detail     string `json:"detail" header:"DETAIL"`
+	Suggestion string `json:"suggestion,omitempty" header:"SUGGESTION"`
+}
+
+// Failed returns the number of failed checks
+func Failed(results []Result) int {
+	failed := 0
+	for _, r := range results {
+		if r.Status == Fail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Doctor runs the checks for a configuration
+type Doctor struct {
+	config *utils.Config
+	now    func() time.Time
+}
+
+// New returns a doctor checking config
+func New(config *utils.Config) *Doctor {
+	return &Doctor{config: config, now: time.Now}
+}
+
+// Run runs every check. Checks depending on a failed one are skipped
+func (d *Doctor) Run() []Result {
+	results := []Result{d.CheckConfig()}
+	results = append(results, d.CheckPermissions()...)
+
+	cert := d.CheckCertificate()
+	results = append(results, cert)
+
+	reach := d.CheckReachability()
+	results = append(results, reach)
+	if reach.Status == Fail {
+		results = append(results,
+			Result{Check: "tls", Status: Skip, Detail: "API endpoint isn't reachable"},
+			Result{Check: "api auth", Status: Skip, Detail: "API endpoint isn't reachable"},
+			Result{Check: "clock", Status: Skip, Detail: "API endpoint isn't reachable"})
+	} else {
+		results = append(results, d.CheckTLS())
+		if cert.Status == Fail {
+			results = append(results,
+				Result{Check: "api auth", Status: Skip, Detail: "Client certificate isn't valid"},
+				Result{Check: "clock", Status: Skip, Detail: "Client certificate isn't valid"})
+		} else {
+			results = append(results, d.CheckAPI()...)
+		}
+	}
+	return append(results, d.CheckFirewallTool())
+}
+
+// CheckConfig checks that the configuration file exists and points to the API
+func (d *Doctor) CheckConfig() Result {
+	r := Result{Check: "config"}
+	if d.config.ConfFile == "" || !utils.FileExists(d.config.ConfFile) {
+		r.Status, r.Detail = Fail, fmt.Sprintf("Configuration file %s not found", d.config.ConfFile)
+		r.Suggestion = "Run concerto setup api_keys, or the setup script, to create it"
+		return r
+	}
+	if d.config.APIEndpoint == "" {
+		r.Status, r.Detail = Fail, fmt.Sprintf("No server in %s", d.config.ConfFile)
+		r.Suggestion = "Set the server attribute of the concerto element, or CONCERTO_ENDPOINT"
+		return r
+	}
+	r.Status, r.Detail = OK, fmt.Sprintf("%s points to %s", d.config.ConfFile, d.config.APIEndpoint)
+	if d.config.ProfileName != "" {
+		r.Detail = fmt.Sprintf("Profile %s of %s points to %s", d.config.ProfileName, d.config.ConfFile, d.config.APIEndpoint)
+	}
+	return r
+}
+
+// CheckPermissions checks that the configuration can only be written, and the private key read, by its owner
+func (d *Doctor) CheckPermissions() []Result {
+	if runtime.GOOS == "windows" {
+		return []Result{{Check: "permissions", Status: Skip, Detail: "File modes aren't checked on windows"}}
+	}
+	var results []Result
+	for _, f := range []struct {
+		name string
+		path string
+		mask os.FileMode
+	}{
+		{"config permissions", d.config.ConfFile, 0022},
+		{"key permissions", d.config.Certificate.Key, 0077},
+	} {
+		r := Result{Check: f.name}
+		info, err := os.Stat(f.path)
+		switch {
+		case f.path == "":
+			r.Status, r.Detail = Skip, "Not configured"
+		case keyring.IsReference(f.path):
+			r.Status, r.Detail = Skip, "Key is kept in the OS keyring"
+		case err != nil:
+			r.Status, r.Detail = Skip, fmt.Sprintf("%s not found", f.path)
+		case info.Mode().Perm()&f.mask != 0:
+			r.Status, r.Detail = Fail, fmt.Sprintf("%s has mode %s", f.path, info.Mode().Perm())
+			r.Suggestion = fmt.Sprintf("Run chmod %o %s", info.Mode().Perm()&^f.mask, f.path)
+		default:
+			r.Status, r.Detail = OK, fmt.Sprintf("%s has mode %s", f.path, info.Mode().Perm())
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// CheckCertificate checks that the client certificate matches its key, and hasn't expired
+func (d *Doctor) CheckCertificate() Result {
+	r := Result{Check: "certificate"}
+	if d.config.UsesToken() && d.config.Certificate.Cert == "" {
+		r.Status, r.Detail = Skip, "Requests are authenticated with an API token"
+		return r
+	}
+	pair, err := d.config.ClientCertificate()
+	if err != nil {
+		r.Status, r.Detail = Fail, fmt.Sprintf("Couldn't load client certificate: %s", err)
+		r.Suggestion = "Check the ssl element of the configuration, or download new API keys and run concerto setup api_keys"
+		return r
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		r.Status, r.Detail = Fail, fmt.Sprintf("Couldn't parse client certificate: %s", err)
+		r.Suggestion = "Download new API keys and run concerto setup api_keys"
+		return r
+	}
+	return certificateExpiry(cert, d.now())
+}
+
+func certificateExpiry(cert *x509.Certificate, now time.Time) Result {
+	r := Result{Check: "certificate"}
+	expiry := cert.NotAfter.Format("2006-01-02")
+	switch remaining := cert.NotAfter.Sub(now); {
+	case now.Before(cert.NotBefore):
+		r.Status, r.Detail = Fail, fmt.Sprintf("Client certificate isn't valid until %s", cert.NotBefore.Format("2006-01-02"))
+		r.Suggestion = "Check the clock of this machine"
+	case remaining <= 0:
+		r.Status, r.Detail = Fail, fmt.Sprintf("Client certificate expired on %s", expiry)
+		r.Suggestion = "Download new API keys and run concerto setup api_keys"
+	case remaining < expiryWarning:
+		r.Status, r.Detail = Warn, fmt.Sprintf("Client certificate expires on %s", expiry)
+		r.Suggestion = "Renew your API keys before they expire"
+	default:
+		r.Status, r.Detail = OK, fmt.Sprintf("Client certificate valid until %s", expiry)
+	}
+	return r
+}
+
+// CheckReachability checks that a connection can be opened to the API endpoint
+func (d *Doctor) CheckReachability() Result {
+	r := Result{Check: "endpoint"}
+	addr, err := endpointAddress(d.config.APIEndpoint)
+	if err != nil {
+		r.Status, r.Detail = Fail, err.Error()
+		r.Suggestion = "Fix the server attribute of the concerto element"
+		return r
+	}
+	proxy, err := d.proxy()
+	if err != nil {
+		r.Status, r.Detail = Fail, err.Error()
+		r.Suggestion = "Fix the proxy_url attribute of the concerto element"
+		return r
+	}
+	if proxy != nil {
+		addr = proxyAddress(proxy)
+	}
+
+	start := d.now()
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		r.Status, r.Detail = Fail, fmt.Sprintf("Couldn't connect to %s: %s", addr, err)
+		r.Suggestion = fmt.Sprintf("Check the network connection, DNS, and that firewalls or proxies allow reaching %s", addr)
+		return r
+	}
+	conn.Close()
+	r.Status, r.Detail = OK, fmt.Sprintf("Connected to %s in %s", addr, d.now().Sub(start))
+	if proxy != nil {
+		r.Detail = fmt.Sprintf("Connected to proxy %s in %s", addr, d.now().Sub(start))
+	}
+	return r
+}
+
+// proxy returns the proxy API connections go through, if any
+func (d *Doctor) proxy() (*url.URL, error) {
+	proxyFunc, err := d.config.ProxyFunc()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", d.config.APIEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	return proxyFunc(req)
+}
+
+// proxyAddress returns the host:port of proxy, using the default port of its scheme when missing
+func proxyAddress(proxy *url.URL) string {
+	if proxy.Port() != "" {
+		return proxy.Host
+	}
+	switch proxy.Scheme {
+	case "https":
+		return net.JoinHostPort(proxy.Hostname(), "443")
+	case "socks5":
+		return net.JoinHostPort(proxy.Hostname(), "1080")
+	}
+	return net.JoinHostPort(proxy.Hostname(), "80")
+}
+
+// CheckTLS checks that the certificate chain of the API endpoint is signed by the configured CA
+func (d *Doctor) CheckTLS() Result {
+	r := Result{Check: "tls"}
+	addr, err := endpointAddress(d.config.APIEndpoint)
+	if err != nil {
+		r.Status, r.Detail = Skip, err.Error()
+		return r
+	}
+	if proxy, err := d.proxy(); err == nil && proxy != nil {
+		r.Status, r.Detail = Skip, fmt.Sprintf("Connections go through proxy %s, so the chain is checked by api auth", proxyAddress(proxy))
+		return r
+	}
+	host, _, _ := net.SplitHostPort(addr)
+	tlsConfig := &tls.Config{ServerName: host}
+	if utils.FileExists(d.config.Certificate.Ca) {
+		pem, err := ioutil.ReadFile(d.config.Certificate.Ca)
+		if err != nil {
+			r.Status, r.Detail = Fail, fmt.Sprintf("Couldn't read CA certificate: %s", err)
+			return r
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			r.Status, r.Detail = Fail, fmt.Sprintf("%s holds no PEM certificates", d.config.Certificate.Ca)
+			r.Suggestion = "Download new API keys, which include the CA certificate, and run concerto setup api_keys"
+			return r
+		}
+	}
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", addr, tlsConfig)
+	if err != nil {
+		r.Status, r.Detail = Fail, fmt.Sprintf("Server certificate chain isn't trusted: %s", err)
+		r.Suggestion = "Check server_ca in the configuration. A proxy intercepting TLS connections also causes this"
+		return r
+	}
+	defer conn.Close()
+	chain := conn.ConnectionState().PeerCertificates
+	pins, err := pin.Parse(d.config.Certificate.Pins)
+	if err == nil {
+		err = pin.Verify(chain, pins)
+	}
+	if err != nil {
+		r.Status, r.Detail = Fail, err.Error()
+		r.Suggestion = "Check the pins of the configuration, and update them if the API key has been renewed"
+		return r
+	}
+	r.Status, r.Detail = OK, fmt.Sprintf("Server certificate issued by %s, valid until %s, key pin %s",
+		chain[0].Issuer.CommonName, chain[0].NotAfter.Format("2006-01-02"), pin.Of(chain[0]))
+	if d.config.Certificate.Insecure {
+		r.Status = Warn
+		r.Suggestion = "The chain is trusted, so insecure can be removed from the configuration"
+	}
+	return r
+}
+
+// CheckAPI sends an authenticated request, checking that credentials are accepted and that the
+// clock of this machine agrees with the API one
+func (d *Doctor) CheckAPI() []Result {
+	auth := Result{Check: "api auth"}
+	clock := Result{Check: "clock"}
+	if d.config.IsHost {
+		auth.Status, auth.Detail = Skip, "Host certificates don't authenticate client commands"
+		clock.Status, clock.Detail = Skip, auth.Detail
+		return []Result{auth, clock}
+	}
+
+	client, err := utils.NewHTTPClient(d.config)
+	if err != nil {
+		auth.Status, auth.Detail = Fail, fmt.Sprintf("Couldn't create API client: %s", err)
+		clock.Status, clock.Detail = Skip, "No API client"
+		return []Result{auth, clock}
+	}
+	req, err := http.NewRequest("GET", strings.TrimRight(d.config.APIEndpoint, "/")+"/v1/cloud/workspaces", nil)
+	if err != nil {
+		auth.Status, auth.Detail = Fail, err.Error()
+		clock.Status, clock.Detail = Skip, "No API response"
+		return []Result{auth, clock}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		auth.Status, auth.Detail = Fail, fmt.Sprintf("Request failed: %s", err)
+		auth.Suggestion = "Check the network connection, and the timeout attribute of the configuration"
+		clock.Status, clock.Detail = Skip, "No API response"
+		return []Result{auth, clock}
+	}
+	resp.Body.Close()
+
+	credentials, accepted := "client certificate", "Client certificate accepted"
+	suggestion := "API keys may have been revoked. Download new ones and run concerto setup api_keys"
+	if d.config.UsesToken() {
+		credentials, accepted = "API token", "API token accepted"
+		suggestion = "The API token may have expired or been revoked. Check the token element of the configuration"
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		auth.Status, auth.Detail = Fail, fmt.Sprintf("API rejected the %s: %s", credentials, resp.Status)
+		auth.Suggestion = suggestion
+	case resp.StatusCode >= 300:
+		auth.Status, auth.Detail = Warn, fmt.Sprintf("API responded %s", resp.Status)
+	default:
+		auth.Status, auth.Detail = OK, accepted
+	}
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		clock.Status, clock.Detail = Skip, "API response has no date"
+	} else {
+		clock = clockSkew(date, d.now())
+	}
+	return []Result{auth, clock}
+}
+
+func clockSkew(server time.Time, local time.Time) Result {
+	r := Result{Check: "clock"}
+	skew := local.Sub(server)
+	if skew < 0 {
+		skew = -skew
+	}
+	// Date headers have a precision of seconds
+	skew = skew - skew%time.Second
+	switch {
+	case skew >= skewFailure:
+		r.Status, r.Detail = Fail, fmt.Sprintf("Local clock differs %s from the API one", skew)
+		r.Suggestion = "Synchronize the clock with NTP, such as running ntpdate or enabling timedatectl set-ntp true"
+	case skew >= skewWarning:
+		r.Status, r.Detail = Warn, fmt.Sprintf("Local clock differs %s from the API one", skew)
+		r.Suggestion = "Synchronize the clock with NTP"
+	default:
+		r.Status, r.Detail = OK, fmt.Sprintf("Local clock differs %s from the API one", skew)
+	}
+	return r
+}
+
+// CheckFirewallTool checks that the command applying firewall profiles is available. It's only required on hosts
+func (d *Doctor) CheckFirewallTool() Result {
+	r := Result{Check: "firewall tool"}
+	tools, ok := firewallTools[runtime.GOOS]
+	if !ok {
+		r.Status, r.Detail = Skip, fmt.Sprintf("Firewall profiles aren't applied on %s", runtime.GOOS)
+		return r
+	}
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool); err == nil {
+			r.Status, r.Detail = OK, fmt.Sprintf("%s found", tool)
+			return r
+		}
+	}
+	missing := strings.Join(tools, " or ")
+	if !d.config.IsHost {
+		r.Status, r.Detail = Skip, fmt.Sprintf("%s not found, but it's only required on hosts", missing)
+		return r
+	}
+	r.Status, r.Detail = Fail, fmt.Sprintf("%s not found", missing)
+	r.Suggestion = fmt.Sprintf("Install %s so that firewall profiles can be applied", missing)
+	return r
+}
+
+// endpointAddress returns the host:port of endpoint, using the default HTTPS port when missing
+func endpointAddress(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("Invalid API endpoint %q", endpoint)
+	}
+	if _, _, err := net.SplitHostPort(u.Host); err != nil {
+		return u.Host + ":443", nil
+	}
+	return u.Host, nil
+}
@@ -0,0 +1,131 @@
+package doctor
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/flexiant/concerto/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertificateExpiry(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)
+	cert := &x509.Certificate{NotBefore: now.AddDate(-1, 0, 0), NotAfter: now.AddDate(1, 0, 0)}
+	assert.Equal(OK, certificateExpiry(cert, now).Status, "Valid certificates should pass")
+
+	cert.NotAfter = now.AddDate(0, 0, 10)
+	assert.Equal(Warn, certificateExpiry(cert, now).Status, "Certificates about to expire should be warned")
+
+	cert.NotAfter = now.AddDate(0, 0, -1)
+	r := certificateExpiry(cert, now)
+	assert.Equal(Fail, r.Status, "Expired certificates should fail")
+	assert.NotEmpty(r.Suggestion, "Failures should suggest a fix")
+
+	cert.NotBefore, cert.NotAfter = now.AddDate(0, 0, 1), now.AddDate(1, 0, 0)
+	assert.Equal(Fail, certificateExpiry(cert, now).Status, "Certificates not valid yet should fail")
+}
+
+func TestClockSkew(t *testing.T) {
+	assert := assert.New(t)
+
+	server := time.Date(2016, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(OK, clockSkew(server, server.Add(2*time.Second)).Status, "Small differences should pass")
+	assert.Equal(Warn, clockSkew(server, server.Add(-2*time.Minute)).Status, "Minutes of difference should be warned")
+	assert.Equal(Fail, clockSkew(server, server.Add(time.Hour)).Status, "Big differences should fail")
+}
+
+func TestCheckPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("File modes aren't checked on windows")
+	}
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "doctor")
+	assert.Nil(err, "Couldn't create temporary directory")
+	defer os.RemoveAll(dir)
+	conf := filepath.Join(dir, "client.xml")
+	key := filepath.Join(dir, "cert.key")
+	ioutil.WriteFile(conf, []byte("<concerto/>"), 0644)
+	ioutil.WriteFile(key, []byte("key"), 0644)
+	os.Chmod(key, 0644)
+
+	config := &utils.Config{ConfFile: conf}
+	config.Certificate.Key = key
+	results := New(config).CheckPermissions()
+	assert.Equal(OK, results[0].Status, "Config readable by others should pass")
+	assert.Equal(Fail, results[1].Status, "Key readable by others should fail")
+	assert.Equal("Run chmod 600 "+key, results[1].Suggestion, "Unexpected suggestion")
+}
+
+func TestCheckReachability(t *testing.T) {
+	assert := assert.New(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(err, "Couldn't listen")
+	addr := l.Addr().String()
+
+	d := New(&utils.Config{APIEndpoint: "https://" + addr + "/"})
+	assert.Equal(OK, d.CheckReachability().Status, "Listening endpoint should be reachable")
+
+	l.Close()
+	assert.Equal(Fail, d.CheckReachability().Status, "Closed endpoint shouldn't be reachable")
+
+	d = New(&utils.Config{APIEndpoint: "clients"})
+	assert.Equal(Fail, d.CheckReachability().Status, "Invalid endpoint should fail")
+
+	l, err = net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(err, "Couldn't listen")
+	defer l.Close()
+	d = New(&utils.Config{APIEndpoint: "https://127.0.0.1:1/", Proxy: "http://jdoe:s3cr3t@" + l.Addr().String()})
+	r := d.CheckReachability()
+	assert.Equal(OK, r.Status, "Endpoints should be reached through the proxy")
+	assert.Contains(r.Detail, "proxy "+l.Addr().String(), "Unexpected detail")
+	assert.NotContains(r.Detail, "s3cr3t", "Proxy credentials shouldn't be shown")
+	assert.Equal(Skip, d.CheckTLS().Status, "Chains can't be checked directly through proxies")
+}
+
+func TestProxyAddress(t *testing.T) {
+	assert := assert.New(t)
+
+	for proxy, addr := range map[string]string{
+		"http://proxy.example.com:3128": "proxy.example.com:3128",
+		"http://proxy.example.com":      "proxy.example.com:80",
+		"https://proxy.example.com":     "proxy.example.com:443",
+		"socks5://[::1]":                "[::1]:1080",
+	} {
+		u, err := url.Parse(proxy)
+		assert.Nil(err, "Couldn't parse proxy")
+		assert.Equal(addr, proxyAddress(u), "Unexpected address of %s", proxy)
+	}
+}
+
+func TestRunWithoutConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	results := New(&utils.Config{APIEndpoint: "https://127.0.0.1:1/"}).Run()
+	assert.Equal(Fail, results[0].Status, "Missing configuration should fail")
+	assert.True(Failed(results) >= 3, "Configuration, certificate and endpoint should fail")
+	for _, r := range results {
+		if r.Check == "api auth" {
+			assert.Equal(Skip, r.Status, "Checks depending on failed ones should be skipped")
+		}
+	}
+}
+
+func TestEndpointAddress(t *testing.T) {
+	addr, err := endpointAddress("https://clients.concerto.io:886/")
+	assert.Nil(t, err, "Couldn't parse endpoint")
+	assert.Equal(t, "clients.concerto.io:886", addr, "Unexpected address")
+
+	addr, _ = endpointAddress("https://clients.concerto.io/")
+	assert.Equal(t, "clients.concerto.io:443", addr, "Default port should be added")
+}
@@ -0,0 +1,53 @@
+// Package editor lets users edit text in their editor, as set in VISUAL or EDITOR
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Command returns the editor command line, with its arguments. It defaults to vi, or notepad in Windows
+func Command() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// Edit opens text in the editor, in a temporary file named after name, and returns it once the editor exits
+func Edit(name string, text string) (string, error) {
+	f, err := ioutil.TempFile("", fmt.Sprintf("concerto-*-%s", name))
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.WriteString(text); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		return "", err
+	}
+
+	command := Command()
+	cmd := exec.Command(command[0], append(command[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err = cmd.Run(); err != nil {
+		return "", fmt.Errorf("Editor %s failed: %s", command[0], err)
+	}
+
+	data, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,45 @@
+package editor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommand(t *testing.T) {
+	defer os.Setenv("VISUAL", os.Getenv("VISUAL"))
+	defer os.Setenv("EDITOR", os.Getenv("EDITOR"))
+
+	os.Setenv("VISUAL", "")
+	os.Setenv("EDITOR", "code --wait")
+	assert.Equal(t, []string{"code", "--wait"}, Command(), "EDITOR wasn't used")
+
+	os.Setenv("VISUAL", "emacs")
+	assert.Equal(t, []string{"emacs"}, Command(), "VISUAL didn't take precedence")
+}
+
+func TestEdit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Editor is a shell script")
+	}
+	defer os.Setenv("VISUAL", os.Getenv("VISUAL"))
+
+	dir, err := ioutil.TempDir("", "editor")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "edit.sh")
+	assert.Nil(t, ioutil.WriteFile(script, []byte("#!/bin/sh\nprintf 'b\\nc\\n' > \"$1\"\n"), 0755))
+
+	os.Setenv("VISUAL", script)
+	text, err := Edit("order.txt", "a\nc\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "b\nc\n", text, "Edited text wasn't returned")
+
+	os.Setenv("VISUAL", "false")
+	_, err = Edit("order.txt", "a\n")
+	assert.NotNil(t, err, "Failing editor wasn't reported")
+}
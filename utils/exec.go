@@ -1,7 +1,6 @@
 package utils
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
@@ -10,6 +9,7 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -30,8 +30,16 @@ func extractExitCode(err error) int {
 	return 0
 }
 
-func ExecCode(code string, path string, filename string) (output string, exitCode int, startedAt time.Time, finishedAt time.Time) {
-	var err error
+// ExecCode writes code to a script named filename in path and runs it. Errors are only returned when the
+// script couldn't be run; scripts that fail are reported through their exit code. env holds variables, as
+// name=value, set for the script on top of the ones of the process
+func ExecCode(code string, path string, filename string, env []string) (output string, exitCode int, startedAt time.Time, finishedAt time.Time, err error) {
+	return StreamCode(code, path, filename, env, nil, nil)
+}
+
+// StreamCode runs code as ExecCode does, copying its standard output and error to stdout and stderr as
+// they're written. Nil writers discard them
+func StreamCode(code string, path string, filename string, env []string, stdout io.Writer, stderr io.Writer) (output string, exitCode int, startedAt time.Time, finishedAt time.Time, err error) {
 	var tmp *os.File
 
 	if runtime.GOOS == "windows" {
@@ -41,30 +49,46 @@ func ExecCode(code string, path string, filename string) (output string, exitCod
 	}
 
 	if err != nil {
-		log.Fatalf("Error creating temp file : ", err)
+		return "", 0, startedAt, finishedAt, fmt.Errorf("Error creating temp file: %s", err)
 	}
 
 	defer tmp.Close()
 
 	_, err = tmp.WriteString(code)
 	if err != nil {
-		log.Fatalf("Error writing to file : ", err)
+		return "", 0, startedAt, finishedAt, fmt.Errorf("Error writing to file: %s", err)
 	}
 
-	os.Chmod(tmp.Name(), 0777)
+	err = os.Chmod(tmp.Name(), 0777)
 	if err != nil {
-		log.Fatalf("Error changing permision to file : ", err)
+		return "", 0, startedAt, finishedAt, fmt.Errorf("Error changing permission to file: %s", err)
 	}
 
-	return RunFile(tmp.Name())
+	return StreamFile(tmp.Name(), env, stdout, stderr)
 }
 
-func RunFile(command string) (output string, exitCode int, startedAt time.Time, finishedAt time.Time) {
+// RunFile runs a script. Errors are only returned when the script couldn't be run
+func RunFile(command string) (output string, exitCode int, startedAt time.Time, finishedAt time.Time, err error) {
+	return StreamFile(command, nil, nil, nil)
+}
 
-	var cmd *exec.Cmd
+// lockedBuffer collects the output of both streams of a command, which are written concurrently
+type lockedBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
 
-	var b bytes.Buffer
-	buffer := bufio.NewWriter(&b)
+func (l *lockedBuffer) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.Write(p)
+}
+
+// StreamFile runs a script as RunFile does, with the variables of env set, copying its standard output and error to stdout and stderr as
+// they're written. Nil writers discard them. The output returned holds both streams, interleaved as written
+func StreamFile(command string, env []string, stdout io.Writer, stderr io.Writer) (output string, exitCode int, startedAt time.Time, finishedAt time.Time, err error) {
+
+	var cmd *exec.Cmd
 
 	if runtime.GOOS == "windows" {
 		log.Infof("Command: %s", command)
@@ -73,36 +97,40 @@ func RunFile(command string) (output string, exitCode int, startedAt time.Time,
 		log.Infof("Command: %s %s", "/bin/sh", command)
 		cmd = exec.Command("/bin/sh", command)
 	}
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
-	stdout, err := cmd.StdoutPipe()
-	CheckError(err)
-
-	stderr, err := cmd.StderrPipe()
-	CheckError(err)
-
-	multi := io.MultiReader(stdout, stderr)
+	var b lockedBuffer
+	cmd.Stdout, cmd.Stderr = &b, &b
+	if stdout != nil {
+		cmd.Stdout = io.MultiWriter(&b, stdout)
+	}
+	if stderr != nil {
+		cmd.Stderr = io.MultiWriter(&b, stderr)
+	}
 
 	startedAt = time.Now()
 	err = cmd.Start()
-	CheckError(err)
-
-	io.Copy(buffer, multi)
-
-	//go io.Copy(buffer, stderr)
-	//go io.Copy(buffer, stdout)
+	if err != nil {
+		return
+	}
 
 	err = cmd.Wait()
 	finishedAt = time.Now()
+	output = b.b.String()
+	// scripts that fail are reported through their exit code, but errors waiting for them are returned
+	if _, ok := err.(*exec.ExitError); !ok && err != nil {
+		return
+	}
 	exitCode = extractExitCode(err)
-
-	err = buffer.Flush()
-	CheckError(err)
+	err = nil
 
 	log.Debugf("Starting Time: %s", startedAt.Format(TimeStampLayout))
 	log.Debugf("End Time: %s", finishedAt.Format(TimeStampLayout))
 	log.Debugf("Output")
 	log.Debugf("")
-	log.Debugf("%s", b.String())
+	log.Debugf("%s", output)
 	log.Debugf("")
 	log.Infof("Exit Code: %d", exitCode)
 	return
@@ -0,0 +1,59 @@
+// +build !windows
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func writeScript(t *testing.T, code string) string {
+	dir, err := ioutil.TempDir("", "concerto-exec")
+	assert.Nil(t, err, "Couldn't create temporary directory")
+	file := filepath.Join(dir, "script")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(code), 0700), "Couldn't write script")
+	return file
+}
+
+func TestStreamFile(t *testing.T) {
+	assert := assert.New(t)
+
+	file := writeScript(t, "echo out $GREETING\necho err >&2\nexit 3\n")
+	defer os.RemoveAll(filepath.Dir(file))
+
+	var stdout, stderr bytes.Buffer
+	output, exitCode, startedAt, finishedAt, err := StreamFile(file, []string{"GREETING=hello"}, &stdout, &stderr)
+	assert.Nil(err, "Scripts that fail should be reported through their exit code")
+	assert.Equal(3, exitCode, "Unexpected exit code")
+	assert.Equal("out hello\n", stdout.String(), "Standard output should be streamed")
+	assert.Equal("err\n", stderr.String(), "Standard error should be streamed")
+	assert.Contains(output, "out hello\n", "Output should hold standard output")
+	assert.Contains(output, "err\n", "Output should hold standard error")
+	assert.False(finishedAt.Before(startedAt), "Scripts should finish after starting")
+
+	os.Unsetenv("GREETING")
+	output, _, _, _, err = RunFile(file)
+	assert.Nil(err, "Couldn't run script")
+	assert.Contains(output, "out\n", "Variables should only be set for the script given them")
+}
+
+func TestStreamFileErrors(t *testing.T) {
+	file := writeScript(t, "echo out\n")
+	defer os.RemoveAll(filepath.Dir(file))
+
+	_, _, _, _, err := StreamFile(file, nil, failingWriter{}, nil)
+	assert.NotNil(t, err, "Errors other than the exit code of the script should be returned")
+}
@@ -0,0 +1,84 @@
+// Package exit tells apart the ways a command can fail, so that scripts running the CLI can react to
+// each of them through its exit code
+package exit
+
+import (
+	"errors"
+
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/cancel"
+)
+
+// Exit codes. Cancelled commands finish with the codes of package cancel
+const (
+	// OK is the exit code of commands that succeeded
+	OK = 0
+	// Failure is the exit code of commands that failed for any other reason
+	Failure = 1
+	// Validation is the exit code of commands given missing or invalid parameters
+	Validation = 2
+	// Auth is the exit code of commands whose credentials were rejected by the API
+	Auth = 3
+	// API is the exit code of commands whose requests failed in the API
+	API = 4
+)
+
+// Error is an error with the exit code the command should finish with
+type Error struct {
+	Err  error
+	Code int
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// NewValidationError returns err as caused by missing or invalid parameters
+func NewValidationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Err: err, Code: Validation}
+}
+
+// Code returns the exit code a command failing with err, or with an error wrapping it, should finish with
+func Code(err error) int {
+	if err == nil {
+		return OK
+	}
+	var exitErr *Error
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	var cancelErr *cancel.Error
+	if errors.As(err, &cancelErr) {
+		return cancelErr.Code
+	}
+	var httpErr *utils.HTTPError
+	if errors.As(err, &httpErr) {
+		if httpErr.Status == 401 || httpErr.Status == 403 {
+			return Auth
+		}
+		return API
+	}
+	return Failure
+}
+
+// Category names the kind of failure of an exit code, for machine-readable error output
+func Category(code int) string {
+	switch code {
+	case OK:
+		return "ok"
+	case Validation:
+		return "validation"
+	case Auth:
+		return "auth"
+	case API:
+		return "api"
+	case cancel.TimeoutExitCode:
+		return "timeout"
+	case cancel.InterruptExitCode:
+		return "interrupted"
+	}
+	return "failure"
+}
@@ -0,0 +1,36 @@
+package exit
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(OK, Code(nil), "Successful commands should exit with 0")
+	assert.Equal(Failure, Code(fmt.Errorf("boom")), "Unknown errors should exit with 1")
+	assert.Equal(Validation, Code(NewValidationError(fmt.Errorf("missing --id"))), "Validation errors should exit with 2")
+	assert.Equal(Auth, Code(&utils.HTTPError{Status: 401}), "Unauthorized requests should exit with 3")
+	assert.Equal(Auth, Code(&utils.HTTPError{Status: 403}), "Forbidden requests should exit with 3")
+	assert.Equal(API, Code(&utils.HTTPError{Status: 422}), "Rejected requests should exit with 4")
+	assert.Equal(API, Code(&utils.HTTPError{Status: 500}), "Failed requests should exit with 4")
+	assert.Equal(cancel.TimeoutExitCode, Code(&cancel.Error{Code: cancel.TimeoutExitCode}), "Cancelled commands should keep their code")
+	assert.Nil(NewValidationError(nil), "No error shouldn't become a validation error")
+}
+
+func TestCategory(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("validation", Category(Validation))
+	assert.Equal("auth", Category(Auth))
+	assert.Equal("api", Category(API))
+	assert.Equal("timeout", Category(cancel.TimeoutExitCode))
+	assert.Equal("interrupted", Category(cancel.InterruptExitCode))
+	assert.Equal("failure", Category(Failure))
+	assert.Equal("failure", Category(42), "Unknown codes should be failures")
+}
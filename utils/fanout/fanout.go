@@ -0,0 +1,150 @@
+package fanout
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/flexiant/concerto/utils/format"
+)
+
+// ProfileColumn is the column added to every row, naming the profile it comes from
+const ProfileColumn = "profile"
+
+// ReadOnly returns whether args run a command that doesn't change resources, that is, list and
+// show commands, and GET api requests
+func ReadOnly(args []string) bool {
+	if len(args) >= 2 && args[0] == "api" {
+		return strings.EqualFold(args[1], "GET")
+	}
+	for _, a := range args {
+		if strings.HasPrefix(a, "-") {
+			break
+		}
+		if a == "list" || a == "show" || strings.HasPrefix(a, "list_") || strings.HasPrefix(a, "show_") {
+			return true
+		}
+	}
+	return false
+}
+
+// Table merges the JSON outputs of a command run against several profiles. Columns are kept in
+// the order they appear, after the profile column
+type Table struct {
+	Columns []string
+	Rows    []map[string]interface{}
+
+	seen map[string]bool
+}
+
+// NewTable returns an empty table
+func NewTable() *Table {
+	return &Table{
+		Columns: []string{ProfileColumn},
+		seen:    map[string]bool{ProfileColumn: true},
+	}
+}
+
+// Add appends the items of the JSON output of profile, which may be a list or a single item.
+// Items which aren't objects are added as a value column
+func (t *Table) Add(profile string, output []byte) error {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return nil
+	}
+	items := []json.RawMessage{output}
+	if output[0] == '[' {
+		if err := json.Unmarshal(output, &items); err != nil {
+			return fmt.Errorf("Output isn't JSON: %s", err)
+		}
+	}
+
+	for _, item := range items {
+		keys, err := objectKeys(item)
+		if err != nil {
+			return fmt.Errorf("Output isn't JSON: %s", err)
+		}
+		row := make(map[string]interface{})
+		decoder := json.NewDecoder(bytes.NewReader(item))
+		decoder.UseNumber()
+		if keys != nil {
+			err = decoder.Decode(&row)
+		} else {
+			var value interface{}
+			err = decoder.Decode(&value)
+			keys, row["value"] = []string{"value"}, value
+		}
+		if err != nil {
+			return fmt.Errorf("Output isn't JSON: %s", err)
+		}
+
+		for _, k := range keys {
+			if !t.seen[k] {
+				t.seen[k] = true
+				t.Columns = append(t.Columns, k)
+			}
+		}
+		row[ProfileColumn] = profile
+		t.Rows = append(t.Rows, row)
+	}
+	return nil
+}
+
+// WriteText writes the table as the text formatter does, with upper case column headers
+func (t *Table) WriteText(out io.Writer) error {
+	header := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		header[i] = strings.ToUpper(c)
+	}
+	rows := make([][]string, len(t.Rows))
+	for i, row := range t.Rows {
+		for _, c := range t.Columns {
+			rows[i] = append(rows[i], textValue(row[c]))
+		}
+	}
+	return format.NewTable(out, header, rows).Write(out)
+}
+
+// objectKeys returns the keys of a JSON object in order, or nil when item isn't an object
+func objectKeys(item []byte) ([]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(item))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, nil
+	}
+
+	keys := []string{}
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, token.(string))
+		var value json.RawMessage
+		if err = decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func textValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number, bool:
+		return fmt.Sprint(value)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
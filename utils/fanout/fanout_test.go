@@ -0,0 +1,50 @@
+package fanout
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnly(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(ReadOnly([]string{"cloud", "servers", "list"}), "List commands are read-only")
+	assert.True(ReadOnly([]string{"cloud", "servers", "show", "--id", "1"}), "Show commands are read-only")
+	assert.True(ReadOnly([]string{"blueprint", "templates", "list_template_scripts", "--id", "1"}), "List commands are read-only")
+	assert.True(ReadOnly([]string{"api", "get", "/v1/cloud/servers"}), "GET requests are read-only")
+	assert.False(ReadOnly([]string{"api", "DELETE", "/v1/cloud/servers/1"}), "DELETE requests aren't read-only")
+	assert.False(ReadOnly([]string{"cloud", "servers", "delete", "--id", "list"}), "Flag values shouldn't be taken as commands")
+	assert.False(ReadOnly([]string{}), "Missing commands aren't read-only")
+}
+
+func TestTable(t *testing.T) {
+	assert := assert.New(t)
+
+	table := NewTable()
+	assert.Nil(table.Add("prod", []byte(`[{"id":"1","name":"web","cpus":2},{"id":"2","name":"db","cpus":4}]`)), "Couldn't add list")
+	assert.Nil(table.Add("dr", []byte(`{"id":"3","name":"web","labels":["a"],"state":null}`)), "Couldn't add item")
+	assert.Nil(table.Add("lab", []byte("")), "Empty outputs should be ignored")
+	assert.NotNil(table.Add("bad", []byte("ERROR")), "Non JSON outputs should fail")
+
+	assert.Equal([]string{"profile", "id", "name", "cpus", "labels", "state"}, table.Columns, "Columns should keep their order")
+	assert.Len(table.Rows, 3, "Unexpected number of rows")
+	assert.Equal("dr", table.Rows[2]["profile"], "Rows should name their profile")
+
+	var out bytes.Buffer
+	assert.Nil(table.WriteText(&out), "Couldn't write table")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(lines, 4, "Unexpected number of lines")
+	assert.Equal([]string{"PROFILE", "ID", "NAME", "CPUS", "LABELS", "STATE"}, strings.Fields(lines[0]), "Unexpected header")
+	assert.Equal([]string{"prod", "1", "web", "2"}, strings.Fields(lines[1]), "Unexpected row")
+	assert.Equal([]string{"dr", "3", "web", `["a"]`}, strings.Fields(lines[3]), "Unexpected row")
+}
+
+func TestTableScalars(t *testing.T) {
+	table := NewTable()
+	assert.Nil(t, table.Add("prod", []byte(`["a","b"]`)), "Couldn't add scalars")
+	assert.Equal(t, []string{"profile", "value"}, table.Columns, "Scalars should be added as a value column")
+	assert.Equal(t, "b", table.Rows[1]["value"], "Unexpected value")
+}
@@ -0,0 +1,164 @@
+// Package flags validates the flags of commands, collecting every problem so that they're all
+// reported at once instead of one per run
+package flags
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Context is where flags are read from, as cli.Context does
+type Context interface {
+	IsSet(name string) bool
+	String(name string) string
+}
+
+// Problem is a flag missing or given an invalid value. Problems with groups of flags, such as one of
+// them being required, hold every flag of the group
+type Problem struct {
+	Flags   []string
+	Message string
+}
+
+// Validator checks the flags of a command. Checks are chained, and their problems returned by Err
+type Validator struct {
+	c        Context
+	problems []Problem
+}
+
+// New creates a Validator of the flags of c
+func New(c Context) *Validator {
+	return &Validator{c: c}
+}
+
+// Required checks that every flag is given
+func (v *Validator) Required(flags ...string) *Validator {
+	for _, flag := range flags {
+		if !v.c.IsSet(flag) {
+			v.add([]string{flag}, "--%s is required", flag)
+		}
+	}
+	return v
+}
+
+// AnyOf checks that at least one of flags is given
+func (v *Validator) AnyOf(flags ...string) *Validator {
+	for _, flag := range flags {
+		if v.c.IsSet(flag) {
+			return v
+		}
+	}
+	v.add(flags, "One of %s is required", strings.Join(dashed(flags), ", "))
+	return v
+}
+
+// Exclusive checks that at most one of flags is given
+func (v *Validator) Exclusive(flags ...string) *Validator {
+	var given []string
+	for _, flag := range flags {
+		if v.c.IsSet(flag) {
+			given = append(given, flag)
+		}
+	}
+	if len(given) > 1 {
+		v.add(given, "Only one of %s can be given", strings.Join(dashed(flags), ", "))
+	}
+	return v
+}
+
+// Enum checks that flag, when given, has one of values
+func (v *Validator) Enum(flag string, values ...string) *Validator {
+	if !v.c.IsSet(flag) {
+		return v
+	}
+	value := v.c.String(flag)
+	for _, allowed := range values {
+		if value == allowed {
+			return v
+		}
+	}
+	v.add([]string{flag}, "--%s must be %s, not %q", flag, quotedList(values), value)
+	return v
+}
+
+// JSON checks that flags, when given, hold valid JSON
+func (v *Validator) JSON(flags ...string) *Validator {
+	for _, flag := range flags {
+		if !v.c.IsSet(flag) {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(v.c.String(flag)), &value); err != nil {
+			v.add([]string{flag}, "--%s isn't valid JSON: %s", flag, err)
+		}
+	}
+	return v
+}
+
+// Check checks flag, when given, with check, which returns why its value isn't valid
+func (v *Validator) Check(flag string, check func(value string) error) *Validator {
+	if !v.c.IsSet(flag) {
+		return v
+	}
+	if err := check(v.c.String(flag)); err != nil {
+		v.add([]string{flag}, "--%s isn't valid: %s", flag, err)
+	}
+	return v
+}
+
+// Problems returns the problems found by the checks, in the order they were made
+func (v *Validator) Problems() []Problem {
+	return v.problems
+}
+
+// Flags returns the flags with problems, without repeating them
+func (v *Validator) Flags() []string {
+	var flags []string
+	seen := make(map[string]bool)
+	for _, p := range v.problems {
+		for _, flag := range p.Flags {
+			if !seen[flag] {
+				seen[flag] = true
+				flags = append(flags, flag)
+			}
+		}
+	}
+	return flags
+}
+
+// Err returns an error listing every problem found by the checks, or nil when there's none
+func (v *Validator) Err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	messages := make([]string, len(v.problems))
+	for i, p := range v.problems {
+		messages[i] = p.Message
+	}
+	return fmt.Errorf("Invalid parameters:\n\t%s", strings.Join(messages, "\n\t"))
+}
+
+func (v *Validator) add(flags []string, format string, a ...interface{}) {
+	v.problems = append(v.problems, Problem{Flags: flags, Message: fmt.Sprintf(format, a...)})
+}
+
+func dashed(flags []string) []string {
+	d := make([]string, len(flags))
+	for i, flag := range flags {
+		d[i] = "--" + flag
+	}
+	return d
+}
+
+// quotedList returns values as "a", "b" or "c"
+func quotedList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	if len(quoted) < 2 {
+		return strings.Join(quoted, "")
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
@@ -0,0 +1,84 @@
+package flags
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeContext holds the flags given to a command
+type fakeContext map[string]string
+
+func (c fakeContext) IsSet(name string) bool {
+	_, ok := c[name]
+	return ok
+}
+
+func (c fakeContext) String(name string) string {
+	return c[name]
+}
+
+func TestValidatorReportsEveryProblem(t *testing.T) {
+	assert := assert.New(t)
+
+	c := fakeContext{"type": "startup", "parameter_values": `{"a":`}
+	v := New(c).
+		Required("template_id", "type", "script_id").
+		Enum("type", "operational", "boot", "migration", "shutdown").
+		JSON("parameter_values")
+
+	assert.Equal([]Problem{
+		{Flags: []string{"template_id"}, Message: "--template_id is required"},
+		{Flags: []string{"script_id"}, Message: "--script_id is required"},
+		{Flags: []string{"type"}, Message: `--type must be "operational", "boot", "migration" or "shutdown", not "startup"`},
+		{Flags: []string{"parameter_values"}, Message: "--parameter_values isn't valid JSON: unexpected end of JSON input"},
+	}, v.Problems(), "Unexpected problems")
+	assert.Equal([]string{"template_id", "script_id", "type", "parameter_values"}, v.Flags(), "Unexpected flags")
+	assert.EqualError(v.Err(), "Invalid parameters:\n"+
+		"\t--template_id is required\n"+
+		"\t--script_id is required\n"+
+		"\t--type must be \"operational\", \"boot\", \"migration\" or \"shutdown\", not \"startup\"\n"+
+		"\t--parameter_values isn't valid JSON: unexpected end of JSON input")
+}
+
+func TestValidatorAnyOf(t *testing.T) {
+	assert := assert.New(t)
+
+	v := New(fakeContext{}).AnyOf("id", "name")
+	assert.Equal([]Problem{{Flags: []string{"id", "name"}, Message: "One of --id, --name is required"}}, v.Problems(), "Unexpected problems")
+	assert.Nil(New(fakeContext{"name": "web"}).AnyOf("id", "name").Err(), "A single flag of the group is enough")
+}
+
+func TestValidatorExclusive(t *testing.T) {
+	assert := assert.New(t)
+
+	v := New(fakeContext{"id": "1", "file": "f", "name": "web"}).Exclusive("id", "name", "file")
+	assert.Equal([]Problem{{Flags: []string{"id", "name", "file"}, Message: "Only one of --id, --name, --file can be given"}}, v.Problems(), "Unexpected problems")
+	assert.Nil(New(fakeContext{"name": "web"}).Exclusive("id", "name").Err(), "A single flag of the group is allowed")
+	assert.Nil(New(fakeContext{}).Exclusive("id", "name").Err(), "None of the flags is allowed")
+}
+
+func TestValidatorIgnoresFlagsNotGiven(t *testing.T) {
+	assert := assert.New(t)
+
+	v := New(fakeContext{"type": "boot", "credentials": `{"key":"value"}`}).
+		Enum("type", "operational", "boot").
+		Enum("format", "json").
+		JSON("credentials", "parameter_values")
+	assert.Nil(v.Err(), "Valid and missing optional flags shouldn't be problems")
+	assert.Empty(v.Flags(), "No flags should have problems")
+}
+
+func TestValidatorCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	positive := func(value string) error {
+		if value == "" || value[0] == '-' {
+			return fmt.Errorf("%s isn't positive", value)
+		}
+		return nil
+	}
+	v := New(fakeContext{"count": "-1", "size": "2"}).Check("count", positive).Check("size", positive).Check("limit", positive)
+	assert.Equal([]Problem{{Flags: []string{"count"}, Message: "--count isn't valid: -1 isn't positive"}}, v.Problems(), "Only flags given with invalid values should be problems")
+}
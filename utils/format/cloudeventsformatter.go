@@ -0,0 +1,116 @@
+package format
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// CloudEventsSpecVersion is the version of the CloudEvents specification printed
+const CloudEventsSpecVersion = "1.0"
+
+// CloudEventsTypePrefix prefixes the type of every printed event
+const CloudEventsTypePrefix = "com.flexiant.concerto."
+
+// CloudEvent is a CloudEvents envelope in structured JSON mode
+type CloudEvent struct {
+	SpecVersion     string      `json:"specversion"`
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	Type            string      `json:"type"`
+	Time            *time.Time  `json:"time,omitempty"`
+	DataContentType string      `json:"datacontenttype"`
+	Data            interface{} `json:"data"`
+}
+
+// CloudEventer is implemented by items which provide their own event attributes.
+// Type is appended to CloudEventsTypePrefix
+type CloudEventer interface {
+	CloudEventAttributes() (id string, eventType string, t time.Time)
+}
+
+// CloudEventsFormatter prints every item wrapped in a CloudEvents envelope, one per line
+type CloudEventsFormatter struct {
+	*NDJSONFormatter
+	source string
+}
+
+// NewCloudEventsFormatter creates a new CloudEventsFormatter. Source identifies the context where events happened
+func NewCloudEventsFormatter(out io.Writer, source string) *CloudEventsFormatter {
+	log.Debug("Creating CloudEvents formatter")
+	return &CloudEventsFormatter{
+		NDJSONFormatter: NewNDJSONFormatter(out),
+		source:          source,
+	}
+}
+
+// PrintItem prints an item as a single event
+func (f *CloudEventsFormatter) PrintItem(item interface{}) error {
+	event, err := f.envelope(item)
+	if err != nil {
+		return err
+	}
+	return f.NDJSONFormatter.PrintItem(event)
+}
+
+// PrintList prints every item of the list as an event in its own line
+func (f *CloudEventsFormatter) PrintList(items interface{}) error {
+	log.Debug("PrintList")
+	it := reflect.ValueOf(items)
+	if it.Kind() == reflect.Ptr {
+		it = it.Elem()
+	}
+	if it.Kind() != reflect.Slice {
+		return fmt.Errorf("Couldn't print list. Expected slice, but received %s", it.Kind())
+	}
+
+	for i := 0; i < it.Len(); i++ {
+		if err := f.PrintItem(it.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envelope wraps item in an event. Items which don't provide their attributes
+// are typed after their Go type and identified by a hash of their content
+func (f *CloudEventsFormatter) envelope(item interface{}) (*CloudEvent, error) {
+	event := &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		Source:          f.source,
+		DataContentType: "application/json",
+		Data:            item,
+	}
+
+	if ce, ok := item.(CloudEventer); ok {
+		id, eventType, t := ce.CloudEventAttributes()
+		event.ID = id
+		event.Type = CloudEventsTypePrefix + eventType
+		if !t.IsZero() {
+			event.Time = &t
+		}
+		return event, nil
+	}
+
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	event.ID = fmt.Sprintf("%x", sha1.Sum(b))
+	t := reflect.TypeOf(item)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	name := strings.ToLower(t.Name())
+	if name == "" {
+		name = "item"
+	}
+	event.Type = CloudEventsTypePrefix + name
+	return event, nil
+}
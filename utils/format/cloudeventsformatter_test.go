@@ -0,0 +1,46 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/flexiant/concerto/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintListEventsCloudEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	eventsIn := testdata.GetEventData()
+	var b bytes.Buffer
+	f := NewCloudEventsFormatter(&b, "https://clients.concerto.io:886/")
+	assert.Nil(f.PrintList(*eventsIn), "Couldn't print event list")
+
+	lines := bytes.Split(bytes.TrimRight(b.Bytes(), "\n"), []byte("\n"))
+	assert.Len(lines, len(*eventsIn), "Every event should be printed in its own line")
+	for i, line := range lines {
+		var event map[string]interface{}
+		assert.Nil(json.Unmarshal(line, &event), "Couldn't parse event")
+		assert.Equal("1.0", event["specversion"], "Unexpected spec version")
+		assert.Equal((*eventsIn)[i].Id, event["id"], "Event ID should be kept")
+		assert.Equal("https://clients.concerto.io:886/", event["source"], "Unexpected source")
+		assert.Equal("com.flexiant.concerto.event."+strings.ToLower((*eventsIn)[i].Level), event["type"], "Unexpected type")
+		assert.NotNil(event["data"], "Event should hold data")
+	}
+}
+
+func TestPrintItemDomainCloudEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	domainsIn := testdata.GetDomainData()
+	var b bytes.Buffer
+	f := NewCloudEventsFormatter(&b, "concerto")
+	assert.Nil(f.PrintItem((*domainsIn)[0]), "Couldn't print domain")
+
+	var event map[string]interface{}
+	assert.Nil(json.Unmarshal(b.Bytes(), &event), "Couldn't parse event")
+	assert.Equal("com.flexiant.concerto.domain", event["type"], "Items should be typed after their Go type")
+	assert.NotEmpty(event["id"], "Items should be given an ID")
+}
@@ -0,0 +1,164 @@
+package format
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/crash"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/shutdown"
+)
+
+// CSVFormatter prints items as comma separated rows, with the columns of text lists. The header row is
+// only printed once, so that items printed as they're received make up a single table. Errors are
+// printed to stderr, out of the table
+type CSVFormatter struct {
+	output  *csv.Writer
+	errors  io.Writer
+	started bool
+}
+
+// NewCSVFormatter creates a new CSVFormatter
+func NewCSVFormatter(out io.Writer) *CSVFormatter {
+	log.Debug("Creating CSV formatter")
+	return &CSVFormatter{
+		output: csv.NewWriter(out),
+		errors: os.Stderr,
+	}
+}
+
+// PrintItem prints an item as a row
+func (f *CSVFormatter) PrintItem(item interface{}) error {
+	it := reflect.ValueOf(item)
+	for it.Kind() == reflect.Ptr {
+		it = it.Elem()
+	}
+	if it.Kind() != reflect.Struct {
+		return fmt.Errorf("Couldn't print item. Expected struct, but received %s", it.Kind())
+	}
+	return f.write(it.Type(), []reflect.Value{it})
+}
+
+// PrintList prints every item of the list as a row
+func (f *CSVFormatter) PrintList(items interface{}) error {
+	its := reflect.ValueOf(items)
+	if its.Kind() == reflect.Ptr {
+		its = its.Elem()
+	}
+	if its.Kind() != reflect.Slice {
+		return fmt.Errorf("Couldn't print list. Expected slice, but received %s", its.Kind())
+	}
+	rows := make([]reflect.Value, its.Len())
+	for i := range rows {
+		rows[i] = its.Index(i)
+	}
+	return f.write(its.Type().Elem(), rows)
+}
+
+// write prints rows of type t, preceded by the header row when nothing has been printed yet
+func (f *CSVFormatter) write(t reflect.Type, rows []reflect.Value) error {
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("Couldn't print list. Expected structs, but received %s", t.Kind())
+	}
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+		if !hasShowTag(t.Field(i), "nolist") {
+			fields = append(fields, i)
+		}
+	}
+
+	if !f.started {
+		header := make([]string, len(fields))
+		for i, field := range fields {
+			header[i] = t.Field(field).Tag.Get("header")
+		}
+		if err := f.output.Write(header); err != nil {
+			return err
+		}
+		f.started = true
+	}
+	for _, row := range rows {
+		record := make([]string, len(fields))
+		for i, field := range fields {
+			value, err := csvValue(row.Field(field))
+			if err != nil {
+				return err
+			}
+			record[i] = value
+		}
+		if err := f.output.Write(record); err != nil {
+			return err
+		}
+	}
+	f.output.Flush()
+	return f.output.Error()
+}
+
+// csvValue returns a field as a cell. Fields holding several values, such as lists or maps, are JSON
+func csvValue(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return "", nil
+		}
+	}
+	switch v.Type().String() {
+	case "json.RawMessage":
+		return string(v.Bytes()), nil
+	case "*json.RawMessage":
+		return string(v.Elem().Bytes()), nil
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Ptr, reflect.Interface:
+		b, err := json.Marshal(v.Interface())
+		return string(b), err
+	case reflect.Float32, reflect.Float64:
+		// spreadsheets don't take large numbers in exponent notation, as fmt prints them
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	}
+	return spreadsheetSafe(fmt.Sprint(v.Interface())), nil
+}
+
+// spreadsheetSafe prefixes with a quote values spreadsheets would take as formulas, such as a name
+// starting with =, so that opening a listing never runs them. Numbers are kept as they are
+func spreadsheetSafe(s string) string {
+	if s == "" || !strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return s
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s
+	}
+	return "'" + s
+}
+
+// hasShowTag returns whether field has tag among its show tags
+func hasShowTag(field reflect.StructField, tag string) bool {
+	for _, t := range strings.Split(field.Tag.Get("show"), ",") {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintError prints an error
+func (f *CSVFormatter) PrintError(context string, err error) {
+	fmt.Fprintf(f.errors, "ERROR: %s\n -> %s\n", context, err)
+}
+
+// PrintFatal prints an error and exists
+func (f *CSVFormatter) PrintFatal(context string, err error) {
+	if !ReportError(context, err) {
+		f.PrintError(context, err)
+	}
+	crash.Fatal(context, err)
+	shutdown.Exit(cancel.ExitCode(exit.Code(err)))
+}
@@ -0,0 +1,88 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintListServersCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	var b bytes.Buffer
+	InitializeFormatter("csv", &b)
+	assert.Nil(GetFormatter().PrintList(*testdata.GetServerData()), "CSV formatter PrintList error")
+	assertGolden(t, "servers.csv", b.Bytes())
+}
+
+func TestPrintListServersTXT(t *testing.T) {
+	assert := assert.New(t)
+
+	var b bytes.Buffer
+	InitializeFormatter("text", &b)
+	assert.Nil(GetFormatter().PrintList(*testdata.GetServerData()), "Text formatter PrintList error")
+	assertGolden(t, "servers.txt", b.Bytes())
+}
+
+func TestPrintItemCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	attributes := json.RawMessage(`{"port":"80"}`)
+	templates := []types.Template{
+		{ID: "t1", Name: "web, \"front\"", ServiceList: []string{"nginx"}, ConfigurationAttributes: &attributes},
+		{ID: "t2", Name: "db"},
+	}
+
+	var b bytes.Buffer
+	f := NewCSVFormatter(&b)
+	for _, template := range templates {
+		assert.Nil(f.PrintItem(template), "CSV formatter PrintItem error")
+	}
+	assert.Equal("ID,NAME,GENERIC IMAGE ID\nt1,\"web, \"\"front\"\"\",\nt2,db,\n", b.String(), "Header should be printed once, and nolist fields skipped")
+
+	assert.NotNil(f.PrintItem("t3"), "Items other than structs can't be printed")
+	assert.NotNil(f.PrintList(templates[0]), "Lists other than slices can't be printed")
+}
+
+func TestPrintItemFirewallProfileCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	profile := types.FirewallProfile{Id: "f1", Rules: []types.Rule{{Protocol: "tcp", MinPort: 22, MaxPort: 22, CidrIp: "0.0.0.0/0"}}}
+	var b bytes.Buffer
+	assert.Nil(NewCSVFormatter(&b).PrintItem(profile), "CSV formatter PrintItem error")
+	assertGolden(t, "firewall_profile.csv", b.Bytes())
+}
+
+func TestPrintItemFloatsCSV(t *testing.T) {
+	item := struct {
+		Seconds float32 `header:"SECONDS"`
+		Hours   float64 `header:"HOURS"`
+	}{Seconds: 2678400, Hours: 744.25}
+	var b bytes.Buffer
+	assert.Nil(t, NewCSVFormatter(&b).PrintItem(item), "CSV formatter PrintItem error")
+	assert.Equal(t, "SECONDS,HOURS\n2678400,744.25\n", b.String(), "Floats should be printed without exponent")
+}
+
+func TestSpreadsheetSafe(t *testing.T) {
+	for value, expected := range map[string]string{
+		"web":               "web",
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"-1":                "-1",
+		"+34.5":             "+34.5",
+		"-cmd":              "'-cmd",
+		"":                  "",
+	} {
+		assert.Equal(t, expected, spreadsheetSafe(value), "Unexpected cell for %q", value)
+	}
+}
+
+func TestOutput(t *testing.T) {
+	var b bytes.Buffer
+	InitializeFormatter("json", &b)
+	assert.Equal(t, &b, Output(), "Output should be the writer the formatter prints to")
+}
@@ -0,0 +1,74 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/exit"
+)
+
+// ErrorFormats lists the supported formats of the errors commands fail with
+var ErrorFormats = []string{"text", "json"}
+
+// errorFormat is the format of the errors commands fail with
+var errorFormat = "text"
+
+// errorOutput is where errors are reported in json error format, kept apart from the output of commands
+var errorOutput io.Writer = os.Stderr
+
+// ErrorReport is the machine-readable description of the error a command failed with
+type ErrorReport struct {
+	Code       int    `json:"code"`
+	Category   string `json:"category"`
+	Message    string `json:"message"`
+	Context    string `json:"context,omitempty"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+}
+
+// SetErrorFormat sets the format of the errors commands fail with
+func SetErrorFormat(ftype string) error {
+	for _, f := range ErrorFormats {
+		if f == ftype {
+			errorFormat = ftype
+			return nil
+		}
+	}
+	return exit.NewValidationError(fmt.Errorf("Unrecognized error format %s. Please, use one of [ text | json ]", ftype))
+}
+
+// NewErrorReport describes err, which a command failed with in context
+func NewErrorReport(context string, err error) ErrorReport {
+	code := cancel.ExitCode(exit.Code(err))
+	report := ErrorReport{
+		Code:     code,
+		Category: exit.Category(code),
+		Message:  strings.TrimSpace(err.Error()),
+		Context:  context,
+	}
+	if httpErr, ok := err.(*utils.HTTPError); ok {
+		report.Message = httpErr.Message
+		report.HTTPStatus = httpErr.Status
+		report.RequestID = httpErr.RequestID
+	}
+	return report
+}
+
+// ReportError writes err as a JSON error report to stderr when the json error format is set, returning
+// whether it was reported. Otherwise errors are printed by the formatter or logged
+func ReportError(context string, err error) bool {
+	if errorFormat != "json" {
+		return false
+	}
+	data, merr := json.Marshal(NewErrorReport(context, err))
+	if merr != nil {
+		return false
+	}
+	fmt.Fprintf(errorOutput, "%s\n", data)
+	return true
+}
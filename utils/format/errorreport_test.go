@@ -0,0 +1,59 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewErrorReport(t *testing.T) {
+	assert := assert.New(t)
+
+	report := NewErrorReport("Couldn't list servers", &utils.HTTPError{Status: 403, Message: "Forbidden", RequestID: "abc123"})
+	assert.Equal(exit.Auth, report.Code)
+	assert.Equal("auth", report.Category)
+	assert.Equal("Forbidden", report.Message, "The scraped API message should be reported")
+	assert.Equal(403, report.HTTPStatus)
+	assert.Equal("abc123", report.RequestID)
+
+	report = NewErrorReport("Incorrect usage.", exit.NewValidationError(fmt.Errorf("missing --id")))
+	assert.Equal(exit.Validation, report.Code)
+	assert.Equal("validation", report.Category)
+	assert.Equal("missing --id", report.Message)
+	assert.Zero(report.HTTPStatus)
+}
+
+func TestReportError(t *testing.T) {
+	assert := assert.New(t)
+
+	var b bytes.Buffer
+	output := errorOutput
+	defer func() {
+		errorOutput = output
+		errorFormat = "text"
+	}()
+	errorOutput = &b
+
+	assert.Nil(SetErrorFormat("text"))
+	assert.False(ReportError("Command failed", fmt.Errorf("boom")), "Text errors should be printed by the formatter")
+	assert.Empty(b.String())
+
+	assert.Nil(SetErrorFormat("json"))
+	assert.True(ReportError("Command failed", &utils.HTTPError{Status: 500, Message: "Internal error"}))
+	var report map[string]interface{}
+	assert.Nil(json.Unmarshal(b.Bytes(), &report))
+	assert.Equal(float64(exit.API), report["code"])
+	assert.Equal("api", report["category"])
+	assert.Equal("Internal error", report["message"])
+	assert.Equal(float64(500), report["http_status"])
+	assert.NotContains(report, "request_id", "Missing request ids should be omitted")
+
+	err := SetErrorFormat("xml")
+	assert.NotNil(err)
+	assert.Equal(exit.Validation, exit.Code(err))
+}
@@ -17,15 +17,68 @@ type Formatter interface {
 
 var formatter Formatter
 
+// output is where the formatter prints
+var output io.Writer = os.Stdout
+
+// Formats lists the output formats supported
+var Formats = []string{"text", "json", "ndjson", "cloudevents", "yaml", "csv", "ids"}
+
+// eventSource is the CloudEvents source of printed items
+var eventSource = "concerto"
+
+// SetEventSource sets the CloudEvents source, usually the API endpoint. Must be called before initializing the formatter
+func SetEventSource(source string) {
+	if source != "" {
+		eventSource = source
+	}
+}
+
 // InitializeFormatter creates a singleton Formatter
 func InitializeFormatter(ftype string, out io.Writer) {
-	if ftype == "json" {
+	output = out
+	switch ftype {
+	case "json":
 		formatter = NewJSONFormatter(out)
-	} else {
+	case "ndjson":
+		formatter = NewNDJSONFormatter(out)
+	case "cloudevents":
+		formatter = NewCloudEventsFormatter(out, eventSource)
+	case "yaml":
+		formatter = NewYAMLFormatter(out)
+	case "csv":
+		formatter = NewCSVFormatter(out)
+	case "ids":
+		formatter = NewIDsFormatter(out)
+	default:
 		formatter = NewTextFormatter(out)
 	}
 }
 
+// IsValidFormat returns whether ftype is a supported output format
+func IsValidFormat(ftype string) bool {
+	for _, f := range Formats {
+		if f == ftype {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLineDelimited returns whether f prints every item in its own line, so that items can be printed as they're received
+func IsLineDelimited(f Formatter) bool {
+	switch f.(type) {
+	case *NDJSONFormatter, *CloudEventsFormatter, *CSVFormatter, *IDsFormatter:
+		return true
+	}
+	return false
+}
+
+// Output returns where the formatter prints, so that commands printing output of their own, such as raw
+// API responses, write it along with formatted items
+func Output() io.Writer {
+	return output
+}
+
 // GetFormatter creates a new JSONFormatter
 func GetFormatter() Formatter {
 	if formatter != nil {
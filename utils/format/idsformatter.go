@@ -0,0 +1,98 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/crash"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/shutdown"
+)
+
+// IDsFormatter prints only the id of items, one per line, so that they can be piped to other commands.
+// Errors are printed to stderr, out of the pipe
+type IDsFormatter struct {
+	output io.Writer
+	errors io.Writer
+}
+
+// NewIDsFormatter creates a new IDsFormatter
+func NewIDsFormatter(out io.Writer) *IDsFormatter {
+	log.Debug("Creating IDs formatter")
+	return &IDsFormatter{
+		output: out,
+		errors: os.Stderr,
+	}
+}
+
+// PrintItem prints the id of an item
+func (f *IDsFormatter) PrintItem(item interface{}) error {
+	id, err := itemID(reflect.ValueOf(item))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(f.output, id)
+	return nil
+}
+
+// PrintList prints the id of every item of the list in its own line
+func (f *IDsFormatter) PrintList(items interface{}) error {
+	it := reflect.ValueOf(items)
+	if it.Kind() == reflect.Ptr {
+		it = it.Elem()
+	}
+	if it.Kind() != reflect.Slice {
+		return fmt.Errorf("Couldn't print list. Expected slice, but received %s", it.Kind())
+	}
+
+	for i := 0; i < it.Len(); i++ {
+		id, err := itemID(it.Index(i))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(f.output, id)
+	}
+	return nil
+}
+
+// PrintError prints an error
+func (f *IDsFormatter) PrintError(context string, err error) {
+	fmt.Fprintf(f.errors, "ERROR: %s\n -> %s\n", context, err)
+}
+
+// PrintFatal prints an error and exists
+func (f *IDsFormatter) PrintFatal(context string, err error) {
+	if !ReportError(context, err) {
+		f.PrintError(context, err)
+	}
+	crash.Fatal(context, err)
+	shutdown.Exit(cancel.ExitCode(exit.Code(err)))
+}
+
+// itemID returns the value of the id field of a struct, named id in JSON or ID in Go. Items without
+// id, such as exported files, are identified by their first field
+func itemID(item reflect.Value) (string, error) {
+	for item.Kind() == reflect.Ptr || item.Kind() == reflect.Interface {
+		item = item.Elem()
+	}
+	if item.Kind() != reflect.Struct {
+		return "", fmt.Errorf("Couldn't print id. Expected struct, but received %s", item.Kind())
+	}
+	if item.NumField() == 0 {
+		return "", fmt.Errorf("Couldn't print id of %s, as it has no fields", item.Type())
+	}
+
+	t := item.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "id" || strings.EqualFold(t.Field(i).Name, "id") {
+			return fmt.Sprint(item.Field(i).Interface()), nil
+		}
+	}
+	return fmt.Sprint(item.Field(0).Interface()), nil
+}
@@ -0,0 +1,52 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/flexiant/concerto/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintListDomainsIDs(t *testing.T) {
+	assert := assert.New(t)
+
+	domainsIn := testdata.GetDomainData()
+	var b bytes.Buffer
+	f := NewIDsFormatter(&b)
+	assert.Nil(f.PrintList(domainsIn), "Couldn't print domain list")
+
+	var expected string
+	for _, d := range *domainsIn {
+		expected += d.ID + "\n"
+	}
+	assert.Equal(expected, b.String(), "Every domain id should be printed in its own line")
+}
+
+func TestPrintItemIDs(t *testing.T) {
+	assert := assert.New(t)
+
+	var b bytes.Buffer
+	f := NewIDsFormatter(&b)
+	assert.Nil(f.PrintItem(struct {
+		Name string `json:"name"`
+		Id   string `json:"id"`
+	}{Name: "web", Id: "5630ed8fa6f9db6b84000001"}))
+	assert.Nil(f.PrintItem(&struct {
+		File string `json:"file"`
+	}{File: "servers.tf"}), "Items without id should print their first field")
+	assert.Equal("5630ed8fa6f9db6b84000001\nservers.tf\n", b.String())
+
+	assert.NotNil(f.PrintItem("not a struct"), "Non structs should fail")
+	assert.NotNil(f.PrintList("not a slice"), "Non slices should fail")
+}
+
+func TestPrintErrorIDs(t *testing.T) {
+	var out, errors bytes.Buffer
+	f := NewIDsFormatter(&out)
+	f.errors = &errors
+	f.PrintError("Testing errors", fmt.Errorf("Mocked error"))
+	assert.Empty(t, out.String(), "Errors shouldn't be piped")
+	assert.Equal(t, "ERROR: Testing errors\n -> Mocked error\n", errors.String())
+}
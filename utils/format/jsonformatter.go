@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/crash"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/shutdown"
 	"io"
-	"os"
 )
 
 // JSONFormatter prints items and lists in JSON format
@@ -76,6 +79,9 @@ func (f *JSONFormatter) PrintError(context string, err error) {
 // PrintFatal prints an error and exists
 func (f *JSONFormatter) PrintFatal(context string, err error) {
 	// TODO JSON
-	f.PrintError(context, err)
-	os.Exit(1)
+	if !ReportError(context, err) {
+		f.PrintError(context, err)
+	}
+	crash.Fatal(context, err)
+	shutdown.Exit(cancel.ExitCode(exit.Code(err)))
 }
@@ -0,0 +1,83 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/crash"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/shutdown"
+)
+
+// NDJSONFormatter prints one JSON object per line, so that output can be processed as a stream
+type NDJSONFormatter struct {
+	output io.Writer
+}
+
+// NewNDJSONFormatter creates a new NDJSONFormatter
+func NewNDJSONFormatter(out io.Writer) *NDJSONFormatter {
+	log.Debug("Creating NDJSON formatter")
+	return &NDJSONFormatter{
+		output: out,
+	}
+}
+
+// PrintItem prints an item in a single line
+func (f *NDJSONFormatter) PrintItem(item interface{}) error {
+	log.Debug("PrintItem")
+	b, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	f.output.Write(append(b, '\n'))
+
+	return nil
+}
+
+// PrintList prints every item of the list in its own line
+func (f *NDJSONFormatter) PrintList(items interface{}) error {
+	log.Debug("PrintList")
+	it := reflect.ValueOf(items)
+	if it.Kind() == reflect.Ptr {
+		it = it.Elem()
+	}
+	if it.Kind() != reflect.Slice {
+		return fmt.Errorf("Couldn't print list. Expected slice, but received %s", it.Kind())
+	}
+
+	for i := 0; i < it.Len(); i++ {
+		if err := f.PrintItem(it.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrintError prints an error
+func (f *NDJSONFormatter) PrintError(context string, err error) {
+	msg := JSONMessage{
+		Type:    "Error",
+		Context: context,
+		Message: err.Error(),
+	}
+
+	msgJSON, err := json.Marshal(msg)
+	if err != nil {
+		// fallback to hand made message
+		msgJSON = []byte(fmt.Sprintf("(Formatting error, cannot show JSON) %s -> %s", context, err))
+	}
+	f.output.Write(append(msgJSON, '\n'))
+}
+
+// PrintFatal prints an error and exists
+func (f *NDJSONFormatter) PrintFatal(context string, err error) {
+	if !ReportError(context, err) {
+		f.PrintError(context, err)
+	}
+	crash.Fatal(context, err)
+	shutdown.Exit(cancel.ExitCode(exit.Code(err)))
+}
@@ -0,0 +1,38 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/flexiant/concerto/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintListDomainsNDJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	domainsIn := testdata.GetDomainData()
+	var b bytes.Buffer
+	f := NewNDJSONFormatter(&b)
+	assert.Nil(f.PrintList(*domainsIn), "Couldn't print domain list")
+
+	lines := bytes.Split(bytes.TrimRight(b.Bytes(), "\n"), []byte("\n"))
+	assert.Len(lines, len(*domainsIn), "Every domain should be printed in its own line")
+	for i, line := range lines {
+		assert.Contains(string(line), (*domainsIn)[i].ID, "Line should contain domain ID")
+	}
+}
+
+func TestPrintListNonSliceErrorNDJSON(t *testing.T) {
+	var b bytes.Buffer
+	f := NewNDJSONFormatter(&b)
+	assert.NotNil(t, f.PrintList("not a slice"), "Non slices should fail")
+}
+
+func TestPrintErrorNDJSON(t *testing.T) {
+	var b bytes.Buffer
+	f := NewNDJSONFormatter(&b)
+	f.PrintError("Testing errors", fmt.Errorf("Mocked error"))
+	assert.Equal(t, "{\"type\":\"Error\",\"context\":\"Testing errors\",\"message\":\"Mocked error\"}\n", b.String(), "Unexpected error line")
+}
@@ -0,0 +1,181 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// Layout of text tables, as given to tabwriter
+const (
+	tableMinWidth = 15
+	tablePadding  = 3
+)
+
+// minTruncatedWidth is the narrowest a cell is truncated to, so that truncated values can still be told apart
+const minTruncatedWidth = 10
+
+// truncationMark ends truncated cells
+const truncationMark = "..."
+
+// TableOptions tunes the tables of text output
+type TableOptions struct {
+	// Wide keeps cells whole, instead of truncating the widest columns to fit the terminal
+	Wide bool
+	// NoHeader omits the header row of lists
+	NoHeader bool
+}
+
+var tableOptions TableOptions
+
+// SetTableOptions sets how text tables are printed. Must be called before initializing the formatter
+func SetTableOptions(options TableOptions) {
+	tableOptions = options
+}
+
+// Table renders rows of cells aligned in columns, as text output does
+type Table struct {
+	// Header is the first row, whose cells aren't truncated. Nil for tables without one
+	Header []string
+	Rows   [][]string
+	// Width is the width rows are truncated to fit in. 0 keeps them whole
+	Width int
+	// Fixed is the number of leading columns which aren't truncated
+	Fixed int
+	// Open leaves the last column unpadded, as in items, whose values aren't aligned with anything
+	Open bool
+}
+
+// NewTable creates a table of rows with header, truncated to fit the terminal out writes to unless
+// wide tables were asked for. The header is omitted when asked to
+func NewTable(out io.Writer, header []string, rows [][]string) *Table {
+	t := &Table{Rows: rows}
+	if !tableOptions.NoHeader {
+		t.Header = header
+	}
+	if !tableOptions.Wide {
+		t.Width = terminalWidth(out)
+	}
+	return t
+}
+
+// Write writes the table to out
+func (t *Table) Write(out io.Writer) error {
+	limits := t.limits()
+	w := tabwriter.NewWriter(out, tableMinWidth, 1, tablePadding, ' ', 0)
+	if t.Header != nil {
+		t.writeRow(w, t.Header, nil)
+	}
+	for _, row := range t.Rows {
+		t.writeRow(w, row, limits)
+	}
+	return w.Flush()
+}
+
+func (t *Table) writeRow(w io.Writer, row []string, limits []int) {
+	for i, cell := range row {
+		if limits != nil && i < len(limits) {
+			cell = truncate(cell, limits[i])
+		}
+		if t.Open && i == len(row)-1 {
+			fmt.Fprint(w, cell)
+		} else {
+			fmt.Fprintf(w, "%s\t", cell)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// limits returns the widest each column's cells can be for rows to fit the table width, or nil when they
+// fit whole. The widest columns are truncated first, leaving narrow ones whole
+func (t *Table) limits() []int {
+	if t.Width <= 0 {
+		return nil
+	}
+	var widths, floors []int
+	for _, row := range append([][]string{t.Header}, t.Rows...) {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+				floors = append(floors, minTruncatedWidth)
+			}
+			n := utf8.RuneCountInString(cell)
+			if n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	for i := range floors {
+		if i < t.Fixed {
+			floors[i] = widths[i]
+		}
+		if i < len(t.Header) {
+			if n := utf8.RuneCountInString(t.Header[i]); n > floors[i] {
+				floors[i] = n
+			}
+		}
+	}
+
+	widest := 0
+	for _, n := range widths {
+		if n > widest {
+			widest = n
+		}
+	}
+	if t.rowWidth(widths, widths, widest) <= t.Width {
+		return nil
+	}
+	limit := widest
+	for limit > minTruncatedWidth && t.rowWidth(widths, floors, limit) > t.Width {
+		limit--
+	}
+
+	limits := make([]int, len(widths))
+	for i := range widths {
+		limits[i] = capWidth(widths[i], floors[i], limit)
+	}
+	return limits
+}
+
+// rowWidth returns how wide rows are when cells are cut to limit, though never below their column's floor
+func (t *Table) rowWidth(widths []int, floors []int, limit int) int {
+	total := 0
+	for i, w := range widths {
+		w = capWidth(w, floors[i], limit)
+		if t.Open && i == len(widths)-1 {
+			total += w
+			continue
+		}
+		if w+tablePadding < tableMinWidth {
+			total += tableMinWidth
+		} else {
+			total += w + tablePadding
+		}
+	}
+	return total
+}
+
+// capWidth returns width cut to limit, though never below floor
+func capWidth(width int, floor int, limit int) int {
+	if limit < floor {
+		limit = floor
+	}
+	if width > limit {
+		return limit
+	}
+	return width
+}
+
+// truncate cuts s to width runes, ending it with the truncation mark when it's cut
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	if width <= len(truncationMark) {
+		return string(runes[:width])
+	}
+	return strings.TrimRight(string(runes[:width-len(truncationMark)]), " ") + truncationMark
+}
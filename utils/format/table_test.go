@@ -0,0 +1,78 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableWhole(t *testing.T) {
+	var b bytes.Buffer
+	table := NewTable(&b, []string{"ID", "NAME"}, [][]string{{"1", "web"}})
+	assert.Nil(t, table.Write(&b))
+	assert.Equal(t, "ID             NAME           \n1              web            \n", b.String(), "Table isn't written as tabwriter does")
+}
+
+func TestTableTruncatesWidestColumn(t *testing.T) {
+	attributes := `{"nginx":{"port":8080,"workers":4,"server_name":"www.example.com"}}`
+	table := &Table{
+		Header: []string{"ID", "NAME", "CONFIGURATION ATTRIBUTES"},
+		Rows:   [][]string{{"5b5aed", "web", attributes}},
+		Width:  80,
+	}
+	var b bytes.Buffer
+	assert.Nil(t, table.Write(&b))
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	for _, l := range lines {
+		assert.True(t, len(l) <= 80, "Line is wider than the table: %q", l)
+	}
+	assert.Contains(t, lines[0], "CONFIGURATION ATTRIBUTES", "Header was truncated")
+	assert.Contains(t, lines[1], "5b5aed         web", "Narrow columns were truncated")
+	assert.Contains(t, lines[1], `{"nginx":{"port":8080,`, "Value wasn't kept as wide as it fits")
+	assert.True(t, strings.HasSuffix(strings.TrimRight(lines[1], " "), truncationMark), "Truncated value isn't marked")
+}
+
+func TestTableTruncatesOpenColumn(t *testing.T) {
+	table := &Table{
+		Rows:  [][]string{{"CONFIGURATION ATTRIBUTES:", strings.Repeat("x", 100)}, {"ID:", "1"}},
+		Width: 60,
+		Fixed: 1,
+		Open:  true,
+	}
+	var b bytes.Buffer
+	assert.Nil(t, table.Write(&b))
+	assert.Equal(t, "CONFIGURATION ATTRIBUTES:   "+strings.Repeat("x", 29)+"...\nID:                         1\n", b.String())
+}
+
+func TestTableMinimumWidth(t *testing.T) {
+	table := &Table{Rows: [][]string{{strings.Repeat("a", 30), strings.Repeat("b", 30)}}, Width: 10}
+	var b bytes.Buffer
+	assert.Nil(t, table.Write(&b))
+	assert.Equal(t, "aaaaaaa...     bbbbbbb...     \n", b.String(), "Cells weren't truncated to the narrowest width")
+}
+
+func TestTableOptions(t *testing.T) {
+	defer SetTableOptions(TableOptions{})
+
+	SetTableOptions(TableOptions{NoHeader: true})
+	table := NewTable(&bytes.Buffer{}, []string{"ID"}, [][]string{{"1"}})
+	assert.Nil(t, table.Header, "Header wasn't omitted")
+
+	var b bytes.Buffer
+	InitializeFormatter("text", &b)
+	assert.Nil(t, GetFormatter().PrintList([]struct {
+		ID string `header:"ID"`
+	}{{"1"}}))
+	assert.Equal(t, "1              \n", b.String(), "List was printed with its header")
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "a lo...", truncate("a long value", 7), "Trailing spaces weren't trimmed before the mark")
+	assert.Equal(t, "añoñ...", truncate("añoñoñoñoñ", 7), "Runes weren't counted")
+	assert.Equal(t, "ab", truncate("abcdef", 2))
+}
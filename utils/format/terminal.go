@@ -0,0 +1,23 @@
+// +build !solaris
+
+package format
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// terminalWidth returns the columns of the terminal out writes to, or 0 when it doesn't write to one
+func terminalWidth(out io.Writer) int {
+	f, ok := out.(*os.File)
+	if !ok || !terminal.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	width, _, err := terminal.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
@@ -0,0 +1,10 @@
+// +build solaris
+
+package format
+
+import "io"
+
+// terminalWidth returns 0, as the size of terminals isn't read in Solaris. Tables are printed whole
+func terminalWidth(out io.Writer) int {
+	return 0
+}
@@ -3,12 +3,14 @@ package format
 import (
 	"fmt"
 	"io"
-	"os"
 	"reflect"
 	"strings"
-	"text/tabwriter"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/crash"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/shutdown"
 )
 
 const minifySeconds string = "minifySeconds"
@@ -32,20 +34,28 @@ func (f *TextFormatter) PrintItem(item interface{}) error {
 	it := reflect.ValueOf(item)
 	nf := it.NumField()
 
-	w := tabwriter.NewWriter(f.output, 15, 1, 3, ' ', 0)
+	var rows [][]string
 	for i := 0; i < nf; i++ {
+		header := fmt.Sprintf("%s:", it.Type().Field(i).Tag.Get("header"))
 		// TODO not the best way to use reflection. Check this later
 		switch it.Field(i).Type().String() {
 		case "json.RawMessage":
-			fmt.Fprintf(w, "%s:\t%s\n", it.Type().Field(i).Tag.Get("header"), it.Field(i).Interface())
+			rows = append(rows, []string{header, fmt.Sprintf("%s", it.Field(i).Interface())})
 		case "*json.RawMessage":
-			fmt.Fprintf(w, "%s:\t%s\n", it.Type().Field(i).Tag.Get("header"), it.Field(i).Elem())
+			rows = append(rows, []string{header, fmt.Sprintf("%s", it.Field(i).Elem())})
 		default:
-			fmt.Fprintf(w, "%s:\t%+v\n", it.Type().Field(i).Tag.Get("header"), it.Field(i).Interface())
+			rows = append(rows, []string{header, fmt.Sprintf("%+v", it.Field(i).Interface())})
 		}
 	}
-	fmt.Fprintln(w)
-	w.Flush()
+
+	// headers are kept whole, truncating values
+	t := NewTable(f.output, nil, rows)
+	t.Fixed = 1
+	t.Open = true
+	if err := t.Write(f.output); err != nil {
+		return err
+	}
+	fmt.Fprintln(f.output)
 
 	return nil
 }
@@ -60,8 +70,6 @@ func (f *TextFormatter) PrintList(items interface{}) error {
 		return fmt.Errorf("Couldn't print list. Expected slice, but received %s", t.String())
 	}
 
-	w := tabwriter.NewWriter(f.output, 15, 1, 3, ' ', 0)
-
 	header := reflect.TypeOf(items).Elem()
 	nf := header.NumField()
 
@@ -82,18 +90,20 @@ func (f *TextFormatter) PrintList(items interface{}) error {
 		}
 	}
 
-	// print header
+	// header
+	var headers []string
 	for i := 0; i < nf; i++ {
 		if !avoid[i] {
-			fmt.Fprintf(w, "%+v\t", header.Field(i).Tag.Get("header"))
+			headers = append(headers, fmt.Sprintf("%+v", header.Field(i).Tag.Get("header")))
 		}
 	}
-	fmt.Fprintln(w)
 
-	// print contents
+	// contents
+	var rows [][]string
 	for i := 0; i < its.Len(); i++ {
 		it := its.Index(i)
 		nf := it.NumField()
+		var row []string
 		for i := 0; i < nf; i++ {
 			if !avoid[i] {
 
@@ -109,33 +119,32 @@ func (f *TextFormatter) PrintList(items interface{}) error {
 					d := int(remainingSeconds / 86400)
 
 					if d > 0 {
-						fmt.Fprintf(w, "%dd%dh%dm\t", d, h, m)
+						row = append(row, fmt.Sprintf("%dd%dh%dm", d, h, m))
 					} else {
-						fmt.Fprintf(w, "%dh%dm%ds\t", h, m, s)
+						row = append(row, fmt.Sprintf("%dh%dm%ds", h, m, s))
 					}
 
 				} else {
 
 					switch it.Field(i).Type().String() {
 					case "json.RawMessage":
-						fmt.Fprintf(w, "%s\t", it.Field(i).Interface())
+						row = append(row, fmt.Sprintf("%s", it.Field(i).Interface()))
 					case "*json.RawMessage":
 						if it.Field(i).IsNil() {
-							fmt.Fprintf(w, " \t")
+							row = append(row, " ")
 						} else {
-							fmt.Fprintf(w, "%s\t", it.Field(i).Elem())
+							row = append(row, fmt.Sprintf("%s", it.Field(i).Elem()))
 						}
 					default:
-						fmt.Fprintf(w, "%+v\t", it.Field(i).Interface())
+						row = append(row, fmt.Sprintf("%+v", it.Field(i).Interface()))
 					}
 				}
 			}
 		}
-		fmt.Fprintln(w)
+		rows = append(rows, row)
 	}
-	w.Flush()
 
-	return nil
+	return NewTable(f.output, headers, rows).Write(f.output)
 }
 
 // PrintError prints an error
@@ -145,6 +154,9 @@ func (f *TextFormatter) PrintError(context string, err error) {
 
 // PrintFatal prints an error and exists
 func (f *TextFormatter) PrintFatal(context string, err error) {
-	f.PrintError(context, err)
-	os.Exit(1)
+	if !ReportError(context, err) {
+		f.PrintError(context, err)
+	}
+	crash.Fatal(context, err)
+	shutdown.Exit(cancel.ExitCode(exit.Code(err)))
 }
@@ -0,0 +1,208 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/crash"
+	"github.com/flexiant/concerto/utils/exit"
+	"github.com/flexiant/concerto/utils/shutdown"
+)
+
+// yamlPlain matches strings that can be written without quotes, as they can't be read as
+// numbers, booleans, nulls, timestamps or YAML syntax
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_/][^:#\n\r\t"'\\]*$`)
+
+// yamlReserved are plain words that YAML reads as booleans or nulls
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true, "y": true, "n": true, "null": true,
+}
+
+// YAMLFormatter prints items and lists in YAML format. Fields keep the order and names of JSON output
+type YAMLFormatter struct {
+	output  io.Writer
+	printed bool
+}
+
+// NewYAMLFormatter creates a new YAMLFormatter
+func NewYAMLFormatter(out io.Writer) *YAMLFormatter {
+	log.Debug("Creating YAML formatter")
+	return &YAMLFormatter{
+		output: out,
+	}
+}
+
+// PrintItem prints an item as a YAML mapping
+func (f *YAMLFormatter) PrintItem(item interface{}) error {
+	log.Debug("PrintItem")
+	return f.print(item)
+}
+
+// PrintList prints item list as a YAML sequence
+func (f *YAMLFormatter) PrintList(items interface{}) error {
+	log.Debug("PrintList")
+	return f.print(items)
+}
+
+// PrintError prints an error
+func (f *YAMLFormatter) PrintError(context string, err error) {
+	msg := JSONMessage{
+		Type:    "Error",
+		Context: context,
+		Message: err.Error(),
+	}
+	if perr := f.print(msg); perr != nil {
+		// fallback to hand made message
+		fmt.Fprintf(f.output, "(Formatting error, cannot show YAML)\n %s -> %s \n", context, err)
+	}
+}
+
+// PrintFatal prints an error and exists
+func (f *YAMLFormatter) PrintFatal(context string, err error) {
+	if !ReportError(context, err) {
+		f.PrintError(context, err)
+	}
+	crash.Fatal(context, err)
+	shutdown.Exit(cancel.ExitCode(exit.Code(err)))
+}
+
+// print writes v as a YAML document. Documents after the first one are separated by ---
+func (f *YAMLFormatter) print(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	if f.printed {
+		b.WriteString("---\n")
+	}
+	if err = writeYAML(&b, data); err != nil {
+		return err
+	}
+	f.printed = true
+	_, err = b.WriteTo(f.output)
+	return err
+}
+
+// yamlNode is a JSON value whose object keys keep their order
+type yamlNode struct {
+	scalar   interface{}
+	object   bool
+	array    bool
+	keys     []string
+	children []*yamlNode
+}
+
+// writeYAML converts a JSON document into YAML
+func writeYAML(b *bytes.Buffer, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := readYAMLNode(decoder)
+	if err != nil {
+		return err
+	}
+	if node.isCollection() && len(node.children) > 0 {
+		node.write(b, 0)
+	} else {
+		b.WriteString(node.inline())
+		b.WriteString("\n")
+	}
+	return nil
+}
+
+func readYAMLNode(decoder *json.Decoder) (*yamlNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return &yamlNode{scalar: token}, nil
+	}
+
+	node := &yamlNode{object: delim == '{', array: delim == '['}
+	for decoder.More() {
+		if node.object {
+			if token, err = decoder.Token(); err != nil {
+				return nil, err
+			}
+			node.keys = append(node.keys, token.(string))
+		}
+		child, err := readYAMLNode(decoder)
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, child)
+	}
+	// closing delimiter
+	_, err = decoder.Token()
+	return node, err
+}
+
+func (n *yamlNode) isCollection() bool {
+	return n.object || n.array
+}
+
+// write writes a non empty collection, indented by indent spaces
+func (n *yamlNode) write(b *bytes.Buffer, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for i, child := range n.children {
+		if n.object {
+			b.WriteString(pad + yamlString(n.keys[i]) + ":")
+			switch {
+			case !child.isCollection() || len(child.children) == 0:
+				b.WriteString(" " + child.inline() + "\n")
+			case child.array:
+				// sequences are written at the indentation of their key
+				b.WriteString("\n")
+				child.write(b, indent)
+			default:
+				b.WriteString("\n")
+				child.write(b, indent+2)
+			}
+			continue
+		}
+
+		if !child.isCollection() || len(child.children) == 0 {
+			b.WriteString(pad + "- " + child.inline() + "\n")
+			continue
+		}
+		// the first line of nested collections follows the dash
+		var nested bytes.Buffer
+		child.write(&nested, indent+2)
+		b.WriteString(pad + "- ")
+		b.Write(nested.Bytes()[indent+2:])
+	}
+}
+
+// inline returns scalars and empty collections
+func (n *yamlNode) inline() string {
+	switch {
+	case n.object:
+		return "{}"
+	case n.array:
+		return "[]"
+	}
+	switch v := n.scalar.(type) {
+	case nil:
+		return "null"
+	case string:
+		return yamlString(v)
+	}
+	return fmt.Sprint(n.scalar)
+}
+
+// yamlString quotes s when it would otherwise be read as something else than the same string
+func yamlString(s string) string {
+	if yamlPlain.MatchString(s) && !yamlReserved[strings.ToLower(s)] && strings.TrimSpace(s) == s {
+		return s
+	}
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
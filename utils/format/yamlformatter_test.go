@@ -0,0 +1,102 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/flexiant/concerto/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+type yamlTestItem struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Enabled  bool              `json:"enabled"`
+	Port     int               `json:"port"`
+	Labels   []string          `json:"labels"`
+	Settings map[string]string `json:"settings"`
+	Nested   []yamlTestNested  `json:"nested"`
+	Empty    []string          `json:"empty"`
+	Missing  *string           `json:"missing"`
+}
+
+type yamlTestNested struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func TestPrintItemYAML(t *testing.T) {
+	var b bytes.Buffer
+	f := NewYAMLFormatter(&b)
+	item := yamlTestItem{
+		ID:       "5630ed8fa6f9db6b84000001",
+		Name:     "web: production",
+		Enabled:  true,
+		Port:     443,
+		Labels:   []string{"a", "yes"},
+		Settings: map[string]string{"mode": ""},
+		Nested:   []yamlTestNested{{"k1", "v1"}, {"k2", "v2"}},
+	}
+	assert.Nil(t, f.PrintItem(item), "Couldn't print item")
+	assert.Equal(t, `id: "5630ed8fa6f9db6b84000001"
+name: "web: production"
+enabled: true
+port: 443
+labels:
+- a
+- "yes"
+settings:
+  mode: ""
+nested:
+- key: k1
+  value: v1
+- key: k2
+  value: v2
+empty: null
+missing: null
+`, b.String(), "Unexpected YAML")
+}
+
+func TestPrintListYAML(t *testing.T) {
+	assert := assert.New(t)
+
+	domainsIn := testdata.GetDomainData()
+	var b bytes.Buffer
+	f := NewYAMLFormatter(&b)
+	assert.Nil(f.PrintList(*domainsIn), "Couldn't print domain list")
+	for _, domain := range *domainsIn {
+		assert.Contains(b.String(), fmt.Sprintf("- id: %s\n", yamlString(domain.ID)), "Every domain should be an item of the sequence")
+	}
+
+	b.Reset()
+	assert.Nil(f.PrintList([]string{}), "Couldn't print empty list")
+	assert.Equal("---\n[]\n", b.String(), "Empty lists should be printed inline, in a new document")
+}
+
+func TestPrintErrorYAML(t *testing.T) {
+	var b bytes.Buffer
+	f := NewYAMLFormatter(&b)
+	f.PrintError("Testing errors", fmt.Errorf("Mocked error"))
+	assert.Equal(t, "type: Error\ncontext: Testing errors\nmessage: Mocked error\n", b.String(), "Unexpected error")
+}
+
+func TestYAMLString(t *testing.T) {
+	assert := assert.New(t)
+	for s, expected := range map[string]string{
+		"web":           "web",
+		"/v1/servers":   "/v1/servers",
+		"":              `""`,
+		"true":          `"true"`,
+		"No":            `"No"`,
+		"12":            `"12"`,
+		"2016-01-01":    `"2016-01-01"`,
+		"a #comment":    `"a #comment"`,
+		"-dash":         `"-dash"`,
+		"line\nbreak":   `"line\nbreak"`,
+		"trailing ":     `"trailing "`,
+		"say \"hello\"": `"say \"hello\""`,
+	} {
+		assert.Equal(expected, yamlString(s), "Unexpected quoting of %q", s)
+	}
+}
@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{"dot", "mermaid"}
+
+// kinds of nodes, with the shape used to draw them
+var dotShapes = map[string]string{
+	"template":         "box",
+	"script":           "note",
+	"server":           "box3d",
+	"workspace":        "folder",
+	"firewall_profile": "hexagon",
+	"dns_record":       "ellipse",
+}
+
+// Node is an element of the topology
+type Node struct {
+	Key   string
+	Kind  string
+	Label string
+}
+
+// Edge is a relationship between two nodes
+type Edge struct {
+	From  string
+	To    string
+	Label string
+}
+
+// Graph stores nodes and edges in the order they were added
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+
+	keys  map[string]bool
+	edges map[Edge]bool
+}
+
+// New creates an empty graph
+func New() *Graph {
+	return &Graph{
+		keys:  make(map[string]bool),
+		edges: make(map[Edge]bool),
+	}
+}
+
+var invalidKeyChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Key returns the key identifying a node of a kind
+func Key(kind string, id string) string {
+	return invalidKeyChars.ReplaceAllString(kind+"_"+id, "_")
+}
+
+// AddNode adds a node, unless it already exists, and returns its key
+func (g *Graph) AddNode(kind string, id string, label string) string {
+	key := Key(kind, id)
+	if !g.keys[key] {
+		g.keys[key] = true
+		g.Nodes = append(g.Nodes, Node{Key: key, Kind: kind, Label: label})
+	}
+	return key
+}
+
+// HasNode returns whether a node of a kind has been added
+func (g *Graph) HasNode(kind string, id string) bool {
+	return g.keys[Key(kind, id)]
+}
+
+// AddEdge adds an edge between two node keys, unless it already exists
+func (g *Graph) AddEdge(from string, to string, label string) {
+	e := Edge{From: from, To: to, Label: label}
+	if !g.edges[e] {
+		g.edges[e] = true
+		g.Edges = append(g.Edges, e)
+	}
+}
+
+// Write writes the graph in the given format
+func (g *Graph) Write(w io.Writer, format string) error {
+	switch format {
+	case "dot":
+		return g.WriteDot(w)
+	case "mermaid":
+		return g.WriteMermaid(w)
+	}
+	return fmt.Errorf("Unsupported graph format %s. Please, use one of [ %s ]", format, strings.Join(Formats, " | "))
+}
+
+// WriteDot writes the graph in Graphviz DOT language
+func (g *Graph) WriteDot(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "digraph concerto {\n  rankdir=LR;"); err != nil {
+		return err
+	}
+	for _, n := range g.Nodes {
+		shape := dotShapes[n.Kind]
+		if shape == "" {
+			shape = "box"
+		}
+		if _, err := fmt.Fprintf(w, "  %s [label=%s, shape=%s];\n", n.Key, dotQuote(n.Label+"\n"+n.Kind), shape); err != nil {
+			return err
+		}
+	}
+	for _, e := range g.Edges {
+		if _, err := fmt.Fprintf(w, "  %s -> %s [label=%s];\n", e.From, e.To, dotQuote(e.Label)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "graph LR"); err != nil {
+		return err
+	}
+	for _, n := range g.Nodes {
+		if _, err := fmt.Fprintf(w, "  %s[\"%s<br/><i>%s</i>\"]\n", n.Key, mermaidEscape(n.Label), n.Kind); err != nil {
+			return err
+		}
+	}
+	for _, e := range g.Edges {
+		if _, err := fmt.Fprintf(w, "  %s -->|\"%s\"| %s\n", e.From, mermaidEscape(e.Label), e.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dotQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return `"` + s + `"`
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", " ").Replace(s)
+}
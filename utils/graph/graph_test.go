@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testGraph() *Graph {
+	g := New()
+	template := g.AddNode("template", "5643", "wordpress")
+	script := g.AddNode("script", "56a1", `install "plugins"`)
+	server := g.AddNode("server", "99b2", "wp1")
+	g.AddEdge(template, script, "boot #1")
+	g.AddEdge(server, template, "uses")
+	g.AddEdge(server, g.AddNode("template", "5643", "duplicated"), "uses")
+	return g
+}
+
+func TestAddNode(t *testing.T) {
+	assert := assert.New(t)
+
+	g := testGraph()
+	assert.Len(g.Nodes, 3, "Nodes shouldn't be duplicated")
+	assert.Len(g.Edges, 2, "Edges shouldn't be duplicated")
+	assert.Equal("wordpress", g.Nodes[0].Label, "First label should be kept")
+	assert.True(g.HasNode("server", "99b2"), "Server should have been added")
+	assert.Equal("dns_record_a_b_c", Key("dns_record", "a.b-c"), "Keys should be sanitized")
+}
+
+func TestWriteDot(t *testing.T) {
+	var b bytes.Buffer
+	assert.Nil(t, testGraph().Write(&b, "dot"), "Couldn't write graph")
+	assert.Equal(t, `digraph concerto {
+  rankdir=LR;
+  template_5643 [label="wordpress\ntemplate", shape=box];
+  script_56a1 [label="install \"plugins\"\nscript", shape=note];
+  server_99b2 [label="wp1\nserver", shape=box3d];
+  template_5643 -> script_56a1 [label="boot #1"];
+  server_99b2 -> template_5643 [label="uses"];
+}
+`, b.String(), "Unexpected DOT graph")
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var b bytes.Buffer
+	assert.Nil(t, testGraph().Write(&b, "mermaid"), "Couldn't write graph")
+	assert.Equal(t, `graph LR
+  template_5643["wordpress<br/><i>template</i>"]
+  script_56a1["install #quot;plugins#quot;<br/><i>script</i>"]
+  server_99b2["wp1<br/><i>server</i>"]
+  template_5643 -->|"boot #1"| script_56a1
+  server_99b2 -->|"uses"| template_5643
+`, b.String(), "Unexpected Mermaid graph")
+
+	assert.NotNil(t, testGraph().Write(&b, "svg"), "Unsupported formats should fail")
+}
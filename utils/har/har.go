@@ -0,0 +1,337 @@
+package har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/flexiant/concerto/utils/logging"
+)
+
+// maxBodySize limits the bodies kept in a recording, so that downloads don't bloat it
+const maxBodySize = 1 << 20
+
+// repeatedSlashes matches consecutive slashes in request paths
+var repeatedSlashes = regexp.MustCompile(`/{2,}`)
+
+// Archive is a recording of API interactions, laid out as an HTTP Archive (HAR) file
+type Archive struct {
+	Log Log `json:"log"`
+}
+
+// Log holds the recorded entries, in the order requests were sent
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator identifies the program that made the recording
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is a request and its response. Requests that failed without response have a zero
+// status, and the error in the comment
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Time            float64   `json:"time"`
+	Request         Request   `json:"request"`
+	Response        Response  `json:"response"`
+	Comment         string    `json:"comment,omitempty"`
+}
+
+// Request is a recorded request
+type Request struct {
+	Method      string    `json:"method"`
+	URL         string    `json:"url"`
+	HTTPVersion string    `json:"httpVersion"`
+	Headers     []Header  `json:"headers"`
+	PostData    *PostData `json:"postData,omitempty"`
+	BodySize    int64     `json:"bodySize"`
+}
+
+// Response is a recorded response
+type Response struct {
+	Status      int      `json:"status"`
+	StatusText  string   `json:"statusText"`
+	HTTPVersion string   `json:"httpVersion"`
+	Headers     []Header `json:"headers"`
+	Content     Content  `json:"content"`
+	BodySize    int64    `json:"bodySize"`
+}
+
+// Header is a header name and value. Headers with several values are recorded once per value
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PostData is the body of a request
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// Content is the body of a response. Binary bodies are base64 encoded
+type Content struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// Recorder captures API interactions. Credentials are redacted from headers, URLs and bodies,
+// so that recordings can be attached to bug reports
+type Recorder struct {
+	creator Creator
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewRecorder returns an empty recorder for program name and version
+func NewRecorder(name string, version string) *Recorder {
+	return &Recorder{creator: Creator{Name: name, Version: version}}
+}
+
+// Wrap returns a transport sending requests through next, and recording them into r
+func (r *Recorder) Wrap(next http.RoundTripper) http.RoundTripper {
+	return &recordingTransport{recorder: r, next: next}
+}
+
+// Entries returns the interactions recorded so far
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// Save writes the recording to file
+func (r *Recorder) Save(file string) error {
+	archive := Archive{Log: Log{Version: "1.2", Creator: r.creator, Entries: r.Entries()}}
+	if archive.Log.Entries == nil {
+		archive.Log.Entries = []Entry{}
+	}
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0600)
+}
+
+func (r *Recorder) add(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, e)
+}
+
+type recordingTransport struct {
+	recorder *Recorder
+	next     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *recordingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	entry := Entry{
+		StartedDateTime: time.Now().UTC(),
+		Request: Request{
+			Method:      request.Method,
+			URL:         logging.Redact(request.URL.String()),
+			HTTPVersion: request.Proto,
+			Headers:     headers(request.Header),
+			BodySize:    -1,
+		},
+	}
+	if request.Body != nil {
+		body, err := ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		entry.Request.BodySize = int64(len(body))
+		entry.Request.PostData = &PostData{MimeType: request.Header.Get("Content-Type"), Text: logging.Redact(string(body))}
+	}
+
+	response, err := t.next.RoundTrip(request)
+	entry.Time = float64(time.Since(entry.StartedDateTime)) / float64(time.Millisecond)
+	if err != nil {
+		entry.Comment = logging.Redact(err.Error())
+		t.recorder.add(entry)
+		return nil, err
+	}
+
+	// only bodies that fit in a recording are read ahead, bigger ones are streamed to the caller
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, maxBodySize+1))
+	if err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+	entry.Response = Response{
+		Status:      response.StatusCode,
+		StatusText:  http.StatusText(response.StatusCode),
+		HTTPVersion: response.Proto,
+		Headers:     headers(response.Header),
+	}
+	if len(body) > maxBodySize {
+		entry.Response.Content = Content{
+			Size:     response.ContentLength,
+			MimeType: response.Header.Get("Content-Type"),
+			Comment:  fmt.Sprintf("Body over %d bytes not recorded", maxBodySize),
+		}
+		entry.Response.BodySize = response.ContentLength
+		response.Body = &streamedBody{Reader: io.MultiReader(bytes.NewReader(body), response.Body), Closer: response.Body}
+	} else {
+		response.Body.Close()
+		response.Body = ioutil.NopCloser(bytes.NewReader(body))
+		entry.Response.Content = content(body, response.Header.Get("Content-Type"))
+		entry.Response.BodySize = int64(len(body))
+	}
+	t.recorder.add(entry)
+	return response, nil
+}
+
+// streamedBody passes on the part of a body read ahead, followed by the rest of it
+type streamedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// Replayer serves recorded responses, without contacting the API
+type Replayer struct {
+	mu      sync.Mutex
+	entries []Entry
+	served  []bool
+}
+
+// NewReplayer returns a replayer serving entries
+func NewReplayer(entries []Entry) *Replayer {
+	return &Replayer{entries: entries, served: make([]bool, len(entries))}
+}
+
+// LoadReplayer returns a replayer serving the entries recorded in file
+func LoadReplayer(file string) (*Replayer, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var archive Archive
+	if err = json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("%s isn't a valid recording: %s", file, err)
+	}
+	return NewReplayer(archive.Log.Entries), nil
+}
+
+// RoundTrip implements http.RoundTripper. Requests are matched by method, path and query, so that
+// recordings can be replayed against any endpoint. Repeated requests get the responses in the
+// order they were recorded, and the last one once all have been served
+func (r *Replayer) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Body != nil {
+		request.Body.Close()
+	}
+	entry, ok := r.next(request.Method, requestURI(logging.Redact(request.URL.String())))
+	if !ok {
+		return nil, fmt.Errorf("No recorded response for %s %s", request.Method, request.URL.RequestURI())
+	}
+	if entry.Response.Status == 0 {
+		return nil, fmt.Errorf("Recorded failure: %s", entry.Comment)
+	}
+
+	body, err := entry.Response.Content.body()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid recorded response for %s %s: %s", request.Method, request.URL.RequestURI(), err)
+	}
+	response := &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.Response.Status, entry.Response.StatusText),
+		StatusCode:    entry.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}
+	for _, h := range entry.Response.Headers {
+		response.Header.Add(h.Name, h.Value)
+	}
+	return response, nil
+}
+
+func (r *Replayer) next(method string, uri string) (Entry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, e := range r.entries {
+		if e.Request.Method != method || requestURI(e.Request.URL) != uri {
+			continue
+		}
+		if !r.served[i] {
+			r.served[i] = true
+			return e, true
+		}
+		last = i
+	}
+	if last < 0 {
+		return Entry{}, false
+	}
+	return r.entries[last], true
+}
+
+// requestURI returns the path and query of a URL. Repeated slashes are collapsed, as endpoints
+// are configured with a trailing slash
+func requestURI(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		rawURL = u.RequestURI()
+	}
+	return repeatedSlashes.ReplaceAllString(rawURL, "/")
+}
+
+func headers(h http.Header) []Header {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []Header
+	for _, name := range names {
+		for _, value := range h[name] {
+			result = append(result, Header{Name: name, Value: logging.RedactHeader(name, value)})
+		}
+	}
+	return result
+}
+
+func content(body []byte, mimeType string) Content {
+	c := Content{Size: int64(len(body)), MimeType: mimeType}
+	switch {
+	case utf8.Valid(body):
+		c.Text = logging.Redact(string(body))
+	default:
+		c.Text = base64.StdEncoding.EncodeToString(body)
+		c.Encoding = "base64"
+	}
+	return c
+}
+
+func (c Content) body() ([]byte, error) {
+	if strings.EqualFold(c.Encoding, "base64") {
+		return base64.StdEncoding.DecodeString(c.Text)
+	}
+	return []byte(c.Text), nil
+}
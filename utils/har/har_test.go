@@ -0,0 +1,125 @@
+package har
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordReplay(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		fmt.Fprintf(w, `{"call":%d,"echo":%q}`, calls, body)
+	}))
+	defer ts.Close()
+
+	recorder := NewRecorder("concerto", "0.1.0")
+	client := &http.Client{Transport: recorder.Wrap(http.DefaultTransport)}
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("POST", ts.URL+"/v1/cloud/servers?api_key=abc", strings.NewReader(`{"password":"s3cr3t"}`))
+		req.Header.Set("Authorization", "Bearer abc")
+		resp, err := client.Do(req)
+		assert.Nil(err, "Recorded request failed")
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Contains(string(body), fmt.Sprintf(`"call":%d`, i+1), "Recorder shouldn't alter responses")
+		assert.Contains(string(body), "s3cr3t", "Recorder shouldn't alter requests")
+	}
+
+	entries := recorder.Entries()
+	assert.Len(entries, 2, "Every request should be recorded")
+	e := entries[0]
+	assert.Equal("POST", e.Request.Method, "Unexpected method")
+	assert.NotContains(e.Request.URL, "abc", "Query credentials should be redacted")
+	assert.NotContains(e.Request.PostData.Text, "s3cr3t", "Body credentials should be redacted")
+	for _, h := range append(e.Request.Headers, e.Response.Headers...) {
+		assert.NotContains(h.Value, "abc", "Header credentials should be redacted")
+	}
+	assert.Equal(200, e.Response.Status, "Unexpected status")
+
+	dir, err := ioutil.TempDir("", "har")
+	assert.Nil(err, "Couldn't create temporary directory")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "trace.har")
+	assert.Nil(recorder.Save(file), "Couldn't save recording")
+
+	replayer, err := LoadReplayer(file)
+	assert.Nil(err, "Couldn't load recording")
+	client = &http.Client{Transport: replayer}
+	for _, expected := range []string{`"call":1`, `"call":2`, `"call":2`} {
+		req, _ := http.NewRequest("POST", "https://other.example.com/v1/cloud/servers?api_key=xyz", bytes.NewReader(nil))
+		resp, err := client.Do(req)
+		assert.Nil(err, "Replayed request failed")
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Contains(string(body), expected, "Responses should be replayed in order, repeating the last one")
+		assert.Equal("application/json", resp.Header.Get("Content-Type"), "Headers should be replayed")
+	}
+	assert.Equal(2, calls, "Replaying shouldn't contact the server")
+
+	_, err = client.Get("https://other.example.com/v1/cloud/workspaces")
+	assert.NotNil(err, "Requests that weren't recorded should fail")
+}
+
+func TestReplayFailureAndBinary(t *testing.T) {
+	assert := assert.New(t)
+
+	binary := []byte{0xff, 0x00, 0xfe}
+	replayer := NewReplayer([]Entry{
+		{Request: Request{Method: "GET", URL: "https://clients.concerto.io:886/v1/down"}, Comment: "connection refused"},
+		{Request: Request{Method: "GET", URL: "https://clients.concerto.io:886/v1/file"}, Response: Response{Status: 200, Content: content(binary, "application/octet-stream")}},
+	})
+	client := &http.Client{Transport: replayer}
+
+	_, err := client.Get("https://clients.concerto.io:886/v1/down")
+	assert.NotNil(err, "Recorded failures should be replayed")
+
+	resp, err := client.Get("https://clients.concerto.io:886//v1/file")
+	assert.Nil(err, "Replayed request failed")
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(binary, body, "Binary bodies should be replayed as recorded")
+}
+
+func TestRecordLargeBody(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte("a"), maxBodySize+10))
+		w.(http.Flusher).Flush()
+		// the rest is only sent once the response has been handed to the caller
+		<-release
+		w.Write([]byte("end"))
+	}))
+	defer ts.Close()
+
+	recorder := NewRecorder("concerto", "0.1.0")
+	client := &http.Client{Transport: recorder.Wrap(http.DefaultTransport)}
+	resp, err := client.Get(ts.URL + "/v1/file")
+	close(release)
+	assert.Nil(err, "Recorded request failed")
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Nil(err, "Couldn't read streamed body")
+	assert.Len(body, maxBodySize+13, "Whole body should reach the caller")
+	assert.True(bytes.HasSuffix(body, []byte("end")), "Rest of the body should be streamed")
+
+	entries := recorder.Entries()
+	assert.Len(entries, 1, "Request should be recorded")
+	assert.Empty(entries[0].Response.Content.Text, "Large bodies shouldn't be recorded")
+	assert.NotEmpty(entries[0].Response.Content.Comment, "Large bodies should be noted")
+}
@@ -0,0 +1,119 @@
+// Package httpcache keeps API responses on disk, so that read-heavy sessions and shell completion revalidate
+// them with their ETag, or reuse them while they're fresh, instead of receiving them again
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// now is replaced in tests
+var now = time.Now
+
+// Entry is a cached response
+type Entry struct {
+	Status int       `json:"status"`
+	ETag   string    `json:"etag,omitempty"`
+	Body   []byte    `json:"body"`
+	Stored time.Time `json:"stored"`
+}
+
+// Cache stores entries as files of a directory only readable by its owner, as responses may hold secrets
+type Cache struct {
+	dir    string
+	maxAge time.Duration
+}
+
+// New returns a cache in dir. Entries stored less than maxAge ago are fresh
+func New(dir string, maxAge time.Duration) *Cache {
+	return &Cache{dir: dir, maxAge: maxAge}
+}
+
+// Dir returns the directory of the cache
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// MaxAge returns how long entries are fresh
+func (c *Cache) MaxAge() time.Duration {
+	return c.maxAge
+}
+
+// Get returns the entry of key, or nil when there's none or it can't be read
+func (c *Cache) Get(key string) *Entry {
+	data, err := ioutil.ReadFile(c.file(key))
+	if err != nil {
+		return nil
+	}
+	var e Entry
+	if err = json.Unmarshal(data, &e); err != nil {
+		return nil
+	}
+	return &e
+}
+
+// Fresh returns whether e can be used without revalidating it
+func (c *Cache) Fresh(e *Entry) bool {
+	return c.maxAge > 0 && now().Sub(e.Stored) < c.maxAge
+}
+
+// Put stores e as the entry of key, stored now. Entries are replaced at once, so that concurrent
+// commands never read half written ones
+func (c *Cache) Put(key string, e Entry) error {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	e.Stored = now()
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(c.dir, "entry")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.file(key))
+}
+
+// Clear removes every entry, returning how many there were
+func (c *Cache) Clear() (int, error) {
+	files, err := ioutil.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		if err = os.Remove(filepath.Join(c.dir, f.Name())); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// file returns the file of key. Keys are hashed, as they hold URLs
+func (c *Cache) file(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,76 @@
+package httpcache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tempCache(t *testing.T, maxAge time.Duration) (*Cache, func()) {
+	dir, err := ioutil.TempDir("", "concerto-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(filepath.Join(dir, "cache"), maxAge), func() { os.RemoveAll(dir) }
+}
+
+func TestPutGet(t *testing.T) {
+	assert := assert.New(t)
+	c, cleanup := tempCache(t, 0)
+	defer cleanup()
+
+	assert.Nil(c.Get("https://clients.concerto.io:886/v1/cloud/servers"), "Missing entries shouldn't be returned")
+	assert.Nil(c.Put("https://clients.concerto.io:886/v1/cloud/servers", Entry{Status: 200, ETag: `"abc"`, Body: []byte(`[]`)}))
+
+	e := c.Get("https://clients.concerto.io:886/v1/cloud/servers")
+	if assert.NotNil(e, "Stored entries should be returned") {
+		assert.Equal(200, e.Status, "Unexpected status")
+		assert.Equal(`"abc"`, e.ETag, "Unexpected ETag")
+		assert.Equal([]byte(`[]`), e.Body, "Unexpected body")
+	}
+	assert.Nil(c.Get("https://clients.concerto.io:886/v1/cloud/workspaces"), "Entries of other keys shouldn't be returned")
+
+	info, err := os.Stat(c.Dir())
+	if assert.Nil(err) {
+		assert.Equal(os.FileMode(0700), info.Mode().Perm(), "Cache should only be readable by its owner")
+	}
+}
+
+func TestFresh(t *testing.T) {
+	assert := assert.New(t)
+	clock := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	c, cleanup := tempCache(t, 30*time.Second)
+	defer cleanup()
+	assert.Nil(c.Put("servers", Entry{Status: 200}))
+	e := c.Get("servers")
+
+	clock = clock.Add(10 * time.Second)
+	assert.True(c.Fresh(e), "Entries younger than the maximum age should be fresh")
+	clock = clock.Add(30 * time.Second)
+	assert.False(c.Fresh(e), "Entries older than the maximum age should be revalidated")
+	assert.False(New(c.Dir(), 0).Fresh(e), "Entries should always be revalidated without maximum age")
+}
+
+func TestClear(t *testing.T) {
+	assert := assert.New(t)
+	c, cleanup := tempCache(t, 0)
+	defer cleanup()
+
+	removed, err := c.Clear()
+	assert.Nil(err, "Clearing a cache never written shouldn't fail")
+	assert.Equal(0, removed, "Unexpected entries removed")
+
+	assert.Nil(c.Put("servers", Entry{Status: 200}))
+	assert.Nil(c.Put("workspaces", Entry{Status: 200}))
+	removed, err = c.Clear()
+	assert.Nil(err, "Clearing shouldn't fail")
+	assert.Equal(2, removed, "Every entry should be removed")
+	assert.Nil(c.Get("servers"), "Cleared entries shouldn't be returned")
+}
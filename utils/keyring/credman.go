@@ -0,0 +1,111 @@
+// +build windows
+
+package keyring
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	// credMaxBlobSize is the largest secret Credential Manager stores
+	credMaxBlobSize = 5 * 512
+	errorNotFound   = syscall.Errno(1168)
+)
+
+var (
+	advapi32        = syscall.NewLazyDLL("advapi32.dll")
+	procCredWriteW  = advapi32.NewProc("CredWriteW")
+	procCredReadW   = advapi32.NewProc("CredReadW")
+	procCredDeleteW = advapi32.NewProc("CredDeleteW")
+	procCredFree    = advapi32.NewProc("CredFree")
+)
+
+// credential is the CREDENTIALW structure
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// osBackend stores secrets as generic credentials of the Windows Credential Manager
+type osBackend struct{}
+
+func (osBackend) set(account, secret string) error {
+	if len(secret) == 0 || len(secret) > credMaxBlobSize {
+		return fmt.Errorf("Credential Manager only stores secrets from 1 to %d bytes long", credMaxBlobSize)
+	}
+	target, err := syscall.UTF16PtrFromString(targetName(account))
+	if err != nil {
+		return err
+	}
+	user, err := syscall.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		CredentialBlob:     &blob[0],
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if r, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); r == 0 {
+		return fmt.Errorf("Couldn't write to Credential Manager: %s", err)
+	}
+	return nil
+}
+
+func (osBackend) get(account string) (string, error) {
+	target, err := syscall.UTF16PtrFromString(targetName(account))
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	if r, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred))); r == 0 {
+		if err == errorNotFound {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("Couldn't read from Credential Manager: %s", err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	blob := make([]byte, cred.CredentialBlobSize)
+	for i := range blob {
+		blob[i] = *(*byte)(unsafe.Pointer(uintptr(unsafe.Pointer(cred.CredentialBlob)) + uintptr(i)))
+	}
+	return string(blob), nil
+}
+
+func (osBackend) delete(account string) error {
+	target, err := syscall.UTF16PtrFromString(targetName(account))
+	if err != nil {
+		return err
+	}
+	if r, _, err := procCredDeleteW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); r == 0 {
+		if err == errorNotFound {
+			return ErrNotFound
+		}
+		return fmt.Errorf("Couldn't delete from Credential Manager: %s", err)
+	}
+	return nil
+}
+
+// targetName identifies the credential of account among those of other applications
+func targetName(account string) string {
+	return Service + ":" + account
+}
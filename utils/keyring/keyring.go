@@ -0,0 +1,96 @@
+package keyring
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Service names the entries concerto stores in the OS keyring
+const Service = "concerto"
+
+// Prefix marks values that must be read from the OS keyring, such as keyring:aws-prod
+const Prefix = "keyring:"
+
+// ErrNotFound is returned when the keyring has no entry for an account
+var ErrNotFound = errors.New("Secret not found in keyring")
+
+// backend stores secrets in the keyring of the OS concerto is built for
+type backend interface {
+	set(account, secret string) error
+	get(account string) (string, error)
+	delete(account string) error
+}
+
+// provider is replaced in tests
+var provider backend = osBackend{}
+
+// IsReference returns whether value must be read from the OS keyring
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Reference returns the value referencing the secret stored for account
+func Reference(account string) string {
+	return Prefix + account
+}
+
+// Resolve returns the secret referenced by value, in the form keyring:<account>.
+// Values that aren't keyring references are returned as they are.
+func Resolve(value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	account := strings.TrimPrefix(value, Prefix)
+	secret, err := Get(account)
+	if err == ErrNotFound {
+		return "", fmt.Errorf("Keyring has no secret for %s. Please, store it with concerto setup keyring --account %s", account, account)
+	}
+	return secret, err
+}
+
+// Set stores secret for account, replacing any previous one
+func Set(account, secret string) error {
+	if err := validAccount(account); err != nil {
+		return err
+	}
+	return provider.set(account, secret)
+}
+
+// Get returns the secret stored for account, or ErrNotFound
+func Get(account string) (string, error) {
+	if err := validAccount(account); err != nil {
+		return "", err
+	}
+	return provider.get(account)
+}
+
+// Delete removes the secret stored for account, or returns ErrNotFound
+func Delete(account string) error {
+	if err := validAccount(account); err != nil {
+		return err
+	}
+	return provider.delete(account)
+}
+
+func validAccount(account string) error {
+	if account == "" || strings.ContainsAny(account, "\"\n\r") {
+		return fmt.Errorf("Invalid keyring account %q", account)
+	}
+	return nil
+}
+
+// encode turns secrets into printable text for keyrings managed through command line tools,
+// which print binary secrets or multiline ones such as private keys mangled
+func encode(secret string) string {
+	return base64.StdEncoding.EncodeToString([]byte(secret))
+}
+
+func decode(stored string) (string, error) {
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(stored))
+	if err != nil {
+		return "", fmt.Errorf("Keyring secret wasn't stored by concerto: %s", err)
+	}
+	return string(secret), nil
+}
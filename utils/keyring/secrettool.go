@@ -0,0 +1,59 @@
+// +build linux
+
+package keyring
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// osBackend stores secrets in the Secret Service (GNOME Keyring, KWallet) through
+// secret-tool, which comes with libsecret
+type osBackend struct{}
+
+func (osBackend) set(account, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", fmt.Sprintf("%s %s", Service, account), "service", Service, "account", account)
+	cmd.Stdin = strings.NewReader(encode(secret))
+	_, err := secretTool(cmd)
+	return err
+}
+
+func (osBackend) get(account string) (string, error) {
+	out, err := secretTool(exec.Command("secret-tool", "lookup", "service", Service, "account", account))
+	if err != nil {
+		return "", err
+	}
+	// lookup fails without output when there's no matching secret
+	if out == "" {
+		return "", ErrNotFound
+	}
+	return decode(out)
+}
+
+func (b osBackend) delete(account string) error {
+	// clear succeeds when nothing matches, so a missing secret is detected first
+	if _, err := b.get(account); err != nil {
+		return err
+	}
+	_, err := secretTool(exec.Command("secret-tool", "clear", "service", Service, "account", account))
+	return err
+}
+
+// secretTool runs cmd, returning its output
+func secretTool(cmd *exec.Cmd) (string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", fmt.Errorf("Couldn't find secret-tool. Please, install libsecret tools (libsecret-tools or libsecret packages)")
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if _, ok := err.(*exec.ExitError); ok && stderr.Len() == 0 && len(out) == 0 {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("Couldn't access keyring: %s", strings.TrimSpace(stderr.String()+" "+err.Error()))
+	}
+	return string(out), nil
+}
@@ -0,0 +1,57 @@
+// +build darwin
+
+package keyring
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// errSecItemNotFound is the exit status of security when the keychain has no matching item
+const errSecItemNotFound = 44
+
+// osBackend stores secrets in the macOS login keychain through the security tool
+type osBackend struct{}
+
+func (osBackend) set(account, secret string) error {
+	// the secret is sent through stdin in interactive mode, so that it doesn't show in the process list
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a \"%s\" -l \"%s %s\" -X %s\n",
+		Service, account, Service, account, hex.EncodeToString([]byte(encode(secret)))))
+	_, err := security(cmd)
+	return err
+}
+
+func (osBackend) get(account string) (string, error) {
+	out, err := security(exec.Command("security", "find-generic-password", "-s", Service, "-a", account, "-w"))
+	if err != nil {
+		return "", err
+	}
+	return decode(out)
+}
+
+func (osBackend) delete(account string) error {
+	_, err := security(exec.Command("security", "delete-generic-password", "-s", Service, "-a", account))
+	return err
+}
+
+// security runs cmd, returning its output, or ErrNotFound when the keychain has no matching item
+func security(cmd *exec.Cmd) (string, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if exitErr.Sys().(syscall.WaitStatus).ExitStatus() == errSecItemNotFound {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("Couldn't access keychain: %s", strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return "", fmt.Errorf("Couldn't access keychain: %s", err)
+	}
+	return string(out), nil
+}
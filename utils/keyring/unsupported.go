@@ -0,0 +1,27 @@
+// +build !darwin,!linux,!windows
+
+package keyring
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// osBackend fails, as there's no supported keyring on this OS
+type osBackend struct{}
+
+func (osBackend) set(account, secret string) error {
+	return errUnsupported()
+}
+
+func (osBackend) get(account string) (string, error) {
+	return "", errUnsupported()
+}
+
+func (osBackend) delete(account string) error {
+	return errUnsupported()
+}
+
+func errUnsupported() error {
+	return fmt.Errorf("OS keyring isn't supported on %s", runtime.GOOS)
+}
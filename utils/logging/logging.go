@@ -0,0 +1,149 @@
+package logging
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKey matches names of fields or parameters holding credentials
+var sensitiveKey = regexp.MustCompile(`(?i)(password|passwd|secret|token|credentials|private_key|access_key|api_key)`)
+
+// sensitiveValue matches key/value pairs holding credentials, either in JSON or flag/query form
+var sensitiveValue = regexp.MustCompile(`(?i)("?[\w-]*(?:password|passwd|secret|token|credentials|private_key|access_key|api_key)[\w-]*"?\s*[:=]\s*)("(?:[^"\\]|\\.)*"|\{[^}]*\}|[^\s,&]+)`)
+
+// sensitiveHeader matches HTTP headers holding credentials
+var sensitiveHeader = regexp.MustCompile(`(?i)^(authorization|proxy-authorization|cookie|set-cookie)$|token|secret|api-key`)
+
+// privateKey matches PEM encoded private keys
+var privateKey = regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`)
+
+// InitializeLogging configures level and format of the standard logger
+func InitializeLogging(level string, format string) error {
+
+	if err := SetLevel(level); err != nil {
+		return err
+	}
+
+	switch format {
+	case "", "text":
+		log.SetFormatter(&log.TextFormatter{})
+	case "json":
+		log.SetFormatter(&log.JSONFormatter{})
+	default:
+		return fmt.Errorf("Unrecognized log format %s. Please, use one of [ text | json ]", format)
+	}
+
+	log.AddHook(&redactHook{})
+	return nil
+}
+
+// SetLevel sets the level of the standard logger, such as when the configuration gives one
+func SetLevel(level string) error {
+	lvl, err := log.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("Unrecognized log level %s. Please, use one of [ debug | info | warning | error | fatal | panic ]", level)
+	}
+	log.SetLevel(lvl)
+	return nil
+}
+
+// OpenFile makes the standard logger append to file instead of stderr, creating it when missing,
+// so that collectors can ship the logs of agent-mode runs. The file must be closed once done
+func OpenFile(file string) (io.Closer, error) {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	log.SetOutput(f)
+	return f, nil
+}
+
+// SetCommand tags every log entry with the command being executed
+func SetCommand(command string) {
+	if command == "" {
+		return
+	}
+	log.AddHook(&fieldsHook{fields: log.Fields{"command": command}})
+}
+
+// CommandName extracts command and subcommand names from the arguments received
+func CommandName(args []string) string {
+	var names []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		names = append(names, arg)
+	}
+	return strings.Join(names, " ")
+}
+
+// NewRequestID returns a random identifier used to correlate log entries of an API call
+func NewRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%x", b)
+}
+
+// Redact masks credentials contained in s
+func Redact(s string) string {
+	s = privateKey.ReplaceAllString(s, redacted)
+	return sensitiveValue.ReplaceAllString(s, "${1}\""+redacted+"\"")
+}
+
+// RedactHeader masks the value of HTTP header name, or the credentials it contains
+func RedactHeader(name string, value string) string {
+	if sensitiveHeader.MatchString(name) {
+		return redacted
+	}
+	return Redact(value)
+}
+
+// fieldsHook adds a fixed set of fields to every entry
+type fieldsHook struct {
+	fields log.Fields
+}
+
+func (h *fieldsHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *fieldsHook) Fire(entry *log.Entry) error {
+	for k, v := range h.fields {
+		if _, ok := entry.Data[k]; !ok {
+			entry.Data[k] = v
+		}
+	}
+	return nil
+}
+
+// redactHook makes sure credentials never reach the logs
+type redactHook struct{}
+
+func (h *redactHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *redactHook) Fire(entry *log.Entry) error {
+	entry.Message = Redact(entry.Message)
+	for k, v := range entry.Data {
+		if sensitiveKey.MatchString(k) {
+			entry.Data[k] = redacted
+			continue
+		}
+		if s, ok := v.(string); ok {
+			entry.Data[k] = Redact(s)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/flexiant/concerto/api/types"
+)
+
+// Bundle holds a template and the scripts it runs, as exported to a single file
+type Bundle struct {
+	Template TemplateDefinition `json:"template"`
+	Scripts  []ScriptDefinition `json:"scripts,omitempty"`
+}
+
+// NewBundle returns the definitions of a template, its script characterisations of every type, and the
+// scripts they run by ID. Characterisations keep their execution order, and scripts are referenced by name
+func NewBundle(template *types.Template, templateScripts []types.TemplateScript, scripts map[string]*types.Script) (*Bundle, error) {
+	b := &Bundle{
+		Template: TemplateDefinition{
+			Name:                    template.Name,
+			GenericImageID:          template.GenericImgID,
+			ServiceList:             template.ServiceList,
+			ConfigurationAttributes: template.ConfigurationAttributes,
+		},
+	}
+
+	ordered := make([]types.TemplateScript, len(templateScripts))
+	copy(ordered, templateScripts)
+	sort.Stable(byTypeAndOrder(ordered))
+
+	names := make(map[string]string)
+	for _, ts := range ordered {
+		script, ok := scripts[ts.ScriptID]
+		if !ok {
+			return nil, fmt.Errorf("Script %s of template %s not found", ts.ScriptID, template.Name)
+		}
+		if id, ok := names[script.Name]; !ok {
+			names[script.Name] = script.ID
+			b.Scripts = append(b.Scripts, ScriptDefinition{
+				Name:        script.Name,
+				Description: script.Description,
+				Code:        script.Code,
+				Parameters:  script.Parameters,
+			})
+		} else if id != script.ID {
+			return nil, fmt.Errorf("Scripts %s and %s are both named %s. Please, rename one of them so that they can be told apart", id, script.ID, script.Name)
+		}
+		b.Template.Scripts = append(b.Template.Scripts, TemplateScriptDefinition{
+			Type:            ts.Type,
+			Script:          script.Name,
+			ParameterValues: ts.ParameterValues,
+		})
+	}
+	return b, nil
+}
+
+// WriteBundle writes b as indented JSON
+func WriteBundle(w io.Writer, b *Bundle) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// LoadBundle reads a bundle file as a manifest holding its template and scripts. Template scripts
+// must refer to scripts in the bundle
+func LoadBundle(file string) (*Manifest, error) {
+	var b Bundle
+	if err := readJSON(file, &b); err != nil {
+		return nil, err
+	}
+	if b.Template.Name == "" {
+		b.Template.Name = baseName(file)
+	}
+
+	m := &Manifest{Scripts: b.Scripts, Templates: []TemplateDefinition{b.Template}}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	for _, ts := range b.Template.Scripts {
+		if m.Script(ts.Script) == nil {
+			return nil, fmt.Errorf("Script %s of template %s isn't in %s", ts.Script, b.Template.Name, file)
+		}
+	}
+	return m, nil
+}
+
+// byTypeAndOrder sorts script characterisations by type, in the order types are run, and execution order
+type byTypeAndOrder []types.TemplateScript
+
+// scriptTypeOrder holds the position of each type of script characterisations in the order they're run
+var scriptTypeOrder = make(map[string]int)
+
+func init() {
+	for i, t := range types.TemplateScriptTypes {
+		scriptTypeOrder[t] = i
+	}
+}
+
+// scriptTypeNames returns the types of script characterisations, quoted, as they're listed in messages
+func scriptTypeNames() string {
+	quoted := make([]string, len(types.TemplateScriptTypes))
+	for i, t := range types.TemplateScriptTypes {
+		quoted[i] = fmt.Sprintf("%q", t)
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+func (a byTypeAndOrder) Len() int      { return len(a) }
+func (a byTypeAndOrder) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byTypeAndOrder) Less(i, j int) bool {
+	if a[i].Type != a[j].Type {
+		return scriptTypeOrder[a[i].Type] < scriptTypeOrder[a[j].Type]
+	}
+	return a[i].ExecutionOrder < a[j].ExecutionOrder
+}
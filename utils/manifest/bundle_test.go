@@ -0,0 +1,72 @@
+package manifest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBundle(t *testing.T) {
+	assert := assert.New(t)
+
+	template := &types.Template{ID: "t1", Name: "web", GenericImgID: "img", ConfigurationAttributes: rawJSON(`{"a":1}`)}
+	templateScripts := []types.TemplateScript{
+		{ID: "ts3", Type: "shutdown", ScriptID: "s2", ExecutionOrder: 1},
+		{ID: "ts4", Type: "migration", ScriptID: "s2", ExecutionOrder: 1},
+		{ID: "ts2", Type: "boot", ScriptID: "s1", ExecutionOrder: 2, ParameterValues: rawJSON(`{"v":"1"}`)},
+		{ID: "ts1", Type: "boot", ScriptID: "s2", ExecutionOrder: 1},
+	}
+	scripts := map[string]*types.Script{
+		"s1": {ID: "s1", Name: "install", Code: "apt-get install -y nginx", Parameters: []string{"v"}},
+		"s2": {ID: "s2", Name: "notify", Code: "true"},
+	}
+
+	b, err := NewBundle(template, templateScripts, scripts)
+	assert.Nil(err, "Bundle shouldn't fail")
+	assert.Equal("web", b.Template.Name, "Unexpected template name")
+	assert.Equal([]TemplateScriptDefinition{
+		{Type: "boot", Script: "notify"},
+		{Type: "boot", Script: "install", ParameterValues: rawJSON(`{"v":"1"}`)},
+		{Type: "migration", Script: "notify"},
+		{Type: "shutdown", Script: "notify"},
+	}, b.Template.Scripts, "Script characterisations should be sorted by type and execution order")
+	assert.Len(b.Scripts, 2, "Scripts should be bundled once")
+
+	scripts["s2"] = &types.Script{ID: "s2", Name: "install"}
+	_, err = NewBundle(template, templateScripts, scripts)
+	assert.NotNil(err, "Scripts sharing a name should fail")
+}
+
+func TestLoadBundle(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "bundle")
+	assert.Nil(err, "Couldn't create temp dir")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "web.json")
+
+	b := &Bundle{
+		Template: TemplateDefinition{Name: "web", GenericImageID: "img", Scripts: []TemplateScriptDefinition{{Type: "boot", Script: "install"}}},
+		Scripts:  []ScriptDefinition{{Name: "install", Code: "true"}},
+	}
+	var buf bytes.Buffer
+	assert.Nil(WriteBundle(&buf, b), "Couldn't write bundle")
+	assert.Nil(ioutil.WriteFile(file, buf.Bytes(), 0600), "Couldn't write bundle file")
+
+	m, err := LoadBundle(file)
+	assert.Nil(err, "Bundle should load")
+	assert.Equal([]TemplateDefinition{b.Template}, m.Templates, "Unexpected templates")
+	assert.Equal(b.Scripts, m.Scripts, "Unexpected scripts")
+
+	b.Scripts = nil
+	buf.Reset()
+	assert.Nil(WriteBundle(&buf, b), "Couldn't write bundle")
+	assert.Nil(ioutil.WriteFile(file, buf.Bytes(), 0600), "Couldn't write bundle file")
+	_, err = LoadBundle(file)
+	assert.NotNil(err, "Template scripts missing from the bundle should fail")
+}
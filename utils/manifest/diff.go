@@ -0,0 +1,169 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// Difference is a field whose value in a local template definition differs from the live template
+type Difference struct {
+	Field  string `json:"field" header:"FIELD"`
+	Local  string `json:"local" header:"LOCAL"`
+	Remote string `json:"remote" header:"REMOTE"`
+}
+
+// LoadTemplate reads a template definition from file, either written by templates export or
+// as a template of a blueprint directory
+func LoadTemplate(file string) (*TemplateDefinition, error) {
+	b, err := LoadTemplateBundle(file)
+	if err != nil {
+		return nil, err
+	}
+	return &b.Template, nil
+}
+
+// LoadTemplateBundle reads a template definition and the scripts defined along with it from file.
+// Template files of blueprint directories define no scripts
+func LoadTemplateBundle(file string) (*Bundle, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var keys map[string]json.RawMessage
+	if err = json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("Couldn't parse %s: %s", file, err)
+	}
+	var b Bundle
+	if _, ok := keys["template"]; ok {
+		err = json.Unmarshal(data, &b)
+	} else {
+		err = json.Unmarshal(data, &b.Template)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't parse %s: %s", file, err)
+	}
+	if b.Template.Name == "" {
+		b.Template.Name = baseName(file)
+	}
+	return &b, nil
+}
+
+// DiffTemplate returns the fields of local which differ from remote. Service lists are compared
+// regardless of order, configuration attributes key by key, and script characterisations by type,
+// in execution order
+func DiffTemplate(local *TemplateDefinition, remote *TemplateDefinition) []Difference {
+	var diffs []Difference
+	if local.Name != remote.Name {
+		diffs = append(diffs, Difference{Field: "name", Local: local.Name, Remote: remote.Name})
+	}
+	if local.GenericImageID != remote.GenericImageID {
+		diffs = append(diffs, Difference{Field: "generic_image_id", Local: local.GenericImageID, Remote: remote.GenericImageID})
+	}
+	if l, r := sortedCopy(local.ServiceList), sortedCopy(remote.ServiceList); !sameStrings(l, r) {
+		diffs = append(diffs, Difference{Field: "service_list", Local: strings.Join(l, ", "), Remote: strings.Join(r, ", ")})
+	}
+
+	localAttrs := make(map[string]string)
+	flattenJSON("configuration_attributes", decodeJSON(local.ConfigurationAttributes), localAttrs)
+	remoteAttrs := make(map[string]string)
+	flattenJSON("configuration_attributes", decodeJSON(remote.ConfigurationAttributes), remoteAttrs)
+	fields := make(map[string]bool)
+	for field := range localAttrs {
+		fields[field] = true
+	}
+	for field := range remoteAttrs {
+		fields[field] = true
+	}
+	for _, field := range sortedKeys(fields) {
+		if localAttrs[field] != remoteAttrs[field] {
+			diffs = append(diffs, Difference{Field: field, Local: localAttrs[field], Remote: remoteAttrs[field]})
+		}
+	}
+
+	localScripts, remoteScripts := scriptsByType(local.Scripts), scriptsByType(remote.Scripts)
+	types := make(map[string]bool)
+	for t := range localScripts {
+		types[t] = true
+	}
+	for t := range remoteScripts {
+		types[t] = true
+	}
+	for _, t := range scriptTypes(types) {
+		l, r := localScripts[t], remoteScripts[t]
+		if ln, rn := scriptNames(l), scriptNames(r); !sameStrings(ln, rn) {
+			diffs = append(diffs, Difference{Field: fmt.Sprintf("scripts.%s", t), Local: strings.Join(ln, ", "), Remote: strings.Join(rn, ", ")})
+			continue
+		}
+		for i := range l {
+			if !SameJSON(l[i].ParameterValues, r[i].ParameterValues) {
+				diffs = append(diffs, Difference{
+					Field:  fmt.Sprintf("scripts.%s[%d].parameter_values", t, i),
+					Local:  encodeJSON(decodeJSON(l[i].ParameterValues)),
+					Remote: encodeJSON(decodeJSON(r[i].ParameterValues)),
+				})
+			}
+		}
+	}
+	return diffs
+}
+
+// flattenJSON stores the values of v by their dotted path from prefix. Objects are walked into,
+// and any other value is stored as compact JSON. Empty objects store nothing
+func flattenJSON(prefix string, v interface{}, values map[string]string) {
+	if m, ok := v.(map[string]interface{}); ok {
+		for k, child := range m {
+			flattenJSON(prefix+"."+k, child, values)
+		}
+		return
+	}
+	values[prefix] = encodeJSON(v)
+}
+
+// encodeJSON returns v as compact JSON, with object keys sorted
+func encodeJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// scriptsByType groups script characterisations by type, keeping their execution order
+func scriptsByType(scripts []TemplateScriptDefinition) map[string][]TemplateScriptDefinition {
+	byType := make(map[string][]TemplateScriptDefinition)
+	for _, s := range scripts {
+		byType[s.Type] = append(byType[s.Type], s)
+	}
+	return byType
+}
+
+// scriptTypes returns the types of a set in the order they're run, and unknown ones last
+func scriptTypes(set map[string]bool) []string {
+	types := sortedKeys(set)
+	sort.SliceStable(types, func(i, j int) bool {
+		oi, ki := scriptTypeOrder[types[i]]
+		oj, kj := scriptTypeOrder[types[j]]
+		if ki != kj {
+			return ki
+		}
+		return oi < oj
+	})
+	return types
+}
+
+func scriptNames(scripts []TemplateScriptDefinition) []string {
+	names := make([]string, len(scripts))
+	for i, s := range scripts {
+		names[i] = s.Script
+	}
+	return names
+}
+
+func sortedCopy(list []string) []string {
+	sorted := append([]string{}, list...)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,90 @@
+package manifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	remote := &TemplateDefinition{
+		Name:                    "web",
+		GenericImageID:          "img",
+		ServiceList:             []string{"nginx", "ntp"},
+		ConfigurationAttributes: rawJSON(`{"nginx":{"port":80,"workers":2},"ntp":{"server":"pool"}}`),
+		Scripts: []TemplateScriptDefinition{
+			{Type: "boot", Script: "install", ParameterValues: rawJSON(`{"v":"1"}`)},
+			{Type: "boot", Script: "notify"},
+			{Type: "shutdown", Script: "notify"},
+		},
+	}
+	same := &TemplateDefinition{
+		Name:                    "web",
+		GenericImageID:          "img",
+		ServiceList:             []string{"ntp", "nginx"},
+		ConfigurationAttributes: rawJSON(`{"ntp": {"server": "pool"}, "nginx": {"workers": 2, "port": 80}}`),
+		Scripts: []TemplateScriptDefinition{
+			{Type: "shutdown", Script: "notify", ParameterValues: rawJSON(`{}`)},
+			{Type: "boot", Script: "install", ParameterValues: rawJSON(`{"v":"1"}`)},
+			{Type: "boot", Script: "notify"},
+		},
+	}
+	assert.Empty(DiffTemplate(same, remote), "Formatting and service order shouldn't be differences")
+
+	local := &TemplateDefinition{
+		Name:                    "web",
+		GenericImageID:          "img2",
+		ServiceList:             []string{"nginx"},
+		ConfigurationAttributes: rawJSON(`{"nginx":{"port":8080,"workers":2},"ntp":{"server":"pool"},"debug":true}`),
+		Scripts: []TemplateScriptDefinition{
+			{Type: "boot", Script: "install", ParameterValues: rawJSON(`{"v":"2"}`)},
+			{Type: "boot", Script: "notify"},
+			{Type: "operational", Script: "notify"},
+		},
+	}
+	assert.Equal([]Difference{
+		{Field: "generic_image_id", Local: "img2", Remote: "img"},
+		{Field: "service_list", Local: "nginx", Remote: "nginx, ntp"},
+		{Field: "configuration_attributes.debug", Local: "true", Remote: ""},
+		{Field: "configuration_attributes.nginx.port", Local: "8080", Remote: "80"},
+		{Field: "scripts.boot[0].parameter_values", Local: `{"v":"2"}`, Remote: `{"v":"1"}`},
+		{Field: "scripts.operational", Local: "notify", Remote: ""},
+		{Field: "scripts.shutdown", Local: "", Remote: "notify"},
+	}, DiffTemplate(local, remote), "Unexpected differences")
+
+	reordered := &TemplateDefinition{Name: "web", GenericImageID: "img", ServiceList: remote.ServiceList, ConfigurationAttributes: remote.ConfigurationAttributes,
+		Scripts: []TemplateScriptDefinition{remote.Scripts[1], remote.Scripts[0], remote.Scripts[2]}}
+	assert.Equal([]Difference{{Field: "scripts.boot", Local: "notify, install", Remote: "install, notify"}},
+		DiffTemplate(reordered, remote), "Script order should be a difference")
+}
+
+func TestLoadTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "concerto")
+	assert.Nil(err, "Temporary directory couldn't be created")
+	defer os.RemoveAll(dir)
+
+	bundle := filepath.Join(dir, "exported.json")
+	ioutil.WriteFile(bundle, []byte(`{"template":{"name":"web","generic_image_id":"img"},"scripts":[{"name":"install"}]}`), 0600)
+	tpl, err := LoadTemplate(bundle)
+	assert.Nil(err, "Exported templates should load")
+	assert.Equal("web", tpl.Name, "Unexpected template name")
+	assert.Equal("img", tpl.GenericImageID, "Unexpected image")
+
+	definition := filepath.Join(dir, "db.json")
+	ioutil.WriteFile(definition, []byte(`{"generic_image_id":"img","service_list":["mysql"]}`), 0600)
+	tpl, err = LoadTemplate(definition)
+	assert.Nil(err, "Template definitions should load")
+	assert.Equal("db", tpl.Name, "Templates should be named after their file")
+	assert.Equal([]string{"mysql"}, tpl.ServiceList, "Unexpected services")
+
+	ioutil.WriteFile(definition, []byte(`[]`), 0600)
+	_, err = LoadTemplate(definition)
+	assert.NotNil(err, "Files not holding an object should fail")
+}
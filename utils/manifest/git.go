@@ -0,0 +1,56 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Checkout leaves in dir the latest commit of branch in the git repository at url.
+// The repository is cloned the first time, and fetched afterwards
+func Checkout(url string, branch string, dir string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("Couldn't find git in your environment. Please, install it")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if err = os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+			return err
+		}
+		log.Debugf("Cloning %s into %s", url, dir)
+		return git("", "clone", "--quiet", "--depth", "1", "--branch", branch, url, dir)
+	}
+
+	log.Debugf("Fetching %s into %s", url, dir)
+	if err := git(dir, "fetch", "--quiet", "--depth", "1", url, branch); err != nil {
+		return err
+	}
+	return git(dir, "reset", "--quiet", "--hard", "FETCH_HEAD")
+}
+
+// Revision returns the commit checked out in dir
+func Revision(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func git(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %s %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ScriptDefinition describes a blueprint script
+type ScriptDefinition struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Code        string   `json:"code,omitempty"`
+	CodeFile    string   `json:"code_file,omitempty"`
+	Parameters  []string `json:"parameters,omitempty"`
+}
+
+// TemplateDefinition describes a blueprint template and its scripts
+type TemplateDefinition struct {
+	Name                    string                     `json:"name"`
+	GenericImageID          string                     `json:"generic_image_id"`
+	ServiceList             []string                   `json:"service_list,omitempty"`
+	ConfigurationAttributes *json.RawMessage           `json:"configuration_attributes,omitempty"`
+	Scripts                 []TemplateScriptDefinition `json:"scripts,omitempty"`
+}
+
+// TemplateScriptDefinition describes a script characterisation. Scripts are run in the order they're listed
+type TemplateScriptDefinition struct {
+	Type            string           `json:"type"`
+	Script          string           `json:"script"`
+	ParameterValues *json.RawMessage `json:"parameter_values,omitempty"`
+}
+
+// Manifest stores the blueprint definitions of a repository
+type Manifest struct {
+	Scripts   []ScriptDefinition
+	Templates []TemplateDefinition
+}
+
+// TemplateScriptsFile is the file of a directory listing the script characterisations of a template
+const TemplateScriptsFile = "template_scripts.json"
+
+// LoadDir reads definitions from the scripts and templates subdirectories of dir.
+// Each JSON file holds a definition, named after the file unless a name is given
+func LoadDir(dir string) (*Manifest, error) {
+	scripts, err := loadScripts(dir)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{Scripts: scripts}
+
+	templateFiles, err := filepath.Glob(filepath.Join(dir, "templates", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range templateFiles {
+		var t TemplateDefinition
+		if err = readJSON(file, &t); err != nil {
+			return nil, err
+		}
+		if t.Name == "" {
+			t.Name = baseName(file)
+		}
+		m.Templates = append(m.Templates, t)
+	}
+
+	return m, m.Validate()
+}
+
+// LoadTemplateScripts reads the script characterisations of template name, in execution order, from
+// the scripts key of the template_scripts.json file in dir, and the scripts defined in its scripts
+// subdirectory as LoadDir does. Characterisations may also use scripts not defined in dir
+func LoadTemplateScripts(dir string, name string) (*Manifest, error) {
+	var t TemplateDefinition
+	if err := readJSON(filepath.Join(dir, TemplateScriptsFile), &t); err != nil {
+		return nil, err
+	}
+	scripts, err := loadScripts(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{Scripts: scripts, Templates: []TemplateDefinition{{Name: name, Scripts: t.Scripts}}}
+	if err = m.validateScripts(); err != nil {
+		return nil, err
+	}
+	return m, m.Templates[0].validateScripts()
+}
+
+// loadScripts reads the script definitions in the scripts subdirectory of dir, with the code of
+// their code_file, relative to the definition
+func loadScripts(dir string) ([]ScriptDefinition, error) {
+	scriptFiles, err := filepath.Glob(filepath.Join(dir, "scripts", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var scripts []ScriptDefinition
+	for _, file := range scriptFiles {
+		var s ScriptDefinition
+		if err = readJSON(file, &s); err != nil {
+			return nil, err
+		}
+		if s.Name == "" {
+			s.Name = baseName(file)
+		}
+		if err = s.readCode(filepath.Dir(file)); err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, s)
+	}
+	return scripts, nil
+}
+
+// readCode sets the code of the script to the content of its code_file, relative to dir
+func (s *ScriptDefinition) readCode(dir string) error {
+	if s.CodeFile == "" {
+		return nil
+	}
+	code, err := ioutil.ReadFile(filepath.Join(dir, s.CodeFile))
+	if err != nil {
+		return fmt.Errorf("Couldn't read code of script %s: %s", s.Name, err)
+	}
+	s.Code = string(code)
+	s.CodeFile = ""
+	return nil
+}
+
+// Validate checks that names are unique and template scripts are well formed
+func (m *Manifest) Validate() error {
+	if err := m.validateScripts(); err != nil {
+		return err
+	}
+
+	templates := make(map[string]bool)
+	for _, t := range m.Templates {
+		if templates[t.Name] {
+			return fmt.Errorf("Template %s is defined more than once", t.Name)
+		}
+		templates[t.Name] = true
+		if t.GenericImageID == "" {
+			return fmt.Errorf("Template %s has no generic_image_id", t.Name)
+		}
+		if err := t.validateScripts(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateScripts checks that script names are unique
+func (m *Manifest) validateScripts() error {
+	scripts := make(map[string]bool)
+	for _, s := range m.Scripts {
+		if scripts[s.Name] {
+			return fmt.Errorf("Script %s is defined more than once", s.Name)
+		}
+		scripts[s.Name] = true
+	}
+	return nil
+}
+
+// validateScripts checks that script characterisations have a valid type and a script
+func (t *TemplateDefinition) validateScripts() error {
+	for _, ts := range t.Scripts {
+		if _, ok := scriptTypeOrder[ts.Type]; !ok {
+			return fmt.Errorf("Script %s of template %s has invalid type %q. Must be %s", ts.Script, t.Name, ts.Type, scriptTypeNames())
+		}
+		if ts.Script == "" {
+			return fmt.Errorf("Template %s has a %s script without name", t.Name, ts.Type)
+		}
+	}
+	return nil
+}
+
+// Script returns the script definition with the given name
+func (m *Manifest) Script(name string) *ScriptDefinition {
+	for i := range m.Scripts {
+		if m.Scripts[i].Name == name {
+			return &m.Scripts[i]
+		}
+	}
+	return nil
+}
+
+// Template returns the template definition with the given name
+func (m *Manifest) Template(name string) *TemplateDefinition {
+	for i := range m.Templates {
+		if m.Templates[i].Name == name {
+			return &m.Templates[i]
+		}
+	}
+	return nil
+}
+
+// Params returns the script as expected by the API
+func (s *ScriptDefinition) Params() *map[string]interface{} {
+	params := map[string]interface{}{
+		"name":        s.Name,
+		"description": s.Description,
+		"code":        s.Code,
+		"parameters":  s.Parameters,
+	}
+	if s.Parameters == nil {
+		params["parameters"] = []string{}
+	}
+	return &params
+}
+
+// Params returns the template as expected by the API
+func (t *TemplateDefinition) Params() *map[string]interface{} {
+	params := map[string]interface{}{
+		"name":             t.Name,
+		"generic_image_id": t.GenericImageID,
+	}
+	if t.ServiceList != nil {
+		params["service_list"] = t.ServiceList
+	}
+	if t.ConfigurationAttributes != nil {
+		params["configuration_attributes"] = t.ConfigurationAttributes
+	}
+	return &params
+}
+
+// SameJSON returns whether two JSON documents hold the same values, regardless of formatting.
+// Missing documents are the same as empty objects
+func SameJSON(a *json.RawMessage, b *json.RawMessage) bool {
+	return reflect.DeepEqual(decodeJSON(a), decodeJSON(b))
+}
+
+func decodeJSON(raw *json.RawMessage) interface{} {
+	var v interface{}
+	if raw == nil || json.Unmarshal(*raw, &v) != nil || v == nil {
+		return map[string]interface{}{}
+	}
+	return v
+}
+
+func readJSON(file string, v interface{}) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("Couldn't parse %s: %s", file, err)
+	}
+	return nil
+}
+
+func baseName(file string) string {
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
+
+// sameStrings returns whether two lists hold the same strings in the same order
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
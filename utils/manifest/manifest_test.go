@@ -0,0 +1,48 @@
+package manifest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTemplateScripts(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "template_scripts")
+	assert.Nil(err, "Couldn't create temp dir")
+	defer os.RemoveAll(dir)
+	assert.Nil(os.Mkdir(filepath.Join(dir, "scripts"), 0700), "Couldn't create scripts dir")
+	files := map[string]string{
+		TemplateScriptsFile: `{"scripts": [
+			{"type": "boot", "script": "install", "parameter_values": {"version": "1.10"}},
+			{"type": "operational", "script": "chef-client"}
+		]}`,
+		"scripts/install.json": `{"description": "Installs nginx", "code_file": "install.sh", "parameters": ["version"]}`,
+		"scripts/install.sh":   "apt-get install -y nginx=$version\n",
+	}
+	for name, content := range files {
+		assert.Nil(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600), "Couldn't write %s", name)
+	}
+
+	m, err := LoadTemplateScripts(dir, "web")
+	assert.Nil(err, "Template scripts should load")
+	values := json.RawMessage(`{"version": "1.10"}`)
+	assert.Equal([]TemplateDefinition{{Name: "web", Scripts: []TemplateScriptDefinition{
+		{Type: "boot", Script: "install", ParameterValues: &values},
+		{Type: "operational", Script: "chef-client"},
+	}}}, m.Templates, "Unexpected template scripts")
+	assert.Equal([]ScriptDefinition{{Name: "install", Description: "Installs nginx", Code: "apt-get install -y nginx=$version\n", Parameters: []string{"version"}}}, m.Scripts, "Unexpected scripts")
+
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, TemplateScriptsFile), []byte(`{"scripts": [{"type": "startup", "script": "install"}]}`), 0600), "Couldn't write template scripts")
+	_, err = LoadTemplateScripts(dir, "web")
+	assert.NotNil(err, "Invalid types should fail")
+
+	assert.Nil(os.Remove(filepath.Join(dir, TemplateScriptsFile)), "Couldn't remove template scripts")
+	_, err = LoadTemplateScripts(dir, "web")
+	assert.NotNil(err, "Directories without template scripts should fail")
+}
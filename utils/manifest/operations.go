@@ -0,0 +1,275 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/flexiant/concerto/utils/alias"
+)
+
+const (
+	// OnErrorAbort stops the run when a step fails
+	OnErrorAbort = "abort"
+	// OnErrorContinue goes on with the next step when a step fails
+	OnErrorContinue = "continue"
+)
+
+// variable matches ${name} references in operation command lines
+var variable = regexp.MustCompile(`\$\{([A-Za-z_][\w.-]*)\}`)
+
+// Operations is a list of CLI command lines run in order, and the variables they start with
+type Operations struct {
+	Vars  map[string]string
+	Steps []Operation
+}
+
+// Operation is a step of an operations file. Its command line may reference variables as ${name},
+// and values of its JSON output can be captured into variables for later steps
+type Operation struct {
+	Line    int
+	Name    string
+	Run     string
+	Capture []Capture
+	OnError string
+}
+
+// Capture stores a value of the output, given as a dot separated path such as id or 0.name,
+// into a variable
+type Capture struct {
+	Variable string
+	Path     string
+}
+
+// LoadOperations reads an operations file such as
+//
+//	vars:
+//	  workspace: 5aa2fd3bb6b9f6000a34c5a0
+//	steps:
+//	  - name: template
+//	    run: blueprint templates create --name web --generic_image_id 5630ed8fa6f9db6b84000001
+//	    capture:
+//	      template_id: id
+//	  - run: cloud servers create --name web1 --template_id ${template_id} --workspace_id ${workspace} ...
+//	    on_error: continue
+func LoadOperations(file string) (*Operations, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ops, err := readOperations(f)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't parse %s: %s", file, err)
+	}
+	if err = ops.validate(); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// readOperations reads operations files: a mapping of variables, and a list of steps whose capture key holds
+// a mapping of variables to output paths
+func readOperations(r io.Reader) (*Operations, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	root, err := decodeYAML(string(data))
+	if err != nil {
+		return nil, err
+	}
+	ops := &Operations{Vars: make(map[string]string)}
+	if yamlNull(root) {
+		return ops, nil
+	}
+	if root.Kind != yamlMapping {
+		return nil, fmt.Errorf("line %d: expected vars: or steps:", root.Line)
+	}
+
+	for _, section := range root.Keys {
+		node := root.Values[section]
+		switch {
+		case section != "vars" && section != "steps":
+			return nil, fmt.Errorf("line %d: expected vars: or steps:", node.Line)
+		case yamlNull(node):
+		case section == "vars":
+			if node.Kind != yamlMapping {
+				return nil, fmt.Errorf("line %d: vars must be a mapping of values", node.Line)
+			}
+			for _, name := range node.Keys {
+				if ops.Vars[name], err = yamlValue(node.Values[name], name); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			if node.Kind != yamlSequence {
+				return nil, fmt.Errorf("line %d: expected a list of steps", node.Line)
+			}
+			for _, item := range node.Items {
+				step, err := readOperation(item)
+				if err != nil {
+					return nil, err
+				}
+				ops.Steps = append(ops.Steps, *step)
+			}
+		}
+	}
+	return ops, nil
+}
+
+// readOperation reads a step of an operations file
+func readOperation(item *yamlNode) (*Operation, error) {
+	if item.Kind != yamlMapping {
+		return nil, fmt.Errorf("line %d: expected a list of steps", item.Line)
+	}
+	op := &Operation{Line: item.Line}
+	for _, key := range item.Keys {
+		v := item.Values[key]
+		var err error
+		switch key {
+		case "name":
+			op.Name, err = yamlValue(v, key)
+		case "run":
+			op.Run, err = yamlValue(v, key)
+		case "on_error":
+			op.OnError, err = yamlValue(v, key)
+		case "capture":
+			if v.Kind != yamlMapping {
+				return nil, fmt.Errorf("line %d: capture must be a mapping of variables to output paths", v.Line)
+			}
+			for _, name := range v.Keys {
+				path, err := yamlValue(v.Values[name], name)
+				if err != nil {
+					return nil, err
+				}
+				op.Capture = append(op.Capture, Capture{Variable: name, Path: path})
+			}
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %s", v.Line, key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return op, nil
+}
+
+// yamlValue returns the value of key, which must be a scalar
+func yamlValue(n *yamlNode, key string) (string, error) {
+	if n.Kind != yamlScalar {
+		return "", fmt.Errorf("line %d: %s must be a single value", n.Line, key)
+	}
+	return yamlString(n), nil
+}
+
+// validate checks every step, reporting all problems at once
+func (ops *Operations) validate() error {
+	var problems []string
+	for i := range ops.Steps {
+		s := &ops.Steps[i]
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("step %d", i+1)
+		}
+		if s.OnError == "" {
+			s.OnError = OnErrorAbort
+		}
+		if s.Run == "" {
+			problems = append(problems, fmt.Sprintf("line %d: missing run", s.Line))
+		} else if _, err := alias.Split(s.Run); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: invalid run: %s", s.Line, err))
+		}
+		if s.OnError != OnErrorAbort && s.OnError != OnErrorContinue {
+			problems = append(problems, fmt.Sprintf("line %d: on_error must be %s or %s", s.Line, OnErrorAbort, OnErrorContinue))
+		}
+		for _, c := range s.Capture {
+			if c.Path == "" {
+				problems = append(problems, fmt.Sprintf("line %d: missing output path of %s", s.Line, c.Variable))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("Invalid operations:\n\t%s", strings.Join(problems, "\n\t"))
+	}
+	if len(ops.Steps) == 0 {
+		return fmt.Errorf("No steps defined")
+	}
+	return nil
+}
+
+// Args returns the command line of the operation, with variables replaced by their values.
+// Variables not in vars are taken from the environment
+func (o *Operation) Args(vars map[string]string) ([]string, error) {
+	words, err := alias.Split(o.Run)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for i, w := range words {
+		words[i] = variable.ReplaceAllStringFunc(w, func(ref string) string {
+			name := variable.FindStringSubmatch(ref)[1]
+			if value, ok := vars[name]; ok {
+				return value
+			}
+			if value, ok := os.LookupEnv(name); ok {
+				return value
+			}
+			missing = append(missing, name)
+			return ref
+		})
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("Undefined variables %s", strings.Join(missing, ", "))
+	}
+	return words, nil
+}
+
+// CaptureValue returns the value at path of a JSON output. Path elements are keys of objects,
+// or indexes of arrays
+func CaptureValue(output []byte, path string) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("Output isn't JSON: %s", err)
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if value, ok = v[key]; !ok {
+				return "", fmt.Errorf("Output has no %s", path)
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return "", fmt.Errorf("Output has no %s", path)
+			}
+			value = v[i]
+		default:
+			return "", fmt.Errorf("Output has no %s", path)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "", nil
+	}
+	data, err := json.Marshal(value)
+	return string(data), err
+}
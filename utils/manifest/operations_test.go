@@ -0,0 +1,89 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadOperations(t *testing.T) {
+	assert := assert.New(t)
+	file := writeServerManifest(t, "ops.yml", `# deploy web
+vars:
+  workspace: 5aa2fd3bb6b9f6000a34c5a0
+  image: "5630ed8fa6f9db6b84000001"
+steps:
+  - name: template
+    run: blueprint templates create --name web --generic_image_id ${image}
+    capture:
+      template_id: id
+      template_name: name
+  - run: cloud servers create --name "web 1" --template_id ${template_id} --workspace_id ${workspace}
+    on_error: continue
+`)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	ops, err := LoadOperations(file)
+	assert.Nil(err, "Couldn't load operations")
+	assert.Equal(map[string]string{"workspace": "5aa2fd3bb6b9f6000a34c5a0", "image": "5630ed8fa6f9db6b84000001"}, ops.Vars, "Unexpected variables")
+	assert.Len(ops.Steps, 2, "Unexpected number of steps")
+
+	assert.Equal("template", ops.Steps[0].Name, "Unexpected name")
+	assert.Equal(OnErrorAbort, ops.Steps[0].OnError, "Steps should abort by default")
+	assert.Equal([]Capture{{"template_id", "id"}, {"template_name", "name"}}, ops.Steps[0].Capture, "Unexpected captures")
+	assert.Equal("step 2", ops.Steps[1].Name, "Steps should be named after their position")
+	assert.Equal(OnErrorContinue, ops.Steps[1].OnError, "Unexpected error policy")
+	assert.Equal(11, ops.Steps[1].Line, "Unexpected line")
+
+	vars := map[string]string{"workspace": "ws1", "template_id": "t1"}
+	args, err := ops.Steps[1].Args(vars)
+	assert.Nil(err, "Couldn't expand variables")
+	assert.Equal([]string{"cloud", "servers", "create", "--name", "web 1", "--template_id", "t1", "--workspace_id", "ws1"}, args, "Unexpected arguments")
+
+	_, err = ops.Steps[0].Args(map[string]string{})
+	assert.NotNil(err, "Undefined variables should fail")
+}
+
+func TestLoadOperationsInvalid(t *testing.T) {
+	file := writeServerManifest(t, "ops.yml", `steps:
+  - name: nothing
+  - run: cloud servers list
+    on_error: retry
+`)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	_, err := LoadOperations(file)
+	assert.NotNil(t, err, "Invalid operations should fail")
+	assert.Contains(t, err.Error(), "line 2: missing run", "Missing run should be reported")
+	assert.Contains(t, err.Error(), "line 3: on_error must be abort or continue", "Invalid policies should be reported")
+
+	file = writeServerManifest(t, "ops.yml", "steps:\n  - run: cloud servers list\n    retries: 3\n")
+	defer os.RemoveAll(filepath.Dir(file))
+	_, err = LoadOperations(file)
+	assert.NotNil(t, err, "Unknown keys should fail")
+
+	file = writeServerManifest(t, "ops.yml", "steps:\n  - run: cloud servers list\n    capture: id\n")
+	defer os.RemoveAll(filepath.Dir(file))
+	_, err = LoadOperations(file)
+	assert.NotNil(t, err, "Captures should be mappings")
+	assert.Contains(t, err.Error(), "line 3: capture must be a mapping", "Invalid captures should be located")
+}
+
+func TestCaptureValue(t *testing.T) {
+	assert := assert.New(t)
+	output := []byte(`[{"id":"5aa2","name":"web","cpus":2,"labels":["a"]},{"id":"5aa3","name":"db"}]`)
+
+	for path, expected := range map[string]string{"0.id": "5aa2", "1.name": "db", "0.cpus": "2", "0.labels": `["a"]`} {
+		value, err := CaptureValue(output, path)
+		assert.Nil(err, "Couldn't capture %s", path)
+		assert.Equal(expected, value, "Unexpected value of %s", path)
+	}
+	for _, path := range []string{"2.id", "0.missing", "id", "0.id.x"} {
+		_, err := CaptureValue(output, path)
+		assert.NotNil(err, "Missing path %s should fail", path)
+	}
+	_, err := CaptureValue([]byte("Server created"), "id")
+	assert.NotNil(err, "Non JSON outputs should fail")
+}
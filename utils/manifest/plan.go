@@ -0,0 +1,203 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/flexiant/concerto/api/types"
+)
+
+// plan actions
+const (
+	Create  = "create"
+	Update  = "update"
+	Delete  = "delete"
+	Reorder = "reorder"
+)
+
+// kinds of planned changes
+const (
+	KindScript         = "script"
+	KindTemplate       = "template"
+	KindTemplateScript = "template_script"
+)
+
+// Change is an action needed for the live state to match the manifest
+type Change struct {
+	Action string `json:"action" header:"ACTION"`
+	Kind   string `json:"kind" header:"KIND"`
+	Name   string `json:"name" header:"NAME"`
+	ID     string `json:"id" header:"ID"`
+}
+
+// PlanScripts returns the changes needed for scripts to match the manifest.
+// Scripts not in the manifest are only deleted when pruning
+func PlanScripts(m *Manifest, current []types.Script, prune bool) []Change {
+	byName := make(map[string]types.Script)
+	for _, s := range current {
+		byName[s.Name] = s
+	}
+
+	var changes []Change
+	for _, def := range m.Scripts {
+		s, ok := byName[def.Name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Action: Create, Kind: KindScript, Name: def.Name})
+		case s.Description != def.Description || s.Code != def.Code || !sameStrings(s.Parameters, def.Parameters):
+			changes = append(changes, Change{Action: Update, Kind: KindScript, Name: def.Name, ID: s.ID})
+		}
+	}
+	if prune {
+		for _, s := range current {
+			if m.Script(s.Name) == nil {
+				changes = append(changes, Change{Action: Delete, Kind: KindScript, Name: s.Name, ID: s.ID})
+			}
+		}
+	}
+	return changes
+}
+
+// PlanTemplates returns the changes needed for templates to match the manifest, not including their scripts.
+// Templates not in the manifest are only deleted when pruning
+func PlanTemplates(m *Manifest, current []types.Template, prune bool) []Change {
+	byName := make(map[string]types.Template)
+	for _, t := range current {
+		byName[t.Name] = t
+	}
+
+	var changes []Change
+	for _, def := range m.Templates {
+		t, ok := byName[def.Name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Action: Create, Kind: KindTemplate, Name: def.Name})
+		case t.GenericImgID != def.GenericImageID || !sameStrings(t.ServiceList, def.ServiceList) || !SameJSON(t.ConfigurationAttributes, def.ConfigurationAttributes):
+			changes = append(changes, Change{Action: Update, Kind: KindTemplate, Name: def.Name, ID: t.ID})
+		}
+	}
+	if prune {
+		for _, t := range current {
+			if m.Template(t.Name) == nil {
+				changes = append(changes, Change{Action: Delete, Kind: KindTemplate, Name: t.Name, ID: t.ID})
+			}
+		}
+	}
+	return changes
+}
+
+// TemplateScriptSlot is a script characterisation as it must end up, in execution order
+type TemplateScriptSlot struct {
+	Type            string
+	Script          string
+	ScriptID        string
+	ParameterValues *json.RawMessage
+
+	// ID of the existing characterisation kept, empty when it has to be created
+	ID     string
+	Action string
+}
+
+// TemplateScriptsPlan stores the changes needed for the scripts of a template to match its definition
+type TemplateScriptsPlan struct {
+	Template string
+	Slots    []TemplateScriptSlot
+	Deletes  []types.TemplateScript
+
+	// Reorders lists the script types whose execution order has to be set once slots are applied
+	Reorders []string
+}
+
+// PlanTemplateScripts matches the script characterisations of a template against its definition.
+// scriptIDs maps script names to IDs; scripts still to be created map to an empty ID
+func PlanTemplateScripts(def *TemplateDefinition, current []types.TemplateScript, scriptIDs map[string]string) (*TemplateScriptsPlan, error) {
+	plan := &TemplateScriptsPlan{Template: def.Name}
+
+	sorted := make([]types.TemplateScript, len(current))
+	copy(sorted, current)
+	sort.Sort(byExecutionOrder(sorted))
+
+	used := make(map[string]bool)
+	kept := make(map[string][]string)
+	tailCreate := make(map[string]bool)
+	reorder := make(map[string]bool)
+	for _, ts := range def.Scripts {
+		scriptID, ok := scriptIDs[ts.Script]
+		if !ok {
+			return nil, fmt.Errorf("Template %s uses script %s, which doesn't exist", def.Name, ts.Script)
+		}
+		slot := TemplateScriptSlot{Type: ts.Type, Script: ts.Script, ScriptID: scriptID, ParameterValues: ts.ParameterValues, Action: Create}
+		for _, cur := range sorted {
+			if scriptID != "" && !used[cur.ID] && cur.Type == ts.Type && cur.ScriptID == scriptID {
+				used[cur.ID] = true
+				slot.ID = cur.ID
+				slot.Action = ""
+				if !SameJSON(cur.ParameterValues, ts.ParameterValues) {
+					slot.Action = Update
+				}
+				break
+			}
+		}
+		// new characterisations are appended, so kept ones after a new one need reordering
+		if slot.ID == "" {
+			tailCreate[ts.Type] = true
+		} else {
+			if tailCreate[ts.Type] {
+				reorder[ts.Type] = true
+			}
+			kept[ts.Type] = append(kept[ts.Type], slot.ID)
+		}
+		plan.Slots = append(plan.Slots, slot)
+	}
+
+	remaining := make(map[string][]string)
+	for _, cur := range sorted {
+		if used[cur.ID] {
+			remaining[cur.Type] = append(remaining[cur.Type], cur.ID)
+		} else {
+			plan.Deletes = append(plan.Deletes, cur)
+		}
+	}
+	for t, ids := range kept {
+		if !sameStrings(ids, remaining[t]) {
+			reorder[t] = true
+		}
+	}
+	plan.Reorders = sortedKeys(reorder)
+	return plan, nil
+}
+
+// Changes lists the changes in the plan
+func (p *TemplateScriptsPlan) Changes() []Change {
+	var changes []Change
+	for _, s := range p.Slots {
+		if s.Action != "" {
+			changes = append(changes, Change{Action: s.Action, Kind: KindTemplateScript, Name: fmt.Sprintf("%s/%s/%s", p.Template, s.Type, s.Script), ID: s.ID})
+		}
+	}
+	for _, d := range p.Deletes {
+		changes = append(changes, Change{Action: Delete, Kind: KindTemplateScript, Name: fmt.Sprintf("%s/%s/%s", p.Template, d.Type, d.ScriptID), ID: d.ID})
+	}
+	for _, t := range p.Reorders {
+		changes = append(changes, Change{Action: Reorder, Kind: KindTemplateScript, Name: fmt.Sprintf("%s/%s", p.Template, t)})
+	}
+	return changes
+}
+
+// OrderedIDs returns the IDs of the characterisations of a type in execution order. Slots must have been applied
+func (p *TemplateScriptsPlan) OrderedIDs(scriptType string) []string {
+	var ids []string
+	for _, s := range p.Slots {
+		if s.Type == scriptType {
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids
+}
+
+type byExecutionOrder []types.TemplateScript
+
+func (a byExecutionOrder) Len() int           { return len(a) }
+func (a byExecutionOrder) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byExecutionOrder) Less(i, j int) bool { return a[i].ExecutionOrder < a[j].ExecutionOrder }
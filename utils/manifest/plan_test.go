@@ -0,0 +1,77 @@
+package manifest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func rawJSON(s string) *json.RawMessage {
+	raw := json.RawMessage(s)
+	return &raw
+}
+
+func TestPlanScripts(t *testing.T) {
+	m := &Manifest{Scripts: []ScriptDefinition{
+		{Name: "install", Description: "Installs", Code: "apt-get install -y nginx"},
+		{Name: "new", Description: "New", Code: "true"},
+		{Name: "same", Description: "Same", Code: "true", Parameters: []string{"a"}},
+	}}
+	current := []types.Script{
+		{ID: "1", Name: "install", Description: "Installs", Code: "yum install -y nginx"},
+		{ID: "2", Name: "same", Description: "Same", Code: "true", Parameters: []string{"a"}},
+		{ID: "3", Name: "stale"},
+	}
+
+	assert.Equal(t, []Change{
+		{Action: Update, Kind: KindScript, Name: "install", ID: "1"},
+		{Action: Create, Kind: KindScript, Name: "new"},
+	}, PlanScripts(m, current, false), "Unexpected plan")
+
+	changes := PlanScripts(m, current, true)
+	assert.Equal(t, Change{Action: Delete, Kind: KindScript, Name: "stale", ID: "3"}, changes[len(changes)-1], "Stale scripts should be pruned")
+}
+
+func TestPlanTemplates(t *testing.T) {
+	m := &Manifest{Templates: []TemplateDefinition{
+		{Name: "web", GenericImageID: "img", ConfigurationAttributes: rawJSON(`{"a": 1}`)},
+	}}
+	current := []types.Template{{ID: "t1", Name: "web", GenericImgID: "img", ConfigurationAttributes: rawJSON(`{"a":1}`)}}
+	assert.Empty(t, PlanTemplates(m, current, false), "Equivalent attributes shouldn't be updated")
+
+	current[0].ConfigurationAttributes = rawJSON(`{"a":2}`)
+	assert.Equal(t, []Change{{Action: Update, Kind: KindTemplate, Name: "web", ID: "t1"}}, PlanTemplates(m, current, false), "Changed attributes should be updated")
+}
+
+func TestPlanTemplateScripts(t *testing.T) {
+	assert := assert.New(t)
+
+	def := &TemplateDefinition{Name: "web", Scripts: []TemplateScriptDefinition{
+		{Type: "boot", Script: "new"},
+		{Type: "boot", Script: "install", ParameterValues: rawJSON(`{"v":"2"}`)},
+		{Type: "operational", Script: "install"},
+	}}
+	current := []types.TemplateScript{
+		{ID: "ts1", Type: "boot", ScriptID: "s1", ExecutionOrder: 1, ParameterValues: rawJSON(`{"v":"1"}`)},
+		{ID: "ts2", Type: "boot", ScriptID: "s9", ExecutionOrder: 2},
+		{ID: "ts3", Type: "operational", ScriptID: "s1", ExecutionOrder: 1},
+	}
+	scriptIDs := map[string]string{"install": "s1", "new": ""}
+
+	plan, err := PlanTemplateScripts(def, current, scriptIDs)
+	assert.Nil(err, "Couldn't plan template scripts")
+	assert.Equal([]Change{
+		{Action: Create, Kind: KindTemplateScript, Name: "web/boot/new"},
+		{Action: Update, Kind: KindTemplateScript, Name: "web/boot/install", ID: "ts1"},
+		{Action: Delete, Kind: KindTemplateScript, Name: "web/boot/s9", ID: "ts2"},
+		{Action: Reorder, Kind: KindTemplateScript, Name: "web/boot"},
+	}, plan.Changes(), "Unexpected plan")
+
+	plan.Slots[0].ID = "ts4"
+	assert.Equal([]string{"ts4", "ts1"}, plan.OrderedIDs("boot"), "Unexpected execution order")
+
+	_, err = PlanTemplateScripts(def, current, map[string]string{})
+	assert.NotNil(err, "Unknown scripts should fail")
+}
@@ -0,0 +1,32 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// scriptVariable matches $NAME and ${NAME} references to environment variables in shell scripts
+var scriptVariable = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// Render returns the code of a script with the parameter values of a script characterisation in place
+// of the variables referencing them, as hosts pass parameters as environment variables. Other variables
+// are kept
+func Render(code string, parameterValues *json.RawMessage) string {
+	values, ok := decodeJSON(parameterValues).(map[string]interface{})
+	if !ok || len(values) == 0 {
+		return code
+	}
+	return scriptVariable.ReplaceAllStringFunc(code, func(ref string) string {
+		m := scriptVariable.FindStringSubmatch(ref)
+		name := m[1] + m[2]
+		value, ok := values[name]
+		if !ok {
+			return ref
+		}
+		if s, isString := value.(string); isString {
+			return s
+		}
+		return fmt.Sprint(value)
+	})
+}
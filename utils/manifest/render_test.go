@@ -0,0 +1,15 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	assert := assert.New(t)
+
+	code := "apt-get install -y nginx=$VERSION\necho ${WORKERS} workers in $HOME\n"
+	assert.Equal("apt-get install -y nginx=1.10\necho 4 workers in $HOME\n", Render(code, rawJSON(`{"VERSION":"1.10","WORKERS":4}`)), "Parameters should be substituted")
+	assert.Equal(code, Render(code, nil), "Scripts without parameters should be kept")
+}
@@ -0,0 +1,280 @@
+package manifest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ServerDefinition describes a server to be created. References may hold names or IDs
+type ServerDefinition struct {
+	Line       int      `json:"line"`
+	Name       string   `json:"name"`
+	Fqdn       string   `json:"fqdn"`
+	Template   string   `json:"template"`
+	Plan       string   `json:"plan"`
+	Workspace  string   `json:"workspace"`
+	SSHProfile string   `json:"ssh_profile,omitempty"`
+	Labels     []string `json:"labels,omitempty"`
+}
+
+// serverFields maps accepted column names to the field they set
+var serverFields = map[string]string{
+	"name":           "name",
+	"fqdn":           "fqdn",
+	"template":       "template",
+	"template_id":    "template",
+	"plan":           "plan",
+	"server_plan":    "plan",
+	"server_plan_id": "plan",
+	"workspace":      "workspace",
+	"workspace_id":   "workspace",
+	"ssh_profile":    "ssh_profile",
+	"ssh_profile_id": "ssh_profile",
+	"labels":         "labels",
+}
+
+// serverRow holds the raw values of a server definition
+type serverRow struct {
+	line   int
+	fields map[string]string
+	labels []string
+}
+
+// LoadServers reads server definitions from a CSV file with a header row, or from a YAML file
+// holding a list of servers. Every definition is validated, and all problems are reported at once
+func LoadServers(file string) ([]ServerDefinition, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rows []serverRow
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		rows, err = readServersCSV(f)
+	case ".yaml", ".yml":
+		rows, err = readServersYAML(f)
+	default:
+		return nil, fmt.Errorf("Unsupported server manifest %s. Use a .csv, .yaml or .yml file", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't parse %s: %s", file, err)
+	}
+	return serverDefinitions(rows)
+}
+
+// serverDefinitions validates rows and converts them into definitions
+func serverDefinitions(rows []serverRow) ([]ServerDefinition, error) {
+	var defs []ServerDefinition
+	var problems []string
+	names := make(map[string]int)
+	for _, r := range rows {
+		def := ServerDefinition{
+			Line:       r.line,
+			Name:       r.fields["name"],
+			Fqdn:       r.fields["fqdn"],
+			Template:   r.fields["template"],
+			Plan:       r.fields["plan"],
+			Workspace:  r.fields["workspace"],
+			SSHProfile: r.fields["ssh_profile"],
+			Labels:     r.labels,
+		}
+		if def.Fqdn == "" {
+			def.Fqdn = def.Name
+		}
+
+		for _, required := range []string{"name", "template", "plan", "workspace"} {
+			if r.fields[required] == "" {
+				problems = append(problems, fmt.Sprintf("line %d: missing %s", r.line, required))
+			}
+		}
+		if def.Name != "" {
+			if prev, ok := names[def.Name]; ok {
+				problems = append(problems, fmt.Sprintf("line %d: server %s is already defined at line %d", r.line, def.Name, prev))
+			} else {
+				names[def.Name] = r.line
+			}
+		}
+		defs = append(defs, def)
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("Invalid server definitions:\n\t%s", strings.Join(problems, "\n\t"))
+	}
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("No servers defined")
+	}
+	return defs, nil
+}
+
+// readServersCSV reads rows of a CSV file whose first row names the columns
+func readServersCSV(r io.Reader) ([]serverRow, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, len(header))
+	for i, h := range header {
+		field, ok := serverFields[strings.ToLower(strings.TrimSpace(h))]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", h)
+		}
+		columns[i] = field
+	}
+
+	var rows []serverRow
+	// the header is the first line
+	line := 1
+	for {
+		line++
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := serverRow{line: line, fields: make(map[string]string)}
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			if columns[i] == "labels" {
+				row.labels = splitLabels(value)
+			} else {
+				row.fields[columns[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readServersYAML reads a YAML list of flat mappings, optionally under a servers key.
+// Labels may be given as a flow sequence, a block sequence or a comma separated string
+func readServersYAML(r io.Reader) ([]serverRow, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	root, err := decodeYAML(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if root.Kind == yamlMapping {
+		if len(root.Keys) != 1 || root.Keys[0] != "servers" {
+			return nil, fmt.Errorf("line %d: expected a list of servers", root.Line)
+		}
+		root = root.Values["servers"]
+	}
+	if yamlNull(root) {
+		return nil, nil
+	}
+	return yamlServerRows(root)
+}
+
+// yamlServerRows reads the raw values of the server definitions listed in node
+func yamlServerRows(node *yamlNode) ([]serverRow, error) {
+	if node.Kind != yamlSequence {
+		return nil, fmt.Errorf("line %d: servers must be a list", node.Line)
+	}
+	var rows []serverRow
+	for _, item := range node.Items {
+		if item.Kind != yamlMapping {
+			return nil, fmt.Errorf("line %d: expected a server definition", item.Line)
+		}
+		row := serverRow{line: item.Line, fields: make(map[string]string)}
+		for _, k := range item.Keys {
+			v := item.Values[k]
+			field, ok := serverFields[strings.ToLower(k)]
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown key %q", v.Line, k)
+			}
+			switch {
+			case field == "labels" && v.Kind == yamlSequence:
+				for _, l := range v.Items {
+					if l.Kind != yamlScalar {
+						return nil, fmt.Errorf("line %d: labels must be a list of values", l.Line)
+					}
+					row.labels = append(row.labels, yamlString(l))
+				}
+			case v.Kind != yamlScalar:
+				return nil, fmt.Errorf("line %d: %s must be a single value", v.Line, k)
+			case field == "labels":
+				row.labels = splitLabels(yamlString(v))
+			default:
+				row.fields[field] = yamlString(v)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func unquoteYAML(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// splitLabels splits a list of labels separated by commas, semicolons or spaces
+func splitLabels(s string) []string {
+	var labels []string
+	for _, l := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' || r == ' ' }) {
+		if l = unquoteYAML(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// WriteServersYAML writes definitions as a YAML manifest read by LoadServers.
+// Unknown references are written empty, so that they are filled before creating the servers
+func WriteServersYAML(w io.Writer, defs []ServerDefinition) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("servers:\n")
+	for _, def := range defs {
+		fmt.Fprintf(bw, "  - name: %s\n", quoteYAML(def.Name))
+		if def.Fqdn != "" && def.Fqdn != def.Name {
+			fmt.Fprintf(bw, "    fqdn: %s\n", quoteYAML(def.Fqdn))
+		}
+		fmt.Fprintf(bw, "    template: %s\n", quoteYAML(def.Template))
+		fmt.Fprintf(bw, "    plan: %s\n", quoteYAML(def.Plan))
+		fmt.Fprintf(bw, "    workspace: %s\n", quoteYAML(def.Workspace))
+		if def.SSHProfile != "" {
+			fmt.Fprintf(bw, "    ssh_profile: %s\n", quoteYAML(def.SSHProfile))
+		}
+		if len(def.Labels) > 0 {
+			labels := make([]string, len(def.Labels))
+			for i, l := range def.Labels {
+				labels[i] = quoteYAML(l)
+			}
+			fmt.Fprintf(bw, "    labels: [%s]\n", strings.Join(labels, ", "))
+		}
+	}
+	return bw.Flush()
+}
+
+// quoteYAML quotes values that would otherwise be read differently, such as empty ones or those holding comments
+func quoteYAML(s string) string {
+	if s == "" || strings.ContainsAny(s, ":#,[]'\"") || strings.TrimSpace(s) != s {
+		if strings.Contains(s, `"`) {
+			return "'" + s + "'"
+		}
+		return `"` + s + `"`
+	}
+	return s
+}
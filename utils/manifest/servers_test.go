@@ -0,0 +1,130 @@
+package manifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeServerManifest(t *testing.T, name string, content string) string {
+	dir, err := ioutil.TempDir("", "servers")
+	assert.Nil(t, err, "Couldn't create temporary directory")
+	file := filepath.Join(dir, name)
+	assert.Nil(t, ioutil.WriteFile(file, []byte(content), 0600), "Couldn't write server manifest")
+	return file
+}
+
+func TestLoadServersCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	file := writeServerManifest(t, "servers.csv", `name,template,plan,workspace,ssh_profile,labels
+web1,wordpress,plan1,production,deploy,"web,prod"
+web2,wordpress,plan1,production,,web
+`)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	servers, err := LoadServers(file)
+	assert.Nil(err, "Couldn't load servers")
+	assert.Equal([]ServerDefinition{
+		{Line: 2, Name: "web1", Fqdn: "web1", Template: "wordpress", Plan: "plan1", Workspace: "production", SSHProfile: "deploy", Labels: []string{"web", "prod"}},
+		{Line: 3, Name: "web2", Fqdn: "web2", Template: "wordpress", Plan: "plan1", Workspace: "production", Labels: []string{"web"}},
+	}, servers, "Unexpected servers")
+}
+
+func TestLoadServersYAML(t *testing.T) {
+	assert := assert.New(t)
+
+	file := writeServerManifest(t, "servers.yaml", `# lab servers
+servers:
+  - name: lab1
+    fqdn: lab1.example.com
+    template: "base"
+    plan: plan1
+    workspace: lab
+    labels: [lab, "test"]
+  - name: lab2
+    template: base
+    plan: plan1
+    workspace: lab # default workspace
+    labels:
+      - lab
+`)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	servers, err := LoadServers(file)
+	assert.Nil(err, "Couldn't load servers")
+	assert.Equal([]ServerDefinition{
+		{Line: 3, Name: "lab1", Fqdn: "lab1.example.com", Template: "base", Plan: "plan1", Workspace: "lab", Labels: []string{"lab", "test"}},
+		{Line: 9, Name: "lab2", Fqdn: "lab2", Template: "base", Plan: "plan1", Workspace: "lab", Labels: []string{"lab"}},
+	}, servers, "Unexpected servers")
+}
+
+func TestLoadServersYAMLList(t *testing.T) {
+	assert := assert.New(t)
+
+	file := writeServerManifest(t, "servers.yml", `- {name: "007", template: base, plan: 5501000000000000000001e5, workspace: lab}
+- name: lab2
+  template: base
+  plan: plan1
+  workspace: [lab]
+`)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	_, err := LoadServers(file)
+	assert.NotNil(err, "Nested values should fail")
+	assert.Contains(err.Error(), "line 5: workspace must be a single value", "Nested values should be located")
+
+	file = writeServerManifest(t, "servers.yml", `- {name: "007", template: base, plan: 5501000000000000000001e5, workspace: 0123}
+`)
+	defer os.RemoveAll(filepath.Dir(file))
+	servers, err := LoadServers(file)
+	assert.Nil(err, "Couldn't load servers")
+	assert.Equal([]ServerDefinition{
+		{Line: 1, Name: "007", Fqdn: "007", Template: "base", Plan: "5501000000000000000001e5", Workspace: "0123"},
+	}, servers, "Values should be read as written")
+}
+
+func TestLoadServersInvalid(t *testing.T) {
+	file := writeServerManifest(t, "servers.csv", `name,template,plan,workspace
+web1,wordpress,,production
+web1,wordpress,plan1,production
+`)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	_, err := LoadServers(file)
+	assert.NotNil(t, err, "Invalid servers should fail")
+	assert.Contains(t, err.Error(), "line 2: missing plan", "Missing fields should be reported")
+	assert.Contains(t, err.Error(), "line 3: server web1 is already defined at line 2", "Duplicated servers should be reported")
+}
+
+func TestWriteServersYAML(t *testing.T) {
+	assert := assert.New(t)
+
+	defs := []ServerDefinition{
+		{Name: "web-01", Fqdn: "web-01", Template: "base", Plan: "t3.small", Workspace: "prod", Labels: []string{"web", "tier:front"}},
+		{Name: "db", Fqdn: "db.example.com", Plan: "5a1f", Workspace: "prod", SSHProfile: "ops"},
+	}
+	file := writeServerManifest(t, "servers.yaml", "")
+	defer os.RemoveAll(filepath.Dir(file))
+	f, err := os.Create(file)
+	assert.Nil(err, "Couldn't create manifest")
+	assert.Nil(WriteServersYAML(f, defs), "Couldn't write manifest")
+	f.Close()
+
+	_, err = LoadServers(file)
+	assert.NotNil(err, "Servers without template should fail")
+	assert.Contains(err.Error(), "line 7: missing template", "Empty references should be reported")
+
+	defs[1].Template = "db"
+	f, _ = os.Create(file)
+	assert.Nil(WriteServersYAML(f, defs), "Couldn't write manifest")
+	f.Close()
+
+	servers, err := LoadServers(file)
+	assert.Nil(err, "Written manifest should be loaded")
+	defs[0].Line, defs[1].Line = 2, 7
+	assert.Equal(defs, servers, "Servers should be kept")
+}
@@ -0,0 +1,174 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/flexiant/concerto/api/types"
+)
+
+// KindServer is the kind of planned changes to servers
+const KindServer = "server"
+
+// Stack describes scripts, templates and the servers built from them
+type Stack struct {
+	Manifest
+	Servers []ServerDefinition
+}
+
+// stackKeys are the keys of a stack file
+var stackKeys = map[string]bool{"scripts": true, "templates": true, "servers": true}
+
+// LoadStack reads a YAML file whose scripts, templates and servers keys list the definitions LoadDir and
+// LoadServers read. Code files are relative to the stack file
+func LoadStack(file string) (*Stack, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	s, err := readStack(string(data), filepath.Dir(file))
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't parse %s: %s", file, err)
+	}
+	return s, nil
+}
+
+// readStack reads a stack, reading code files in dir
+func readStack(data string, dir string) (*Stack, error) {
+	root, err := decodeYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	if root.Kind != yamlMapping {
+		return nil, fmt.Errorf("line %d: expected scripts, templates and servers keys", root.Line)
+	}
+	for _, k := range root.Keys {
+		if !stackKeys[k] {
+			return nil, fmt.Errorf("line %d: unknown key %q", root.Values[k].Line, k)
+		}
+	}
+
+	s := &Stack{}
+	if err = decodeStackList(root, "scripts", &s.Scripts); err != nil {
+		return nil, err
+	}
+	if err = decodeStackList(root, "templates", &s.Templates); err != nil {
+		return nil, err
+	}
+	for i := range s.Scripts {
+		if s.Scripts[i].Name == "" {
+			return nil, fmt.Errorf("script %d has no name", i+1)
+		}
+		if err = s.Scripts[i].readCode(dir); err != nil {
+			return nil, err
+		}
+	}
+	for i, t := range s.Templates {
+		if t.Name == "" {
+			return nil, fmt.Errorf("template %d has no name", i+1)
+		}
+	}
+	if err = s.Validate(); err != nil {
+		return nil, err
+	}
+
+	if node := stackList(root, "servers"); node != nil {
+		rows, err := yamlServerRows(node)
+		if err != nil {
+			return nil, err
+		}
+		if s.Servers, err = serverDefinitions(rows); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(s.Scripts) == 0 && len(s.Templates) == 0 && len(s.Servers) == 0 {
+		return nil, fmt.Errorf("no scripts, templates or servers defined")
+	}
+	return s, nil
+}
+
+// stackList returns the list under key, or nil when it's missing or empty
+func stackList(root *yamlNode, key string) *yamlNode {
+	node := root.Values[key]
+	if yamlNull(node) {
+		return nil
+	}
+	return node
+}
+
+// decodeStackList decodes the list under key into v as encoding/json would, so that definitions are
+// read as in blueprint repositories
+func decodeStackList(root *yamlNode, key string, v interface{}) error {
+	node := stackList(root, key)
+	if node == nil {
+		return nil
+	}
+	if node.Kind != yamlSequence {
+		return fmt.Errorf("line %d: %s must be a list", node.Line, key)
+	}
+	data, err := json.Marshal(node.Value())
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid %s: %s", key, err)
+	}
+	return nil
+}
+
+// PlanServers returns the changes needed for servers to match the stack. templateIDs maps names and IDs of
+// templates to their IDs, and has none for those still to be created. Servers can't be moved to another
+// template or plan, so those differences are reported as errors. Servers built from templates of the stack
+// that aren't in it are only deleted when pruning
+func PlanServers(s *Stack, current []types.Server, templateIDs map[string]string, prune bool) ([]Change, error) {
+	byName := make(map[string]types.Server)
+	for _, srv := range current {
+		byName[srv.Name] = srv
+	}
+
+	var changes []Change
+	var problems []string
+	names := make(map[string]bool)
+	for _, def := range s.Servers {
+		names[def.Name] = true
+		srv, ok := byName[def.Name]
+		if !ok {
+			changes = append(changes, Change{Action: Create, Kind: KindServer, Name: def.Name})
+			continue
+		}
+		if templateIDs[def.Template] != srv.Template_id {
+			problems = append(problems, fmt.Sprintf("line %d: server %s uses template %s, and can't be moved to %s", def.Line, def.Name, srv.Template_id, def.Template))
+		}
+		if def.Plan != srv.Server_plan_id {
+			problems = append(problems, fmt.Sprintf("line %d: server %s uses server plan %s, and can't be moved to %s", def.Line, def.Name, srv.Server_plan_id, def.Plan))
+		}
+		if def.Fqdn != srv.Fqdn {
+			changes = append(changes, Change{Action: Update, Kind: KindServer, Name: def.Name, ID: srv.Id})
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("Servers can't be changed as defined:\n\t%s", strings.Join(problems, "\n\t"))
+	}
+
+	if prune {
+		templates := make(map[string]bool)
+		for _, t := range s.Templates {
+			if id := templateIDs[t.Name]; id != "" {
+				templates[id] = true
+			}
+		}
+		for _, srv := range current {
+			if !names[srv.Name] && templates[srv.Template_id] {
+				changes = append(changes, Change{Action: Delete, Kind: KindServer, Name: srv.Name, ID: srv.Id})
+			}
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,97 @@
+package manifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadStack(t *testing.T) {
+	assert := assert.New(t)
+
+	file := writeServerManifest(t, "stack.yaml", `scripts:
+  - name: install
+    description: Installs nginx
+    code_file: install.sh
+    parameters: [version]
+templates:
+  - name: web
+    generic_image_id: "img1"
+    service_list: ["nginx"]
+    configuration_attributes: {nginx: {port: 80}}
+    scripts:
+      - type: boot
+        script: install
+        parameter_values: {version: "1.9"}
+servers:
+  - name: web1
+    template: web
+    plan: plan1
+    workspace: production
+    labels:
+      - web
+`)
+	defer os.RemoveAll(filepath.Dir(file))
+	assert.Nil(ioutil.WriteFile(filepath.Join(filepath.Dir(file), "install.sh"), []byte("apt-get install -y nginx"), 0600), "Couldn't write code file")
+
+	s, err := LoadStack(file)
+	assert.Nil(err, "Couldn't load stack")
+	assert.Equal([]ScriptDefinition{{Name: "install", Description: "Installs nginx", Code: "apt-get install -y nginx", Parameters: []string{"version"}}}, s.Scripts, "Code should be read from code files")
+	assert.Equal("img1", s.Templates[0].GenericImageID, "Unexpected template")
+	assert.True(SameJSON(rawJSON(`{"nginx":{"port":80}}`), s.Templates[0].ConfigurationAttributes), "Unexpected configuration attributes")
+	assert.Equal([]TemplateScriptDefinition{{Type: "boot", Script: "install", ParameterValues: rawJSON(`{"version":"1.9"}`)}}, s.Templates[0].Scripts, "Unexpected template scripts")
+	assert.Equal([]ServerDefinition{{Line: 16, Name: "web1", Fqdn: "web1", Template: "web", Plan: "plan1", Workspace: "production", Labels: []string{"web"}}}, s.Servers, "Unexpected servers")
+}
+
+func TestLoadStackInvalid(t *testing.T) {
+	for content, expected := range map[string]string{
+		"volumes: []":                                 `line 1: unknown key "volumes"`,
+		"scripts:\n  - name: a\n    kode: b":          `invalid scripts: json: unknown field "kode"`,
+		"templates:\n  - name: web":                   "Template web has no generic_image_id",
+		"servers:\n  - name: web1\n    template: web": "Invalid server definitions:\n\tline 2: missing plan\n\tline 2: missing workspace",
+		"servers:\n  - name: web1\n    size: large":   `line 3: unknown key "size"`,
+		"scripts:\ntemplates:":                        "no scripts, templates or servers defined",
+	} {
+		_, err := readStack(content, ".")
+		assert.EqualError(t, err, expected, "Unexpected error for %q", content)
+	}
+}
+
+func TestPlanServers(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Stack{
+		Manifest: Manifest{Templates: []TemplateDefinition{{Name: "web"}}},
+		Servers: []ServerDefinition{
+			{Name: "web1", Fqdn: "web1.example.com", Template: "web", Plan: "p1"},
+			{Name: "web2", Fqdn: "web2", Template: "web", Plan: "p1"},
+			{Name: "web3", Fqdn: "web3", Template: "web", Plan: "p1"},
+		},
+	}
+	current := []types.Server{
+		{Id: "s1", Name: "web1", Fqdn: "web1", Template_id: "t1", Server_plan_id: "p1"},
+		{Id: "s2", Name: "web2", Fqdn: "web2", Template_id: "t1", Server_plan_id: "p1"},
+		{Id: "s4", Name: "web4", Template_id: "t1"},
+		{Id: "s5", Name: "db1", Template_id: "t2"},
+	}
+	templateIDs := map[string]string{"web": "t1", "t1": "t1", "db": "t2", "t2": "t2"}
+
+	changes, err := PlanServers(s, current, templateIDs, false)
+	assert.Nil(err, "Couldn't plan servers")
+	assert.Equal([]Change{
+		{Action: Update, Kind: KindServer, Name: "web1", ID: "s1"},
+		{Action: Create, Kind: KindServer, Name: "web3"},
+	}, changes, "Unexpected plan")
+
+	changes, err = PlanServers(s, current, templateIDs, true)
+	assert.Nil(err, "Couldn't plan servers")
+	assert.Equal(Change{Action: Delete, Kind: KindServer, Name: "web4", ID: "s4"}, changes[len(changes)-1], "Only servers of stack templates should be pruned")
+
+	s.Servers[1].Template = "db"
+	_, err = PlanServers(s, current, templateIDs, false)
+	assert.EqualError(err, "Servers can't be changed as defined:\n\tline 0: server web2 uses template t1, and can't be moved to db", "Servers shouldn't move to other templates")
+}
@@ -0,0 +1,209 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/attributes"
+)
+
+// Severities of validation issues. Templates with errors would be rejected or fail in their servers,
+// while warnings point at likely mistakes
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue is a problem found validating a template definition
+type Issue struct {
+	Severity string `json:"severity" header:"SEVERITY"`
+	Field    string `json:"field" header:"FIELD"`
+	Message  string `json:"message" header:"MESSAGE"`
+}
+
+// Catalog holds what templates are checked against. Services not given aren't checked, and scripts not
+// given are only looked up in the template file
+type Catalog struct {
+	Services []types.Service
+	// Scripts holds the parameters of the existing scripts, by name
+	Scripts map[string][]string
+}
+
+// HasErrors returns whether any of issues is an error
+func HasErrors(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTemplate checks the service list of t against the services of catalog, its configuration attributes
+// against the cookbooks of its service list and the attributes their services honor, and the parameter values
+// of its script characterisations against the parameters of the scripts they run, defined in scripts or in catalog
+func ValidateTemplate(t *TemplateDefinition, scripts []ScriptDefinition, catalog *Catalog) []Issue {
+	var issues []Issue
+	add := func(severity, field, format string, a ...interface{}) {
+		issues = append(issues, Issue{Severity: severity, Field: field, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if t.GenericImageID == "" {
+		add(SeverityError, "generic_image_id", "Template has no generic_image_id")
+	}
+
+	cookbooks := make(map[string]bool)
+	for i, service := range t.ServiceList {
+		field := fmt.Sprintf("service_list[%d]", i)
+		cookbook, recipe := parseService(service)
+		if cookbook == "" {
+			add(SeverityError, field, "Invalid service %q", service)
+			continue
+		}
+		cookbooks[cookbook] = true
+		if catalog.Services != nil && !knownService(catalog.Services, cookbook, recipe) {
+			add(SeverityError, field, "Service %s isn't in the catalog", service)
+		}
+	}
+
+	if t.ConfigurationAttributes != nil {
+		var attrs map[string]interface{}
+		if err := json.Unmarshal(*t.ConfigurationAttributes, &attrs); err != nil {
+			add(SeverityError, "configuration_attributes", "Configuration attributes must be a JSON object")
+		} else {
+			for _, key := range sortedKeys(keySet(attrs)) {
+				if !cookbooks[key] {
+					add(SeverityWarning, "configuration_attributes."+key, "Attributes of %s don't configure any service of service_list", key)
+					continue
+				}
+				definitions, ok := serviceDefinitions(catalog.Services, key)
+				if !ok {
+					continue
+				}
+				for _, path := range sortedKeys(keySet(attributes.Flatten(map[string]interface{}{key: attrs[key]}))) {
+					if !attributes.Declared(definitions, path) {
+						add(SeverityWarning, "configuration_attributes."+path, "Service %s has no attribute %s", key, path)
+					}
+				}
+			}
+		}
+	}
+
+	local := make(map[string][]string)
+	for _, s := range scripts {
+		local[s.Name] = s.Parameters
+	}
+	for i, ts := range t.Scripts {
+		field := fmt.Sprintf("scripts[%d]", i)
+		if _, ok := scriptTypeOrder[ts.Type]; !ok {
+			add(SeverityError, field+".type", "Invalid type %q. Must be %s", ts.Type, scriptTypeNames())
+		}
+		if ts.Script == "" {
+			add(SeverityError, field+".script", "Script characterisation has no script")
+			continue
+		}
+
+		parameters, ok := local[ts.Script]
+		if !ok {
+			parameters, ok = catalog.Scripts[ts.Script]
+		}
+		if !ok {
+			if catalog.Scripts != nil {
+				add(SeverityError, field+".script", "Script %s doesn't exist", ts.Script)
+			} else {
+				add(SeverityWarning, field+".script", "Script %s isn't in the file, so its parameters can't be checked", ts.Script)
+			}
+			continue
+		}
+
+		values := make(map[string]interface{})
+		if ts.ParameterValues != nil {
+			if err := json.Unmarshal(*ts.ParameterValues, &values); err != nil {
+				add(SeverityError, field+".parameter_values", "Parameter values must be a JSON object")
+				continue
+			}
+		}
+		defined := make(map[string]bool)
+		for _, p := range parameters {
+			defined[p] = true
+			if _, ok := values[p]; !ok {
+				add(SeverityWarning, field+".parameter_values", "Parameter %s of script %s has no value", p, ts.Script)
+			}
+		}
+		for _, name := range sortedKeys(keySet(values)) {
+			if !defined[name] {
+				add(SeverityError, field+".parameter_values."+name, "Script %s has no parameter %s", ts.Script, name)
+			}
+		}
+	}
+	return issues
+}
+
+// parseService returns the cookbook and recipe of a service list entry, such as "nginx", "nginx::default"
+// or "nginx@1.2.0::default". Recipe is empty when not given
+func parseService(service string) (cookbook string, recipe string) {
+	service = strings.TrimSpace(service)
+	if i := strings.Index(service, "::"); i >= 0 {
+		service, recipe = service[:i], service[i+2:]
+	}
+	if i := strings.Index(service, "@"); i >= 0 {
+		service = service[:i]
+	}
+	return service, recipe
+}
+
+// knownService returns whether a service of the catalog provides the cookbook, either by name or through
+// its recipes, and the recipe, when given and the service lists its recipes
+func knownService(services []types.Service, cookbook string, recipe string) bool {
+	for _, s := range services {
+		provides := s.Name == cookbook
+		var recipes []string
+		for _, r := range s.Recipes {
+			if c, rr := parseService(r); c == cookbook {
+				provides = true
+				recipes = append(recipes, rr)
+			}
+		}
+		if !provides {
+			continue
+		}
+		if recipe == "" || len(recipes) == 0 {
+			return true
+		}
+		for _, r := range recipes {
+			if r == recipe || (r == "" && recipe == "default") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// serviceDefinitions returns the attributes honored by the services of the catalog providing the cookbook,
+// and whether any of them defines its attributes, as they can't be checked otherwise
+func serviceDefinitions(services []types.Service, cookbook string) ([]attributes.Definition, bool) {
+	var definitions []attributes.Definition
+	defined := false
+	for _, s := range services {
+		if s.Attributes == nil || !knownService([]types.Service{s}, cookbook, "") {
+			continue
+		}
+		var given map[string]interface{}
+		if err := json.Unmarshal(*s.Attributes, &given); err != nil {
+			continue
+		}
+		definitions = append(definitions, attributes.Definitions(given)...)
+		defined = true
+	}
+	return definitions, defined
+}
+
+func keySet(m map[string]interface{}) map[string]bool {
+	set := make(map[string]bool)
+	for k := range m {
+		set[k] = true
+	}
+	return set
+}
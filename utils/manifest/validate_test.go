@@ -0,0 +1,95 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	catalog := &Catalog{
+		Services: []types.Service{
+			{Name: "nginx", Recipes: []string{"nginx", "nginx::source"}},
+			{Name: "database", Recipes: []string{"mysql::server"}},
+		},
+		Scripts: map[string][]string{"notify": {"url"}},
+	}
+	template := &TemplateDefinition{
+		Name:                    "web",
+		GenericImageID:          "img",
+		ServiceList:             []string{"nginx@1.2.0::source", "mysql::server"},
+		ConfigurationAttributes: rawJSON(`{"nginx":{"port":80},"mysql":{"port":3306}}`),
+		Scripts: []TemplateScriptDefinition{
+			{Type: "boot", Script: "install", ParameterValues: rawJSON(`{"version":"1.10"}`)},
+			{Type: "shutdown", Script: "notify", ParameterValues: rawJSON(`{"url":"http://example.com"}`)},
+		},
+	}
+	scripts := []ScriptDefinition{{Name: "install", Parameters: []string{"version"}}}
+	assert.Empty(ValidateTemplate(template, scripts, catalog), "Valid templates should have no issues")
+
+	template.GenericImageID = ""
+	template.ServiceList = []string{"nginx::binary", "redis"}
+	template.ConfigurationAttributes = rawJSON(`{"nginx":{"port":80},"mysql":{"port":3306}}`)
+	template.Scripts = []TemplateScriptDefinition{
+		{Type: "startup", Script: "install", ParameterValues: rawJSON(`{"versoin":"1.10"}`)},
+		{Type: "boot", Script: "missing"},
+	}
+	issues := ValidateTemplate(template, scripts, catalog)
+	assert.Equal([]Issue{
+		{Severity: SeverityError, Field: "generic_image_id", Message: "Template has no generic_image_id"},
+		{Severity: SeverityError, Field: "service_list[0]", Message: "Service nginx::binary isn't in the catalog"},
+		{Severity: SeverityError, Field: "service_list[1]", Message: "Service redis isn't in the catalog"},
+		{Severity: SeverityWarning, Field: "configuration_attributes.mysql", Message: "Attributes of mysql don't configure any service of service_list"},
+		{Severity: SeverityError, Field: "scripts[0].type", Message: `Invalid type "startup". Must be "boot", "operational", "migration" or "shutdown"`},
+		{Severity: SeverityWarning, Field: "scripts[0].parameter_values", Message: "Parameter version of script install has no value"},
+		{Severity: SeverityError, Field: "scripts[0].parameter_values.versoin", Message: "Script install has no parameter versoin"},
+		{Severity: SeverityError, Field: "scripts[1].script", Message: "Script missing doesn't exist"},
+	}, issues, "Unexpected issues")
+	assert.True(HasErrors(issues), "Issues should hold errors")
+}
+
+func TestValidateTemplateAttributes(t *testing.T) {
+	assert := assert.New(t)
+
+	catalog := &Catalog{
+		Services: []types.Service{
+			{Name: "nginx", Attributes: rawJSON(`{"nginx/port":{"default":80,"description":"Listen port"},"nginx/sites":{"default":{}}}`)},
+			{Name: "database", Recipes: []string{"mysql::server"}},
+			{Name: "ntp", Attributes: rawJSON(`{"ntp":{"servers":["pool.ntp.org"]}}`)},
+		},
+	}
+	template := &TemplateDefinition{
+		GenericImageID:          "img",
+		ServiceList:             []string{"nginx", "mysql::server", "ntp"},
+		ConfigurationAttributes: rawJSON(`{"nginx":{"port":8080,"sites":{"web":{"root":"/srv"}}},"mysql":{"anything":1},"ntp":{"servers":["a"]}}`),
+	}
+	assert.Empty(ValidateTemplate(template, nil, catalog), "Attributes honored by services should have no issues")
+
+	template.ConfigurationAttributes = rawJSON(`{"nginx":{"prot":8080,"ssl":{"enabled":true}},"ntp":{"server":["a"]}}`)
+	assert.Equal([]Issue{
+		{Severity: SeverityWarning, Field: "configuration_attributes.nginx.prot", Message: "Service nginx has no attribute nginx.prot"},
+		{Severity: SeverityWarning, Field: "configuration_attributes.nginx.ssl.enabled", Message: "Service nginx has no attribute nginx.ssl.enabled"},
+		{Severity: SeverityWarning, Field: "configuration_attributes.ntp.server", Message: "Service ntp has no attribute ntp.server"},
+	}, ValidateTemplate(template, nil, catalog), "Attributes services don't honor should be reported")
+}
+
+func TestValidateTemplateOffline(t *testing.T) {
+	assert := assert.New(t)
+
+	template := &TemplateDefinition{
+		GenericImageID:          "img",
+		ServiceList:             []string{"anything"},
+		ConfigurationAttributes: rawJSON(`[]`),
+		Scripts:                 []TemplateScriptDefinition{{Type: "boot", Script: "remote"}},
+	}
+	issues := ValidateTemplate(template, nil, &Catalog{})
+	assert.Equal([]Issue{
+		{Severity: SeverityError, Field: "configuration_attributes", Message: "Configuration attributes must be a JSON object"},
+		{Severity: SeverityWarning, Field: "scripts[0].script", Message: "Script remote isn't in the file, so its parameters can't be checked"},
+	}, issues, "Services and remote scripts shouldn't be checked offline")
+	assert.True(HasErrors(issues), "Issues should hold errors")
+	assert.False(HasErrors(issues[1:]), "Warnings aren't errors")
+}
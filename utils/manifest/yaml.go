@@ -0,0 +1,474 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// kinds of YAML nodes
+const (
+	yamlScalar = iota
+	yamlMapping
+	yamlSequence
+)
+
+// yamlNode is a node of a YAML document, keeping the line it starts at so that problems can be located
+type yamlNode struct {
+	Line   int
+	Kind   int
+	Scalar interface{}
+	// Text is the scalar as written, unquoted, so that values such as IDs made of digits can be read as strings
+	Text   string
+	Keys   []string
+	Values map[string]*yamlNode
+	Items  []*yamlNode
+}
+
+// Value returns the node as the values encoding/json decodes: maps, slices, strings, numbers, booleans and nil
+func (n *yamlNode) Value() interface{} {
+	switch n.Kind {
+	case yamlMapping:
+		m := make(map[string]interface{}, len(n.Keys))
+		for _, k := range n.Keys {
+			m[k] = n.Values[k].Value()
+		}
+		return m
+	case yamlSequence:
+		s := make([]interface{}, len(n.Items))
+		for i, item := range n.Items {
+			s[i] = item.Value()
+		}
+		return s
+	}
+	return n.Scalar
+}
+
+// yamlLine is a line of a YAML document, without indentation nor comments
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+// yamlParser reads the block structure of a document line by line
+type yamlParser struct {
+	raw   []string
+	lines []*yamlLine
+	pos   int
+}
+
+// decodeYAML parses the subset of YAML manifests are written in: block mappings and sequences, plain and
+// quoted scalars, single line flow sequences and mappings, literal (|) and folded (>) block scalars, and
+// comments. Anchors, tags and multiple documents aren't supported
+func decodeYAML(data string) (*yamlNode, error) {
+	p := &yamlParser{raw: strings.Split(strings.Replace(data, "\r\n", "\n", -1), "\n")}
+	for i, raw := range p.raw {
+		text := strings.TrimRight(stripYAMLComment(raw), " \t")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || (trimmed == "---" && len(trimmed) == len(text)) {
+			continue
+		}
+		p.lines = append(p.lines, &yamlLine{num: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(p.lines) == 0 {
+		return &yamlNode{Line: 1, Kind: yamlScalar}, nil
+	}
+
+	root, err := p.parseBlock(0)
+	if err != nil {
+		return nil, err
+	}
+	if l := p.peek(); l != nil {
+		return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+	}
+	return root, nil
+}
+
+func (p *yamlParser) peek() *yamlLine {
+	if p.pos < len(p.lines) {
+		return p.lines[p.pos]
+	}
+	return nil
+}
+
+// parseBlock parses the node starting at the next line, which must be indented at least minIndent
+func (p *yamlParser) parseBlock(minIndent int) (*yamlNode, error) {
+	l := p.peek()
+	if l == nil || l.indent < minIndent {
+		return &yamlNode{Kind: yamlScalar}, nil
+	}
+	if strings.HasPrefix(l.text, "\t") {
+		return nil, fmt.Errorf("line %d: tabs can't be used for indentation", l.num)
+	}
+	if isYAMLItem(l.text) {
+		return p.parseSequence(l.indent)
+	}
+	if _, _, ok := splitYAMLKey(l.text); ok {
+		return p.parseMapping(l.indent)
+	}
+	p.pos++
+	return parseYAMLValue(l.text, l.num)
+}
+
+// parseSequence parses the items of a block sequence indented by indent
+func (p *yamlParser) parseSequence(indent int) (*yamlNode, error) {
+	node := &yamlNode{Line: p.peek().num, Kind: yamlSequence}
+	for l := p.peek(); l != nil && l.indent == indent && isYAMLItem(l.text); l = p.peek() {
+		rest := strings.TrimLeft(l.text[1:], " ")
+		var item *yamlNode
+		var err error
+		switch {
+		case rest == "":
+			p.pos++
+			item, err = p.parseBlock(indent + 1)
+		case isYAMLItem(rest) || isYAMLKey(rest):
+			// the item is a nested block starting in the same line, as in "- name: web", so the line is
+			// read again as if the content started in a line of its own
+			l.indent += len(l.text) - len(rest)
+			l.text = rest
+			item, err = p.parseBlock(l.indent)
+		default:
+			p.pos++
+			item, err = p.parseScalar(rest, l, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if item.Line == 0 {
+			item.Line = l.num
+		}
+		node.Items = append(node.Items, item)
+	}
+	if l := p.peek(); l != nil && l.indent > indent {
+		return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+	}
+	return node, nil
+}
+
+// parseMapping parses the keys of a block mapping indented by indent
+func (p *yamlParser) parseMapping(indent int) (*yamlNode, error) {
+	node := &yamlNode{Line: p.peek().num, Kind: yamlMapping, Values: make(map[string]*yamlNode)}
+	for l := p.peek(); l != nil && l.indent == indent && !isYAMLItem(l.text); l = p.peek() {
+		if strings.HasPrefix(l.text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs can't be used for indentation", l.num)
+		}
+		key, rest, ok := splitYAMLKey(l.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", l.num)
+		}
+		if _, ok := node.Values[key]; ok {
+			return nil, fmt.Errorf("line %d: key %s is repeated", l.num, key)
+		}
+		p.pos++
+
+		var value *yamlNode
+		var err error
+		if rest == "" {
+			next := p.peek()
+			switch {
+			case next != nil && next.indent > indent:
+				value, err = p.parseBlock(indent + 1)
+			case next != nil && next.indent == indent && isYAMLItem(next.text):
+				// sequences may be indented as their key
+				value, err = p.parseSequence(indent)
+			default:
+				value = &yamlNode{Kind: yamlScalar}
+			}
+		} else {
+			value, err = p.parseScalar(rest, l, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if value.Line == 0 {
+			value.Line = l.num
+		}
+		node.Keys = append(node.Keys, key)
+		node.Values[key] = value
+	}
+	if l := p.peek(); l != nil && l.indent > indent {
+		return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+	}
+	return node, nil
+}
+
+// parseScalar parses the value given in line l after its key or item indicator, reading the following
+// lines of block scalars, which must be indented deeper than parent
+func (p *yamlParser) parseScalar(s string, l *yamlLine, parent int) (*yamlNode, error) {
+	if s[0] != '|' && s[0] != '>' {
+		return parseYAMLValue(s, l.num)
+	}
+	chomp := strings.TrimSpace(s[1:])
+	if chomp != "" && chomp != "-" && chomp != "+" {
+		return nil, fmt.Errorf("line %d: unsupported block scalar header %s", l.num, s)
+	}
+
+	// block scalars keep their comments and blank lines, so they're read from the raw lines
+	var lines []string
+	contentIndent := -1
+	end := l.num
+	for i := l.num; i < len(p.raw); i++ {
+		raw := strings.TrimRight(p.raw[i], " \t")
+		trimmed := strings.TrimLeft(raw, " ")
+		if trimmed == "" {
+			lines = append(lines, "")
+			continue
+		}
+		indent := len(raw) - len(trimmed)
+		if contentIndent < 0 {
+			contentIndent = indent
+		}
+		if indent <= parent || indent < contentIndent {
+			break
+		}
+		lines = append(lines, raw[contentIndent:])
+		end = i + 1
+	}
+	for p.pos < len(p.lines) && p.lines[p.pos].num <= end {
+		p.pos++
+	}
+
+	trailing := 0
+	for trailing < len(lines) && lines[len(lines)-1-trailing] == "" {
+		trailing++
+	}
+	content := lines[:len(lines)-trailing]
+	var text string
+	if s[0] == '|' {
+		text = strings.Join(content, "\n")
+	} else {
+		for i, line := range content {
+			// blank lines are kept as line breaks, other lines are joined
+			switch {
+			case line == "":
+				text += "\n"
+			case i > 0 && content[i-1] != "":
+				text += " "
+			}
+			text += line
+		}
+	}
+	switch {
+	case len(content) == 0:
+	case chomp == "+":
+		text += strings.Repeat("\n", trailing+1)
+	case chomp == "":
+		text += "\n"
+	}
+	return &yamlNode{Line: l.num, Kind: yamlScalar, Scalar: text, Text: text}, nil
+}
+
+// parseYAMLValue parses a value written in a single line: a flow collection or a scalar
+func parseYAMLValue(s string, line int) (*yamlNode, error) {
+	f := &yamlFlow{s: s, line: line}
+	node, err := f.parse(false)
+	if err != nil {
+		return nil, err
+	}
+	if f.skipSpaces(); f.pos < len(f.s) {
+		return nil, fmt.Errorf("line %d: unexpected %s", line, f.s[f.pos:])
+	}
+	return node, nil
+}
+
+// yamlFlow parses flow collections, such as [a, b] or {port: 80}
+type yamlFlow struct {
+	s    string
+	pos  int
+	line int
+}
+
+func (f *yamlFlow) skipSpaces() {
+	for f.pos < len(f.s) && f.s[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+// parse parses the value at the current position. Plain scalars in collections end at their separators
+func (f *yamlFlow) parse(inFlow bool) (*yamlNode, error) {
+	f.skipSpaces()
+	if f.pos >= len(f.s) {
+		return &yamlNode{Line: f.line, Kind: yamlScalar}, nil
+	}
+	switch f.s[f.pos] {
+	case '[':
+		return f.parseCollection(']')
+	case '{':
+		return f.parseCollection('}')
+	case '"', '\'':
+		s, err := f.parseQuoted()
+		return &yamlNode{Line: f.line, Kind: yamlScalar, Scalar: s, Text: s}, err
+	}
+	start := f.pos
+	for f.pos < len(f.s) {
+		c := f.s[f.pos]
+		if inFlow && (c == ',' || c == ']' || c == '}' || (c == ':' && (f.pos+1 == len(f.s) || f.s[f.pos+1] == ' '))) {
+			break
+		}
+		f.pos++
+	}
+	text := strings.TrimSpace(f.s[start:f.pos])
+	return &yamlNode{Line: f.line, Kind: yamlScalar, Scalar: plainYAMLScalar(text), Text: text}, nil
+}
+
+// parseCollection parses a flow sequence or mapping, whose opening bracket is at the current position
+func (f *yamlFlow) parseCollection(closing byte) (*yamlNode, error) {
+	node := &yamlNode{Line: f.line, Kind: yamlSequence}
+	if closing == '}' {
+		node.Kind, node.Values = yamlMapping, make(map[string]*yamlNode)
+	}
+	f.pos++
+	for {
+		f.skipSpaces()
+		if f.pos < len(f.s) && f.s[f.pos] == closing {
+			f.pos++
+			return node, nil
+		}
+		item, err := f.parse(true)
+		if err != nil {
+			return nil, err
+		}
+		if node.Kind == yamlMapping {
+			key := fmt.Sprint(item.Scalar)
+			if item.Kind != yamlScalar || item.Scalar == nil {
+				return nil, fmt.Errorf("line %d: invalid key in %s", f.line, f.s)
+			}
+			if f.skipSpaces(); f.pos >= len(f.s) || f.s[f.pos] != ':' {
+				return nil, fmt.Errorf("line %d: expected key: value in %s", f.line, f.s)
+			}
+			f.pos++
+			value, err := f.parse(true)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := node.Values[key]; ok {
+				return nil, fmt.Errorf("line %d: key %s is repeated", f.line, key)
+			}
+			node.Keys = append(node.Keys, key)
+			node.Values[key] = value
+		} else {
+			node.Items = append(node.Items, item)
+		}
+
+		f.skipSpaces()
+		switch {
+		case f.pos >= len(f.s):
+			return nil, fmt.Errorf("line %d: missing %c in %s", f.line, closing, f.s)
+		case f.s[f.pos] == ',':
+			f.pos++
+		case f.s[f.pos] != closing:
+			return nil, fmt.Errorf("line %d: unexpected %c in %s", f.line, f.s[f.pos], f.s)
+		}
+	}
+}
+
+// parseQuoted parses a single or double quoted string at the current position
+func (f *yamlFlow) parseQuoted() (string, error) {
+	quote := f.s[f.pos]
+	for i := f.pos + 1; i < len(f.s); i++ {
+		switch {
+		case quote == '"' && f.s[i] == '\\':
+			i++
+		case f.s[i] == quote && quote == '\'' && i+1 < len(f.s) && f.s[i+1] == '\'':
+			i++
+		case f.s[i] == quote:
+			quoted := f.s[f.pos : i+1]
+			f.pos = i + 1
+			if quote == '\'' {
+				return strings.Replace(quoted[1:len(quoted)-1], "''", "'", -1), nil
+			}
+			var s string
+			if err := json.Unmarshal([]byte(quoted), &s); err != nil {
+				return "", fmt.Errorf("line %d: invalid string %s", f.line, quoted)
+			}
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("line %d: unterminated string %s", f.line, f.s[f.pos:])
+}
+
+// plainYAMLScalar returns the value of an unquoted scalar: null, a boolean, a number or a string
+func plainYAMLScalar(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !strings.ContainsAny(s, "xXnN") {
+		return f
+	}
+	return s
+}
+
+// yamlString returns a scalar as written. Null values are empty
+func yamlString(n *yamlNode) string {
+	if n.Scalar == nil {
+		return ""
+	}
+	return n.Text
+}
+
+// yamlNull returns whether a node is missing or null, as keys without value are
+func yamlNull(n *yamlNode) bool {
+	return n == nil || (n.Kind == yamlScalar && n.Scalar == nil)
+}
+
+// stripYAMLComment removes comments outside quoted values
+func stripYAMLComment(s string) string {
+	var quote rune
+	for i, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// isYAMLItem returns whether text is an item of a block sequence
+func isYAMLItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// isYAMLKey returns whether text starts a block mapping
+func isYAMLKey(text string) bool {
+	_, _, ok := splitYAMLKey(text)
+	return ok
+}
+
+// splitYAMLKey splits a key: value line. Flow collections aren't keys
+func splitYAMLKey(text string) (key string, rest string, ok bool) {
+	if text[0] == '[' || text[0] == '{' {
+		return "", "", false
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		f := &yamlFlow{s: text}
+		key, err := f.parseQuoted()
+		if err != nil || f.pos >= len(text) || text[f.pos] != ':' || (f.pos+1 < len(text) && text[f.pos+1] != ' ') {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(text[f.pos+1:]), true
+	}
+	i := strings.Index(text, ": ")
+	if i < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", false
+		}
+		i = len(text) - 1
+	}
+	return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+}
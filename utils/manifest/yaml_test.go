@@ -0,0 +1,72 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	assert := assert.New(t)
+
+	node, err := decodeYAML(`---
+# stack
+name: "web: front" # quoted
+count: 3
+ratio: 0.5
+enabled: true
+empty:
+tags: [a, "b c", 1]
+attrs: {port: 80, host: 'it''s'}
+items:
+- one
+- key: value
+  other: [x]
+-
+  - nested
+script: |
+  #!/bin/sh
+  echo "# not a comment"
+
+  exit 0
+folded: >-
+  one
+  two
+
+  three
+`)
+	assert.Nil(err, "Valid documents shouldn't fail")
+	assert.Equal(map[string]interface{}{
+		"name":    "web: front",
+		"count":   int64(3),
+		"ratio":   0.5,
+		"enabled": true,
+		"empty":   nil,
+		"tags":    []interface{}{"a", "b c", int64(1)},
+		"attrs":   map[string]interface{}{"port": int64(80), "host": "it's"},
+		"items": []interface{}{
+			"one",
+			map[string]interface{}{"key": "value", "other": []interface{}{"x"}},
+			[]interface{}{"nested"},
+		},
+		"script": "#!/bin/sh\necho \"# not a comment\"\n\nexit 0\n",
+		"folded": "one two\nthree",
+	}, node.Value(), "Unexpected values")
+	assert.Equal(12, node.Values["items"].Items[1].Line, "Items should keep their line")
+}
+
+func TestDecodeYAMLInvalid(t *testing.T) {
+	for doc, expected := range map[string]string{
+		"a: 1\na: 2":           "line 2: key a is repeated",
+		"a:\n  b: 1\n    c: 2": "line 3: unexpected indentation",
+		"a: [1, 2":             "line 1: missing ] in [1, 2",
+		"a: \"open":            "line 1: unterminated string \"open",
+		"a:\n\t- b":            "line 2: tabs can't be used for indentation",
+		"- a\n- b\n  c: d":     "line 3: unexpected indentation",
+		"a: {b: 1} trailing":   "line 1: unexpected trailing",
+		"a: |x\n  text":        "line 1: unsupported block scalar header |x",
+	} {
+		_, err := decodeYAML(doc)
+		assert.EqualError(t, err, expected, "Unexpected error for %q", doc)
+	}
+}
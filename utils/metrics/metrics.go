@@ -0,0 +1,219 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// metric types as named in Prometheus text exposition format
+const (
+	typeCounter = "counter"
+	typeGauge   = "gauge"
+	typeSummary = "summary"
+)
+
+var (
+	metrics   []*Metric
+	metricsMu sync.Mutex
+)
+
+// Metric is a family of values sharing name and label names
+type Metric struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]*value
+}
+
+type value struct {
+	labelValues []string
+	value       float64
+	count       uint64
+}
+
+func newMetric(name string, help string, kind string, labels []string) *Metric {
+	m := &Metric{name: name, help: help, kind: kind, labels: labels, values: make(map[string]*value)}
+	metricsMu.Lock()
+	metrics = append(metrics, m)
+	metricsMu.Unlock()
+	return m
+}
+
+// NewCounter registers a metric that only goes up
+func NewCounter(name string, help string, labels ...string) *Metric {
+	return newMetric(name, help, typeCounter, labels)
+}
+
+// NewGauge registers a metric that can be set to any value
+func NewGauge(name string, help string, labels ...string) *Metric {
+	return newMetric(name, help, typeGauge, labels)
+}
+
+// NewSummary registers a metric accounting for the count and sum of observations, such as durations
+func NewSummary(name string, help string, labels ...string) *Metric {
+	return newMetric(name, help, typeSummary, labels)
+}
+
+func (m *Metric) get(labelValues []string) *value {
+	if len(labelValues) != len(m.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", m.name, len(m.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	v, ok := m.values[key]
+	if !ok {
+		v = &value{labelValues: labelValues}
+		m.values[key] = v
+	}
+	return v
+}
+
+// Inc adds one to a counter
+func (m *Metric) Inc(labelValues ...string) {
+	m.Add(1, labelValues...)
+}
+
+// Add adds delta to a counter
+func (m *Metric) Add(delta float64, labelValues ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(labelValues).value += delta
+}
+
+// Set sets the value of a gauge
+func (m *Metric) Set(v float64, labelValues ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(labelValues).value = v
+}
+
+// Observe accounts for an observation of a summary
+func (m *Metric) Observe(v float64, labelValues ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	val := m.get(labelValues)
+	val.value += v
+	val.count++
+}
+
+// WriteText writes every metric with values in Prometheus text exposition format
+func WriteText(w io.Writer) error {
+	metricsMu.Lock()
+	all := make([]*Metric, len(metrics))
+	copy(all, metrics)
+	metricsMu.Unlock()
+	sort.Sort(byName(all))
+
+	buf := new(bytes.Buffer)
+	for _, m := range all {
+		m.writeText(buf)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func (m *Metric) writeText(buf *bytes.Buffer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.values) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(m.values))
+	for k := range m.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(buf, "# HELP %s %s\n", m.name, m.help)
+	fmt.Fprintf(buf, "# TYPE %s %s\n", m.name, m.kind)
+	for _, k := range keys {
+		v := m.values[k]
+		labels := m.formatLabels(v.labelValues)
+		if m.kind == typeSummary {
+			fmt.Fprintf(buf, "%s_sum%s %s\n", m.name, labels, formatValue(v.value))
+			fmt.Fprintf(buf, "%s_count%s %d\n", m.name, labels, v.count)
+			continue
+		}
+		fmt.Fprintf(buf, "%s%s %s\n", m.name, labels, formatValue(v.value))
+	}
+}
+
+func (m *Metric) formatLabels(labelValues []string) string {
+	if len(m.labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(m.labels))
+	for i, l := range m.labels {
+		pairs[i] = fmt.Sprintf("%s=%s", l, strconv.Quote(labelValues[i]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Handler returns an http handler exposing metrics, to be scraped by Prometheus
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := WriteText(w); err != nil {
+			log.Errorf("Couldn't write metrics: %s", err)
+		}
+	})
+}
+
+// Serve exposes metrics at /metrics on addr in background
+func Serve(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	go func() {
+		log.Debugf("Exposing metrics at http://%s/metrics", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Errorf("Couldn't expose metrics at %s: %s", addr, err)
+		}
+	}()
+}
+
+// WriteFile writes metrics to file, as read by node_exporter textfile collector.
+// File is replaced atomically so that collector never reads partial contents
+func WriteFile(file string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file))
+	if err != nil {
+		return err
+	}
+	if err = WriteText(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+type byName []*Metric
+
+func (a byName) Len() int           { return len(a) }
+func (a byName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byName) Less(i, j int) bool { return a[i].name < a[j].name }
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteText(t *testing.T) {
+	assert := assert.New(t)
+
+	runs := NewCounter("test_runs_total", "Test runs.", "result")
+	duration := NewSummary("test_duration_seconds", "Test duration.")
+	drift := NewGauge("test_drift", "Test drift.")
+	NewGauge("test_unused", "Metrics without values aren't written.")
+
+	runs.Inc("success")
+	runs.Inc("success")
+	runs.Inc("fail\"ure")
+	duration.Observe(1.5)
+	duration.Observe(0.5)
+	drift.Set(1)
+
+	buf := new(bytes.Buffer)
+	assert.Nil(WriteText(buf), "Couldn't write metrics")
+	expected := `# HELP test_drift Test drift.
+# TYPE test_drift gauge
+test_drift 1
+# HELP test_duration_seconds Test duration.
+# TYPE test_duration_seconds summary
+test_duration_seconds_sum 2
+test_duration_seconds_count 2
+# HELP test_runs_total Test runs.
+# TYPE test_runs_total counter
+test_runs_total{result="fail\"ure"} 1
+test_runs_total{result="success"} 2
+`
+	assert.Equal(expected, buf.String(), "Unexpected metrics")
+}
+
+func TestWriteFile(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "concerto-metrics")
+	assert.Nil(err, "Couldn't create temp dir")
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "concerto.prom")
+	assert.Nil(WriteFile(file), "Couldn't write metrics file")
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(err, "Couldn't read temp dir")
+	assert.Len(files, 1, "Temporary files should be removed")
+}
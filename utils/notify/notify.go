@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils/shutdown"
+)
+
+// requestTimeout is the maximum time a notification can take
+const requestTimeout = 10 * time.Second
+
+// Summary is posted to the webhook when a long running command finishes.
+// Text makes it suitable for Slack incoming webhooks
+type Summary struct {
+	Text            string  `json:"text"`
+	Command         string  `json:"command"`
+	Status          string  `json:"status"`
+	Succeeded       int     `json:"succeeded"`
+	Failed          int     `json:"failed"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+var (
+	webhook string
+	command string
+	start   time.Time
+
+	mu        sync.Mutex
+	tracked   bool
+	succeeded int
+	failed    int
+)
+
+// Initialize sets the webhook notified when the command finishes. Nothing is notified when url is empty
+func Initialize(url string, cmd string) {
+	webhook = url
+	command = cmd
+	start = time.Now()
+	mu.Lock()
+	tracked, succeeded, failed = false, 0, 0
+	mu.Unlock()
+
+	if url != "" {
+		shutdown.AddHook(func() {
+			if err := Send(); err != nil {
+				log.Errorf("Couldn't send notification to %s: %s", webhook, err)
+			}
+		})
+	}
+}
+
+// Track marks the running command as a long running one, to be notified when it finishes
+func Track() {
+	mu.Lock()
+	defer mu.Unlock()
+	tracked = true
+}
+
+// Record adds the outcome of items processed by the running command, and tracks it
+func Record(ok int, ko int) {
+	mu.Lock()
+	defer mu.Unlock()
+	tracked = true
+	succeeded += ok
+	failed += ko
+}
+
+// Send posts the summary of the running command, if it has been tracked
+func Send() error {
+	mu.Lock()
+	if !tracked || webhook == "" {
+		mu.Unlock()
+		return nil
+	}
+	summary := newSummary(command, succeeded, failed, time.Since(start), shutdown.Code())
+	mu.Unlock()
+
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Notifying %s: %s", webhook, summary.Text)
+	client := &http.Client{Timeout: requestTimeout}
+	response, err := client.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", response.Status)
+	}
+	return nil
+}
+
+func newSummary(cmd string, ok int, ko int, duration time.Duration, exitCode int) *Summary {
+	status := "succeeded"
+	if ko > 0 || exitCode != 0 {
+		status = "failed"
+	}
+	duration = duration - duration%time.Second
+	return &Summary{
+		Text:            fmt.Sprintf("concerto %s %s after %s: %d succeeded, %d failed", cmd, status, duration, ok, ko),
+		Command:         cmd,
+		Status:          status,
+		Succeeded:       ok,
+		Failed:          ko,
+		DurationSeconds: duration.Seconds(),
+	}
+}
@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSummary(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newSummary("cloud servers create", 9, 1, 90*time.Second+300*time.Millisecond, 0)
+	assert.Equal("failed", s.Status, "Failed items should fail the command")
+	assert.Equal("concerto cloud servers create failed after 1m30s: 9 succeeded, 1 failed", s.Text, "Unexpected text")
+	assert.Equal(90.0, s.DurationSeconds, "Unexpected duration")
+
+	s = newSummary("converge", 1, 0, time.Second, 0)
+	assert.Equal("succeeded", s.Status, "Unexpected status")
+
+	s = newSummary("docker create_host", 0, 0, time.Second, 1)
+	assert.Equal("failed", s.Status, "Non zero exit codes should fail the command")
+}
+
+func TestSend(t *testing.T) {
+	assert := assert.New(t)
+
+	var received *Summary
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = &Summary{}
+		assert.Nil(json.NewDecoder(r.Body).Decode(received), "Couldn't decode notification")
+	}))
+	defer ts.Close()
+
+	Initialize(ts.URL, "cloud servers delete")
+	assert.Nil(Send(), "Couldn't send notification")
+	assert.Nil(received, "Commands not tracked shouldn't be notified")
+
+	Record(2, 1)
+	Record(1, 0)
+	assert.Nil(Send(), "Couldn't send notification")
+	if assert.NotNil(received, "Tracked commands should be notified") {
+		assert.Equal("cloud servers delete", received.Command, "Unexpected command")
+		assert.Equal(3, received.Succeeded, "Unexpected succeeded items")
+		assert.Equal(1, received.Failed, "Unexpected failed items")
+	}
+}
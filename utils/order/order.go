@@ -0,0 +1,129 @@
+// Package order rearranges ordered lists of IDs, such as the execution order of the scripts of a template,
+// by moving one of them or by editing the whole list as text
+package order
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Split returns the IDs given in values, each of them a JSON array or comma separated IDs
+func Split(values []string) ([]string, error) {
+	var ids []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "[") {
+			var list []string
+			if err := json.Unmarshal([]byte(value), &list); err != nil {
+				return nil, fmt.Errorf("%s isn't a JSON array of IDs: %s", value, err)
+			}
+			ids = append(ids, list...)
+			continue
+		}
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// Move returns ids with id moved to position, counted from 1, shifting the IDs in between
+func Move(ids []string, id string, position int) ([]string, error) {
+	if position < 1 || position > len(ids) {
+		return nil, fmt.Errorf("Position must be between 1 and %d", len(ids))
+	}
+	var moved []string
+	found := false
+	for _, v := range ids {
+		if v == id {
+			found = true
+			continue
+		}
+		moved = append(moved, v)
+	}
+	if !found {
+		return nil, fmt.Errorf("%s isn't in the list", id)
+	}
+
+	moved = append(moved, "")
+	copy(moved[position:], moved[position-1:])
+	moved[position-1] = id
+	return moved, nil
+}
+
+// Format returns ids one per line, each followed by its description as a comment, so that they can be
+// rearranged as text and read back with Parse
+func Format(ids []string, descriptions map[string]string) string {
+	var b bytes.Buffer
+	b.WriteString("# Rearrange the lines below in the desired order, and save the file.\n")
+	b.WriteString("# Lines starting with # are ignored. Leaving the order as it is changes nothing.\n")
+	for _, id := range ids {
+		if d := descriptions[id]; d != "" {
+			fmt.Fprintf(&b, "%s  # %s\n", id, d)
+		} else {
+			fmt.Fprintf(&b, "%s\n", id)
+		}
+	}
+	return b.String()
+}
+
+// Parse returns the IDs of text as written by Format, checking it holds every one of ids once
+func Parse(text string, ids []string) ([]string, error) {
+	known := make(map[string]bool)
+	for _, id := range ids {
+		known[id] = true
+	}
+
+	var parsed []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(strings.SplitN(scanner.Text(), "#", 2)[0])
+		if len(fields) == 0 {
+			continue
+		}
+		id := fields[0]
+		switch {
+		case len(fields) > 1:
+			return nil, fmt.Errorf("line %d: expected a single ID, found %q", line, strings.Join(fields, " "))
+		case !known[id]:
+			return nil, fmt.Errorf("line %d: unknown ID %s", line, id)
+		case seen[id]:
+			return nil, fmt.Errorf("line %d: %s is repeated", line, id)
+		}
+		seen[id] = true
+		parsed = append(parsed, id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if !seen[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing %s. Every ID must be kept", strings.Join(missing, ", "))
+	}
+	return parsed, nil
+}
+
+// Equal returns whether a and b hold the same IDs in the same order
+func Equal(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
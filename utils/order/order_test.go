@@ -0,0 +1,71 @@
+package order
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	assert := assert.New(t)
+
+	ids, err := Split([]string{`["a","b"]`, "c, d", "e"})
+	assert.Nil(err)
+	assert.Equal([]string{"a", "b", "c", "d", "e"}, ids, "IDs weren't split")
+
+	_, err = Split([]string{`["a",`})
+	assert.NotNil(err, "Invalid JSON array was accepted")
+}
+
+func TestMove(t *testing.T) {
+	assert := assert.New(t)
+	ids := []string{"a", "b", "c", "d"}
+
+	moved, err := Move(ids, "d", 1)
+	assert.Nil(err)
+	assert.Equal([]string{"d", "a", "b", "c"}, moved, "ID wasn't moved up")
+
+	moved, err = Move(ids, "a", 3)
+	assert.Nil(err)
+	assert.Equal([]string{"b", "c", "a", "d"}, moved, "ID wasn't moved down")
+
+	moved, err = Move(ids, "b", 4)
+	assert.Nil(err)
+	assert.Equal([]string{"a", "c", "d", "b"}, moved, "ID wasn't moved last")
+	assert.Equal([]string{"a", "b", "c", "d"}, ids, "Original list was changed")
+
+	_, err = Move(ids, "x", 1)
+	assert.EqualError(err, "x isn't in the list")
+	_, err = Move(ids, "a", 5)
+	assert.EqualError(err, "Position must be between 1 and 4")
+}
+
+func TestFormatParse(t *testing.T) {
+	assert := assert.New(t)
+	ids := []string{"a", "b", "c"}
+
+	text := Format(ids, map[string]string{"a": "install nginx"})
+	assert.Contains(text, "a  # install nginx\nb\nc\n")
+	parsed, err := Parse(text, ids)
+	assert.Nil(err)
+	assert.Equal(ids, parsed, "Unedited text didn't keep the order")
+
+	parsed, err = Parse("# comment\nc\n\n  a  # install nginx\nb\n", ids)
+	assert.Nil(err)
+	assert.Equal([]string{"c", "a", "b"}, parsed, "Rearranged text wasn't read")
+
+	_, err = Parse("a\nb\n", ids)
+	assert.EqualError(err, "missing c. Every ID must be kept")
+	_, err = Parse("a\nb\nc\na\n", ids)
+	assert.EqualError(err, "line 4: a is repeated")
+	_, err = Parse("a\nb\nx\n", ids)
+	assert.EqualError(err, "line 3: unknown ID x")
+	_, err = Parse("a b\nc\n", ids)
+	assert.EqualError(err, `line 1: expected a single ID, found "a b"`)
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, Equal([]string{"a", "b"}, []string{"a", "b"}))
+	assert.False(t, Equal([]string{"a", "b"}, []string{"b", "a"}))
+	assert.False(t, Equal([]string{"a"}, []string{"a", "b"}))
+}
@@ -0,0 +1,85 @@
+// Package pin checks that servers present the public keys they're expected to, in addition to a chain
+// signed by a trusted CA. Pins are written as curl does: sha256// followed by the base64 SHA-256 hash of
+// the DER encoded public key
+package pin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+const prefix = "sha256//"
+
+// Parse returns the hashes of a list of pins separated by semicolons or commas
+func Parse(s string) ([][]byte, error) {
+	var pins [][]byte
+	for _, p := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == ',' }) {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !strings.HasPrefix(p, prefix) {
+			return nil, fmt.Errorf("Invalid pin %s. Pins must start with %s", p, prefix)
+		}
+		hash, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(p, prefix))
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("Invalid pin %s. Pins must hold a base64 SHA-256 hash", p)
+		}
+		pins = append(pins, hash)
+	}
+	return pins, nil
+}
+
+// Of returns the pin of the public key of cert
+func Of(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return prefix + base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// Verify checks that a certificate of chain, either the server or an intermediate one, has one of the pinned keys
+func Verify(chain []*x509.Certificate, pins [][]byte) error {
+	if len(pins) == 0 {
+		return nil
+	}
+	for _, cert := range chain {
+		hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		for _, p := range pins {
+			if bytes.Equal(hash[:], p) {
+				return nil
+			}
+		}
+	}
+	if len(chain) == 0 {
+		return fmt.Errorf("Server presented no certificates to match pins")
+	}
+	return fmt.Errorf("Server certificate doesn't match any pin. Its key pin is %s", Of(chain[0]))
+}
+
+// VerifyPeer checks pins against the chains the handshake verified, as the certificates servers send may
+// include any other, such as a pinned one whose key they don't hold. When verification is skipped there
+// are no verified chains, and only the leaf, whose key the server proved to hold, is checked
+func VerifyPeer(rawCerts [][]byte, verifiedChains [][]*x509.Certificate, pins [][]byte) error {
+	if len(pins) == 0 {
+		return nil
+	}
+	if len(verifiedChains) == 0 {
+		if len(rawCerts) == 0 {
+			return Verify(nil, pins)
+		}
+		leaf, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return err
+		}
+		return Verify([]*x509.Certificate{leaf}, pins)
+	}
+	for _, chain := range verifiedChains {
+		if Verify(chain, pins) == nil {
+			return nil
+		}
+	}
+	return Verify(verifiedChains[0], pins)
+}
@@ -0,0 +1,94 @@
+package pin
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+
+	hash := sha256.Sum256([]byte("key"))
+	p := prefix + base64.StdEncoding.EncodeToString(hash[:])
+
+	pins, err := Parse(p + "; " + p + ",")
+	assert.Nil(err, "Pins should be parsed")
+	assert.Equal([][]byte{hash[:], hash[:]}, pins, "Unexpected pins")
+
+	pins, err = Parse("")
+	assert.Nil(err, "No pins shouldn't fail")
+	assert.Empty(pins, "No pins expected")
+
+	_, err = Parse(base64.StdEncoding.EncodeToString(hash[:]))
+	assert.NotNil(err, "Pins without prefix should fail")
+	_, err = Parse(prefix + "c2hvcnQ=")
+	assert.NotNil(err, "Pins that aren't SHA-256 hashes should fail")
+}
+
+func TestVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	leaf := &x509.Certificate{RawSubjectPublicKeyInfo: []byte("leaf")}
+	intermediate := &x509.Certificate{RawSubjectPublicKeyInfo: []byte("intermediate")}
+	chain := []*x509.Certificate{leaf, intermediate}
+
+	assert.Nil(Verify(chain, nil), "Nothing pinned should pass")
+
+	pins, _ := Parse(Of(intermediate))
+	assert.Nil(Verify(chain, pins), "Pinned intermediate should pass")
+
+	other := &x509.Certificate{RawSubjectPublicKeyInfo: []byte("other")}
+	pins, _ = Parse(Of(other))
+	err := Verify(chain, pins)
+	assert.NotNil(err, "Unpinned chain should fail")
+	assert.Contains(err.Error(), Of(leaf), "Error should show the server pin")
+}
+
+func TestVerifyPeer(t *testing.T) {
+	assert := assert.New(t)
+
+	leaf := &x509.Certificate{RawSubjectPublicKeyInfo: []byte("leaf")}
+	intermediate := &x509.Certificate{RawSubjectPublicKeyInfo: []byte("intermediate")}
+	pinned := &x509.Certificate{RawSubjectPublicKeyInfo: []byte("pinned")}
+	pins, _ := Parse(Of(pinned))
+
+	// a server sending the public pinned certificate along its own chain doesn't pass
+	verified := [][]*x509.Certificate{{leaf, intermediate}}
+	assert.NotNil(VerifyPeer(nil, verified, pins), "Pin out of the verified chain should fail")
+
+	verified = append(verified, []*x509.Certificate{leaf, pinned})
+	assert.Nil(VerifyPeer(nil, verified, pins), "Pin in a verified chain should pass")
+	assert.Nil(VerifyPeer(nil, nil, nil), "Nothing pinned should pass")
+	assert.NotNil(VerifyPeer(nil, nil, pins), "No certificates should fail")
+	assert.NotNil(VerifyPeer([][]byte{[]byte("not DER")}, nil, pins), "Unverified leaf that can't be parsed should fail")
+}
+
+func selfSigned(t *testing.T) ([]byte, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err, "Couldn't generate key")
+	template := &x509.Certificate{SerialNumber: big.NewInt(1)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err, "Couldn't create certificate")
+	cert, _ := x509.ParseCertificate(der)
+	return der, cert
+}
+
+func TestVerifyPeerUnverified(t *testing.T) {
+	assert := assert.New(t)
+
+	leafDER, leaf := selfSigned(t)
+	pinnedDER, pinned := selfSigned(t)
+
+	pins, _ := Parse(Of(leaf))
+	assert.Nil(VerifyPeer([][]byte{leafDER}, nil, pins), "Pinned leaf should pass")
+	pins, _ = Parse(Of(pinned))
+	assert.NotNil(VerifyPeer([][]byte{leafDER, pinnedDER}, nil, pins), "Pinned certificate other than the leaf should fail when unverified")
+}
@@ -0,0 +1,196 @@
+package pool
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/crash"
+	"github.com/flexiant/concerto/utils/notify"
+)
+
+const (
+	// DefaultConcurrency is the number of workers used when none has been configured
+	DefaultConcurrency = 4
+	// DefaultAttempts is the number of times an item is tried before giving up
+	DefaultAttempts = 3
+
+	statusDone    = "done"
+	statusFailed  = "failed"
+	statusPending = "pending"
+)
+
+var (
+	concurrency = DefaultConcurrency
+	attempts    = DefaultAttempts
+	backoff     = time.Second
+)
+
+// Result stores the outcome of processing a single item of a bulk operation
+type Result struct {
+	Item     string `json:"item" header:"ITEM"`
+	Status   string `json:"status" header:"STATUS"`
+	Attempts int    `json:"attempts" header:"ATTEMPTS"`
+	Error    string `json:"error,omitempty" header:"ERROR"`
+}
+
+// Succeeded returns whether the item was processed, in this or in a previous run
+func (r Result) Succeeded() bool {
+	return r.Status == statusDone || r.Status == statusSkipped
+}
+
+// InitializePool sets number of workers and attempts per item for every bulk operation
+func InitializePool(workers int, maxAttempts int) {
+	if workers < 1 {
+		log.Warnf("Invalid concurrency %d, using %d", workers, DefaultConcurrency)
+		workers = DefaultConcurrency
+	}
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	concurrency = workers
+	attempts = maxAttempts
+}
+
+// Concurrency returns the configured number of workers
+func Concurrency() int {
+	return concurrency
+}
+
+// throttle makes every worker hold on when the API starts rejecting requests
+type throttle struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+func (t *throttle) wait() {
+	t.mu.Lock()
+	d := t.until.Sub(time.Now())
+	t.mu.Unlock()
+	if d > 0 {
+		cancel.Sleep(d)
+	}
+}
+
+func (t *throttle) hold(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until := time.Now().Add(d); until.After(t.until) {
+		t.until = until
+	}
+}
+
+// Run calls fn for every item using a bounded number of workers.
+// Items that fail with a retryable error are tried again, and rate limited responses
+// pause all workers before they send further requests.
+// When a state file has been initialized, items completed in previous runs are skipped.
+// Once the command is cancelled, items that weren't started are left pending.
+// Results are returned in the same order as items.
+func Run(items []string, fn func(item string) error) []Result {
+	results := make([]Result, len(items))
+	indexes := make(chan int)
+	t := &throttle{}
+
+	workers := concurrency
+	if workers > len(items) {
+		workers = len(items)
+	}
+	log.Debugf("Processing %d items with %d workers", len(items), workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer crash.Recover()
+			for i := range indexes {
+				if cancel.Err() != nil {
+					results[i] = Result{Item: items[i], Status: statusPending}
+					continue
+				}
+				if state != nil && state.completed(items[i]) {
+					results[i] = Result{Item: items[i], Status: statusSkipped}
+					continue
+				}
+				results[i] = process(items[i], fn, t)
+				if state != nil {
+					state.record(results[i])
+				}
+			}
+		}()
+	}
+
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	failed := Failed(results)
+	notify.Record(len(results)-failed, failed)
+	if err := cancel.Err(); err != nil {
+		pending := Pending(results)
+		log.Warnf("%s: %d items completed, %d failed, %d pending", err, len(results)-failed, failed-pending, pending)
+	}
+
+	return results
+}
+
+// process handles a single item, retrying when it makes sense
+func process(item string, fn func(item string) error, t *throttle) Result {
+	result := Result{Item: item}
+	delay := backoff
+
+	var err error
+	for result.Attempts < attempts {
+		t.wait()
+		if cancel.Err() != nil && result.Attempts > 0 {
+			break
+		}
+		result.Attempts++
+
+		if err = fn(item); err == nil {
+			result.Status = statusDone
+			return result
+		}
+		if !utils.IsRetryable(err) {
+			break
+		}
+
+		log.Debugf("Attempt %d for %s failed: %s", result.Attempts, item, err)
+		if utils.IsThrottled(err) {
+			t.hold(delay)
+		} else {
+			cancel.Sleep(delay)
+		}
+		delay *= 2
+	}
+
+	result.Status = statusFailed
+	result.Error = err.Error()
+	return result
+}
+
+// Pending returns the number of items left unprocessed because the command was cancelled
+func Pending(results []Result) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == statusPending {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns the number of items that couldn't be processed
+func Failed(results []Result) int {
+	n := 0
+	for _, r := range results {
+		if !r.Succeeded() {
+			n++
+		}
+	}
+	return n
+}
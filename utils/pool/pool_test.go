@@ -0,0 +1,152 @@
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flexiant/concerto/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunKeepsOrder(t *testing.T) {
+	assert := assert.New(t)
+	InitializePool(3, 1)
+
+	items := []string{"a", "b", "c", "d", "e"}
+	results := Run(items, func(item string) error {
+		if item == "c" {
+			return &utils.HTTPError{Status: 404, Message: "not found"}
+		}
+		return nil
+	})
+
+	assert.Len(results, len(items), "Every item should have a result")
+	for i, r := range results {
+		assert.Equal(items[i], r.Item, "Results should keep items order")
+	}
+	assert.False(results[2].Succeeded(), "Item c should fail")
+	assert.Equal(1, Failed(results), "Only one item should fail")
+}
+
+func TestRunRetries(t *testing.T) {
+	assert := assert.New(t)
+	InitializePool(2, 3)
+	backoff = time.Millisecond
+
+	var calls int32
+	results := Run([]string{"a"}, func(item string) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return &utils.HTTPError{Status: 503, Message: "unavailable", Method: "GET"}
+		}
+		return nil
+	})
+
+	assert.True(results[0].Succeeded(), "Item should succeed after retrying")
+	assert.Equal(3, results[0].Attempts, "Item should have been tried 3 times")
+}
+
+func TestRunDoesntRetryClientErrors(t *testing.T) {
+	assert := assert.New(t)
+	InitializePool(2, 3)
+
+	results := Run([]string{"a"}, func(item string) error {
+		return &utils.HTTPError{Status: 422, Message: "invalid"}
+	})
+
+	assert.Equal(1, results[0].Attempts, "Validation errors shouldn't be retried")
+	assert.Contains(results[0].Error, "422", "Error should contain http code 422")
+}
+
+func TestRunTransportErrors(t *testing.T) {
+	assert := assert.New(t)
+	InitializePool(2, 2)
+	backoff = time.Millisecond
+
+	results := Run([]string{"a", "b"}, func(item string) error {
+		return &url.Error{Op: "Get", URL: "https://clients.concerto.io/v1/cloud/servers", Err: fmt.Errorf("connection reset")}
+	})
+
+	assert.Equal(2, Failed(results), "Both items should fail")
+	assert.Equal(2, results[1].Attempts, "Transport errors should be retried")
+}
+
+func TestRunDoesntRetryFinalErrors(t *testing.T) {
+	assert := assert.New(t)
+	InitializePool(1, 3)
+	backoff = time.Millisecond
+
+	errs := map[string]error{
+		"unsafe":    &utils.HTTPError{Status: 500, Message: "boot failed", Method: "PUT"},
+		"final":     utils.Final(&url.Error{Op: "Get", URL: "https://clients.concerto.io/v1/cloud/servers/a", Err: fmt.Errorf("timeout")}),
+		"decode":    &json.SyntaxError{},
+		"cancelled": &url.Error{Op: "Get", URL: "https://clients.concerto.io/v1/cloud/servers", Err: context.Canceled},
+		"other":     fmt.Errorf("Server a reached error state"),
+	}
+	items := []string{"unsafe", "final", "decode", "cancelled", "other"}
+	results := Run(items, func(item string) error {
+		return errs[item]
+	})
+
+	for i, r := range results {
+		assert.Equal(1, r.Attempts, "Item %s shouldn't be retried", items[i])
+	}
+}
+
+func TestRunResumesFromState(t *testing.T) {
+	assert := assert.New(t)
+	InitializePool(1, 1)
+
+	dir, err := ioutil.TempDir("", "concerto-pool")
+	assert.Nil(err, "Couldn't create temp dir")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "state.json")
+	command := []string{"cloud", "servers", "delete", "--id", "a", "--id", "b"}
+
+	// first run: b fails
+	assert.Nil(InitializeState(file, command, false), "Couldn't initialize state")
+	results := Run([]string{"a", "b"}, func(item string) error {
+		if item == "b" {
+			return &utils.HTTPError{Status: 422, Message: "invalid"}
+		}
+		return nil
+	})
+	assert.Equal(1, Failed(results), "Only b should fail")
+
+	// second run: only b is processed
+	assert.Nil(InitializeState(file, command, false), "Couldn't reload state")
+	var processed []string
+	results = Run([]string{"a", "b"}, func(item string) error {
+		processed = append(processed, item)
+		return nil
+	})
+	assert.Equal([]string{"b"}, processed, "Only failed items should be processed again")
+	assert.Equal(statusSkipped, results[0].Status, "Completed items should be skipped")
+	assert.Equal(0, Failed(results), "No item should fail")
+
+	// another command: its items aren't taken as completed
+	assert.Nil(InitializeState(file, []string{"cloud", "servers", "boot", "--id", "a", "--id", "b"}, false), "Couldn't reload state")
+	processed = nil
+	Run([]string{"a", "b"}, func(item string) error {
+		processed = append(processed, item)
+		return nil
+	})
+	assert.Len(processed, 2, "Progress of other commands should be discarded")
+
+	// restate: everything is processed
+	assert.Nil(InitializeState(file, command, true), "Couldn't restart state")
+	processed = nil
+	Run([]string{"a", "b"}, func(item string) error {
+		processed = append(processed, item)
+		return nil
+	})
+	assert.Len(processed, 2, "Every item should be processed after restate")
+	InitializeState("", nil, false)
+}
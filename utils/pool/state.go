@@ -0,0 +1,143 @@
+package pool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils"
+)
+
+const statusSkipped = "skipped"
+
+// ItemState stores the last known outcome of an item
+type ItemState struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// State tracks the progress of a bulk operation so it can be resumed
+type State struct {
+	// Scope identifies the command line the progress belongs to, so that items of another command,
+	// or of the same one with other arguments, aren't taken as completed
+	Scope string               `json:"scope"`
+	Items map[string]ItemState `json:"items"`
+
+	file string
+	mu   sync.Mutex
+}
+
+var state *State
+
+// InitializeState loads progress of previous runs of the command, given as its arguments, from file.
+// When restart is set, or file holds the progress of another command, progress is discarded and the
+// operation starts over.
+func InitializeState(file string, command []string, restart bool) error {
+	state = nil
+	if file == "" {
+		return nil
+	}
+
+	if restart && utils.FileExists(file) {
+		log.Debugf("Discarding bulk operation state at %s", file)
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+
+	s, err := loadState(file, stateScope(command))
+	if err != nil {
+		return err
+	}
+	state = s
+	return nil
+}
+
+// stateScope returns the hash of a command line
+func stateScope(command []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(command, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func loadState(file string, scope string) (*State, error) {
+	s := &State{
+		Scope: scope,
+		Items: make(map[string]ItemState),
+		file:  file,
+	}
+	if !utils.FileExists(file) {
+		return s, nil
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Scope != scope {
+		log.Warnf("Discarding bulk operation state at %s, as it was recorded by another command", file)
+		s.Scope = scope
+		s.Items = nil
+	}
+	if s.Items == nil {
+		s.Items = make(map[string]ItemState)
+	}
+	log.Debugf("Loaded state for %d items from %s", len(s.Items), file)
+	return s, nil
+}
+
+// completed returns whether item was processed in a previous run
+func (s *State) completed(item string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Items[item].Status == statusDone
+}
+
+// record stores the outcome of an item and persists the whole state
+func (s *State) record(r Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Items[r.Item] = ItemState{
+		Status:    r.Status,
+		Error:     r.Error,
+		UpdatedAt: time.Now(),
+	}
+	if err := s.save(); err != nil {
+		log.Warnf("Couldn't save bulk operation state to %s: %s", s.file, err)
+	}
+}
+
+// save writes state to a temporary file and then renames it, so that an interrupted
+// run never leaves a corrupt state file behind
+func (s *State) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.file), ".concerto-state")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.file)
+}
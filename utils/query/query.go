@@ -0,0 +1,322 @@
+// Package query filters and sorts lists of API types by their fields, named as in their JSON
+// or list headers, so that list commands can narrow down and order what they print
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Filter keeps the items whose field matches, or doesn't match when Negate is set, a value.
+// Values can hold * and ? wildcards, and are matched regardless of case
+type Filter struct {
+	Field  string
+	Value  string
+	Negate bool
+}
+
+// Order sorts items by a field
+type Order struct {
+	Field string
+	Desc  bool
+}
+
+// ParseFilter parses a filter such as state=active, name=web-* or state!=inactive
+func ParseFilter(s string) (Filter, error) {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return Filter{}, fmt.Errorf("Invalid filter %s. Please, use key=value or key!=value", s)
+	}
+	f := Filter{Field: s[:i], Value: s[i+1:]}
+	if strings.HasSuffix(f.Field, "!") {
+		f.Field, f.Negate = strings.TrimSuffix(f.Field, "!"), true
+	}
+	if f.Field == "" {
+		return Filter{}, fmt.Errorf("Invalid filter %s. Please, use key=value or key!=value", s)
+	}
+	if _, err := path.Match(strings.ToLower(f.Value), ""); err != nil {
+		return Filter{}, fmt.Errorf("Invalid filter %s: %s", s, err)
+	}
+	return f, nil
+}
+
+// Exact returns the value a field must have to match filters, and whether there's one. That is, the value
+// of a filter on field which isn't negated and holds no wildcards, so that it can be sent to APIs filtering
+// by the field. Values are matched regardless of case, so APIs filtering by them may be stricter
+func Exact(filters []Filter, field string) (string, bool) {
+	for _, f := range filters {
+		if f.Field == field && !f.Negate && !strings.ContainsAny(f.Value, `*?[\`) {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// ParseOrder parses comma separated sort fields, each optionally followed by :asc or :desc, such as state,name:desc
+func ParseOrder(s string) ([]Order, error) {
+	var orders []Order
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		o := Order{Field: field}
+		if i := strings.LastIndex(field, ":"); i >= 0 {
+			switch strings.ToLower(field[i+1:]) {
+			case "asc":
+			case "desc":
+				o.Desc = true
+			default:
+				return nil, fmt.Errorf("Invalid sort %s. Please, use field, field:asc or field:desc", field)
+			}
+			o.Field = field[:i]
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+// Apply returns the items of a slice which match every filter, sorted by orders. Items are structs, or
+// pointers to them, and fields are named by their JSON name or their header
+func Apply(items interface{}, filters []Filter, orders []Order) (interface{}, error) {
+	list := reflect.ValueOf(items)
+	if list.Kind() == reflect.Ptr {
+		list = list.Elem()
+	}
+	if list.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("Couldn't filter list. Expected slice, but received %s", list.Kind())
+	}
+	if len(filters) == 0 && len(orders) == 0 {
+		return items, nil
+	}
+
+	elem := list.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Couldn't filter list of %s", elem.Kind())
+	}
+	filterFields := make([]int, len(filters))
+	for i, f := range filters {
+		if filterFields[i] = fieldIndex(elem, f.Field); filterFields[i] < 0 {
+			return nil, unknownField(elem, f.Field)
+		}
+	}
+	orderFields := make([]int, len(orders))
+	for i, o := range orders {
+		if orderFields[i] = fieldIndex(elem, o.Field); orderFields[i] < 0 {
+			return nil, unknownField(elem, o.Field)
+		}
+	}
+
+	result := reflect.MakeSlice(list.Type(), 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		item := list.Index(i)
+		matches := true
+		for j, f := range filters {
+			if match(fieldValue(item, filterFields[j]), f.Value) == f.Negate {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			result = reflect.Append(result, item)
+		}
+	}
+
+	sort.SliceStable(result.Interface(), func(a, b int) bool {
+		for j, o := range orders {
+			c := compare(fieldValue(result.Index(a), orderFields[j]), fieldValue(result.Index(b), orderFields[j]))
+			if c == 0 {
+				continue
+			}
+			return (c < 0) != o.Desc
+		}
+		return false
+	})
+	return result.Interface(), nil
+}
+
+// ParseColumns parses comma separated columns, such as id,name,state
+func ParseColumns(s string) []string {
+	var columns []string
+	for _, column := range strings.Split(s, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// Select returns the items of a slice with only the fields named by columns, in their order, so that
+// formatters print just those. Items are structs, or pointers to them, and fields are named as in Apply
+func Select(items interface{}, columns []string) (interface{}, error) {
+	list := reflect.ValueOf(items)
+	if list.Kind() == reflect.Ptr {
+		list = list.Elem()
+	}
+	if list.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("Couldn't select columns. Expected slice, but received %s", list.Kind())
+	}
+	if len(columns) == 0 {
+		return items, nil
+	}
+
+	elem := list.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Couldn't select columns of %s", elem.Kind())
+	}
+	indexes := make([]int, len(columns))
+	fields := make([]reflect.StructField, len(columns))
+	for i, column := range columns {
+		if indexes[i] = fieldIndex(elem, column); indexes[i] < 0 {
+			return nil, unknownField(elem, column)
+		}
+		for j := 0; j < i; j++ {
+			if indexes[j] == indexes[i] {
+				return nil, fmt.Errorf("Column %s is selected twice", column)
+			}
+		}
+		f := elem.Field(indexes[i])
+		fields[i] = reflect.StructField{Name: f.Name, Type: f.Type, Tag: f.Tag}
+	}
+
+	selected := reflect.StructOf(fields)
+	result := reflect.MakeSlice(reflect.SliceOf(selected), list.Len(), list.Len())
+	for i := 0; i < list.Len(); i++ {
+		item := list.Index(i)
+		if item.Kind() == reflect.Ptr {
+			if item.IsNil() {
+				continue
+			}
+			item = item.Elem()
+		}
+		for j, index := range indexes {
+			result.Index(i).Field(j).Set(item.Field(index))
+		}
+	}
+	return result.Interface(), nil
+}
+
+// fieldIndex returns the index of the field of t named name, by its JSON name, its header or its Go
+// name, regardless of case and using underscores or spaces. It's -1 when there's none
+func fieldIndex(t reflect.Type, name string) int {
+	name = normalize(name)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		jsonName := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == normalize(jsonName) || name == normalize(f.Tag.Get("header")) || name == normalize(f.Name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.Replace(strings.Replace(name, " ", "", -1), "_", "", -1))
+}
+
+func unknownField(t reflect.Type, name string) error {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if jsonName := strings.Split(f.Tag.Get("json"), ",")[0]; f.PkgPath == "" && jsonName != "" && jsonName != "-" {
+			names = append(names, jsonName)
+		}
+	}
+	return fmt.Errorf("Unknown field %s. Please, use one of %s", name, strings.Join(names, ", "))
+}
+
+// fieldValue returns field i of item, a struct or a pointer to one, or nil when the pointer is
+func fieldValue(item reflect.Value, i int) interface{} {
+	if item.Kind() == reflect.Ptr {
+		if item.IsNil() {
+			return nil
+		}
+		item = item.Elem()
+	}
+	v := item.Field(i)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// text returns v as matched by filters. Lists are joined by commas, and other values are printed as JSON
+func text(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []string:
+		return strings.Join(value, ",")
+	case json.RawMessage:
+		return string(value)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Struct, reflect.Array:
+		data, err := json.Marshal(v)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// match returns whether v matches value, a pattern with wildcards, regardless of case
+func match(v interface{}, value string) bool {
+	s, pattern := strings.ToLower(text(v)), strings.ToLower(value)
+	if matched, err := path.Match(pattern, s); err == nil && matched {
+		return true
+	}
+	return s == pattern
+}
+
+// compare returns the order of a and b, numerically when both are numbers, and by their text otherwise.
+// Missing values go first
+func compare(a interface{}, b interface{}) int {
+	fa, aNumber := number(a)
+	fb, bNumber := number(b)
+	if aNumber && bNumber {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(text(a)), strings.ToLower(text(b)))
+}
+
+func number(v interface{}) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
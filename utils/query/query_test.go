@@ -0,0 +1,126 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type server struct {
+	ID       string   `json:"id" header:"ID"`
+	Name     string   `json:"name" header:"NAME"`
+	State    string   `json:"state" header:"STATE"`
+	Cpus     int      `json:"cpus" header:"CPUS"`
+	Services []string `json:"services" header:"SERVICE LIST"`
+}
+
+var servers = []server{
+	{ID: "1", Name: "web-1", State: "active", Cpus: 2, Services: []string{"nginx", "ntp"}},
+	{ID: "2", Name: "db-1", State: "inactive", Cpus: 16},
+	{ID: "3", Name: "web-2", State: "Active", Cpus: 4},
+}
+
+func TestParseFilter(t *testing.T) {
+	assert := assert.New(t)
+
+	f, err := ParseFilter("name=web-*")
+	assert.Nil(err, "Valid filters shouldn't fail")
+	assert.Equal(Filter{Field: "name", Value: "web-*"}, f, "Unexpected filter")
+
+	f, err = ParseFilter("state!=active")
+	assert.Nil(err, "Negated filters shouldn't fail")
+	assert.Equal(Filter{Field: "state", Value: "active", Negate: true}, f, "Unexpected filter")
+
+	f, err = ParseFilter("description=")
+	assert.Nil(err, "Filters of empty values shouldn't fail")
+	assert.Equal(Filter{Field: "description"}, f, "Unexpected filter")
+
+	for _, s := range []string{"name", "=web", "!=web", "name=[web"} {
+		_, err = ParseFilter(s)
+		assert.NotNil(err, "Filter %s should fail", s)
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	orders, err := ParseOrder("state, cpus:desc,name:asc")
+	assert.Nil(err, "Valid orders shouldn't fail")
+	assert.Equal([]Order{{Field: "state"}, {Field: "cpus", Desc: true}, {Field: "name"}}, orders, "Unexpected orders")
+
+	_, err = ParseOrder("name:down")
+	assert.NotNil(err, "Unknown directions should fail")
+}
+
+func TestApply(t *testing.T) {
+	assert := assert.New(t)
+
+	items, err := Apply(servers, nil, nil)
+	assert.Nil(err, "Lists without filters shouldn't fail")
+	assert.Equal(servers, items, "Lists without filters should be kept")
+
+	items, err = Apply(servers, []Filter{{Field: "STATE", Value: "active"}}, []Order{{Field: "cpus", Desc: true}})
+	assert.Nil(err, "Valid filters shouldn't fail")
+	assert.Equal([]server{servers[2], servers[0]}, items, "Filters should ignore case and sort numerically")
+
+	items, err = Apply(&servers, []Filter{{Field: "name", Value: "web-*"}, {Field: "service_list", Value: "*nginx*", Negate: true}}, nil)
+	assert.Nil(err, "Pointers to lists shouldn't fail")
+	assert.Equal([]server{servers[2]}, items, "Every filter should match")
+
+	pointers := []*server{&servers[0], &servers[1], &servers[2]}
+	items, err = Apply(pointers, nil, []Order{{Field: "state"}, {Field: "name", Desc: true}})
+	assert.Nil(err, "Lists of pointers shouldn't fail")
+	assert.Equal([]*server{&servers[2], &servers[0], &servers[1]}, items, "Unexpected order")
+
+	_, err = Apply(servers, []Filter{{Field: "region", Value: "eu"}}, nil)
+	assert.NotNil(err, "Unknown fields should fail")
+	assert.Contains(err.Error(), "id, name, state, cpus, services", "Error should list the fields")
+}
+
+func TestParseColumns(t *testing.T) {
+	assert.Equal(t, []string{"id", "name", "public ip"}, ParseColumns(" id,name,,public ip "), "Unexpected columns")
+	assert.Empty(t, ParseColumns(""), "No columns should be selected")
+}
+
+func TestSelect(t *testing.T) {
+	assert := assert.New(t)
+
+	items, err := Select(servers, nil)
+	assert.Nil(err, "Lists without columns shouldn't fail")
+	assert.Equal(servers, items, "Lists without columns should be kept")
+
+	items, err = Select([]*server{&servers[1], nil}, []string{"state", "ID"})
+	if assert.Nil(err, "Valid columns shouldn't fail") {
+		assert.Equal(`[{"state":"inactive","id":"2"},{"state":"","id":""}]`, marshal(t, items), "Only the columns should be kept, in their order")
+	}
+
+	_, err = Select(servers, []string{"region"})
+	assert.NotNil(err, "Unknown columns should fail")
+	_, err = Select([]server(nil), []string{"name", "NAME"})
+	assert.NotNil(err, "Repeated columns should fail, even without items")
+}
+
+func marshal(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestExact(t *testing.T) {
+	assert := assert.New(t)
+
+	filters := []Filter{{Field: "name", Value: "web-*"}, {Field: "state", Value: "inactive", Negate: true}, {Field: "type", Value: "boot"}}
+	value, ok := Exact(filters, "type")
+	assert.True(ok, "Filters matching a value should be exact")
+	assert.Equal("boot", value)
+
+	_, ok = Exact(filters, "name")
+	assert.False(ok, "Filters with wildcards shouldn't be exact")
+	_, ok = Exact(filters, "state")
+	assert.False(ok, "Negated filters shouldn't be exact")
+	_, ok = Exact(filters, "id")
+	assert.False(ok, "Fields without filters shouldn't be exact")
+}
@@ -0,0 +1,75 @@
+// Package ratelimit spaces out API requests with a token bucket, so that bulk commands don't get the
+// account throttled, and holds every request back while the API asks clients to slow down
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/flexiant/concerto/utils/cancel"
+)
+
+// now and sleep are replaced in tests
+var (
+	now   = time.Now
+	sleep = cancel.Sleep
+)
+
+// Limiter is a token bucket shared by the requests of an API client
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	until  time.Time
+}
+
+// New returns a limiter allowing rate requests per second, with bursts of up to rate requests, rounded up.
+// A rate of 0 doesn't limit requests, which are only held back by pauses
+func New(rate float64) *Limiter {
+	burst := math.Max(1, math.Ceil(rate))
+	return &Limiter{rate: rate, burst: burst, tokens: burst, last: now()}
+}
+
+// Wait blocks until a request can be sent, unless the command is cancelled meanwhile
+func (l *Limiter) Wait() error {
+	if d := l.reserve(); d > 0 && !sleep(d) {
+		return cancel.Err()
+	}
+	return nil
+}
+
+// Pause holds every request back for d, such as when the API answered with a Retry-After header
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := now().Add(d); until.After(l.until) {
+		l.until = until
+	}
+}
+
+// reserve takes a token and returns how long the request has to wait for it. Tokens can be owed, so
+// that requests waiting at the same time are sent one after another
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t := now()
+	var wait time.Duration
+	if l.until.After(t) {
+		wait = l.until.Sub(t)
+	}
+	if l.rate <= 0 {
+		return wait
+	}
+	l.tokens = math.Min(l.burst, l.tokens+t.Sub(l.last).Seconds()*l.rate)
+	l.last = t
+	l.tokens--
+	if l.tokens < 0 {
+		if owed := time.Duration(-l.tokens / l.rate * float64(time.Second)); owed > wait {
+			wait = owed
+		}
+	}
+	return wait
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock replaces the clock of the package, so that sleeping moves it forward
+func fakeClock() (*time.Time, *[]time.Duration) {
+	clock := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	var slept []time.Duration
+	now = func() time.Time { return clock }
+	sleep = func(d time.Duration) bool {
+		slept = append(slept, d)
+		clock = clock.Add(d)
+		return true
+	}
+	return &clock, &slept
+}
+
+func TestLimiterBurst(t *testing.T) {
+	assert := assert.New(t)
+	_, slept := fakeClock()
+
+	l := New(2)
+	assert.Nil(l.Wait())
+	assert.Nil(l.Wait())
+	assert.Empty(*slept, "Requests within the burst shouldn't wait")
+
+	assert.Nil(l.Wait())
+	assert.Equal([]time.Duration{500 * time.Millisecond}, *slept, "Requests beyond the burst should be spaced out")
+}
+
+func TestLimiterRefill(t *testing.T) {
+	assert := assert.New(t)
+	clock, slept := fakeClock()
+
+	l := New(1)
+	assert.Nil(l.Wait())
+	*clock = clock.Add(time.Hour)
+	assert.Nil(l.Wait())
+	assert.Nil(l.Wait())
+	assert.Equal([]time.Duration{time.Second}, *slept, "Idle time shouldn't build up more tokens than the burst")
+}
+
+func TestLimiterPause(t *testing.T) {
+	assert := assert.New(t)
+	_, slept := fakeClock()
+
+	l := New(0)
+	assert.Nil(l.Wait())
+	assert.Empty(*slept, "Unlimited requests shouldn't wait")
+
+	l.Pause(3 * time.Second)
+	l.Pause(time.Second)
+	assert.Nil(l.Wait())
+	assert.Equal([]time.Duration{3 * time.Second}, *slept, "Requests should wait for the longest pause")
+
+	assert.Nil(l.Wait())
+	assert.Len(*slept, 1, "Pauses should end")
+}
@@ -0,0 +1,214 @@
+package release
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils"
+)
+
+// DefaultURL is where releases are published, as the setup script downloads them
+const DefaultURL = "https://get.concerto.io"
+
+// PublicKey is the base64 DER encoded ECDSA P-256 key verifying release signatures. It's set
+// at build time with -ldflags "-X github.com/flexiant/concerto/utils/release.PublicKey=MFkw..."
+var PublicKey = ""
+
+// maxBinarySize limits downloads, so that a wrong URL doesn't fill the disk
+const maxBinarySize = 256 << 20
+
+// maxManifestSize limits the size of the latest release manifest
+const maxManifestSize = 64 << 10
+
+// Release is a published CLI version for the current platform
+type Release struct {
+	Version      string
+	URL          string
+	SignatureURL string
+	// Digest is the hex encoded SHA-256 of the binary, as listed in the signed manifest
+	Digest string
+}
+
+// Latest returns the latest release published under baseURL. Releases are named as the
+// setup script expects, such as concerto.x64.linux, and signed in a .sig file next to them.
+// The latest manifest, signed in latest.sig, holds the version in its first line, followed by
+// the SHA-256 of each binary as sha256sum writes them, so that binaries of other versions can't
+// be passed off as the latest one
+func Latest(baseURL string) (*Release, error) {
+	baseURL = strings.TrimRight(baseURL, "/")
+	data, err := get(baseURL+"/latest", maxManifestSize)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get latest version: %s", err)
+	}
+	signature, err := get(baseURL+"/latest.sig", 1024)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get signature of latest version: %s", err)
+	}
+	if err = Verify(data, signature, PublicKey); err != nil {
+		return nil, fmt.Errorf("Couldn't verify latest version: %s", err)
+	}
+	artifact := ArtifactName(runtime.GOOS, runtime.GOARCH)
+	version, digest, err := parseManifest(string(data), artifact)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get latest version: %s", err)
+	}
+	url := fmt.Sprintf("%s/%s", baseURL, artifact)
+	return &Release{Version: version, URL: url, SignatureURL: url + ".sig", Digest: digest}, nil
+}
+
+// parseManifest returns the version of the latest manifest, and the digest it lists for artifact
+func parseManifest(manifest string, artifact string) (version string, digest string, err error) {
+	lines := strings.Split(strings.TrimSpace(manifest), "\n")
+	version = strings.TrimSpace(lines[0])
+	if version == "" {
+		return "", "", fmt.Errorf("empty manifest")
+	}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == artifact {
+			return version, strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", "", fmt.Errorf("%s isn't published for version %s", artifact, version)
+}
+
+// ArtifactName returns the name of the binary published for a platform
+func ArtifactName(goos string, goarch string) string {
+	arch := goarch
+	if arch == "amd64" {
+		arch = "x64"
+	}
+	name := fmt.Sprintf("concerto.%s.%s", arch, goos)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Download returns the binary of r, once its signature has been verified
+func (r *Release) Download() ([]byte, error) {
+	log.Debugf("Downloading %s", r.URL)
+	binary, err := get(r.URL, maxBinarySize)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't download %s: %s", r.URL, err)
+	}
+	signature, err := get(r.SignatureURL, 1024)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't download signature %s: %s", r.SignatureURL, err)
+	}
+	if err = Verify(binary, signature, PublicKey); err != nil {
+		return nil, err
+	}
+	if digest := sha256.Sum256(binary); hex.EncodeToString(digest[:]) != r.Digest {
+		return nil, fmt.Errorf("%s isn't the binary of version %s", r.URL, r.Version)
+	}
+	return binary, nil
+}
+
+// Verify checks a detached ASN.1 ECDSA signature of the SHA-256 of data, as written by
+// openssl dgst -sha256 -sign. Signatures may be raw or base64 encoded
+func Verify(data []byte, signature []byte, publicKey string) error {
+	if publicKey == "" {
+		return fmt.Errorf("This build has no release key, so downloads can't be verified. Please, update with the setup script")
+	}
+	der, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return fmt.Errorf("Invalid release key: %s", err)
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return fmt.Errorf("Invalid release key: %s", err)
+	}
+	ecKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("Invalid release key: expected an ECDSA key")
+	}
+
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+		signature = decoded
+	}
+	var sig struct {
+		R, S *big.Int
+	}
+	if _, err = asn1.Unmarshal(signature, &sig); err != nil {
+		return fmt.Errorf("Invalid release signature: %s", err)
+	}
+	digest := sha256.Sum256(data)
+	if !ecdsa.Verify(ecKey, digest[:], sig.R, sig.S) {
+		return fmt.Errorf("Release signature doesn't match. The download may have been tampered with")
+	}
+	return nil
+}
+
+// Executable returns the path of the running binary, resolving symbolic links
+func Executable() (string, error) {
+	path, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		return "", err
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// Install replaces the binary at path with binary, keeping the current one as path.bak.
+// The new binary is written next to the current one, so that it's renamed in place
+func Install(path string, binary []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := ioutil.TempFile(dir, ".concerto-update")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+
+	backup := path + ".bak"
+	os.Remove(backup)
+	// running binaries can be renamed, even on windows, but not overwritten
+	if err = os.Rename(path, backup); err != nil {
+		return fmt.Errorf("Couldn't keep current binary as %s: %s", backup, err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		if rerr := os.Rename(backup, path); rerr != nil {
+			log.Errorf("Couldn't restore %s from %s: %s", path, backup, rerr)
+		}
+		return fmt.Errorf("Couldn't install new binary: %s", err)
+	}
+	return nil
+}
+
+func get(url string, limit int64) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server responded %s", resp.Status)
+	}
+	return utils.ReadBody(resp.Body, limit)
+}
@@ -0,0 +1,146 @@
+package release
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err, "Couldn't generate key")
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.Nil(t, err, "Couldn't encode key")
+	return key, base64.StdEncoding.EncodeToString(der)
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, data []byte) []byte {
+	digest := sha256.Sum256(data)
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	assert.Nil(t, err, "Couldn't sign")
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	assert.Nil(t, err, "Couldn't encode signature")
+	return sig
+}
+
+func TestArtifactName(t *testing.T) {
+	assert.Equal(t, "concerto.x64.linux", ArtifactName("linux", "amd64"), "Unexpected name")
+	assert.Equal(t, "concerto.x64.windows.exe", ArtifactName("windows", "amd64"), "Windows binaries should keep the extension")
+	assert.Equal(t, "concerto.arm64.darwin", ArtifactName("darwin", "arm64"), "Unexpected name")
+}
+
+func TestVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	key, publicKey := testKey(t)
+	data := []byte("concerto binary")
+	sig := sign(t, key, data)
+
+	assert.Nil(Verify(data, sig, publicKey), "Valid signature should pass")
+	assert.Nil(Verify(data, []byte(base64.StdEncoding.EncodeToString(sig)+"\n"), publicKey), "Base64 signatures should pass")
+	assert.NotNil(Verify([]byte("tampered binary"), sig, publicKey), "Tampered data should fail")
+	assert.NotNil(Verify(data, sig, ""), "Missing key should fail")
+
+	_, otherKey := testKey(t)
+	assert.NotNil(Verify(data, sig, otherKey), "Signature of another key should fail")
+}
+
+func TestLatestDownload(t *testing.T) {
+	assert := assert.New(t)
+
+	key, publicKey := testKey(t)
+	binary := []byte("new concerto binary")
+	sig := sign(t, key, binary)
+	artifact := "/" + ArtifactName(runtime.GOOS, runtime.GOARCH)
+	digest := sha256.Sum256(binary)
+	manifest := []byte(fmt.Sprintf("0.4.0\n%x  %s\n", digest, artifact[1:]))
+	manifestSig := sign(t, key, manifest)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			w.Write(manifest)
+		case "/latest.sig":
+			w.Write(manifestSig)
+		case artifact:
+			w.Write(binary)
+		case artifact + ".sig":
+			w.Write(sig)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	PublicKey = publicKey
+	defer func() { PublicKey = "" }()
+
+	r, err := Latest(ts.URL + "/")
+	assert.Nil(err, "Couldn't get latest release")
+	assert.Equal("0.4.0", r.Version, "Unexpected version")
+
+	data, err := r.Download()
+	assert.Nil(err, "Couldn't download release")
+	assert.Equal(binary, data, "Unexpected binary")
+
+	r.SignatureURL = ts.URL + "/missing.sig"
+	_, err = r.Download()
+	assert.NotNil(err, "Missing signatures should fail")
+
+	r.SignatureURL = r.URL + ".sig"
+	r.Digest = fmt.Sprintf("%x", sha256.Sum256([]byte("old concerto binary")))
+	_, err = r.Download()
+	assert.NotNil(err, "Binaries of other versions should fail")
+
+	manifest = []byte("0.5.0\n")
+	_, err = Latest(ts.URL)
+	assert.NotNil(err, "Manifests not matching their signature should fail")
+}
+
+func TestParseManifest(t *testing.T) {
+	assert := assert.New(t)
+
+	version, digest, err := parseManifest("0.4.0\nAB12  concerto.x64.linux\ncd34 *concerto.x64.windows.exe\n", "concerto.x64.windows.exe")
+	assert.Nil(err, "Couldn't parse manifest")
+	assert.Equal("0.4.0", version, "Unexpected version")
+	assert.Equal("cd34", digest, "Unexpected digest")
+
+	_, _, err = parseManifest("0.4.0\nab12  concerto.x64.linux\n", "concerto.arm64.darwin")
+	assert.NotNil(err, "Unpublished binaries should fail")
+	_, _, err = parseManifest("\n", "concerto.x64.linux")
+	assert.NotNil(err, "Empty manifests should fail")
+}
+
+func TestInstall(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "release")
+	assert.Nil(err, "Couldn't create temporary directory")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "concerto")
+	assert.Nil(ioutil.WriteFile(path, []byte("old"), 0755), "Couldn't write binary")
+
+	assert.Nil(Install(path, []byte("new")), "Couldn't install binary")
+	current, _ := ioutil.ReadFile(path)
+	backup, _ := ioutil.ReadFile(path + ".bak")
+	assert.Equal("new", string(current), "Binary should be replaced")
+	assert.Equal("old", string(backup), "Previous binary should be kept")
+
+	info, err := os.Stat(path)
+	assert.Nil(err, "Couldn't stat binary")
+	assert.NotZero(info.Mode()&0100, "Binary should be executable")
+}
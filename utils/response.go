@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// DefaultMaxResponseSize is the biggest response kept in memory when no limit has been configured
+const DefaultMaxResponseSize int64 = 64 << 20
+
+// ResponseTooLargeError is returned when a response doesn't fit in the configured limit
+type ResponseTooLargeError struct {
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("Response is larger than %d bytes. Please, increase --max-response-size", e.Limit)
+}
+
+// ParseSize parses sizes such as 1024, 512KB, 64MB or 1GB
+func ParseSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := int64(1)
+	for suffix, m := range map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30} {
+		if strings.HasSuffix(s, suffix) {
+			multiplier = m
+			s = strings.TrimSuffix(s, suffix)
+			break
+		}
+	}
+	s = strings.TrimSuffix(s, "B")
+
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("Invalid size %s. Please, use a size such as 512KB or 64MB", size)
+	}
+	return n * multiplier, nil
+}
+
+// ReadBody reads a response body, failing when it's bigger than limit
+func ReadBody(body io.Reader, limit int64) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, &ResponseTooLargeError{Limit: limit}
+	}
+	return data, nil
+}
+
+// SpillBody returns a reader with the contents of body. Contents are kept in memory up to limit,
+// bigger bodies are written to a temporary file which is removed when the reader is closed.
+func SpillBody(body io.Reader, limit int64) (io.ReadCloser, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) <= limit {
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	tmp, err := ioutil.TempFile("", "concerto-response")
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Response larger than %d bytes, spilling it to %s", limit, tmp.Name())
+
+	spilled := &spillFile{tmp}
+	if _, err = tmp.Write(data); err != nil {
+		spilled.Close()
+		return nil, err
+	}
+	if _, err = io.Copy(tmp, body); err != nil {
+		spilled.Close()
+		return nil, err
+	}
+	if _, err = tmp.Seek(0, 0); err != nil {
+		spilled.Close()
+		return nil, err
+	}
+	return spilled, nil
+}
+
+// spillFile is a temporary file removed on close
+type spillFile struct {
+	*os.File
+}
+
+func (f *spillFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
+
+// DecodeJSONList decodes a JSON array calling fn for each element, so that big lists
+// can be processed without loading all of them in memory
+func DecodeJSONList(r io.Reader, fn func(item json.RawMessage) error) error {
+	dec := json.NewDecoder(r)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("Expected JSON array, but received %v", tok)
+	}
+
+	for dec.More() {
+		var item json.RawMessage
+		if err = dec.Decode(&item); err != nil {
+			return err
+		}
+		if err = fn(item); err != nil {
+			return err
+		}
+	}
+
+	_, err = dec.Token()
+	return err
+}
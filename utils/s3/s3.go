@@ -0,0 +1,199 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	defaultRegion = "us-east-1"
+	algorithm     = "AWS4-HMAC-SHA256"
+	// unsignedPayload lets bodies be streamed without hashing them first
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// Config stores the endpoint and credentials of an S3 compatible storage.
+// An empty endpoint means Amazon S3, which is reached using virtual hosted buckets
+type Config struct {
+	Endpoint     string
+	Region       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+// Location is where artifacts are uploaded
+type Location struct {
+	Bucket string
+	Prefix string
+}
+
+// ParseLocation parses an s3://bucket/prefix URL
+func ParseLocation(s string) (*Location, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "s3" || u.Host == "" {
+		return nil, fmt.Errorf("Invalid upload location %s. Please, use s3://bucket/prefix", s)
+	}
+	return &Location{Bucket: u.Host, Prefix: strings.Trim(u.Path, "/")}, nil
+}
+
+// Key returns the object key of name under the location prefix
+func (l *Location) Key(name string) string {
+	if l.Prefix == "" {
+		return name
+	}
+	return l.Prefix + "/" + name
+}
+
+// String returns the location as an s3:// URL
+func (l *Location) String() string {
+	return fmt.Sprintf("s3://%s/%s", l.Bucket, l.Prefix)
+}
+
+// Client uploads objects to an S3 compatible storage
+type Client struct {
+	config Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewClient returns a client for the given storage
+func NewClient(config Config) (*Client, error) {
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("Missing S3 credentials. Please, configure them in the s3 element of the configuration file or AWS environment variables")
+	}
+	if config.Region == "" {
+		config.Region = defaultRegion
+	}
+	return &Client{config: config, client: &http.Client{}, now: time.Now}, nil
+}
+
+// Upload streams size bytes of body to key in bucket
+func (c *Client) Upload(bucket string, key string, body io.Reader, size int64) error {
+	req, err := http.NewRequest("PUT", c.objectURL(bucket, key), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	c.sign(req, unsignedPayload)
+
+	log.Debugf("Uploading %d bytes to s3://%s/%s", size, bucket, key)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("Upload to s3://%s/%s failed with %s: %s", bucket, key, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// UploadFile streams a local file to key in bucket
+func (c *Client) UploadFile(bucket string, key string, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return c.Upload(bucket, key, f, info.Size())
+}
+
+// objectURL uses path style addressing for custom endpoints, which most S3 compatible storages require
+func (c *Client) objectURL(bucket string, key string) string {
+	if c.config.Endpoint == "" {
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, c.config.Region, escapePath(key))
+	}
+	return fmt.Sprintf("%s/%s/%s", strings.TrimRight(c.config.Endpoint, "/"), bucket, escapePath(key))
+}
+
+// sign adds AWS Signature Version 4 headers to req. Every header already set, and host, is signed
+func (c *Client) sign(req *http.Request, payloadHash string) {
+	t := c.now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.config.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders string
+	for _, name := range names {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, c.config.Region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{algorithm, amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.config.SecretKey), date)
+	key = hmacSHA256(key, c.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, c.config.AccessKey, scope, signedHeaders, signature))
+}
+
+// escapePath encodes every segment of an object key as S3 expects
+func escapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+	}
+	return path.Join(segments...)
+}
+
+func hashHex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,43 @@
+package shutdown
+
+import (
+	"os"
+	"sync"
+)
+
+var (
+	hooks   []func()
+	hooksMu sync.Mutex
+	once    sync.Once
+	code    int
+)
+
+// AddHook registers fn to be run before the process finishes, either normally or through Exit
+func AddHook(fn func()) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, fn)
+}
+
+// RunHooks runs registered hooks. Hooks are run only once, no matter how many times it's called
+func RunHooks() {
+	once.Do(func() {
+		hooksMu.Lock()
+		defer hooksMu.Unlock()
+		for _, fn := range hooks {
+			fn()
+		}
+	})
+}
+
+// Exit runs registered hooks and finishes the process with exitCode
+func Exit(exitCode int) {
+	code = exitCode
+	RunHooks()
+	os.Exit(exitCode)
+}
+
+// Code returns the code the process is finishing with. It's zero unless finished through Exit
+func Code() int {
+	return code
+}
@@ -0,0 +1,113 @@
+package ssh
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Options are the settings used to reach a server with OpenSSH clients
+type Options struct {
+	User    string
+	Host    string
+	Bastion string
+
+	// IdentityFile is the private key used to authenticate. When empty, keys are taken from ssh-agent
+	IdentityFile string
+
+	// KnownHostsFile is where host keys are verified and stored
+	KnownHostsFile string
+
+	// StrictHostKeyChecking refuses to connect to hosts not found in KnownHostsFile.
+	// Otherwise, keys of unknown hosts are added on first connection
+	StrictHostKeyChecking bool
+
+	// BatchMode prevents ssh from asking for passwords or passphrases
+	BatchMode bool
+}
+
+// Target returns the destination as understood by ssh
+func (o *Options) Target() string {
+	if o.User == "" {
+		return o.Host
+	}
+	return fmt.Sprintf("%s@%s", o.User, o.Host)
+}
+
+// commonArgs are the arguments shared by ssh and scp
+func (o *Options) commonArgs() []string {
+	var args []string
+	if o.Bastion != "" {
+		args = append(args, "-J", o.Bastion)
+	}
+	if o.IdentityFile != "" {
+		args = append(args, "-i", o.IdentityFile, "-o", "IdentitiesOnly=yes")
+	}
+	if o.KnownHostsFile != "" {
+		args = append(args, "-o", fmt.Sprintf("UserKnownHostsFile=%s", o.KnownHostsFile))
+	}
+	if o.StrictHostKeyChecking {
+		args = append(args, "-o", "StrictHostKeyChecking=yes")
+	} else {
+		args = append(args, "-o", "StrictHostKeyChecking=accept-new")
+	}
+	if o.BatchMode {
+		args = append(args, "-o", "BatchMode=yes")
+	}
+	return args
+}
+
+// SSHArgs returns the ssh arguments to open a session, or run command when given
+func SSHArgs(o *Options, command []string) []string {
+	args := o.commonArgs()
+	if len(command) > 0 {
+		args = append(args, "-T")
+	}
+	args = append(args, o.Target())
+	return append(args, command...)
+}
+
+// SCPArgs returns the scp arguments to copy paths. Paths starting with a colon refer to the server
+func SCPArgs(o *Options, paths []string) []string {
+	args := append(o.commonArgs(), "-r")
+	for _, p := range paths {
+		if strings.HasPrefix(p, ":") {
+			p = o.Target() + p
+		}
+		args = append(args, p)
+	}
+	return args
+}
+
+// CheckAgent returns an error when no ssh-agent is reachable
+func CheckAgent() error {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return fmt.Errorf("SSH_AUTH_SOCK is not set. Please, start ssh-agent and add your key with ssh-add")
+	}
+	if _, err := os.Stat(sock); err != nil {
+		return fmt.Errorf("Couldn't reach ssh-agent at %s: %s", sock, err)
+	}
+	return nil
+}
+
+// WriteIdentity stores a private key in dir, readable only by the current user, and returns its path
+func WriteIdentity(dir string, name string, key string) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("SSH profile %s has no private key. Please, use ssh-agent", name)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if !strings.HasSuffix(key, "\n") {
+		key += "\n"
+	}
+	if err := ioutil.WriteFile(path, []byte(key), 0600); err != nil {
+		return "", err
+	}
+	// WriteFile keeps the mode of existing files
+	return path, os.Chmod(path, 0600)
+}
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/flexiant/concerto/utils/metrics"
+)
+
+// maxSlowestEndpoints is the number of endpoints shown in the stats summary
+const maxSlowestEndpoints = 5
+
+// idSegment matches path segments holding identifiers: object IDs, UUIDs and numbers
+var idSegment = regexp.MustCompile(`^([0-9a-fA-F]{24}|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9]+)$`)
+
+// endpointKey returns the endpoint an API call is accounted for, with identifiers in its path replaced by
+// :id, so that calls to the same endpoint for different resources add up
+func endpointKey(method string, path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if idSegment.MatchString(s) {
+			segments[i] = ":id"
+		}
+	}
+	return method + " " + strings.Join(segments, "/")
+}
+
+// endpointStats stores accumulated figures for an endpoint
+type endpointStats struct {
+	Endpoint string
+	Calls    int
+	Total    time.Duration
+	Max      time.Duration
+}
+
+// apiStats stores figures of the API calls made during a command
+type apiStats struct {
+	mu        sync.Mutex
+	calls     int
+	sent      int64
+	received  int64
+	endpoints map[string]*endpointStats
+}
+
+var stats = &apiStats{endpoints: make(map[string]*endpointStats)}
+
+var (
+	apiRequests        = metrics.NewCounter("concerto_api_requests_total", "API calls made, by method and status code.", "method", "code")
+	apiRequestDuration = metrics.NewSummary("concerto_api_request_duration_seconds", "Time spent in API calls, by method.", "method")
+)
+
+// record accounts for an API call
+func (s *apiStats) record(endpoint string, sent int64, received int64, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	s.sent += sent
+	s.received += received
+
+	e, ok := s.endpoints[endpoint]
+	if !ok {
+		e = &endpointStats{Endpoint: endpoint}
+		s.endpoints[endpoint] = e
+	}
+	e.Calls++
+	e.Total += elapsed
+	if elapsed > e.Max {
+		e.Max = elapsed
+	}
+}
+
+// PrintStats writes a summary of the API calls made so far
+func PrintStats(out io.Writer) {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	fmt.Fprintf(out, "API calls: %d\n", stats.calls)
+	fmt.Fprintf(out, "Bytes sent: %d\n", stats.sent)
+	fmt.Fprintf(out, "Bytes received: %d\n", stats.received)
+	if len(stats.endpoints) == 0 {
+		return
+	}
+
+	endpoints := make([]*endpointStats, 0, len(stats.endpoints))
+	for _, e := range stats.endpoints {
+		endpoints = append(endpoints, e)
+	}
+	sort.Sort(byMaxDuration(endpoints))
+	if len(endpoints) > maxSlowestEndpoints {
+		endpoints = endpoints[:maxSlowestEndpoints]
+	}
+
+	fmt.Fprintln(out, "Slowest endpoints:")
+	w := tabwriter.NewWriter(out, 15, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "ENDPOINT\tCALLS\tMAX\tAVERAGE")
+	for _, e := range endpoints {
+		avg := e.Total / time.Duration(e.Calls)
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", e.Endpoint, e.Calls, roundDuration(e.Max), roundDuration(avg))
+	}
+	w.Flush()
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	return d - d%time.Millisecond
+}
+
+// byMaxDuration sorts endpoints from slowest to fastest
+type byMaxDuration []*endpointStats
+
+func (a byMaxDuration) Len() int           { return len(a) }
+func (a byMaxDuration) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byMaxDuration) Less(i, j int) bool { return a[i].Max > a[j].Max }
+
+// statsBody accounts for the bytes read from a response body, and records the call once closed
+type statsBody struct {
+	io.ReadCloser
+	endpoint string
+	sent     int64
+	received int64
+	start    time.Time
+	once     sync.Once
+}
+
+func (b *statsBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.received += int64(n)
+	return n, err
+}
+
+func (b *statsBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		stats.record(b.endpoint, b.sent, b.received, time.Since(b.start))
+	})
+	return err
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointKey(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("GET /v1/cloud/servers/:id", endpointKey("GET", "/v1/cloud/servers/5641e7497aa4b1a67800006c"), "Object IDs should be collapsed")
+	assert.Equal("PUT /v1/blueprint/templates/:id/scripts/:id", endpointKey("PUT", "/v1/blueprint/templates/5641e7497aa4b1a67800006c/scripts/5641e7497aa4b1a67800006d"), "Every ID should be collapsed")
+	assert.Equal("DELETE /v1/dns/records/:id", endpointKey("DELETE", "/v1/dns/records/123"), "Numeric IDs should be collapsed")
+	assert.Equal("GET /v1/jobs/:id", endpointKey("GET", "/v1/jobs/0f8fad5b-d9cb-469f-a165-70867728950e"), "UUIDs should be collapsed")
+	assert.Equal("GET /v1/cloud/servers", endpointKey("GET", "/v1/cloud/servers"), "Paths without IDs should be kept")
+	assert.Equal("GET /v1/admin/reports/dead-beef", endpointKey("GET", "/v1/admin/reports/dead-beef"), "Names shouldn't be collapsed")
+}
+
+func TestPrintStatsGroupsEndpoints(t *testing.T) {
+	defer func(s *apiStats) { stats = s }(stats)
+	stats = &apiStats{endpoints: make(map[string]*endpointStats)}
+
+	for _, id := range []string{"5641e7497aa4b1a67800006c", "5641e7497aa4b1a67800006d"} {
+		stats.record(endpointKey("GET", "/v1/cloud/servers/"+id), 0, 10, time.Millisecond)
+	}
+
+	var out bytes.Buffer
+	PrintStats(&out)
+	assert.Len(t, stats.endpoints, 1, "Calls for different servers should add up")
+	assert.Contains(t, out.String(), "GET /v1/cloud/servers/:id", "Endpoint should be printed with placeholders")
+	assert.NotContains(t, out.String(), "5641e7497aa4b1a67800006c", "IDs shouldn't be printed")
+}
@@ -0,0 +1,154 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// Instance is a server described by a terraform plan or state. Template, workspace and
+// SSH profile are only known for concerto_server resources
+type Instance struct {
+	Address    string
+	Type       string
+	Name       string
+	Fqdn       string
+	Template   string
+	Plan       string
+	Workspace  string
+	SSHProfile string
+	Labels     []string
+}
+
+// instanceMapping tells which values of a resource type hold the name, size and labels of an instance
+type instanceMapping struct {
+	name string
+	plan string
+	tags string
+}
+
+// instanceTypes are the resource types read as instances. Dotted paths read nested maps, such as AWS tags
+var instanceTypes = map[string]instanceMapping{
+	"concerto_server":                 {name: "name", plan: "server_plan_id", tags: "labels"},
+	"aws_instance":                    {name: "tags.Name", plan: "instance_type"},
+	"google_compute_instance":         {name: "name", plan: "machine_type"},
+	"azurerm_linux_virtual_machine":   {name: "name", plan: "size"},
+	"azurerm_windows_virtual_machine": {name: "name", plan: "size"},
+	"digitalocean_droplet":            {name: "name", plan: "size", tags: "tags"},
+	"openstack_compute_instance_v2":   {name: "name", plan: "flavor_name"},
+}
+
+// planFile is the subset of terraform show -json output read, either from a plan or from a state
+type planFile struct {
+	PlannedValues *planValues `json:"planned_values"`
+	Values        *planValues `json:"values"`
+}
+
+type planValues struct {
+	RootModule planModule `json:"root_module"`
+}
+
+type planModule struct {
+	Resources    []planResource `json:"resources"`
+	ChildModules []planModule   `json:"child_modules"`
+}
+
+type planResource struct {
+	Address string                 `json:"address"`
+	Mode    string                 `json:"mode"`
+	Type    string                 `json:"type"`
+	Name    string                 `json:"name"`
+	Index   interface{}            `json:"index"`
+	Values  map[string]interface{} `json:"values"`
+}
+
+// LoadInstances reads the instances of a plan or state exported with terraform show -json.
+// It returns the instances found and the number of skipped resources of other types
+func LoadInstances(file string) ([]Instance, int, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	var plan planFile
+	if err = json.Unmarshal(data, &plan); err != nil {
+		return nil, 0, fmt.Errorf("Couldn't parse %s: %s", file, err)
+	}
+	values := plan.PlannedValues
+	if values == nil {
+		values = plan.Values
+	}
+	if values == nil {
+		return nil, 0, fmt.Errorf("Couldn't find planned values in %s. Please, use the output of terraform show -json", file)
+	}
+
+	var instances []Instance
+	skipped := 0
+	for _, r := range moduleResources(values.RootModule) {
+		mapping, ok := instanceTypes[r.Type]
+		if !ok || r.Mode == "data" {
+			skipped++
+			continue
+		}
+		instances = append(instances, newInstance(r, mapping))
+	}
+	sort.Sort(byAddress(instances))
+	return instances, skipped, nil
+}
+
+// moduleResources returns resources of m and its child modules
+func moduleResources(m planModule) []planResource {
+	resources := m.Resources
+	for _, child := range m.ChildModules {
+		resources = append(resources, moduleResources(child)...)
+	}
+	return resources
+}
+
+func newInstance(r planResource, mapping instanceMapping) Instance {
+	i := Instance{
+		Address: r.Address,
+		Type:    r.Type,
+		Name:    stringValue(r.Values, mapping.name),
+		Plan:    stringValue(r.Values, mapping.plan),
+	}
+	if i.Name == "" {
+		i.Name = ResourceName(r.Name)
+		if r.Index != nil {
+			i.Name = fmt.Sprintf("%s-%v", i.Name, r.Index)
+		}
+	}
+	if r.Type == "concerto_server" {
+		i.Fqdn = stringValue(r.Values, "fqdn")
+		i.Template = stringValue(r.Values, "template_id")
+		i.Workspace = stringValue(r.Values, "workspace_id")
+		i.SSHProfile = stringValue(r.Values, "ssh_profile_id")
+	}
+	if mapping.tags != "" {
+		if tags, ok := r.Values[mapping.tags].([]interface{}); ok {
+			for _, t := range tags {
+				if s, ok := t.(string); ok {
+					i.Labels = append(i.Labels, s)
+				}
+			}
+		}
+	}
+	return i
+}
+
+// stringValue returns the string at path, where a dot separates a key of a nested map
+func stringValue(values map[string]interface{}, path string) string {
+	if i := strings.Index(path, "."); i >= 0 {
+		nested, _ := values[path[:i]].(map[string]interface{})
+		return stringValue(nested, path[i+1:])
+	}
+	s, _ := values[path].(string)
+	return s
+}
+
+type byAddress []Instance
+
+func (a byAddress) Len() int           { return len(a) }
+func (a byAddress) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byAddress) Less(i, j int) bool { return a[i].Address < a[j].Address }
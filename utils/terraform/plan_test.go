@@ -0,0 +1,56 @@
+package terraform
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPlan = `{
+  "format_version": "1.2",
+  "planned_values": {
+    "root_module": {
+      "resources": [
+        {"address": "aws_instance.web[0]", "mode": "managed", "type": "aws_instance", "name": "web", "index": 0,
+         "values": {"instance_type": "t3.small", "tags": {"Name": "web-01"}}},
+        {"address": "aws_instance.web[1]", "mode": "managed", "type": "aws_instance", "name": "web", "index": 1,
+         "values": {"instance_type": "t3.small", "tags": null}},
+        {"address": "aws_security_group.web", "mode": "managed", "type": "aws_security_group", "name": "web", "values": {}},
+        {"address": "data.aws_ami.ubuntu", "mode": "data", "type": "aws_ami", "name": "ubuntu", "values": {}}
+      ],
+      "child_modules": [
+        {"resources": [
+          {"address": "module.db.concerto_server.db", "mode": "managed", "type": "concerto_server", "name": "db",
+           "values": {"name": "db", "fqdn": "db.example.com", "template_id": "5643", "server_plan_id": "5a1f",
+                      "workspace_id": "9b01", "labels": ["database", "prod"]}}
+        ]}
+      ]
+    }
+  }
+}`
+
+func TestLoadInstances(t *testing.T) {
+	assert := assert.New(t)
+
+	f, err := ioutil.TempFile("", "plan")
+	assert.Nil(err, "Couldn't create plan file")
+	defer os.Remove(f.Name())
+	f.WriteString(testPlan)
+	f.Close()
+
+	instances, skipped, err := LoadInstances(f.Name())
+	assert.Nil(err, "Couldn't load plan")
+	assert.Equal(2, skipped, "Resources other than instances should be skipped")
+	assert.Equal([]Instance{
+		{Address: "aws_instance.web[0]", Type: "aws_instance", Name: "web-01", Plan: "t3.small"},
+		{Address: "aws_instance.web[1]", Type: "aws_instance", Name: "web-1", Plan: "t3.small"},
+		{Address: "module.db.concerto_server.db", Type: "concerto_server", Name: "db", Fqdn: "db.example.com",
+			Template: "5643", Plan: "5a1f", Workspace: "9b01", Labels: []string{"database", "prod"}},
+	}, instances, "Unexpected instances")
+
+	ioutil.WriteFile(f.Name(), []byte(`{"format_version": "1.2"}`), 0600)
+	_, _, err = LoadInstances(f.Name())
+	assert.NotNil(err, "Files without values should fail")
+}
@@ -0,0 +1,223 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Resource is a terraform resource describing an existing Concerto object
+type Resource struct {
+	Type       string
+	Name       string
+	ID         string
+	Attributes []Attribute
+	Blocks     []Block
+}
+
+// Attribute is a name/value pair of a resource or block.
+// Supported values are string, int, bool, []string, *json.RawMessage and Reference
+type Attribute struct {
+	Name  string
+	Value interface{}
+}
+
+// Reference is an expression referring to an attribute of another resource, such as concerto_template.web.id,
+// written as is so that terraform orders the resources
+type Reference string
+
+// Ref returns the reference to the id of resource r
+func Ref(r Resource) Reference {
+	return Reference(fmt.Sprintf("%s.%s.id", r.Type, r.Name))
+}
+
+// Block is a nested block of a resource, such as a firewall rule
+type Block struct {
+	Name       string
+	Attributes []Attribute
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// ResourceName converts a Concerto object name into a valid terraform identifier
+func ResourceName(name string) string {
+	s := invalidNameChars.ReplaceAllString(strings.ToLower(strings.TrimSpace(name)), "_")
+	s = strings.Trim(s, "_")
+	if s == "" {
+		return "unnamed"
+	}
+	if s[0] >= '0' && s[0] <= '9' {
+		s = "_" + s
+	}
+	return s
+}
+
+// UniqueNames makes resource names unique within each resource type, adding a suffix to repeated ones.
+// The first resource with a name keeps it, and suffixes skip names taken by other resources
+func UniqueNames(resources []Resource) {
+	taken := make(map[string]bool)
+	for _, r := range resources {
+		taken[r.Type+"."+r.Name] = true
+	}
+	kept := make(map[string]bool)
+	for i := range resources {
+		key := resources[i].Type + "." + resources[i].Name
+		if !kept[key] {
+			kept[key] = true
+			continue
+		}
+		for n := 2; ; n++ {
+			name := fmt.Sprintf("%s_%d", resources[i].Name, n)
+			if !taken[resources[i].Type+"."+name] {
+				resources[i].Name = name
+				break
+			}
+		}
+		key = resources[i].Type + "." + resources[i].Name
+		taken[key] = true
+		kept[key] = true
+	}
+}
+
+// WriteResources writes resources in HCL
+func WriteResources(w io.Writer, resources []Resource) error {
+	buf := new(bytes.Buffer)
+	for i, r := range resources {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "resource %q %q {\n", r.Type, r.Name)
+		if err := writeAttributes(buf, r.Attributes, "  "); err != nil {
+			return fmt.Errorf("Couldn't write %s.%s: %s", r.Type, r.Name, err)
+		}
+		for _, b := range r.Blocks {
+			fmt.Fprintf(buf, "\n  %s {\n", b.Name)
+			if err := writeAttributes(buf, b.Attributes, "    "); err != nil {
+				return fmt.Errorf("Couldn't write %s.%s: %s", r.Type, r.Name, err)
+			}
+			buf.WriteString("  }\n")
+		}
+		buf.WriteString("}\n")
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// WriteImports writes import blocks so that terraform adopts resources instead of creating them
+func WriteImports(w io.Writer, resources []Resource) error {
+	buf := new(bytes.Buffer)
+	for i, r := range resources {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "import {\n  to = %s.%s\n  id = %s\n}\n", r.Type, r.Name, quote(r.ID))
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func writeAttributes(buf *bytes.Buffer, attributes []Attribute, indent string) error {
+	width := 0
+	for _, a := range attributes {
+		if len(a.Name) > width {
+			width = len(a.Name)
+		}
+	}
+	for _, a := range attributes {
+		value, err := encodeValue(a.Value)
+		if err != nil {
+			return fmt.Errorf("attribute %s: %s", a.Name, err)
+		}
+		fmt.Fprintf(buf, "%s%-*s = %s\n", indent, width, a.Name, value)
+	}
+	return nil
+}
+
+func encodeValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return quote(v), nil
+	case Reference:
+		return string(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []string:
+		items := make([]string, len(v))
+		for i, s := range v {
+			items[i] = quote(s)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case *json.RawMessage:
+		if v == nil {
+			return "null", nil
+		}
+		// JSON is kept as is, terraform reads it through jsondecode
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, *v); err != nil {
+			return "", err
+		}
+		return "jsonencode(jsondecode(" + quote(compact.String()) + "))", nil
+	}
+	return "", fmt.Errorf("unsupported value type %T", value)
+}
+
+// quote returns s as an HCL string. Only the escapes HCL knows are used, and template sequences are
+// escaped so that they're kept literally
+func quote(s string) string {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"':
+			buf.WriteString(`\"`)
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			// ${ and %{ are written doubled
+			buf.WriteRune(r)
+			buf.WriteRune(r)
+		case r == utf8.RuneError:
+			// invalid UTF-8 is replaced, as HCL files can't hold it
+			buf.WriteString(`\ufffd`)
+		case !unicode.IsPrint(r) && r <= 0xffff:
+			fmt.Fprintf(buf, `\u%04x`, r)
+		case !unicode.IsPrint(r):
+			fmt.Fprintf(buf, `\U%08x`, r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// SortByName sorts resources by type and name, so that exports are stable between runs
+func SortByName(resources []Resource) {
+	sort.Sort(byName(resources))
+}
+
+type byName []Resource
+
+func (a byName) Len() int      { return len(a) }
+func (a byName) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byName) Less(i, j int) bool {
+	if a[i].Type != a[j].Type {
+		return a[i].Type < a[j].Type
+	}
+	return a[i].Name < a[j].Name
+}
@@ -0,0 +1,113 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceName(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("web_01", ResourceName("Web 01"), "Spaces should be replaced")
+	assert.Equal("_1st-server", ResourceName("1st-server"), "Names can't start with a digit")
+	assert.Equal("example_com", ResourceName("example.com."), "Dots should be replaced")
+	assert.Equal("unnamed", ResourceName("..."), "Empty names should get a default")
+}
+
+func TestUniqueNames(t *testing.T) {
+	assert := assert.New(t)
+
+	resources := []Resource{
+		{Type: "concerto_server", Name: "web"},
+		{Type: "concerto_server", Name: "web"},
+		{Type: "concerto_template", Name: "web"},
+	}
+	UniqueNames(resources)
+	assert.Equal("web", resources[0].Name, "First name should be kept")
+	assert.Equal("web_2", resources[1].Name, "Repeated name should get a suffix")
+	assert.Equal("web", resources[2].Name, "Names are unique per type")
+
+	resources = []Resource{
+		{Type: "concerto_server", Name: "web"},
+		{Type: "concerto_server", Name: "web"},
+		{Type: "concerto_server", Name: "web_2"},
+		{Type: "concerto_server", Name: "web"},
+	}
+	UniqueNames(resources)
+	var names []string
+	for _, r := range resources {
+		names = append(names, r.Name)
+	}
+	assert.Equal([]string{"web", "web_3", "web_2", "web_4"}, names, "Suffixes should skip taken names")
+}
+
+func TestQuote(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(`"say \"hi\"\n\tC:\\dir"`, quote("say \"hi\"\n\tC:\\dir"), "Unexpected escapes")
+	assert.Equal(`"$${a} %%{if} $5 100%"`, quote("${a} %{if} $5 100%"), "Template sequences should be escaped")
+	assert.Equal(`"bell\u0007 del\u007f esc\u001b"`, quote("bell\a del\x7f esc\x1b"), "Control characters should use unicode escapes")
+	assert.Equal(`"café \ufffd"`, quote("café \xff"), "Invalid UTF-8 should be replaced")
+	assert.Equal(`"café"`, quote("café"), "Printable unicode should be kept")
+}
+
+func TestWriteResources(t *testing.T) {
+	assert := assert.New(t)
+
+	attrs := json.RawMessage(`{"port": 80, "motd": "${user}"}`)
+	resources := []Resource{
+		{
+			Type: "concerto_template",
+			Name: "web",
+			ID:   "1234",
+			Attributes: []Attribute{
+				{Name: "name", Value: "web"},
+				{Name: "service_list", Value: []string{"nginx", "php"}},
+				{Name: "configuration_attributes", Value: &attrs},
+			},
+			Blocks: []Block{
+				{Name: "rule", Attributes: []Attribute{{Name: "min_port", Value: 80}, {Name: "enabled", Value: true}}},
+			},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	assert.Nil(WriteResources(buf, resources), "Couldn't write resources")
+	expected := `resource "concerto_template" "web" {
+  name                     = "web"
+  service_list             = ["nginx", "php"]
+  configuration_attributes = jsonencode(jsondecode("{\"port\":80,\"motd\":\"$${user}\"}"))
+
+  rule {
+    min_port = 80
+    enabled  = true
+  }
+}
+`
+	assert.Equal(expected, buf.String(), "Unexpected HCL")
+
+	buf.Reset()
+	assert.Nil(WriteImports(buf, resources), "Couldn't write imports")
+	assert.Equal("import {\n  to = concerto_template.web\n  id = \"1234\"\n}\n", buf.String(), "Unexpected import block")
+}
+
+func TestWriteResourcesReference(t *testing.T) {
+	template := Resource{Type: "concerto_template", Name: "web"}
+	server := Resource{
+		Type:       "concerto_server",
+		Name:       "web_1",
+		Attributes: []Attribute{{Name: "template_id", Value: Ref(template)}},
+	}
+
+	buf := new(bytes.Buffer)
+	assert.Nil(t, WriteResources(buf, []Resource{server}), "Couldn't write resources")
+	assert.Equal(t, "resource \"concerto_server\" \"web_1\" {\n  template_id = concerto_template.web.id\n}\n", buf.String(), "References should be written unquoted")
+}
+
+func TestWriteResourcesUnsupportedValue(t *testing.T) {
+	err := WriteResources(new(bytes.Buffer), []Resource{{Type: "t", Name: "n", Attributes: []Attribute{{Name: "a", Value: 1.5}}}})
+	assert.NotNil(t, err, "Unsupported values should fail")
+}
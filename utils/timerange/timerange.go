@@ -0,0 +1,83 @@
+// Package timerange parses the time ranges commands narrow their lists down to, given as dates or as
+// durations before now, such as --since 2h or --until 2016-01-02
+package timerange
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// layouts are the date formats accepted, from the most to the least precise
+var layouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+// Range holds the times between which items are kept. Zero times leave that end open
+type Range struct {
+	Since time.Time
+	Until time.Time
+}
+
+// Parse returns the range between since and until, relative to now when they're durations. Empty
+// values leave that end open
+func Parse(since string, until string, now time.Time) (Range, error) {
+	var r Range
+	var err error
+	if r.Since, err = ParseTime(since, now); err != nil {
+		return r, fmt.Errorf("Invalid since %s. %s", since, err)
+	}
+	if r.Until, err = ParseTime(until, now); err != nil {
+		return r, fmt.Errorf("Invalid until %s. %s", until, err)
+	}
+	if !r.Since.IsZero() && !r.Until.IsZero() && r.Until.Before(r.Since) {
+		return r, fmt.Errorf("Until %s is before since %s", until, since)
+	}
+	return r, nil
+}
+
+// ParseTime returns the time s stands for: a date, in local time unless it has a zone, or a duration
+// before now, such as 90m, 2h or 7d. Empty values are the zero time
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := parseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Please, use a date such as 2016-01-02 or 2016-01-02T15:04:05Z, or a duration such as 30m, 2h or 7d")
+}
+
+// parseDuration parses durations as time.ParseDuration does, adding days
+func parseDuration(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("Invalid duration %s", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("Invalid duration %s", s)
+	}
+	return d, nil
+}
+
+// Contains returns whether t is within the range. Both ends are included
+func (r Range) Contains(t time.Time) bool {
+	if !r.Since.IsZero() && t.Before(r.Since) {
+		return false
+	}
+	return r.Until.IsZero() || !t.After(r.Until)
+}
+
+// Ended returns whether the range is over at now, so that following new items can stop
+func (r Range) Ended(now time.Time) bool {
+	return !r.Until.IsZero() && now.After(r.Until)
+}
@@ -0,0 +1,51 @@
+package timerange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2016, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func TestParseTime(t *testing.T) {
+	assert := assert.New(t)
+
+	for s, expected := range map[string]time.Time{
+		"":                     {},
+		"90m":                  now.Add(-90 * time.Minute),
+		"7d":                   now.Add(-7 * 24 * time.Hour),
+		"2016-03-01":           time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC),
+		"2016-03-01 10:30":     time.Date(2016, 3, 1, 10, 30, 0, 0, time.UTC),
+		"2016-03-01T10:30:15Z": time.Date(2016, 3, 1, 10, 30, 15, 0, time.UTC),
+	} {
+		parsed, err := ParseTime(s, now)
+		assert.Nil(err, "Time %q should be valid", s)
+		assert.True(expected.Equal(parsed), "Unexpected time for %q: %s", s, parsed)
+	}
+
+	for _, s := range []string{"yesterday", "-2h", "2016-13-01", "xd"} {
+		_, err := ParseTime(s, now)
+		assert.NotNil(err, "Time %q should be invalid", s)
+	}
+}
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+
+	r, err := Parse("2d", "1h", now)
+	assert.Nil(err, "Valid ranges shouldn't fail")
+	assert.False(r.Contains(now.Add(-72*time.Hour)), "Times before since shouldn't be in range")
+	assert.True(r.Contains(now.Add(-2*time.Hour)), "Times between since and until should be in range")
+	assert.False(r.Contains(now.Add(-30*time.Minute)), "Times after until shouldn't be in range")
+	assert.True(r.Ended(now), "Ranges until a past time should be over")
+
+	r, err = Parse("", "", now)
+	assert.Nil(err, "Open ranges shouldn't fail")
+	assert.True(r.Contains(time.Time{}), "Open ranges should contain any time")
+	assert.False(r.Ended(now), "Open ranges shouldn't end")
+
+	_, err = Parse("1h", "2h", now)
+	assert.EqualError(err, "Until 2h is before since 1h", "Reversed ranges should fail")
+}
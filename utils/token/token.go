@@ -0,0 +1,207 @@
+// Package token authenticates API requests with bearer tokens, for deployments issuing API tokens instead of
+// client certificates. Tokens are either given as they are, or obtained from an OAuth2 token endpoint with a
+// refresh token or client credentials, and obtained again when they expire or the API rejects them
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// now is replaced in tests
+var now = time.Now
+
+// expiryMargin is how long before their expiry tokens are renewed, so that they don't expire in flight
+const expiryMargin = 30 * time.Second
+
+// Source returns the token requests are authenticated with, renewing it when it can
+type Source struct {
+	url          string
+	clientID     string
+	clientSecret string
+	refreshToken string
+	client       *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewSource returns a source of token. When url is given, tokens are obtained from that OAuth2 token
+// endpoint with refreshToken, or with the client credentials when there's no refresh token. Token may
+// be empty then, so that the first one is obtained before the first request
+func NewSource(token string, url string, clientID string, clientSecret string, refreshToken string, client *http.Client) *Source {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Source{
+		token:        token,
+		url:          url,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		refreshToken: refreshToken,
+		client:       client,
+	}
+}
+
+// Refreshable returns whether tokens can be renewed
+func (s *Source) Refreshable() bool {
+	return s.url != ""
+}
+
+// Token returns a valid token, renewing the current one when there's none or it's about to expire
+func (s *Source) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && (s.expiry.IsZero() || now().Add(expiryMargin).Before(s.expiry)) {
+		return s.token, nil
+	}
+	if !s.Refreshable() {
+		if s.token == "" {
+			return "", fmt.Errorf("No API token configured")
+		}
+		return s.token, nil
+	}
+	if err := s.refresh(); err != nil {
+		return "", err
+	}
+	return s.token, nil
+}
+
+// Invalidate discards token, once the API has rejected it, so that a new one is obtained. Tokens
+// renewed meanwhile by concurrent requests are kept
+func (s *Source) Invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == token && s.Refreshable() {
+		s.token = ""
+	}
+}
+
+// tokenResponse is the response of OAuth2 token endpoints
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Error        string `json:"error"`
+	Description  string `json:"error_description"`
+}
+
+// refresh obtains a new token from the token endpoint. Refresh tokens rotated by the endpoint replace
+// the configured one for the rest of the command
+func (s *Source) refresh() error {
+	form := url.Values{}
+	if s.refreshToken != "" {
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", s.refreshToken)
+	} else {
+		form.Set("grant_type", "client_credentials")
+	}
+	request, err := http.NewRequest("POST", s.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if s.clientID != "" {
+		request.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+	}
+
+	log.Debugf("Obtaining API token from %s with %s grant", s.url, form.Get("grant_type"))
+	response, err := s.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("Couldn't obtain API token: %s", err)
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("Couldn't obtain API token: %s", err)
+	}
+
+	var t tokenResponse
+	if err = json.Unmarshal(body, &t); err != nil && response.StatusCode < 300 {
+		return fmt.Errorf("Couldn't obtain API token: token endpoint response isn't valid JSON")
+	}
+	if response.StatusCode >= 300 || t.Error != "" {
+		reason := strings.TrimSpace(strings.Join([]string{t.Error, t.Description}, " "))
+		if reason == "" {
+			reason = response.Status
+		}
+		return fmt.Errorf("Couldn't obtain API token: %s", reason)
+	}
+	if t.AccessToken == "" {
+		return fmt.Errorf("Couldn't obtain API token: token endpoint returned no access_token")
+	}
+
+	s.token = t.AccessToken
+	s.expiry = time.Time{}
+	if t.ExpiresIn > 0 {
+		s.expiry = now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+	if t.RefreshToken != "" {
+		s.refreshToken = t.RefreshToken
+	}
+	return nil
+}
+
+// Transport authenticates requests to the API in host, such as clients.concerto.io:886, sent through
+// next with the tokens of source. Requests to other hosts, such as those the API redirects to, are sent
+// without token. Requests rejected with 401 are sent once more with a renewed token, when tokens can be
+// renewed
+type Transport struct {
+	Source *Source
+	Host   string
+	Next   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if !strings.EqualFold(request.URL.Host, t.Host) {
+		return t.Next.RoundTrip(request)
+	}
+	token, err := t.Source.Token()
+	if err != nil {
+		return nil, err
+	}
+	response, err := t.Next.RoundTrip(authorize(request, token))
+	if err != nil || response.StatusCode != http.StatusUnauthorized || !t.Source.Refreshable() {
+		return response, err
+	}
+	if request.Body != nil && request.GetBody == nil {
+		return response, err
+	}
+
+	log.Debugf("API rejected the token of %s %s. Renewing it", request.Method, request.URL)
+	t.Source.Invalidate(token)
+	if token, err = t.Source.Token(); err != nil {
+		log.Warn(err)
+		return response, nil
+	}
+	retry := authorize(request, token)
+	if request.Body != nil {
+		if retry.Body, err = request.GetBody(); err != nil {
+			return response, nil
+		}
+	}
+	io.Copy(ioutil.Discard, response.Body)
+	response.Body.Close()
+	return t.Next.RoundTrip(retry)
+}
+
+// authorize returns a copy of request with token in its Authorization header, as round trippers must not
+// modify requests
+func authorize(request *http.Request, token string) *http.Request {
+	r := request.Clone(request.Context())
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
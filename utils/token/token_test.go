@@ -0,0 +1,149 @@
+package token
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tokenEndpoint returns a token endpoint issuing tokens numbered in order, valid for an hour
+func tokenEndpoint(t *testing.T, grants *[]string) *httptest.Server {
+	issued := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if id, secret, ok := r.BasicAuth(); !ok || id != "concerto" || secret != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client","error_description":"Unknown client"}`)
+			return
+		}
+		*grants = append(*grants, r.Form.Get("grant_type")+" "+r.Form.Get("refresh_token"))
+		issued++
+		fmt.Fprintf(w, `{"access_token":"token%d","token_type":"bearer","expires_in":3600,"refresh_token":"refresh%d"}`, issued, issued)
+	}))
+}
+
+func TestStaticToken(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewSource("abc", "", "", "", "", nil)
+	token, err := s.Token()
+	assert.Nil(err, "Static tokens shouldn't fail")
+	assert.Equal("abc", token, "Unexpected token")
+	s.Invalidate("abc")
+	token, _ = s.Token()
+	assert.Equal("abc", token, "Tokens that can't be renewed should be kept")
+
+	_, err = NewSource("", "", "", "", "", nil).Token()
+	assert.NotNil(err, "Sources without token nor endpoint should fail")
+}
+
+func TestRefresh(t *testing.T) {
+	assert := assert.New(t)
+	clock := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	var grants []string
+	server := tokenEndpoint(t, &grants)
+	defer server.Close()
+
+	s := NewSource("", server.URL, "concerto", "s3cr3t", "initial", nil)
+	token, err := s.Token()
+	assert.Nil(err, "Refreshing shouldn't fail")
+	assert.Equal("token1", token, "First token should be obtained before the first request")
+
+	clock = clock.Add(30 * time.Minute)
+	token, _ = s.Token()
+	assert.Equal("token1", token, "Valid tokens should be reused")
+
+	clock = clock.Add(30 * time.Minute)
+	token, _ = s.Token()
+	assert.Equal("token2", token, "Expired tokens should be renewed")
+	assert.Equal([]string{"refresh_token initial", "refresh_token refresh1"}, grants, "Rotated refresh tokens should be used")
+
+	grants = nil
+	s = NewSource("", server.URL, "concerto", "s3cr3t", "", nil)
+	_, err = s.Token()
+	assert.Nil(err, "Client credentials shouldn't fail")
+	assert.Equal([]string{"client_credentials "}, grants, "Client credentials should be used without refresh token")
+
+	_, err = NewSource("", server.URL, "concerto", "wrong", "", nil).Token()
+	assert.EqualError(err, "Couldn't obtain API token: invalid_client Unknown client", "Rejected clients should fail")
+}
+
+func TestTransport(t *testing.T) {
+	assert := assert.New(t)
+
+	var grants []string
+	endpoint := tokenEndpoint(t, &grants)
+	defer endpoint.Close()
+
+	var received []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = append(received, r.Header.Get("Authorization")+" "+string(body))
+		if r.Header.Get("Authorization") != "Bearer token1" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer api.Close()
+
+	client := &http.Client{Transport: &Transport{
+		Source: NewSource("expired", endpoint.URL, "concerto", "s3cr3t", "initial", nil),
+		Host:   hostOf(api.URL),
+		Next:   http.DefaultTransport,
+	}}
+	response, err := client.Post(api.URL, "application/json", strings.NewReader(`{"name":"web"}`))
+	if assert.Nil(err, "Requests shouldn't fail") {
+		response.Body.Close()
+		assert.Equal(http.StatusOK, response.StatusCode, "Rejected requests should be sent again with a new token")
+	}
+	assert.Equal([]string{`Bearer expired {"name":"web"}`, `Bearer token1 {"name":"web"}`}, received, "Unexpected requests")
+
+	received = nil
+	client.Transport = &Transport{Source: NewSource("expired", "", "", "", "", nil), Host: hostOf(api.URL), Next: http.DefaultTransport}
+	response, err = client.Get(api.URL)
+	if assert.Nil(err, "Requests shouldn't fail") {
+		response.Body.Close()
+		assert.Equal(http.StatusUnauthorized, response.StatusCode, "Tokens that can't be renewed should be rejected")
+	}
+	assert.Len(received, 1, "Requests with tokens that can't be renewed shouldn't be sent again")
+}
+
+func hostOf(s string) string {
+	u, _ := url.Parse(s)
+	return u.Host
+}
+
+func TestTransportRedirect(t *testing.T) {
+	assert := assert.New(t)
+
+	var leaked string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Get("Authorization")
+	}))
+	defer other.Close()
+	var authorization string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		http.Redirect(w, r, other.URL, http.StatusFound)
+	}))
+	defer api.Close()
+
+	client := &http.Client{Transport: &Transport{Source: NewSource("t0k3n", "", "", "", "", nil), Host: hostOf(api.URL), Next: http.DefaultTransport}}
+	response, err := client.Get(api.URL)
+	if assert.Nil(err, "Requests shouldn't fail") {
+		response.Body.Close()
+	}
+	assert.Equal("Bearer t0k3n", authorization, "API requests should be authorized")
+	assert.Equal("", leaked, "Token shouldn't be sent to other hosts")
+}
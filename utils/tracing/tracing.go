@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/flexiant/concerto/utils/logging"
+)
+
+// maxBodySize limits the part of bodies written, so that downloads don't flood the trace
+const maxBodySize = 64 << 10
+
+// Tracer writes every API request and response, with credentials redacted from headers, URLs
+// and bodies. Lines are prefixed with the X-Request-Id of the request, so that concurrent
+// requests can be told apart
+type Tracer struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// New returns a tracer writing to out
+func New(out io.Writer) *Tracer {
+	return &Tracer{out: out}
+}
+
+// Wrap returns a transport tracing the requests sent through next
+func (t *Tracer) Wrap(next http.RoundTripper) http.RoundTripper {
+	return &tracingTransport{tracer: t, next: next}
+}
+
+type tracingTransport struct {
+	tracer *Tracer
+	next   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *tracingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	id := request.Header.Get("X-Request-Id")
+	var lines []string
+	lines = append(lines, fmt.Sprintf("> %s %s", request.Method, logging.Redact(request.URL.String())))
+	lines = append(lines, headerLines(">", request.Header)...)
+	if request.Body != nil {
+		body, err := ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		lines = append(lines, bodyLines(">", body, int64(len(body)))...)
+	}
+	t.tracer.write(id, lines)
+
+	start := time.Now()
+	response, err := t.next.RoundTrip(request)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.tracer.write(id, []string{fmt.Sprintf("< failed after %s: %s", elapsed, logging.Redact(err.Error()))})
+		return nil, err
+	}
+
+	lines = []string{fmt.Sprintf("< %s in %s", response.Status, elapsed)}
+	lines = append(lines, headerLines("<", response.Header)...)
+	// only the beginning of the body is read, and put back in front of the rest
+	head, err := ioutil.ReadAll(io.LimitReader(response.Body, maxBodySize+1))
+	if err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+	response.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), response.Body), response.Body}
+	lines = append(lines, bodyLines("<", head, response.ContentLength)...)
+	t.tracer.write(id, lines)
+	return response, nil
+}
+
+func (t *Tracer) write(id string, lines []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, line := range lines {
+		if id != "" {
+			fmt.Fprintf(t.out, "[%s] %s\n", id, line)
+		} else {
+			fmt.Fprintln(t.out, line)
+		}
+	}
+}
+
+// headerLines returns the headers of a message, sorted by name
+func headerLines(prefix string, h http.Header) []string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		for _, value := range h[name] {
+			lines = append(lines, fmt.Sprintf("%s %s: %s", prefix, name, logging.RedactHeader(name, value)))
+		}
+	}
+	return lines
+}
+
+// bodyLines returns the text of a body, or its size when it's binary. Bodies beyond maxBodySize
+// are truncated; size is the length of the whole body, or -1 when unknown
+func bodyLines(prefix string, body []byte, size int64) []string {
+	if len(body) == 0 {
+		return nil
+	}
+	if size < int64(len(body)) {
+		size = -1
+	}
+	shown := len(body)
+	if shown > maxBodySize {
+		shown = maxBodySize
+		for shown > 0 && !utf8.RuneStart(body[shown]) {
+			shown--
+		}
+	}
+	if !utf8.Valid(body[:shown]) {
+		if size > 0 {
+			return []string{fmt.Sprintf("%s [%d bytes of binary data]", prefix, size)}
+		}
+		return []string{fmt.Sprintf("%s [binary data]", prefix)}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(logging.Redact(string(body[:shown])), "\n"), "\n") {
+		lines = append(lines, fmt.Sprintf("%s %s", prefix, line))
+	}
+	if shown < len(body) {
+		if size > 0 {
+			lines = append(lines, fmt.Sprintf("%s [truncated, %d of %d bytes shown]", prefix, shown, size))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s [truncated, %d bytes shown]", prefix, shown))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.WriteHeader(201)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client := &http.Client{Transport: New(&out).Wrap(http.DefaultTransport)}
+	request, err := http.NewRequest("POST", server.URL+"/v1/settings/cloud_accounts?token=abc", strings.NewReader(`{"name":"aws","credentials":{"secret":"s3cr3t"}}`))
+	assert.Nil(err, "Couldn't create request")
+	request.Header.Set("X-Request-Id", "42")
+	request.Header.Set("Authorization", "Bearer abc")
+
+	response, err := client.Do(request)
+	assert.Nil(err, "Request shouldn't fail")
+	body, err := ioutil.ReadAll(response.Body)
+	assert.Nil(err, "Couldn't read response")
+	assert.Contains(string(body), "s3cr3t", "Response body should be left untouched")
+
+	trace := out.String()
+	assert.Contains(trace, "[42] > POST "+server.URL+"/v1/settings/cloud_accounts", "Request line should be traced")
+	assert.Contains(trace, "[42] < 201 Created in ", "Status and latency should be traced")
+	assert.Contains(trace, "[42] < Content-Type: application/json", "Response headers should be traced")
+	assert.Contains(trace, `[42] > {"name":"aws"`, "Request body should be traced")
+	assert.NotContains(trace, "abc", "Credentials in URL and headers should be redacted")
+	assert.NotContains(trace, "s3cr3t", "Credentials in bodies should be redacted")
+}
+
+func TestBodyLines(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(bodyLines("<", nil, 0), "Empty bodies shouldn't be traced")
+	assert.Equal([]string{"< [3 bytes of binary data]"}, bodyLines("<", []byte{0xff, 0xfe, 0x00}, 3), "Binary bodies should be summarized")
+
+	long := bytes.Repeat([]byte("a"), maxBodySize+1)
+	lines := bodyLines("<", long, 2*maxBodySize)
+	assert.Equal("< [truncated, 65536 of 131072 bytes shown]", lines[len(lines)-1], "Long bodies should be truncated")
+}
@@ -0,0 +1,376 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/har"
+	"github.com/flexiant/concerto/utils/pin"
+	"github.com/flexiant/concerto/utils/ratelimit"
+	"github.com/flexiant/concerto/utils/token"
+	"github.com/flexiant/concerto/utils/tracing"
+)
+
+// retryDelay is the wait before the first retry. It doubles on every attempt
+var retryDelay = 500 * time.Millisecond
+
+// Idle connections kept for reuse, so that requests after the first one, such as those of bulk commands
+// running concurrently, don't open a connection and handshake TLS again. http.Transport keeps only two
+const (
+	maxIdleConns    = 32
+	idleConnTimeout = 90 * time.Second
+)
+
+// minCompressedSize is the smallest request body compressed, as smaller ones gain little from it
+const minCompressedSize = 1024
+
+var (
+	clients   = make(map[*Config]*http.Client)
+	clientsMu sync.Mutex
+)
+
+var (
+	recorder *har.Recorder
+	replayer *har.Replayer
+	tracer   *tracing.Tracer
+)
+
+// RecordAPI makes API clients created from now on capture every request and response into r
+func RecordAPI(r *har.Recorder) {
+	recorder = r
+}
+
+// ReplayAPI makes API clients created from now on serve the responses of r, instead of contacting the API
+func ReplayAPI(r *har.Replayer) {
+	replayer = r
+}
+
+// TraceAPI makes API clients created from now on write every request and response to t
+func TraceAPI(t *tracing.Tracer) {
+	tracer = t
+}
+
+// Replaying returns whether API responses come from a recording. Certificates aren't required then
+func Replaying() bool {
+	return replayer != nil
+}
+
+// NewHTTPClient returns an http client for Concerto API based on config.
+// Clients are created once per configuration and shared by every service in the process.
+func NewHTTPClient(config *Config) (*http.Client, error) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	if client, ok := clients[config]; ok {
+		return client, nil
+	}
+
+	client, err := newHTTPClient(config)
+	if err != nil {
+		return nil, err
+	}
+	clients[config] = client
+	return client, nil
+}
+
+func newHTTPClient(config *Config) (*http.Client, error) {
+	log.Debug("Creating HTTP client")
+
+	if replayer != nil {
+		log.Debug("Replaying recorded API interactions")
+		if tracer != nil {
+			return &http.Client{Transport: tracer.Wrap(replayer)}, nil
+		}
+		return &http.Client{Transport: replayer}, nil
+	}
+
+	tlsConfig, err := config.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pins, err := pin.Parse(config.Certificate.Pins)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := config.RequestTimeout()
+	if err != nil {
+		return nil, err
+	}
+
+	maxDelay, err := config.RetryMaxDelay()
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, err := config.ProxyFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := config.RequestRate()
+	if err != nil {
+		return nil, err
+	}
+
+	connectTimeout, err := config.ConnectTimeout()
+	if err != nil {
+		return nil, err
+	}
+
+	readTimeout, err := config.ReadIdleTimeout()
+	if err != nil {
+		return nil, err
+	}
+
+	maxConns, err := config.MaxConnections()
+	if err != nil {
+		return nil, err
+	}
+
+	// Creates a client with specific transport configurations. Pins are verified in the handshake,
+	// so that connections tunnelled through a proxy are verified too
+	if len(pins) > 0 {
+		tlsConfig.VerifyPeerCertificate = pinnedVerifier(pins)
+	}
+	// new connections resume TLS sessions, which spares them most of the handshake
+	tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+	// responses are asked for and decompressed with gzip by the transport itself
+	transport := &http.Transport{
+		TLSClientConfig:     tlsConfig,
+		Proxy:               proxy,
+		DialContext:         idleTimeoutDialer(&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}, readTimeout),
+		TLSHandshakeTimeout: connectTimeout,
+		MaxIdleConns:        maxIdleConns,
+		MaxIdleConnsPerHost: maxIdleConns,
+		MaxConnsPerHost:     maxConns,
+		IdleConnTimeout:     idleConnTimeout,
+	}
+
+	// bodies are compressed last, so that they're recorded and traced as sent
+	var next http.RoundTripper = transport
+	if config.Compress {
+		next = &gzipTransport{next: next}
+	}
+	if recorder != nil {
+		next = recorder.Wrap(next)
+	}
+	if tracer != nil {
+		next = tracer.Wrap(next)
+	}
+
+	var authenticated http.RoundTripper = &retryTransport{next: next, retries: config.Retries, maxDelay: maxDelay, limiter: ratelimit.New(rate)}
+	if config.UsesToken() {
+		// tokens are obtained through the same connections, but aren't recorded nor traced
+		source, err := config.TokenSource(&http.Client{Transport: transport, Timeout: timeout})
+		if err != nil {
+			return nil, err
+		}
+		endpoint, err := url.Parse(config.APIEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid API endpoint %s: %s", config.APIEndpoint, err)
+		}
+		authenticated = &token.Transport{Source: source, Host: endpoint.Host, Next: authenticated}
+	}
+
+	return &http.Client{
+		Transport: authenticated,
+		Timeout:   timeout,
+	}, nil
+}
+
+// idleTimeoutDialer returns a dial function whose connections fail reads once they've gone without receiving
+// data for timeout, so that requests to a hung API don't block forever
+func idleTimeoutDialer(dialer *net.Dialer, timeout time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil || timeout <= 0 {
+			return conn, err
+		}
+		return &idleTimeoutConn{Conn: conn, timeout: timeout}, nil
+	}
+}
+
+// idleTimeoutConn extends its read deadline whenever data is sent or received. Writes extend it too, as
+// idle connections kept for reuse wait for responses in reads started before their request is written
+type idleTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *idleTimeoutConn) Read(b []byte) (int, error) {
+	c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+	return c.Conn.Read(b)
+}
+
+func (c *idleTimeoutConn) Write(b []byte) (int, error) {
+	c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+	return c.Conn.Write(b)
+}
+
+// gzipTransport compresses request bodies of minCompressedSize bytes or more with gzip
+type gzipTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *gzipTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Body == nil || request.Header.Get("Content-Encoding") != "" || (request.ContentLength > 0 && request.ContentLength < minCompressedSize) {
+		return t.next.RoundTrip(request)
+	}
+
+	data, err := ioutil.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	r := request.Clone(request.Context())
+	if len(data) < minCompressedSize {
+		r.Body = ioutil.NopCloser(bytes.NewReader(data))
+		return t.next.RoundTrip(r)
+	}
+
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	log.Debugf("Request body compressed from %d to %d bytes", len(data), compressed.Len())
+
+	body := compressed.Bytes()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	r.ContentLength = int64(len(body))
+	r.Header.Set("Content-Encoding", "gzip")
+	return t.next.RoundTrip(r)
+}
+
+// pinnedVerifier returns a check that servers present one of the pinned keys in the chain verified
+func pinnedVerifier(pins [][]byte) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		return pin.VerifyPeer(rawCerts, verifiedChains, pins)
+	}
+}
+
+// retryTransport sends again requests that failed because of transient errors. Requests are spaced out by
+// limiter, which holds every request back when the API rate limits one of them
+type retryTransport struct {
+	next     http.RoundTripper
+	retries  int
+	maxDelay time.Duration
+	limiter  *ratelimit.Limiter
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	delay := retryDelay
+	req := request
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.Wait(); err != nil {
+			return nil, err
+		}
+		response, err := t.next.RoundTrip(req)
+		if err == nil && response.StatusCode == 429 {
+			wait := retryAfter(response)
+			if wait <= 0 {
+				wait = delay
+			}
+			if t.maxDelay > 0 && wait > t.maxDelay {
+				wait = t.maxDelay
+			}
+			log.Debugf("API rate limit reached. Holding requests back for %s", wait)
+			t.limiter.Pause(wait)
+		}
+		if attempt >= t.retries || !shouldRetry(request.Method, response, err) {
+			return response, err
+		}
+
+		// body has already been consumed, so request must be rebuilt
+		if request.Body != nil {
+			if request.GetBody == nil {
+				return response, err
+			}
+			body, bodyErr := request.GetBody()
+			if bodyErr != nil {
+				return response, err
+			}
+			r := *request
+			r.Body = body
+			req = &r
+		}
+
+		wait := delay
+		if after := retryAfter(response); after > wait {
+			wait = after
+		}
+		if t.maxDelay > 0 && wait > t.maxDelay {
+			wait = t.maxDelay
+		}
+
+		if response != nil {
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+			log.Debugf("%s %s returned %s. Retrying in %s", request.Method, request.URL, response.Status, wait)
+		} else {
+			log.Debugf("%s %s failed: %s. Retrying in %s", request.Method, request.URL, err, wait)
+		}
+		if !cancel.Sleep(wait) {
+			return nil, cancel.Err()
+		}
+		if delay *= 2; t.maxDelay > 0 && delay > t.maxDelay {
+			delay = t.maxDelay
+		}
+	}
+}
+
+// shouldRetry returns whether a request could succeed if sent again.
+// Non idempotent requests are only retried when the API explicitly rejected them.
+func shouldRetry(method string, response *http.Response, err error) bool {
+	idempotent := method != "POST" && method != "PATCH"
+	if err != nil {
+		return idempotent
+	}
+	if response.StatusCode == 429 {
+		return true
+	}
+	return response.StatusCode >= 500 && idempotent
+}
+
+// retryAfter returns the wait asked for by the Retry-After header of rate limited or unavailable responses,
+// given either in seconds or as a date
+func retryAfter(response *http.Response) time.Duration {
+	if response == nil || (response.StatusCode != 429 && response.StatusCode != 503) {
+		return 0
+	}
+	header := response.Header.Get("Retry-After")
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return date.Sub(time.Now())
+	}
+	return 0
+}
@@ -0,0 +1,144 @@
+// Package usage aggregates the server time of reports by server or account group, within
+// a time range, so that consumption can be billed
+package usage
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/timerange"
+)
+
+// Groupings of consumption
+const (
+	ByServer       = "server"
+	ByAccountGroup = "account_group"
+)
+
+// Groupings are the ways consumption can be aggregated
+var Groupings = []string{ByServer, ByAccountGroup}
+
+// Consumption is the server time used by a server or an account group. AccountGroup is
+// empty when grouping by account group, as ID and Name are the group ones
+type Consumption struct {
+	ID            string  `json:"id" header:"ID"`
+	Name          string  `json:"name" header:"NAME"`
+	AccountGroup  string  `json:"account_group" header:"ACCOUNT GROUP"`
+	Servers       int     `json:"servers" header:"SERVERS"`
+	ServerSeconds float64 `json:"server_seconds" header:"SERVER TIME" show:"minifySeconds"`
+	ServerHours   float64 `json:"server_hours" header:"SERVER HOURS"`
+}
+
+// Overlaps returns whether the period of report has some time within r
+func Overlaps(report types.Report, r timerange.Range) bool {
+	if !r.Until.IsZero() && !report.StartTime.IsZero() && !report.StartTime.Before(r.Until) {
+		return false
+	}
+	return r.Since.IsZero() || report.EndTime.IsZero() || report.EndTime.After(r.Since)
+}
+
+// Within returns report with the lines that ran some time within r
+func Within(report types.Report, r timerange.Range) types.Report {
+	lines := []types.Lines{}
+	for _, line := range report.Lines {
+		if secondsWithin(report, line, r) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	report.Lines = lines
+	return report
+}
+
+// Aggregate returns the consumption of the report lines within r, grouped by one of Groupings, the
+// largest first. Lines running only partly within r count the part within it
+func Aggregate(reports []types.Report, r timerange.Range, by string) ([]Consumption, error) {
+	key := groupKey(by)
+	if key == nil {
+		return nil, fmt.Errorf("Consumption can't be grouped by %s", by)
+	}
+	groups := make(map[string]*Consumption)
+	servers := make(map[string]map[string]bool)
+	for _, report := range reports {
+		for _, line := range report.Lines {
+			seconds := secondsWithin(report, line, r)
+			if seconds <= 0 {
+				continue
+			}
+			k, c := key(report, line)
+			if _, ok := groups[k]; !ok {
+				groups[k] = &c
+				servers[k] = make(map[string]bool)
+			}
+			groups[k].ServerSeconds += seconds
+			servers[k][line.InstanceID] = true
+		}
+	}
+
+	consumption := []Consumption{}
+	for k, c := range groups {
+		c.Servers = len(servers[k])
+		c.ServerHours = hours(c.ServerSeconds)
+		consumption = append(consumption, *c)
+	}
+	sort.Slice(consumption, func(i, j int) bool {
+		if consumption[i].ServerSeconds != consumption[j].ServerSeconds {
+			return consumption[i].ServerSeconds > consumption[j].ServerSeconds
+		}
+		return consumption[i].ID < consumption[j].ID
+	})
+	return consumption, nil
+}
+
+// groupKey returns the function telling the group of a line and its consumption without time, or nil
+// when by isn't one of Groupings
+func groupKey(by string) func(report types.Report, line types.Lines) (string, Consumption) {
+	switch by {
+	case ByServer:
+		return func(report types.Report, line types.Lines) (string, Consumption) {
+			return line.InstanceID, Consumption{ID: line.InstanceID, Name: line.InstanceName, AccountGroup: report.AccountGroup.Name}
+		}
+	case ByAccountGroup:
+		return func(report types.Report, line types.Lines) (string, Consumption) {
+			return report.AccountGroup.ID, Consumption{ID: report.AccountGroup.ID, Name: report.AccountGroup.Name}
+		}
+	}
+	return nil
+}
+
+// secondsWithin returns the consumption of line within r, prorated to the part of the time it ran,
+// bounded by the report period, that is within r
+func secondsWithin(report types.Report, line types.Lines, r timerange.Range) float64 {
+	start, end := line.CommissionedAt, line.DecommissionedAt
+	if start.IsZero() || start.Before(report.StartTime) {
+		start = report.StartTime
+	}
+	if end.IsZero() || (!report.EndTime.IsZero() && end.After(report.EndTime)) {
+		end = report.EndTime
+	}
+	if start.IsZero() || end.IsZero() || !end.After(start) {
+		// the time it ran is unknown, so it's counted whole when it can be within r
+		if Overlaps(report, r) {
+			return float64(line.Consumption)
+		}
+		return 0
+	}
+	from, to := start, end
+	if !r.Since.IsZero() && r.Since.After(from) {
+		from = r.Since
+	}
+	if !r.Until.IsZero() && r.Until.Before(to) {
+		to = r.Until
+	}
+	if !to.After(from) {
+		return 0
+	}
+	return float64(line.Consumption) * float64(to.Sub(from)) / float64(end.Sub(start))
+}
+
+// hours returns seconds as hours, rounded to hundredths
+func hours(seconds float64) float64 {
+	return math.Round(seconds/time.Hour.Seconds()*100) / 100
+}
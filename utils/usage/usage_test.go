@@ -0,0 +1,74 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexiant/concerto/api/types"
+	"github.com/flexiant/concerto/utils/timerange"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+// testReports are two January reports: a group running two servers, one of them half the month, and
+// another one running a server all month
+func testReports() []types.Report {
+	return []types.Report{
+		{
+			ID: "r1", StartTime: date("2016-01-01"), EndTime: date("2016-01-31"),
+			AccountGroup: types.AccountGroup{ID: "ag1", Name: "Finance"},
+			Lines: []types.Lines{
+				{InstanceID: "s1", InstanceName: "web", Consumption: 30 * 86400},
+				{InstanceID: "s2", InstanceName: "db", CommissionedAt: date("2016-01-16"), Consumption: 15 * 86400},
+			},
+		},
+		{
+			ID: "r2", StartTime: date("2016-01-01"), EndTime: date("2016-01-31"),
+			AccountGroup: types.AccountGroup{ID: "ag2", Name: "Sales"},
+			Lines: []types.Lines{
+				{InstanceID: "s3", InstanceName: "crm", Consumption: 30 * 86400},
+			},
+		},
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	assert := assert.New(t)
+
+	consumption, err := Aggregate(testReports(), timerange.Range{}, ByAccountGroup)
+	assert.Nil(err, "Error aggregating consumption")
+	assert.Equal([]Consumption{
+		{ID: "ag1", Name: "Finance", Servers: 2, ServerSeconds: 45 * 86400, ServerHours: 1080},
+		{ID: "ag2", Name: "Sales", Servers: 1, ServerSeconds: 30 * 86400, ServerHours: 720},
+	}, consumption, "Consumption wasn't grouped by account group")
+
+	// the first ten days of January, when db wasn't running
+	r := timerange.Range{Since: date("2016-01-01"), Until: date("2016-01-11")}
+	consumption, _ = Aggregate(testReports(), r, ByServer)
+	assert.Equal([]Consumption{
+		{ID: "s1", Name: "web", AccountGroup: "Finance", Servers: 1, ServerSeconds: 10 * 86400, ServerHours: 240},
+		{ID: "s3", Name: "crm", AccountGroup: "Sales", Servers: 1, ServerSeconds: 10 * 86400, ServerHours: 240},
+	}, consumption, "Consumption wasn't prorated to the range")
+
+	_, err = Aggregate(testReports(), r, "workspace")
+	assert.EqualError(err, "Consumption can't be grouped by workspace")
+}
+
+func TestOverlaps(t *testing.T) {
+	assert := assert.New(t)
+	report := testReports()[0]
+	assert.True(Overlaps(report, timerange.Range{}), "Open range didn't overlap")
+	assert.True(Overlaps(report, timerange.Range{Since: date("2016-01-30")}), "Range starting in the report didn't overlap")
+	assert.False(Overlaps(report, timerange.Range{Since: date("2016-01-31")}), "Range starting after the report overlapped")
+	assert.False(Overlaps(report, timerange.Range{Until: date("2016-01-01")}), "Range ending before the report overlapped")
+}
+
+func TestWithin(t *testing.T) {
+	r := timerange.Range{Until: date("2016-01-11")}
+	report := Within(testReports()[0], r)
+	assert.Equal(t, []types.Lines{testReports()[0].Lines[0]}, report.Lines, "Lines outside of the range were kept")
+}
@@ -2,25 +2,20 @@ package utils
 
 import (
 	"archive/zip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
 	"github.com/codegangsta/cli"
-
-	log "github.com/Sirupsen/logrus"
 )
 
-// TODO remove after migration
-func CheckError(err error) {
-	if err != nil {
-		log.Fatal(err)
-	}
-}
-
 func Unzip(archive, target string) error {
 	reader, err := zip.OpenReader(archive)
 	if err != nil {
@@ -74,59 +69,18 @@ func ScrapeErrorMessage(message string, regExpression string) string {
 	return message
 }
 
-func CheckReturnCode(res int, mesg []byte) {
-	if res >= 300 {
-
-		message := string(mesg[:])
-		log.Debugf("Concerto API response: %s", message)
-
-		f := func(c rune) bool {
-			return c == ',' || c == ':' || c == '{' || c == '}' || c == '"' || c == ']' || c == '['
-		}
-
-		// check if response is a web page.
-		if strings.Contains(message, "<html>") {
-			scrapResponse := "<title>(.*?)</title>"
-			message = ScrapeErrorMessage(message, scrapResponse)
-		} else if strings.Contains(message, "{\"errors\":{") {
-			scrapResponse := "{\"errors\":(.*?)}"
-
-			message = ScrapeErrorMessage(message, scrapResponse)
-			result := strings.Split(message, ",")
-			if result != nil && len(result) >= 1 {
-				message = result[0]
-			}
-			// Separate into fields with func.
-			fields := strings.FieldsFunc(message, f)
-			message = strings.Join(fields[:], " ")
-
-		} else if strings.Contains(message, "{\"error\":") {
-			scrapResponse := "{\"error\":\"(.*?)\"}"
-			message = ScrapeErrorMessage(message, scrapResponse)
-		}
-
-		// temporary fix to replace any mention of fleet or ship with the appropriate counterparts (CARM-296)
-		re := regexp.MustCompile("\\bfleet\\b")
-		message = re.ReplaceAllString(message, "cluster")
-		re = regexp.MustCompile("\\bFleet\\b")
-		message = re.ReplaceAllString(message, "Cluster")
-		re = regexp.MustCompile("\\bship\\b")
-		message = re.ReplaceAllString(message, "node")
-		re = regexp.MustCompile("\\bShip\\b")
-		message = re.ReplaceAllString(message, "Node")
-
-		// if it's not a web page or json-formatted message, return the raw message
-		log.Fatal(fmt.Sprintf("HTTP request failed: (%d) [%s]", res, message))
-	}
-}
-
 // CheckStandardStatus return error if status is not OK
 func CheckStandardStatus(status int, mesg []byte) error {
 
 	if status < 300 {
 		return nil
 	}
+	return NewHTTPError(status, mesg, "")
+}
 
+// NewHTTPError returns the error of a non successful API response, with the message of its body and the
+// identifier of the request it failed
+func NewHTTPError(status int, mesg []byte, requestID string) *HTTPError {
 	message := string(mesg[:])
 
 	f := func(c rune) bool {
@@ -161,8 +115,69 @@ func CheckStandardStatus(status int, mesg []byte) error {
 	message = re.ReplaceAllString(message, "Node")
 
 	// if it's not a web page or json-formatted message, return the raw message
-	return fmt.Errorf("HTTP request failed: (%d) [%s]", status, message)
+	return &HTTPError{Status: status, Message: message, RequestID: requestID}
+
+}
+
+// HTTPError holds a non successful API response
+type HTTPError struct {
+	Status  int
+	Message string
+	// RequestID identifies the failed request in concerto logs and, when the API echoes it, in API logs
+	RequestID string
+	// Method is the method of the failed request, when known
+	Method string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP request failed: (%d) [%s]", e.Status, e.Message)
+}
+
+// finalError is an error that sending requests again can't fix
+type finalError struct {
+	err error
+}
+
+func (e *finalError) Error() string {
+	return e.err.Error()
+}
+
+func (e *finalError) Unwrap() error {
+	return e.err
+}
+
+// Final marks err as not retryable, whatever caused it
+func Final(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &finalError{err: err}
+}
+
+// IsRetryable returns whether the request that caused the error could succeed if sent again: transport errors
+// (timeouts, connection resets, ...) but cancellations, rate limited responses, and server errors of GET and
+// HEAD requests, as others may have been carried out before failing. Errors marked Final never are
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var final *finalError
+	if errors.As(err, &final) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Status == 429 || (httpErr.Status >= 500 && (httpErr.Method == "GET" || httpErr.Method == "HEAD"))
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
 
+// IsThrottled returns whether the error was caused by API rate limiting
+func IsThrottled(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.Status == 429
 }
 
 // FileExists checks file existence
@@ -0,0 +1,136 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/mitchellh/go-homedir"
+)
+
+// Prefix marks values that must be read from Vault, such as vault:secret/aws#access_key
+const Prefix = "vault:"
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// IsReference returns whether value must be read from Vault
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Resolve returns the secret referenced by value, in the form vault:<path>#<key>.
+// When key is omitted, the whole secret is returned as JSON.
+// Values that aren't Vault references are returned as they are.
+func Resolve(value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	ref := strings.TrimPrefix(value, Prefix)
+	path, key := ref, ""
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		path, key = ref[:i], ref[i+1:]
+	}
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return "", fmt.Errorf("Invalid Vault reference %s. Please, use vault:<path>#<key>", value)
+	}
+
+	data, err := read(path)
+	if err != nil {
+		return "", err
+	}
+
+	if key == "" {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	v, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("Vault secret %s has no key %s", path, key)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// read returns data of the secret stored at path, supporting both KV engine versions
+func read(path string) (map[string]interface{}, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return nil, fmt.Errorf("VAULT_ADDR must be set to read secrets from Vault")
+	}
+	token, err := token()
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s", strings.TrimRight(addr, "/"), path)
+	log.Debugf("Reading Vault secret %s", path)
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		request.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Couldn't read Vault secret %s: (%d) %s", path, response.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err = json.Unmarshal(body, &secret); err != nil {
+		return nil, err
+	}
+
+	// KV version 2 nests secret data and metadata
+	if nested, ok := secret.Data["data"].(map[string]interface{}); ok {
+		if _, ok := secret.Data["metadata"]; ok {
+			return nested, nil
+		}
+	}
+	return secret.Data, nil
+}
+
+// token returns VAULT_TOKEN, or the token stored by vault login
+func token() (string, error) {
+	if t := os.Getenv("VAULT_TOKEN"); t != "" {
+		return t, nil
+	}
+	home, err := homedir.Dir()
+	if err == nil {
+		if b, err := ioutil.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+			return strings.TrimSpace(string(b)), nil
+		}
+	}
+	return "", fmt.Errorf("VAULT_TOKEN must be set to read secrets from Vault")
+}
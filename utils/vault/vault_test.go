@@ -0,0 +1,55 @@
+package vault
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "testtoken" {
+			w.WriteHeader(403)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/aws":
+			fmt.Fprint(w, `{"data":{"data":{"access_key":"AKIA","secret_key":"s3cr3t"},"metadata":{"version":1}}}`)
+		case "/v1/kv/gce":
+			fmt.Fprint(w, `{"data":{"project":"p1"}}`)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	os.Setenv("VAULT_ADDR", server.URL)
+	os.Setenv("VAULT_TOKEN", "testtoken")
+	defer os.Unsetenv("VAULT_ADDR")
+	defer os.Unsetenv("VAULT_TOKEN")
+
+	v, err := Resolve("plain value")
+	assert.Nil(err, "Plain values shouldn't fail")
+	assert.Equal("plain value", v, "Plain values should be kept")
+
+	v, err = Resolve("vault:secret/data/aws#access_key")
+	assert.Nil(err, "Couldn't resolve KV v2 key")
+	assert.Equal("AKIA", v, "Unexpected KV v2 value")
+
+	v, err = Resolve("vault:kv/gce")
+	assert.Nil(err, "Couldn't resolve KV v1 secret")
+	assert.JSONEq(`{"project":"p1"}`, v, "Whole secret should be returned as JSON")
+
+	_, err = Resolve("vault:kv/gce#missing")
+	assert.NotNil(err, "Missing keys should fail")
+
+	_, err = Resolve("vault:kv/unknown#key")
+	assert.NotNil(err, "Missing secrets should fail")
+	assert.Contains(err.Error(), "404", "Error should contain http code 404")
+}
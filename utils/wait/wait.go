@@ -0,0 +1,83 @@
+// Package wait polls resources acted on by asynchronous operations, such as booting a server, till
+// they reach a terminal state, so that commands can block until the operation finishes
+package wait
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flexiant/concerto/utils/cancel"
+)
+
+// now and sleep are replaced in tests
+var (
+	now   = time.Now
+	sleep = cancel.Sleep
+)
+
+// Target holds the terminal states of an operation. Reaching a Done state finishes it, and reaching
+// a Failed state fails it
+type Target struct {
+	Done   []string
+	Failed []string
+	// Restart, when set, takes Done states only once another state has been seen, as operations such as
+	// rebooting finish in the state they start from
+	Restart bool
+}
+
+// Poller polls the state of a resource every Interval, for up to Timeout when positive
+type Poller struct {
+	Interval time.Duration
+	Timeout  time.Duration
+	// Progress, when set, is called with the first state and every change of state
+	Progress func(state string)
+}
+
+// Until polls state till it returns a terminal state of target, returning it. Failed states, timeouts
+// and cancellations are errors; timeouts finish commands with the exit code of package cancel
+func (p *Poller) Until(what string, state func() (string, error), target Target) (string, error) {
+	deadline := now().Add(p.Timeout)
+	last := ""
+	restarted := !target.Restart
+	for {
+		s, err := state()
+		if err != nil {
+			return "", err
+		}
+		if s != last && p.Progress != nil {
+			p.Progress(s)
+		}
+		last = s
+		if !contains(target.Done, s) {
+			restarted = true
+		} else if restarted {
+			return s, nil
+		}
+		if contains(target.Failed, s) {
+			return s, fmt.Errorf("%s is %s", what, s)
+		}
+		interval := p.Interval
+		if p.Timeout > 0 {
+			left := deadline.Sub(now())
+			if left <= 0 {
+				return s, &cancel.Error{Reason: fmt.Sprintf("%s is still %s after %s", what, s, p.Timeout), Code: cancel.TimeoutExitCode}
+			}
+			// the last poll is made right at the deadline
+			if left < interval {
+				interval = left
+			}
+		}
+		if !sleep(interval) {
+			return s, cancel.Err()
+		}
+	}
+}
+
+func contains(states []string, state string) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
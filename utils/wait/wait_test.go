@@ -0,0 +1,92 @@
+package wait
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock replaces the clock of the package, so that sleeping moves it forward
+func fakeClock() *[]time.Duration {
+	clock := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	var slept []time.Duration
+	now = func() time.Time { return clock }
+	sleep = func(d time.Duration) bool {
+		slept = append(slept, d)
+		clock = clock.Add(d)
+		return true
+	}
+	return &slept
+}
+
+// states returns a state function returning each of states in turn, and then the last one
+func states(s ...string) func() (string, error) {
+	return func() (string, error) {
+		state := s[0]
+		if len(s) > 1 {
+			s = s[1:]
+		}
+		return state, nil
+	}
+}
+
+var boot = Target{Done: []string{"operational"}, Failed: []string{"stalled", "error"}}
+
+func TestUntilDone(t *testing.T) {
+	assert := assert.New(t)
+	slept := fakeClock()
+
+	var progress []string
+	p := &Poller{Interval: 10 * time.Second, Timeout: time.Minute, Progress: func(s string) { progress = append(progress, s) }}
+	state, err := p.Until("Server web", states("inactive", "booting", "booting", "bootstrapping", "operational"), boot)
+	assert.Nil(err, "Reaching a done state shouldn't fail")
+	assert.Equal("operational", state, "Unexpected state")
+	assert.Equal([]string{"inactive", "booting", "bootstrapping", "operational"}, progress, "Only changes of state should be reported")
+	assert.Len(*slept, 4, "State should be polled every interval")
+}
+
+func TestUntilRestart(t *testing.T) {
+	assert := assert.New(t)
+	slept := fakeClock()
+
+	reboot := Target{Done: boot.Done, Failed: boot.Failed, Restart: true}
+	state, err := (&Poller{Interval: time.Second}).Until("Server web", states("operational", "operational", "rebooting", "operational"), reboot)
+	assert.Nil(err, "Getting back to a done state shouldn't fail")
+	assert.Equal("operational", state, "Unexpected state")
+	assert.Len(*slept, 3, "Done states shouldn't finish the wait before another state is seen")
+
+	slept = fakeClock()
+	_, err = (&Poller{Interval: 10 * time.Second, Timeout: 30 * time.Second}).Until("Server web", states("operational"), reboot)
+	assert.EqualError(err, "Server web is still operational after 30s", "Never leaving the done state should time out")
+}
+
+func TestUntilFailed(t *testing.T) {
+	assert := assert.New(t)
+	fakeClock()
+
+	state, err := (&Poller{Interval: time.Second}).Until("Server web", states("booting", "stalled"), boot)
+	assert.EqualError(err, "Server web is stalled", "Failed states should fail")
+	assert.Equal("stalled", state, "Unexpected state")
+
+	_, err = (&Poller{Interval: time.Second}).Until("Server web", func() (string, error) { return "", fmt.Errorf("Not found") }, boot)
+	assert.EqualError(err, "Not found", "Errors polling should be returned")
+}
+
+func TestUntilTimeout(t *testing.T) {
+	assert := assert.New(t)
+	slept := fakeClock()
+
+	state, err := (&Poller{Interval: 10 * time.Second, Timeout: 30 * time.Second}).Until("Server web", states("booting"), boot)
+	assert.EqualError(err, "Server web is still booting after 30s", "Polling should stop after timeout")
+	assert.Equal(cancel.TimeoutExitCode, err.(*cancel.Error).Code, "Timeouts should exit as timed out commands")
+	assert.Equal("booting", state, "Unexpected state")
+	assert.Len(*slept, 3, "Unexpected number of polls")
+
+	slept = fakeClock()
+	_, err = (&Poller{Interval: 10 * time.Second, Timeout: 25 * time.Second}).Until("Server web", states("booting"), boot)
+	assert.EqualError(err, "Server web is still booting after 25s", "Polling should stop after timeout")
+	assert.Equal([]time.Duration{10 * time.Second, 10 * time.Second, 5 * time.Second}, *slept, "The last poll should be made at the deadline")
+}
@@ -0,0 +1,88 @@
+package watch
+
+import (
+	"bytes"
+	"reflect"
+)
+
+const (
+	highlight = "\x1b[1;33m"
+	reset     = "\x1b[0m"
+)
+
+// Transition is a change in the state of a listed item between refreshes. Items appearing
+// after the first refresh come from no state
+type Transition struct {
+	Index int
+	ID    string
+	Name  string
+	From  string
+	To    string
+}
+
+// Tracker remembers the state of listed items between refreshes
+type Tracker struct {
+	states  map[string]string
+	started bool
+}
+
+// NewTracker returns a tracker which hasn't seen any list yet
+func NewTracker() *Tracker {
+	return &Tracker{states: make(map[string]string)}
+}
+
+// Update records the state of every item in list, a slice of resources with ID and State fields,
+// and returns the transitions since the previous refresh. The first refresh has none
+func (t *Tracker) Update(list interface{}) []Transition {
+	var transitions []Transition
+	states := make(map[string]string)
+	v := reflect.Indirect(reflect.ValueOf(list))
+	if v.Kind() != reflect.Slice {
+		return nil
+	}
+	for i := 0; i < v.Len(); i++ {
+		item := reflect.Indirect(v.Index(i))
+		if item.Kind() != reflect.Struct {
+			continue
+		}
+		id := stringField(item, "ID", "Id")
+		if id == "" {
+			continue
+		}
+		state := stringField(item, "State")
+		states[id] = state
+		if previous, ok := t.states[id]; t.started && (!ok || previous != state) {
+			transitions = append(transitions, Transition{Index: i, ID: id, Name: stringField(item, "Name"), From: previous, To: state})
+		}
+	}
+	t.states = states
+	t.started = true
+	return transitions
+}
+
+// Highlight returns table, a list printed with a header line, with the rows of the items
+// that transitioned highlighted for terminals
+func Highlight(table []byte, transitions []Transition) []byte {
+	changed := make(map[int]bool)
+	for _, tr := range transitions {
+		changed[tr.Index+1] = true
+	}
+	lines := bytes.Split(table, []byte("\n"))
+	for i, line := range lines {
+		if changed[i] && len(line) > 0 {
+			lines[i] = append(append([]byte(highlight), line...), reset...)
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+// stringField returns the first of the fields of item which is a string
+func stringField(item reflect.Value, fields ...string) string {
+	for _, field := range fields {
+		f := item.FieldByName(field)
+		if f.IsValid() && f.Kind() == reflect.String {
+			return f.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testServer struct {
+	Id    string
+	Name  string
+	State string
+}
+
+func TestUpdate(t *testing.T) {
+	assert := assert.New(t)
+	tracker := NewTracker()
+
+	assert.Empty(tracker.Update([]testServer{{"5aa1", "web", "inactive"}, {"5bb2", "db", "operational"}}), "First refresh shouldn't have transitions")
+	assert.Empty(tracker.Update([]testServer{{"5aa1", "web", "inactive"}, {"5bb2", "db", "operational"}}), "Unchanged states shouldn't have transitions")
+
+	transitions := tracker.Update(&[]testServer{{"5cc3", "cache", "commissioning"}, {"5aa1", "web", "bootstrapping"}, {"5bb2", "db", "operational"}})
+	assert.Equal([]Transition{
+		{Index: 0, ID: "5cc3", Name: "cache", From: "", To: "commissioning"},
+		{Index: 1, ID: "5aa1", Name: "web", From: "inactive", To: "bootstrapping"},
+	}, transitions, "Unexpected transitions")
+
+	assert.Nil(tracker.Update("notalist"), "Non slices should be ignored")
+}
+
+func TestHighlight(t *testing.T) {
+	assert := assert.New(t)
+
+	table := []byte("ID   STATE\n5aa1 inactive\n5bb2 operational\n")
+	assert.Equal(table, Highlight(table, nil), "Tables without transitions shouldn't change")
+	assert.Equal("ID   STATE\n5aa1 inactive\n\x1b[1;33m5bb2 operational\x1b[0m\n",
+		string(Highlight(table, []Transition{{Index: 1, ID: "5bb2"}})), "Rows of transitioned items should be highlighted")
+}
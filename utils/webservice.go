@@ -1,31 +1,41 @@
 package utils
 
 import (
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
+	"github.com/flexiant/concerto/utils/cancel"
+	"github.com/flexiant/concerto/utils/logging"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
-// ConcertoService defines actions to be performed by web service manager
+// ConcertoService defines actions to be performed by web service manager. Responses with error status
+// (300 and above) fail with *HTTPError, holding their status and message. Their status and body are returned
+// too, but GetFile and GetStream return no file nor reader for them
 type ConcertoService interface {
 	Post(path string, payload *map[string]interface{}) ([]byte, int, error)
 	Put(path string, payload *map[string]interface{}) ([]byte, int, error)
+	Patch(path string, payload *map[string]interface{}) ([]byte, int, error)
 	Delete(path string) ([]byte, int, error)
 	Get(path string) ([]byte, int, error)
+	Head(path string) ([]byte, int, error)
+	Do(method string, path string, body io.Reader, headers http.Header) ([]byte, int, error)
 	GetFile(path string, directoryPath string) (string, int, error)
+	GetStream(path string) (io.ReadCloser, int, error)
 }
 
 // HTTPConcertoservice web service manager.
 type HTTPConcertoservice struct {
 	config *Config
 	client *http.Client
+	ctx    context.Context
 }
 
 // NewHTTPConcertoService creates new http Concerto client based on config
@@ -35,7 +45,7 @@ func NewHTTPConcertoService(config *Config) (hcs *HTTPConcertoservice, err error
 		return nil, fmt.Errorf("Web service configuration failed. No data in configuration")
 	}
 
-	if !config.IsConfigReady() {
+	if !config.IsConfigReady() && !Replaying() {
 		return nil, fmt.Errorf("Configuration is incomplete.")
 	}
 
@@ -44,95 +54,123 @@ func NewHTTPConcertoService(config *Config) (hcs *HTTPConcertoservice, err error
 		config: config,
 	}
 
-	// Loads Clients Certificates and creates and 509KeyPair
-	cert, err := tls.LoadX509KeyPair(hcs.config.Certificate.Cert, hcs.config.Certificate.Key)
+	hcs.client, err = NewHTTPClient(config)
 	if err != nil {
 		return nil, err
 	}
 
-	// Creates a client with specific transport configurations
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{Certificates: []tls.Certificate{cert}, InsecureSkipVerify: true},
-	}
-	hcs.client = &http.Client{Transport: transport}
-
 	return hcs, nil
 }
 
-// Post sends POST request to Concerto API
-func (hcs *HTTPConcertoservice) Post(path string, payload *map[string]interface{}) ([]byte, int, error) {
-
-	url, jsPayload, err := hcs.prepareCall(path, payload)
-	if err != nil {
-		return nil, 0, err
-	}
+// WithContext returns a copy of the service whose requests are sent with ctx, so that they're aborted when
+// it's done. Services send requests with cancel.Context by default
+func (hcs *HTTPConcertoservice) WithContext(ctx context.Context) *HTTPConcertoservice {
+	c := *hcs
+	c.ctx = ctx
+	return &c
+}
 
-	log.Debugf("Sending POST request to %s with payload %s ", url, jsPayload)
-	response, err := hcs.client.Post(url, "application/json", jsPayload)
-	if err != nil {
-		return nil, 0, err
+func (hcs *HTTPConcertoservice) context() context.Context {
+	if hcs.ctx != nil {
+		return hcs.ctx
 	}
+	return cancel.Context()
+}
 
-	return hcs.receiveResponse(response)
+// Post sends POST request to Concerto API
+func (hcs *HTTPConcertoservice) Post(path string, payload *map[string]interface{}) ([]byte, int, error) {
+	return hcs.sendPayload("POST", path, payload)
 }
 
 // Put sends PUT request to Concerto API
 func (hcs *HTTPConcertoservice) Put(path string, payload *map[string]interface{}) ([]byte, int, error) {
-	url, jsPayload, err := hcs.prepareCall(path, payload)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	log.Debugf("Sending PUT request to %s with payload %s ", url, jsPayload)
-	request, err := http.NewRequest("PUT", url, jsPayload)
-	if err != nil {
-		return nil, 0, err
-	}
-	request.Header = map[string][]string{"Content-type": {"application/json"}}
-	response, err := hcs.client.Do(request)
-	if err != nil {
-		return nil, 0, err
-	}
+	return hcs.sendPayload("PUT", path, payload)
+}
 
-	return hcs.receiveResponse(response)
+// Patch sends PATCH request to Concerto API, updating only the attributes of payload
+func (hcs *HTTPConcertoservice) Patch(path string, payload *map[string]interface{}) ([]byte, int, error) {
+	return hcs.sendPayload("PATCH", path, payload)
 }
 
 // Delete sends DELETE request to Concerto API
 func (hcs *HTTPConcertoservice) Delete(path string) ([]byte, int, error) {
+	return hcs.Do("DELETE", path, nil, jsonHeaders())
+}
+
+// Get sends GET request to Concerto API
+func (hcs *HTTPConcertoservice) Get(path string) ([]byte, int, error) {
+	return hcs.Do("GET", path, nil, nil)
+}
+
+// Head sends HEAD request to Concerto API, telling whether a resource exists without receiving it
+func (hcs *HTTPConcertoservice) Head(path string) ([]byte, int, error) {
+	return hcs.Do("HEAD", path, nil, nil)
+}
+
+// Do sends a request with any method, a raw JSON body and extra headers to Concerto API. Path may include a
+// query string. Bodies are sent as JSON unless headers set another Content-type
+func (hcs *HTTPConcertoservice) Do(method string, path string, body io.Reader, headers http.Header) ([]byte, int, error) {
 	url, _, err := hcs.prepareCall(path, nil)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	log.Debugf("Sending DELETE request to %s", url)
-	request, err := http.NewRequest("DELETE", url, nil)
+	cached := hcs.cachedResponse(method, url)
+	if cached != nil && responseCache.Fresh(cached) {
+		log.Debugf("Using cached response of %s", url)
+		return cached.Body, cached.Status, nil
+	}
+
+	log.Debugf("Sending %s request to %s", method, url)
+	request, err := http.NewRequestWithContext(hcs.context(), method, url, body)
 	if err != nil {
 		return nil, 0, err
 	}
-	request.Header = map[string][]string{"Content-type": {"application/json"}}
-	response, err := hcs.client.Do(request)
+	if body != nil {
+		request.Header.Set("Content-type", "application/json")
+	}
+	if cached != nil && cached.ETag != "" {
+		request.Header.Set("If-None-Match", cached.ETag)
+	}
+	for name, values := range headers {
+		request.Header[http.CanonicalHeaderKey(name)] = values
+	}
+	response, err := SendRequest(hcs.client, request)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	return hcs.receiveResponse(response)
-}
-
-// Get sends GET request to Concerto API
-func (hcs *HTTPConcertoservice) Get(path string) ([]byte, int, error) {
-
-	url, _, err := hcs.prepareCall(path, nil)
+	data, status, err := hcs.receiveResponse(response)
 	if err != nil {
 		return nil, 0, err
 	}
+	data, status = hcs.cacheResponse(method, url, cached, response.Header.Get("ETag"), data, status)
+	if status >= 300 {
+		httpErr := NewHTTPError(status, data, response.Header.Get(requestIDHeader))
+		httpErr.Method = method
+		return data, status, httpErr
+	}
+	return data, status, nil
+}
 
-	log.Debugf("Sending GET request to %s", url)
-	response, err := hcs.client.Get(url)
+// sendPayload sends a request with payload encoded as JSON
+func (hcs *HTTPConcertoservice) sendPayload(method string, path string, payload *map[string]interface{}) ([]byte, int, error) {
+	_, jsPayload, err := hcs.prepareCall(path, payload)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	return hcs.receiveResponse(response)
+	var body io.Reader
+	if jsPayload != nil {
+		log.Debugf("Payload of %s request to %s: %s", method, path, jsPayload)
+		body = jsPayload
+	}
+	return hcs.Do(method, path, body, jsonHeaders())
+}
+
+// jsonHeaders are the headers of requests whose body, even when empty, is JSON
+func jsonHeaders() http.Header {
+	return http.Header{"Content-Type": {"application/json"}}
 }
 
 // GetFile sends GET request to Concerto API and receives a file
@@ -144,7 +182,11 @@ func (hcs *HTTPConcertoservice) GetFile(path string, directoryPath string) (stri
 	}
 
 	log.Debugf("Sending GET request to %s", url)
-	response, err := hcs.client.Get(url)
+	request, err := http.NewRequestWithContext(hcs.context(), "GET", url, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	response, err := SendRequest(hcs.client, request)
 	if err != nil {
 		return "", 0, err
 	}
@@ -152,13 +194,26 @@ func (hcs *HTTPConcertoservice) GetFile(path string, directoryPath string) (stri
 	defer response.Body.Close()
 	log.Debugf("Status code:%d message:%s", response.StatusCode, response.Status)
 
+	// errors are returned as messages instead of files
+	if response.StatusCode >= 300 {
+		data, err := ReadBody(response.Body, DefaultMaxResponseSize)
+		if err != nil {
+			return "", response.StatusCode, err
+		}
+		httpErr := NewHTTPError(response.StatusCode, data, response.Header.Get(requestIDHeader))
+		httpErr.Method = "GET"
+		return "", response.StatusCode, httpErr
+	}
+
 	r, err := regexp.Compile("filename=\\\"([^\\\"]*){1}\\\"")
 	if err != nil {
 		return "", response.StatusCode, err
 	}
 
-	// TODO check errors
-	fileName := r.FindStringSubmatch(response.Header.Get("Content-Disposition"))[1]
+	fileName := path[strings.LastIndex(path, "/")+1:]
+	if m := r.FindStringSubmatch(response.Header.Get("Content-Disposition")); m != nil {
+		fileName = m[1]
+	}
 	realFileName := fmt.Sprintf("%s/%s", directoryPath, fileName)
 
 	output, err := os.Create(realFileName)
@@ -176,6 +231,79 @@ func (hcs *HTTPConcertoservice) GetFile(path string, directoryPath string) (stri
 	return realFileName, response.StatusCode, nil
 }
 
+// GetStream sends GET request to Concerto API and returns a reader with the response body.
+// Responses bigger than the configured limit are buffered on disk instead of memory.
+func (hcs *HTTPConcertoservice) GetStream(path string) (io.ReadCloser, int, error) {
+
+	url, _, err := hcs.prepareCall(path, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	log.Debugf("Sending GET request to %s", url)
+	request, err := http.NewRequestWithContext(hcs.context(), "GET", url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	response, err := SendRequest(hcs.client, request)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer response.Body.Close()
+
+	limit, err := hcs.config.MaxResponseSize()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if response.StatusCode >= 300 {
+		data, err := ReadBody(response.Body, limit)
+		if err != nil {
+			return nil, response.StatusCode, err
+		}
+		httpErr := NewHTTPError(response.StatusCode, data, response.Header.Get(requestIDHeader))
+		httpErr.Method = "GET"
+		return nil, response.StatusCode, httpErr
+	}
+	body, err := SpillBody(response.Body, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, response.StatusCode, nil
+}
+
+// GetJSON sends GET request to path and decodes the JSON response into v. Responses with error status
+// are returned as errors
+func GetJSON(cs ConcertoService, path string, v interface{}) error {
+	data, status, err := cs.Get(path)
+	return decodeJSONResponse(data, status, err, v)
+}
+
+// PostJSON sends POST request to path with payload, and decodes the JSON response into v unless it's nil
+func PostJSON(cs ConcertoService, path string, payload *map[string]interface{}, v interface{}) error {
+	data, status, err := cs.Post(path, payload)
+	return decodeJSONResponse(data, status, err, v)
+}
+
+// PutJSON sends PUT request to path with payload, and decodes the JSON response into v unless it's nil
+func PutJSON(cs ConcertoService, path string, payload *map[string]interface{}, v interface{}) error {
+	data, status, err := cs.Put(path, payload)
+	return decodeJSONResponse(data, status, err, v)
+}
+
+func decodeJSONResponse(data []byte, status int, err error, v interface{}) error {
+	if err != nil {
+		return err
+	}
+	if err = CheckStandardStatus(status, data); err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
 func (hcs *HTTPConcertoservice) prepareCall(path string, payload *map[string]interface{}) (url string, jsPayload *strings.Reader, err error) {
 
 	if hcs.config == nil || hcs.client == nil {
@@ -200,7 +328,13 @@ func (hcs *HTTPConcertoservice) prepareCall(path string, payload *map[string]int
 func (hcs *HTTPConcertoservice) receiveResponse(response *http.Response) (body []byte, status int, err error) {
 
 	defer response.Body.Close()
-	body, err = ioutil.ReadAll(response.Body)
+
+	limit, err := hcs.config.MaxResponseSize()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	body, err = ReadBody(response.Body, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -209,3 +343,53 @@ func (hcs *HTTPConcertoservice) receiveResponse(response *http.Response) (body [
 
 	return body, response.StatusCode, nil
 }
+
+// requestIDHeader holds the identifier of requests, and of the responses failing them
+const requestIDHeader = "X-Request-Id"
+
+// SendRequest sends request to Concerto API, tagging it with an identifier that can be
+// used to correlate log entries. Requests without a context are aborted when the command is cancelled
+func SendRequest(client *http.Client, request *http.Request) (*http.Response, error) {
+	if err := cancel.Err(); err != nil {
+		return nil, err
+	}
+	if request.Context() == context.Background() {
+		request = request.WithContext(cancel.Context())
+	}
+
+	requestID := logging.NewRequestID()
+	request.Header.Set(requestIDHeader, requestID)
+
+	rlog := log.WithField("request_id", requestID)
+	rlog.Debugf("%s %s", request.Method, request.URL)
+
+	start := time.Now()
+	endpoint := endpointKey(request.Method, request.URL.Path)
+	response, err := client.Do(request)
+	if err != nil {
+		rlog.Debugf("Request failed: %s", err)
+		if cerr := cancel.Err(); cerr != nil {
+			err = cerr
+		}
+		stats.record(endpoint, request.ContentLength, 0, time.Since(start))
+		apiRequests.Inc(request.Method, "error")
+		apiRequestDuration.Observe(time.Since(start).Seconds(), request.Method)
+		return nil, err
+	}
+	rlog.Debugf("Status code: (%d) %s", response.StatusCode, response.Status)
+	// failed responses carry the identifier of their request, unless the API echoes its own, so that errors
+	// tell which request failed
+	if response.StatusCode >= 300 && response.Header.Get(requestIDHeader) == "" {
+		response.Header.Set(requestIDHeader, requestID)
+	}
+	apiRequests.Inc(request.Method, strconv.Itoa(response.StatusCode))
+	apiRequestDuration.Observe(time.Since(start).Seconds(), request.Method)
+
+	response.Body = &statsBody{
+		ReadCloser: response.Body,
+		endpoint:   endpoint,
+		sent:       request.ContentLength,
+		start:      start,
+	}
+	return response, nil
+}
@@ -1,40 +1,91 @@
 package utils
 
 import (
+	"io"
+	"io/ioutil"
+	"net/http"
+
 	"github.com/stretchr/testify/mock"
 )
 
-// MockConcertoService  service manager.
+// MockConcertoService  service manager. As HTTPConcertoservice, it returns responses with error status as
+// HTTPError unless they're mocked with another error
 type MockConcertoService struct {
 	mock.Mock
 }
 
+// mockedResponse returns a mocked response to a method request, failing with HTTPError when its status is an
+// error one
+func mockedResponse(method string, data []byte, status int, err error) ([]byte, int, error) {
+	if err == nil && status >= 300 {
+		httpErr := NewHTTPError(status, data, "")
+		httpErr.Method = method
+		err = httpErr
+	}
+	return data, status, err
+}
+
 // Post mocks POST request to Concerto API
 func (m *MockConcertoService) Post(path string, payload *map[string]interface{}) ([]byte, int, error) {
 	args := m.Called(path, payload)
-	return args.Get(0).([]byte), args.Int(1), args.Error(2)
+	return mockedResponse("POST", args.Get(0).([]byte), args.Int(1), args.Error(2))
 }
 
 // Put mocks PUT request to Concerto API
 func (m *MockConcertoService) Put(path string, payload *map[string]interface{}) ([]byte, int, error) {
 	args := m.Called(path, payload)
-	return args.Get(0).([]byte), args.Int(1), args.Error(2)
+	return mockedResponse("PUT", args.Get(0).([]byte), args.Int(1), args.Error(2))
+}
+
+// Patch mocks PATCH request to Concerto API
+func (m *MockConcertoService) Patch(path string, payload *map[string]interface{}) ([]byte, int, error) {
+	args := m.Called(path, payload)
+	return mockedResponse("PATCH", args.Get(0).([]byte), args.Int(1), args.Error(2))
 }
 
 // Delete mocks DELETE request to Concerto API
 func (m *MockConcertoService) Delete(path string) ([]byte, int, error) {
 	args := m.Called(path)
-	return args.Get(0).([]byte), args.Int(1), args.Error(2)
+	return mockedResponse("DELETE", args.Get(0).([]byte), args.Int(1), args.Error(2))
 }
 
 // Get mocks GET request to Concerto API
 func (m *MockConcertoService) Get(path string) ([]byte, int, error) {
 	args := m.Called(path)
-	return args.Get(0).([]byte), args.Int(1), args.Error(2)
+	return mockedResponse("GET", args.Get(0).([]byte), args.Int(1), args.Error(2))
+}
+
+// Head mocks HEAD request to Concerto API
+func (m *MockConcertoService) Head(path string) ([]byte, int, error) {
+	args := m.Called(path)
+	return mockedResponse("HEAD", args.Get(0).([]byte), args.Int(1), args.Error(2))
+}
+
+// Do mocks a request with any method to Concerto API
+func (m *MockConcertoService) Do(method string, path string, body io.Reader, headers http.Header) ([]byte, int, error) {
+	args := m.Called(method, path, body, headers)
+	return mockedResponse(method, args.Get(0).([]byte), args.Int(1), args.Error(2))
 }
 
 // GetFile sends GET request to Concerto API and receives a file
 func (m *MockConcertoService) GetFile(path string, directoryPath string) (string, int, error) {
 	args := m.Called(path, directoryPath)
-	return args.String(0), args.Int(1), args.Error(2)
+	_, status, err := mockedResponse("GET", nil, args.Int(1), args.Error(2))
+	return args.String(0), status, err
+}
+
+// GetStream mocks GET request to Concerto API returning a reader
+func (m *MockConcertoService) GetStream(path string) (io.ReadCloser, int, error) {
+	args := m.Called(path)
+	body, status, err := args.Get(0).(io.ReadCloser), args.Int(1), args.Error(2)
+	if err == nil && status >= 300 {
+		defer body.Close()
+		data, rerr := ioutil.ReadAll(body)
+		if rerr != nil {
+			return nil, status, rerr
+		}
+		_, status, err = mockedResponse("GET", data, status, nil)
+		return nil, status, err
+	}
+	return body, status, err
 }
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendRequestFailedID(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/echo" {
+			w.Header().Set(requestIDHeader, "api-"+r.Header.Get(requestIDHeader))
+		}
+		http.Error(w, `{"error":"Not found"}`, http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	request, _ := http.NewRequest("GET", ts.URL+"/servers", nil)
+	response, err := SendRequest(http.DefaultClient, request)
+	assert.Nil(err, "Couldn't send request")
+	response.Body.Close()
+	assert.Equal(request.Header.Get(requestIDHeader), response.Header.Get(requestIDHeader), "Failed responses should carry the identifier of their request")
+
+	request, _ = http.NewRequest("GET", ts.URL+"/echo", nil)
+	response, err = SendRequest(http.DefaultClient, request)
+	assert.Nil(err, "Couldn't send request")
+	response.Body.Close()
+	assert.Equal("api-"+request.Header.Get(requestIDHeader), response.Header.Get(requestIDHeader), "Identifiers echoed by the API should be kept")
+
+	httpErr := NewHTTPError(404, []byte(`{"error":"Not found"}`), "abc123")
+	assert.Equal(&HTTPError{Status: 404, Message: "Not found", RequestID: "abc123"}, httpErr, "Unexpected error")
+}
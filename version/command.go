@@ -0,0 +1,21 @@
+package version
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/flexiant/concerto/cmd"
+)
+
+// Command returns the version CLI command
+func Command() cli.Command {
+	return cli.Command{
+		Name:   "version",
+		Usage:  "Shows the CLI version and build, and checks that the API supports it",
+		Action: cmd.VersionShow,
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "offline",
+				Usage: "Don't query the API version",
+			},
+		},
+	}
+}
@@ -11,6 +11,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists the available Apps.",
 			Action: cmd.AppList,
+			Flags:  cmd.ListFlags(),
 		},
 		{
 			Name:   "deploy",
@@ -11,6 +11,7 @@ func SubCommands() []cli.Command {
 			Name:   "list",
 			Usage:  "Lists the available Locations.",
 			Action: cmd.LocationList,
+			Flags:  cmd.ListFlags(),
 		},
 	}
 }